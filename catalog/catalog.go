@@ -10,6 +10,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/alpacahq/marketstore/executor/colfile"
 	"github.com/alpacahq/marketstore/utils/io"
 )
 
@@ -215,6 +216,17 @@ func (d *Directory) GetLatestTimeBucketInfoFromKey(key *io.TimeBucketKey) (fi *i
 	return subDir.getLatestYearFile()
 }
 
+// GetTimeBucketInfoSliceFromKey returns the TimeBucketInfo of every year file
+// of a bucket
+func (d *Directory) GetTimeBucketInfoSliceFromKey(key *io.TimeBucketKey) ([]*io.TimeBucketInfo, error) {
+	path := key.GetPathToYearFiles(d.pathToItemName)
+	subDir, err := d.GetOwningSubDirectory(path + "/1970.bin")
+	if err != nil {
+		return nil, err
+	}
+	return subDir.GetTimeBucketInfoSlice(), nil
+}
+
 func (d *Directory) GetLatestTimeBucketInfoFromFullFilePath(fullFilePath string) (fi *io.TimeBucketInfo, err error) {
 	subDir, err := d.GetOwningSubDirectory(fullFilePath)
 	if err != nil {
@@ -304,6 +316,43 @@ func (subDir *Directory) AddFile(newYear int16) (finfo_p *io.TimeBucketInfo, err
 	return newFileInfo, nil
 }
 
+// UpdateTimeBucketInfo replaces the catalog entry of a year file whose
+// header was rewritten
+func (d *Directory) UpdateTimeBucketInfo(tbi *io.TimeBucketInfo) error {
+	subDir, err := d.GetOwningSubDirectory(tbi.Path)
+	if err != nil {
+		return err
+	}
+	subDir.Lock()
+	defer subDir.Unlock()
+	if _, ok := subDir.datafile[tbi.Path]; !ok {
+		return NotFoundError(tbi.Path)
+	}
+	subDir.datafile[tbi.Path] = tbi
+	return nil
+}
+
+// RemoveYearFile deletes a year file from the catalog and the disk. The latest
+// year file of a bucket, which new writes rely on, cannot be removed.
+func (d *Directory) RemoveYearFile(tbi *io.TimeBucketInfo) error {
+	subDir, err := d.GetOwningSubDirectory(tbi.Path)
+	if err != nil {
+		return err
+	}
+	subDir.Lock()
+	defer subDir.Unlock()
+	if _, ok := subDir.datafile[tbi.Path]; !ok {
+		return NotFoundError(tbi.Path)
+	}
+	for _, other := range subDir.datafile {
+		if other.Year > tbi.Year {
+			delete(subDir.datafile, tbi.Path)
+			return os.Remove(tbi.Path)
+		}
+	}
+	return fmt.Errorf("Unable to remove %s, the latest year file of its bucket", tbi.Path)
+}
+
 func (d *Directory) DirHasDataFiles() bool {
 	d.RLock()
 	defer d.RUnlock()
@@ -641,7 +690,16 @@ func newTimeBucketInfoFromTemplate(newTimeBucketInfo *io.TimeBucketInfo) (err er
 	if err = io.WriteHeader(fp, newTimeBucketInfo); err != nil {
 		return UnableToWriteHeader(err.Error())
 	}
-	if err = fp.Truncate(io.FileSize(newTimeBucketInfo.GetTimeframe(), int(newTimeBucketInfo.Year), int(newTimeBucketInfo.GetRecordLength()))); err != nil {
+	size := io.FileSize(newTimeBucketInfo.GetTimeframe(), int(newTimeBucketInfo.Year), int(newTimeBucketInfo.GetRecordLength()))
+	/*
+		Extending the file with Truncate leaves it sparse, so that disk space
+		is only allocated for the intervals that are written
+	*/
+	if newTimeBucketInfo.GetCompression() != io.NOCOMPRESSION {
+		// Compressed files start with an empty block directory
+		size = colfile.EmptyFileSize(newTimeBucketInfo)
+	}
+	if err = fp.Truncate(size); err != nil {
 		return UnableToCreateFile(err.Error())
 	}
 
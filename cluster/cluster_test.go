@@ -0,0 +1,309 @@
+package cluster
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/alpacahq/marketstore/frontend"
+	"github.com/alpacahq/marketstore/frontend/client"
+	"github.com/alpacahq/marketstore/utils/io"
+	"github.com/alpacahq/marketstore/utils/rpc/msgpack2"
+	rpc "github.com/alpacahq/rpc/rpc2"
+)
+
+// Hook up gocheck into the "go test" runner.
+func Test(t *testing.T) { TestingT(t) }
+
+type ClusterTestSuite struct{}
+
+var _ = Suite(&ClusterTestSuite{})
+
+func (s *ClusterTestSuite) TestRing(c *C) {
+	nodes := []string{"a:5993", "b:5993", "c:5993"}
+	ring := NewRing(nodes, DefaultReplicas)
+	reversed := NewRing([]string{"c:5993", "b:5993", "a:5993", "a:5993"}, DefaultReplicas)
+	grown := NewRing(append(nodes, "d:5993"), DefaultReplicas)
+	c.Assert(reversed.Nodes(), HasLen, 3)
+
+	owned := make(map[string]int)
+	for i := 0; i < 1000; i++ {
+		symbol := fmt.Sprintf("SYM%d", i)
+		owner := ring.Owner(symbol)
+		owned[owner]++
+		c.Assert(reversed.Owner(symbol), Equals, owner)
+		// Only the symbols of the new node move
+		if moved := grown.Owner(symbol); moved != owner {
+			c.Assert(moved, Equals, "d:5993")
+		}
+	}
+	c.Assert(owned, HasLen, 3)
+	for _, node := range nodes {
+		c.Assert(owned[node] > 200, Equals, true, Commentf("%v", owned))
+	}
+	c.Assert(NewRing(nil, DefaultReplicas).Owner("AAPL"), Equals, "")
+}
+
+func (s *ClusterTestSuite) TestCoordinator(c *C) {
+	var nodes []string
+	stores := make(map[string]*nodeService)
+	for i := 0; i < 3; i++ {
+		store := &nodeService{series: io.NewColumnSeriesMap()}
+		server := rpc.NewServer()
+		server.RegisterCodec(msgpack2.NewCodec(), "application/x-msgpack")
+		c.Assert(server.RegisterService(store, "DataService"), IsNil)
+		mux := http.NewServeMux()
+		mux.Handle("/rpc", server)
+		ts := httptest.NewServer(mux)
+		defer ts.Close()
+		u, _ := url.Parse(ts.URL)
+		nodes = append(nodes, u.Host)
+		stores[u.Host] = store
+	}
+	server, coordinator, err := NewServer(nodes)
+	c.Assert(err, IsNil)
+	mux := http.NewServeMux()
+	mux.Handle("/rpc", server)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	cl, err := client.NewClient(ts.URL)
+	c.Assert(err, IsNil)
+
+	symbols := []string{"AAPL", "AMZN", "FB", "GOOG", "IBM", "MSFT", "NFLX", "TSLA"}
+	csm := io.NewColumnSeriesMap()
+	for i, symbol := range symbols {
+		cs := io.NewColumnSeries()
+		cs.AddColumn("Epoch", []int64{1500000000, 1500000060})
+		cs.AddColumn("Close", []float32{float32(i), float32(i) + 0.5})
+		csm.AddColumnSeries(*io.NewTimeBucketKey(symbol + "/1Min/OHLC"), cs)
+	}
+	nmds, err := newDataset(csm)
+	c.Assert(err, IsNil)
+	written := &frontend.MultiServerResponse{}
+	err = cl.Call("Write", &frontend.MultiWriteRequest{
+		Requests: []frontend.WriteRequest{{Data: nmds}},
+	}, written)
+	c.Assert(err, IsNil)
+	c.Assert(written.Responses, HasLen, 0)
+
+	// Each symbol is written to its owner only
+	for node, store := range stores {
+		for tbk := range store.series {
+			c.Assert(coordinator.Owner(tbk.GetItemInCategory("Symbol")), Equals, node)
+		}
+	}
+	total := 0
+	for _, store := range stores {
+		total += len(store.series)
+	}
+	c.Assert(total, Equals, len(symbols))
+
+	listed := &frontend.ListSymbolsResponse{}
+	c.Assert(cl.Call("ListSymbols", &frontend.ListSymbolsArgs{}, listed), IsNil)
+	c.Assert(listed.Results, DeepEquals, symbols)
+
+	queried := &frontend.MultiQueryResponse{}
+	err = cl.Call("Query", &frontend.MultiQueryRequest{
+		Requests: []frontend.QueryRequest{
+			{Destination: "TSLA,AAPL,IBM/1Min/OHLC"},
+			{Destination: "*/1Min/OHLC"},
+			{Destination: "XYZ/1Min/OHLC"},
+		},
+	}, queried)
+	c.Assert(err, IsNil)
+	c.Assert(queried.Responses, HasLen, 3)
+	result, err := queried.Responses[0].Result.ToColumnSeriesMap()
+	c.Assert(err, IsNil)
+	c.Assert(result, HasLen, 3)
+	c.Assert(result[*io.NewTimeBucketKey("IBM/1Min/OHLC")].GetByName("Close"), DeepEquals, []float32{4, 4.5})
+	result, err = queried.Responses[1].Result.ToColumnSeriesMap()
+	c.Assert(err, IsNil)
+	c.Assert(result, HasLen, len(symbols))
+	for tbk, cs := range csm {
+		c.Assert(result[tbk].GetByName("Close"), DeepEquals, cs.GetByName("Close"))
+	}
+	c.Assert(queried.Responses[2].Result, IsNil)
+
+	// The merged results are encoded by the coordinator
+	queried = &frontend.MultiQueryResponse{}
+	err = cl.Call("Query", &frontend.MultiQueryRequest{
+		Requests: []frontend.QueryRequest{{Destination: "*/1Min/OHLC", Format: frontend.FormatArrow}},
+	}, queried)
+	c.Assert(err, IsNil)
+	c.Assert(queried.Responses[0].Result, IsNil)
+	c.Assert(queried.Responses[0].Arrow, Not(HasLen), 0)
+
+	err = cl.Call("Query", &frontend.MultiQueryRequest{
+		Requests: []frontend.QueryRequest{{IsSQLStatement: true, SQLStatement: "SELECT * FROM `AAPL/1Min/OHLC`;"}},
+	}, &frontend.MultiQueryResponse{})
+	c.Assert(err, NotNil)
+
+	/*
+		A node holding symbols it no longer owns, as after a change of the
+		nodes, still serves them unless their owner does
+	*/
+	var former *nodeService
+	for node, store := range stores {
+		if node != coordinator.Owner("ORCL") && node != coordinator.Owner("AAPL") {
+			former = store
+			break
+		}
+	}
+	c.Assert(former, NotNil)
+	stale := io.NewColumnSeries()
+	stale.AddColumn("Epoch", []int64{1400000000})
+	stale.AddColumn("Close", []float32{-1})
+	former.series[*io.NewTimeBucketKey("ORCL/1Min/OHLC")] = csm[*io.NewTimeBucketKey("IBM/1Min/OHLC")]
+	former.series[*io.NewTimeBucketKey("AAPL/1Min/OHLC")] = stale
+	listed = &frontend.ListSymbolsResponse{}
+	c.Assert(cl.Call("ListSymbols", &frontend.ListSymbolsArgs{}, listed), IsNil)
+	c.Assert(listed.Results, HasLen, len(symbols)+1)
+	queried = &frontend.MultiQueryResponse{}
+	err = cl.Call("Query", &frontend.MultiQueryRequest{
+		Requests: []frontend.QueryRequest{{Destination: "*/1Min/OHLC"}},
+	}, queried)
+	c.Assert(err, IsNil)
+	result, err = queried.Responses[0].Result.ToColumnSeriesMap()
+	c.Assert(err, IsNil)
+	c.Assert(result, HasLen, len(symbols)+1)
+	c.Assert(result[*io.NewTimeBucketKey("ORCL/1Min/OHLC")].Len(), Equals, 2)
+	c.Assert(result[*io.NewTimeBucketKey("AAPL/1Min/OHLC")].GetByName("Close"), DeepEquals, []float32{0, 0.5})
+	delete(former.series, *io.NewTimeBucketKey("ORCL/1Min/OHLC"))
+	delete(former.series, *io.NewTimeBucketKey("AAPL/1Min/OHLC"))
+
+	/*
+		Deletes go to the owners of their symbols, backups are rejected
+	*/
+	deleted := &frontend.MultiDeleteResponse{}
+	err = cl.Call("Delete", &frontend.MultiDeleteRequest{
+		Requests: []frontend.DeleteRequest{
+			{Destination: "*/1Min/OHLC", Symbols: []string{"AAPL", "TSLA"}, EpochEnd: 1500000000},
+			{Destination: "*/1Min/OHLC", EpochEnd: 1500000000},
+		},
+	}, deleted)
+	c.Assert(err, IsNil)
+	c.Assert(deleted.Responses, HasLen, 2)
+	c.Assert(deleted.Responses[0].ServerResp.Error, Equals, "")
+	c.Assert(deleted.Responses[0].Deleted, Equals, int64(2))
+	c.Assert(deleted.Responses[1].Deleted, Equals, int64(len(symbols)-2))
+
+	err = cl.Call("Backup", &frontend.BackupRequest{Destination: "all"}, &frontend.BackupResponse{})
+	c.Assert(err, NotNil)
+}
+
+// nodeService stands in for the DataService of a node, keeping the series
+// written to it in memory
+type nodeService struct {
+	sync.Mutex
+	series io.ColumnSeriesMap
+}
+
+func (ns *nodeService) Write(r *http.Request, reqs *frontend.MultiWriteRequest, response *frontend.MultiServerResponse) error {
+	ns.Lock()
+	defer ns.Unlock()
+	for _, req := range reqs.Requests {
+		csm, err := req.Data.ToColumnSeriesMap()
+		if err != nil {
+			return err
+		}
+		for tbk, cs := range csm {
+			ns.series[tbk] = cs
+		}
+	}
+	return nil
+}
+
+func (ns *nodeService) Query(r *http.Request, reqs *frontend.MultiQueryRequest, response *frontend.MultiQueryResponse) error {
+	ns.Lock()
+	defer ns.Unlock()
+	for _, req := range reqs.Requests {
+		dest := io.NewTimeBucketKey(req.Destination)
+		symbols := symbolsOf(dest)
+		if symbols[0] == "*" {
+			symbols = nil
+			for tbk := range ns.series {
+				symbols = append(symbols, tbk.GetItemInCategory("Symbol"))
+			}
+		}
+		csm := io.NewColumnSeriesMap()
+		for _, symbol := range symbols {
+			tbk := io.NewTimeBucketKey(withSymbols(dest, []string{symbol}))
+			if cs, ok := ns.series[*tbk]; ok {
+				csm[*tbk] = cs
+			}
+		}
+		nmds, err := newDataset(csm)
+		if err != nil {
+			return err
+		}
+		response.Responses = append(response.Responses, frontend.QueryResponse{Result: nmds})
+	}
+	return nil
+}
+
+func (ns *nodeService) ListSymbols(r *http.Request, args *frontend.ListSymbolsArgs, response *frontend.ListSymbolsResponse) error {
+	ns.Lock()
+	defer ns.Unlock()
+	for tbk := range ns.series {
+		response.Results = append(response.Results, tbk.GetItemInCategory("Symbol"))
+	}
+	return nil
+}
+
+// Delete removes the rows up to EpochEnd, the only bound used by the tests
+func (ns *nodeService) Delete(r *http.Request, reqs *frontend.MultiDeleteRequest, response *frontend.MultiDeleteResponse) error {
+	ns.Lock()
+	defer ns.Unlock()
+	for _, req := range reqs.Requests {
+		dest := io.NewTimeBucketKey(req.Destination)
+		symbols := symbolsOf(dest)
+		all := symbols[0] == "*"
+		var deleted int64
+		for tbk, cs := range ns.series {
+			if !all && !containsString(symbols, tbk.GetItemInCategory("Symbol")) {
+				continue
+			}
+			var keep []int
+			for i, epoch := range cs.GetEpoch() {
+				if epoch > req.EpochEnd {
+					keep = append(keep, i)
+				}
+			}
+			deleted += int64(cs.Len() - len(keep))
+			kept := io.NewColumnSeries()
+			for _, name := range cs.GetColumnNames() {
+				switch col := cs.GetColumn(name).(type) {
+				case []int64:
+					var values []int64
+					for _, i := range keep {
+						values = append(values, col[i])
+					}
+					kept.AddColumn(name, values)
+				case []float32:
+					var values []float32
+					for _, i := range keep {
+						values = append(values, col[i])
+					}
+					kept.AddColumn(name, values)
+				}
+			}
+			ns.series[tbk] = kept
+		}
+		response.Responses = append(response.Responses, frontend.DeleteResponse{Deleted: deleted})
+	}
+	return nil
+}
+
+func containsString(items []string, item string) bool {
+	for _, i := range items {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,537 @@
+package cluster
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/alpacahq/marketstore/frontend"
+	"github.com/alpacahq/marketstore/frontend/client"
+	"github.com/alpacahq/marketstore/utils"
+	"github.com/alpacahq/marketstore/utils/io"
+	"github.com/alpacahq/marketstore/utils/rpc/msgpack2"
+	rpc "github.com/alpacahq/rpc/rpc2"
+	"github.com/alpacahq/rpc/rpc2/json2"
+)
+
+/*
+DataService is the RPC service of a coordinator, registered under the name of
+the one of a node so that the clients can not tell them apart. It serves
+Query, ListSymbols, Write, Create, Destroy, GetInfo, AlterBucket, Delete and
+Compact. The SQL statements and the paged queries, which can not be split by
+symbol, are rejected, as are backups, which are taken on each node.
+*/
+type DataService struct {
+	ring    *Ring
+	clients map[string]*client.Client
+}
+
+// NewDataService coordinates the nodes, given as host:port
+func NewDataService(nodes []string) (*DataService, error) {
+	if len(nodes) == 0 {
+		return nil, errors.New("A cluster requires at least one node")
+	}
+	s := &DataService{
+		ring:    NewRing(nodes, DefaultReplicas),
+		clients: make(map[string]*client.Client),
+	}
+	for _, node := range s.ring.Nodes() {
+		cl, err := client.NewClient("http://" + node)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid cluster node %s: %v", node, err)
+		}
+		s.clients[node] = cl
+	}
+	return s, nil
+}
+
+// NewServer returns the RPC server of a coordinator of the nodes, to be
+// served on /rpc in place of the one of frontend.NewServer
+func NewServer(nodes []string) (*frontend.RpcServer, *DataService, error) {
+	service, err := NewDataService(nodes)
+	if err != nil {
+		return nil, nil, err
+	}
+	s := &frontend.RpcServer{
+		Server: rpc.NewServer(),
+	}
+	s.RegisterCodec(json2.NewCodec(), "application/json")
+	s.RegisterCodec(json2.NewCodec(), "application/json;charset=UTF-8")
+	s.RegisterCodec(msgpack2.NewCodec(), "application/x-msgpack")
+	if err = s.RegisterService(service, "DataService"); err != nil {
+		return nil, nil, err
+	}
+	return s, service, nil
+}
+
+// Owner returns the node owning the symbol
+func (s *DataService) Owner(symbol string) string {
+	return s.ring.Owner(symbol)
+}
+
+func (s *DataService) Query(r *http.Request, reqs *frontend.MultiQueryRequest, response *frontend.MultiQueryResponse) (err error) {
+	response.Version = utils.GitHash
+	response.Timezone = utils.InstanceConfig.Timezone.String()
+
+	// Each node gets the parts of the requests it owns, in one call
+	type batch struct {
+		requests frontend.MultiQueryRequest
+		indexes  []int
+	}
+	batches := make(map[string]*batch)
+	for i, req := range reqs.Requests {
+		if req.IsSQLStatement {
+			return errors.New("SQL statements are not supported by a cluster coordinator")
+		}
+		if req.PageSize != nil || req.Cursor != "" {
+			return errors.New("Paged queries are not supported by a cluster coordinator")
+		}
+		if err = frontend.CheckFormat(req.Format); err != nil {
+			return err
+		}
+		// The results are merged before being encoded in the requested format
+		req.Format = ""
+		for node, part := range s.splitQuery(req) {
+			b := batches[node]
+			if b == nil {
+				b = &batch{}
+				batches[node] = b
+			}
+			b.requests.Requests = append(b.requests.Requests, part)
+			b.indexes = append(b.indexes, i)
+		}
+	}
+	var calls []*call
+	for _, node := range s.ring.Nodes() {
+		if b := batches[node]; b != nil {
+			calls = append(calls, &call{node: node, args: &b.requests, reply: &frontend.MultiQueryResponse{}})
+		}
+	}
+	s.do("Query", calls)
+
+	results := make([]io.ColumnSeriesMap, len(reqs.Requests))
+	fromOwner := make([]map[io.TimeBucketKey]bool, len(reqs.Requests))
+	for i := range results {
+		results[i] = io.NewColumnSeriesMap()
+		fromOwner[i] = make(map[io.TimeBucketKey]bool)
+	}
+	for _, c := range calls {
+		if c.err != nil {
+			return c.err
+		}
+		b := batches[c.node]
+		reply := c.reply.(*frontend.MultiQueryResponse)
+		if len(reply.Responses) != len(b.indexes) {
+			return fmt.Errorf("Node %s returned %d responses for %d queries",
+				c.node, len(reply.Responses), len(b.indexes))
+		}
+		for j, qr := range reply.Responses {
+			if qr.Result == nil {
+				continue
+			}
+			csm, err := qr.Result.ToColumnSeriesMap()
+			if err != nil {
+				return err
+			}
+			// A node may still hold the symbols it no longer owns, which are
+			// returned unless their owner returns them too
+			i := b.indexes[j]
+			for tbk, cs := range csm {
+				isOwner := s.owner(&tbk) == c.node
+				if _, found := results[i][tbk]; !found || (isOwner && !fromOwner[i][tbk]) {
+					results[i][tbk] = cs
+					fromOwner[i][tbk] = isOwner
+				}
+			}
+		}
+	}
+	for i, csm := range results {
+		nmds, err := newDataset(csm)
+		if err != nil {
+			return err
+		}
+		resp := frontend.QueryResponse{Result: nmds}
+		if err = resp.EncodeFormat(reqs.Requests[i].Format); err != nil {
+			return err
+		}
+		response.Responses = append(response.Responses, resp)
+	}
+	return nil
+}
+
+/*
+splitQuery returns the part of a query each node answers, the ones of the
+symbols it owns. Queries of all the symbols, through the "*" symbol, go to
+every node, which expands it to the symbols it holds.
+*/
+func (s *DataService) splitQuery(req frontend.QueryRequest) map[string]frontend.QueryRequest {
+	parts := make(map[string]frontend.QueryRequest)
+	dest := io.NewTimeBucketKey(req.Destination, req.KeyCategory)
+	symbols := symbolsOf(dest)
+	if len(symbols) == 0 || (len(symbols) == 1 && symbols[0] == "*") {
+		for _, node := range s.ring.Nodes() {
+			parts[node] = req
+		}
+		return parts
+	}
+	owned := make(map[string][]string)
+	for _, symbol := range symbols {
+		node := s.ring.Owner(symbol)
+		owned[node] = append(owned[node], symbol)
+	}
+	for node, symbols := range owned {
+		part := req
+		part.Destination = withSymbols(dest, symbols)
+		parts[node] = part
+	}
+	return parts
+}
+
+func (s *DataService) ListSymbols(r *http.Request, args *frontend.ListSymbolsArgs, response *frontend.ListSymbolsResponse) (err error) {
+	var calls []*call
+	for _, node := range s.ring.Nodes() {
+		calls = append(calls, &call{node: node, args: &frontend.ListSymbolsArgs{}, reply: &frontend.ListSymbolsResponse{}})
+	}
+	s.do("ListSymbols", calls)
+	listed := make(map[string]bool)
+	for _, c := range calls {
+		if c.err != nil {
+			return c.err
+		}
+		// Including the symbols held by a node that no longer owns them
+		for _, symbol := range c.reply.(*frontend.ListSymbolsResponse).Results {
+			if !listed[symbol] {
+				listed[symbol] = true
+				response.Results = append(response.Results, symbol)
+			}
+		}
+	}
+	sort.Strings(response.Results)
+	return nil
+}
+
+// Write sends the series of each request to the nodes owning their symbols.
+// The errors of the nodes are returned as the ones of a node would be
+func (s *DataService) Write(r *http.Request, reqs *frontend.MultiWriteRequest, response *frontend.MultiServerResponse) (err error) {
+	batches := make(map[string]*frontend.MultiWriteRequest)
+	for _, req := range reqs.Requests {
+		if req.Data == nil {
+			response.Responses = append(response.Responses, serverResponse(errors.New("Write request without a dataset")))
+			continue
+		}
+		csm, err := req.Data.ToColumnSeriesMap()
+		if err != nil {
+			response.Responses = append(response.Responses, serverResponse(err))
+			continue
+		}
+		parts := make(map[string]io.ColumnSeriesMap)
+		for tbk, cs := range csm {
+			node := s.owner(&tbk)
+			if parts[node] == nil {
+				parts[node] = io.NewColumnSeriesMap()
+			}
+			parts[node][tbk] = cs
+		}
+		for node, part := range parts {
+			nmds, err := newDataset(part)
+			if err != nil {
+				response.Responses = append(response.Responses, serverResponse(err))
+				continue
+			}
+			if nmds == nil {
+				continue
+			}
+			b := batches[node]
+			if b == nil {
+				b = &frontend.MultiWriteRequest{Sync: reqs.Sync}
+				batches[node] = b
+			}
+			b.Requests = append(b.Requests, frontend.WriteRequest{Data: nmds, IsVariableLength: req.IsVariableLength})
+		}
+	}
+	var calls []*call
+	for _, node := range s.ring.Nodes() {
+		if b := batches[node]; b != nil {
+			calls = append(calls, &call{node: node, args: b, reply: &frontend.MultiServerResponse{}})
+		}
+	}
+	s.do("Write", calls)
+	for _, c := range calls {
+		if c.err != nil {
+			response.Responses = append(response.Responses, serverResponse(c.err))
+			continue
+		}
+		response.Responses = append(response.Responses, c.reply.(*frontend.MultiServerResponse).Responses...)
+	}
+	return nil
+}
+
+func (s *DataService) Create(r *http.Request, reqs *frontend.MultiCreateRequest, response *frontend.MultiServerResponse) (err error) {
+	calls := make([]*call, len(reqs.Requests))
+	for i, req := range reqs.Requests {
+		calls[i] = &call{
+			node:  s.owner(parseKey(req.Key)),
+			args:  &frontend.MultiCreateRequest{Requests: []frontend.CreateRequest{req}},
+			reply: &frontend.MultiServerResponse{},
+		}
+	}
+	s.do("Create", calls)
+	for _, c := range calls {
+		if c.err != nil {
+			response.Responses = append(response.Responses, serverResponse(c.err))
+			continue
+		}
+		response.Responses = append(response.Responses, c.reply.(*frontend.MultiServerResponse).Responses...)
+	}
+	return nil
+}
+
+func (s *DataService) Destroy(r *http.Request, reqs *frontend.MultiKeyRequest, response *frontend.MultiServerResponse) (err error) {
+	calls := s.keyCalls(reqs, func() interface{} { return &frontend.MultiServerResponse{} })
+	s.do("Destroy", calls)
+	for _, c := range calls {
+		if c.err != nil {
+			response.Responses = append(response.Responses, serverResponse(c.err))
+			continue
+		}
+		response.Responses = append(response.Responses, c.reply.(*frontend.MultiServerResponse).Responses...)
+	}
+	return nil
+}
+
+func (s *DataService) GetInfo(r *http.Request, reqs *frontend.MultiKeyRequest, response *frontend.MultiGetInfoResponse) (err error) {
+	calls := s.keyCalls(reqs, func() interface{} { return &frontend.MultiGetInfoResponse{} })
+	s.do("GetInfo", calls)
+	for _, c := range calls {
+		if c.err != nil {
+			response.Responses = append(response.Responses, frontend.GetInfoResponse{ServerResp: serverResponse(c.err)})
+			continue
+		}
+		response.Responses = append(response.Responses, c.reply.(*frontend.MultiGetInfoResponse).Responses...)
+	}
+	return nil
+}
+
+func (s *DataService) AlterBucket(r *http.Request, reqs *frontend.MultiAlterRequest, response *frontend.MultiServerResponse) (err error) {
+	calls := make([]*call, len(reqs.Requests))
+	for i, req := range reqs.Requests {
+		calls[i] = &call{
+			node:  s.owner(parseKey(req.Key)),
+			args:  &frontend.MultiAlterRequest{Requests: []frontend.AlterRequest{req}},
+			reply: &frontend.MultiServerResponse{},
+		}
+	}
+	s.do("AlterBucket", calls)
+	for _, c := range calls {
+		if c.err != nil {
+			response.Responses = append(response.Responses, serverResponse(c.err))
+			continue
+		}
+		response.Responses = append(response.Responses, c.reply.(*frontend.MultiServerResponse).Responses...)
+	}
+	return nil
+}
+
+/*
+Delete sends each request to the nodes owning its symbols, or to every node
+for the "*" symbol, and returns the records deleted by all of them along with
+their errors
+*/
+func (s *DataService) Delete(r *http.Request, reqs *frontend.MultiDeleteRequest, response *frontend.MultiDeleteResponse) (err error) {
+	// One batch per node, in the order of the requests, so that each node
+	// deletes in that order
+	batches := make(map[string]*frontend.MultiDeleteRequest)
+	indexes := make(map[string][]int)
+	for i, req := range reqs.Requests {
+		destination := req.Destination
+		if len(req.Symbols) != 0 {
+			if parts := strings.SplitN(destination, "/", 2); len(parts) == 2 {
+				destination = strings.Join(req.Symbols, ",") + "/" + parts[1]
+			}
+		}
+		for node, part := range s.splitQuery(frontend.QueryRequest{Destination: destination}) {
+			b := batches[node]
+			if b == nil {
+				b = &frontend.MultiDeleteRequest{}
+				batches[node] = b
+			}
+			nodeReq := req
+			nodeReq.Destination, nodeReq.Symbols = part.Destination, nil
+			b.Requests = append(b.Requests, nodeReq)
+			indexes[node] = append(indexes[node], i)
+		}
+	}
+	var calls []*call
+	for _, node := range s.ring.Nodes() {
+		if b := batches[node]; b != nil {
+			calls = append(calls, &call{node: node, args: b, reply: &frontend.MultiDeleteResponse{}})
+		}
+	}
+	s.do("Delete", calls)
+
+	// The counts and errors of each node are split back per request
+	response.Responses = make([]frontend.DeleteResponse, len(reqs.Requests))
+	errs := make([][]string, len(reqs.Requests))
+	for _, c := range calls {
+		nodeIndexes := indexes[c.node]
+		if c.err != nil {
+			for _, i := range nodeIndexes {
+				errs[i] = append(errs[i], c.err.Error())
+			}
+			continue
+		}
+		for j, dr := range c.reply.(*frontend.MultiDeleteResponse).Responses {
+			if j >= len(nodeIndexes) {
+				break
+			}
+			i := nodeIndexes[j]
+			response.Responses[i].Deleted += dr.Deleted
+			if dr.ServerResp.Error != "" {
+				errs[i] = append(errs[i], fmt.Sprintf("Node %s: %s", c.node, dr.ServerResp.Error))
+			}
+		}
+	}
+	for i := range response.Responses {
+		response.Responses[i].ServerResp = frontend.ServerResponse{
+			Error:   strings.Join(errs[i], "; "),
+			Version: utils.GitHash,
+		}
+	}
+	return nil
+}
+
+func (s *DataService) Compact(r *http.Request, reqs *frontend.MultiKeyRequest, response *frontend.MultiCompactResponse) (err error) {
+	calls := s.keyCalls(reqs, func() interface{} { return &frontend.MultiCompactResponse{} })
+	s.do("Compact", calls)
+	for _, c := range calls {
+		if c.err != nil {
+			response.Responses = append(response.Responses, frontend.CompactResponse{ServerResp: serverResponse(c.err)})
+			continue
+		}
+		response.Responses = append(response.Responses, c.reply.(*frontend.MultiCompactResponse).Responses...)
+	}
+	return nil
+}
+
+// Backup is rejected, as the nodes are backed up each on its own
+func (s *DataService) Backup(r *http.Request, req *frontend.BackupRequest, response *frontend.BackupResponse) (err error) {
+	return errors.New("Backups are not supported by a cluster coordinator, back up each node instead")
+}
+
+// keyCalls sends each key to the node owning its symbol, one call per key to
+// keep the responses in the order of the requests
+func (s *DataService) keyCalls(reqs *frontend.MultiKeyRequest, reply func() interface{}) []*call {
+	calls := make([]*call, len(reqs.Requests))
+	for i, req := range reqs.Requests {
+		calls[i] = &call{
+			node:  s.owner(parseKey(req.Key)),
+			args:  &frontend.MultiKeyRequest{Requests: []frontend.KeyRequest{req}},
+			reply: reply(),
+		}
+	}
+	return calls
+}
+
+// owner returns the node owning the symbol of a key. The keys without a
+// symbol go to a node all the same, for it to reject them
+func (s *DataService) owner(tbk *io.TimeBucketKey) string {
+	symbols := symbolsOf(tbk)
+	if len(symbols) == 0 {
+		return s.ring.Owner("")
+	}
+	return s.ring.Owner(symbols[0])
+}
+
+type call struct {
+	node  string
+	args  interface{}
+	reply interface{}
+	err   error
+}
+
+// do makes the calls to the nodes concurrently
+func (s *DataService) do(method string, calls []*call) {
+	var wg sync.WaitGroup
+	for _, c := range calls {
+		wg.Add(1)
+		go func(c *call) {
+			defer wg.Done()
+			if err := s.clients[c.node].Call(method, c.args, c.reply); err != nil {
+				c.err = fmt.Errorf("Node %s: %v", c.node, err)
+			}
+		}(c)
+	}
+	wg.Wait()
+}
+
+/*
+Utility functions
+*/
+
+// parseKey builds the TimeBucketKey of a key request, where the schema is
+// optional
+func parseKey(key string) *io.TimeBucketKey {
+	parts := strings.Split(key, ":")
+	if len(parts) < 2 {
+		parts = append(parts, "")
+	}
+	return io.NewTimeBucketKey(parts[0], parts[1])
+}
+
+// symbolsOf returns the comma separated symbols of a key, or nil if it has
+// none
+func symbolsOf(tbk *io.TimeBucketKey) []string {
+	items := tbk.GetItems()
+	for i, category := range tbk.GetCategories() {
+		if category == "Symbol" && i < len(items) && items[i] != "" {
+			return strings.Split(items[i], ",")
+		}
+	}
+	return nil
+}
+
+// withSymbols returns the item key of a key with its symbols replaced
+func withSymbols(tbk *io.TimeBucketKey, symbols []string) string {
+	items := tbk.GetItems()
+	for i, category := range tbk.GetCategories() {
+		if category == "Symbol" && i < len(items) {
+			items[i] = strings.Join(symbols, ",")
+		}
+	}
+	return strings.Join(items, "/")
+}
+
+// newDataset packs the series of a ColumnSeriesMap, sorted by key, or
+// returns nil if they are all empty
+func newDataset(csm io.ColumnSeriesMap) (nmds *io.NumpyMultiDataset, err error) {
+	keys := make([]io.TimeBucketKey, 0, len(csm))
+	for tbk, cs := range csm {
+		if cs.Len() > 0 {
+			keys = append(keys, tbk)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+	for _, tbk := range keys {
+		if nmds == nil {
+			nds, err := io.NewNumpyDataset(csm[tbk])
+			if err != nil {
+				return nil, err
+			}
+			if nmds, err = io.NewNumpyMultiDataset(nds, tbk); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err = nmds.Append(csm[tbk], tbk); err != nil {
+			return nil, err
+		}
+	}
+	return nmds, nil
+}
+
+func serverResponse(err error) frontend.ServerResponse {
+	return frontend.ServerResponse{Error: err.Error(), Version: utils.GitHash}
+}
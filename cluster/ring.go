@@ -0,0 +1,81 @@
+/*
+Package cluster shards the symbols across several marketstore nodes.
+
+A coordinator assigns each symbol to a node with a consistent hash ring, so
+that adding or removing a node only moves the symbols of its neighbours on the
+ring. It serves the RPC API of a node: the writes are routed to the nodes
+owning their symbols, and the queries are fanned out to them, their results
+being merged into one response. A symbol is only read from the node owning
+it, and changing the nodes does not move the data already written.
+*/
+package cluster
+
+import (
+	"hash/crc32"
+	"sort"
+	"strconv"
+)
+
+// DefaultReplicas is the number of points of each node on the ring, which
+// spread the symbols evenly across the nodes
+const DefaultReplicas = 128
+
+// Ring is a consistent hash ring of nodes
+type Ring struct {
+	nodes  []string
+	points []uint32
+	owners map[uint32]string
+}
+
+// NewRing places each node at replicas points of the ring
+func NewRing(nodes []string, replicas int) *Ring {
+	r := &Ring{owners: make(map[uint32]string)}
+	for _, node := range nodes {
+		if r.has(node) {
+			continue
+		}
+		r.nodes = append(r.nodes, node)
+		for i := 0; i < replicas; i++ {
+			point := crc32.ChecksumIEEE([]byte(node + "#" + strconv.Itoa(i)))
+			// The lowest node name wins the rare collisions, so that the
+			// owners do not depend on the order of the nodes
+			if owner, ok := r.owners[point]; ok && owner < node {
+				continue
+			}
+			if _, ok := r.owners[point]; !ok {
+				r.points = append(r.points, point)
+			}
+			r.owners[point] = node
+		}
+	}
+	sort.Slice(r.points, func(i, j int) bool { return r.points[i] < r.points[j] })
+	return r
+}
+
+func (r *Ring) has(node string) bool {
+	for _, n := range r.nodes {
+		if n == node {
+			return true
+		}
+	}
+	return false
+}
+
+// Nodes returns the nodes of the ring, in the order they were given
+func (r *Ring) Nodes() []string {
+	return r.nodes
+}
+
+// Owner returns the node owning the symbol, the first one following its
+// point on the ring, or an empty string if the ring has no node
+func (r *Ring) Owner(symbol string) string {
+	if len(r.points) == 0 {
+		return ""
+	}
+	point := crc32.ChecksumIEEE([]byte(symbol))
+	i := sort.Search(len(r.points), func(i int) bool { return r.points[i] >= point })
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.owners[r.points[i]]
+}
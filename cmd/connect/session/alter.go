@@ -0,0 +1,71 @@
+package session
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alpacahq/marketstore/frontend"
+)
+
+// alter adds, drops and renames the columns of a bucket, and changes its write policy.
+func (c *Client) alter(line string) {
+	args := strings.Split(line, " ")
+	args = args[1:] // chop off the first word which should be "alter"
+	if len(args) < 3 || len(args)%2 != 1 {
+		fmt.Println("Not enough arguments - need \"alter key [add shapes] [drop columns] [rename old=new] [policy name]\"")
+		return
+	}
+
+	req := frontend.AlterRequest{Key: args[0]}
+	for i := 1; i < len(args); i += 2 {
+		switch strings.ToLower(args[i]) {
+		case "add":
+			req.AddShapes = args[i+1]
+		case "drop":
+			req.DropColumns = strings.Split(args[i+1], ",")
+		case "rename":
+			req.RenameColumns = make(map[string]string)
+			for _, pair := range strings.Split(args[i+1], ",") {
+				names := strings.Split(pair, "=")
+				if len(names) != 2 {
+					fmt.Printf("Rename \"%s\" is not in the format old=new\n", pair)
+					return
+				}
+				req.RenameColumns[names[0]] = names[1]
+			}
+		case "policy":
+			req.WritePolicy = args[i+1]
+		default:
+			fmt.Printf("Unknown alteration \"%s\", should be one of add, drop, rename or policy\n", args[i])
+			return
+		}
+	}
+
+	reqs := &frontend.MultiAlterRequest{
+		Requests: []frontend.AlterRequest{req},
+	}
+	responses := &frontend.MultiServerResponse{}
+	var err error
+	if c.mode == local {
+		ds := frontend.DataService{}
+		err = ds.AlterBucket(nil, reqs, responses)
+	} else {
+		var respI interface{}
+		respI, err = c.rc.DoRPC("AlterBucket", reqs)
+		if respI != nil {
+			responses = respI.(*frontend.MultiServerResponse)
+		}
+	}
+	if err != nil {
+		fmt.Printf("Failed with error: %s\n", err.Error())
+		return
+	}
+
+	for _, resp := range responses.Responses {
+		if len(resp.Error) != 0 {
+			fmt.Printf("Failed with error: %s\n", resp.Error)
+			return
+		}
+	}
+	fmt.Printf("Successfully altered bucket %s\n", args[0])
+}
@@ -157,6 +157,14 @@ EVAL:
 			c.create(line)
 		case strings.HasPrefix(line, "\\destroy"):
 			c.destroy(line)
+		case strings.HasPrefix(line, "\\alter"):
+			c.alter(line)
+		case strings.HasPrefix(line, "\\compact"):
+			c.compact(line)
+		case strings.HasPrefix(line, "\\export"):
+			c.exportParquet(line)
+		case strings.HasPrefix(line, "\\import"):
+			c.importParquet(line)
 		case strings.HasPrefix(line, "\\getinfo"):
 			c.getinfo(line)
 		case strings.HasPrefix(line, "\\help") || strings.HasPrefix(line, "\\?"):
@@ -190,8 +198,12 @@ func newReader() (*readline.Instance, error) {
 	autoComplete := readline.NewPrefixCompleter(
 		readline.PcItem("\\show"),
 		readline.PcItem("\\load"),
+		readline.PcItem("\\export"),
+		readline.PcItem("\\import"),
 		readline.PcItem("\\create"),
 		readline.PcItem("\\trim"),
+		readline.PcItem("\\compact"),
+		readline.PcItem("\\alter"),
 		readline.PcItem("\\help"),
 		readline.PcItem("\\exit"),
 		readline.PcItem("\\quit"),
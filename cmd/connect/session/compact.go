@@ -0,0 +1,47 @@
+package session
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alpacahq/marketstore/frontend"
+)
+
+// compact frees the disk space of the empty intervals of a bucket.
+func (c *Client) compact(line string) {
+	args := strings.Split(line, " ")
+	args = args[1:] // chop off the first word which should be "compact"
+	if len(args) < 1 {
+		fmt.Println("Not enough arguments - need \"compact key\"")
+		return
+	}
+
+	req := frontend.KeyRequest{Key: args[0]}
+	reqs := &frontend.MultiKeyRequest{
+		Requests: []frontend.KeyRequest{req},
+	}
+	responses := &frontend.MultiCompactResponse{}
+	var err error
+	if c.mode == local {
+		ds := frontend.DataService{}
+		err = ds.Compact(nil, reqs, responses)
+	} else {
+		var respI interface{}
+		respI, err = c.rc.DoRPC("Compact", reqs)
+		if respI != nil {
+			responses = respI.(*frontend.MultiCompactResponse)
+		}
+	}
+	if err != nil {
+		fmt.Printf("Failed with error: %s\n", err.Error())
+		return
+	}
+
+	for _, resp := range responses.Responses {
+		if len(resp.ServerResp.Error) != 0 {
+			fmt.Printf("Failed with error: %s\n", resp.ServerResp.Error)
+			return
+		}
+		fmt.Printf("Compacted %s: freed %d bytes, %d bytes used\n", args[0], resp.FreedBytes, resp.UsedBytes)
+	}
+}
@@ -53,8 +53,12 @@ func (c *Client) getinfo(line string) {
 	*/
 	// Print out the bucket information we obtained
 	fmt.Printf("Bucket: %s\n", args[0])
-	fmt.Printf("Latest Year: %v, RecordType: %v, TF: %v\n",
-		resp.LatestYear, resp.RecordType.String(), resp.TimeFrame)
+	fmt.Printf("Latest Year: %v, RecordType: %v, Compression: %v, TF: %v, Used Bytes: %v, Schema Version: %v, Write Policy: %v\n",
+		resp.LatestYear, resp.RecordType.String(), resp.Compression.String(), resp.TimeFrame, resp.UsedBytes,
+		resp.SchemaVersion, resp.WritePolicy.String())
+	if resp.RecordType == io.VARIABLE {
+		fmt.Printf("Timestamp Format: %v\n", resp.TimestampFormat.String())
+	}
 	fmt.Printf("Data Types: {")
 	for i, shape := range resp.DSV {
 		fmt.Printf("%s", shape.String())
@@ -71,6 +75,15 @@ func (c *Client) create(line string) {
 	args = args[1:] // chop off the first word which should be "create"
 
 	req := frontend.CreateRequest{Key: args[0], DataShapes: args[1], RowType: args[2]}
+	if len(args) > 3 {
+		req.Compression = args[3]
+	}
+	if len(args) > 4 {
+		req.WritePolicy = args[4]
+	}
+	if len(args) > 5 {
+		req.TimestampFormat = args[5]
+	}
 	reqs := &frontend.MultiCreateRequest{
 		Requests: []frontend.CreateRequest{req},
 	}
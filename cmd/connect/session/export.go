@@ -0,0 +1,239 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alpacahq/marketstore/frontend"
+	"github.com/alpacahq/marketstore/utils/io"
+	"github.com/alpacahq/marketstore/utils/parquet"
+)
+
+// exportPageSize is the number of rows of each bucket queried at a time when
+// exporting
+const exportPageSize = 100000
+
+// exportParquet writes buckets to Parquet files.
+func (c *Client) exportParquet(line string) {
+	args := strings.Split(line, " ")
+	args = args[1:] // chop off the first word which should be "export"
+	if len(args) < 2 {
+		fmt.Println("Not enough arguments, see \"\\help export\" ")
+		return
+	}
+	var start, end *time.Time
+	if len(args) > 2 {
+		_, start, end = c.parseQueryArgs(append(args[:1:1], args[2:]...))
+		if start == nil {
+			fmt.Println("Could not parse the time range, see \"\\help export\" ")
+			return
+		}
+	}
+	files, err := c.Export(args[0], args[1], start, end)
+	if err != nil {
+		fmt.Printf("Failed with error: %s\n", err.Error())
+		return
+	}
+	fmt.Printf("Exported %d files to %s\n", len(files), args[1])
+}
+
+// importParquet writes the rows of Parquet files to buckets.
+func (c *Client) importParquet(line string) {
+	args := strings.Split(line, " ")
+	args = args[1:] // chop off the first word which should be "import"
+	if len(args) < 2 {
+		fmt.Println("Not enough arguments, see \"\\help import\" ")
+		return
+	}
+	files, rows, err := c.Import(args[0], args[1])
+	if err != nil {
+		fmt.Printf("Failed with error: %s\n", err.Error())
+		return
+	}
+	fmt.Printf("Imported %d rows from %d files in %s\n", rows, files, args[1])
+}
+
+/*
+Export writes the buckets of a key, e.g. AAPL,MSFT/1Min/OHLCV, or of every
+symbol when the symbol of the key is "*", to Parquet files partitioned by
+Symbol and year under dir, between the optional start and end times. The data
+is queried a page at a time, so that buckets of any size can be exported. It
+returns the paths of the files.
+*/
+func (c *Client) Export(key, dir string, start, end *time.Time) ([]string, error) {
+	tbk := io.NewTimeBucketKey(key)
+	if tbk == nil {
+		return nil, fmt.Errorf("Key %s is not in proper format, e.g. AAPL/1Min/OHLCV", key)
+	}
+	pageSize := exportPageSize
+	req := frontend.QueryRequest{Destination: tbk.String(), PageSize: &pageSize}
+	if start != nil {
+		epochStart := start.Unix()
+		req.EpochStart = &epochStart
+	}
+	if end != nil {
+		epochEnd := end.Unix()
+		req.EpochEnd = &epochEnd
+	}
+
+	exporter := parquet.NewExporter(dir)
+	metadata := make(map[io.TimeBucketKey]parquet.Metadata)
+	write := func(resp *frontend.QueryResponse) error {
+		if resp.Result == nil {
+			return nil
+		}
+		csm, err := resp.Result.ToColumnSeriesMap()
+		if err != nil {
+			return err
+		}
+		for tbk, cs := range csm {
+			md, ok := metadata[tbk]
+			if !ok {
+				info, err := c.GetBucketInfo(tbk)
+				if err != nil {
+					return err
+				}
+				md = parquet.Metadata{
+					Key:             tbk.GetItemKey(),
+					RecordType:      info.RecordType,
+					TimestampFormat: info.TimestampFormat,
+				}
+				metadata[tbk] = md
+			}
+			if err = exporter.Write(tbk, cs, md); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for {
+		response, err := c.query(&frontend.MultiQueryRequest{Requests: []frontend.QueryRequest{req}})
+		if err == nil && len(response.Responses) != 1 {
+			err = errors.New("Query returned no response")
+		}
+		if err == nil {
+			err = write(&response.Responses[0])
+		}
+		if err != nil {
+			exporter.Close()
+			return nil, err
+		}
+		if response.Responses[0].Cursor == "" {
+			break
+		}
+		req.Cursor = response.Responses[0].Cursor
+	}
+	return exporter.Close()
+}
+
+/*
+Import writes the Parquet files partitioned by Symbol and year under dir to the
+buckets of a key, e.g. AAPL,MSFT/1Min/OHLCV, or of every symbol of the files
+when the symbol of the key is "*". A missing bucket is created with the columns
+of the files, and the record type and timestamp format they were exported from.
+It returns the number of files and rows imported.
+*/
+func (c *Client) Import(key, dir string) (files, rows int, err error) {
+	tbk := io.NewTimeBucketKey(key)
+	if tbk == nil {
+		return 0, 0, fmt.Errorf("Key %s is not in proper format, e.g. AAPL/1Min/OHLCV", key)
+	}
+	symbols := make(map[string]bool)
+	for _, symbol := range tbk.GetMultiItemInCategory("Symbol") {
+		symbols[symbol] = true
+	}
+	parts, err := parquet.Partitions(dir)
+	if err != nil {
+		return 0, 0, err
+	}
+	created := make(map[io.TimeBucketKey]bool)
+	for _, part := range parts {
+		if !symbols["*"] && !symbols[part.Symbol] {
+			continue
+		}
+		cs, md, err := parquet.Read(part.Path)
+		if err != nil {
+			return files, rows, err
+		}
+		dest := io.NewTimeBucketKey(key)
+		dest.SetItemInCategory("Symbol", part.Symbol)
+		variable := md.RecordType == io.VARIABLE || cs.GetByName("Nanoseconds") != nil
+		if !created[*dest] {
+			if err = c.ensureBucket(dest, cs, variable, md.TimestampFormat); err != nil {
+				return files, rows, err
+			}
+			created[*dest] = true
+		}
+		if cs.Len() != 0 {
+			nds, err := io.NewNumpyDataset(cs)
+			if err != nil {
+				return files, rows, err
+			}
+			nmds, err := io.NewNumpyMultiDataset(nds, *dest)
+			if err != nil {
+				return files, rows, err
+			}
+			if err = writeNumpy(c, nmds, variable); err != nil {
+				return files, rows, fmt.Errorf("Unable to write %s: %s", part.Path, err.Error())
+			}
+		}
+		files++
+		rows += cs.Len()
+	}
+	return files, rows, nil
+}
+
+// ensureBucket creates a bucket with the columns of a series if it does not
+// exist
+func (c *Client) ensureBucket(tbk *io.TimeBucketKey, cs *io.ColumnSeries, variable bool,
+	timestampFormat io.EnumTimestampFormat) error {
+	if _, err := c.GetBucketInfo(*tbk); err == nil {
+		return nil
+	}
+	var shapes []string
+	for _, shape := range cs.GetDataShapes() {
+		if shape.Name == "Epoch" || (variable && shape.Name == "Nanoseconds") {
+			continue
+		}
+		shapes = append(shapes, shape.Name+"/"+strings.ToLower(shape.Type.String()))
+	}
+	req := frontend.CreateRequest{
+		Key:        tbk.String(),
+		DataShapes: strings.Join(shapes, ":"),
+		RowType:    "fixed",
+	}
+	if variable {
+		req.RowType = "variable"
+		req.TimestampFormat = timestampFormat.String()
+	}
+	reqs := &frontend.MultiCreateRequest{Requests: []frontend.CreateRequest{req}}
+	responses := &frontend.MultiServerResponse{}
+	var err error
+	if c.mode == local {
+		ds := frontend.DataService{}
+		err = ds.Create(nil, reqs, responses)
+	} else {
+		err = c.rc.Call("Create", reqs, responses)
+	}
+	if err != nil {
+		return err
+	}
+	for _, resp := range responses.Responses {
+		if len(resp.Error) != 0 {
+			return fmt.Errorf("Unable to create %s: %s", tbk.GetItemKey(), resp.Error)
+		}
+	}
+	return nil
+}
+
+// query executes the query requests in local or remote mode
+func (c *Client) query(reqs *frontend.MultiQueryRequest) (*frontend.MultiQueryResponse, error) {
+	response := &frontend.MultiQueryResponse{}
+	if c.mode == local {
+		ds := frontend.DataService{}
+		return response, ds.Query(nil, reqs, response)
+	}
+	return response, c.rc.Call("Query", reqs, response)
+}
@@ -21,7 +21,7 @@ func (c *Client) functionHelp(line string) {
 		fmt.Println(`
 		Usage: \help command_name
 
-		Available commands: o, timing, show, trim, gaps, load, create, destroy, feed`)
+		Available commands: o, timing, show, trim, gaps, load, export, import, create, destroy, alter, compact, feed`)
 
 	case "o":
 		fmt.Println(`
@@ -75,11 +75,51 @@ func (c *Client) functionHelp(line string) {
 		specify the epoch-date and epoch-time columns in the columnNameMap
 	`)
 
+	case "alter":
+		fmt.Println(`
+		The alter command changes the columns of an existing bucket, rewriting all of its year files.
+		Added columns are appended and filled with zeros, renamed columns keep their position.
+		It also changes the write policy of a fixed bucket, described in \help create.
+		Syntax:
+			>> \alter <Symbol/Timeframe/RecordFormat> [add <row-data-shape>] [drop <name1,name2>] [rename <old1=new1,old2=new2>] [policy <write-policy>]
+		- Example: add a trade count, drop the high and rename the volume:
+			>> \alter TSLA/1Min/OHLCV add Trades/int32 drop High rename Volume=Vol
+		- Example: merge the bars written more than once:
+			>> \alter TSLA/1Min/OHLCV policy merge`)
+
+	case "export", "import":
+		fmt.Println(`
+		The export command writes buckets to Parquet files for data lakes, with one column per data shape,
+		the Epoch and the Nanoseconds of variable rows included. The files are partitioned by symbol and year,
+		as in <directory>/Symbol=TSLA/year=2016/data.parquet, and keep the record type of their bucket.
+		Syntax:
+			>> \export <Symbol/Timeframe/RecordFormat> <directory> [<start time> [<end time>]]
+		- Example: export every symbol:
+			>> \export */1Min/OHLCV /data/lake/1Min/OHLCV
+		- Example: export 2016 only:
+			>> \export TSLA,AAPL/1Min/OHLCV /data/lake/1Min/OHLCV 2016-01-01 2016-12-31T23:59
+
+		The import command writes the files of a directory partitioned as above to the buckets of their symbols,
+		creating the missing buckets with the columns of the files.
+		Syntax:
+			>> \import <Symbol/Timeframe/RecordFormat> <directory>
+		- Example: import every symbol:
+			>> \import */1Min/OHLCV /data/lake/1Min/OHLCV`)
+
+	case "compact":
+		fmt.Println(`
+		The compact command frees the disk space of the intervals without data in a bucket, by
+		punching holes over them in the year files. The used bytes are also shown by \getinfo.
+		Syntax:
+			>> \compact <Symbol/Timeframe/RecordFormat>
+		- Example:
+			>> \compact TSLA/1Min/OHLCV`)
+
 	case "create", "destroy":
 		fmt.Println(`
 		The create command generates new subdirectories and buckets for a database, and requires specially formatted schema keys as arguments.
 		Syntax:
-			>> \create <full-schema-key> <row-data-shape> <row-type>
+			>> \create <full-schema-key> <row-data-shape> <row-type> [<compression>] [<write-policy>] [<timestamp-format>]
 		Example: We create a new DB entry to store 1 minute candles for TSLA:
 			>> \create TSLA/1Min/OHLCV:Symbol/Timeframe/AttributeGroup Open,High,Low,Close/float32:Volume/int32 fixed
 
@@ -105,7 +145,20 @@ func (c *Client) functionHelp(line string) {
 		<row-type>: The type of rows to be stored, one of "fixed" or "variable":
 		- Example: We are storing tick data, where each time interval can contain a variable
 		number of rows:
-			<row-type> = variable`)
+			<row-type> = variable
+
+		<compression>: Optional storage format of fixed rows, one of "none" (the default) or "columnar".
+		Columnar buckets are stored in compressed blocks and are read the same way:
+			>> \create TSLA/1Min/OHLCV:Symbol/Timeframe/AttributeGroup Open,High,Low,Close/float32:Volume/int32 fixed columnar
+
+		<write-policy>: Optional handling of rows written at the interval of an existing fixed row, one of
+		"overwrite" (the default), "keep-first", "reject" or "merge". Merged rows keep the first Open,
+		the highest High, the lowest Low and the total Volume, and take the other columns from the new row:
+			>> \create TSLA/1Min/OHLCV:Symbol/Timeframe/AttributeGroup Open,High,Low,Close/float32:Volume/int32 fixed none merge
+
+		<timestamp-format>: Optional storage of the timestamps of variable rows, one of "ticks" (the default)
+		or "nanoseconds". Ticks are 32-bit fractions of the interval, nanoseconds keep the exact time of each row:
+			>> \create TSLA/1Min/TICK:Symbol/Timeframe/AttributeGroup Bid,Ask/float32 variable none overwrite nanoseconds`)
 
 	default:
 		fmt.Printf("No help available for %s\n", helpKey)
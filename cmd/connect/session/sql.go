@@ -65,8 +65,13 @@ func (c *Client) remoteSQL(line string) (cs *io.ColumnSeries, err error) {
 		return nil, err
 	}
 
-	for _, sub := range *resp.(*io.ColumnSeriesMap) {
+	csm := *resp.(*io.ColumnSeriesMap)
+	for key, sub := range csm {
 		cs = sub
+		// Results over several symbols index the symbols listed in the key
+		if symbols := key.GetMultiItemInCategory("Symbol"); len(symbols) != 0 && cs.Exists("Symbol") {
+			err = sqlparser.ResolveSymbols(symbols, cs)
+		}
 		break
 	}
 	return cs, err
@@ -2,10 +2,12 @@ package session
 
 import (
 	"fmt"
+	stdio "io"
 	"os"
 	"strings"
 
 	"github.com/alpacahq/marketstore/executor"
+	"github.com/alpacahq/marketstore/executor/colfile"
 	"github.com/alpacahq/marketstore/utils/io"
 	"github.com/alpacahq/marketstore/utils/log"
 )
@@ -26,7 +28,15 @@ func (c *Client) trim(line string) {
 	for _, info := range fInfos {
 		if info.Year == int16(trimDate.Year()) {
 			offset := io.TimeToOffset(trimDate, info.GetTimeframe(), info.GetRecordLength())
-			fp, err := os.OpenFile(info.Path, os.O_CREATE|os.O_RDWR, 0600)
+			var fp interface {
+				stdio.WriteSeeker
+				stdio.Closer
+			}
+			if info.GetCompression() != io.NOCOMPRESSION {
+				fp, err = colfile.Open(info.Path, os.O_RDWR)
+			} else {
+				fp, err = os.OpenFile(info.Path, os.O_CREATE|os.O_RDWR, 0600)
+			}
 			if err != nil {
 				log.Error("Failed to open file %v - Error: %v", info.Path, err)
 				continue
@@ -68,7 +68,7 @@ func (fi bindataFileInfo) Sys() interface{} {
 	return nil
 }
 
-var _defaultYml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x94\x54\x4d\x6f\xdc\x46\x0c\xbd\xeb\x57\x10\xd2\xa5\x35\x20\xaf\xdc\xc6\x2d\x3c\x37\x27\x4d\xea\x83\x53\x1b\x70\x1b\x34\x27\x81\x92\x28\xed\x60\x47\x43\x85\x43\x79\xbd\x45\x7e\x7c\x31\x5a\xaf\xbd\x5f\x0e\x6c\x9d\x24\xbe\xa7\xf7\xf8\x31\x9c\x0c\xf2\xd7\x3e\x49\x06\x97\xa3\x72\xde\x91\x27\x41\xa5\x06\x7a\x94\x05\x69\x50\x16\x82\x9a\x7d\x6b\xbb\x51\x50\x2d\xfb\xd3\xe4\x6d\xba\xc2\xac\xd0\x58\xa1\x5a\x59\x56\xc0\x2d\xe8\x9c\xa0\x41\xc5\x0a\x03\x25\x11\x2e\x9f\x60\x33\x01\x49\x06\xce\x06\x25\x0f\x03\x8b\x42\x3e\xfd\x31\xbd\xd2\xc3\xc0\x81\x1a\xa8\x56\x3b\x2a\x10\x48\xee\x49\x92\xf5\x5f\x65\xa4\x1a\x38\xbf\xb8\xf8\x35\x2a\x71\x07\x8e\xee\xc9\xc1\x4f\xd6\xb7\xfc\x7d\x89\xe2\xbf\x93\x08\xcb\xcf\x89\xe3\xae\x9c\x30\x03\x11\x4b\x32\xf8\x36\x92\xac\xb0\x72\x04\x39\xa0\x73\xbc\x0c\xbb\x46\xca\x50\xd1\xc4\xb2\xd4\x80\xce\x85\xc7\x6e\x0e\x08\xb5\xb3\xe4\x35\x76\xca\x53\x1d\xdb\x94\x3c\x29\x19\x50\x19\x29\xc9\x92\xa0\x3c\x94\x9d\x60\x4d\xe5\x40\x62\xb9\x31\x50\x24\x59\xb2\x44\x57\x0a\x2b\x2a\x95\xd6\x2b\xc9\x3d\x3a\x03\xe7\x49\x96\x90\x8f\xbf\x97\xd8\x34\x4f\x12\x8f\x21\xa1\x9e\xef\xc9\x40\x8b\x2e\x6c\x85\x1d\x06\x2d\x17\x9e\x97\xfe\x19\xca\x40\x6d\x4f\xff\xb1\x27\x03\xe9\x65\x4f\x62\x6b\x9c\xfd\x45\xcb\xf2\x2b\xcb\x22\x9d\x08\x37\x43\x4c\x18\xdd\xa6\xe9\x73\x0e\x0a\x2d\xcb\x41\x7b\x37\x63\x29\x23\xc3\x40\xea\xb8\x46\x17\xdf\xd7\x3a\x1f\xa7\x2c\xa0\xa1\x6a\xec\x3a\xeb\x3b\x18\x06\xe1\x16\xd0\x37\x30\x27\x14\xad\x08\x15\xc8\x37\x03\x5b\xaf\x21\xc9\x60\x54\xeb\xac\x5a\x0a\xe5\x28\x6e\x5b\xcf\x9c\x5f\x5c\xbc\x4b\x93\x37\x9c\xb4\x68\xff\x80\xfd\xe0\x08\x54\x6c\xd7\x91\x40\xcf\xcd\xe8\x28\x4c\xa9\xfd\xe3\xf3\x9a\xfb\x3e\x8e\x48\x19\xd6\xed\x7a\xcb\x41\x5e\x37\x72\x2d\x1c\x4c\x92\x01\x40\xfe\x68\x60\x80\x7d\x63\xc3\x02\xbb\xee\x34\xf0\x04\x01\xb0\x37\x90\x9e\xcc\xce\x3e\x5b\x3f\xbb\xb9\xba\xfe\xf0\x25\x7d\x04\xd6\xab\x64\x1e\xbf\x00\x1a\x0a\x6a\xfd\xb4\x58\xe1\x39\x1a\xd5\xcf\x3f\x5b\xbf\x13\x38\x3b\x8c\x5c\xed\x7e\xfe\xb1\x97\x58\x50\x21\xec\x0f\xb2\x3a\x99\x9d\xbc\x94\x4e\x6b\x9d\x92\x18\xf0\x18\x1a\xfc\x96\x64\x50\x75\x4b\x96\xc5\x91\xa2\xbb\x06\x1f\x5a\xa2\x86\xe4\x59\xdf\x63\x4f\x06\xfe\x6c\xf0\xe1\x13\x69\x3d\x9f\x4e\xcc\x31\x97\x69\x35\xca\xa0\x18\xd7\x34\xfd\xa5\x38\xfb\x3d\x2f\x2e\xf2\xe2\x0c\x8a\xc2\x14\x45\xba\x5f\x85\x43\xab\xcf\x26\x1b\x9b\xbb\x18\xbe\x1b\xab\x50\x8b\xad\x9e\xac\x0e\xcd\xe2\xb3\x39\x74\x06\xb6\x8e\x58\x51\x14\x3b\x24\xe5\xc1\xd6\x06\x2a\x94\x50\xc6\xea\x76\x40\x54\x15\x5b\x8d\x4a\x65\x27\x3c\x0e\x06\xa6\xa9\xee\x50\xc2\x1c\x07\xda\xf5\xcd\x21\x87\x8f\x03\xd7\xf3\x9d\x68\x8c\x5b\xaf\xbf\xbd\x3b\xe0\xde\x0c\xe4\x0f\xa8\xad\x63\x3c\x46\xbe\xb2\xdd\xa1\xee\x4b\xe4\x6b\x5e\xbe\x9a\xfb\xc1\x71\xbc\x3a\x5e\xc9\xfe\xc2\x6e\xec\x7f\x4c\x7f\x9e\xe5\xc0\x6e\xd5\xb1\xdf\x9e\xe6\x66\x9e\xb7\x6b\x68\x2b\x7e\x6c\x92\x00\x38\xd8\x72\x41\x2b\x03\x2b\x1e\xa5\x7c\xfc\xda\xe3\xc4\x4b\x6b\x7d\xa7\xcc\x55\x87\x60\x66\x33\x1c\xec\xe9\xc6\xdc\xf2\x1e\x3d\xac\xfa\x8a\x5d\xd8\x77\x5a\x17\x72\x79\x79\x7b\x7d\x14\xb8\xbb\xfd\xba\x57\x5d\x65\xb5\xa7\x17\xb6\xe2\xfd\x84\x7d\x9a\xb0\x37\xac\xc5\xd9\xde\x5a\xbc\x90\x6e\x0e\xa7\xff\xbe\xff\xfb\x29\x30\xd5\x1f\xef\xfd\x56\x26\xf3\x34\x5e\x1d\x69\xf2\x7f\x00\x00\x00\xff\xff\x5a\x0d\xb7\x16\x11\x08\x00\x00")
+var _defaultYml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x95\x55\x4d\x6f\xe3\x36\x10\xbd\xeb\x57\x0c\x9c\x4b\x5b\xc0\xb6\xbc\xdd\xb4\x88\x6e\xd9\xed\x6e\x73\x48\x9b\x00\x69\x17\xdd\x93\x40\x49\x23\x8a\x30\xc5\xd1\x92\x54\x14\x17\xfb\xe3\x3b\xa4\xe2\x0f\xd9\x4e\x91\x48\x17\x69\xde\xe3\x9b\x2f\x0e\x79\x01\xf3\xd7\x3e\xc9\x05\x5c\xf7\x9e\xe6\x12\x0d\x5a\xe1\xb1\x82\x56\xd8\x35\x7a\xe7\xc9\x22\x94\x64\x6a\x25\x7b\x06\x14\x99\x05\x73\xdf\xa2\x6b\x89\x3c\x54\xca\x62\xc9\x5a\x1b\xa0\x1a\x7c\x83\x50\x09\x2f\x0a\xe1\x30\x09\x70\xbe\x83\xb3\x08\xf0\x2a\xad\x9c\x47\x03\x1d\x59\x0f\xf3\xb8\x22\x7e\xe2\x53\x47\x8e\xa3\x2b\x36\x13\x15\x70\x68\x1f\xd1\x26\xe3\xaa\x3c\x50\x33\xb8\xbc\xba\xfa\x39\x28\x91\x04\x8d\x8f\xa8\xe1\x07\x65\x6a\xfa\x3e\x08\x6b\xbe\xa3\xb5\x64\x7f\x4c\x18\xcb\x23\x96\x41\xc0\x98\xfd\xad\x47\xbb\x11\x85\x46\xf6\x2a\xb4\xa6\xc1\x4d\x1d\x79\x82\x02\x23\x4b\x71\x18\xbe\xb1\xd4\xcb\x06\x04\x94\x5a\xa1\xf1\xa1\x52\x86\x33\xe1\x32\x25\x3b\xa5\x0c\xbc\xed\x31\xb9\x48\xb8\x98\x5d\x2e\xad\x28\x31\xef\x78\x3d\x55\x19\xa4\x6c\x1e\x84\xce\x2d\x79\xae\x7a\xae\x8c\xe7\x44\x04\x87\x73\xc9\x00\x9a\xb0\x3c\x17\x55\xb5\x93\x78\x36\x59\x6c\xe9\x91\x85\x6b\xa1\xdd\x81\x59\x0b\xe7\xf3\xb5\xa1\xc1\xec\xa1\x0b\xf0\xaa\xc5\x7f\xc9\x30\x7d\x76\xdd\xb2\xdf\x52\x2c\xff\xc4\x21\xff\x4a\x76\x3d\x8b\x84\xbb\x2e\x04\x2c\xf4\xb6\xe8\x0d\x39\x0f\x35\xd9\x93\xf2\x6e\xdb\x92\x07\x06\xcb\x69\x2a\x85\x0e\xdf\xa3\xce\xa7\x18\x05\x54\x58\xf4\x52\x2a\x23\xa1\xeb\x2c\xb7\x5b\x98\x0a\x1a\x14\xd6\x17\x28\xb8\x83\xa6\xea\x88\xf3\x74\xbc\xa0\xf7\x4a\x2b\xaf\xd0\xe5\xbd\xd5\x87\x7a\x19\xf7\xee\xfd\x2c\x79\xc3\x4e\x0b\xee\x9f\x44\xdb\xb1\x7f\x6f\x95\x94\x68\xa1\xa5\xaa\xd7\xe8\x62\x68\x7f\x9b\x79\x49\x6d\x1b\x5a\xc4\x1d\x1c\xcb\xb5\x78\x93\x7c\x28\xe4\x28\xec\x32\xfe\x06\xde\x1f\xa3\x83\x0c\xc8\x54\xca\xad\x85\x94\x0b\x47\x11\x02\x36\x71\x3a\x3f\x2d\x57\x7f\x28\xb3\xbc\xbb\xb9\xfd\xf8\x65\xf6\x0c\x8c\xa3\x94\x3d\xff\x01\x17\xcb\x79\x65\xe2\x60\xb9\xbd\x35\xa8\x5f\xf2\xda\x89\x61\x75\x6a\xb9\x99\xfe\xfe\x76\x14\x98\xf3\x16\x45\x7b\x12\x15\xbf\x2f\x85\x53\x2b\xcd\x3b\x30\x03\x23\x5c\x25\xbe\xb1\xb9\x90\x03\xef\x93\x33\x49\xcb\x4a\x3c\xd5\x88\x15\xda\xbd\xbe\x11\x2d\x23\xbf\x33\xf2\x19\x7d\xd9\xc4\x1d\x73\xce\x4b\x1c\x8d\xdc\x79\x11\xc6\x74\xf6\x2e\x5d\xfd\x3a\x4f\xaf\xe6\xe9\x0a\xd2\x34\x4b\xd3\xd9\x71\x16\x5a\x28\xbf\x77\xb2\x75\xf3\x10\xcc\x0f\x7d\xe1\x4a\xab\x8a\x9d\xab\x53\x67\xe1\xd9\x6e\xba\x0c\x0e\xb6\x58\x9a\xa6\x13\x12\x4f\xa7\x2a\x33\x28\x84\x75\x79\xc8\x6e\x02\x0a\xcf\xdd\x2f\x7a\x9e\x51\xc9\x23\xdf\x65\x10\xbb\x3a\xa1\xb8\x46\x74\x38\xf5\x3b\xe7\xf7\x53\x47\x65\x33\xb1\x06\x3b\x07\xf3\xcb\xfb\x13\xee\x5d\x87\xe6\x84\x5a\x6b\x12\xe7\xc8\x37\x4a\x36\xaf\x26\xdf\xd2\xf0\x6a\xee\x47\x4d\xe1\xe8\x78\x25\xfb\x0b\xe9\xbe\xfd\x7f\xfa\xbe\x97\x1d\xe9\x8d\xe4\x3b\xe4\xa0\x9b\xdb\x7e\xde\x8f\xd0\x81\xfd\x5c\x27\xb9\x13\x9d\xca\xd7\xc8\x37\xc5\x86\x7a\x9b\x3f\xff\x1d\x71\xc2\xa1\x35\x9e\x29\x8d\xf7\x9d\xcb\x96\x4b\xe6\x2d\xb6\xce\x15\x1d\xd1\xdd\xa6\x2d\x48\xbb\x63\x4f\x63\x22\xd7\xd7\xf7\xb7\x67\x81\x87\xfb\xaf\x47\xd9\x15\xca\xb7\xf8\xc2\x54\x7c\x88\xd8\xe7\x88\xbd\x61\x2c\x56\x47\x63\xf1\x42\xb8\x73\x58\xfc\xf3\xe1\xaf\x9d\x21\xe6\x1f\xce\xfd\xda\x46\xe7\xb3\x70\x74\x04\x81\xbe\x12\x27\x93\xdc\x6e\xf8\xe8\xb2\x28\xf9\x02\x3a\x0e\x3a\x42\x2f\x44\x3b\x28\x53\xd1\x90\xc1\xbb\x34\xf9\x0f\x4c\xcb\xb9\x34\x69\x08\x00\x00")
 
 func defaultYmlBytes() ([]byte, error) {
 	return bindataRead(
@@ -83,7 +83,7 @@ func defaultYml() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "default.yml", size: 2065, mode: os.FileMode(420), modTime: time.Unix(1548668816, 0)}
+	info := bindataFileInfo{name: "default.yml", size: 2153, mode: os.FileMode(420), modTime: time.Unix(1792316077, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
@@ -3,6 +3,7 @@ package start
 import (
 	"fmt"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -11,9 +12,11 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/alpacahq/marketstore/cluster"
 	"github.com/alpacahq/marketstore/executor"
 	"github.com/alpacahq/marketstore/frontend"
 	"github.com/alpacahq/marketstore/frontend/stream"
+	"github.com/alpacahq/marketstore/replication"
 	"github.com/alpacahq/marketstore/utils"
 	"github.com/alpacahq/marketstore/utils/log"
 	"github.com/spf13/cobra"
@@ -100,21 +103,61 @@ func executeStart(cmd *cobra.Command, args []string) error {
 		utils.InstanceConfig.WALBypass)
 
 	// New server.
-	server, _ := frontend.NewServer()
+	// A coordinator serves the API of the cluster nodes in place of its own.
+	coordinator := len(utils.InstanceConfig.ClusterNodes) > 0
+	server, dataService := frontend.NewServer()
+	var service frontend.Service = dataService
+	if coordinator {
+		log.Info("coordinating cluster nodes %v...", utils.InstanceConfig.ClusterNodes)
+		var coordinatorService *cluster.DataService
+		server, coordinatorService, err = cluster.NewServer(utils.InstanceConfig.ClusterNodes)
+		if err != nil {
+			return fmt.Errorf("failed to coordinate cluster nodes - error: %s", err.Error())
+		}
+		service = coordinatorService
+	}
 
 	// Set rpc handler.
 	log.Info("launching rpc data server...")
 	go http.Handle("/rpc", server)
+	go http.HandleFunc("/arrow", frontend.ArrowHandler(service))
+	if !coordinator {
+		go http.HandleFunc("/query/stream", frontend.QueryStreamHandler)
+	}
 
 	// Set websocket handler.
 	log.Info("initializing websocket...")
 	stream.Initialize()
 	go http.HandleFunc("/ws", stream.Handler)
 
+	// Set replication.
+	// The data of a follower only changes through replication.
+	follower := utils.InstanceConfig.ClusterMode &&
+		utils.InstanceConfig.ReplicationRole == utils.ReplicationRoleFollower
+	if follower {
+		log.Info("following replication primary %v...", utils.InstanceConfig.ReplicationPrimary)
+		executor.ThisInstance.ReadOnly = true
+		go replication.NewFollower(utils.InstanceConfig.ReplicationPrimary).Run(nil)
+	} else if utils.InstanceConfig.ClusterMode && !coordinator && utils.InstanceConfig.ReplicationURL != "" {
+		log.Info("serving replication to followers at %v...", utils.InstanceConfig.ReplicationURL)
+		executor.Replication.MaxBytes = utils.InstanceConfig.ReplicationLogSize
+		go func() {
+			if err := replication.Serve(utils.InstanceConfig.ReplicationURL); err != nil {
+				log.Error("replication server stopped - error: %s", err.Error())
+			}
+		}()
+	}
+
 	// Initialize any provided plugins.
+	InitializeUDAs()
 	InitializeTriggers()
 	RunBgWorkers()
 
+	if len(utils.InstanceConfig.Retention) > 0 && !follower {
+		log.Info("launching retention enforcement every %v...", utils.InstanceConfig.RetentionInterval)
+		go executor.RunRetention(utils.InstanceConfig.Retention, utils.InstanceConfig.RetentionInterval)
+	}
+
 	if utils.InstanceConfig.UtilitiesURL != "" {
 		// Start utility endpoints.
 		log.Info("launching utility service...")
@@ -124,6 +167,20 @@ func executeStart(cmd *cobra.Command, args []string) error {
 	log.Info("enabling query access...")
 	atomic.StoreUint32(&frontend.Queryable, 1)
 
+	// Set grpc server, which streams as the websocket does.
+	if utils.InstanceConfig.GRPCListenURL != "" {
+		log.Info("launching grpc data server...")
+		listener, err := net.Listen("tcp", utils.InstanceConfig.GRPCListenURL)
+		if err != nil {
+			return fmt.Errorf("failed to start grpc server - error: %s", err.Error())
+		}
+		go func() {
+			if err := frontend.NewGRPCServer(service).Serve(listener); err != nil {
+				log.Error("grpc server stopped - error: %s", err.Error())
+			}
+		}()
+	}
+
 	// Serve.
 	log.Info("launching tcp listener for all services...")
 	if err := http.ListenAndServe(utils.InstanceConfig.ListenURL, nil); err != nil {
@@ -134,8 +191,7 @@ func executeStart(cmd *cobra.Command, args []string) error {
 }
 
 func shutdown() {
-	executor.ThisInstance.ShutdownPending = true
-	executor.ThisInstance.WALWg.Wait()
+	executor.Shutdown()
 	log.Info("exiting...")
 	os.Exit(0)
 }
@@ -3,8 +3,11 @@ package start
 import (
 	"github.com/alpacahq/marketstore/executor"
 	"github.com/alpacahq/marketstore/plugins"
+	"github.com/alpacahq/marketstore/plugins/aggregate"
 	"github.com/alpacahq/marketstore/plugins/bgworker"
 	"github.com/alpacahq/marketstore/plugins/trigger"
+	"github.com/alpacahq/marketstore/sqlparser"
+	"github.com/alpacahq/marketstore/uda"
 	"github.com/alpacahq/marketstore/utils"
 	"github.com/alpacahq/marketstore/utils/log"
 )
@@ -68,3 +71,33 @@ func NewBgWorker(s *utils.BgWorkerSetting) bgworker.BgWorker {
 	}
 	return bgWorker
 }
+
+func InitializeUDAs() {
+	log.Info("InitializeUDAs")
+	config := utils.InstanceConfig
+	for _, udaSetting := range config.UDAs {
+		log.Info("udaSetting = %v", udaSetting)
+		agg := NewAggregate(udaSetting)
+		if agg == nil {
+			continue
+		}
+		if err := sqlparser.RegisterAggregate(udaSetting.Name, agg); err != nil {
+			log.Error("Unable to register aggregate from %s: %v", udaSetting.Module, err)
+		}
+	}
+	log.Info("InitializeUDAs - Done")
+}
+
+func NewAggregate(s *utils.UDASetting) uda.AggInterface {
+	loader, err := plugins.NewSymbolLoader(s.Module)
+	if err != nil {
+		log.Error("Unable to open plugin for aggregate in %s: %v", s.Module, err)
+		return nil
+	}
+	agg, err := aggregate.Load(loader, s.Config)
+	if err != nil {
+		log.Error("Failed to create aggregate: %v", err)
+		return nil
+	}
+	return agg
+}
@@ -0,0 +1,113 @@
+package backup
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/alpacahq/marketstore/executor"
+	"github.com/alpacahq/marketstore/frontend"
+	"github.com/alpacahq/marketstore/frontend/client"
+	"github.com/spf13/cobra"
+)
+
+const (
+	usage = "backup"
+	short = "Write a consistent snapshot of a database"
+	long  = `This command writes a consistent snapshot of a database, along with a manifest
+of the checksums of its files. The snapshot is a directory, or a gzipped
+tarball when the output ends with ".tar.gz".
+
+With --incremental, the output is a directory storing the snapshots in an S3
+compatible layout, to which only the chunks of the files changed since the
+previous snapshot are written.
+
+With --url, the running server writes the snapshot under its backup_dir,
+holding the writes only while the files are listed. With --dir, the database
+must not be in use by a server.`
+	example = "marketstore tool backup --url localhost:5993 --out mktsdb.tar.gz"
+
+	// Flag descriptions.
+	serverURLDesc   = "set the address of the server to back up"
+	rootDirDesc     = "set filesystem path of the root directory to back up offline"
+	outputPathDesc  = "set the path of the snapshot, which must not exist, relative to the backup_dir of a server"
+	incrementalDesc = "write an incremental snapshot to the store at the output path, default is false"
+)
+
+var (
+	// Available flags.
+	serverURL, rootDirPath, outputPath string
+	incremental                        bool
+
+	// Cmd is the backup command.
+	Cmd = &cobra.Command{
+		Use:     usage,
+		Short:   short,
+		Long:    long,
+		Example: example,
+		RunE:    executeBackup,
+	}
+)
+
+func init() {
+	// Parse flags.
+	Cmd.Flags().StringVarP(&serverURL, "url", "u", "", serverURLDesc)
+	Cmd.Flags().StringVarP(&rootDirPath, "dir", "d", "", rootDirDesc)
+	Cmd.Flags().StringVarP(&outputPath, "out", "o", "", outputPathDesc)
+	Cmd.MarkFlagRequired("out")
+	Cmd.Flags().BoolVar(&incremental, "incremental", false, incrementalDesc)
+}
+
+func executeBackup(cmd *cobra.Command, args []string) error {
+	switch {
+	case serverURL != "" && rootDirPath != "":
+		return fmt.Errorf("only one of --url and --dir can be set")
+	case serverURL != "":
+		return backupServer()
+	case rootDirPath != "":
+		return backupDirectory()
+	default:
+		return fmt.Errorf("one of --url and --dir is required")
+	}
+}
+
+func backupServer() error {
+	cl, err := client.NewClient("http://" + serverURL)
+	if err != nil {
+		return err
+	}
+	resp, err := cl.DoRPC("Backup", &frontend.BackupRequest{Destination: outputPath, Incremental: incremental})
+	if err != nil {
+		return err
+	}
+	result := resp.(*frontend.BackupResponse)
+	if result.ServerResp.Error != "" {
+		return fmt.Errorf("%s", result.ServerResp.Error)
+	}
+	if incremental {
+		fmt.Printf("Backed up %d files (%d bytes) to %s on the server, %d changed (%d bytes written)\n",
+			result.Files, result.Bytes, result.Snapshot, result.FilesChanged, result.BytesWritten)
+		return nil
+	}
+	fmt.Printf("Backed up %d files (%d bytes) to %s on the server\n", result.Files, result.Bytes, outputPath)
+	return nil
+}
+
+func backupDirectory() error {
+	// The WAL files left by the last run are replayed before the snapshot
+	executor.NewInstanceSetup(filepath.Clean(rootDirPath), true, true, false)
+	if incremental {
+		result, err := executor.BackupIncremental(outputPath)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Backed up %d files (%d bytes) to %s, %d changed (%d bytes written)\n",
+			len(result.Manifest.Files), result.Manifest.TotalSize(), result.Key, result.FilesChanged, result.BytesWritten)
+		return nil
+	}
+	manifest, err := executor.Backup(outputPath)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Backed up %d files (%d bytes) to %s\n", len(manifest.Files), manifest.TotalSize(), outputPath)
+	return nil
+}
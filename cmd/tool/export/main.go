@@ -0,0 +1,113 @@
+package export
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/alpacahq/marketstore/cmd/connect/session"
+	"github.com/spf13/cobra"
+)
+
+const (
+	usage = "export"
+	short = "Export buckets to Parquet files"
+	long  = `This command exports the buckets of a key to Parquet files for data lakes,
+with one column per data shape, the Epoch and the Nanoseconds of variable
+records included. The files are partitioned by symbol and year, as in
+<out>/Symbol=AAPL/year=2020/data.parquet, and keep the key, record type and
+timestamp format of their bucket in their metadata for the import tool.
+
+With --url, the data is queried from a running server. With --dir, the
+database must not be in use by a server.`
+	example = "marketstore tool export --url localhost:5993 --key \"*/1Min/OHLCV\" --out /data/lake/1Min/OHLCV"
+
+	// Flag descriptions.
+	serverURLDesc = "set the address of the server to export from"
+	rootDirDesc   = "set filesystem path of the root directory to export from offline"
+	keyDesc       = "set the key of the buckets to export, e.g. AAPL,MSFT/1Min/OHLCV or */1Min/OHLCV"
+	outputDesc    = "set the path of the directory to write the files to"
+	formatDesc    = "set the format of the files, only parquet is supported"
+	startDesc     = "set the start time of the rows exported, as 2006-01-02 or 2006-01-02T15:04 in UTC"
+	endDesc       = "set the end time of the rows exported, as 2006-01-02 or 2006-01-02T15:04 in UTC"
+)
+
+var (
+	// Available flags.
+	serverURL, rootDirPath, key, outputPath, format, start, end string
+
+	// Cmd is the export command.
+	Cmd = &cobra.Command{
+		Use:     usage,
+		Short:   short,
+		Long:    long,
+		Example: example,
+		RunE:    executeExport,
+	}
+)
+
+func init() {
+	// Parse flags.
+	Cmd.Flags().StringVarP(&serverURL, "url", "u", "", serverURLDesc)
+	Cmd.Flags().StringVarP(&rootDirPath, "dir", "d", "", rootDirDesc)
+	Cmd.Flags().StringVarP(&key, "key", "k", "", keyDesc)
+	Cmd.MarkFlagRequired("key")
+	Cmd.Flags().StringVarP(&outputPath, "out", "o", "", outputDesc)
+	Cmd.MarkFlagRequired("out")
+	Cmd.Flags().StringVarP(&format, "format", "f", "parquet", formatDesc)
+	Cmd.Flags().StringVar(&start, "start", "", startDesc)
+	Cmd.Flags().StringVar(&end, "end", "", endDesc)
+}
+
+func executeExport(cmd *cobra.Command, args []string) error {
+	if format != "parquet" {
+		return fmt.Errorf("format %s is not supported, only parquet is", format)
+	}
+	startTime, err := parseTime(start)
+	if err != nil {
+		return err
+	}
+	endTime, err := parseTime(end)
+	if err != nil {
+		return err
+	}
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	files, err := c.Export(key, filepath.Clean(outputPath), startTime, endTime)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Exported %d files to %s\n", len(files), outputPath)
+	return nil
+}
+
+func newClient() (c *session.Client, err error) {
+	switch {
+	case serverURL != "" && rootDirPath != "":
+		return nil, fmt.Errorf("only one of --url and --dir can be set")
+	case serverURL != "":
+		c, err = session.NewRemoteClient(serverURL)
+	case rootDirPath != "":
+		c, err = session.NewLocalClient(filepath.Clean(rootDirPath))
+	default:
+		return nil, fmt.Errorf("one of --url and --dir is required")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c, c.Connect()
+}
+
+func parseTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	for _, layout := range []string{"2006-01-02", "2006-01-02T15:04"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("time %s is not formatted as 2006-01-02 or 2006-01-02T15:04", value)
+}
@@ -0,0 +1,88 @@
+// Package importer implements the import tool, import being a keyword.
+package importer
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/alpacahq/marketstore/cmd/connect/session"
+	"github.com/spf13/cobra"
+)
+
+const (
+	usage = "import"
+	short = "Import Parquet files to buckets"
+	long  = `This command imports the Parquet files written by the export tool, or by
+other tools in the same <in>/Symbol=AAPL/year=2020 partitions, to the
+buckets of their symbols. A missing bucket is created with the columns of
+the files, the Epoch and the Nanoseconds of variable records excluded, and
+the record type and timestamp format they were exported from.
+
+With --url, the data is written to a running server. With --dir, the
+database must not be in use by a server.`
+	example = "marketstore tool import --url localhost:5993 --key \"*/1Min/OHLCV\" --in /data/lake/1Min/OHLCV"
+
+	// Flag descriptions.
+	serverURLDesc = "set the address of the server to import to"
+	rootDirDesc   = "set filesystem path of the root directory to import to offline"
+	keyDesc       = "set the key of the buckets to import to, e.g. AAPL,MSFT/1Min/OHLCV or */1Min/OHLCV for every symbol"
+	inputDesc     = "set the path of the directory to read the files from"
+	formatDesc    = "set the format of the files, only parquet is supported"
+)
+
+var (
+	// Available flags.
+	serverURL, rootDirPath, key, inputPath, format string
+
+	// Cmd is the import command.
+	Cmd = &cobra.Command{
+		Use:     usage,
+		Short:   short,
+		Long:    long,
+		Example: example,
+		RunE:    executeImport,
+	}
+)
+
+func init() {
+	// Parse flags.
+	Cmd.Flags().StringVarP(&serverURL, "url", "u", "", serverURLDesc)
+	Cmd.Flags().StringVarP(&rootDirPath, "dir", "d", "", rootDirDesc)
+	Cmd.Flags().StringVarP(&key, "key", "k", "", keyDesc)
+	Cmd.MarkFlagRequired("key")
+	Cmd.Flags().StringVarP(&inputPath, "in", "i", "", inputDesc)
+	Cmd.MarkFlagRequired("in")
+	Cmd.Flags().StringVarP(&format, "format", "f", "parquet", formatDesc)
+}
+
+func executeImport(cmd *cobra.Command, args []string) error {
+	if format != "parquet" {
+		return fmt.Errorf("format %s is not supported, only parquet is", format)
+	}
+	var (
+		c   *session.Client
+		err error
+	)
+	switch {
+	case serverURL != "" && rootDirPath != "":
+		return fmt.Errorf("only one of --url and --dir can be set")
+	case serverURL != "":
+		c, err = session.NewRemoteClient(serverURL)
+	case rootDirPath != "":
+		c, err = session.NewLocalClient(filepath.Clean(rootDirPath))
+	default:
+		return fmt.Errorf("one of --url and --dir is required")
+	}
+	if err != nil {
+		return err
+	}
+	if err = c.Connect(); err != nil {
+		return err
+	}
+	files, rows, err := c.Import(key, filepath.Clean(inputPath))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Imported %d rows from %d files in %s\n", rows, files, inputPath)
+	return nil
+}
@@ -1,7 +1,11 @@
 package tool
 
 import (
+	"github.com/alpacahq/marketstore/cmd/tool/backup"
+	"github.com/alpacahq/marketstore/cmd/tool/export"
+	"github.com/alpacahq/marketstore/cmd/tool/importer"
 	"github.com/alpacahq/marketstore/cmd/tool/integrity"
+	"github.com/alpacahq/marketstore/cmd/tool/restore"
 	"github.com/alpacahq/marketstore/cmd/tool/wal"
 	"github.com/spf13/cobra"
 )
@@ -19,12 +23,16 @@ var (
 		Use:        usage,
 		Short:      short,
 		Long:       long,
-		SuggestFor: []string{"wal", "integrity"},
+		SuggestFor: []string{"wal", "integrity", "backup", "restore", "export", "import"},
 		Example:    example,
 	}
 )
 
 func init() {
+	Cmd.AddCommand(backup.Cmd)
+	Cmd.AddCommand(export.Cmd)
+	Cmd.AddCommand(importer.Cmd)
 	Cmd.AddCommand(integrity.Cmd)
+	Cmd.AddCommand(restore.Cmd)
 	Cmd.AddCommand(wal.Cmd)
 }
@@ -0,0 +1,57 @@
+package restore
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/alpacahq/marketstore/executor/backup"
+	"github.com/spf13/cobra"
+)
+
+const (
+	usage = "restore"
+	short = "Restore a database from a snapshot"
+	long  = `This command restores a snapshot written by the backup tool to a root
+directory, which must not exist or be empty. The checksums of the restored
+files are verified against the manifest of the snapshot before they are
+moved into place. An incremental snapshot is restored from the directory
+storing it, which restores the latest one, or from its manifest under the
+snapshots directory.`
+	example = "marketstore tool restore --from /backups/mktsdb.tar.gz --dir <path>"
+
+	// Flag descriptions.
+	snapshotPathDesc = "set the path of the snapshot directory, tarball, or incremental snapshot store"
+	rootDirDesc      = "set filesystem path of the root directory to restore to"
+)
+
+var (
+	// Available flags.
+	snapshotPath, rootDirPath string
+
+	// Cmd is the restore command.
+	Cmd = &cobra.Command{
+		Use:     usage,
+		Short:   short,
+		Long:    long,
+		Example: example,
+		RunE:    executeRestore,
+	}
+)
+
+func init() {
+	// Parse flags.
+	Cmd.Flags().StringVarP(&snapshotPath, "from", "f", "", snapshotPathDesc)
+	Cmd.MarkFlagRequired("from")
+	Cmd.Flags().StringVarP(&rootDirPath, "dir", "d", "", rootDirDesc)
+	Cmd.MarkFlagRequired("dir")
+}
+
+func executeRestore(cmd *cobra.Command, args []string) error {
+	manifest, err := backup.Restore(filepath.Clean(snapshotPath), filepath.Clean(rootDirPath))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Restored %d files (%d bytes) taken at %v by %s\n",
+		len(manifest.Files), manifest.TotalSize(), manifest.Created, manifest.Version)
+	return nil
+}
@@ -58,8 +58,9 @@ type OnDiskAggTrigger struct {
 }
 
 var (
-	_         trigger.Trigger = &OnDiskAggTrigger{}
-	loadError                 = errors.New("plugin load error")
+	_         trigger.Trigger       = &OnDiskAggTrigger{}
+	_         trigger.DeleteTrigger = &OnDiskAggTrigger{}
+	loadError                       = errors.New("plugin load error")
 )
 
 func recast(config map[string]interface{}) *AggTriggerConfig {
@@ -182,6 +183,48 @@ Query:
 	return
 }
 
+// FireDelete implements trigger.DeleteTrigger, recomputing the aggregates
+// of the windows the deleted records belonged to.
+func (s *OnDiskAggTrigger) FireDelete(keyPath string, indexes []int64) {
+	elements := strings.Split(keyPath, "/")
+	tf := utils.NewTimeframe(elements[1])
+	fileName := elements[len(elements)-1]
+	year, _ := strconv.Atoi(strings.Replace(fileName, ".bin", "", 1))
+	tbk := io.NewTimeBucketKey(strings.Join(elements[:len(elements)-1], "/"))
+
+	head := io.IndexToTime(minInt64(indexes), tf.Duration, int16(year))
+	tail := io.IndexToTime(maxInt64(indexes), tf.Duration, int16(year))
+
+	s.aggCache.Delete(tbk.String())
+
+	// clear the aggregates of these windows, the remaining data
+	// is aggregated again below
+	for _, dest := range s.destinations {
+		aggTbk := io.NewTimeBucketKeyFromString(elements[0] + "/" + dest.String + "/" + elements[2])
+		if _, err := executor.ThisInstance.CatalogDir.GetLatestTimeBucketInfoFromKey(aggTbk); err != nil {
+			continue
+		}
+		window := utils.CandleDurationFromString(dest.String)
+		start := window.Truncate(head).Unix()
+		end := window.Ceil(tail).Add(-time.Second).Unix()
+		if _, err := executor.DeleteRange(aggTbk, start, end); err != nil {
+			log.Error("failed to delete %v aggregates (%v)\n", aggTbk.String(), err)
+			return
+		}
+	}
+
+	window := utils.CandleDurationFromString(s.destinations.UpperBound().String)
+	csm, err := s.query(tbk, window, head, tail)
+	if err != nil || csm == nil {
+		log.Error("query error for %v (%v)\n", tbk.String(), err)
+		return
+	}
+
+	if cs := (*csm)[*tbk]; cs != nil {
+		s.write(tbk, cs, tail, head, elements)
+	}
+}
+
 func (s *OnDiskAggTrigger) write(
 	tbk *io.TimeBucketKey,
 	cs *io.ColumnSeries,
@@ -2,6 +2,8 @@ package executor
 
 import (
 	"bytes"
+	"encoding/binary"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"math"
@@ -9,15 +11,18 @@ import (
 	"path/filepath"
 	"reflect"
 	"sort"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	. "gopkg.in/check.v1"
 
 	. "github.com/alpacahq/marketstore/catalog"
+	"github.com/alpacahq/marketstore/executor/backup"
 	. "github.com/alpacahq/marketstore/planner"
 	"github.com/alpacahq/marketstore/utils"
 	. "github.com/alpacahq/marketstore/utils/io"
+	"github.com/alpacahq/marketstore/utils/stats"
 	. "github.com/alpacahq/marketstore/utils/test"
 )
 
@@ -281,6 +286,61 @@ func (s *TestSuite) TestWriteVariable(c *C) {
 		break
 	}
 }
+func (s *TestSuite) TestWriteVariableNanoseconds(c *C) {
+	tbk := NewTimeBucketKey("TEST-NANO/1Min/TICK-BIDASK")
+	dsv := NewDataShapeVector([]string{"Bid", "Ask"}, []EnumElementType{FLOAT32, FLOAT32})
+	tbinfo := NewTimeBucketInfo(*utils.TimeframeFromString("1Min"), tbk.GetPathToYearFiles(s.Rootdir), "Test",
+		int16(2016), dsv, VARIABLE)
+	c.Assert(tbinfo.SetTimestampFormat(NANOSECONDS), IsNil)
+	c.Assert(ThisInstance.CatalogDir.AddTimeBucket(tbk, tbinfo), IsNil)
+	tbi, err := ThisInstance.CatalogDir.GetLatestTimeBucketInfoFromKey(tbk)
+	c.Assert(err, IsNil)
+	c.Assert(tbi.GetVariableRecordLength(), Equals, int32(16))
+	tgc := ThisInstance.TXNPipe
+
+	// Prints a nanosecond apart within the same microsecond, written out of order
+	writer, err := NewWriter(tbi, tgc, s.DataDirectory)
+	c.Assert(err, IsNil)
+	row := struct {
+		Epoch    int64
+		Bid, Ask float32
+	}{0, 100, 200}
+	base := time.Date(2016, time.December, 31, 2, 59, 18, 123456000, time.UTC)
+	for _, ns := range []int{3, 1, 2} {
+		ts := base.Add(time.Duration(ns))
+		row.Epoch = ts.Unix()
+		row.Bid = float32(ns)
+		buffer, _ := Serialize([]byte{}, row)
+		writer.WriteRecords([]time.Time{ts}, buffer)
+		c.Assert(s.WALFile.flushToWAL(tgc), IsNil)
+	}
+
+	q := NewQuery(s.DataDirectory)
+	q.AddTargetKey(tbk)
+	q.SetRange(base.Unix(), base.Unix())
+	parsed, err := q.Parse()
+	c.Assert(err, IsNil)
+	reader, err := NewReader(parsed)
+	c.Assert(err, IsNil)
+	csm, err := reader.Read()
+	c.Assert(err, IsNil)
+	cs := csm[*tbk]
+	c.Assert(cs.Len(), Equals, 3)
+	c.Assert(cs.GetEpoch(), DeepEquals, []int64{base.Unix(), base.Unix(), base.Unix()})
+	c.Assert(cs.GetByName("Nanoseconds"), DeepEquals, []int32{123456001, 123456002, 123456003})
+	c.Assert(cs.GetByName("Bid"), DeepEquals, []float32{1, 2, 3})
+	c.Assert(cs.GetByName("Ask"), DeepEquals, []float32{200, 200, 200})
+
+	q.SetRowLimit(LAST, 2)
+	parsed, err = q.Parse()
+	c.Assert(err, IsNil)
+	reader, err = NewReader(parsed)
+	c.Assert(err, IsNil)
+	csm, err = reader.Read()
+	c.Assert(err, IsNil)
+	c.Assert(csm[*tbk].GetByName("Nanoseconds"), DeepEquals, []int32{123456002, 123456003})
+}
+
 func (s *TestSuite) TestFileRead(c *C) {
 	q := NewQuery(s.DataDirectory)
 	q.AddRestriction("Symbol", "NZDUSD")
@@ -403,6 +463,839 @@ func (s *TestSuite) TestDelete(c *C) {
 	}
 }
 
+func (s *TestSuite) TestCompressed(c *C) {
+	d := ThisInstance.CatalogDir
+	tgc := ThisInstance.TXNPipe
+	dataItemKey := "TEST-COMPRESSED/1Min/OHLC"
+	dataItemPath := filepath.Join(d.GetPath(), dataItemKey)
+	dsv := NewDataShapeVector(
+		[]string{"Open", "High", "Low", "Close"},
+		[]EnumElementType{FLOAT32, FLOAT32, FLOAT32, FLOAT32},
+	)
+	tbi := NewTimeBucketInfo(*utils.TimeframeFromString("1Min"), dataItemPath, "Test item", 2018,
+		dsv, FIXED)
+	c.Assert(tbi.SetCompression(COLUMNAR), IsNil)
+	tbk := NewTimeBucketKey(dataItemKey)
+	c.Assert(d.AddTimeBucket(tbk, tbi), IsNil)
+
+	tbi, err := d.GetLatestTimeBucketInfoFromKey(tbk)
+	c.Assert(err, IsNil)
+	c.Assert(tbi.GetCompression(), Equals, COLUMNAR)
+	writer, err := NewWriter(tbi, tgc, s.DataDirectory)
+	c.Assert(err, IsNil)
+
+	// Every other minute, spanning several blocks
+	startTime := time.Date(2018, 3, 1, 0, 0, 0, 0, time.UTC)
+	var tsA []time.Time
+	var buffer []byte
+	for i := 0; i < 5000; i++ {
+		tsA = append(tsA, startTime.Add(time.Duration(2*i)*time.Minute))
+		price := 100 + float32(i%40)/4
+		buffer, _ = Serialize(buffer, OHLCtest{0, price, price + 1, price - 1, price + 0.5})
+	}
+	writer.WriteRecords(tsA, buffer)
+	c.Assert(s.WALFile.flushToWAL(tgc), IsNil)
+
+	fi, err := os.Stat(tbi.Path)
+	c.Assert(err, IsNil)
+	c.Assert(fi.Size() < Headersize+5000*int64(tbi.GetRecordLength())/2, Equals, true)
+
+	q := NewQuery(s.DataDirectory)
+	q.AddTargetKey(tbk)
+	q.SetRange(startTime.Unix(), tsA[len(tsA)-1].Unix())
+	parsed, err := q.Parse()
+	c.Assert(err, IsNil)
+	r, err := NewReader(parsed)
+	c.Assert(err, IsNil)
+	csm, err := r.Read()
+	c.Assert(err, IsNil)
+	cs := csm[*tbk]
+	c.Assert(cs.Len(), Equals, 5000)
+	epoch := cs.GetEpoch()
+	open := cs.GetByName("Open").([]float32)
+	for i := range epoch {
+		c.Assert(epoch[i], Equals, tsA[i].Unix())
+		c.Assert(open[i], Equals, 100+float32(i%40)/4)
+	}
+
+	q = NewQuery(s.DataDirectory)
+	q.AddTargetKey(tbk)
+	q.SetRowLimit(LAST, 10)
+	parsed, err = q.Parse()
+	c.Assert(err, IsNil)
+	r, err = NewReader(parsed)
+	c.Assert(err, IsNil)
+	csm, err = r.Read()
+	c.Assert(err, IsNil)
+	cs = csm[*tbk]
+	c.Assert(cs.Len(), Equals, 10)
+	c.Assert(cs.GetEpoch()[9], Equals, tsA[len(tsA)-1].Unix())
+
+	q = NewQuery(s.DataDirectory)
+	q.AddTargetKey(tbk)
+	q.SetRange(tsA[100].Unix(), tsA[199].Unix())
+	parsed, err = q.Parse()
+	c.Assert(err, IsNil)
+	de, err := NewDeleter(parsed)
+	c.Assert(err, IsNil)
+	c.Assert(de.Delete(), IsNil)
+	r, err = NewReader(parsed)
+	c.Assert(err, IsNil)
+	csm, err = r.Read()
+	c.Assert(err, IsNil)
+	c.Assert(csm[*tbk].Len(), Equals, 0)
+}
+
+func (s *TestSuite) TestCompact(c *C) {
+	d := ThisInstance.CatalogDir
+	tgc := ThisInstance.TXNPipe
+	dataItemKey := "TEST-COMPACT/1Min/OHLC"
+	dataItemPath := filepath.Join(d.GetPath(), dataItemKey)
+	dsv := NewDataShapeVector(
+		[]string{"Open", "High", "Low", "Close"},
+		[]EnumElementType{FLOAT32, FLOAT32, FLOAT32, FLOAT32},
+	)
+	tbi := NewTimeBucketInfo(*utils.TimeframeFromString("1Min"), dataItemPath, "Test item", 2018,
+		dsv, FIXED)
+	tbk := NewTimeBucketKey(dataItemKey)
+	c.Assert(d.AddTimeBucket(tbk, tbi), IsNil)
+	tbi, err := d.GetLatestTimeBucketInfoFromKey(tbk)
+	c.Assert(err, IsNil)
+
+	// New year files are sparse
+	used, err := UsedBytes(tbi.Path)
+	c.Assert(err, IsNil)
+	c.Assert(used < 2*Headersize, Equals, true)
+
+	writer, err := NewWriter(tbi, tgc, s.DataDirectory)
+	c.Assert(err, IsNil)
+	startTime := time.Date(2018, 3, 1, 0, 0, 0, 0, time.UTC)
+	var tsA []time.Time
+	var buffer []byte
+	for i := 0; i < 5000; i++ {
+		tsA = append(tsA, startTime.Add(time.Duration(i)*time.Minute))
+		buffer, _ = Serialize(buffer, OHLCtest{0, 100, 200, 300, 400})
+	}
+	writer.WriteRecords(tsA, buffer)
+	c.Assert(s.WALFile.flushToWAL(tgc), IsNil)
+
+	q := NewQuery(s.DataDirectory)
+	q.AddTargetKey(tbk)
+	q.SetRange(tsA[1000].Unix(), tsA[3999].Unix())
+	parsed, err := q.Parse()
+	c.Assert(err, IsNil)
+	de, err := NewDeleter(parsed)
+	c.Assert(err, IsNil)
+	c.Assert(de.Delete(), IsNil)
+
+	freed, err := Compact(tbi)
+	c.Assert(err, IsNil)
+	c.Assert(freed >= 3000*int64(tbi.GetRecordLength())-2*HoleAlignment, Equals, true)
+
+	q = NewQuery(s.DataDirectory)
+	q.AddTargetKey(tbk)
+	q.SetRange(startTime.Unix(), tsA[len(tsA)-1].Unix())
+	parsed, err = q.Parse()
+	c.Assert(err, IsNil)
+	r, err := NewReader(parsed)
+	c.Assert(err, IsNil)
+	csm, err := r.Read()
+	c.Assert(err, IsNil)
+	epoch := csm[*tbk].GetEpoch()
+	c.Assert(len(epoch), Equals, 2000)
+	c.Assert(epoch[999], Equals, tsA[999].Unix())
+	c.Assert(epoch[1000], Equals, tsA[4000].Unix())
+}
+
+func (s *TestSuite) TestAlter(c *C) {
+	d := ThisInstance.CatalogDir
+	tgc := ThisInstance.TXNPipe
+	tbk := NewTimeBucketKey("TEST-ALTER/1Min/TICK-BIDASK")
+	dsv := NewDataShapeVector([]string{"Bid", "Ask"}, []EnumElementType{FLOAT32, FLOAT64})
+	tbi := NewTimeBucketInfo(*utils.TimeframeFromString("1Min"), tbk.GetPathToYearFiles(s.Rootdir), "Test",
+		2016, dsv, VARIABLE)
+	c.Assert(d.AddTimeBucket(tbk, tbi), IsNil)
+	tbi, err := d.GetLatestTimeBucketInfoFromKey(tbk)
+	c.Assert(err, IsNil)
+
+	writer, err := NewWriter(tbi, tgc, s.DataDirectory)
+	c.Assert(err, IsNil)
+	row := struct {
+		Epoch int64
+		Bid   float32
+		Ask   float64
+	}{0, 100, 200}
+	ts := time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 10; i++ {
+		ts = ts.Add(20 * time.Second)
+		row.Epoch, row.Bid, row.Ask = ts.Unix(), float32(100+i), float64(200+i)
+		buffer, _ := Serialize([]byte{}, row)
+		writer.WriteRecords([]time.Time{ts}, buffer)
+	}
+	// A record in a second year file
+	last := time.Date(2017, time.March, 1, 0, 0, 0, 0, time.UTC)
+	row.Epoch, row.Bid, row.Ask = last.Unix(), 110, 210
+	buffer, _ := Serialize([]byte{}, row)
+	writer.WriteRecords([]time.Time{last}, buffer)
+	c.Assert(s.WALFile.flushToWAL(tgc), IsNil)
+
+	_, _, err = AlterShapes(tbi.GetDataShapes(), nil, []string{"Last"}, nil)
+	c.Assert(err, NotNil)
+	_, _, err = AlterShapes(tbi.GetDataShapes(), nil, nil, map[string]string{"Bid": "Ask"})
+	c.Assert(err, NotNil)
+
+	newDsv, _, err := AlterShapes(tbi.GetDataShapes(),
+		[]DataShape{{Name: "Size", Type: INT32}}, []string{"Bid"}, map[string]string{"Ask": "Price"})
+	c.Assert(err, IsNil)
+	c.Assert(newDsv, DeepEquals, []DataShape{{Name: "Price", Type: FLOAT64}, {Name: "Size", Type: INT32}})
+
+	// A year file that fails to be rewritten leaves every year unchanged
+	blocker := filepath.Join(tbk.GetPathToYearFiles(s.Rootdir), "2017.bin.alter")
+	c.Assert(os.MkdirAll(filepath.Join(blocker, "blocker"), 0700), IsNil)
+	c.Assert(AlterBucket(tbk, []DataShape{{Name: "Size", Type: INT32}}, []string{"Bid"},
+		map[string]string{"Ask": "Price"}), NotNil)
+	c.Assert(os.RemoveAll(blocker), IsNil)
+	_, err = os.Stat(filepath.Join(tbk.GetPathToYearFiles(s.Rootdir), "2016.bin.alter"))
+	c.Assert(os.IsNotExist(err), Equals, true)
+	tbis, err := d.GetTimeBucketInfoSliceFromKey(tbk)
+	c.Assert(err, IsNil)
+	c.Assert(tbis, HasLen, 2)
+	for _, tbi := range tbis {
+		c.Assert(tbi.GetSchemaVersion(), Equals, int64(0))
+	}
+
+	// So does a year file that fails to be replaced
+	newTbis, err := rewriteYearFiles(tbis, []DataShape{{Name: "Size", Type: INT32}}, []string{"Bid"},
+		map[string]string{"Ask": "Price"})
+	c.Assert(err, IsNil)
+	c.Assert(os.Remove(newTbis[1].Path+".alter"), IsNil)
+	c.Assert(swapYearFiles(newTbis), NotNil)
+	for _, newTbi := range newTbis {
+		for _, suffix := range []string{".alter", ".orig"} {
+			_, err = os.Stat(newTbi.Path + suffix)
+			c.Assert(os.IsNotExist(err), Equals, true)
+		}
+		reloaded := &TimeBucketInfo{Path: newTbi.Path}
+		c.Assert(reloaded.GetSchemaVersion(), Equals, int64(0))
+	}
+
+	c.Assert(AlterBucket(tbk, []DataShape{{Name: "Size", Type: INT32}}, []string{"Bid"},
+		map[string]string{"Ask": "Price"}), IsNil)
+	tbis, err = d.GetTimeBucketInfoSliceFromKey(tbk)
+	c.Assert(err, IsNil)
+	for _, tbi := range tbis {
+		c.Assert(tbi.GetSchemaVersion(), Equals, int64(1))
+		c.Assert(tbi.GetDataShapes(), DeepEquals, newDsv)
+	}
+
+	q := NewQuery(s.DataDirectory)
+	q.AddTargetKey(tbk)
+	q.SetRange(time.Date(2016, time.January, 1, 0, 0, 0, 0, time.UTC).Unix(), last.Unix())
+	parsed, err := q.Parse()
+	c.Assert(err, IsNil)
+	r, err := NewReader(parsed)
+	c.Assert(err, IsNil)
+	csm, err := r.Read()
+	c.Assert(err, IsNil)
+	cs := csm[*tbk]
+	c.Assert(cs.Len(), Equals, 11)
+	c.Assert(cs.GetByName("Bid"), IsNil)
+	c.Assert(cs.GetByName("Price").([]float64)[9], Equals, float64(209))
+	c.Assert(cs.GetByName("Price").([]float64)[10], Equals, float64(210))
+	c.Assert(cs.GetByName("Size").([]int32), DeepEquals, make([]int32, 11))
+}
+
+func (s *TestSuite) TestRetention(c *C) {
+	d := ThisInstance.CatalogDir
+	tgc := ThisInstance.TXNPipe
+	tbk := NewTimeBucketKey("TEST-RETAIN/1Min/OHLC")
+	dsv := NewDataShapeVector(
+		[]string{"Open", "High", "Low", "Close"},
+		[]EnumElementType{FLOAT32, FLOAT32, FLOAT32, FLOAT32},
+	)
+	tbi := NewTimeBucketInfo(*utils.TimeframeFromString("1Min"), tbk.GetPathToYearFiles(s.Rootdir), "Test",
+		2016, dsv, FIXED)
+	c.Assert(d.AddTimeBucket(tbk, tbi), IsNil)
+	tbi, err := d.GetLatestTimeBucketInfoFromKey(tbk)
+	c.Assert(err, IsNil)
+
+	// One record a day from 2016 to 2018
+	writer, err := NewWriter(tbi, tgc, s.DataDirectory)
+	c.Assert(err, IsNil)
+	var tsA []time.Time
+	var buffer []byte
+	for ts := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC); ts.Year() < 2019; ts = ts.AddDate(0, 0, 1) {
+		tsA = append(tsA, ts)
+		buffer, _ = Serialize(buffer, OHLCtest{0, 100, 200, 300, 400})
+	}
+	writer.WriteRecords(tsA, buffer)
+	c.Assert(s.WALFile.flushToWAL(tgc), IsNil)
+	tbis, err := d.GetTimeBucketInfoSliceFromKey(tbk)
+	c.Assert(err, IsNil)
+	c.Assert(tbis, HasLen, 3)
+
+	cutoff := time.Date(2017, 7, 1, 0, 0, 0, 0, time.UTC)
+	now := time.Now()
+	policies := []*utils.RetentionSetting{
+		{On: "NOSUCH/*/*", MaxAge: time.Hour},
+		{On: "TEST-RETAIN/1Min/*", MaxAge: now.Sub(cutoff)},
+	}
+	result, err := EnforceRetention(d, policies, now)
+	c.Assert(err, IsNil)
+	c.Assert(result.FilesRemoved, Equals, int64(1))
+	c.Assert(result.RecordsDeleted, Equals, int64(181))
+	c.Assert(result.BytesFreed > 0, Equals, true)
+
+	tbis, err = d.GetTimeBucketInfoSliceFromKey(tbk)
+	c.Assert(err, IsNil)
+	c.Assert(tbis, HasLen, 2)
+	_, err = os.Stat(filepath.Join(tbk.GetPathToYearFiles(s.Rootdir), "2016.bin"))
+	c.Assert(os.IsNotExist(err), Equals, true)
+
+	q := NewQuery(s.DataDirectory)
+	q.AddTargetKey(tbk)
+	q.SetRange(tsA[0].Unix(), tsA[len(tsA)-1].Unix())
+	parsed, err := q.Parse()
+	c.Assert(err, IsNil)
+	r, err := NewReader(parsed)
+	c.Assert(err, IsNil)
+	csm, err := r.Read()
+	c.Assert(err, IsNil)
+	epoch := csm[*tbk].GetEpoch()
+	c.Assert(epoch[0], Equals, cutoff.Unix())
+	c.Assert(epoch[len(epoch)-1], Equals, tsA[len(tsA)-1].Unix())
+
+	// The latest year file is kept even when it expired
+	result, err = EnforceRetention(d, policies[1:], now.AddDate(10, 0, 0))
+	c.Assert(err, IsNil)
+	c.Assert(result.FilesRemoved, Equals, int64(1))
+	tbis, err = d.GetTimeBucketInfoSliceFromKey(tbk)
+	c.Assert(err, IsNil)
+	c.Assert(tbis, HasLen, 1)
+	c.Assert(tbis[0].Year, Equals, int16(2018))
+}
+
+func (s *TestSuite) TestQueueDeleteVariable(c *C) {
+	d := ThisInstance.CatalogDir
+	tgc := ThisInstance.TXNPipe
+	tbk := NewTimeBucketKey("TEST-QD/1Min/TICK-BIDASK")
+	dsv := NewDataShapeVector([]string{"Bid", "Ask"}, []EnumElementType{FLOAT32, FLOAT32})
+	tbi := NewTimeBucketInfo(*utils.TimeframeFromString("1Min"), tbk.GetPathToYearFiles(s.Rootdir), "Test",
+		2016, dsv, VARIABLE)
+	c.Assert(d.AddTimeBucket(tbk, tbi), IsNil)
+	tbi, err := d.GetLatestTimeBucketInfoFromKey(tbk)
+	c.Assert(err, IsNil)
+
+	// Two ticks a minute for five minutes
+	writer, err := NewWriter(tbi, tgc, s.DataDirectory)
+	c.Assert(err, IsNil)
+	row := struct {
+		Epoch    int64
+		Bid, Ask float32
+	}{0, 100, 200}
+	start := time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 10; i++ {
+		ts := start.Add(time.Duration(i) * 30 * time.Second)
+		row.Epoch = ts.Unix()
+		buffer, _ := Serialize([]byte{}, row)
+		writer.WriteRecords([]time.Time{ts}, buffer)
+	}
+	c.Assert(s.WALFile.flushToWAL(tgc), IsNil)
+
+	query := func(start, end time.Time) *ParseResult {
+		q := NewQuery(s.DataDirectory)
+		q.AddTargetKey(tbk)
+		q.SetRange(start.Unix(), end.Unix())
+		parsed, err := q.Parse()
+		c.Assert(err, IsNil)
+		return parsed
+	}
+	de, err := NewDeleter(query(start, start.Add(90*time.Second)))
+	c.Assert(err, IsNil)
+	c.Assert(de.QueueDelete(tgc), IsNil)
+	c.Assert(de.Deleted, Equals, int64(2))
+	c.Assert(s.WALFile.flushToWAL(tgc), IsNil)
+
+	r, err := NewReader(query(start, start.Add(time.Hour)))
+	c.Assert(err, IsNil)
+	csm, err := r.Read()
+	c.Assert(err, IsNil)
+	c.Assert(csm[*tbk].Len(), Equals, 6)
+	c.Assert(csm[*tbk].GetEpoch()[0], Equals, start.Add(2*time.Minute).Unix())
+
+	// A record still queued is deleted too
+	late := start.Add(10 * time.Minute)
+	row.Epoch = late.Unix()
+	buffer, _ := Serialize([]byte{}, row)
+	writer.WriteRecords([]time.Time{late}, buffer)
+	deleted, err := DeleteRange(tbk, late.Unix(), late.Unix())
+	c.Assert(err, IsNil)
+	c.Assert(deleted, Equals, int64(1))
+}
+
+func (s *TestSuite) TestWritePolicy(c *C) {
+	d := ThisInstance.CatalogDir
+	tgc := ThisInstance.TXNPipe
+	tbk := NewTimeBucketKey("TEST-POLICY/1Min/OHLCV")
+	dsv := NewDataShapeVector(
+		[]string{"Open", "High", "Low", "Close", "Volume"},
+		[]EnumElementType{FLOAT32, FLOAT32, FLOAT32, FLOAT32, INT32},
+	)
+	tbi := NewTimeBucketInfo(*utils.TimeframeFromString("1Min"), tbk.GetPathToYearFiles(s.Rootdir), "Test",
+		2016, dsv, FIXED)
+	c.Assert(d.AddTimeBucket(tbk, tbi), IsNil)
+	tbi, err := d.GetLatestTimeBucketInfoFromKey(tbk)
+	c.Assert(err, IsNil)
+
+	start := time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+	writer, err := NewWriter(tbi, tgc, s.DataDirectory)
+	c.Assert(err, IsNil)
+	buffer, _ := Serialize([]byte{}, OHLCVtest{start.Unix(), 1, 10, 5, 7, 100})
+	writer.WriteRecords([]time.Time{start}, buffer)
+	c.Assert(s.WALFile.flushToWAL(tgc), IsNil)
+
+	// Two late bars for the existing interval and one for a new interval
+	ts := []time.Time{start, start, start.Add(time.Minute)}
+	var data []byte
+	data, _ = Serialize(data, OHLCVtest{start.Unix(), 2, 12, 6, 8, 50})
+	data, _ = Serialize(data, OHLCVtest{start.Unix(), 3, 9, 4, 9, 10})
+	data, _ = Serialize(data, OHLCVtest{start.Add(time.Minute).Unix(), 1, 1, 1, 1, 1})
+
+	apply := func(policy EnumWritePolicy) ([]time.Time, []OHLCVtest, error) {
+		policyTbi := tbi.GetDeepCopy()
+		c.Assert(policyTbi.SetWritePolicy(policy), IsNil)
+		outTs, out, err := applyWritePolicy(d, tbk, policyTbi, nil, ts, data)
+		if err != nil {
+			return nil, nil, err
+		}
+		rows := make([]OHLCVtest, len(outTs))
+		c.Assert(binary.Read(bytes.NewReader(out), binary.LittleEndian, rows), IsNil)
+		return outTs, rows, nil
+	}
+
+	outTs, rows, err := apply(OVERWRITE)
+	c.Assert(err, IsNil)
+	c.Assert(outTs, HasLen, 3)
+
+	outTs, rows, err = apply(KEEPFIRST)
+	c.Assert(err, IsNil)
+	c.Assert(outTs, DeepEquals, ts[2:])
+	c.Assert(rows[0].Open, Equals, float32(1))
+
+	_, _, err = apply(REJECT)
+	c.Assert(err, NotNil)
+
+	outTs, rows, err = apply(MERGE)
+	c.Assert(err, IsNil)
+	c.Assert(outTs, DeepEquals, ts[1:])
+	c.Assert(rows[0], Equals, OHLCVtest{start.Unix(), 1, 12, 4, 9, 160})
+	c.Assert(rows[1].Volume, Equals, int32(1))
+
+	// The records queued by a previous write count as existing until flushed
+	rejectTbi := tbi.GetDeepCopy()
+	c.Assert(rejectTbi.SetWritePolicy(REJECT), IsNil)
+	state := &policyState{pending: make(map[intervalKey]pendingRecord)}
+	state.queued(rejectTbi, ts[2:], data[2*len(data)/3:])
+	_, _, err = applyWritePolicy(d, tbk, rejectTbi, state, ts[2:], data[2*len(data)/3:])
+	c.Assert(err, NotNil)
+	c.Assert(s.WALFile.flushToWAL(tgc), IsNil)
+	state.queued(rejectTbi, nil, nil)
+	c.Assert(state.pending, HasLen, 0)
+
+	// The policy is kept in the header of the year files
+	tbi, err = UpdateWritePolicy(tbi, REJECT)
+	c.Assert(err, IsNil)
+	c.Assert(d.UpdateTimeBucketInfo(tbi), IsNil)
+	tbi, err = d.GetLatestTimeBucketInfoFromKey(tbk)
+	c.Assert(err, IsNil)
+	c.Assert(tbi.GetWritePolicy(), Equals, REJECT)
+	reloaded := &TimeBucketInfo{Path: tbi.Path}
+	c.Assert(reloaded.GetWritePolicy(), Equals, REJECT)
+
+	// A rejected column series keeps the others of the same write from being written
+	bar := func(t time.Time) *ColumnSeries {
+		cs := NewColumnSeries()
+		cs.AddColumn("Epoch", []int64{t.Unix()})
+		cs.AddColumn("Open", []float32{1})
+		cs.AddColumn("High", []float32{1})
+		cs.AddColumn("Low", []float32{1})
+		cs.AddColumn("Close", []float32{1})
+		cs.AddColumn("Volume", []int32{1})
+		return cs
+	}
+	other := NewTimeBucketKey("TEST-ALL/1Min/OHLCV")
+	csm := NewColumnSeriesMap()
+	csm.AddColumnSeries(*other, bar(start))
+	csm.AddColumnSeries(*tbk, bar(start))
+	c.Assert(WriteCSM(csm, false), NotNil)
+	c.Assert(s.WALFile.flushToWAL(tgc), IsNil)
+	existing, err := newRecordSource(d, other, nil)
+	c.Assert(err, IsNil)
+	defer existing.Close()
+	record, err := existing.read(intervalKey{2016, TimeToIndex(start, time.Minute)})
+	c.Assert(err, IsNil)
+	c.Assert(record, IsNil)
+}
+
+func (s *TestSuite) TestWritePolicyAfterChanges(c *C) {
+	d := ThisInstance.CatalogDir
+	start := time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+	write := func(tbk *TimeBucketKey, extra bool) error {
+		cs := NewColumnSeries()
+		cs.AddColumn("Epoch", []int64{start.Unix()})
+		cs.AddColumn("Open", []float32{1})
+		cs.AddColumn("High", []float32{1})
+		cs.AddColumn("Low", []float32{1})
+		cs.AddColumn("Close", []float32{1})
+		cs.AddColumn("Volume", []int32{1})
+		if extra {
+			cs.AddColumn("Extra", []float32{1})
+		}
+		csm := NewColumnSeriesMap()
+		csm.AddColumnSeries(*tbk, cs)
+		return WriteCSM(csm, false)
+	}
+	create := func(key string, policy EnumWritePolicy) *TimeBucketKey {
+		tbk := NewTimeBucketKey(key)
+		dsv := NewDataShapeVector(
+			[]string{"Open", "High", "Low", "Close", "Volume"},
+			[]EnumElementType{FLOAT32, FLOAT32, FLOAT32, FLOAT32, INT32},
+		)
+		tbi := NewTimeBucketInfo(*utils.TimeframeFromString("1Min"), tbk.GetPathToYearFiles(s.Rootdir), "Test",
+			2016, dsv, FIXED)
+		c.Assert(tbi.SetWritePolicy(policy), IsNil)
+		c.Assert(d.AddTimeBucket(tbk, tbi), IsNil)
+		return tbk
+	}
+
+	// The records queued before an alteration are not merged with the new layout
+	tbk := create("TEST-MERGEALTER/1Min/OHLCV", MERGE)
+	c.Assert(write(tbk, false), IsNil)
+	c.Assert(AlterBucket(tbk, []DataShape{{Name: "Extra", Type: FLOAT32}}, nil, nil), IsNil)
+	c.Assert(write(tbk, true), IsNil)
+	c.Assert(s.WALFile.flushToWAL(ThisInstance.TXNPipe), IsNil)
+	existing, err := newRecordSource(d, tbk, nil)
+	c.Assert(err, IsNil)
+	record, err := existing.read(intervalKey{2016, TimeToIndex(start, time.Minute)})
+	existing.Close()
+	c.Assert(err, IsNil)
+	c.Assert(int32(binary.LittleEndian.Uint32(record[24:])), Equals, int32(2))
+
+	// Deleted records are not rejected
+	tbk = create("TEST-REJECTDELETE/1Min/OHLCV", REJECT)
+	c.Assert(write(tbk, false), IsNil)
+	c.Assert(write(tbk, false), NotNil)
+	deleted, err := DeleteRange(tbk, start.Unix(), start.Unix())
+	c.Assert(err, IsNil)
+	c.Assert(deleted, Equals, int64(1))
+	c.Assert(write(tbk, false), IsNil)
+}
+
+func (s *TestSuite) TestSyncFileFailure(c *C) {
+	fp, err := os.Create(filepath.Join(c.MkDir(), "wal"))
+	c.Assert(err, IsNil)
+	c.Assert(fp.Close(), IsNil)
+	wf := &WALFileType{FilePtr: fp, unsynced: true, unpublished: [][]byte{{1}}}
+	syncErr := wf.syncFile()
+	c.Assert(syncErr, NotNil)
+
+	// The failure is kept once the file could be synced again
+	wf.FilePtr, err = os.OpenFile(fp.Name(), os.O_RDWR, 0600)
+	c.Assert(err, IsNil)
+	defer wf.FilePtr.Close()
+	c.Assert(wf.syncFile(), Equals, syncErr)
+	c.Assert(wf.unpublished, HasLen, 1)
+}
+
+func (s *TestSuite) TestCommitGroup(c *C) {
+	tgc := ThisInstance.TXNPipe
+	tbk := NewTimeBucketKey("TEST-SYNC/1Min/OHLC")
+	dsv := NewDataShapeVector(
+		[]string{"Open", "High", "Low", "Close"},
+		[]EnumElementType{FLOAT32, FLOAT32, FLOAT32, FLOAT32},
+	)
+	tbi := NewTimeBucketInfo(*utils.TimeframeFromString("1Min"), tbk.GetPathToYearFiles(s.Rootdir), "Test",
+		2016, dsv, FIXED)
+	c.Assert(ThisInstance.CatalogDir.AddTimeBucket(tbk, tbi), IsNil)
+	tbi, err := ThisInstance.CatalogDir.GetLatestTimeBucketInfoFromKey(tbk)
+	c.Assert(err, IsNil)
+
+	writer, err := NewWriter(tbi, tgc, s.DataDirectory)
+	c.Assert(err, IsNil)
+	ts := time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+	buffer, _ := Serialize([]byte{}, OHLCtest{ts.Unix(), 1, 2, 3, 4})
+	writer.WriteRecords([]time.Time{ts}, buffer)
+
+	// Two more sync requests are waiting behind the first one
+	requests := atomic.LoadUint64(&stats.WALSyncRequests)
+	commits := atomic.LoadUint64(&stats.WALGroupCommits)
+	group := []chan error{make(chan error, 1), make(chan error, 1), make(chan error, 1)}
+	tgc.syncChannel <- group[1]
+	tgc.syncChannel <- group[2]
+	c.Assert(s.WALFile.commitGroup(group[0]), IsNil)
+	for _, f := range group {
+		c.Assert(len(f), Equals, 1)
+		c.Assert(<-f, IsNil)
+	}
+	c.Assert(len(tgc.syncChannel), Equals, 0)
+	c.Assert(len(tgc.writeChannel), Equals, 0)
+	c.Assert(s.WALFile.unsynced, Equals, false)
+	c.Assert(atomic.LoadUint64(&stats.WALSyncRequests)-requests, Equals, uint64(3))
+	c.Assert(atomic.LoadUint64(&stats.WALGroupCommits)-commits, Equals, uint64(1))
+
+	q := NewQuery(s.DataDirectory)
+	q.AddTargetKey(tbk)
+	q.SetRange(ts.Unix(), ts.Unix())
+	parsed, err := q.Parse()
+	c.Assert(err, IsNil)
+	reader, err := NewReader(parsed)
+	c.Assert(err, IsNil)
+	csm, err := reader.Read()
+	c.Assert(err, IsNil)
+	c.Assert(csm[*tbk].GetByName("Close"), DeepEquals, []float32{4})
+}
+
+func (s *TestSuite) TestReplicationLog(c *C) {
+	rl := NewReplicationLog(10)
+	rl.publish([]byte{1, 2, 3, 4})
+	// The entries are only retained once a follower asks for them
+	_, _, _, err := rl.Since(rl.Last() - 1)
+	c.Assert(err, Equals, ErrReplicationGap)
+	start := rl.Last()
+	entries, last, wait, err := rl.Since(start)
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 0)
+	c.Assert(last, Equals, start)
+
+	rl.publish([]byte{5, 6, 7, 8})
+	<-wait
+	rl.Resync()
+	entries, last, _, err = rl.Since(start)
+	c.Assert(err, IsNil)
+	c.Assert(entries, DeepEquals, []ReplicationEntry{
+		{start + 1, TransactionGroupEntry, []byte{5, 6, 7, 8}},
+		{start + 2, ResyncEntry, nil},
+	})
+	c.Assert(last, Equals, start+2)
+
+	// The oldest entries are released past MaxBytes
+	rl.publish([]byte{9, 10, 11, 12, 13, 14, 15, 16})
+	_, _, _, err = rl.Since(start)
+	c.Assert(err, Equals, ErrReplicationGap)
+	entries, _, _, err = rl.Since(start + 1)
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 2)
+}
+
+func (s *TestSuite) TestApplyCatalogCreate(c *C) {
+	rl := NewReplicationLog(1 << 20)
+	_, _, _, err := rl.Since(0)
+	c.Assert(err, Equals, ErrReplicationGap)
+	start := rl.Last()
+	defer os.Remove(filepath.Join(s.Rootdir, ReplicationPositionFile))
+
+	// The creation of a bucket ships its description
+	tbk := NewTimeBucketKey("TEST-CREATE/1Min/OHLC")
+	dsv := NewDataShapeVector(
+		[]string{"Open", "High", "Low", "Close"},
+		[]EnumElementType{FLOAT32, FLOAT32, FLOAT32, FLOAT32},
+	)
+	tbi := NewTimeBucketInfo(*utils.TimeframeFromString("1Min"), tbk.GetPathToYearFiles(s.Rootdir), "Test",
+		2016, dsv, FIXED)
+	c.Assert(tbi.SetCompression(COLUMNAR), IsNil)
+	c.Assert(tbi.SetWritePolicy(REJECT), IsNil)
+	rl.PublishCreate(tbk, tbi)
+	next := tbi.GetDeepCopy()
+	next.Year = 2017
+	rl.PublishCreate(tbk, next)
+	entries, _, _, err := rl.Since(start)
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 2)
+	c.Assert(entries[0].Type, Equals, CatalogCreateEntry)
+
+	for _, entry := range entries {
+		c.Assert(ApplyCatalogCreate(entry.Position, entry.Data), IsNil)
+	}
+	tbis, err := ThisInstance.CatalogDir.GetTimeBucketInfoSliceFromKey(tbk)
+	c.Assert(err, IsNil)
+	c.Assert(tbis, HasLen, 2)
+	for _, created := range tbis {
+		c.Assert(created.GetDataShapes(), DeepEquals, dsv)
+		c.Assert(created.GetCompression(), Equals, COLUMNAR)
+		c.Assert(created.GetWritePolicy(), Equals, REJECT)
+	}
+	position, err := ReplicationPosition()
+	c.Assert(err, IsNil)
+	c.Assert(position, Equals, entries[1].Position)
+
+	// Applying a create again leaves the file as is
+	c.Assert(ApplyCatalogCreate(entries[0].Position, entries[0].Data), IsNil)
+	c.Assert(ApplyCatalogCreate(entries[0].Position, []byte{1}), ErrorMatches, "Invalid catalog create.*")
+}
+
+func (s *TestSuite) TestBackupIncremental(c *C) {
+	tbk := NewTimeBucketKey("TEST-BACKUP/1Min/OHLC")
+	dsv := NewDataShapeVector(
+		[]string{"Open", "High", "Low", "Close"},
+		[]EnumElementType{FLOAT32, FLOAT32, FLOAT32, FLOAT32},
+	)
+	tbi := NewTimeBucketInfo(*utils.TimeframeFromString("1Min"), tbk.GetPathToYearFiles(s.Rootdir), "Test",
+		2016, dsv, FIXED)
+	c.Assert(ThisInstance.CatalogDir.AddTimeBucket(tbk, tbi), IsNil)
+	tbi, err := ThisInstance.CatalogDir.GetLatestTimeBucketInfoFromKey(tbk)
+	c.Assert(err, IsNil)
+	write := func(ts time.Time, close float32) {
+		writer, err := NewWriter(tbi, ThisInstance.TXNPipe, s.DataDirectory)
+		c.Assert(err, IsNil)
+		buffer, _ := Serialize([]byte{}, OHLCtest{ts.Unix(), 1, 2, 3, close})
+		writer.WriteRecords([]time.Time{ts}, buffer)
+		s.WALFile.RequestFlush()
+	}
+	write(time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC), 4)
+
+	// The files look unchanged since well before the first snapshot
+	past := time.Now().Add(-time.Hour)
+	err = filepath.Walk(s.Rootdir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && info.Mode().IsRegular() {
+			err = os.Chtimes(path, past, past)
+		}
+		return err
+	})
+	c.Assert(err, IsNil)
+
+	store := filepath.Join(c.MkDir(), "store")
+	first, err := BackupIncremental(store)
+	c.Assert(err, IsNil)
+	c.Assert(first.FilesChanged, Equals, len(first.Manifest.Files))
+	c.Assert(first.ChunksWritten > 0, Equals, true)
+
+	second, err := BackupIncremental(store)
+	c.Assert(err, IsNil)
+	c.Assert(second.Key > first.Key, Equals, true)
+	c.Assert(second.FilesChanged, Equals, 0)
+	c.Assert(second.ChunksWritten, Equals, 0)
+	c.Assert(second.Manifest.Files, DeepEquals, first.Manifest.Files)
+
+	// Only the chunk holding the new record is written
+	write(time.Date(2016, time.December, 1, 0, 0, 0, 0, time.UTC), 5)
+	third, err := BackupIncremental(store)
+	c.Assert(err, IsNil)
+	c.Assert(third.FilesChanged, Equals, 1)
+	c.Assert(third.ChunksWritten, Equals, 1)
+
+	restored := filepath.Join(c.MkDir(), "restored")
+	manifest, err := backup.Restore(store, restored)
+	c.Assert(err, IsNil)
+	c.Assert(manifest.Files, DeepEquals, third.Manifest.Files)
+	for _, f := range manifest.Files {
+		original, err := ioutil.ReadFile(filepath.Join(s.Rootdir, f.Path))
+		c.Assert(err, IsNil)
+		copied, err := ioutil.ReadFile(filepath.Join(restored, f.Path))
+		c.Assert(err, IsNil)
+		c.Assert(bytes.Equal(original, copied), Equals, true, Commentf("%s", f.Path))
+	}
+
+	// An older snapshot is restored from its manifest
+	restored = filepath.Join(c.MkDir(), "restored")
+	manifest, err = backup.Restore(filepath.Join(store, filepath.FromSlash(first.Key)), restored)
+	c.Assert(err, IsNil)
+	c.Assert(manifest.Files, DeepEquals, first.Manifest.Files)
+}
+
+func (s *TestSuite) TestBackupFrozen(c *C) {
+	tbk := NewTimeBucketKey("TEST-FROZEN/1Min/OHLC")
+	dsv := NewDataShapeVector(
+		[]string{"Open", "High", "Low", "Close"},
+		[]EnumElementType{FLOAT32, FLOAT32, FLOAT32, FLOAT32},
+	)
+	tbi := NewTimeBucketInfo(*utils.TimeframeFromString("1Min"), tbk.GetPathToYearFiles(s.Rootdir), "Test",
+		2016, dsv, FIXED)
+	c.Assert(ThisInstance.CatalogDir.AddTimeBucket(tbk, tbi), IsNil)
+	tbi, err := ThisInstance.CatalogDir.GetLatestTimeBucketInfoFromKey(tbk)
+	c.Assert(err, IsNil)
+	write := func(ts time.Time, close float32) {
+		writer, err := NewWriter(tbi, ThisInstance.TXNPipe, s.DataDirectory)
+		c.Assert(err, IsNil)
+		buffer, _ := Serialize([]byte{}, OHLCtest{ts.Unix(), 1, 2, 3, close})
+		writer.WriteRecords([]time.Time{ts}, buffer)
+		s.WALFile.RequestFlush()
+	}
+	write(time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC), 4)
+	frozenData, err := ioutil.ReadFile(tbi.Path)
+	c.Assert(err, IsNil)
+
+	fd, err := freezeRootDir(isWALFile, nil)
+	c.Assert(err, IsNil)
+	defer thaw(fd)
+
+	// A write after the freeze goes through, and the file is preserved
+	write(time.Date(2016, time.March, 2, 0, 0, 0, 0, time.UTC), 5)
+	data, err := ioutil.ReadFile(tbi.Path)
+	c.Assert(err, IsNil)
+	c.Assert(bytes.Equal(data, frozenData), Equals, false)
+
+	dest := filepath.Join(c.MkDir(), "snapshot")
+	_, err = backup.SnapshotFrozen(fd, dest)
+	c.Assert(err, IsNil)
+	rel, err := filepath.Rel(s.Rootdir, tbi.Path)
+	c.Assert(err, IsNil)
+	copied, err := ioutil.ReadFile(filepath.Join(dest, rel))
+	c.Assert(err, IsNil)
+	c.Assert(bytes.Equal(copied, frozenData), Equals, true)
+}
+
+func (s *TestSuite) TestBackupRestore(c *C) {
+	snapshots := c.MkDir()
+	for _, name := range []string{"snapshot", "snapshot.tar.gz"} {
+		dest := filepath.Join(snapshots, name)
+		manifest, err := Backup(dest)
+		c.Assert(err, IsNil)
+		c.Assert(len(manifest.Files) > 0, Equals, true)
+		for _, f := range manifest.Files {
+			c.Assert(filepath.Ext(f.Path), Not(Equals), ".walfile")
+		}
+		// An existing snapshot is not overwritten
+		_, err = Backup(dest)
+		c.Assert(err, NotNil)
+
+		restored := filepath.Join(c.MkDir(), "restored")
+		got, err := backup.Restore(dest, restored)
+		c.Assert(err, IsNil)
+		c.Assert(got.Files, DeepEquals, manifest.Files)
+		for _, f := range manifest.Files {
+			original, err := ioutil.ReadFile(filepath.Join(s.Rootdir, f.Path))
+			c.Assert(err, IsNil)
+			copied, err := ioutil.ReadFile(filepath.Join(restored, f.Path))
+			c.Assert(err, IsNil)
+			c.Assert(bytes.Equal(original, copied), Equals, true, Commentf("%s", f.Path))
+		}
+		// The restored database is not overwritten either
+		_, err = backup.Restore(dest, restored)
+		c.Assert(err, NotNil)
+	}
+
+	// A snapshot inside the root directory would be copied into itself
+	_, err := Backup(filepath.Join(s.Rootdir, "snapshot"))
+	c.Assert(err, NotNil)
+
+	// A damaged file fails the checksum and nothing is restored
+	dest := filepath.Join(snapshots, "snapshot")
+	data, err := ioutil.ReadFile(filepath.Join(dest, backup.ManifestName))
+	c.Assert(err, IsNil)
+	manifest := &backup.Manifest{}
+	c.Assert(json.Unmarshal(data, manifest), IsNil)
+	damaged := filepath.Join(dest, filepath.FromSlash(manifest.Files[0].Path))
+	data, err = ioutil.ReadFile(damaged)
+	c.Assert(err, IsNil)
+	data[0] ^= 0xff
+	c.Assert(ioutil.WriteFile(damaged, data, 0600), IsNil)
+	restored := filepath.Join(c.MkDir(), "restored")
+	_, err = backup.Restore(dest, restored)
+	c.Assert(err, ErrorMatches, ".*does not match its checksum.*")
+	_, err = os.Stat(filepath.Join(restored, filepath.Base(damaged)))
+	c.Assert(os.IsNotExist(err), Equals, true)
+}
+
 func asserter(c *C, err error, shouldBeNil bool) {
 	if err != nil {
 		fmt.Println("error: ", err.Error())
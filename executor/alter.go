@@ -0,0 +1,434 @@
+package executor
+
+import (
+	"encoding/binary"
+	"fmt"
+	goio "io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/snappy"
+
+	"github.com/alpacahq/marketstore/executor/colfile"
+	"github.com/alpacahq/marketstore/utils"
+	"github.com/alpacahq/marketstore/utils/io"
+)
+
+/*
+AlterShapes applies column changes to the data shapes of a bucket: renamed
+columns keep their position, dropped columns are removed and added columns
+are appended. It returns the new data shapes with, for each of them, the
+index of the current column holding its values, or -1 for added columns.
+*/
+func AlterShapes(current, add []io.DataShape, drop []string, rename map[string]string) (
+	dsv []io.DataShape, sources []int, err error) {
+
+	find := func(name string) int {
+		for i, shape := range current {
+			if strings.EqualFold(shape.Name, name) {
+				return i
+			}
+		}
+		return -1
+	}
+	isEpoch := func(name string) bool {
+		return strings.EqualFold(name, "Epoch")
+	}
+
+	dropped := make(map[int]bool)
+	for _, name := range drop {
+		i := find(name)
+		if i < 0 || isEpoch(name) {
+			return nil, nil, fmt.Errorf("Unable to drop column %s, which is not a data column", name)
+		}
+		dropped[i] = true
+	}
+	names := make([]string, len(current))
+	for i, shape := range current {
+		names[i] = shape.Name
+	}
+	for from, to := range rename {
+		i := find(from)
+		if i < 0 || isEpoch(from) || isEpoch(to) {
+			return nil, nil, fmt.Errorf("Unable to rename column %s to %s", from, to)
+		}
+		if dropped[i] {
+			return nil, nil, fmt.Errorf("Unable to rename dropped column %s", from)
+		}
+		names[i] = to
+	}
+
+	seen := make(map[string]bool)
+	appendShape := func(shape io.DataShape, source int) error {
+		if seen[strings.ToLower(shape.Name)] {
+			return fmt.Errorf("Duplicate column %s", shape.Name)
+		}
+		seen[strings.ToLower(shape.Name)] = true
+		dsv = append(dsv, shape)
+		sources = append(sources, source)
+		return nil
+	}
+	for i, shape := range current {
+		if dropped[i] || isEpoch(shape.Name) {
+			continue
+		}
+		if err = appendShape(io.DataShape{Name: names[i], Type: shape.Type}, i); err != nil {
+			return nil, nil, err
+		}
+	}
+	for _, shape := range add {
+		if isEpoch(shape.Name) {
+			return nil, nil, fmt.Errorf("Unable to add the Epoch column")
+		}
+		if err = appendShape(shape, -1); err != nil {
+			return nil, nil, err
+		}
+	}
+	if len(dsv) == 0 {
+		return nil, nil, fmt.Errorf("A bucket must have at least one data column")
+	}
+	return dsv, sources, nil
+}
+
+/*
+AlterBucket applies column changes to every year file of a bucket, as with
+AlterShapes, and updates the catalog. Writes to the bucket are held during
+the alteration, and the writes already checked against the current columns
+are flushed before the year files are rewritten. Every year file is
+rewritten and synced before any is replaced, and the files are replaced all
+together or not at all, so that a failed alteration leaves the bucket
+unchanged. Only the replacement holds the writes to the other buckets, once
+the WAL is checkpointed so that no record of the old layout is left in it.
+*/
+func AlterBucket(tbk *io.TimeBucketKey, add []io.DataShape, drop []string, rename map[string]string) error {
+	cDir := ThisInstance.CatalogDir
+	lock := bucketLock(tbk)
+	lock.Lock()
+	defer lock.Unlock()
+
+	tbis, err := cDir.GetTimeBucketInfoSliceFromKey(tbk)
+	if err != nil {
+		return err
+	}
+	ThisInstance.WALFile.RequestFlush()
+	newTbis, err := rewriteYearFiles(tbis, add, drop, rename)
+	if err != nil {
+		return err
+	}
+	err = ThisInstance.WALFile.RunQuiesced(func() error {
+		return swapYearFiles(newTbis)
+	})
+	if err != nil {
+		removeAltered(newTbis)
+		return err
+	}
+	for _, newTbi := range newTbis {
+		if err = cDir.UpdateTimeBucketInfo(newTbi); err != nil {
+			return err
+		}
+	}
+	forgetQueuedRecords(tbk.GetPathToYearFiles(ThisInstance.RootDir))
+	Replication.Resync()
+	return nil
+}
+
+/*
+rewriteYearFiles rewrites the year files of a bucket to ".alter" files next
+to them, removing them all if any fails
+*/
+func rewriteYearFiles(tbis []*io.TimeBucketInfo, add []io.DataShape, drop []string,
+	rename map[string]string) ([]*io.TimeBucketInfo, error) {
+
+	var newTbis []*io.TimeBucketInfo
+	for _, tbi := range tbis {
+		dsv, sources, err := AlterShapes(tbi.GetDataShapes(), add, drop, rename)
+		if err == nil {
+			var newTbi *io.TimeBucketInfo
+			if newTbi, err = alterYearFile(tbi, dsv, sources); err == nil {
+				newTbis = append(newTbis, newTbi)
+			}
+		}
+		if err != nil {
+			removeAltered(newTbis)
+			return nil, fmt.Errorf("alteration of %s failed: %s", tbi.Path, err.Error())
+		}
+	}
+	return newTbis, nil
+}
+
+/*
+swapYearFiles replaces the year files by their ".alter" files. The originals
+are kept as ".orig" links until all are replaced, and put back if any
+replacement fails. The writes to the primary files must be held.
+*/
+func swapYearFiles(newTbis []*io.TimeBucketInfo) (err error) {
+	var swapped []*io.TimeBucketInfo
+	defer func() {
+		if err != nil {
+			for _, newTbi := range swapped {
+				os.Rename(newTbi.Path+".orig", newTbi.Path)
+			}
+			removeAltered(newTbis)
+		}
+		for _, newTbi := range newTbis {
+			os.Remove(newTbi.Path + ".orig")
+		}
+	}()
+	for _, newTbi := range newTbis {
+		os.Remove(newTbi.Path + ".orig")
+		if err = os.Link(newTbi.Path, newTbi.Path+".orig"); err != nil {
+			return fmt.Errorf("replacement of %s failed: %s", newTbi.Path, err.Error())
+		}
+	}
+	for _, newTbi := range newTbis {
+		preserveFrozen(newTbi.Path)
+		if err = os.Rename(newTbi.Path+".alter", newTbi.Path); err != nil {
+			return fmt.Errorf("replacement of %s failed: %s", newTbi.Path, err.Error())
+		}
+		swapped = append(swapped, newTbi)
+	}
+	if len(newTbis) != 0 {
+		if err = syncPath(filepath.Dir(newTbis[0].Path)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func removeAltered(newTbis []*io.TimeBucketInfo) {
+	for _, newTbi := range newTbis {
+		os.Remove(newTbi.Path + ".alter")
+	}
+}
+
+/*
+alterYearFile rewrites a year file with new data shapes, as returned by
+AlterShapes along with the source of each column, to a synced ".alter" file
+next to it. Values of added columns are zero. The schema version in the
+header is incremented. The returned TimeBucketInfo describes the new file
+once renamed.
+*/
+func alterYearFile(tbi *io.TimeBucketInfo, dsv []io.DataShape, sources []int) (*io.TimeBucketInfo, error) {
+	if len(dsv) != len(sources) {
+		return nil, fmt.Errorf("Each column must have a source")
+	}
+	tf := utils.Timeframe{Duration: tbi.GetTimeframe()}
+	newTbi := io.NewTimeBucketInfo(tf, filepath.Dir(tbi.Path), tbi.GetDescription(), tbi.Year,
+		dsv, tbi.GetRecordType())
+	if err := newTbi.SetCompression(tbi.GetCompression()); err != nil {
+		return nil, err
+	}
+	if err := newTbi.SetWritePolicy(tbi.GetWritePolicy()); err != nil {
+		return nil, err
+	}
+	if err := newTbi.SetTimestampFormat(tbi.GetTimestampFormat()); err != nil {
+		return nil, err
+	}
+	newTbi.SetSchemaVersion(tbi.GetSchemaVersion() + 1)
+
+	finalPath := newTbi.Path
+	newTbi.Path = finalPath + ".alter"
+	var err error
+	if tbi.GetRecordType() == io.VARIABLE {
+		err = rewriteVariable(tbi, newTbi, sources)
+	} else {
+		err = rewriteFixed(tbi, newTbi, sources)
+	}
+	if err == nil {
+		err = syncPath(newTbi.Path)
+	}
+	if err != nil {
+		os.Remove(newTbi.Path)
+		return nil, err
+	}
+	newTbi.Path = finalPath
+	return newTbi, nil
+}
+
+// syncPath flushes a file or directory to disk
+func syncPath(path string) error {
+	fp, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	if err = fp.Sync(); err != nil {
+		fp.Close()
+		return err
+	}
+	return fp.Close()
+}
+
+/*
+fieldCopy copies a column from a record of the old layout to the new one
+*/
+type fieldCopy struct {
+	from, to, size int
+}
+
+func fieldCopies(oldTbi, newTbi *io.TimeBucketInfo, sources []int, base int) (copies []fieldCopy) {
+	oldOffsets := make([]int, 0, len(oldTbi.GetElementTypes()))
+	offset := base
+	for _, typ := range oldTbi.GetElementTypes() {
+		oldOffsets = append(oldOffsets, offset)
+		offset += typ.Size()
+	}
+	offset = base
+	for i, typ := range newTbi.GetElementTypes() {
+		if sources[i] >= 0 {
+			copies = append(copies, fieldCopy{from: oldOffsets[sources[i]], to: offset, size: typ.Size()})
+		}
+		offset += typ.Size()
+	}
+	return copies
+}
+
+func createYearFile(tbi *io.TimeBucketInfo) (*os.File, error) {
+	fp, err := os.OpenFile(tbi.Path, os.O_CREATE|os.O_TRUNC|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+	size := io.FileSize(tbi.GetTimeframe(), int(tbi.Year), int(tbi.GetRecordLength()))
+	if tbi.GetCompression() != io.NOCOMPRESSION {
+		size = colfile.EmptyFileSize(tbi)
+	}
+	if err = io.WriteHeader(fp, tbi); err == nil {
+		err = fp.Truncate(size)
+	}
+	if err != nil {
+		fp.Close()
+		return nil, err
+	}
+	return fp, nil
+}
+
+func rewriteFixed(oldTbi, newTbi *io.TimeBucketInfo, sources []int) error {
+	fp, err := createYearFile(newTbi)
+	if err != nil {
+		return err
+	}
+	var dst interface {
+		goio.WriterAt
+		goio.Closer
+	} = fp
+	var src interface {
+		goio.ReaderAt
+		goio.Closer
+	}
+	if oldTbi.GetCompression() != io.NOCOMPRESSION {
+		fp.Close()
+		if dst, err = colfile.Open(newTbi.Path, os.O_RDWR); err != nil {
+			return err
+		}
+		src, err = colfile.Open(oldTbi.Path, os.O_RDONLY)
+	} else {
+		src, err = os.Open(oldTbi.Path)
+	}
+	if err != nil {
+		dst.Close()
+		return err
+	}
+	defer src.Close()
+
+	oldLength := int64(oldTbi.GetRecordLength())
+	newLength := int64(newTbi.GetRecordLength())
+	copies := fieldCopies(oldTbi, newTbi, sources, 8)
+	nRecords := (io.FileSize(oldTbi.GetTimeframe(), int(oldTbi.Year), int(oldLength)) - io.Headersize) / oldLength
+	buffer := make([]byte, RecordsPerRead*oldLength)
+	record := make([]byte, newLength)
+	for first := int64(0); first < nRecords; first += RecordsPerRead {
+		count := nRecords - first
+		if count > RecordsPerRead {
+			count = RecordsPerRead
+		}
+		if _, err = src.ReadAt(buffer[:count*oldLength], io.Headersize+first*oldLength); err != nil {
+			dst.Close()
+			return err
+		}
+		for i := int64(0); i < count; i++ {
+			old := buffer[i*oldLength : (i+1)*oldLength]
+			if binary.LittleEndian.Uint64(old) == 0 {
+				// Holes are left unwritten, keeping the new file sparse
+				continue
+			}
+			for j := range record {
+				record[j] = 0
+			}
+			copy(record, old[:8])
+			for _, fc := range copies {
+				copy(record[fc.to:fc.to+fc.size], old[fc.from:fc.from+fc.size])
+			}
+			if _, err = dst.WriteAt(record, io.Headersize+(first+i)*newLength); err != nil {
+				dst.Close()
+				return err
+			}
+		}
+	}
+	return dst.Close()
+}
+
+func rewriteVariable(oldTbi, newTbi *io.TimeBucketInfo, sources []int) error {
+	dst, err := createYearFile(newTbi)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+	src, err := os.Open(oldTbi.Path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	// Variable rows are the packed fields followed by the timestamp
+	oldLength := int(oldTbi.GetVariableRecordLength())
+	newLength := int(newTbi.GetVariableRecordLength())
+	trailer := oldTbi.GetTimestampFormat().Size()
+	copies := fieldCopies(oldTbi, newTbi, sources, 0)
+	compressed := !utils.InstanceConfig.DisableVariableCompression
+
+	nRecords := (io.FileSize(oldTbi.GetTimeframe(), int(oldTbi.Year), 24) - io.Headersize) / 24
+	end := io.FileSize(newTbi.GetTimeframe(), int(newTbi.Year), 24)
+	pointer := make([]byte, 24) // {Index, Offset, Len}
+	for i := int64(0); i < nRecords; i++ {
+		pointerOffset := io.Headersize + i*24
+		if _, err = src.ReadAt(pointer, pointerOffset); err != nil {
+			return err
+		}
+		index := int64(binary.LittleEndian.Uint64(pointer))
+		if index == 0 {
+			continue
+		}
+		data := make([]byte, binary.LittleEndian.Uint64(pointer[16:]))
+		if _, err = src.ReadAt(data, int64(binary.LittleEndian.Uint64(pointer[8:]))); err != nil {
+			return err
+		}
+		if compressed {
+			if data, err = snappy.Decode(nil, data); err != nil {
+				return err
+			}
+		}
+		rows := len(data) / oldLength
+		newData := make([]byte, rows*newLength)
+		for r := 0; r < rows; r++ {
+			old := data[r*oldLength : (r+1)*oldLength]
+			row := newData[r*newLength : (r+1)*newLength]
+			for _, fc := range copies {
+				copy(row[fc.to:fc.to+fc.size], old[fc.from:fc.from+fc.size])
+			}
+			copy(row[newLength-trailer:], old[oldLength-trailer:])
+		}
+		if compressed {
+			newData = snappy.Encode(nil, newData)
+		}
+		if _, err = dst.WriteAt(newData, end); err != nil {
+			return err
+		}
+		binary.LittleEndian.PutUint64(pointer[8:], uint64(end))
+		binary.LittleEndian.PutUint64(pointer[16:], uint64(len(newData)))
+		if _, err = dst.WriteAt(pointer, pointerOffset); err != nil {
+			return err
+		}
+		end += int64(len(newData))
+	}
+	return dst.Close()
+}
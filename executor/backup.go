@@ -0,0 +1,117 @@
+package executor
+
+import (
+	"path/filepath"
+	"sync"
+
+	"github.com/alpacahq/marketstore/executor/backup"
+	"github.com/alpacahq/marketstore/utils/io"
+	"github.com/alpacahq/marketstore/utils/log"
+)
+
+/*
+Backup writes a snapshot of the root directory to dest, which is a directory
+or a tarball when it ends with ".tar.gz". The WAL is checkpointed first and
+the files are frozen, so the snapshot is consistent and does not need the WAL
+files, which are left out. The writes are only held while the files are
+listed, and then while a file they change is copied.
+*/
+func Backup(dest string) (*backup.Manifest, error) {
+	fd, err := freezeRootDir(isWALFile, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer thaw(fd)
+	manifest, err := backup.SnapshotFrozen(fd, dest)
+	if err != nil {
+		return nil, err
+	}
+	log.Info("Backed up %d files (%d bytes) to %s", len(manifest.Files), manifest.TotalSize(), dest)
+	return manifest, nil
+}
+
+/*
+BackupIncremental writes an incremental snapshot of the root directory to the
+store directory, freezing the files like Backup. Only the chunks of the files
+missing from the previous snapshots are written.
+*/
+func BackupIncremental(storeDir string) (*backup.IncrementalResult, error) {
+	fd, err := freezeRootDir(isWALFile, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer thaw(fd)
+	result, err := backup.SnapshotIncrementalFrozen(fd, backup.NewDirStore(storeDir))
+	if err != nil {
+		return nil, err
+	}
+	log.Info("Backed up %d changed files of %d to %s, writing %d chunks (%d bytes)",
+		result.FilesChanged, len(result.Manifest.Files), result.Key, result.ChunksWritten, result.BytesWritten)
+	return result, nil
+}
+
+func isWALFile(path string) bool {
+	return filepath.Ext(path) == ".walfile"
+}
+
+// frozen holds the frozen root directories being read by snapshots
+var frozen struct {
+	sync.Mutex
+	dirs []*backup.FrozenDir
+}
+
+/*
+freezeRootDir flushes the pending writes, checkpoints the WAL and freezes the
+files of the root directory, for which skip returns false. The writes are
+held meanwhile, and fn is run before they resume. Until the directory is
+thawed, the files are preserved by the writers changing them.
+*/
+func freezeRootDir(skip func(relPath string) bool, fn func()) (fd *backup.FrozenDir, err error) {
+	err = ThisInstance.WALFile.RunQuiesced(func() error {
+		if fd, err = backup.Freeze(ThisInstance.RootDir, skip); err != nil {
+			return err
+		}
+		frozen.Lock()
+		frozen.dirs = append(frozen.dirs, fd)
+		frozen.Unlock()
+		if fn != nil {
+			fn()
+		}
+		return nil
+	})
+	return fd, err
+}
+
+// thaw stops preserving the files of a frozen directory
+func thaw(fd *backup.FrozenDir) {
+	frozen.Lock()
+	for i, dir := range frozen.dirs {
+		if dir == fd {
+			frozen.dirs = append(frozen.dirs[:i], frozen.dirs[i+1:]...)
+			break
+		}
+	}
+	frozen.Unlock()
+	fd.Close()
+}
+
+/*
+preserveFrozen preserves a file of the root directory for the snapshots
+being taken, before it changes or is removed
+*/
+func preserveFrozen(fullPath string) {
+	frozen.Lock()
+	dirs := append([]*backup.FrozenDir{}, frozen.dirs...)
+	frozen.Unlock()
+	for _, fd := range dirs {
+		fd.Preserve(fullPath)
+	}
+}
+
+// PreserveYearFiles preserves year files about to be removed, for the
+// snapshots being taken
+func PreserveYearFiles(tbis []*io.TimeBucketInfo) {
+	for _, tbi := range tbis {
+		preserveFrozen(tbi.Path)
+	}
+}
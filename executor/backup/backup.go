@@ -0,0 +1,504 @@
+/*
+Package backup writes and restores snapshots of a root directory.
+
+A snapshot is either a directory holding a copy of the files, or a gzipped
+tarball when its path ends with ".tar.gz" or ".tgz". Both include a manifest
+listing the size and SHA-256 checksum of each file, which restore verifies.
+Runs of zeros, such as the empty intervals of sparse year files, are not
+written to disk when copying or restoring.
+*/
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alpacahq/marketstore/utils"
+)
+
+// ManifestName is the name of the manifest in a snapshot
+const ManifestName = "manifest.json"
+
+const blockSize = 64 * 1024
+
+// Manifest describes the files of a snapshot
+type Manifest struct {
+	Version string    `json:"version"`
+	GitHash string    `json:"git_hash"`
+	Created time.Time `json:"created"`
+	// ChunkSize is the size of the chunks of the files of incremental snapshots
+	ChunkSize int64  `json:"chunk_size,omitempty"`
+	Files     []File `json:"files"`
+}
+
+// File is a file of a snapshot, with its slash separated path relative to the
+// root directory
+type File struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+	// ModTime in unix epoch nanoseconds and Chunks, the checksums of the chunks
+	// of the file, are only set in incremental snapshots. The chunks of zeros
+	// are not stored and have an empty checksum.
+	ModTime int64    `json:"mod_time,omitempty"`
+	Chunks  []string `json:"chunks,omitempty"`
+}
+
+// TotalSize returns the number of bytes of the files of the snapshot
+func (m *Manifest) TotalSize() (size int64) {
+	for _, f := range m.Files {
+		size += f.Size
+	}
+	return size
+}
+
+// IsArchive tells whether a snapshot path designates a tarball
+func IsArchive(path string) bool {
+	return strings.HasSuffix(path, ".tar.gz") || strings.HasSuffix(path, ".tgz")
+}
+
+/*
+Snapshot copies the regular files of the root directory to dest, for which
+skip returns false. The caller makes sure the files are not written to until
+Snapshot returns. The destination must not exist.
+*/
+func Snapshot(rootDir, dest string, skip func(relPath string) bool) (*Manifest, error) {
+	fd, err := Freeze(rootDir, skip)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+	return SnapshotFrozen(fd, dest)
+}
+
+// SnapshotFrozen copies the files of a frozen directory to dest, which must
+// not exist
+func SnapshotFrozen(fd *FrozenDir, dest string) (*Manifest, error) {
+	if err := checkDestination(fd.Root(), dest); err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(dest); !os.IsNotExist(err) {
+		return nil, fmt.Errorf("Backup destination %s already exists", dest)
+	}
+
+	manifest := &Manifest{
+		Version: utils.Tag,
+		GitHash: utils.GitHash,
+		Created: time.Now().UTC(),
+	}
+	var err error
+	if IsArchive(dest) {
+		err = writeArchive(fd, dest, manifest)
+	} else {
+		err = writeDirectory(fd, dest, manifest)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// checkDestination makes sure a backup is not written inside the root directory
+func checkDestination(rootDir, dest string) error {
+	rootDir, err := filepath.Abs(rootDir)
+	if err != nil {
+		return err
+	}
+	absDest, err := filepath.Abs(dest)
+	if err != nil {
+		return err
+	}
+	if rel, err := filepath.Rel(rootDir, absDest); err == nil && !strings.HasPrefix(rel, "..") {
+		return fmt.Errorf("Backup destination %s is inside the root directory", dest)
+	}
+	return nil
+}
+
+// listFiles returns the sorted slash separated paths of the regular files of
+// the root directory, for which skip returns false
+func listFiles(rootDir string, skip func(relPath string) bool) (paths []string, err error) {
+	err = filepath.Walk(rootDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(rootDir, path)
+		if err != nil {
+			return err
+		}
+		if skip == nil || !skip(filepath.ToSlash(rel)) {
+			paths = append(paths, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+func writeDirectory(fd *FrozenDir, dest string, manifest *Manifest) error {
+	if err := os.MkdirAll(dest, 0700); err != nil {
+		return err
+	}
+	for _, f := range fd.Files() {
+		err := fd.read(f, func(r io.Reader) error {
+			file, err := copyFile(filepath.Join(dest, filepath.FromSlash(f.Path)), r)
+			if err != nil {
+				return err
+			}
+			file.Path = f.Path
+			manifest.Files = append(manifest.Files, file)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeSynced(filepath.Join(dest, ManifestName), data)
+}
+
+func writeArchive(fd *FrozenDir, dest string, manifest *Manifest) (err error) {
+	if err = os.MkdirAll(filepath.Dir(dest), 0700); err != nil {
+		return err
+	}
+	fp, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := fp.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+	gz := gzip.NewWriter(fp)
+	tw := tar.NewWriter(gz)
+	for _, f := range fd.Files() {
+		err = fd.read(f, func(r io.Reader) error {
+			file, err := archiveFile(tw, f, r)
+			if err != nil {
+				return err
+			}
+			manifest.Files = append(manifest.Files, file)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	header := &tar.Header{Name: ManifestName, Mode: 0600, Size: int64(len(data)), ModTime: manifest.Created}
+	if err = tw.WriteHeader(header); err != nil {
+		return err
+	}
+	if _, err = tw.Write(data); err != nil {
+		return err
+	}
+	if err = tw.Close(); err != nil {
+		return err
+	}
+	if err = gz.Close(); err != nil {
+		return err
+	}
+	return fp.Sync()
+}
+
+func archiveFile(tw *tar.Writer, f FrozenFile, src io.Reader) (File, error) {
+	header := &tar.Header{Name: f.Path, Mode: 0600, Size: f.Size, ModTime: f.ModTime}
+	if err := tw.WriteHeader(header); err != nil {
+		return File{}, err
+	}
+	hash := sha256.New()
+	n, err := io.Copy(io.MultiWriter(tw, hash), src)
+	if err != nil {
+		return File{}, err
+	}
+	if n != f.Size {
+		return File{}, fmt.Errorf("%s changed size during the backup", f.Path)
+	}
+	return File{Path: f.Path, Size: n, SHA256: hex.EncodeToString(hash.Sum(nil))}, nil
+}
+
+/*
+Restore restores a snapshot to the root directory, which must not exist or be
+empty. The files are restored next to it and moved into place once their
+checksums match the manifest. The snapshot is a directory, a tarball, a
+directory holding incremental snapshots, of which the latest is restored, or
+the manifest of one of them.
+*/
+func Restore(src, rootDir string) (*Manifest, error) {
+	if entries, err := readDirNames(rootDir); err == nil && len(entries) > 0 {
+		return nil, fmt.Errorf("Restore destination %s is not empty", rootDir)
+	}
+	staging, manifest, err := stage(src, rootDir)
+	if err != nil {
+		return nil, err
+	}
+	os.Remove(rootDir) // An empty destination is replaced
+	if err = os.Rename(staging, rootDir); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+/*
+Replace replaces the files of the root directory, which can be in use, with
+the ones of a snapshot. Each file is moved into place once all of them are
+verified, and the files missing from the snapshot are removed, unless keep
+returns true for them.
+*/
+func Replace(src, rootDir string, keep func(relPath string) bool) (*Manifest, error) {
+	staging, manifest, err := stage(src, rootDir)
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(staging)
+
+	inSnapshot := make(map[string]bool)
+	for _, f := range manifest.Files {
+		inSnapshot[f.Path] = true
+		path := filepath.Join(rootDir, filepath.FromSlash(f.Path))
+		if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			return nil, err
+		}
+		if err = os.Rename(stagedPath(staging, f.Path), path); err != nil {
+			return nil, err
+		}
+	}
+	paths, err := listFiles(rootDir, keep)
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range paths {
+		if !inSnapshot[path] {
+			if err = os.Remove(filepath.Join(rootDir, filepath.FromSlash(path))); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return manifest, nil
+}
+
+// stage restores a snapshot next to the root directory and verifies it
+func stage(src, rootDir string) (staging string, manifest *Manifest, err error) {
+	staging = filepath.Clean(rootDir) + ".restore"
+	if err := os.RemoveAll(staging); err != nil {
+		return "", nil, err
+	}
+
+	var restored map[string]File
+	if IsArchive(src) {
+		manifest, restored, err = extractArchive(src, staging)
+	} else if isIncremental(src) {
+		manifest, restored, err = extractIncremental(src, staging)
+	} else {
+		manifest, restored, err = extractDirectory(src, staging)
+	}
+	if err == nil {
+		err = verify(manifest, restored)
+	}
+	if err != nil {
+		os.RemoveAll(staging)
+		return "", nil, err
+	}
+	return staging, manifest, nil
+}
+
+func extractDirectory(src, staging string) (*Manifest, map[string]File, error) {
+	data, err := ioutil.ReadFile(filepath.Join(src, ManifestName))
+	if err != nil {
+		return nil, nil, err
+	}
+	manifest := &Manifest{}
+	if err = json.Unmarshal(data, manifest); err != nil {
+		return nil, nil, fmt.Errorf("Invalid manifest in %s: %v", src, err)
+	}
+	restored := make(map[string]File)
+	for _, f := range manifest.Files {
+		fp, err := os.Open(filepath.Join(src, filepath.FromSlash(f.Path)))
+		if err != nil {
+			return nil, nil, err
+		}
+		file, err := copyFile(stagedPath(staging, f.Path), fp)
+		fp.Close()
+		if err != nil {
+			return nil, nil, err
+		}
+		restored[f.Path] = file
+	}
+	return manifest, restored, nil
+}
+
+func extractArchive(src, staging string) (*Manifest, map[string]File, error) {
+	fp, err := os.Open(src)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer fp.Close()
+	gz, err := gzip.NewReader(fp)
+	if err != nil {
+		return nil, nil, err
+	}
+	tr := tar.NewReader(gz)
+
+	var manifest *Manifest
+	restored := make(map[string]File)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if header.Name == ManifestName {
+			manifest = &Manifest{}
+			if err = json.NewDecoder(tr).Decode(manifest); err != nil {
+				return nil, nil, fmt.Errorf("Invalid manifest in %s: %v", src, err)
+			}
+			continue
+		}
+		file, err := copyFile(stagedPath(staging, header.Name), tr)
+		if err != nil {
+			return nil, nil, err
+		}
+		restored[header.Name] = file
+	}
+	if manifest == nil {
+		return nil, nil, fmt.Errorf("No manifest in %s", src)
+	}
+	return manifest, restored, nil
+}
+
+// stagedPath keeps the restored files inside the staging directory
+func stagedPath(staging, path string) string {
+	return filepath.Join(staging, filepath.Clean("/"+filepath.FromSlash(path)))
+}
+
+func verify(manifest *Manifest, restored map[string]File) error {
+	if len(restored) != len(manifest.Files) {
+		return fmt.Errorf("The snapshot holds %d files, its manifest lists %d", len(restored), len(manifest.Files))
+	}
+	for _, f := range manifest.Files {
+		got, ok := restored[f.Path]
+		if !ok {
+			return fmt.Errorf("%s is missing from the snapshot", f.Path)
+		}
+		if got.Size != f.Size || got.SHA256 != f.SHA256 {
+			return fmt.Errorf("%s does not match its checksum in the manifest", f.Path)
+		}
+	}
+	return nil
+}
+
+/*
+copyFile writes the content of src to a new file at path, seeking over the
+blocks of zeros so that they are left as holes, and returns its size and
+checksum.
+*/
+func copyFile(path string, src io.Reader) (File, error) {
+	return copySparse(path, src, true)
+}
+
+// copySparse copies like copyFile, only syncing the new file when asked to
+func copySparse(path string, src io.Reader, sync bool) (File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return File{}, err
+	}
+	dst, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return File{}, err
+	}
+	defer dst.Close()
+
+	hash := sha256.New()
+	buffer := make([]byte, blockSize)
+	var size int64
+	for {
+		n, err := io.ReadFull(src, buffer)
+		if n > 0 {
+			block := buffer[:n]
+			hash.Write(block)
+			if isZero(block) {
+				_, err = dst.Seek(int64(n), io.SeekCurrent)
+			} else {
+				_, err = dst.Write(block)
+			}
+			if err != nil {
+				return File{}, err
+			}
+			size += int64(n)
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return File{}, err
+		}
+	}
+	// Trailing holes are only allocated by the size
+	if err = dst.Truncate(size); err != nil {
+		return File{}, err
+	}
+	if sync {
+		if err = dst.Sync(); err != nil {
+			return File{}, err
+		}
+	}
+	return File{Size: size, SHA256: hex.EncodeToString(hash.Sum(nil))}, nil
+}
+
+func isZero(block []byte) bool {
+	for _, b := range block {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func writeSynced(path string, data []byte) error {
+	fp, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err = fp.Write(data); err != nil {
+		fp.Close()
+		return err
+	}
+	if err = fp.Sync(); err != nil {
+		fp.Close()
+		return err
+	}
+	return fp.Close()
+}
+
+func readDirNames(dir string) ([]string, error) {
+	fp, err := os.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer fp.Close()
+	return fp.Readdirnames(-1)
+}
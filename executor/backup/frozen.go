@@ -0,0 +1,167 @@
+package backup
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+/*
+FrozenDir holds the regular files of a directory as they were when it was
+frozen, while they keep being written to. A writer calls Preserve before
+changing or removing a file, which copies the file to a staging directory
+next to the root directory unless the snapshot read it already. The snapshot
+then reads each file either from the directory or from its copy, so that a
+writer is only held while the one file it changes is copied.
+*/
+type FrozenDir struct {
+	root    string
+	staging string
+	files   []FrozenFile
+	states  map[string]*frozenState // keyed by full path
+}
+
+// FrozenFile is a file of a FrozenDir, with its slash separated path relative
+// to the root directory and its size and modification time when frozen
+type FrozenFile struct {
+	Path    string
+	Size    int64
+	ModTime time.Time
+}
+
+type frozenState struct {
+	sync.Mutex
+	read   bool   // The snapshot read the file, which can change since
+	staged string // Path of the copy made before the file changed
+	err    error  // Set when the file could not be copied
+}
+
+/*
+Freeze lists the regular files of the root directory, for which skip returns
+false. The caller makes sure that the files are not written to until Freeze
+returns, and that Preserve is called before they change from then on.
+*/
+func Freeze(rootDir string, skip func(relPath string) bool) (*FrozenDir, error) {
+	rootDir, err := filepath.Abs(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	fd := &FrozenDir{
+		root:    rootDir,
+		staging: fmt.Sprintf("%s.snapshot-%d", rootDir, time.Now().UnixNano()),
+		states:  make(map[string]*frozenState),
+	}
+	err = filepath.Walk(rootDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(rootDir, path)
+		if err != nil {
+			return err
+		}
+		if skip != nil && skip(filepath.ToSlash(rel)) {
+			return nil
+		}
+		fd.files = append(fd.files, FrozenFile{Path: filepath.ToSlash(rel), Size: info.Size(), ModTime: info.ModTime()})
+		fd.states[path] = &frozenState{}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(fd.files, func(i, j int) bool { return fd.files[i].Path < fd.files[j].Path })
+	return fd, nil
+}
+
+// Root returns the absolute path of the frozen directory
+func (fd *FrozenDir) Root() string {
+	return fd.root
+}
+
+// Files returns the files of the directory sorted by path
+func (fd *FrozenDir) Files() []FrozenFile {
+	return fd.files
+}
+
+/*
+Preserve copies a file of the directory, given by its full path, before it
+changes or is removed, unless the snapshot read it already. It does nothing
+for the files created since the directory was frozen. A file that can not be
+copied fails the snapshot rather than the writer.
+*/
+func (fd *FrozenDir) Preserve(fullPath string) {
+	fullPath, err := filepath.Abs(fullPath)
+	if err != nil {
+		return
+	}
+	state, ok := fd.states[fullPath]
+	if !ok {
+		return
+	}
+	state.Lock()
+	defer state.Unlock()
+	if state.read || state.staged != "" || state.err != nil {
+		return
+	}
+	rel, err := filepath.Rel(fd.root, fullPath)
+	if err != nil {
+		state.err = err
+		return
+	}
+	staged := stagedPath(fd.staging, filepath.ToSlash(rel))
+	src, err := os.Open(fullPath)
+	if err == nil {
+		_, err = copySparse(staged, src, false)
+		src.Close()
+	}
+	if err != nil {
+		os.Remove(staged)
+		state.err = err
+		return
+	}
+	state.staged = staged
+}
+
+/*
+read calls fn with the content of a file as it was frozen. The file is held
+until fn returns, after which it can change.
+*/
+func (fd *FrozenDir) read(f FrozenFile, fn func(r io.Reader) error) error {
+	fullPath := filepath.Join(fd.root, filepath.FromSlash(f.Path))
+	state := fd.states[fullPath]
+	state.Lock()
+	defer state.Unlock()
+	if state.err != nil {
+		return fmt.Errorf("Unable to preserve %s while it changed: %v", f.Path, state.err)
+	}
+	path := fullPath
+	if state.staged != "" {
+		path = state.staged
+	}
+	fp, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer fp.Close()
+	if err = fn(io.LimitReader(fp, f.Size)); err != nil {
+		return err
+	}
+	state.read = true
+	if state.staged != "" {
+		os.Remove(state.staged)
+		state.staged = ""
+	}
+	return nil
+}
+
+// Close removes the copies of the files that were not read
+func (fd *FrozenDir) Close() error {
+	return os.RemoveAll(fd.staging)
+}
@@ -0,0 +1,317 @@
+package backup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/alpacahq/marketstore/utils"
+)
+
+/*
+Incremental snapshots are kept in an object store with the layout
+
+	snapshots/<created>.json   manifest of each snapshot
+	chunks/<ab>/<abcdef...>    gzipped chunk named after its SHA-256 checksum
+
+Each file is split into chunks at fixed offsets, so that the records written
+in place in a year file only change the chunks holding them. A chunk already
+in the store is not written again, and the files whose size and modification
+time match the previous snapshot are not even read.
+*/
+
+// DefaultChunkSize is the size of the chunks of incremental snapshots
+const DefaultChunkSize = 4 * 1024 * 1024
+
+const (
+	snapshotPrefix     = "snapshots/"
+	chunkPrefix        = "chunks/"
+	snapshotTimeFormat = "20060102T150405.000000000Z"
+)
+
+// IncrementalResult describes an incremental snapshot and what it added to
+// the store
+type IncrementalResult struct {
+	Manifest *Manifest
+	// Key is the key of the manifest in the store
+	Key string
+	// FilesChanged is the number of files read since the previous snapshot
+	FilesChanged  int
+	ChunksWritten int
+	// BytesWritten is the compressed size of the chunks written
+	BytesWritten int64
+}
+
+/*
+SnapshotIncremental writes an incremental snapshot of the regular files of the
+root directory, for which skip returns false, to the store. The caller makes
+sure the files are not written to until SnapshotIncremental returns.
+*/
+func SnapshotIncremental(rootDir string, store ObjectStore, skip func(relPath string) bool) (*IncrementalResult, error) {
+	fd, err := Freeze(rootDir, skip)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+	return SnapshotIncrementalFrozen(fd, store)
+}
+
+// SnapshotIncrementalFrozen writes an incremental snapshot of the files of a
+// frozen directory to the store
+func SnapshotIncrementalFrozen(fd *FrozenDir, store ObjectStore) (*IncrementalResult, error) {
+	if ds, ok := store.(*DirStore); ok {
+		if err := checkDestination(fd.Root(), ds.Dir); err != nil {
+			return nil, err
+		}
+	}
+
+	manifest := &Manifest{
+		Version:   utils.Tag,
+		GitHash:   utils.GitHash,
+		Created:   time.Now().UTC(),
+		ChunkSize: DefaultChunkSize,
+	}
+	previousFiles := make(map[string]File)
+	_, previous, err := latestSnapshot(store)
+	if err != nil {
+		return nil, err
+	}
+	// A file modified in the second before the previous snapshot could have
+	// been modified again since without its modification time changing
+	var unchangedBefore time.Time
+	if previous != nil && previous.ChunkSize == manifest.ChunkSize {
+		for _, f := range previous.Files {
+			previousFiles[f.Path] = f
+		}
+		unchangedBefore = previous.Created.Add(-time.Second)
+	}
+
+	result := &IncrementalResult{Manifest: manifest}
+	for _, f := range fd.Files() {
+		prev, ok := previousFiles[f.Path]
+		if ok && prev.Size == f.Size && prev.ModTime == f.ModTime.UnixNano() &&
+			f.ModTime.Before(unchangedBefore) {
+			manifest.Files = append(manifest.Files, prev)
+			continue
+		}
+		err := fd.read(f, func(r io.Reader) error {
+			file, err := storeFile(store, r, manifest.ChunkSize, result)
+			if err != nil {
+				return err
+			}
+			file.Path = f.Path
+			file.ModTime = f.ModTime.UnixNano()
+			manifest.Files = append(manifest.Files, file)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		result.FilesChanged++
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	result.Key = snapshotPrefix + manifest.Created.Format(snapshotTimeFormat) + ".json"
+	if err = store.Put(result.Key, data); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// storeFile writes the chunks of the file missing from the store
+func storeFile(store ObjectStore, src io.Reader, chunkSize int64, result *IncrementalResult) (File, error) {
+	var file File
+	hash := sha256.New()
+	buffer := make([]byte, chunkSize)
+	for {
+		n, err := io.ReadFull(src, buffer)
+		if n > 0 {
+			chunk := buffer[:n]
+			hash.Write(chunk)
+			file.Size += int64(n)
+			sum, err := storeChunk(store, chunk, result)
+			if err != nil {
+				return File{}, err
+			}
+			file.Chunks = append(file.Chunks, sum)
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return File{}, err
+		}
+	}
+	file.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	return file, nil
+}
+
+func storeChunk(store ObjectStore, chunk []byte, result *IncrementalResult) (string, error) {
+	if isZero(chunk) {
+		return "", nil
+	}
+	digest := sha256.Sum256(chunk)
+	sum := hex.EncodeToString(digest[:])
+	exists, err := store.Exists(chunkKey(sum))
+	if err != nil || exists {
+		return sum, err
+	}
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	if _, err = gz.Write(chunk); err != nil {
+		return "", err
+	}
+	if err = gz.Close(); err != nil {
+		return "", err
+	}
+	if err = store.Put(chunkKey(sum), compressed.Bytes()); err != nil {
+		return "", err
+	}
+	result.ChunksWritten++
+	result.BytesWritten += int64(compressed.Len())
+	return sum, nil
+}
+
+func chunkKey(sum string) string {
+	return chunkPrefix + sum[:2] + "/" + sum
+}
+
+func loadSnapshot(store ObjectStore, key string) (*Manifest, error) {
+	data, err := store.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	manifest := &Manifest{}
+	if err = json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("Invalid manifest %s: %v", key, err)
+	}
+	return manifest, nil
+}
+
+// latestSnapshot returns a nil manifest when the store holds no snapshot
+func latestSnapshot(store ObjectStore) (string, *Manifest, error) {
+	keys, err := store.List(snapshotPrefix)
+	if err != nil || len(keys) == 0 {
+		return "", nil, err
+	}
+	key := keys[len(keys)-1]
+	manifest, err := loadSnapshot(store, key)
+	return key, manifest, err
+}
+
+// isIncremental tells whether the path is a store of incremental snapshots, or
+// the manifest of one of them
+func isIncremental(path string) bool {
+	if strings.HasSuffix(path, ".json") {
+		return filepath.Base(filepath.Dir(path))+"/" == snapshotPrefix
+	}
+	info, err := os.Stat(filepath.Join(path, strings.TrimSuffix(snapshotPrefix, "/")))
+	return err == nil && info.IsDir()
+}
+
+func extractIncremental(src, staging string) (*Manifest, map[string]File, error) {
+	var (
+		store    *DirStore
+		manifest *Manifest
+		err      error
+	)
+	if strings.HasSuffix(src, ".json") {
+		store = NewDirStore(filepath.Dir(filepath.Dir(src)))
+		manifest, err = loadSnapshot(store, snapshotPrefix+filepath.Base(src))
+	} else {
+		store = NewDirStore(src)
+		_, manifest, err = latestSnapshot(store)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if manifest == nil {
+		return nil, nil, fmt.Errorf("No snapshot in %s", src)
+	}
+
+	restored := make(map[string]File)
+	for _, f := range manifest.Files {
+		cr := &chunkReader{
+			store:     store,
+			chunks:    f.Chunks,
+			chunkSize: manifest.ChunkSize,
+			remaining: f.Size,
+		}
+		file, err := copyFile(stagedPath(staging, f.Path), cr)
+		if err != nil {
+			return nil, nil, err
+		}
+		restored[f.Path] = file
+	}
+	return manifest, restored, nil
+}
+
+// chunkReader reads a file of an incremental snapshot from its chunks
+type chunkReader struct {
+	store     ObjectStore
+	chunks    []string
+	chunkSize int64
+	remaining int64
+	buffer    []byte
+	zeros     []byte
+}
+
+func (cr *chunkReader) Read(p []byte) (int, error) {
+	for len(cr.buffer) == 0 {
+		if len(cr.chunks) == 0 || cr.remaining == 0 {
+			return 0, io.EOF
+		}
+		size := cr.chunkSize
+		if cr.remaining < size {
+			size = cr.remaining
+		}
+		if cr.chunks[0] == "" {
+			if cr.zeros == nil {
+				cr.zeros = make([]byte, cr.chunkSize)
+			}
+			cr.buffer = cr.zeros[:size]
+		} else {
+			chunk, err := cr.readChunk(cr.chunks[0])
+			if err != nil {
+				return 0, err
+			}
+			if int64(len(chunk)) != size {
+				return 0, fmt.Errorf("Chunk %s has %d bytes instead of %d", cr.chunks[0], len(chunk), size)
+			}
+			cr.buffer = chunk
+		}
+		cr.chunks = cr.chunks[1:]
+		cr.remaining -= size
+	}
+	n := copy(p, cr.buffer)
+	cr.buffer = cr.buffer[n:]
+	return n, nil
+}
+
+func (cr *chunkReader) readChunk(sum string) ([]byte, error) {
+	if len(sum) != 2*sha256.Size {
+		return nil, fmt.Errorf("Invalid chunk checksum %q", sum)
+	}
+	data, err := cr.store.Get(chunkKey(sum))
+	if err != nil {
+		return nil, err
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("Invalid chunk %s: %v", sum, err)
+	}
+	defer gz.Close()
+	return ioutil.ReadAll(gz)
+}
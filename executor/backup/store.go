@@ -0,0 +1,111 @@
+package backup
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+/*
+ObjectStore is a flat store of objects named by slash separated keys, as the
+buckets of S3 compatible object stores. Incremental snapshots are written to
+one through this interface.
+*/
+type ObjectStore interface {
+	Put(key string, data []byte) error
+	Get(key string) ([]byte, error)
+	Exists(key string) (bool, error)
+	// List returns the sorted keys starting with the prefix
+	List(prefix string) ([]string, error)
+}
+
+// DirStore is an ObjectStore keeping each object in a file of a local
+// directory, at the path of its key
+type DirStore struct {
+	Dir string
+}
+
+// NewDirStore returns a store in the directory, which is created by the first
+// object put in it
+func NewDirStore(dir string) *DirStore {
+	return &DirStore{Dir: dir}
+}
+
+func (ds *DirStore) path(key string) (string, error) {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains("/"+key+"/", "/../") {
+		return "", fmt.Errorf("Invalid object key %q", key)
+	}
+	return filepath.Join(ds.Dir, filepath.FromSlash(key)), nil
+}
+
+// Put writes the object to a temporary file first, so that a stored object
+// is always complete
+func (ds *DirStore) Put(key string, data []byte) error {
+	path, err := ds.path(key)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err = writeSynced(tmp, data); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (ds *DirStore) Get(key string) ([]byte, error) {
+	path, err := ds.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadFile(path)
+}
+
+func (ds *DirStore) Exists(key string) (bool, error) {
+	path, err := ds.path(key)
+	if err != nil {
+		return false, err
+	}
+	_, err = os.Stat(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// List only walks the directory of the prefix, so that listing the snapshots
+// does not walk the chunks
+func (ds *DirStore) List(prefix string) ([]string, error) {
+	var keys []string
+	dir := filepath.Join(ds.Dir, filepath.FromSlash(prefix[:strings.LastIndex(prefix, "/")+1]))
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if os.IsNotExist(err) && path == dir {
+			return filepath.SkipDir
+		}
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() || strings.HasSuffix(path, ".tmp") {
+			return nil
+		}
+		rel, err := filepath.Rel(ds.Dir, path)
+		if err != nil {
+			return err
+		}
+		if key := filepath.ToSlash(rel); strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
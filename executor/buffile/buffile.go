@@ -24,6 +24,9 @@ type BufferedFile struct {
 	blockSize    int
 	buffer       []byte
 	bufferOffset int64
+	// The range of the buffer written to, so that untouched blocks of a
+	// sparse file are not allocated by writing back their zeros
+	dirtyStart, dirtyEnd int
 }
 
 const defaultBlockSize = 32 * 1024
@@ -72,11 +75,12 @@ func (f *BufferedFile) readBuffer(offset int64, size int) error {
 }
 
 func (f *BufferedFile) writeBuffer() error {
-	if f.buffer != nil {
-		if _, err := f.fp.WriteAt(f.buffer, f.bufferOffset); err != nil {
+	if f.buffer != nil && f.dirtyEnd > f.dirtyStart {
+		if _, err := f.fp.WriteAt(f.buffer[f.dirtyStart:f.dirtyEnd], f.bufferOffset+int64(f.dirtyStart)); err != nil {
 			return err
 		}
 	}
+	f.dirtyStart, f.dirtyEnd = 0, 0
 	return nil
 }
 
@@ -104,7 +108,17 @@ func (f *BufferedFile) WriteAt(data []byte, offset int64) (int, error) {
 	if err := f.ensureBuffer(data, offset); err != nil {
 		return 0, err
 	}
-	writePos := offset - f.bufferOffset
+	writePos := int(offset - f.bufferOffset)
 	n := copy(f.buffer[writePos:], data)
+	if f.dirtyEnd == f.dirtyStart {
+		f.dirtyStart, f.dirtyEnd = writePos, writePos+n
+	} else {
+		if writePos < f.dirtyStart {
+			f.dirtyStart = writePos
+		}
+		if writePos+n > f.dirtyEnd {
+			f.dirtyEnd = writePos + n
+		}
+	}
 	return n, nil
 }
@@ -21,6 +21,10 @@ type WriteCommand struct {
 	VarRecLen     int32
 	Offset, Index int64
 	Data          []byte
+	// DeletedIndex is the index of the record deleted by a command writing
+	// an empty record (Index 0), for the delete triggers. It is not written
+	// to the WAL.
+	DeletedIndex int64
 }
 
 // Convert WriteCommand to string for debuging/presentation
@@ -34,6 +38,8 @@ type TransactionPipe struct {
 	tgID         int64              // Current transaction group ID
 	writeChannel chan *WriteCommand // Channel for write commands
 	flushChannel chan chan struct{} // Channel for flush request
+	syncChannel  chan chan error    // Channel for durable flush requests
+	taskChannel  chan func()        // Channel for work run by the WAL writer
 }
 
 // NewTransactionPipe creates a new transaction pipe that channels all
@@ -43,6 +49,8 @@ func NewTransactionPipe() *TransactionPipe {
 	// Allocate the write channel with enough depth to allow all conceivable writers concurrent access
 	tgc.writeChannel = make(chan *WriteCommand, WriteChannelCommandDepth)
 	tgc.flushChannel = make(chan chan struct{}, WriteChannelCommandDepth)
+	tgc.syncChannel = make(chan chan error, WriteChannelCommandDepth)
+	tgc.taskChannel = make(chan func(), WriteChannelCommandDepth)
 	tgc.NewTGID()
 	return tgc
 }
@@ -0,0 +1,456 @@
+// package colfile reads and writes fixed length time bucket files stored
+// in compressed, columnar blocks, presenting them with the offsets of the
+// uncompressed layout so that callers seek to records as usual.
+package colfile
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	stdio "io"
+	"os"
+	"sort"
+	"unsafe"
+
+	"github.com/alpacahq/marketstore/utils/io"
+)
+
+/*
+A compressed file starts with the usual header, followed by a directory with
+the {offset, length, checksum} of each block of RecordsPerBlock record slots,
+and the blocks themselves. An empty block has a zero length and takes no space.
+
+	[Header][directory][block][block]...
+
+Blocks are never rewritten in place. A modified block is written to free
+space, synced, and only then referenced by its directory entry, so that a
+crash leaves either the old or the new block in the file. The space the old
+block leaves behind is reused by later blocks, and freed by Compact. The
+CRC-32C checksum of the block in its entry lets readers detect a block that
+is torn or was replaced while it was read.
+*/
+
+// RecordsPerBlock is the number of record slots compressed together
+const RecordsPerBlock = 4096
+
+const blockRefSize = 16
+
+const maxReadAttempts = 3
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+type blockRef struct {
+	Offset   int64
+	Length   int64
+	Checksum uint32
+}
+
+// File is a compressed fixed length file with the byte layout of an
+// uncompressed one. A single block is kept decoded, and written back when
+// another block is accessed or the file is flushed or closed. File does not
+// provide any concurrency guarantee.
+type File struct {
+	fp           *os.File
+	recordLength int64
+	nRecords     int64
+	columns      []column
+	directory    []blockRef
+
+	block    []byte // Decoded records of the current block
+	blockNum int64  // -1 when no block is decoded
+	dirty    bool
+
+	position int64
+}
+
+// NumBlocks returns the number of blocks in a compressed file for a bucket
+func NumBlocks(tbi *io.TimeBucketInfo) int64 {
+	nRecords := (io.FileSize(tbi.GetTimeframe(), int(tbi.Year), int(tbi.GetRecordLength())) - io.Headersize) /
+		int64(tbi.GetRecordLength())
+	return (nRecords + RecordsPerBlock - 1) / RecordsPerBlock
+}
+
+// EmptyFileSize returns the size of a compressed file without any records,
+// which holds the header and an empty directory
+func EmptyFileSize(tbi *io.TimeBucketInfo) int64 {
+	return io.Headersize + NumBlocks(tbi)*blockRefSize
+}
+
+// IsCompressed reads the header of a file and returns true if its records
+// are compressed
+func IsCompressed(filePath string) (bool, error) {
+	fp, err := os.Open(filePath)
+	if err != nil {
+		return false, err
+	}
+	defer fp.Close()
+	var buffer [io.Headersize]byte
+	if _, err := fp.ReadAt(buffer[:312], 0); err != nil {
+		return false, err
+	}
+	header := (*io.Header)(unsafe.Pointer(&buffer))
+	return io.EnumCompressionType(header.Compression) == io.COLUMNAR, nil
+}
+
+// Open opens a compressed file with the given os.OpenFile flag
+func Open(filePath string, flag int) (*File, error) {
+	fp, err := os.OpenFile(filePath, flag, 0700)
+	if err != nil {
+		return nil, err
+	}
+	f, err := newFile(fp)
+	if err != nil {
+		fp.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+func newFile(fp *os.File) (*File, error) {
+	var buffer [io.Headersize]byte
+	if _, err := fp.ReadAt(buffer[:], 0); err != nil {
+		return nil, fmt.Errorf("Unable to read the header of %s: %s", fp.Name(), err)
+	}
+	tbi := io.NewTimeBucketInfoFromHeader((*io.Header)(unsafe.Pointer(&buffer)), fp.Name())
+	if tbi.GetCompression() != io.COLUMNAR {
+		return nil, fmt.Errorf("%s is not a compressed file", fp.Name())
+	}
+	if tbi.GetRecordType() != io.FIXED {
+		return nil, fmt.Errorf("%s does not have fixed length records", fp.Name())
+	}
+
+	f := &File{
+		fp:           fp,
+		recordLength: int64(tbi.GetRecordLength()),
+		columns:      recordColumns(tbi.GetElementTypes()),
+		blockNum:     -1,
+	}
+	f.nRecords = (io.FileSize(tbi.GetTimeframe(), int(tbi.Year), int(f.recordLength)) - io.Headersize) / f.recordLength
+
+	f.directory = make([]blockRef, NumBlocks(tbi))
+	if err := f.readDirectory(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *File) readDirectory() error {
+	directory := make([]byte, int64(len(f.directory))*blockRefSize)
+	if _, err := f.fp.ReadAt(directory, io.Headersize); err != nil {
+		return fmt.Errorf("Unable to read the block directory of %s: %s", f.fp.Name(), err)
+	}
+	for i := range f.directory {
+		f.directory[i] = decodeBlockRef(directory[i*blockRefSize:])
+	}
+	return nil
+}
+
+/*
+A directory entry holds the offset of the block on 8 bytes, then its length
+and checksum on 4 bytes each
+*/
+func decodeBlockRef(entry []byte) blockRef {
+	return blockRef{
+		Offset:   int64(binary.LittleEndian.Uint64(entry)),
+		Length:   int64(binary.LittleEndian.Uint32(entry[8:])),
+		Checksum: binary.LittleEndian.Uint32(entry[12:]),
+	}
+}
+
+func encodeBlockRef(ref blockRef) []byte {
+	entry := make([]byte, blockRefSize)
+	binary.LittleEndian.PutUint64(entry, uint64(ref.Offset))
+	binary.LittleEndian.PutUint32(entry[8:], uint32(ref.Length))
+	binary.LittleEndian.PutUint32(entry[12:], ref.Checksum)
+	return entry
+}
+
+// Size returns the size of the file in the uncompressed layout
+func (f *File) Size() int64 {
+	return io.Headersize + f.nRecords*f.recordLength
+}
+
+func (f *File) blockBytes() int64 {
+	return RecordsPerBlock * f.recordLength
+}
+
+/*
+loadBlock decodes a block into the block buffer, writing back the one
+currently decoded
+*/
+func (f *File) loadBlock(b int64) error {
+	if b == f.blockNum {
+		return nil
+	}
+	if err := f.Flush(); err != nil {
+		return err
+	}
+	nRecords := f.nRecords - b*RecordsPerBlock
+	if nRecords > RecordsPerBlock {
+		nRecords = RecordsPerBlock
+	}
+	size := nRecords * f.recordLength
+	if int64(cap(f.block)) < size {
+		f.block = make([]byte, size)
+	}
+	f.block = f.block[:size]
+	for i := range f.block {
+		f.block[i] = 0
+	}
+	f.blockNum = -1
+
+	/*
+		A writer can replace the block while it is read, so a block that
+		does not match its checksum is read again with its current
+		directory entry
+	*/
+	var err error
+	for attempt := 0; attempt < maxReadAttempts; attempt++ {
+		if attempt != 0 {
+			if err = f.readBlockRef(b); err != nil {
+				return err
+			}
+			for i := range f.block {
+				f.block[i] = 0
+			}
+		}
+		if err = f.readBlock(b); err == nil {
+			f.blockNum = b
+			return nil
+		}
+	}
+	return fmt.Errorf("%s: block %d: %s", f.fp.Name(), b, err)
+}
+
+func (f *File) readBlock(b int64) error {
+	ref := f.directory[b]
+	if ref.Length == 0 {
+		return nil
+	}
+	data := make([]byte, ref.Length)
+	if _, err := f.fp.ReadAt(data, ref.Offset); err != nil {
+		return err
+	}
+	if crc32.Checksum(data, crcTable) != ref.Checksum {
+		return fmt.Errorf("Checksum mismatch at offset %d", ref.Offset)
+	}
+	return decodeBlock(data, f.block, int(f.recordLength), b*RecordsPerBlock+1, f.columns)
+}
+
+func (f *File) readBlockRef(b int64) error {
+	var entry [blockRefSize]byte
+	if _, err := f.fp.ReadAt(entry[:], io.Headersize+b*blockRefSize); err != nil {
+		return err
+	}
+	f.directory[b] = decodeBlockRef(entry[:])
+	return nil
+}
+
+/*
+storeBlock compresses the decoded block, writes it to free space and syncs
+it, then points its directory entry to it. The entry is synced as well
+before the space of the old block can be reused.
+*/
+func (f *File) storeBlock() error {
+	b := f.blockNum
+	data, err := encodeBlock(f.block, int(f.recordLength), b*RecordsPerBlock+1, f.columns)
+	if err != nil {
+		return fmt.Errorf("%s: block %d: %s", f.fp.Name(), b, err)
+	}
+	var ref blockRef
+	if len(data) != 0 {
+		if ref.Offset, err = f.allocate(int64(len(data))); err != nil {
+			return err
+		}
+		ref.Length = int64(len(data))
+		ref.Checksum = crc32.Checksum(data, crcTable)
+		if _, err = f.fp.WriteAt(data, ref.Offset); err != nil {
+			return err
+		}
+		if err = f.fp.Sync(); err != nil {
+			return err
+		}
+	}
+	if _, err = f.fp.WriteAt(encodeBlockRef(ref), io.Headersize+b*blockRefSize); err != nil {
+		return err
+	}
+	if err = f.fp.Sync(); err != nil {
+		return err
+	}
+	f.directory[b] = ref
+	return f.truncate()
+}
+
+/*
+allocate returns the offset of the first run of space referenced by no
+block, the current one included, that fits a block of the given length
+*/
+func (f *File) allocate(length int64) (int64, error) {
+	if err := f.readDirectory(); err != nil {
+		return 0, err
+	}
+	free := io.Headersize + int64(len(f.directory))*blockRefSize
+	for _, ref := range f.sortedRefs() {
+		if ref.Offset-free >= length {
+			return free, nil
+		}
+		if end := ref.Offset + ref.Length; end > free {
+			free = end
+		}
+	}
+	return free, nil
+}
+
+// truncate removes the space after the last block of the file
+func (f *File) truncate() error {
+	end := io.Headersize + int64(len(f.directory))*blockRefSize
+	for _, ref := range f.directory {
+		if ref.Length != 0 && ref.Offset+ref.Length > end {
+			end = ref.Offset + ref.Length
+		}
+	}
+	fi, err := f.fp.Stat()
+	if err != nil {
+		return err
+	}
+	if fi.Size() > end {
+		return f.fp.Truncate(end)
+	}
+	return nil
+}
+
+func (f *File) sortedRefs() (refs []blockRef) {
+	for _, ref := range f.directory {
+		if ref.Length != 0 {
+			refs = append(refs, ref)
+		}
+	}
+	sort.Slice(refs, func(i, j int) bool { return refs[i].Offset < refs[j].Offset })
+	return refs
+}
+
+// Flush writes the decoded block to the file if it was modified
+func (f *File) Flush() error {
+	if !f.dirty {
+		return nil
+	}
+	if err := f.storeBlock(); err != nil {
+		return err
+	}
+	f.dirty = false
+	return nil
+}
+
+// Compact punches holes over the space left by blocks that were replaced
+// and not reused
+func (f *File) Compact() error {
+	if err := f.Flush(); err != nil {
+		return err
+	}
+	fi, err := f.fp.Stat()
+	if err != nil {
+		return err
+	}
+	refs := append(f.sortedRefs(), blockRef{Offset: fi.Size()})
+
+	free := io.Headersize + int64(len(f.directory))*blockRefSize
+	for _, ref := range refs {
+		if offset, length := io.AlignHole(free, ref.Offset-free); length != 0 {
+			if err := io.PunchHole(f.fp, offset, length); err != nil {
+				return err
+			}
+		}
+		if end := ref.Offset + ref.Length; end > free {
+			free = end
+		}
+	}
+	return nil
+}
+
+// Close flushes the modified records and closes the file
+func (f *File) Close() error {
+	if err := f.Flush(); err != nil {
+		f.fp.Close()
+		return err
+	}
+	return f.fp.Close()
+}
+
+// ReadAt reads records at an offset of the uncompressed layout. Holes read
+// as zeros, as in uncompressed files.
+func (f *File) ReadAt(p []byte, offset int64) (n int, err error) {
+	if offset < io.Headersize {
+		return 0, fmt.Errorf("Read at %d is within the header", offset)
+	}
+	for n < len(p) && offset < f.Size() {
+		b := (offset - io.Headersize) / f.blockBytes()
+		if err := f.loadBlock(b); err != nil {
+			return n, err
+		}
+		copied := copy(p[n:], f.block[offset-io.Headersize-b*f.blockBytes():])
+		n += copied
+		offset += int64(copied)
+	}
+	if n < len(p) {
+		return n, stdio.EOF
+	}
+	return n, nil
+}
+
+// WriteAt writes records at an offset of the uncompressed layout. Bytes
+// before the first record are ignored, as the header is not rewritten.
+func (f *File) WriteAt(p []byte, offset int64) (n int, err error) {
+	if offset < io.Headersize {
+		skip := io.Headersize - offset
+		if skip > int64(len(p)) {
+			skip = int64(len(p))
+		}
+		n = int(skip)
+		offset += skip
+	}
+	if offset+int64(len(p)-n) > f.Size() {
+		return n, fmt.Errorf("Write at %d is past the end of the year", offset)
+	}
+	for n < len(p) {
+		b := (offset - io.Headersize) / f.blockBytes()
+		if err := f.loadBlock(b); err != nil {
+			return n, err
+		}
+		copied := copy(f.block[offset-io.Headersize-b*f.blockBytes():], p[n:])
+		f.dirty = true
+		n += copied
+		offset += int64(copied)
+	}
+	return n, nil
+}
+
+func (f *File) Read(p []byte) (n int, err error) {
+	if f.position >= f.Size() {
+		return 0, stdio.EOF
+	}
+	n, err = f.ReadAt(p, f.position)
+	f.position += int64(n)
+	return n, err
+}
+
+func (f *File) Write(p []byte) (n int, err error) {
+	n, err = f.WriteAt(p, f.position)
+	f.position += int64(n)
+	return n, err
+}
+
+// Seek sets the position of the next Read or Write in the uncompressed
+// layout
+func (f *File) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case stdio.SeekCurrent:
+		offset += f.position
+	case stdio.SeekEnd:
+		offset += f.Size()
+	}
+	if offset < 0 {
+		return f.position, fmt.Errorf("Seek to negative offset %d", offset)
+	}
+	f.position = offset
+	return offset, nil
+}
@@ -0,0 +1,213 @@
+package colfile
+
+import (
+	"encoding/binary"
+	"math"
+	"os"
+	"testing"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/alpacahq/marketstore/utils"
+	"github.com/alpacahq/marketstore/utils/io"
+)
+
+type TestSuite struct{}
+
+// Hook up gocheck into the "go test" runner.
+func Test(t *testing.T) { TestingT(t) }
+
+var _ = Suite(&TestSuite{})
+
+var testShapes = []io.DataShape{
+	{Name: "Open", Type: io.FLOAT32},
+	{Name: "Close", Type: io.FLOAT64},
+	{Name: "Volume", Type: io.INT32},
+	{Name: "Trades", Type: io.UINT64},
+	{Name: "Flag", Type: io.BOOL},
+}
+
+func createFile(c *C) (string, *io.TimeBucketInfo) {
+	tbi := io.NewTimeBucketInfo(*utils.TimeframeFromString("1Min"), c.MkDir(), "Test", 2016, testShapes, io.FIXED)
+	c.Assert(tbi.SetCompression(io.COLUMNAR), IsNil)
+	fp, err := os.OpenFile(tbi.Path, os.O_CREATE|os.O_RDWR, 0600)
+	c.Assert(err, IsNil)
+	c.Assert(io.WriteHeader(fp, tbi), IsNil)
+	c.Assert(fp.Truncate(EmptyFileSize(tbi)), IsNil)
+	c.Assert(fp.Close(), IsNil)
+	return tbi.Path, tbi
+}
+
+func record(tbi *io.TimeBucketInfo, index int64) []byte {
+	buf := make([]byte, tbi.GetRecordLength())
+	binary.LittleEndian.PutUint64(buf, uint64(index))
+	binary.LittleEndian.PutUint32(buf[8:], math.Float32bits(100+float32(index%50)/4))
+	binary.LittleEndian.PutUint64(buf[12:], math.Float64bits(100.25+float64(index%7)))
+	binary.LittleEndian.PutUint32(buf[20:], uint32(int32(1000-index)))
+	binary.LittleEndian.PutUint64(buf[24:], uint64(index*index))
+	buf[32] = byte(index % 2)
+	return buf
+}
+
+func offset(tbi *io.TimeBucketInfo, index int64) int64 {
+	return io.Headersize + (index-1)*int64(tbi.GetRecordLength())
+}
+
+func (s *TestSuite) TestRoundTrip(c *C) {
+	path, tbi := createFile(c)
+	compressed, err := IsCompressed(path)
+	c.Assert(err, IsNil)
+	c.Assert(compressed, Equals, true)
+
+	// Records in the first block with holes, across a block boundary and
+	// at the end of the year
+	var indexes []int64
+	for i := int64(1); i < 3000; i += 3 {
+		indexes = append(indexes, i)
+	}
+	for i := int64(RecordsPerBlock - 10); i < RecordsPerBlock+10; i++ {
+		indexes = append(indexes, i)
+	}
+	nRecords := (io.FileSize(tbi.GetTimeframe(), 2016, int(tbi.GetRecordLength())) - io.Headersize) /
+		int64(tbi.GetRecordLength())
+	indexes = append(indexes, nRecords)
+
+	f, err := Open(path, os.O_RDWR)
+	c.Assert(err, IsNil)
+	for _, index := range indexes {
+		n, err := f.WriteAt(record(tbi, index), offset(tbi, index))
+		c.Assert(err, IsNil)
+		c.Assert(n, Equals, int(tbi.GetRecordLength()))
+	}
+	c.Assert(f.Close(), IsNil)
+
+	fi, err := os.Stat(path)
+	c.Assert(err, IsNil)
+	c.Assert(fi.Size() < EmptyFileSize(tbi)+int64(len(indexes))*int64(tbi.GetRecordLength())/2, Equals, true)
+
+	f, err = Open(path, os.O_RDONLY)
+	c.Assert(err, IsNil)
+	defer f.Close()
+	present := make(map[int64]bool)
+	for _, index := range indexes {
+		present[index] = true
+	}
+	buf := make([]byte, tbi.GetRecordLength())
+	zero := make([]byte, tbi.GetRecordLength())
+	for index := int64(1); index < RecordsPerBlock+20; index++ {
+		_, err := f.ReadAt(buf, offset(tbi, index))
+		c.Assert(err, IsNil)
+		if present[index] {
+			c.Assert(buf, DeepEquals, record(tbi, index))
+		} else {
+			c.Assert(buf, DeepEquals, zero)
+		}
+	}
+
+	// Sequential reads end at the end of the year
+	_, err = f.Seek(offset(tbi, nRecords), os.SEEK_SET)
+	c.Assert(err, IsNil)
+	n, err := f.Read(make([]byte, 2*len(buf)))
+	c.Assert(n, Equals, len(buf))
+	n, err = f.Read(buf)
+	c.Assert(n, Equals, 0)
+	c.Assert(err, NotNil)
+}
+
+func (s *TestSuite) TestRewrite(c *C) {
+	path, tbi := createFile(c)
+	f, err := Open(path, os.O_RDWR)
+	c.Assert(err, IsNil)
+	for index := int64(1); index <= 100; index++ {
+		f.WriteAt(record(tbi, index), offset(tbi, index))
+	}
+	// Grow a block that is not at the end of the file
+	f.WriteAt(record(tbi, RecordsPerBlock+1), offset(tbi, RecordsPerBlock+1))
+	c.Assert(f.Flush(), IsNil)
+	for index := int64(101); index <= 200; index++ {
+		f.WriteAt(record(tbi, index), offset(tbi, index))
+	}
+	c.Assert(f.Close(), IsNil)
+
+	f, err = Open(path, os.O_RDWR)
+	c.Assert(err, IsNil)
+	buf := make([]byte, tbi.GetRecordLength())
+	for _, index := range []int64{1, 150, 200, RecordsPerBlock + 1} {
+		f.ReadAt(buf, offset(tbi, index))
+		c.Assert(buf, DeepEquals, record(tbi, index))
+	}
+
+	// The space of the block moved by the rewrite is freed
+	c.Assert(f.Compact(), IsNil)
+	for _, index := range []int64{1, 150, 200, RecordsPerBlock + 1} {
+		f.ReadAt(buf, offset(tbi, index))
+		c.Assert(buf, DeepEquals, record(tbi, index))
+	}
+
+	// Zeroing the records of a block empties it
+	zero := make([]byte, 200*int(tbi.GetRecordLength()))
+	_, err = f.WriteAt(zero, offset(tbi, 1))
+	c.Assert(err, IsNil)
+	c.Assert(f.Flush(), IsNil)
+	c.Assert(f.directory[0], Equals, blockRef{})
+	c.Assert(f.Close(), IsNil)
+}
+
+func (s *TestSuite) TestCopyOnWrite(c *C) {
+	path, tbi := createFile(c)
+	f, err := Open(path, os.O_RDWR)
+	c.Assert(err, IsNil)
+	for index := int64(1); index <= 100; index++ {
+		f.WriteAt(record(tbi, index), offset(tbi, index))
+	}
+	c.Assert(f.Flush(), IsNil)
+	first := f.directory[0]
+
+	// A rewritten block does not overwrite the committed one
+	f.WriteAt(record(tbi, 101), offset(tbi, 101))
+	c.Assert(f.Flush(), IsNil)
+	second := f.directory[0]
+	c.Assert(second.Offset >= first.Offset+first.Length, Equals, true)
+
+	// The space of the replaced block is reused once it is not referenced
+	f.WriteAt(make([]byte, tbi.GetRecordLength()), offset(tbi, 101))
+	c.Assert(f.Flush(), IsNil)
+	c.Assert(f.directory[0].Offset, Equals, first.Offset)
+	c.Assert(f.Close(), IsNil)
+
+	// A corrupt block fails its checksum
+	fp, err := os.OpenFile(path, os.O_RDWR, 0600)
+	c.Assert(err, IsNil)
+	_, err = fp.WriteAt([]byte{0xff, 0xff}, first.Offset+first.Length-2)
+	c.Assert(err, IsNil)
+	c.Assert(fp.Close(), IsNil)
+	f, err = Open(path, os.O_RDONLY)
+	c.Assert(err, IsNil)
+	defer f.Close()
+	_, err = f.ReadAt(make([]byte, tbi.GetRecordLength()), offset(tbi, 1))
+	c.Assert(err, ErrorMatches, ".*Checksum mismatch.*")
+}
+
+func (s *TestSuite) TestEncoding(c *C) {
+	columns := recordColumns([]io.EnumElementType{io.FLOAT64, io.INT16, io.STRING, io.BYTE})
+	recordLength := 8 + 8 + 2 + 0 + 1
+	records := make([]byte, 64*recordLength)
+	for slot := 0; slot < 64; slot += 1 + slot%3 {
+		rec := records[slot*recordLength:]
+		binary.LittleEndian.PutUint64(rec, uint64(1001+slot))
+		binary.LittleEndian.PutUint64(rec[8:], math.Float64bits(math.Sin(float64(slot))))
+		binary.LittleEndian.PutUint16(rec[16:], uint16(int16(-slot*300)))
+		rec[18] = byte(-slot)
+	}
+	data, err := encodeBlock(records, recordLength, 1001, columns)
+	c.Assert(err, IsNil)
+	decoded := make([]byte, len(records))
+	c.Assert(decodeBlock(data, decoded, recordLength, 1001, columns), IsNil)
+	c.Assert(decoded, DeepEquals, records)
+
+	c.Assert(decodeBlock(data[:len(data)/2], decoded, recordLength, 1001, columns), NotNil)
+
+	// Indexes must match the record positions
+	_, err = encodeBlock(records, recordLength, 1, columns)
+	c.Assert(err, NotNil)
+}
@@ -0,0 +1,408 @@
+package colfile
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/bits"
+
+	"github.com/alpacahq/marketstore/utils/io"
+)
+
+/*
+A block holds the records present in a run of RecordsPerBlock record slots,
+one column after the other:
+
+	[version byte][uvarint number of records][bit stream]
+
+The bit stream starts with the index of each record, as a delta-of-delta
+from the previous one. The record slots are derived from the indexes, so
+holes take no space. Each data column follows, with floats XOR encoded
+against the previous value as in Gorilla and integers delta encoded. Other
+types are stored as is.
+*/
+
+const blockVersion = 1
+
+var errCorrupt = fmt.Errorf("Corrupt compressed block")
+
+type column struct {
+	offset int // Offset of the column in the record, after the index
+	size   int
+	typ    io.EnumElementType
+}
+
+/*
+recordColumns returns the layout of the data columns of a fixed record,
+which are packed after the 8 byte index
+*/
+func recordColumns(types []io.EnumElementType) (columns []column) {
+	offset := 8
+	for _, typ := range types {
+		columns = append(columns, column{offset: offset, size: typ.Size(), typ: typ})
+		offset += typ.Size()
+	}
+	return columns
+}
+
+/*
+encodeBlock compresses the records of a block, in which the slot of the
+record with index firstIndex comes first. It returns nil when the block has
+no records
+*/
+func encodeBlock(records []byte, recordLength int, firstIndex int64, columns []column) ([]byte, error) {
+	var slots []int
+	nSlots := len(records) / recordLength
+	for slot := 0; slot < nSlots; slot++ {
+		index := int64(binary.LittleEndian.Uint64(records[slot*recordLength:]))
+		if index == 0 {
+			continue
+		}
+		if index != firstIndex+int64(slot) {
+			return nil, fmt.Errorf("Record index %d does not match its position %d", index, firstIndex+int64(slot))
+		}
+		slots = append(slots, slot)
+	}
+	if len(slots) == 0 {
+		return nil, nil
+	}
+
+	w := &bitWriter{}
+	w.buf = append(w.buf, blockVersion)
+	var count [binary.MaxVarintLen64]byte
+	w.buf = append(w.buf, count[:binary.PutUvarint(count[:], uint64(len(slots)))]...)
+
+	previous, previousDelta := int64(-1), int64(1)
+	for _, slot := range slots {
+		delta := int64(slot) - previous
+		w.writeSigned(delta - previousDelta)
+		previous, previousDelta = int64(slot), delta
+	}
+
+	for _, col := range columns {
+		switch col.typ {
+		case io.FLOAT32, io.FLOAT64:
+			width := uint(col.size * 8)
+			xe := xorEncoder{width: width}
+			for _, slot := range slots {
+				xe.write(w, readUint(records[slot*recordLength+col.offset:], col.size))
+			}
+		case io.INT16, io.INT32, io.INT64, io.EPOCH,
+			io.BYTE, io.BOOL, io.UINT8, io.UINT16, io.UINT32, io.UINT64:
+			var previous int64
+			for _, slot := range slots {
+				value := readInt(records[slot*recordLength+col.offset:], col.size, isSigned(col.typ))
+				w.writeSigned(value - previous)
+				previous = value
+			}
+		default:
+			for _, slot := range slots {
+				for _, b := range records[slot*recordLength+col.offset:][:col.size] {
+					w.writeBits(uint64(b), 8)
+				}
+			}
+		}
+	}
+	return w.buf, nil
+}
+
+/*
+decodeBlock decompresses a block into the zeroed records of its slots
+*/
+func decodeBlock(data, records []byte, recordLength int, firstIndex int64, columns []column) error {
+	if len(data) == 0 || data[0] != blockVersion {
+		return errCorrupt
+	}
+	count, n := binary.Uvarint(data[1:])
+	if n <= 0 || count > uint64(len(records)/recordLength) {
+		return errCorrupt
+	}
+	r := &bitReader{buf: data[1+n:]}
+
+	slots := make([]int, count)
+	previous, previousDelta := int64(-1), int64(1)
+	for i := range slots {
+		dod, err := r.readSigned()
+		if err != nil {
+			return err
+		}
+		delta := previousDelta + dod
+		slot := previous + delta
+		if slot < 0 || slot >= int64(len(records)/recordLength) {
+			return errCorrupt
+		}
+		slots[i] = int(slot)
+		binary.LittleEndian.PutUint64(records[int(slot)*recordLength:], uint64(firstIndex+slot))
+		previous, previousDelta = slot, delta
+	}
+
+	for _, col := range columns {
+		switch col.typ {
+		case io.FLOAT32, io.FLOAT64:
+			xd := xorDecoder{width: uint(col.size * 8)}
+			for _, slot := range slots {
+				value, err := xd.read(r)
+				if err != nil {
+					return err
+				}
+				writeUint(records[slot*recordLength+col.offset:], col.size, value)
+			}
+		case io.INT16, io.INT32, io.INT64, io.EPOCH,
+			io.BYTE, io.BOOL, io.UINT8, io.UINT16, io.UINT32, io.UINT64:
+			var value int64
+			for _, slot := range slots {
+				delta, err := r.readSigned()
+				if err != nil {
+					return err
+				}
+				value += delta
+				writeUint(records[slot*recordLength+col.offset:], col.size, uint64(value))
+			}
+		default:
+			for _, slot := range slots {
+				field := records[slot*recordLength+col.offset:][:col.size]
+				for i := range field {
+					b, err := r.readBits(8)
+					if err != nil {
+						return err
+					}
+					field[i] = byte(b)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func isSigned(typ io.EnumElementType) bool {
+	switch typ {
+	case io.INT16, io.INT32, io.INT64, io.EPOCH:
+		return true
+	}
+	return false
+}
+
+func readUint(buf []byte, size int) (value uint64) {
+	for i := size - 1; i >= 0; i-- {
+		value = value<<8 | uint64(buf[i])
+	}
+	return value
+}
+
+func readInt(buf []byte, size int, signed bool) int64 {
+	value := readUint(buf, size)
+	if signed && size < 8 {
+		shift := uint(64 - size*8)
+		return int64(value<<shift) >> shift
+	}
+	return int64(value)
+}
+
+func writeUint(buf []byte, size int, value uint64) {
+	for i := 0; i < size; i++ {
+		buf[i] = byte(value >> uint(i*8))
+	}
+}
+
+/*
+bitWriter appends values to a byte slice, most significant bit first
+*/
+type bitWriter struct {
+	buf  []byte
+	free uint // Unused bits in the last byte
+}
+
+func (w *bitWriter) writeBits(value uint64, n uint) {
+	for n > 0 {
+		if w.free == 0 {
+			w.buf = append(w.buf, 0)
+			w.free = 8
+		}
+		take := n
+		if take > w.free {
+			take = w.free
+		}
+		chunk := byte(value>>(n-take)) & byte(1<<take-1)
+		w.buf[len(w.buf)-1] |= chunk << (w.free - take)
+		w.free -= take
+		n -= take
+	}
+}
+
+/*
+writeSigned writes a zigzag encoded value with a prefix selecting its width,
+so that small values, the common case for deltas, take few bits:
+
+	0      0
+	10     7 bits
+	110    9 bits
+	1110   12 bits
+	1111   64 bits
+*/
+func (w *bitWriter) writeSigned(value int64) {
+	zigzag := uint64(value<<1) ^ uint64(value>>63)
+	switch {
+	case zigzag == 0:
+		w.writeBits(0, 1)
+	case zigzag < 1<<7:
+		w.writeBits(0x2, 2)
+		w.writeBits(zigzag, 7)
+	case zigzag < 1<<9:
+		w.writeBits(0x6, 3)
+		w.writeBits(zigzag, 9)
+	case zigzag < 1<<12:
+		w.writeBits(0xe, 4)
+		w.writeBits(zigzag, 12)
+	default:
+		w.writeBits(0xf, 4)
+		w.writeBits(zigzag, 64)
+	}
+}
+
+type bitReader struct {
+	buf []byte
+	pos uint // Position in bits
+}
+
+func (r *bitReader) readBits(n uint) (value uint64, err error) {
+	for n > 0 {
+		i := r.pos / 8
+		if i >= uint(len(r.buf)) {
+			return 0, errCorrupt
+		}
+		avail := 8 - r.pos%8
+		take := n
+		if take > avail {
+			take = avail
+		}
+		chunk := (r.buf[i] >> (avail - take)) & byte(1<<take-1)
+		value = value<<take | uint64(chunk)
+		r.pos += take
+		n -= take
+	}
+	return value, nil
+}
+
+func (r *bitReader) readSigned() (int64, error) {
+	var prefix uint
+	for prefix < 4 {
+		bit, err := r.readBits(1)
+		if err != nil {
+			return 0, err
+		}
+		if bit == 0 {
+			break
+		}
+		prefix++
+	}
+	var width uint
+	switch prefix {
+	case 0:
+		return 0, nil
+	case 1:
+		width = 7
+	case 2:
+		width = 9
+	case 3:
+		width = 12
+	default:
+		width = 64
+	}
+	zigzag, err := r.readBits(width)
+	if err != nil {
+		return 0, err
+	}
+	return int64(zigzag>>1) ^ -int64(zigzag&1), nil
+}
+
+/*
+xorEncoder writes the bits of 32 or 64 bit floats XOR'ed with the previous
+value. Unchanged values take a single bit, and values sharing the leading and
+trailing zeros of the previous XOR only write the bits between them
+*/
+type xorEncoder struct {
+	width             uint
+	started           bool
+	previous          uint64
+	leading, trailing uint
+}
+
+func (e *xorEncoder) write(w *bitWriter, value uint64) {
+	if !e.started {
+		w.writeBits(value, e.width)
+		e.started, e.previous = true, value
+		e.leading = math.MaxUint8
+		return
+	}
+	xor := value ^ e.previous
+	e.previous = value
+	if xor == 0 {
+		w.writeBits(0, 1)
+		return
+	}
+	w.writeBits(1, 1)
+	leading := uint(bits.LeadingZeros64(xor)) - (64 - e.width)
+	trailing := uint(bits.TrailingZeros64(xor))
+	if leading > 31 {
+		leading = 31
+	}
+	if e.leading != math.MaxUint8 && leading >= e.leading && trailing >= e.trailing {
+		w.writeBits(0, 1)
+		w.writeBits(xor>>e.trailing, e.width-e.leading-e.trailing)
+		return
+	}
+	significant := e.width - leading - trailing
+	w.writeBits(1, 1)
+	w.writeBits(uint64(leading), 5)
+	w.writeBits(uint64(significant-1), 6)
+	w.writeBits(xor>>trailing, significant)
+	e.leading, e.trailing = leading, trailing
+}
+
+type xorDecoder struct {
+	width             uint
+	started           bool
+	previous          uint64
+	leading, trailing uint
+}
+
+func (d *xorDecoder) read(r *bitReader) (uint64, error) {
+	if !d.started {
+		value, err := r.readBits(d.width)
+		if err != nil {
+			return 0, err
+		}
+		d.started, d.previous = true, value
+		return value, nil
+	}
+	changed, err := r.readBits(1)
+	if err != nil || changed == 0 {
+		return d.previous, err
+	}
+	newWindow, err := r.readBits(1)
+	if err != nil {
+		return 0, err
+	}
+	if newWindow == 1 {
+		leading, err := r.readBits(5)
+		if err != nil {
+			return 0, err
+		}
+		significant, err := r.readBits(6)
+		if err != nil {
+			return 0, err
+		}
+		d.leading = uint(leading)
+		if d.leading+uint(significant)+1 > d.width {
+			return 0, errCorrupt
+		}
+		d.trailing = d.width - d.leading - uint(significant) - 1
+	}
+	significant := d.width - d.leading - d.trailing
+	meaningful, err := r.readBits(significant)
+	if err != nil {
+		return 0, err
+	}
+	d.previous ^= meaningful << d.trailing
+	return d.previous, nil
+}
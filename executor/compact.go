@@ -0,0 +1,101 @@
+package executor
+
+import (
+	"encoding/binary"
+	goio "io"
+	"os"
+	"sync"
+
+	"github.com/alpacahq/marketstore/executor/colfile"
+	"github.com/alpacahq/marketstore/utils/io"
+)
+
+// primaryLock keeps writes to primary files out of the files being compacted
+// or rewritten, which would otherwise race with holes punched over records
+// read as empty, or be lost from rewritten files
+var primaryLock sync.RWMutex
+
+/*
+Compact deallocates the disk space of the empty intervals of a fixed length
+year file, by punching holes over the runs of empty records. Compressed files
+have the space left by moved blocks punched instead. It returns the number of
+bytes freed on disk.
+*/
+func Compact(tbi *io.TimeBucketInfo) (freed int64, err error) {
+	if tbi.GetRecordType() != io.FIXED {
+		return 0, nil
+	}
+	primaryLock.Lock()
+	defer primaryLock.Unlock()
+
+	before, err := io.UsedBytes(tbi.Path)
+	if err != nil {
+		return 0, err
+	}
+	if tbi.GetCompression() != io.NOCOMPRESSION {
+		f, err := colfile.Open(tbi.Path, os.O_RDWR)
+		if err != nil {
+			return 0, err
+		}
+		if err = f.Compact(); err != nil {
+			f.Close()
+			return 0, err
+		}
+		if err = f.Close(); err != nil {
+			return 0, err
+		}
+	} else if err = punchEmptyRecords(tbi); err != nil {
+		return 0, err
+	}
+	after, err := io.UsedBytes(tbi.Path)
+	if err != nil {
+		return 0, err
+	}
+	return before - after, nil
+}
+
+func punchEmptyRecords(tbi *io.TimeBucketInfo) error {
+	fp, err := os.OpenFile(tbi.Path, os.O_RDWR, 0700)
+	if err != nil {
+		return err
+	}
+	defer fp.Close()
+
+	recordLength := int64(tbi.GetRecordLength())
+	buffer := make([]byte, RecordsPerRead*recordLength)
+	offset := int64(io.Headersize)
+	runStart := int64(-1) // Offset of the first empty record of the current run
+
+	punch := func(end int64) error {
+		if runStart < 0 {
+			return nil
+		}
+		holeOffset, holeLength := io.AlignHole(runStart, end-runStart)
+		runStart = -1
+		if holeLength == 0 {
+			return nil
+		}
+		return io.PunchHole(fp, holeOffset, holeLength)
+	}
+
+	for {
+		n, err := fp.ReadAt(buffer, offset)
+		if err != nil && err != goio.EOF {
+			return err
+		}
+		for i := int64(0); i+recordLength <= int64(n); i += recordLength {
+			if binary.LittleEndian.Uint64(buffer[i:]) != 0 {
+				if err := punch(offset + i); err != nil {
+					return err
+				}
+			} else if runStart < 0 {
+				runStart = offset + i
+			}
+		}
+		offset += int64(n)
+		if err == goio.EOF || n == 0 {
+			break
+		}
+	}
+	return punch(offset)
+}
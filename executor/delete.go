@@ -15,6 +15,8 @@ import (
 type deleter struct {
 	pr     planner.ParseResult
 	IOPMap map[TimeBucketKey]*ioplan
+	// Deleted is the number of records deleted so far
+	Deleted int64
 }
 
 func NewDeleter(pr *planner.ParseResult) (de *deleter, err error) {
@@ -57,7 +59,7 @@ func (de *deleter) Delete() (err error) {
 func (de *deleter) delete(iop *ioplan) (err error) {
 	for _, fp := range iop.FilePlan {
 		filePath := fp.FullPath
-		f, err := os.OpenFile(filePath, os.O_RDWR, 0666)
+		f, err := fp.open(os.O_RDWR)
 		if err != nil {
 			log.Error("Read: opening %s\n%s", filePath, err)
 			return err
@@ -65,7 +67,7 @@ func (de *deleter) delete(iop *ioplan) (err error) {
 		defer f.Close()
 
 		seekerFunc := func(offset int64) error {
-			if _, err = f.Seek(fp.Offset, io.SeekStart); err != nil {
+			if _, err = f.Seek(offset, io.SeekStart); err != nil {
 				log.Error("Read: seeking in %s\n%s", filePath, err)
 				return err
 			}
@@ -81,7 +83,7 @@ func (de *deleter) delete(iop *ioplan) (err error) {
 			This will preserve the existing holes in the data area at the expense of
 			a potentially large number of file seeks
 		*/
-		bufferSize := int(fp.Length + int64(iop.RecordLen))
+		bufferSize := int(fp.Length)
 		buffer := make([]byte, bufferSize)
 		n, err := f.Read(buffer)
 		if err != nil || n != bufferSize {
@@ -105,12 +107,136 @@ func (de *deleter) delete(iop *ioplan) (err error) {
 				if err != nil || n != int(iop.RecordLen) {
 					return fmt.Errorf("delete(): Short write %d bytes, error: %s", n, err.Error())
 				}
+				de.Deleted++
 			case index == 0:
 				isContiguous = false
 			}
 		}
 		buffer = nil
+		// Compressed files write the zeroed records on close
+		if err := f.Close(); err != nil {
+			return err
+		}
 	}
 
 	return err
 }
+
+/*
+DeleteRange deletes the records of the buckets of tbk between start and end,
+in epoch seconds, through the WAL. It returns the number of records deleted
+once the deletion is committed. The writes to the buckets are held from the
+flush of the queued ones, so that these are deleted too, to the commit.
+*/
+func DeleteRange(tbk *TimeBucketKey, start, end int64) (deleted int64, err error) {
+	if ThisInstance.ReadOnly {
+		return 0, ReadOnlyError("DeleteRange")
+	}
+	parse := func() (*planner.ParseResult, error) {
+		query := planner.NewQuery(ThisInstance.CatalogDir)
+		query.AddTargetKey(tbk)
+		query.SetRange(start, end)
+		return query.Parse()
+	}
+	parsed, err := parse()
+	if err != nil {
+		return 0, err
+	}
+
+	// The buckets are locked in the same order as by the writers
+	locked := make(map[TimeBucketKey]bool)
+	var keys []TimeBucketKey
+	for _, qf := range parsed.QualifiedFiles {
+		if !locked[qf.Key] {
+			locked[qf.Key] = true
+			keys = append(keys, qf.Key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+	for i := range keys {
+		lock := bucketLock(&keys[i])
+		lock.Lock()
+		defer lock.Unlock()
+	}
+
+	ThisInstance.WALFile.RequestFlush()
+	// The buckets may have changed before they were locked
+	if parsed, err = parse(); err != nil {
+		return 0, err
+	}
+	de, err := NewDeleter(parsed)
+	if err != nil {
+		return 0, err
+	}
+	// Only the buckets locked are deleted from
+	for key := range de.IOPMap {
+		if !locked[key] {
+			delete(de.IOPMap, key)
+		}
+	}
+	err = de.QueueDelete(ThisInstance.TXNPipe)
+	ThisInstance.WALFile.RequestFlush()
+	for _, key := range keys {
+		forgetQueuedRecords(key.GetPathToYearFiles(ThisInstance.RootDir))
+	}
+	return de.Deleted, err
+}
+
+/*
+QueueDelete is the crash-safe counterpart of Delete: the selected records are
+deleted through the WAL with the next flush, which fires the delete triggers.
+Pending writes should be flushed first, as only the records found on disk are
+deleted.
+*/
+func (de *deleter) QueueDelete(tgc *TransactionPipe) (err error) {
+	for _, iop := range de.IOPMap {
+		for _, fp := range iop.FilePlan {
+			if err = de.queueDelete(tgc, iop, fp); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (de *deleter) queueDelete(tgc *TransactionPipe, iop *ioplan, fp *ioFilePlan) error {
+	f, err := fp.open(os.O_RDONLY)
+	if err != nil {
+		log.Error("Read: opening %s\n%s", fp.FullPath, err)
+		return err
+	}
+	defer f.Close()
+	if _, err = f.Seek(fp.Offset, io.SeekStart); err != nil {
+		return err
+	}
+	buffer := make([]byte, int(fp.Length))
+	if n, err := io.ReadFull(f, buffer); err != nil {
+		return fmt.Errorf("delete(): Short read %d bytes", n)
+	}
+
+	walKeyPath := ThisInstance.WALFile.FullPathToWALKey(fp.FullPath)
+	recordLen := int(iop.RecordLen)
+	for i := 0; i+recordLen <= len(buffer); i += recordLen {
+		index := int64(binary.LittleEndian.Uint64(buffer[i:]))
+		if index == 0 {
+			continue
+		}
+		// Variable records are deleted by clearing their pointer, fixed
+		// records by writing an empty record over them
+		var data []byte
+		if iop.RecordType == FIXED {
+			data = make([]byte, recordLen-8)
+		}
+		tgc.writeChannel <- &WriteCommand{
+			RecordType:   iop.RecordType,
+			WALKeyPath:   walKeyPath,
+			VarRecLen:    int32(iop.VariableRecordLen),
+			Offset:       fp.Offset + int64(i),
+			Index:        0,
+			Data:         data,
+			DeletedIndex: index,
+		}
+		de.Deleted++
+	}
+	return nil
+}
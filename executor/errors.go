@@ -62,6 +62,12 @@ func (msg WALWriteError) Error() string {
 	return errReport("%s: Error Writing to WAL", string(msg))
 }
 
+type ReadOnlyError string
+
+func (msg ReadOnlyError) Error() string {
+	return errReport("%s: This instance is a read-only replication follower", string(msg))
+}
+
 type ShortReadError string
 
 func (msg ShortReadError) Error() string {
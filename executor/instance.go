@@ -22,6 +22,9 @@ type InstanceMetadata struct {
 	WALWg           sync.WaitGroup
 	ShutdownPending bool
 	WALBypass       bool
+	// ReadOnly refuses the writes to a replication follower, whose data only
+	// changes through replication
+	ReadOnly        bool
 	TriggerMatchers []*trigger.TriggerMatcher
 }
 
@@ -75,8 +78,28 @@ func NewInstanceSetup(relRootDir string, options ...bool) {
 		}
 		if backgroundSync {
 			// Startup the WAL and Primary cache flushers
-			go ThisInstance.WALFile.SyncWAL(500*time.Millisecond, 5*time.Minute, utils.InstanceConfig.WALRotateInterval)
+			walRefresh := utils.InstanceConfig.WALFlushInterval
+			if walRefresh <= 0 {
+				// The config was not parsed, as in the tools embedding the executor
+				walRefresh = 500 * time.Millisecond
+			}
+			go ThisInstance.WALFile.SyncWAL(walRefresh, 5*time.Minute, utils.InstanceConfig.WALRotateInterval)
 			ThisInstance.WALWg.Add(1)
 		}
 	}
 }
+
+/*
+Shutdown stops the WAL writer of this instance once it flushed the pending
+writes and checkpointed the WAL, and waits for it to return.
+*/
+func Shutdown() {
+	if ThisInstance.WALFile != nil {
+		// The writer reads the flag, so it is set by the writer itself
+		ThisInstance.WALFile.runOnWriter(func() error {
+			ThisInstance.ShutdownPending = true
+			return nil
+		})
+	}
+	ThisInstance.WALWg.Wait()
+}
@@ -13,11 +13,13 @@ func (r *reader) readSecondStage(bufMeta []bufferMeta, limitCount int32, directi
 		Here we use the bufFileMap which has index data for each file, then we read
 		the target data into the resultBuffer up to the limitCount number of records
 	*/
-	var varRecLen int
+	var varRecLen, rowLen int
 	// resultBuffers for all bufMetas
 	totalBuf := make([]byte, 0)
 	for _, md := range bufMeta {
 		varRecLen = md.VarRecLen
+		// Result rows have the epoch and a 4 byte nanoseconds in place of the timestamp
+		rowLen = varRecLen - md.TimestampFormat.Size() + 12
 		file := md.FullPath
 		indexBuffer := md.Data
 
@@ -42,7 +44,7 @@ func (r *reader) readSecondStage(bufMeta []bufferMeta, limitCount int32, directi
 						numVarRecords = numberLeftToRead
 					}
 				}
-				totalDatalen += numVarRecords * (varRecLen + 8)
+				totalDatalen += numVarRecords * rowLen
 				numberLeftToRead -= numVarRecords
 			}
 		} else {
@@ -84,8 +86,14 @@ func (r *reader) readSecondStage(bufMeta []bufferMeta, limitCount int32, directi
 					numVarRecords = numberLeftToRead
 				}
 			}
-			rbTemp := RewriteBuffer(buffer,
-				uint32(varRecLen), uint32(numVarRecords), uint32(md.Intervals), uint64(intervalStartEpoch))
+			var rbTemp []byte
+			if md.TimestampFormat == NANOSECONDS {
+				rbTemp = RewriteNanosecondBuffer(buffer,
+					uint32(varRecLen), uint32(numVarRecords), intervalStartEpoch)
+			} else {
+				rbTemp = RewriteBuffer(buffer,
+					uint32(varRecLen), uint32(numVarRecords), uint32(md.Intervals), uint64(intervalStartEpoch))
+			}
 
 			//rb = append(rb, rbTemp...)
 			if (rbCursor + len(rbTemp)) > totalDatalen {
@@ -111,9 +119,9 @@ func (r *reader) readSecondStage(bufMeta []bufferMeta, limitCount int32, directi
 	}
 	if direction == LAST {
 		// Chop the last N records out of the results
-		numVarRecords := len(totalBuf) / (varRecLen + 8)
+		numVarRecords := len(totalBuf) / rowLen
 		if int(limitCount) < numVarRecords {
-			offset := (varRecLen + 8) * (numVarRecords - int(limitCount))
+			offset := rowLen * (numVarRecords - int(limitCount))
 			totalBuf = totalBuf[offset:]
 		}
 	}
@@ -0,0 +1,353 @@
+package executor
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/vmihailenco/msgpack"
+
+	"github.com/alpacahq/marketstore/catalog"
+	"github.com/alpacahq/marketstore/executor/backup"
+	"github.com/alpacahq/marketstore/utils"
+	"github.com/alpacahq/marketstore/utils/io"
+	"github.com/alpacahq/marketstore/utils/log"
+	"github.com/alpacahq/marketstore/utils/stats"
+)
+
+// ReplicationPositionFile holds the position of a follower in the replication
+// log of the primary, in its root directory
+const ReplicationPositionFile = "replication.position"
+
+// DefaultReplicationLogSize is the default number of bytes of transaction
+// groups kept for the followers
+const DefaultReplicationLogSize = 64 * 1024 * 1024
+
+// ReplicationEntryType is the type of an entry of the replication log
+type ReplicationEntryType int8
+
+const (
+	// TransactionGroupEntry holds a transaction group as serialized in the WAL
+	TransactionGroupEntry ReplicationEntryType = iota + 1
+	// CatalogCreateEntry holds a CatalogCreate encoded with msgpack
+	CatalogCreateEntry
+	// ResyncEntry makes the followers catch up from a snapshot, as the files
+	// were altered or removed
+	ResyncEntry
+)
+
+// ReplicationEntry is an entry of the replication log
+type ReplicationEntry struct {
+	// Position increases with each entry, including across restarts
+	Position int64
+	Type     ReplicationEntryType
+	Data     []byte
+}
+
+/*
+CatalogCreate describes a year file created on the primary, either along with
+its bucket or for a new year, which the followers create in turn.
+*/
+type CatalogCreate struct {
+	// Key is the bucket key along with its categories
+	Key             string                 `msgpack:"key"`
+	Year            int16                  `msgpack:"year"`
+	Timeframe       time.Duration          `msgpack:"timeframe"`
+	Description     string                 `msgpack:"description"`
+	ElementNames    []string               `msgpack:"element_names"`
+	ElementTypes    []io.EnumElementType   `msgpack:"element_types"`
+	RecordType      io.EnumRecordType      `msgpack:"record_type"`
+	Compression     io.EnumCompressionType `msgpack:"compression"`
+	WritePolicy     io.EnumWritePolicy     `msgpack:"write_policy"`
+	TimestampFormat io.EnumTimestampFormat `msgpack:"timestamp_format"`
+	SchemaVersion   int64                  `msgpack:"schema_version"`
+}
+
+/*
+ReplicationLog keeps the latest transaction groups written by flushToWAL, for
+the followers streaming them. It only retains them once a follower asked for
+them, and keeps at most MaxBytes of them, so that the followers falling
+further behind have to catch up from a snapshot.
+*/
+type ReplicationLog struct {
+	sync.Mutex
+	MaxBytes int64
+	active   bool
+	start    int64 // all the entries after this position are retained
+	last     int64 // position of the latest entry
+	entries  []ReplicationEntry
+	bytes    int64
+	notify   chan struct{}
+}
+
+// Replication is the log of the transaction groups of this instance
+var Replication = NewReplicationLog(DefaultReplicationLogSize)
+
+func NewReplicationLog(maxBytes int64) *ReplicationLog {
+	return &ReplicationLog{MaxBytes: maxBytes, notify: make(chan struct{})}
+}
+
+// ErrReplicationGap is returned to the followers asking for entries which are
+// no longer retained
+var ErrReplicationGap = fmt.Errorf("The replication log entries requested are no longer retained")
+
+func (rl *ReplicationLog) publish(data []byte) {
+	rl.Lock()
+	defer rl.Unlock()
+	rl.append(TransactionGroupEntry, append([]byte{}, data...))
+	if rl.active {
+		atomic.AddUint64(&stats.ReplicationTGsShipped, 1)
+	}
+}
+
+/*
+PublishCreate ships the creation of a year file, and of its bucket when new,
+to the followers. The callers create the file before, and before writing to
+it, so that the followers create it ahead of the transaction groups writing to
+it, or find it in their snapshot.
+*/
+func (rl *ReplicationLog) PublishCreate(tbk *io.TimeBucketKey, tbi *io.TimeBucketInfo) {
+	data, err := msgpack.Marshal(&CatalogCreate{
+		Key:             tbk.String(),
+		Year:            tbi.Year,
+		Timeframe:       tbi.GetTimeframe(),
+		Description:     tbi.GetDescription(),
+		ElementNames:    tbi.GetElementNames(),
+		ElementTypes:    tbi.GetElementTypes(),
+		RecordType:      tbi.GetRecordType(),
+		Compression:     tbi.GetCompression(),
+		WritePolicy:     tbi.GetWritePolicy(),
+		TimestampFormat: tbi.GetTimestampFormat(),
+		SchemaVersion:   tbi.GetSchemaVersion(),
+	})
+	if err != nil {
+		log.Error("unable to encode the creation of %s, resyncing followers: %v", tbi.Path, err)
+		rl.Resync()
+		return
+	}
+	rl.Lock()
+	defer rl.Unlock()
+	rl.append(CatalogCreateEntry, data)
+}
+
+/*
+Resync makes the followers catch up from a snapshot, for the changes made
+outside of the WAL other than creations, such as the removal or alteration of
+files. The callers make the change before, so that the snapshot holds it.
+*/
+func (rl *ReplicationLog) Resync() {
+	rl.Lock()
+	defer rl.Unlock()
+	rl.append(ResyncEntry, nil)
+}
+
+func (rl *ReplicationLog) append(entryType ReplicationEntryType, data []byte) {
+	rl.last = rl.next()
+	if rl.active {
+		rl.entries = append(rl.entries, ReplicationEntry{rl.last, entryType, data})
+		rl.bytes += int64(len(data))
+		for rl.bytes > rl.MaxBytes && len(rl.entries) > 1 {
+			rl.start = rl.entries[0].Position
+			rl.bytes -= int64(len(rl.entries[0].Data))
+			rl.entries[0].Data = nil // for GC
+			rl.entries = rl.entries[1:]
+		}
+	}
+	close(rl.notify)
+	rl.notify = make(chan struct{})
+}
+
+// next starts the positions of an instance from its start time in unix epoch
+// nanoseconds, above the ones of its previous runs
+func (rl *ReplicationLog) next() int64 {
+	if ThisInstance != nil && rl.last < ThisInstance.InstanceID {
+		return ThisInstance.InstanceID + 1
+	}
+	return rl.last + 1
+}
+
+/*
+Since returns the entries following the position from, along with the
+position of the latest one and a channel closed once there is a newer one. It
+returns ErrReplicationGap when some of them are no longer retained, including
+the ones written before this instance started.
+*/
+func (rl *ReplicationLog) Since(from int64) (entries []ReplicationEntry, last int64, wait <-chan struct{}, err error) {
+	rl.Lock()
+	defer rl.Unlock()
+	rl.activate()
+	if from < rl.start {
+		return nil, rl.last, rl.notify, ErrReplicationGap
+	}
+	// The entries are copied, as the retained ones are released by append
+	i := sort.Search(len(rl.entries), func(i int) bool { return rl.entries[i].Position > from })
+	entries = append([]ReplicationEntry{}, rl.entries[i:]...)
+	return entries, rl.last, rl.notify, nil
+}
+
+// Last returns the position of the latest entry
+func (rl *ReplicationLog) Last() int64 {
+	rl.Lock()
+	defer rl.Unlock()
+	return rl.last
+}
+
+// activate starts retaining the entries, the ones of the previous runs of the
+// instance being gone
+func (rl *ReplicationLog) activate() {
+	if rl.active {
+		return
+	}
+	rl.active = true
+	rl.start = rl.next() - 1
+	if rl.last < rl.start {
+		rl.last = rl.start
+	}
+}
+
+/*
+ReplicationSnapshot writes a snapshot of the root directory to dest for a
+follower to catch up from, freezing the files like Backup, and returns the
+position in the replication log of the last entry it holds.
+*/
+func ReplicationSnapshot(dest string) (position int64, err error) {
+	fd, err := freezeRootDir(isReplicationLocal, func() {
+		// The follower streams the entries following the snapshot
+		Replication.Lock()
+		Replication.activate()
+		position = Replication.last
+		Replication.Unlock()
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer thaw(fd)
+	_, err = backup.SnapshotFrozen(fd, dest)
+	return position, err
+}
+
+/*
+ApplyTransactionGroup writes a transaction group streamed from the primary to
+the primary files of a follower, and records its position once it is durable.
+*/
+func ApplyTransactionGroup(position int64, data []byte) error {
+	if len(data) < 16 {
+		return fmt.Errorf("Transaction group too short: %d bytes", len(data))
+	}
+	wf := ThisInstance.WALFile
+	err := wf.runOnWriter(func() error {
+		primaryLock.RLock()
+		defer primaryLock.RUnlock()
+		if err := wf.replayTGData(data); err != nil {
+			return err
+		}
+		return SetReplicationPosition(position)
+	})
+	if err != nil {
+		return err
+	}
+	atomic.AddUint64(&stats.ReplicationTGsApplied, 1)
+	return nil
+}
+
+/*
+ApplyCatalogCreate creates a year file created on the primary, along with its
+bucket when new, and records its position. The year files added to an existing
+bucket are created like its other ones, and a file which exists already, as it
+was in the snapshot of the follower, is left as is.
+*/
+func ApplyCatalogCreate(position int64, data []byte) error {
+	var create CatalogCreate
+	if err := msgpack.Unmarshal(data, &create); err != nil {
+		return fmt.Errorf("Invalid catalog create: %v", err)
+	}
+	tbk := io.NewTimeBucketKeyFromString(create.Key)
+	tf := utils.TimeframeFromDuration(create.Timeframe)
+	if tbk == nil || tf == nil || len(create.ElementNames) != len(create.ElementTypes) {
+		return fmt.Errorf("Invalid catalog create of %s", create.Key)
+	}
+	dsv := make([]io.DataShape, len(create.ElementNames))
+	for i, name := range create.ElementNames {
+		dsv[i] = io.DataShape{Name: name, Type: create.ElementTypes[i]}
+	}
+	return ThisInstance.WALFile.runOnWriter(func() error {
+		tbi := io.NewTimeBucketInfo(*tf, tbk.GetPathToYearFiles(ThisInstance.RootDir),
+			create.Description, create.Year, dsv, create.RecordType)
+		if err := tbi.SetCompression(create.Compression); err != nil {
+			return err
+		}
+		if err := tbi.SetWritePolicy(create.WritePolicy); err != nil {
+			return err
+		}
+		if err := tbi.SetTimestampFormat(create.TimestampFormat); err != nil {
+			return err
+		}
+		tbi.SetSchemaVersion(create.SchemaVersion)
+		if latest, err := ThisInstance.CatalogDir.GetLatestTimeBucketInfoFromKey(tbk); err == nil {
+			if _, err = ThisInstance.CatalogDir.GetSubDirectoryAndAddFile(latest.Path, create.Year); err != nil {
+				return err
+			}
+		} else if err := ThisInstance.CatalogDir.AddTimeBucket(tbk, tbi); err != nil {
+			if _, ok := err.(catalog.FileAlreadyExists); !ok {
+				return err
+			}
+		}
+		return SetReplicationPosition(position)
+	})
+}
+
+/*
+ReplaceFromSnapshot replaces the files of a follower with the ones of a
+snapshot of the primary, reloads the catalog and records the position of the
+snapshot.
+*/
+func ReplaceFromSnapshot(src string, position int64) error {
+	err := ThisInstance.WALFile.RunQuiesced(func() error {
+		if _, err := backup.Replace(src, ThisInstance.RootDir, isReplicationLocal); err != nil {
+			return err
+		}
+		ThisInstance.CatalogDir = catalog.NewDirectory(ThisInstance.RootDir)
+		return SetReplicationPosition(position)
+	})
+	if err != nil {
+		return err
+	}
+	atomic.AddUint64(&stats.ReplicationCatchUps, 1)
+	return nil
+}
+
+// ReplicationPosition returns the position of this follower in the
+// replication log of the primary, or zero if it never caught up
+func ReplicationPosition() (int64, error) {
+	data, err := ioutil.ReadFile(filepath.Join(ThisInstance.RootDir, ReplicationPositionFile))
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+}
+
+// SetReplicationPosition records the position of this follower in the
+// replication log of the primary
+func SetReplicationPosition(position int64) error {
+	path := filepath.Join(ThisInstance.RootDir, ReplicationPositionFile)
+	if err := ioutil.WriteFile(path+".tmp", []byte(strconv.FormatInt(position, 10)+"\n"), 0600); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// isReplicationLocal tells whether a file of the root directory belongs to
+// this instance rather than to the replicated data
+func isReplicationLocal(path string) bool {
+	return isWALFile(path) || strings.HasPrefix(path, ReplicationPositionFile)
+}
@@ -0,0 +1,162 @@
+package executor
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/alpacahq/marketstore/catalog"
+	"github.com/alpacahq/marketstore/planner"
+	"github.com/alpacahq/marketstore/utils"
+	"github.com/alpacahq/marketstore/utils/io"
+	"github.com/alpacahq/marketstore/utils/log"
+	"github.com/alpacahq/marketstore/utils/stats"
+)
+
+// RetentionResult is what a pass of the retention policies removed
+type RetentionResult struct {
+	FilesRemoved   int64
+	RecordsDeleted int64
+	BytesFreed     int64
+}
+
+func (r *RetentionResult) add(other RetentionResult) {
+	r.FilesRemoved += other.FilesRemoved
+	r.RecordsDeleted += other.RecordsDeleted
+	r.BytesFreed += other.BytesFreed
+}
+
+/*
+RunRetention enforces the retention policies every interval until shutdown
+*/
+func RunRetention(policies []*utils.RetentionSetting, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if ThisInstance.ShutdownPending {
+			return
+		}
+		result, err := EnforceRetention(ThisInstance.CatalogDir, policies, time.Now())
+		if err != nil {
+			log.Error("Retention: %v", err)
+		}
+		if result.FilesRemoved > 0 || result.RecordsDeleted > 0 {
+			log.Info("Retention: removed %d year files and %d records, freeing %d bytes",
+				result.FilesRemoved, result.RecordsDeleted, result.BytesFreed)
+		}
+	}
+}
+
+/*
+EnforceRetention removes the data older than the max age of the first policy
+matching each bucket. Year files entirely older than it are removed, except
+for the latest year file of a bucket which new writes rely on, and the expired
+records of the remaining files are deleted. The counters of the stats package
+are updated with what was removed.
+*/
+func EnforceRetention(d *catalog.Directory, policies []*utils.RetentionSetting, now time.Time) (
+	result RetentionResult, err error) {
+
+	if len(policies) == 0 {
+		return result, nil
+	}
+	buckets := make(map[string][]*io.TimeBucketInfo)
+	for _, tbi := range d.GatherTimeBucketInfo() {
+		key, err := filepath.Rel(d.GetPath(), filepath.Dir(tbi.Path))
+		if err != nil {
+			return result, err
+		}
+		buckets[key] = append(buckets[key], tbi)
+	}
+
+	// Pending writes must reach the year files before they are trimmed
+	ThisInstance.WALFile.RequestFlush()
+
+	for key, tbis := range buckets {
+		policy := retentionPolicyFor(policies, key)
+		if policy == nil {
+			continue
+		}
+		removed, err := enforceBucketRetention(d, io.NewTimeBucketKey(key), tbis, now.Add(-policy.MaxAge))
+		result.add(removed)
+		if err != nil {
+			updateRetentionStats(result)
+			return result, fmt.Errorf("Unable to enforce retention on %s: %v", key, err)
+		}
+	}
+	updateRetentionStats(result)
+	return result, nil
+}
+
+func retentionPolicyFor(policies []*utils.RetentionSetting, key string) *utils.RetentionSetting {
+	for _, policy := range policies {
+		if matched, _ := path.Match(policy.On, key); matched {
+			return policy
+		}
+	}
+	return nil
+}
+
+func enforceBucketRetention(d *catalog.Directory, tbk *io.TimeBucketKey, tbis []*io.TimeBucketInfo,
+	cutoff time.Time) (result RetentionResult, err error) {
+
+	sort.Slice(tbis, func(i, j int) bool { return tbis[i].Year < tbis[j].Year })
+	yearStart := func(year int16) time.Time {
+		return time.Date(int(year), time.January, 1, 0, 0, 0, 0, utils.InstanceConfig.Timezone)
+	}
+
+	// Expired years are removed while the writes to the primary files are held
+	primaryLock.Lock()
+	for len(tbis) > 1 && !yearStart(tbis[0].Year+1).After(cutoff) {
+		tbi := tbis[0]
+		used, err := io.UsedBytes(tbi.Path)
+		if err != nil {
+			primaryLock.Unlock()
+			return result, err
+		}
+		preserveFrozen(tbi.Path)
+		if err = d.RemoveYearFile(tbi); err != nil {
+			primaryLock.Unlock()
+			return result, err
+		}
+		result.FilesRemoved++
+		result.BytesFreed += used
+		tbis = tbis[1:]
+	}
+	primaryLock.Unlock()
+	if result.FilesRemoved > 0 {
+		Replication.Resync()
+	}
+
+	// The expired part of the first remaining year is deleted through the WAL
+	if !yearStart(tbis[0].Year).Before(cutoff) {
+		return result, nil
+	}
+	q := planner.NewQuery(d)
+	q.AddTargetKey(tbk)
+	q.SetRange(yearStart(tbis[0].Year).Unix(), cutoff.Unix()-1)
+	parsed, err := q.Parse()
+	if err != nil {
+		return result, err
+	}
+	de, err := NewDeleter(parsed)
+	if err != nil {
+		return result, err
+	}
+	err = de.QueueDelete(ThisInstance.TXNPipe)
+	result.RecordsDeleted += de.Deleted
+	if err != nil {
+		return result, err
+	}
+	ThisInstance.WALFile.RequestFlush()
+	return result, nil
+}
+
+func updateRetentionStats(result RetentionResult) {
+	atomic.AddUint64(&stats.RetentionFilesRemoved, uint64(result.FilesRemoved))
+	atomic.AddUint64(&stats.RetentionRecordsDeleted, uint64(result.RecordsDeleted))
+	atomic.AddUint64(&stats.RetentionBytesFreed, uint64(result.BytesFreed))
+}
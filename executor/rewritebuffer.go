@@ -62,6 +62,32 @@ func RewriteBuffer(buffer []byte, varRecLen, numVarRecords uint32, intervalsPerD
 	return rbTemp
 }
 
+// RewriteNanosecondBuffer converts variable_length records with nanosecond timestamps
+// to the same result buffer format as RewriteBuffer.
+//
+// variable records in a file: [Actual Data (VarRecLen-8 byte) , Nanoseconds from the interval start(8 byte) ]
+// RewriteNanosecondBuffer converts the binary data to [EpochSecond(8 byte), Actual Data(VarRecLen-8 byte), Nanoseconds(4 byte) ] format.
+func RewriteNanosecondBuffer(buffer []byte, varRecLen, numVarRecords uint32, intervalStartEpoch int64) []byte {
+	dataLen := varRecLen - 8
+	rowLen := dataLen + 12
+	rbTemp := make([]byte, numVarRecords*rowLen)
+
+	var j, cursor uint32
+	for j = 0; j < numVarRecords; j++ {
+		record := buffer[j*varRecLen : (j+1)*varRecLen]
+		nanos := io.ToInt64(record[dataLen:])
+		second := intervalStartEpoch + nanos/1e9
+		nanosecond := nanos % 1e9
+
+		cursor = j * rowLen
+		binary.LittleEndian.PutUint64(rbTemp[cursor:], uint64(second))
+		copy(rbTemp[cursor+8:cursor+8+dataLen], record[:dataLen])
+		binary.LittleEndian.PutUint32(rbTemp[cursor+8+dataLen:], uint32(nanosecond))
+	}
+
+	return rbTemp
+}
+
 // GetTimeFromTicks Takes two time components, the start of the interval and the number of
 // interval ticks to the timestamp and returns an epoch time (seconds) and
 // the number of nanoseconds of fractional time within the last second as a remainder
@@ -9,6 +9,7 @@ import (
 	"sort"
 	"time"
 
+	"github.com/alpacahq/marketstore/executor/colfile"
 	"github.com/alpacahq/marketstore/executor/readhint"
 	"github.com/alpacahq/marketstore/planner"
 	"github.com/alpacahq/marketstore/utils"
@@ -38,11 +39,36 @@ func (iofp *ioFilePlan) GetFileYear() int16 {
 	return iofp.tbi.Year
 }
 
+type recordFile interface {
+	io.ReadWriteSeeker
+	io.Closer
+}
+
+/*
+open opens the file in the uncompressed record layout, decoding the blocks of
+compressed files as they are accessed
+*/
+func (iofp *ioFilePlan) open(flag int) (recordFile, error) {
+	if iofp.tbi.GetCompression() != NOCOMPRESSION {
+		f, err := colfile.Open(iofp.FullPath, flag)
+		if err != nil {
+			return nil, err
+		}
+		return f, nil
+	}
+	f, err := os.OpenFile(iofp.FullPath, flag, 0666)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
 type ioplan struct {
 	FilePlan          []*ioFilePlan
 	RecordLen         int32
 	RecordType        EnumRecordType
 	VariableRecordLen int
+	TimestampFormat   EnumTimestampFormat
 	Limit             *planner.RowLimit
 	TimeQuals         []planner.TimeQualFunc
 }
@@ -76,6 +102,7 @@ func NewIOPlan(fl SortedFileList, pr *planner.ParseResult) (iop *ioplan, err err
 			iop.RecordLen = file.File.GetRecordLength()
 			iop.RecordType = file.File.GetRecordType()
 			iop.VariableRecordLen = int(file.File.GetVariableRecordLength())
+			iop.TimestampFormat = file.File.GetTimestampFormat()
 		} else {
 			// check that we're reading the same recordlength across all files, return err if not
 			if file.File.GetRecordLength() != iop.RecordLen {
@@ -205,13 +232,11 @@ func NewReader(pr *planner.ParseResult) (r *reader, err error) {
 	return r, nil
 }
 
+// Read returns the whole result of the parsed query in a single ColumnSeriesMap.
+// Large scans should set a FIRST row limit and advance the range start page by
+// page (see the paged query mode in the frontend), as forward reads stop once the
+// limit has been filled.
 func (r *reader) Read() (csm ColumnSeriesMap, err error) {
-	// TODO: Need to consider the huge buffer which use loooong time gap to query.
-	// Which probably cause out of memory issue and need new mechanism to handle
-	// those data and not just simply return one ColumnSeriesMap.
-	// Solution: Hack ColumnSeries add subsection fields to break the one big query
-	// down to several parts of small query and each one's Range.Start follow the last's
-	// Range.End with same other conditions.
 	csm = NewColumnSeriesMap()
 	catMap := r.pr.GetCandleAttributes()
 	rtMap := r.pr.GetRowType()
@@ -275,6 +300,8 @@ type bufferMeta struct {
 	Data      []byte
 	VarRecLen int
 	Intervals int64
+	// TimestampFormat is how the timestamps of the variable records are stored
+	TimestampFormat EnumTimestampFormat
 }
 
 // Reads the data from files, removing holes. The resulting buffer will be packed
@@ -333,10 +360,11 @@ func (r *reader) read(iop *ioplan) (resultBuffer []byte, err error) {
 				// If we've added data to the buffer from this file, record it for possible later use
 				if len(resultBuffer) > dataLen {
 					bufMeta = append(bufMeta, bufferMeta{
-						FullPath:  fp.FullPath,
-						Data:      resultBuffer[dataLen:],
-						VarRecLen: iop.VariableRecordLen,
-						Intervals: fp.tbi.GetIntervals(),
+						FullPath:        fp.FullPath,
+						Data:            resultBuffer[dataLen:],
+						VarRecLen:       iop.VariableRecordLen,
+						Intervals:       fp.tbi.GetIntervals(),
+						TimestampFormat: iop.TimestampFormat,
 					})
 				}
 			}
@@ -367,10 +395,11 @@ func (r *reader) read(iop *ioplan) (resultBuffer []byte, err error) {
 						bytesLeftToFill = 0
 					}
 					bufMeta = append(bufMeta, bufferMeta{
-						FullPath:  fp[i].FullPath,
-						Data:      resultBuffer[bytesLeftToFill:],
-						VarRecLen: iop.VariableRecordLen,
-						Intervals: fp[i].tbi.GetIntervals(),
+						FullPath:        fp[i].FullPath,
+						Data:            resultBuffer[bytesLeftToFill:],
+						VarRecLen:       iop.VariableRecordLen,
+						Intervals:       fp[i].tbi.GetIntervals(),
+						TimestampFormat: iop.TimestampFormat,
 					})
 				}
 			}
@@ -417,15 +446,17 @@ type ioExec struct {
 }
 
 func (ex *ioExec) packingReader(packedBuffer *[]byte, f io.ReadSeeker, buffer []byte,
-	maxRead int64, fp *ioFilePlan) error {
+	maxRead int64, maxPacked int32, fp *ioFilePlan) error {
 	// Reads data from file f positioned after the header
 	// Will read records of size recordsize, decoding the index value to determine if this is a null or valid record
 	// The output is a buffer "packedBuffer" that contains only valid records
 	// The index value is converted to a UNIX Epoch timestamp based on the basetime and intervalsecs
 	// buffer is the temporary buffer to store read content from file, and indicates the maximum size to read
 	// maxRead limits the number of bytes to be read from the file
+	// maxPacked limits the size of the packed buffer, so limited scans stop early
 	// Exit conditions:
 	// ==> leftbytes <= 0
+	// ==> len(packedBuffer) >= maxPacked
 
 	recordSize := ex.plan.RecordLen
 	recordSize64 := int64(recordSize)
@@ -462,6 +493,7 @@ func (ex *ioExec) packingReader(packedBuffer *[]byte, f io.ReadSeeker, buffer []
 				// Convert the index to a UNIX timestamp (seconds from epoch)
 				index := IndexToTime(int64(indexuint64), fp.tbi.GetTimeframe(), fp.GetFileYear()).Unix()
 				if !ex.checkTimeQuals(index) {
+					buf = buf[recordSize:]
 					continue
 				}
 				idxpos := len(*packedBuffer)
@@ -481,7 +513,7 @@ func (ex *ioExec) packingReader(packedBuffer *[]byte, f io.ReadSeeker, buffer []
 
 			buf = buf[recordSize:]
 		}
-		if leftBytes <= 0 {
+		if leftBytes <= 0 || int32(len(*packedBuffer)) >= maxPacked {
 			return nil
 		}
 	}
@@ -497,7 +529,7 @@ func (ex *ioExec) readForward(finalBuffer []byte, fp *ioFilePlan, recordLen, byt
 		finalBuffer = make([]byte, 0, len(readBuffer))
 	}
 	// Forward scan
-	f, err := os.OpenFile(filePath, os.O_RDONLY, 0666)
+	f, err := fp.open(os.O_RDONLY)
 	if err != nil {
 		log.Error("Read: opening %s\n%s", filePath, err)
 		return nil, false, err
@@ -509,7 +541,7 @@ func (ex *ioExec) readForward(finalBuffer []byte, fp *ioFilePlan, recordLen, byt
 		return finalBuffer, false, err
 	}
 
-	if err = ex.packingReader(&finalBuffer, f, readBuffer, fp.Length, fp); err != nil {
+	if err = ex.packingReader(&finalBuffer, f, readBuffer, fp.Length, bytesToRead, fp); err != nil {
 		log.Error("Read: reading data from %s\n%s", filePath, err)
 		return finalBuffer, false, err
 
@@ -536,7 +568,7 @@ func (ex *ioExec) readBackward(finalBuffer []byte, fp *ioFilePlan,
 		finalBuffer = make([]byte, bytesToRead, bytesToRead)
 	}
 
-	f, err := os.OpenFile(filePath, os.O_RDONLY, 0666)
+	f, err := fp.open(os.O_RDONLY)
 	if err != nil {
 		log.Error("Read: opening %s\n%s", filePath, err)
 		return nil, false, 0, err
@@ -558,7 +590,7 @@ func (ex *ioExec) readBackward(finalBuffer []byte, fp *ioFilePlan,
 		if err = ex.packingReader(
 			&fileBuffer,
 			f, readBuffer,
-			maxToRead, fp); err != nil {
+			maxToRead, math.MaxInt32, fp); err != nil {
 
 			log.Error("Read: reading data from %s\n%s", filePath, err)
 			return nil, false, 0, err
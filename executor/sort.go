@@ -48,3 +48,29 @@ func (ei *ByIntervalTicks) Swap(i, j int) {
 		cursor_j++
 	}
 }
+
+// ByIntervalNanoseconds implements a custom sort for Variable length record
+// with nanosecond timestamps.
+// Sort by the last 8 byte (= nanoseconds from the interval start) of each record.
+type ByIntervalNanoseconds struct {
+	ByIntervalTicks
+}
+
+func NewByIntervalNanoseconds(buffer []byte, numWords, recordLength int) sort.Interface {
+	return &ByIntervalNanoseconds{
+		ByIntervalTicks{
+			buffer:       buffer,
+			numWords:     numWords,
+			recordLength: recordLength,
+		},
+	}
+}
+
+// Less reports whether the element with
+// index i should sort before the element with index j.
+func (en *ByIntervalNanoseconds) Less(i, j int) bool {
+	cursor_i := (i + 1) * en.recordLength
+	cursor_j := (j + 1) * en.recordLength
+
+	return io.ToInt64(en.buffer[cursor_i-8:cursor_i]) < io.ToInt64(en.buffer[cursor_j-8:cursor_j])
+}
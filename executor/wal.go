@@ -13,10 +13,14 @@ import (
 	"io/ioutil"
 	"path/filepath"
 	"sort"
+	"sync/atomic"
 
 	"github.com/alpacahq/marketstore/executor/buffile"
+	"github.com/alpacahq/marketstore/executor/colfile"
+	"github.com/alpacahq/marketstore/utils"
 	"github.com/alpacahq/marketstore/utils/io"
 	"github.com/alpacahq/marketstore/utils/log"
+	"github.com/alpacahq/marketstore/utils/stats"
 )
 
 /*
@@ -34,6 +38,9 @@ type WALFileType struct {
 	FilePath          string   // WAL file full path
 	lastCommittedTGID int64    // TGID to be checkpointed
 	FilePtr           *os.File // Active file pointer to FileName
+	unsynced          bool     // Whether transaction groups were written since the last fsync
+	syncErr           error    // The first failed fsync, after which nothing is known to be durable
+	unpublished       [][]byte // Transaction groups shipped to the followers after the next fsync
 }
 
 func NewWALFile(rootDir string, existingFilePath string) (wf *WALFileType, err error) {
@@ -173,6 +180,7 @@ func (b offsetIndexBuffer) Payload() []byte {
 type CachedFP struct {
 	fileName string
 	fp       *os.File
+	cf       *colfile.File // Set when the cached file is compressed
 }
 
 func NewCachedFP() *CachedFP {
@@ -183,21 +191,51 @@ func (cfp *CachedFP) GetFP(fileName string) (fp *os.File, err error) {
 	if fileName == cfp.fileName {
 		return cfp.fp, nil
 	} else if len(cfp.fileName) != 0 {
-		cfp.fp.Close()
+		if err = cfp.Close(); err != nil {
+			return nil, err
+		}
 	}
 	cfp.fp, err = os.OpenFile(fileName, os.O_RDWR, 0700)
 	if err != nil {
 		return nil, err
 	}
 	cfp.fileName = fileName
+	if compressed, err := colfile.IsCompressed(fileName); err != nil {
+		return nil, err
+	} else if compressed {
+		if cfp.cf, err = colfile.Open(fileName, os.O_RDWR); err != nil {
+			return nil, err
+		}
+	}
 	return cfp.fp, nil
 }
 
-func (cfp *CachedFP) Close() error {
+// GetWriterAt returns the cached file for writing fixed length records,
+// which are compressed into the blocks of compressed files
+func (cfp *CachedFP) GetWriterAt(fileName string) (goio.WriterAt, error) {
+	fp, err := cfp.GetFP(fileName)
+	if err != nil {
+		return nil, err
+	}
+	if cfp.cf != nil {
+		return cfp.cf, nil
+	}
+	return fp, nil
+}
+
+func (cfp *CachedFP) Close() (err error) {
+	if cfp.cf != nil {
+		err = cfp.cf.Close()
+		cfp.cf = nil
+	}
 	if cfp.fp != nil {
-		return cfp.fp.Close()
+		if closeErr := cfp.fp.Close(); err == nil {
+			err = closeErr
+		}
+		cfp.fp = nil
 	}
-	return nil
+	cfp.fileName = ""
+	return err
 }
 
 // A.k.a. Commit transaction
@@ -209,6 +247,9 @@ func (wf *WALFileType) flushToWAL(tgc *TransactionPipe) (err error) {
 	*/
 
 	defer dispatchRecords()
+	// The writes queued before this point are in the primary files once done
+	atomic.AddUint64(&flushesStarted, 1)
+	defer atomic.AddUint64(&flushesDone, 1)
 
 	WALBypass := ThisInstance.WALBypass
 	//WALBypass = true // Bypass all writing to the WAL File, leaving the writes to the primary
@@ -239,6 +280,7 @@ func (wf *WALFileType) flushToWAL(tgc *TransactionPipe) (err error) {
 	TG_Serialized, _ = io.Serialize(TG_Serialized, tgc.TGID())
 	TG_Serialized, _ = io.Serialize(TG_Serialized, int64(WTCount))
 	writesPerFile := map[string][]offsetIndexBuffer{}
+	deletesPerFile := map[string][]int64{}
 	fileRecordTypes := map[string]io.EnumRecordType{}
 	varRecLens := map[string]int32{}
 	/*
@@ -260,6 +302,9 @@ func (wf *WALFileType) flushToWAL(tgc *TransactionPipe) (err error) {
 		// Store the data in a buffer for primary storage writes after WAL writes are done
 		writesPerFile[keyPath] = append(writesPerFile[keyPath],
 			offsetIndexBuffer(TG_Serialized[oStart:oStart+bufferSize]))
+		if command.Index == 0 {
+			deletesPerFile[keyPath] = append(deletesPerFile[keyPath], command.DeletedIndex)
+		}
 		if _, ok := fileRecordTypes[keyPath]; !ok {
 			fileRecordTypes[keyPath] = command.RecordType
 		}
@@ -289,7 +334,22 @@ func (wf *WALFileType) flushToWAL(tgc *TransactionPipe) (err error) {
 		wf.lastCommittedTGID = TGID
 		tgc.NewTGID()
 
-		wf.FilePtr.Sync()       // Flush the OS buffer
+		wf.unsynced = true
+		switch {
+		case syncOnFlush():
+			// Flush the OS buffer, the followers get the TG once durable
+			wf.unpublished = append(wf.unpublished, TG_Serialized)
+			if err = wf.syncFile(); err != nil {
+				return err
+			}
+		case utils.InstanceConfig.WALSyncPolicy == utils.WALSyncInterval:
+			wf.unpublished = append(wf.unpublished, TG_Serialized)
+		default:
+			// Nothing is synced until the next checkpoint or sync write
+			Replication.publish(TG_Serialized)
+		}
+	} else {
+		Replication.publish(TG_Serialized)
 	}
 
 	/*
@@ -302,11 +362,17 @@ func (wf *WALFileType) flushToWAL(tgc *TransactionPipe) (err error) {
 			return err
 		}
 		for i, buffer := range writes {
-			appendRecord(keyPath, trigger.Record(buffer.IndexAndPayload()))
+			// Deleted records are reported apart
+			if buffer.Index() != 0 {
+				appendRecord(keyPath, trigger.Record(buffer.IndexAndPayload()))
+			}
 			writes[i] = nil // for GC
 		}
 		writesPerFile[keyPath] = nil // for GC
 	}
+	for keyPath, indexes := range deletesPerFile {
+		appendDeleted(keyPath, indexes)
+	}
 	return nil
 }
 
@@ -317,8 +383,20 @@ func (wf *WALFileType) writePrimary(keyPath string, writes []offsetIndexBuffer,
 	}
 	const batchThreshold = 100
 	var fp WriteAtCloser
+	primaryLock.RLock()
+	defer primaryLock.RUnlock()
 	fullPath := wf.WALKeyToFullPath(keyPath)
-	if recordType == io.FIXED && len(writes) >= batchThreshold {
+	preserveFrozen(fullPath)
+	compressed := false
+	if recordType == io.FIXED {
+		if compressed, err = colfile.IsCompressed(fullPath); err != nil {
+			log.Error("cannot read header of %s: %v", fullPath, err)
+			return err
+		}
+	}
+	if compressed {
+		fp, err = colfile.Open(fullPath, os.O_RDWR)
+	} else if recordType == io.FIXED && len(writes) >= batchThreshold {
 		fp, err = buffile.New(fullPath)
 	} else {
 		fp, err = os.OpenFile(fullPath, os.O_RDWR, 0700)
@@ -328,7 +406,13 @@ func (wf *WALFileType) writePrimary(keyPath string, writes []offsetIndexBuffer,
 		log.Error("cannot open file %s for write: %v", fullPath, err)
 		return err
 	}
-	defer fp.Close()
+	defer func() {
+		// Compressed files write their blocks on close
+		if closeErr := fp.Close(); err == nil && closeErr != nil {
+			log.Error("failed to write committed data: %v", closeErr)
+			err = closeErr
+		}
+	}()
 
 	for _, buffer := range writes {
 		switch recordType {
@@ -645,16 +729,21 @@ func (wf *WALFileType) replayTGData(TG_Serialized []byte) (err error) {
 			varRecLen := io.ToInt32(TG_Serialized[cursor : cursor+4])
 			cursor += 4
 			fullPath := wf.WALKeyToFullPath(WALKeyPath)
-			fp, err := cfp.GetFP(fullPath)
-			if err != nil {
-				return err
-			}
+			preserveFrozen(fullPath)
 			switch io.EnumRecordType(RecordType) {
 			case io.FIXED:
+				fp, err := cfp.GetWriterAt(fullPath)
+				if err != nil {
+					return err
+				}
 				if err = WriteBufferToFile(fp, TG_Serialized[cursor:cursor+8+8+dataLen]); err != nil {
 					return err
 				}
 			case io.VARIABLE:
+				fp, err := cfp.GetFP(fullPath)
+				if err != nil {
+					return err
+				}
 				// Find the record length - we need it to use the time column as a sort key later
 				if err = WriteBufferToFileIndirect(fp,
 					TG_Serialized[cursor:cursor+8+8+dataLen],
@@ -799,6 +888,66 @@ func StartupCacheAndWAL(rootDir string) (tgc *TransactionPipe, wf *WALFileType,
 	return NewTransactionPipe(), wf, nil
 }
 
+// syncOnFlush tells whether each transaction group is fsynced as it is written
+func syncOnFlush() bool {
+	policy := utils.InstanceConfig.WALSyncPolicy
+	return policy != utils.WALSyncInterval && policy != utils.WALSyncNever
+}
+
+/*
+syncFile makes the transaction groups written so far durable, and ships them
+to the followers. A failed fsync is not retried: the kernel may have dropped
+the pages it failed to write, so that a later fsync would succeed without
+them, and the error is returned by every later call instead.
+*/
+func (wf *WALFileType) syncFile() error {
+	if ThisInstance.WALBypass {
+		// The writes went straight to the primary files
+		io.Syncfs()
+		return nil
+	}
+	if wf.syncErr != nil {
+		return wf.syncErr
+	}
+	if wf.unsynced {
+		if err := wf.FilePtr.Sync(); err != nil {
+			wf.syncErr = fmt.Errorf("Unable to sync the WAL file: %v", err)
+			return wf.syncErr
+		}
+		wf.unsynced = false
+	}
+	for i, tg := range wf.unpublished {
+		Replication.publish(tg)
+		wf.unpublished[i] = nil // for GC
+	}
+	wf.unpublished = wf.unpublished[:0]
+	return nil
+}
+
+// commitGroup flushes the writes of a sync request and of all the sync
+// requests queued behind it with a single fsync, then releases them with
+// the result. It returns the error of the flush only, as the writes are in
+// the primary files even if the fsync failed.
+func (wf *WALFileType) commitGroup(first chan error) error {
+	group := []chan error{first}
+	for len(ThisInstance.TXNPipe.syncChannel) > 0 {
+		group = append(group, <-ThisInstance.TXNPipe.syncChannel)
+	}
+	flushErr := wf.flushToWAL(ThisInstance.TXNPipe)
+	err := flushErr
+	if err == nil {
+		if err = wf.syncFile(); err != nil {
+			log.Error(err.Error())
+		}
+	}
+	atomic.AddUint64(&stats.WALSyncRequests, uint64(len(group)))
+	atomic.AddUint64(&stats.WALGroupCommits, 1)
+	for _, f := range group {
+		f <- err
+	}
+	return flushErr
+}
+
 var haveWALWriter = false
 
 func (wf *WALFileType) SyncWAL(WALRefresh, PrimaryRefresh time.Duration, walRotateInterval int) {
@@ -810,6 +959,13 @@ func (wf *WALFileType) SyncWAL(WALRefresh, PrimaryRefresh time.Duration, walRota
 	tickerPrimary := time.NewTicker(PrimaryRefresh)
 	tickerCheck := time.NewTicker(WALRefresh / 100)
 	primaryFlushCounter := 0
+	// Without an interval sync policy the ticker never fires
+	var tickerSync <-chan time.Time
+	if utils.InstanceConfig.WALSyncPolicy == utils.WALSyncInterval {
+		t := time.NewTicker(utils.InstanceConfig.WALSyncInterval)
+		defer t.Stop()
+		tickerSync = t.C
+	}
 
 	chanCap := cap(ThisInstance.TXNPipe.writeChannel)
 	for {
@@ -824,6 +980,18 @@ func (wf *WALFileType) SyncWAL(WALRefresh, PrimaryRefresh time.Duration, walRota
 					log.Fatal(err.Error())
 				}
 				f <- struct{}{}
+			case f := <-ThisInstance.TXNPipe.syncChannel:
+				// The requesters get the error first, but as with the other
+				// flushes the dequeued writes are lost with a failed flush
+				if err := wf.commitGroup(f); err != nil {
+					log.Fatal(err.Error())
+				}
+			case task := <-ThisInstance.TXNPipe.taskChannel:
+				task()
+			case <-tickerSync:
+				if err := wf.syncFile(); err != nil {
+					log.Error(err.Error())
+				}
 			case <-tickerCheck.C:
 				queued := len(ThisInstance.TXNPipe.writeChannel)
 				if float64(queued)/float64(chanCap) >= 0.8 {
@@ -844,9 +1012,21 @@ func (wf *WALFileType) SyncWAL(WALRefresh, PrimaryRefresh time.Duration, walRota
 		} else {
 			haveWALWriter = false
 			log.Info("Flushing to WAL...")
-			wf.flushToWAL(ThisInstance.TXNPipe)
+			err := wf.flushToWAL(ThisInstance.TXNPipe)
+			if err == nil {
+				err = wf.syncFile()
+			}
 			log.Info("Flushing to disk...")
 			wf.createCheckpoint()
+			// Release the sync requests made before the shutdown
+			for len(ThisInstance.TXNPipe.syncChannel) > 0 {
+				f := <-ThisInstance.TXNPipe.syncChannel
+				f <- err
+			}
+			for len(ThisInstance.TXNPipe.taskChannel) > 0 {
+				task := <-ThisInstance.TXNPipe.taskChannel
+				task()
+			}
 			ThisInstance.WALWg.Done()
 			return
 		}
@@ -871,3 +1051,53 @@ func (wf *WALFileType) RequestFlush() {
 	ThisInstance.TXNPipe.flushChannel <- f
 	<-f
 }
+
+// RequestSync requests a WAL flush followed by an fsync, and blocks until the
+// writes queued before the call are durable, returning an error if they could
+// not be made so. The WAL writer goroutine commits the concurrent requests as
+// a group, sharing a single fsync.
+func (wf *WALFileType) RequestSync() error {
+	if !haveWALWriter {
+		if err := wf.flushToWAL(ThisInstance.TXNPipe); err != nil {
+			return err
+		}
+		return wf.syncFile()
+	}
+	f := make(chan error, 1)
+	ThisInstance.TXNPipe.syncChannel <- f
+	return <-f
+}
+
+/*
+RunQuiesced flushes the pending writes, checkpoints the WAL and runs fn while
+the writes to the primary files are held, so that fn sees the primary files
+in a consistent state without needing the WAL. The writes made meanwhile
+queue up in the transaction pipe.
+*/
+func (wf *WALFileType) RunQuiesced(fn func() error) error {
+	return wf.runOnWriter(func() error {
+		if err := wf.flushToWAL(ThisInstance.TXNPipe); err != nil {
+			return err
+		}
+		if err := wf.syncFile(); err != nil {
+			return err
+		}
+		if err := wf.createCheckpoint(); err != nil {
+			return err
+		}
+		primaryLock.Lock()
+		defer primaryLock.Unlock()
+		return fn()
+	})
+}
+
+// runOnWriter runs fn in the WAL writer goroutine if it exists, or just in
+// the same goroutine otherwise
+func (wf *WALFileType) runOnWriter(fn func() error) error {
+	if !haveWALWriter {
+		return fn()
+	}
+	done := make(chan error, 1)
+	ThisInstance.TXNPipe.taskChannel <- func() { done <- fn() }
+	return <-done
+}
@@ -0,0 +1,394 @@
+package executor
+
+import (
+	"encoding/binary"
+	"fmt"
+	goio "io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/alpacahq/marketstore/catalog"
+	"github.com/alpacahq/marketstore/executor/colfile"
+	"github.com/alpacahq/marketstore/utils/io"
+)
+
+var (
+	// flushesStarted and flushesDone count the flushes of the write channel
+	flushesStarted, flushesDone uint64
+	// policyStates holds a policyState per bucket, keyed like bucketLocks
+	policyStates sync.Map
+)
+
+/*
+policyState serializes the writes to a bucket with a write policy, and keeps
+the records they queued until these reach the primary files, so that each
+write resolves against the previous ones without waiting for a flush
+*/
+type policyState struct {
+	sync.Mutex
+	pending map[intervalKey]pendingRecord
+}
+
+type pendingRecord struct {
+	row []byte
+	// flush is the count of started flushes when the row was queued, the row
+	// is in the primary file once a later flush is done
+	flush uint64
+}
+
+func bucketPolicyState(tbk *io.TimeBucketKey) *policyState {
+	state, _ := policyStates.LoadOrStore(tbk.GetPathToYearFiles(ThisInstance.RootDir),
+		&policyState{pending: make(map[intervalKey]pendingRecord)})
+	return state.(*policyState)
+}
+
+/*
+forgetQueuedRecords drops the records kept for the writes to a bucket, given
+the path to its year files, when its layout or its records change otherwise
+*/
+func forgetQueuedRecords(path string) {
+	if state, ok := policyStates.Load(filepath.Clean(path)); ok {
+		ps := state.(*policyState)
+		ps.Lock()
+		ps.pending = make(map[intervalKey]pendingRecord)
+		ps.Unlock()
+	}
+}
+
+// queued records the rows about to be queued, and forgets those written since
+func (ps *policyState) queued(tbi *io.TimeBucketInfo, ts []time.Time, data []byte) {
+	done := atomic.LoadUint64(&flushesDone)
+	for key, record := range ps.pending {
+		if record.flush < done {
+			delete(ps.pending, key)
+		}
+	}
+	if len(ts) == 0 {
+		return
+	}
+	flush := atomic.LoadUint64(&flushesStarted)
+	rowLen := len(data) / len(ts)
+	for i, t := range ts {
+		key := intervalKey{int16(t.Year()), io.TimeToIndex(t, tbi.GetTimeframe())}
+		ps.pending[key] = pendingRecord{row: data[i*rowLen : (i+1)*rowLen], flush: flush}
+	}
+}
+
+/*
+UpdateWritePolicy changes the write policy in the header of a year file. The
+returned TimeBucketInfo describes the updated file.
+*/
+func UpdateWritePolicy(tbi *io.TimeBucketInfo, policy io.EnumWritePolicy) (*io.TimeBucketInfo, error) {
+	newTbi := tbi.GetDeepCopy()
+	if err := newTbi.SetWritePolicy(policy); err != nil {
+		return nil, err
+	}
+	preserveFrozen(tbi.Path)
+	fp, err := os.OpenFile(tbi.Path, os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if err = io.WriteHeader(fp, newTbi); err != nil {
+		fp.Close()
+		return nil, err
+	}
+	if err = fp.Close(); err != nil {
+		return nil, err
+	}
+	forgetQueuedRecords(filepath.Dir(tbi.Path))
+	Replication.Resync()
+	return newTbi, nil
+}
+
+/*
+UpdateBucketWritePolicy changes the write policy of every year file of a
+bucket, as with UpdateWritePolicy, and updates the catalog. The writes to the
+bucket are held, and the ones queued are flushed first.
+*/
+func UpdateBucketWritePolicy(tbk *io.TimeBucketKey, policy io.EnumWritePolicy) error {
+	cDir := ThisInstance.CatalogDir
+	lock := bucketLock(tbk)
+	lock.Lock()
+	defer lock.Unlock()
+
+	tbis, err := cDir.GetTimeBucketInfoSliceFromKey(tbk)
+	if err != nil {
+		return err
+	}
+	ThisInstance.WALFile.RequestFlush()
+	for _, tbi := range tbis {
+		newTbi, err := UpdateWritePolicy(tbi, policy)
+		if err != nil {
+			return fmt.Errorf("alteration of %s failed: %s", tbi.Path, err.Error())
+		}
+		if err = cDir.UpdateTimeBucketInfo(newTbi); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type intervalKey struct {
+	year  int16
+	index int64
+}
+
+/*
+applyWritePolicy resolves the rows written to the intervals of existing
+records of a fixed bucket, or to the same interval more than once, following
+the write policy of the bucket. The existing records include those still
+queued by the writes recorded in state, if any. The returned rows hold one row
+per interval.
+*/
+func applyWritePolicy(d *catalog.Directory, tbk *io.TimeBucketKey, tbi *io.TimeBucketInfo,
+	state *policyState, ts []time.Time, data []byte) ([]time.Time, []byte, error) {
+
+	policy := tbi.GetWritePolicy()
+	if len(ts) == 0 || policy == io.OVERWRITE {
+		return ts, data, nil
+	}
+	rowLen := len(data) / len(ts)
+
+	existing, err := newRecordSource(d, tbk, state)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer existing.Close()
+
+	var (
+		outTs   []time.Time
+		outRows [][]byte
+		keep    []bool
+		rows    = make(map[intervalKey]int)
+	)
+	for i, t := range ts {
+		row := data[i*rowLen : (i+1)*rowLen]
+		key := intervalKey{int16(t.Year()), io.TimeToIndex(t, tbi.GetTimeframe())}
+		pos, written := rows[key]
+		var current []byte
+		if written {
+			current = outRows[pos]
+		} else if current, err = existing.read(key); err != nil {
+			return nil, nil, err
+		}
+		if current == nil {
+			rows[key] = len(outRows)
+			outTs = append(outTs, t)
+			outRows = append(outRows, append([]byte{}, row...))
+			keep = append(keep, true)
+			continue
+		}
+
+		var resolved []byte
+		switch policy {
+		case io.KEEPFIRST:
+			resolved = current
+		case io.REJECT:
+			return nil, nil, fmt.Errorf("a record already exists at %v in %s", t.UTC(), tbk.String())
+		case io.MERGE:
+			resolved = mergeRecords(tbi, current, row)
+		default:
+			return nil, nil, fmt.Errorf("unknown write policy %v", policy)
+		}
+		if written {
+			outRows[pos] = resolved
+		} else {
+			// The existing record is only rewritten when it changes
+			rows[key] = len(outRows)
+			outTs = append(outTs, t)
+			outRows = append(outRows, resolved)
+			keep = append(keep, policy != io.KEEPFIRST)
+		}
+	}
+
+	outData := make([]byte, 0, len(outRows)*rowLen)
+	var keptTs []time.Time
+	for i, row := range outRows {
+		if keep[i] {
+			keptTs = append(keptTs, outTs[i])
+			outData = append(outData, row...)
+		}
+	}
+	return keptTs, outData, nil
+}
+
+/*
+mergeRecords combines a row with the existing row of its interval, both
+starting with the epoch: the highest High, lowest Low, total Volume and
+first Open are kept, and the other columns take the new values.
+*/
+func mergeRecords(tbi *io.TimeBucketInfo, current, row []byte) []byte {
+	merged := append([]byte{}, row...)
+	offset := 8
+	for i, typ := range tbi.GetElementTypes() {
+		size := typ.Size()
+		old, cur := current[offset:offset+size], merged[offset:offset+size]
+		switch strings.ToLower(tbi.GetElementNames()[i]) {
+		case "open":
+			copy(cur, old)
+		case "high":
+			if compareValues(typ, old, cur) > 0 {
+				copy(cur, old)
+			}
+		case "low":
+			if compareValues(typ, old, cur) < 0 {
+				copy(cur, old)
+			}
+		case "volume":
+			addValues(typ, cur, old)
+		}
+		offset += size
+	}
+	return merged
+}
+
+func compareValues(typ io.EnumElementType, a, b []byte) int {
+	var x, y float64
+	switch typ {
+	case io.FLOAT32:
+		x = float64(math.Float32frombits(binary.LittleEndian.Uint32(a)))
+		y = float64(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+	case io.FLOAT64:
+		x = math.Float64frombits(binary.LittleEndian.Uint64(a))
+		y = math.Float64frombits(binary.LittleEndian.Uint64(b))
+	default:
+		i, j := intValue(typ, a), intValue(typ, b)
+		switch {
+		case i < j:
+			return -1
+		case i > j:
+			return 1
+		}
+		return 0
+	}
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+// addValues adds b to a in place
+func addValues(typ io.EnumElementType, a, b []byte) {
+	switch typ {
+	case io.FLOAT32:
+		sum := math.Float32frombits(binary.LittleEndian.Uint32(a)) +
+			math.Float32frombits(binary.LittleEndian.Uint32(b))
+		binary.LittleEndian.PutUint32(a, math.Float32bits(sum))
+	case io.FLOAT64:
+		sum := math.Float64frombits(binary.LittleEndian.Uint64(a)) +
+			math.Float64frombits(binary.LittleEndian.Uint64(b))
+		binary.LittleEndian.PutUint64(a, math.Float64bits(sum))
+	default:
+		sum := uint64(intValue(typ, a) + intValue(typ, b))
+		switch typ.Size() {
+		case 1:
+			a[0] = byte(sum)
+		case 2:
+			binary.LittleEndian.PutUint16(a, uint16(sum))
+		case 4:
+			binary.LittleEndian.PutUint32(a, uint32(sum))
+		case 8:
+			binary.LittleEndian.PutUint64(a, sum)
+		}
+	}
+}
+
+func intValue(typ io.EnumElementType, b []byte) int64 {
+	switch typ {
+	case io.INT16:
+		return int64(int16(binary.LittleEndian.Uint16(b)))
+	case io.INT32:
+		return int64(int32(binary.LittleEndian.Uint32(b)))
+	case io.INT64, io.EPOCH:
+		return int64(binary.LittleEndian.Uint64(b))
+	case io.UINT8, io.BYTE, io.BOOL:
+		return int64(b[0])
+	case io.UINT16:
+		return int64(binary.LittleEndian.Uint16(b))
+	case io.UINT32:
+		return int64(binary.LittleEndian.Uint32(b))
+	case io.UINT64:
+		return int64(binary.LittleEndian.Uint64(b))
+	}
+	return 0
+}
+
+// recordSource reads the existing records of a fixed bucket
+type recordSource struct {
+	state *policyState
+	tbis  map[int16]*io.TimeBucketInfo
+	files map[int16]interface {
+		goio.ReaderAt
+		goio.Closer
+	}
+}
+
+func newRecordSource(d *catalog.Directory, tbk *io.TimeBucketKey, state *policyState) (*recordSource, error) {
+	tbis, err := d.GetTimeBucketInfoSliceFromKey(tbk)
+	if err != nil {
+		return nil, err
+	}
+	rs := &recordSource{
+		state: state,
+		tbis:  make(map[int16]*io.TimeBucketInfo),
+		files: make(map[int16]interface {
+			goio.ReaderAt
+			goio.Closer
+		}),
+	}
+	for _, tbi := range tbis {
+		rs.tbis[tbi.Year] = tbi
+	}
+	return rs, nil
+}
+
+// read returns the record of an interval, which starts with its index like
+// rows start with their epoch, or nil if the interval is empty
+func (rs *recordSource) read(key intervalKey) ([]byte, error) {
+	if rs.state != nil {
+		// The records flushed since are read from the file
+		record, ok := rs.state.pending[key]
+		if ok && record.flush >= atomic.LoadUint64(&flushesDone) {
+			return record.row, nil
+		}
+	}
+	tbi, ok := rs.tbis[key.year]
+	if !ok {
+		return nil, nil
+	}
+	f, ok := rs.files[key.year]
+	if !ok {
+		var err error
+		if tbi.GetCompression() != io.NOCOMPRESSION {
+			f, err = colfile.Open(tbi.Path, os.O_RDONLY)
+		} else {
+			f, err = os.Open(tbi.Path)
+		}
+		if err != nil {
+			return nil, err
+		}
+		rs.files[key.year] = f
+	}
+	record := make([]byte, tbi.GetRecordLength())
+	if _, err := f.ReadAt(record, io.IndexToOffset(key.index, tbi.GetRecordLength())); err != nil {
+		return nil, err
+	}
+	if binary.LittleEndian.Uint64(record) == 0 {
+		return nil, nil
+	}
+	return record, nil
+}
+
+func (rs *recordSource) Close() {
+	for _, f := range rs.files {
+		f.Close()
+	}
+}
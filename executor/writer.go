@@ -10,8 +10,10 @@ import (
 	"github.com/klauspost/compress/snappy"
 	stdio "io"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -46,6 +48,12 @@ func (w *Writer) AddNewYearFile(year int16) (err error) {
 		return err
 	}
 	w.tbi = newTbi
+	// The categories are only needed by the followers for new buckets
+	itemKey, err := filepath.Rel(w.root.GetPath(), filepath.Dir(newTbi.Path))
+	if err != nil {
+		return err
+	}
+	Replication.PublishCreate(io.NewTimeBucketKey(filepath.ToSlash(itemKey)), newTbi)
 	return nil
 }
 
@@ -81,6 +89,9 @@ func (w *Writer) WriteRecords(ts []time.Time, data []byte) {
 				Trim the Epoch column off and replace it with ticks since bucket time
 			*/
 			outBuf = append(buf, record...)
+			if w.tbi.GetTimestampFormat() == NANOSECONDS {
+				return AppendIntervalNanoseconds(outBuf, t, index, w.tbi.GetTimeframe())
+			}
 			outBuf = AppendIntervalTicks(outBuf, t, index, intervalsPerDay)
 			return outBuf
 		}
@@ -160,6 +171,14 @@ func AppendIntervalTicks(buf []byte, t time.Time, index, intervalsPerDay int64)
 	return outBuf
 }
 
+// AppendIntervalNanoseconds appends the int64 number of nanoseconds between the
+// start of the interval and the timestamp
+func AppendIntervalNanoseconds(buf []byte, t time.Time, index int64, tf time.Duration) (outBuf []byte) {
+	nanos := t.Sub(IndexToTime(index, tf, int16(t.Year()))).Nanoseconds()
+	postdata, _ := Serialize([]byte{}, nanos)
+	return append(buf, postdata...)
+}
+
 func WriteBufferToFile(fp stdio.WriterAt, buffer offsetIndexBuffer) error {
 	offset := buffer.Offset()
 	data := buffer.IndexAndPayload()
@@ -179,6 +198,11 @@ func WriteBufferToFileIndirect(fp *os.File, buffer offsetIndexBuffer, varRecLen
 	*/
 	primaryOffset := buffer.Offset() // Offset to storage of indirect record info
 	index := buffer.Index()
+	if index == 0 {
+		// Deletes the record, leaving its data unreferenced
+		_, err = fp.WriteAt(make([]byte, 24), primaryOffset)
+		return err
+	}
 	dataToBeWritten := buffer.Payload()
 	dataLen := int64(len(dataToBeWritten))
 	/*
@@ -223,7 +247,15 @@ func WriteBufferToFileIndirect(fp *os.File, buffer offsetIndexBuffer, varRecLen
 	/*
 		Sort the data by the timestamp to maintain on-disk sorted order
 	*/
-	sort.Stable(NewByIntervalTicks(dataToBeWritten, int(dataLen)/int(varRecLen), int(varRecLen)))
+	timestampFormat, err := ReadTimestampFormat(fp)
+	if err != nil {
+		return err
+	}
+	if timestampFormat == NANOSECONDS {
+		sort.Stable(NewByIntervalNanoseconds(dataToBeWritten, int(dataLen)/int(varRecLen), int(varRecLen)))
+	} else {
+		sort.Stable(NewByIntervalTicks(dataToBeWritten, int(dataLen)/int(varRecLen), int(varRecLen)))
+	}
 
 	/*
 		Write the data at the end of the file
@@ -258,82 +290,162 @@ func WriteBufferToFileIndirect(fp *os.File, buffer offsetIndexBuffer, varRecLen
 // isVariableLength is set to true if the record content is variable-length type. WriteCSM
 // also verifies the DataShapeVector of the incoming ColumnSeriesMap matches the on-disk
 // DataShapeVector defined by the file header. WriteCSM will create any files if they do
-// not already exist for the given ColumnSeriesMap based on its TimeBucketKey.
+// not already exist for the given ColumnSeriesMap based on its TimeBucketKey. Nothing is
+// written if any of the column series fails the checks.
 func WriteCSM(csm io.ColumnSeriesMap, isVariableLength bool) (err error) {
+	if ThisInstance.ReadOnly {
+		return ReadOnlyError("WriteCSM")
+	}
 	cDir := ThisInstance.CatalogDir
-	for tbk, cs := range csm {
-		tf, err := tbk.GetTimeFrame()
+	// The buckets are locked in the same order by all the writers
+	keys := make([]io.TimeBucketKey, 0, len(csm))
+	for tbk := range csm {
+		keys = append(keys, tbk)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+
+	writes := make([]*columnSeriesWrite, 0, len(keys))
+	for i := range keys {
+		lock := bucketLock(&keys[i])
+		lock.RLock()
+		defer lock.RUnlock()
+		cw, err := prepareColumnSeries(cDir, keys[i], csm[keys[i]], isVariableLength)
 		if err != nil {
 			return err
 		}
+		if cw == nil {
+			continue
+		}
+		if cw.state != nil {
+			defer cw.state.Unlock()
+		}
+		writes = append(writes, cw)
+	}
+	for _, cw := range writes {
+		if cw.state != nil {
+			cw.state.queued(cw.w.tbi, cw.times, cw.data)
+		}
+		cw.w.WriteRecords(cw.times, cw.data)
+	}
+	wal := ThisInstance.WALFile
+	wal.RequestFlush()
+	return nil
+}
+
+// columnSeriesWrite holds the records of a column series ready to be queued
+type columnSeriesWrite struct {
+	w     *Writer
+	times []time.Time
+	data  []byte
+	// state is the locked policyState of a bucket with a write policy
+	state *policyState
+}
 
+/*
+prepareColumnSeries checks a column series against the data shapes of its
+bucket and resolves its records with the write policy of the bucket. It
+returns nil if there is nothing to write. The caller holds the bucket lock for
+reading until the records are queued, so that the bucket can not be altered
+in between.
+*/
+func prepareColumnSeries(cDir *catalog.Directory, tbk io.TimeBucketKey, cs *io.ColumnSeries,
+	isVariableLength bool) (*columnSeriesWrite, error) {
+
+	tf, err := tbk.GetTimeFrame()
+	if err != nil {
+		return nil, err
+	}
+
+	/*
+		Prepare data for writing
+	*/
+	var alignData bool
+	times := cs.GetTime()
+	if isVariableLength {
+		cs.Remove("Nanoseconds")
+		alignData = false
+	}
+	rs := cs.ToRowSeries(tbk, alignData)
+	rowdata := rs.GetData()
+
+	tbi, err := cDir.GetLatestTimeBucketInfoFromKey(&tbk)
+	if err != nil {
 		/*
-			Prepare data for writing
+			If we can't get the info, we try here to add a new one
 		*/
-		var alignData bool
-		times := cs.GetTime()
+		var recordType io.EnumRecordType
 		if isVariableLength {
-			cs.Remove("Nanoseconds")
-			alignData = false
+			recordType = io.VARIABLE
+		} else {
+			recordType = io.FIXED
 		}
-		rs := cs.ToRowSeries(tbk, alignData)
-		rowdata := rs.GetData()
-
-		tbi, err := cDir.GetLatestTimeBucketInfoFromKey(&tbk)
-		if err != nil {
-			/*
-				If we can't get the info, we try here to add a new one
-			*/
-			var recordType io.EnumRecordType
-			if isVariableLength {
-				recordType = io.VARIABLE
-			} else {
-				recordType = io.FIXED
-			}
-
-			if len(cs.GetTime()) == 0 {
-				continue
-			}
-			year := int16(cs.GetTime()[0].Year())
-			tbi = io.NewTimeBucketInfo(
-				*tf,
-				tbk.GetPathToYearFiles(cDir.GetPath()),
-				"Created By Writer", year,
-				cs.GetDataShapes(), recordType)
 
-			/*
-				Verify there is an available TimeBucket for the destination
-			*/
-			if err := cDir.AddTimeBucket(&tbk, tbi); err != nil {
-				// If File Exists error, ignore it, otherwise return the error
-				if !strings.Contains(err.Error(), "Can not overwrite file") && !strings.Contains(err.Error(), "file exists") {
-					return err
-				}
-			}
-		}
-		// Check if the previously-written data schema matches the input
-		columnMismatchError := "unable to match data columns (%v) to bucket columns (%v)"
-		dbDSV := tbi.GetDataShapesWithEpoch()
-		csDSV := cs.GetDataShapes()
-		if len(dbDSV) != len(csDSV) {
-			return fmt.Errorf(columnMismatchError, csDSV, dbDSV)
-		}
-		missing, coercion := GetMissingAndTypeCoercionColumns(dbDSV, csDSV)
-		if missing != nil || coercion != nil {
-			return fmt.Errorf(columnMismatchError, csDSV, dbDSV)
+		if len(cs.GetTime()) == 0 {
+			return nil, nil
 		}
+		year := int16(cs.GetTime()[0].Year())
+		tbi = io.NewTimeBucketInfo(
+			*tf,
+			tbk.GetPathToYearFiles(cDir.GetPath()),
+			"Created By Writer", year,
+			cs.GetDataShapes(), recordType)
 
 		/*
-			Create a writer for this TimeBucket
+			Verify there is an available TimeBucket for the destination
 		*/
-		w, err := NewWriter(tbi, ThisInstance.TXNPipe, cDir)
-		if err != nil {
-			return err
+		if err := cDir.AddTimeBucket(&tbk, tbi); err != nil {
+			// If File Exists error, ignore it, otherwise return the error
+			if !strings.Contains(err.Error(), "Can not overwrite file") && !strings.Contains(err.Error(), "file exists") {
+				return nil, err
+			}
+		} else {
+			Replication.PublishCreate(&tbk, tbi)
 		}
+	}
+	// Check if the previously-written data schema matches the input
+	columnMismatchError := "unable to match data columns (%v) to bucket columns (%v)"
+	dbDSV := tbi.GetDataShapesWithEpoch()
+	csDSV := cs.GetDataShapes()
+	if len(dbDSV) != len(csDSV) {
+		return nil, fmt.Errorf(columnMismatchError, csDSV, dbDSV)
+	}
+	missing, coercion := GetMissingAndTypeCoercionColumns(dbDSV, csDSV)
+	if missing != nil || coercion != nil {
+		return nil, fmt.Errorf(columnMismatchError, csDSV, dbDSV)
+	}
 
-		w.WriteRecords(times, rowdata)
+	/*
+		Create a writer for this TimeBucket
+	*/
+	w, err := NewWriter(tbi, ThisInstance.TXNPipe, cDir)
+	if err != nil {
+		return nil, err
 	}
-	wal := ThisInstance.WALFile
-	wal.RequestFlush()
-	return nil
+	cw := &columnSeriesWrite{w: w, times: times, data: rowdata}
+
+	if tbi.GetRecordType() == io.FIXED && tbi.GetWritePolicy() != io.OVERWRITE {
+		// The policy applies to the records written before, queued or not
+		state := bucketPolicyState(&tbk)
+		state.Lock()
+		cw.times, cw.data, err = applyWritePolicy(cDir, &tbk, tbi, state, times, rowdata)
+		if err != nil {
+			state.Unlock()
+			return nil, err
+		}
+		cw.state = state
+	}
+	return cw, nil
+}
+
+// bucketLocks holds a lock per bucket, keyed by the path to its year files
+var bucketLocks sync.Map
+
+/*
+bucketLock returns the lock of a bucket, held for reading by the writers
+between the check of their data shapes and the queueing of their records,
+and for writing by the changes of its layout
+*/
+func bucketLock(tbk *io.TimeBucketKey) *sync.RWMutex {
+	lock, _ := bucketLocks.LoadOrStore(tbk.GetPathToYearFiles(ThisInstance.RootDir), &sync.RWMutex{})
+	return lock.(*sync.RWMutex)
 }
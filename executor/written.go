@@ -15,12 +15,15 @@ var (
 	c         chan writtenRecords
 	done      chan struct{}
 	m         map[string][]trigger.Record
+	deleted   map[string][]int64
 	triggerWg sync.WaitGroup
 )
 
 type writtenRecords struct {
 	key     string
 	records []trigger.Record
+	// Indexes of the deleted records, fired to the delete triggers
+	deleted []int64
 }
 
 func setup() {
@@ -38,6 +41,15 @@ func appendRecord(keyPath string, record []byte) {
 	m[keyPath] = append(m[keyPath], record)
 }
 
+// appendDeleted collects the indexes of the records deleted from a file.
+func appendDeleted(keyPath string, indexes []int64) {
+	once.Do(setup)
+	if deleted == nil {
+		deleted = make(map[string][]int64)
+	}
+	deleted[keyPath] = append(deleted[keyPath], indexes...)
+}
+
 // dispatchRecords iterates over the registered triggers and fire the event
 // if the file path matches the condition.  This is meant to be
 // run in a separate goroutine and recovers from panics in the triggers.
@@ -46,15 +58,25 @@ func dispatchRecords() {
 		c <- writtenRecords{key: key, records: records}
 	}
 	m = nil // for GC
+	for key, indexes := range deleted {
+		c <- writtenRecords{key: key, deleted: indexes}
+	}
+	deleted = nil
 }
 
 func run() {
 	defer func() { done <- struct{}{} }()
 	for wr := range c {
 		for _, tmatcher := range ThisInstance.TriggerMatchers {
-			if tmatcher.Match(wr.key) {
+			if !tmatcher.Match(wr.key) {
+				continue
+			}
+			if wr.deleted == nil {
 				triggerWg.Add(1)
 				go fire(tmatcher.Trigger, wr.key, wr.records)
+			} else if dt, ok := tmatcher.Trigger.(trigger.DeleteTrigger); ok {
+				triggerWg.Add(1)
+				go fireDelete(dt, wr.key, wr.deleted)
 			}
 		}
 	}
@@ -70,14 +92,27 @@ func fire(trig trigger.Trigger, key string, records []trigger.Record) {
 	trig.Fire(key, records)
 }
 
+func fireDelete(trig trigger.DeleteTrigger, key string, indexes []int64) {
+	defer func() {
+		triggerWg.Done()
+		if r := recover(); r != nil {
+			log.Error("recovering from %v\n%s", r, string(debug.Stack()))
+		}
+	}()
+	trig.FireDelete(key, indexes)
+}
+
 // FinishAndWait closes the writtenIndexes channel, and waits
-// for the remaining triggers to fire, returning
+// for the remaining triggers to fire, returning. The dispatch starts over
+// with the next written records.
 func FinishAndWait() {
+	once.Do(setup)
 	triggerWg.Wait()
 	for {
 		if len(ThisInstance.TXNPipe.writeChannel) == 0 && len(c) == 0 {
 			close(c)
 			<-done
+			once = sync.Once{}
 			return
 		}
 		time.Sleep(500 * time.Millisecond)
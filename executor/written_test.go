@@ -25,22 +25,55 @@ func (t *FakeTrigger) Fire(keyPath string, records []trigger.Record) {
 	t.fireC <- struct{}{}
 }
 
+type FakeDeleteTrigger struct {
+	FakeTrigger
+	deletedC chan []int64
+}
+
+func (t *FakeDeleteTrigger) FireDelete(keyPath string, indexes []int64) {
+	t.deletedC <- indexes
+}
+
 func (s *WrittenIndexesTests) SetUpSuite(c *C) {
+	stopDispatch()
 	ThisInstance = &InstanceMetadata{}
 	ThisInstance.TXNPipe = NewTransactionPipe()
 }
 
 func (s *WrittenIndexesTests) TearDownSuite(c *C) {
+	stopDispatch()
 	ThisInstance.TriggerMatchers = nil
 }
 
+// SetTrigger installs the trigger before the next dispatch starts
 func (s *WrittenIndexesTests) SetTrigger(t trigger.Trigger, on string) {
+	stopDispatch()
 	matchers := []*trigger.TriggerMatcher{
 		trigger.NewMatcher(t, on),
 	}
 	ThisInstance.TriggerMatchers = matchers
 }
 
+// stopDispatch waits for the dispatch of the records written so far to end,
+// so that the instance can change without racing with it
+func stopDispatch() {
+	if ThisInstance != nil && ThisInstance.TXNPipe != nil {
+		FinishAndWait()
+	}
+}
+
+func (s *WrittenIndexesTests) TestDeletedIndexes(c *C) {
+	t := &FakeDeleteTrigger{FakeTrigger{fireC: make(chan struct{})}, make(chan []int64)}
+	s.SetTrigger(t, "AAPL/1Min/OHLCV")
+
+	appendDeleted("AAPL/1Min/OHLCV/2017.bin", []int64{5, 6})
+	appendDeleted("TSLA/1Min/OHLCV/2017.bin", []int64{7})
+	dispatchRecords()
+
+	c.Check(<-t.deletedC, DeepEquals, []int64{5, 6})
+	c.Check(len(t.calledWith), Equals, 0)
+}
+
 func (s *WrittenIndexesTests) TestWrittenIndexes(c *C) {
 	t := &FakeTrigger{fireC: make(chan struct{})}
 	s.SetTrigger(t, "AAPL/1Min/OHLCV")
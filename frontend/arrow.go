@@ -0,0 +1,333 @@
+package frontend
+
+import (
+	"errors"
+	"fmt"
+	stdio "io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/ipc"
+	"github.com/apache/arrow/go/arrow/memory"
+
+	"github.com/alpacahq/marketstore/utils"
+	"github.com/alpacahq/marketstore/utils/io"
+	"github.com/alpacahq/marketstore/utils/log"
+)
+
+// Output formats of a QueryRequest
+const (
+	FormatNumpy = "numpy"
+	// Arrow IPC streaming format
+	FormatArrow = "arrow"
+	// Arrow IPC file format, which allows random access to the record batches
+	FormatArrowFile = "arrow-file"
+)
+
+// Media types of the Arrow IPC formats
+const (
+	ArrowStreamMediaType = "application/vnd.apache.arrow.stream"
+	ArrowFileMediaType   = "application/vnd.apache.arrow.file"
+)
+
+var arrowTypes = map[io.EnumElementType]arrow.DataType{
+	io.BYTE:    arrow.PrimitiveTypes.Int8,
+	io.INT16:   arrow.PrimitiveTypes.Int16,
+	io.INT32:   arrow.PrimitiveTypes.Int32,
+	io.INT64:   arrow.PrimitiveTypes.Int64,
+	io.UINT8:   arrow.PrimitiveTypes.Uint8,
+	io.UINT16:  arrow.PrimitiveTypes.Uint16,
+	io.UINT32:  arrow.PrimitiveTypes.Uint32,
+	io.UINT64:  arrow.PrimitiveTypes.Uint64,
+	io.FLOAT32: arrow.PrimitiveTypes.Float32,
+	io.FLOAT64: arrow.PrimitiveTypes.Float64,
+	io.EPOCH:   arrow.FixedWidthTypes.Timestamp_s,
+	io.BOOL:    arrow.FixedWidthTypes.Boolean,
+}
+
+// ArrowType returns the Arrow type of the columns of an element type. Only
+// the fixed width types have one.
+func ArrowType(typ io.EnumElementType) (arrow.DataType, error) {
+	if dtype, ok := arrowTypes[typ]; ok {
+		return dtype, nil
+	}
+	return nil, fmt.Errorf("type %s has no arrow equivalent", typ.String())
+}
+
+// CheckFormat returns an error for an unknown output format of a query
+func CheckFormat(format string) error {
+	switch format {
+	case "", FormatNumpy, FormatArrow, FormatArrowFile:
+		return nil
+	}
+	return fmt.Errorf("unknown query format %q, must be one of %s, %s or %s",
+		format, FormatNumpy, FormatArrow, FormatArrowFile)
+}
+
+// EncodeFormat replaces the Result of the response by its Arrow encoding when
+// the format is one of the Arrow formats
+func (qr *QueryResponse) EncodeFormat(format string) (err error) {
+	if qr.Result == nil || (format != FormatArrow && format != FormatArrowFile) {
+		return nil
+	}
+	if qr.Arrow, err = EncodeArrow(qr.Result, format == FormatArrowFile); err != nil {
+		return err
+	}
+	qr.Result = nil
+	return nil
+}
+
+/*
+EncodeArrow encodes a dataset in the Arrow IPC streaming format, or in the
+Arrow IPC file format, with one record batch per TimeBucketKey in key order.
+
+The batches share the column data of the dataset instead of copying it. The
+Epoch column is a timestamp in seconds, and a Symbol column leads the others
+when every key has a symbol, so that the batches concatenate into one table.
+*/
+func EncodeArrow(nmds *io.NumpyMultiDataset, file bool) ([]byte, error) {
+	if nmds == nil {
+		return nil, nil
+	}
+	schema, records, err := arrowRecords(nmds)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		for _, rec := range records {
+			rec.Release()
+		}
+	}()
+
+	buf := &writeBuffer{}
+	var w interface {
+		Write(rec array.Record) error
+		Close() error
+	}
+	if file {
+		if w, err = ipc.NewFileWriter(buf, ipc.WithSchema(schema)); err != nil {
+			return nil, err
+		}
+	} else {
+		w = ipc.NewWriter(buf, ipc.WithSchema(schema))
+	}
+	for _, rec := range records {
+		if err = w.Write(rec); err != nil {
+			return nil, err
+		}
+	}
+	if err = w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.buf, nil
+}
+
+/*
+ArrowHandler serves a native query as Arrow IPC data, taking the fields of the
+QueryRequest as URL (or form) parameters:
+
+	destination, key_category, epoch_start, epoch_end, limit_record_count,
+	limit_from_start, columns (comma separated) and function (repeated)
+
+The format parameter is either stream, the default, or file. A query without
+any result is answered with No Content.
+*/
+func ArrowHandler(service Service) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		if atomic.LoadUint32(&Queryable) == 0 {
+			http.Error(rw, queryableError.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		req, err := arrowQueryRequest(r)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+		response := &MultiQueryResponse{}
+		err = service.Query(r, &MultiQueryRequest{Requests: []QueryRequest{*req}}, response)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		rw.Header().Set("marketstore-version", utils.GitHash)
+		if len(response.Responses) == 0 || response.Responses[0].Arrow == nil {
+			rw.WriteHeader(http.StatusNoContent)
+			return
+		}
+		mediaType := ArrowStreamMediaType
+		if req.Format == FormatArrowFile {
+			mediaType = ArrowFileMediaType
+		}
+		rw.Header().Set("Content-Type", mediaType)
+		rw.Header().Set("Content-Length", strconv.Itoa(len(response.Responses[0].Arrow)))
+		if _, err = rw.Write(response.Responses[0].Arrow); err != nil {
+			log.Error("Failed to write arrow response - Error: %v", err)
+		}
+	}
+}
+
+/*
+Utility functions
+*/
+
+func arrowQueryRequest(r *http.Request) (*QueryRequest, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, err
+	}
+	req := &QueryRequest{
+		Destination: r.Form.Get("destination"),
+		KeyCategory: r.Form.Get("key_category"),
+		Functions:   r.Form["function"],
+		Format:      FormatArrow,
+	}
+	if req.Destination == "" {
+		return nil, errors.New("destination is required")
+	}
+	switch r.Form.Get("format") {
+	case "", "stream":
+	case "file":
+		req.Format = FormatArrowFile
+	default:
+		return nil, fmt.Errorf("format must be stream or file, have: %s", r.Form.Get("format"))
+	}
+	if columns := r.Form.Get("columns"); columns != "" {
+		req.Columns = strings.Split(columns, ",")
+	}
+	for _, name := range []string{"epoch_start", "epoch_end", "limit_record_count"} {
+		value := r.Form.Get(name)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be an integer, have: %s", name, value)
+		}
+		switch name {
+		case "epoch_start":
+			req.EpochStart = &n
+		case "epoch_end":
+			req.EpochEnd = &n
+		case "limit_record_count":
+			limitRecordCount := int(n)
+			req.LimitRecordCount = &limitRecordCount
+		}
+	}
+	if value := r.Form.Get("limit_from_start"); value != "" {
+		limitFromStart, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("limit_from_start must be a boolean, have: %s", value)
+		}
+		req.LimitFromStart = &limitFromStart
+	}
+	return req, nil
+}
+
+// arrowRecords builds the record batches of a dataset, one per TimeBucketKey
+func arrowRecords(nmds *io.NumpyMultiDataset) (*arrow.Schema, []array.Record, error) {
+	shapes, err := nmds.DataShapes()
+	if err != nil {
+		return nil, nil, err
+	}
+	keys := make([]string, 0, len(nmds.StartIndex))
+	withSymbol := true
+	for key := range nmds.StartIndex {
+		keys = append(keys, key)
+		if io.NewTimeBucketKeyFromString(key).GetItemInCategory("Symbol") == "" {
+			withSymbol = false
+		}
+	}
+	sort.Strings(keys)
+
+	var fields []arrow.Field
+	if withSymbol {
+		fields = append(fields, arrow.Field{Name: "Symbol", Type: arrow.BinaryTypes.String})
+	}
+	for _, shape := range shapes {
+		typ := shape.Type
+		if shape.Name == "Epoch" && typ == io.INT64 {
+			typ = io.EPOCH
+		}
+		dtype, err := ArrowType(typ)
+		if err != nil {
+			return nil, nil, fmt.Errorf("column %s: %s", shape.Name, err.Error())
+		}
+		fields = append(fields, arrow.Field{Name: shape.Name, Type: dtype})
+	}
+	schema := arrow.NewSchema(fields, nil)
+
+	records := make([]array.Record, 0, len(keys))
+	for _, key := range keys {
+		start, length := nmds.StartIndex[key], nmds.Lengths[key]
+		cols := make([]array.Interface, 0, len(fields))
+		if withSymbol {
+			symbol := io.NewTimeBucketKeyFromString(key).GetItemInCategory("Symbol")
+			b := array.NewStringBuilder(memory.DefaultAllocator)
+			b.Reserve(length)
+			for i := 0; i < length; i++ {
+				b.Append(symbol)
+			}
+			cols = append(cols, b.NewArray())
+			b.Release()
+		}
+		for i, shape := range shapes {
+			size := shape.Type.Size()
+			if shape.Type == io.BOOL {
+				// Arrow packs the booleans into bits
+				b := array.NewBooleanBuilder(memory.DefaultAllocator)
+				b.Reserve(length)
+				for _, v := range nmds.ColumnData[i][start : start+length] {
+					b.Append(v != 0)
+				}
+				cols = append(cols, b.NewArray())
+				b.Release()
+				continue
+			}
+			buf := memory.NewBufferBytes(nmds.ColumnData[i][start*size : (start+length)*size])
+			data := array.NewData(fields[len(cols)].Type, length, []*memory.Buffer{nil, buf}, nil, 0, 0)
+			cols = append(cols, array.MakeFromData(data))
+			data.Release()
+		}
+		records = append(records, array.NewRecord(schema, cols, int64(length)))
+		for _, col := range cols {
+			col.Release()
+		}
+	}
+	return schema, records, nil
+}
+
+// writeBuffer is an in-memory io.WriteSeeker, as the Arrow file writer needs
+// to seek
+type writeBuffer struct {
+	buf []byte
+	pos int
+}
+
+func (b *writeBuffer) Write(p []byte) (int, error) {
+	if end := b.pos + len(p); end > len(b.buf) {
+		b.buf = append(b.buf, make([]byte, end-len(b.buf))...)
+	}
+	copy(b.buf[b.pos:], p)
+	b.pos += len(p)
+	return len(p), nil
+}
+
+func (b *writeBuffer) Seek(offset int64, whence int) (int64, error) {
+	pos := offset
+	switch whence {
+	case stdio.SeekCurrent:
+		pos += int64(b.pos)
+	case stdio.SeekEnd:
+		pos += int64(len(b.buf))
+	}
+	if pos < 0 {
+		return 0, errors.New("negative position")
+	}
+	b.pos = int(pos)
+	return pos, nil
+}
@@ -0,0 +1,117 @@
+package frontend
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/ipc"
+	. "gopkg.in/check.v1"
+
+	"github.com/alpacahq/marketstore/utils/io"
+)
+
+func (s *ServerTestSuite) TestQueryArrow(c *C) {
+	service := &DataService{}
+	service.Init()
+
+	query := func(format string) QueryResponse {
+		response := &MultiQueryResponse{}
+		err := service.Query(nil, &MultiQueryRequest{Requests: []QueryRequest{
+			NewQueryRequestBuilder("USDJPY,EURUSD/1Min/OHLC").
+				LimitRecordCount(5).
+				Format(format).
+				End(),
+		}}, response)
+		c.Assert(err, IsNil)
+		return response.Responses[0]
+	}
+	expected, err := query(FormatNumpy).Result.ToColumnSeriesMap()
+	c.Assert(err, IsNil)
+
+	// The batches are in key order, led by a Symbol column
+	check := func(schema *arrow.Schema, records []array.Record) {
+		c.Assert(schema.Field(0).Name, Equals, "Symbol")
+		c.Assert(schema.Field(1).Type, DeepEquals, arrow.FixedWidthTypes.Timestamp_s)
+		c.Assert(schema.Field(2).Type, Equals, arrow.PrimitiveTypes.Float32)
+		c.Assert(records, HasLen, 2)
+		for i, symbol := range []string{"EURUSD", "USDJPY"} {
+			rec := records[i]
+			cs := expected[*io.NewTimeBucketKey(symbol + "/1Min/OHLC")]
+			c.Assert(rec.NumRows(), Equals, int64(5))
+			c.Assert(rec.Column(0).(*array.String).Value(4), Equals, symbol)
+			c.Assert(int64(rec.Column(1).(*array.Timestamp).Value(4)), Equals, cs.GetEpoch()[4])
+			c.Assert(rec.Column(5).(*array.Float32).Float32Values(), DeepEquals, cs.GetByName("Close"))
+		}
+	}
+
+	resp := query(FormatArrow)
+	c.Assert(resp.Result, IsNil)
+	r, err := ipc.NewReader(bytes.NewReader(resp.Arrow))
+	c.Assert(err, IsNil)
+	var records []array.Record
+	for r.Next() {
+		rec := r.Record()
+		rec.Retain()
+		records = append(records, rec)
+	}
+	c.Assert(r.Err(), IsNil)
+	check(r.Schema(), records)
+
+	resp = query(FormatArrowFile)
+	f, err := ipc.NewFileReader(bytes.NewReader(resp.Arrow))
+	c.Assert(err, IsNil)
+	records = nil
+	for i := 0; i < f.NumRecords(); i++ {
+		rec, err := f.Record(i)
+		c.Assert(err, IsNil)
+		rec.Retain()
+		records = append(records, rec)
+	}
+	check(f.Schema(), records)
+
+	err = service.Query(nil, &MultiQueryRequest{Requests: []QueryRequest{
+		NewQueryRequestBuilder("USDJPY/1Min/OHLC").Format("csv").End(),
+	}}, &MultiQueryResponse{})
+	c.Assert(err, NotNil)
+
+	// HTTP endpoint
+	handler := ArrowHandler(service)
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest("GET",
+		"/arrow?destination=USDJPY/1Min/OHLC&limit_record_count=3&format=file", nil))
+	c.Assert(rec.Code, Equals, http.StatusOK)
+	c.Assert(rec.Header().Get("Content-Type"), Equals, ArrowFileMediaType)
+	f, err = ipc.NewFileReader(bytes.NewReader(rec.Body.Bytes()))
+	c.Assert(err, IsNil)
+	c.Assert(f.Schema().Fields(), HasLen, 6)
+	c.Assert(f.NumRecords(), Equals, 1)
+	record, err := f.Record(0)
+	c.Assert(err, IsNil)
+	c.Assert(record.NumRows(), Equals, int64(3))
+
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest("GET", "/arrow?destination=USDJPY/1Min/OHLC&epoch_start=x", nil))
+	c.Assert(rec.Code, Equals, http.StatusBadRequest)
+}
+
+func (s *ServerTestSuite) TestEncodeArrowBool(c *C) {
+	cs := io.NewColumnSeries()
+	cs.AddColumn("Epoch", []int64{1, 2, 3})
+	cs.AddColumn("Halted", []bool{true, false, true})
+	nds, err := io.NewNumpyDataset(cs)
+	c.Assert(err, IsNil)
+	nmds, err := io.NewNumpyMultiDataset(nds, *io.NewTimeBucketKey("AAPL/1Min/HALT"))
+	c.Assert(err, IsNil)
+
+	buf, err := EncodeArrow(nmds, false)
+	c.Assert(err, IsNil)
+	r, err := ipc.NewReader(bytes.NewReader(buf))
+	c.Assert(err, IsNil)
+	c.Assert(r.Schema().Field(2).Type, Equals, arrow.FixedWidthTypes.Boolean)
+	c.Assert(r.Next(), Equals, true)
+	halted := r.Record().Column(2).(*array.Boolean)
+	c.Assert([]bool{halted.Value(0), halted.Value(1), halted.Value(2)}, DeepEquals, []bool{true, false, true})
+}
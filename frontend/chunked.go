@@ -0,0 +1,98 @@
+package frontend
+
+import (
+	"fmt"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/alpacahq/marketstore/utils"
+	"github.com/alpacahq/marketstore/utils/io"
+	"github.com/alpacahq/marketstore/utils/log"
+	"github.com/vmihailenco/msgpack"
+)
+
+// DefaultStreamPageSize is the number of rows per TimeBucketKey sent in each
+// chunk of a query stream when the request does not set a page size
+const DefaultStreamPageSize = 100000
+
+// QueryStreamMessage is one chunk of a query stream. A stream is a sequence of
+// msgpack encoded messages; a message with a non-empty Error terminates it.
+type QueryStreamMessage struct {
+	// Index of the request within the MultiQueryRequest
+	Request int                   `msgpack:"request"`
+	Result  *io.NumpyMultiDataset `msgpack:"result,omitempty"`
+	Error   string                `msgpack:"error,omitempty"`
+}
+
+// QueryStreamHandler serves a msgpack encoded MultiQueryRequest as a chunked
+// HTTP stream of QueryStreamMessages, reading the data page by page so that
+// neither the server nor the client needs to hold the whole result in memory.
+func QueryStreamHandler(rw http.ResponseWriter, r *http.Request) {
+	if atomic.LoadUint32(&Queryable) == 0 {
+		http.Error(rw, queryableError.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	reqs := &MultiQueryRequest{}
+	if err := msgpack.NewDecoder(r.Body).Decode(reqs); err != nil {
+		http.Error(rw, fmt.Sprintf("unable to decode query request: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+	for _, req := range reqs.Requests {
+		if req.IsSQLStatement {
+			http.Error(rw, "SQL statements can not be streamed", http.StatusBadRequest)
+			return
+		}
+		if req.Format != "" && req.Format != FormatNumpy {
+			http.Error(rw, "query streams are only sent in the numpy format", http.StatusBadRequest)
+			return
+		}
+	}
+
+	rw.Header().Set("Content-Type", "application/x-msgpack")
+	rw.Header().Set("marketstore-version", utils.GitHash)
+	flusher, _ := rw.(http.Flusher)
+	enc := msgpack.NewEncoder(rw)
+
+	err := streamQuery(reqs, func(msg *QueryStreamMessage) error {
+		if err := enc.Encode(msg); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error("Failed to write query stream - Error: %v", err)
+	}
+}
+
+/*
+streamQuery reads the results of the queries page by page, passing each page
+to send. A failed query is sent as a message with an Error, ending the stream.
+The error returned is the one of send.
+*/
+func streamQuery(reqs *MultiQueryRequest, send func(*QueryStreamMessage) error) error {
+	for i, req := range reqs.Requests {
+		if req.PageSize == nil {
+			pageSize := DefaultStreamPageSize
+			req.PageSize = &pageSize
+		}
+		for {
+			resp, err := executePagedQuery(&req)
+			if err != nil {
+				return send(&QueryStreamMessage{Request: i, Error: err.Error()})
+			}
+			if resp.Result != nil {
+				if err = send(&QueryStreamMessage{Request: i, Result: resp.Result}); err != nil {
+					return err
+				}
+			}
+			if resp.Cursor == "" {
+				break
+			}
+			req.Cursor = resp.Cursor
+		}
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package client
 import (
 	"bytes"
 	"fmt"
+	stdio "io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
@@ -42,37 +43,12 @@ func (cl *Client) DoRPC(functionName string, args interface{}) (response interfa
 		return nil, fmt.Errorf("args must be non-nil - have: args: %v\n",
 			args)
 	}
-	message, err := msgpack2.EncodeClientRequest("DataService."+functionName, args)
-	if err != nil {
-		return nil, err
-	}
-	reqURL := cl.BaseURL + "/rpc"
-	req, err := http.NewRequest("POST", reqURL, bytes.NewBuffer(message))
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Set("Content-Type", "application/x-msgpack")
-	client := new(http.Client)
-	resp, err := client.Do(req)
+	resp, err := cl.post(functionName, args)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	// Handle any error in the RPC call
-	if resp.StatusCode != 200 {
-		bodyBytes, err := ioutil.ReadAll(resp.Body)
-		var errText string
-		if err != nil {
-			errText = err.Error()
-		} else {
-			if bodyBytes != nil {
-				errText = string(bodyBytes)
-			}
-		}
-		return nil, fmt.Errorf("response error (%d): %s", resp.StatusCode, errText)
-	}
-
 	// Unpack and format the response from the RPC call
 	switch functionName {
 	case "GetInfo":
@@ -83,7 +59,7 @@ func (cl *Client) DoRPC(functionName string, args interface{}) (response interfa
 		}
 		return result, nil
 
-	case "Create", "Destroy":
+	case "Create", "Destroy", "AlterBucket":
 		result := &frontend.MultiServerResponse{}
 		err = msgpack2.DecodeClientResponse(resp.Body, result)
 		if err != nil {
@@ -91,6 +67,30 @@ func (cl *Client) DoRPC(functionName string, args interface{}) (response interfa
 		}
 		return result, nil
 
+	case "Compact":
+		result := &frontend.MultiCompactResponse{}
+		err = msgpack2.DecodeClientResponse(resp.Body, result)
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
+
+	case "Delete":
+		result := &frontend.MultiDeleteResponse{}
+		err = msgpack2.DecodeClientResponse(resp.Body, result)
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
+
+	case "Backup":
+		result := &frontend.BackupResponse{}
+		err = msgpack2.DecodeClientResponse(resp.Body, result)
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
+
 	case "Query", "SQLStatement":
 		result := &frontend.MultiQueryResponse{}
 		err = msgpack2.DecodeClientResponse(resp.Body, result)
@@ -114,6 +114,101 @@ func (cl *Client) DoRPC(functionName string, args interface{}) (response interfa
 	return nil, nil
 }
 
+// Call makes an RPC request to MarketStore's API, decoding the response into
+// reply, which is one of the response types of the frontend
+func (cl *Client) Call(functionName string, args, reply interface{}) error {
+	if args == nil {
+		return fmt.Errorf("args must be non-nil - have: args: %v\n",
+			args)
+	}
+	resp, err := cl.post(functionName, args)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return msgpack2.DecodeClientResponse(resp.Body, reply)
+}
+
+func (cl *Client) post(functionName string, args interface{}) (resp *http.Response, err error) {
+	message, err := msgpack2.EncodeClientRequest("DataService."+functionName, args)
+	if err != nil {
+		return nil, err
+	}
+	reqURL := cl.BaseURL + "/rpc"
+	req, err := http.NewRequest("POST", reqURL, bytes.NewBuffer(message))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-msgpack")
+	client := new(http.Client)
+	resp, err = client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Handle any error in the RPC call
+	if resp.StatusCode != 200 {
+		defer resp.Body.Close()
+		bodyBytes, err := ioutil.ReadAll(resp.Body)
+		var errText string
+		if err != nil {
+			errText = err.Error()
+		} else {
+			if bodyBytes != nil {
+				errText = string(bodyBytes)
+			}
+		}
+		return nil, fmt.Errorf("response error (%d): %s", resp.StatusCode, errText)
+	}
+	return resp, nil
+}
+
+// QueryStream runs the queries through the chunked query stream, calling the
+// handler with each page of results as it arrives. The index of the request
+// the page belongs to is passed along with the page.
+func (cl *Client) QueryStream(reqs *frontend.MultiQueryRequest,
+	handler func(request int, csm io.ColumnSeriesMap) error) (err error) {
+
+	if reqs == nil {
+		return fmt.Errorf("requests must be non-nil")
+	}
+	message, err := msgpack.Marshal(reqs)
+	if err != nil {
+		return err
+	}
+	resp, err := http.Post(cl.BaseURL+"/query/stream", "application/x-msgpack", bytes.NewBuffer(message))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		bodyBytes, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("response error (%d): %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	dec := msgpack.NewDecoder(resp.Body)
+	for {
+		msg := frontend.QueryStreamMessage{}
+		if err = dec.Decode(&msg); err != nil {
+			if err == stdio.EOF {
+				return nil
+			}
+			return err
+		}
+		if msg.Error != "" {
+			return fmt.Errorf("query stream error: %s", msg.Error)
+		}
+		csm, err := msg.Result.ToColumnSeriesMap()
+		if err != nil {
+			return err
+		}
+		if err = handler(msg.Request, csm); err != nil {
+			return err
+		}
+	}
+}
+
 func ColumnSeriesFromResult(shapes []io.DataShape, columns map[string]interface{}) (cs *io.ColumnSeries, err error) {
 	cs = io.NewColumnSeries()
 	for _, shape := range shapes {
@@ -0,0 +1,373 @@
+package frontend
+
+import (
+	"context"
+	"errors"
+	stdio "io"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/alpacahq/marketstore/frontend/stream"
+	"github.com/alpacahq/marketstore/proto"
+	"github.com/alpacahq/marketstore/utils/io"
+	"github.com/alpacahq/marketstore/utils/log"
+	"github.com/vmihailenco/msgpack"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GRPCMaxMessageSize bounds the size of the gRPC messages, which hold whole
+// query results
+const GRPCMaxMessageSize = 1 << 30
+
+// Service is the API served over gRPC, implemented by the DataService of a
+// node as well as by the one of a cluster coordinator
+type Service interface {
+	Query(r *http.Request, reqs *MultiQueryRequest, response *MultiQueryResponse) error
+	Write(r *http.Request, reqs *MultiWriteRequest, response *MultiServerResponse) error
+	Create(r *http.Request, reqs *MultiCreateRequest, response *MultiServerResponse) error
+	Destroy(r *http.Request, reqs *MultiKeyRequest, response *MultiServerResponse) error
+	GetInfo(r *http.Request, reqs *MultiKeyRequest, response *MultiGetInfoResponse) error
+	ListSymbols(r *http.Request, args *ListSymbolsArgs, response *ListSymbolsResponse) error
+}
+
+/*
+GRPCService serves a Service over gRPC, as defined in proto/marketstore.proto.
+The messages are converted to the requests and responses of the Service, so
+that both APIs behave the same.
+*/
+type GRPCService struct {
+	service Service
+}
+
+func NewGRPCService(service Service) *GRPCService {
+	return &GRPCService{service: service}
+}
+
+// NewGRPCServer returns a gRPC server of the service
+func NewGRPCServer(service Service) *grpc.Server {
+	s := grpc.NewServer(
+		grpc.MaxRecvMsgSize(GRPCMaxMessageSize),
+		grpc.MaxSendMsgSize(GRPCMaxMessageSize),
+		grpc.UnaryInterceptor(unaryInterceptor),
+		grpc.StreamInterceptor(streamInterceptor),
+	)
+	proto.RegisterMarketstoreServer(s, NewGRPCService(service))
+	return s
+}
+
+/*
+unaryInterceptor rejects the calls made before the server is queryable, and
+turns the panics of the handlers into errors, as gRPC does not recover them
+*/
+func unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (resp interface{}, err error) {
+
+	if atomic.LoadUint32(&Queryable) == 0 {
+		return nil, status.Error(codes.Unavailable, queryableError.Error())
+	}
+	defer recoverGRPC(info.FullMethod, &err)
+	return handler(ctx, req)
+}
+
+// streamInterceptor turns the panics of the stream handlers into errors
+func streamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler) (err error) {
+
+	defer recoverGRPC(info.FullMethod, &err)
+	return handler(srv, ss)
+}
+
+func recoverGRPC(method string, err *error) {
+	if r := recover(); r != nil {
+		log.Error("panic in grpc call %s: %v\n%s", method, r, debug.Stack())
+		*err = status.Errorf(codes.Internal, "%s failed: %v", method, r)
+	}
+}
+
+func (s *GRPCService) Query(ctx context.Context, req *proto.MultiQueryRequest) (*proto.MultiQueryResponse, error) {
+	response := &MultiQueryResponse{}
+	if err := s.service.Query(nil, queryRequestsFromProto(req), response); err != nil {
+		return nil, err
+	}
+	resp := &proto.MultiQueryResponse{
+		Version:  response.Version,
+		Timezone: response.Timezone,
+	}
+	for _, qr := range response.Responses {
+		resp.Responses = append(resp.Responses, &proto.QueryResponse{
+			Result: DatasetToProto(qr.Result),
+			Cursor: qr.Cursor,
+			Arrow:  qr.Arrow,
+		})
+	}
+	return resp, nil
+}
+
+// QueryStream sends the results page by page, a failed query ending the
+// stream with its error. Only the nodes stream queries.
+func (s *GRPCService) QueryStream(req *proto.MultiQueryRequest, srv proto.Marketstore_QueryStreamServer) error {
+	if _, ok := s.service.(*DataService); !ok {
+		return status.Error(codes.Unimplemented, "query streams are only served by the nodes")
+	}
+	if atomic.LoadUint32(&Queryable) == 0 {
+		return status.Error(codes.Unavailable, queryableError.Error())
+	}
+	reqs := queryRequestsFromProto(req)
+	for _, r := range reqs.Requests {
+		if r.IsSQLStatement {
+			return status.Error(codes.InvalidArgument, "SQL statements can not be streamed")
+		}
+		if r.Format != "" && r.Format != FormatNumpy {
+			return status.Error(codes.InvalidArgument, "query streams are only sent in the numpy format")
+		}
+	}
+	return streamQuery(reqs, func(msg *QueryStreamMessage) error {
+		if msg.Error != "" {
+			return errors.New(msg.Error)
+		}
+		return srv.Send(&proto.QueryStreamResponse{
+			Request: int32(msg.Request),
+			Result:  DatasetToProto(msg.Result),
+		})
+	})
+}
+
+func (s *GRPCService) Write(ctx context.Context, req *proto.MultiWriteRequest) (*proto.MultiServerResponse, error) {
+	reqs := &MultiWriteRequest{Sync: req.Sync}
+	for i, wr := range req.Requests {
+		data := DatasetFromProto(wr.Data)
+		if data == nil {
+			return nil, status.Errorf(codes.InvalidArgument, "write request %d has no data", i)
+		}
+		reqs.Requests = append(reqs.Requests, WriteRequest{
+			Data:             data,
+			IsVariableLength: wr.IsVariableLength,
+		})
+	}
+	response := &MultiServerResponse{}
+	if err := s.service.Write(nil, reqs, response); err != nil {
+		return nil, err
+	}
+	return serverResponsesToProto(response), nil
+}
+
+func (s *GRPCService) Create(ctx context.Context, req *proto.MultiCreateRequest) (*proto.MultiServerResponse, error) {
+	reqs := &MultiCreateRequest{}
+	for _, cr := range req.Requests {
+		reqs.Requests = append(reqs.Requests, CreateRequest{
+			Key:             cr.Key,
+			DataShapes:      cr.DataShapes,
+			RowType:         cr.RowType,
+			Compression:     cr.Compression,
+			WritePolicy:     cr.WritePolicy,
+			TimestampFormat: cr.TimestampFormat,
+		})
+	}
+	response := &MultiServerResponse{}
+	if err := s.service.Create(nil, reqs, response); err != nil {
+		return nil, err
+	}
+	return serverResponsesToProto(response), nil
+}
+
+func (s *GRPCService) Destroy(ctx context.Context, req *proto.MultiKeyRequest) (*proto.MultiServerResponse, error) {
+	response := &MultiServerResponse{}
+	if err := s.service.Destroy(nil, keyRequestsFromProto(req), response); err != nil {
+		return nil, err
+	}
+	return serverResponsesToProto(response), nil
+}
+
+func (s *GRPCService) GetInfo(ctx context.Context, req *proto.MultiKeyRequest) (*proto.MultiGetInfoResponse, error) {
+	response := &MultiGetInfoResponse{}
+	if err := s.service.GetInfo(nil, keyRequestsFromProto(req), response); err != nil {
+		return nil, err
+	}
+	resp := &proto.MultiGetInfoResponse{}
+	for _, info := range response.Responses {
+		var shapes []*proto.DataShape
+		for _, shape := range info.DSV {
+			shapes = append(shapes, &proto.DataShape{
+				Name: shape.Name,
+				Type: strings.ToLower(shape.Type.String()),
+			})
+		}
+		resp.Responses = append(resp.Responses, &proto.GetInfoResponse{
+			LatestYear:      int32(info.LatestYear),
+			Timeframe:       int64(info.TimeFrame),
+			DataShapes:      shapes,
+			RecordType:      strings.ToLower(info.RecordType.String()),
+			Compression:     info.Compression.String(),
+			UsedBytes:       info.UsedBytes,
+			SchemaVersion:   info.SchemaVersion,
+			WritePolicy:     info.WritePolicy.String(),
+			TimestampFormat: info.TimestampFormat.String(),
+			ServerResponse:  serverResponseToProto(info.ServerResp),
+		})
+	}
+	return resp, nil
+}
+
+func (s *GRPCService) ListSymbols(ctx context.Context, req *proto.ListSymbolsRequest) (*proto.ListSymbolsResponse, error) {
+	response := &ListSymbolsResponse{}
+	if err := s.service.ListSymbols(nil, &ListSymbolsArgs{}, response); err != nil {
+		return nil, err
+	}
+	return &proto.ListSymbolsResponse{Results: response.Results}, nil
+}
+
+/*
+Stream subscribes to the streams of each request, as the subscribe messages
+of the /ws websocket do. Each request is answered by the streams subscribed
+to, or by its error, and is followed by the payloads pushed to the streams.
+*/
+func (s *GRPCService) Stream(srv proto.Marketstore_StreamServer) error {
+	// The payloads are sent concurrently with the answers to the requests
+	var mu sync.Mutex
+	send := func(resp *proto.StreamResponse) error {
+		mu.Lock()
+		defer mu.Unlock()
+		return srv.Send(resp)
+	}
+	sub := stream.NewSubscriber(func(pl stream.Payload) error {
+		data, err := msgpack.Marshal(pl.Data)
+		if err != nil {
+			return err
+		}
+		return send(&proto.StreamResponse{Key: pl.Key, Data: data})
+	})
+	defer sub.Close()
+
+	for {
+		req, err := srv.Recv()
+		if err == stdio.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		resp := &proto.StreamResponse{Streams: req.Streams}
+		if err = sub.Subscribe(req.Streams); err != nil {
+			resp = &proto.StreamResponse{Error: err.Error()}
+		}
+		if err = send(resp); err != nil {
+			return err
+		}
+	}
+}
+
+/*
+Utility functions
+*/
+
+func queryRequestsFromProto(req *proto.MultiQueryRequest) *MultiQueryRequest {
+	reqs := &MultiQueryRequest{}
+	for _, qr := range req.Requests {
+		r := QueryRequest{
+			IsSQLStatement: qr.IsSqlstatement,
+			SQLStatement:   qr.SqlStatement,
+			Destination:    qr.Destination,
+			KeyCategory:    qr.KeyCategory,
+			Columns:        qr.Columns,
+			Functions:      qr.Functions,
+			Cursor:         qr.Cursor,
+			Format:         qr.Format,
+		}
+		// The zero values leave the fields unset
+		if qr.EpochStart != 0 {
+			epochStart := qr.EpochStart
+			r.EpochStart = &epochStart
+		}
+		if qr.EpochEnd != 0 {
+			epochEnd := qr.EpochEnd
+			r.EpochEnd = &epochEnd
+		}
+		if qr.LimitRecordCount != 0 {
+			limitRecordCount := int(qr.LimitRecordCount)
+			r.LimitRecordCount = &limitRecordCount
+		}
+		if qr.LimitFromStart {
+			limitFromStart := true
+			r.LimitFromStart = &limitFromStart
+		}
+		if qr.PageSize != 0 {
+			pageSize := int(qr.PageSize)
+			r.PageSize = &pageSize
+		}
+		reqs.Requests = append(reqs.Requests, r)
+	}
+	return reqs
+}
+
+func keyRequestsFromProto(req *proto.MultiKeyRequest) *MultiKeyRequest {
+	reqs := &MultiKeyRequest{}
+	for _, kr := range req.Requests {
+		reqs.Requests = append(reqs.Requests, KeyRequest{Key: kr.Key})
+	}
+	return reqs
+}
+
+func serverResponseToProto(resp ServerResponse) *proto.ServerResponse {
+	return &proto.ServerResponse{Error: resp.Error, Version: resp.Version}
+}
+
+func serverResponsesToProto(response *MultiServerResponse) *proto.MultiServerResponse {
+	resp := &proto.MultiServerResponse{}
+	for _, sr := range response.Responses {
+		resp.Responses = append(resp.Responses, serverResponseToProto(sr))
+	}
+	return resp
+}
+
+// DatasetFromProto converts a dataset of the gRPC API
+func DatasetFromProto(ds *proto.NumpyMultiDataSet) *io.NumpyMultiDataset {
+	if ds == nil || ds.Data == nil {
+		return nil
+	}
+	nmds := &io.NumpyMultiDataset{
+		NumpyDataset: io.NumpyDataset{
+			ColumnTypes: ds.Data.ColumnTypes,
+			ColumnNames: ds.Data.ColumnNames,
+			ColumnData:  ds.Data.ColumnData,
+			Length:      int(ds.Data.Length),
+		},
+		StartIndex: make(map[string]int),
+		Lengths:    make(map[string]int),
+	}
+	for key, index := range ds.StartIndex {
+		nmds.StartIndex[key] = int(index)
+	}
+	for key, length := range ds.Lengths {
+		nmds.Lengths[key] = int(length)
+	}
+	return nmds
+}
+
+// DatasetToProto converts a dataset for the gRPC API
+func DatasetToProto(nmds *io.NumpyMultiDataset) *proto.NumpyMultiDataSet {
+	if nmds == nil {
+		return nil
+	}
+	ds := &proto.NumpyMultiDataSet{
+		Data: &proto.NumpyDataSet{
+			ColumnTypes: nmds.ColumnTypes,
+			ColumnNames: nmds.ColumnNames,
+			ColumnData:  nmds.ColumnData,
+			Length:      int32(nmds.Length),
+		},
+		StartIndex: make(map[string]int32),
+		Lengths:    make(map[string]int32),
+	}
+	for key, index := range nmds.StartIndex {
+		ds.StartIndex[key] = int32(index)
+	}
+	for key, length := range nmds.Lengths {
+		ds.Lengths[key] = int32(length)
+	}
+	return ds
+}
@@ -0,0 +1,153 @@
+package frontend
+
+import (
+	"context"
+	stdio "io"
+	"net"
+	"sync/atomic"
+
+	"github.com/vmihailenco/msgpack"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	. "gopkg.in/check.v1"
+
+	"github.com/alpacahq/marketstore/frontend/stream"
+	"github.com/alpacahq/marketstore/proto"
+	"github.com/alpacahq/marketstore/utils/io"
+)
+
+func (s *ServerTestSuite) TestGRPC(c *C) {
+	stream.Initialize()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, IsNil)
+	server := NewGRPCServer(&DataService{})
+	go server.Serve(listener)
+	defer server.Stop()
+
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithInsecure())
+	c.Assert(err, IsNil)
+	defer conn.Close()
+	cl := proto.NewMarketstoreClient(conn)
+	ctx := context.Background()
+
+	symbols, err := cl.ListSymbols(ctx, &proto.ListSymbolsRequest{})
+	c.Assert(err, IsNil)
+	c.Assert(symbols.Results, Not(HasLen), 0)
+
+	// Query
+	queried, err := cl.Query(ctx, &proto.MultiQueryRequest{
+		Requests: []*proto.QueryRequest{{Destination: "EURUSD/1Min/OHLC", LimitRecordCount: 10}},
+	})
+	c.Assert(err, IsNil)
+	c.Assert(queried.Responses, HasLen, 1)
+	csm, err := DatasetFromProto(queried.Responses[0].Result).ToColumnSeriesMap()
+	c.Assert(err, IsNil)
+	cs := csm[*io.NewTimeBucketKey("EURUSD/1Min/OHLC")]
+	c.Assert(cs, NotNil)
+	c.Assert(cs.Len(), Equals, 10)
+
+	// QueryStream
+	qs, err := cl.QueryStream(ctx, &proto.MultiQueryRequest{
+		Requests: []*proto.QueryRequest{{
+			Destination: "EURUSD/1Min/OHLC",
+			EpochStart:  cs.GetEpoch()[0],
+			EpochEnd:    cs.GetEpoch()[9],
+			PageSize:    4,
+		}},
+	})
+	c.Assert(err, IsNil)
+	var epochs []int64
+	for {
+		page, err := qs.Recv()
+		if err == stdio.EOF {
+			break
+		}
+		c.Assert(err, IsNil)
+		pcsm, err := DatasetFromProto(page.Result).ToColumnSeriesMap()
+		c.Assert(err, IsNil)
+		for _, pcs := range pcsm {
+			epochs = append(epochs, pcs.GetEpoch()...)
+		}
+	}
+	c.Assert(epochs, DeepEquals, cs.GetEpoch())
+
+	// Create, Write, GetInfo and Destroy
+	key := "GRPC/1Min/OHLC:Symbol/Timeframe/AttributeGroup"
+	created, err := cl.Create(ctx, &proto.MultiCreateRequest{
+		Requests: []*proto.CreateRequest{{Key: key, DataShapes: "Open,High,Low,Close/float32", RowType: "fixed"}},
+	})
+	c.Assert(err, IsNil)
+	c.Assert(created.Responses, HasLen, 1)
+	c.Assert(created.Responses[0].Error, Equals, "")
+
+	tbk := io.NewTimeBucketKey("GRPC/1Min/OHLC")
+	wcs := io.NewColumnSeries()
+	wcs.AddColumn("Epoch", cs.GetEpoch())
+	for _, name := range []string{"Open", "High", "Low", "Close"} {
+		wcs.AddColumn(name, cs.GetByName(name))
+	}
+	nds, err := io.NewNumpyDataset(wcs)
+	c.Assert(err, IsNil)
+	nmds, err := io.NewNumpyMultiDataset(nds, *tbk)
+	c.Assert(err, IsNil)
+	written, err := cl.Write(ctx, &proto.MultiWriteRequest{
+		Requests: []*proto.WriteRequest{{Data: DatasetToProto(nmds)}},
+		Sync:     true,
+	})
+	c.Assert(err, IsNil)
+	c.Assert(written.Responses, HasLen, 0)
+
+	// A write without data is rejected, and a panic is returned as an error
+	_, err = cl.Write(ctx, &proto.MultiWriteRequest{Requests: []*proto.WriteRequest{{}}})
+	c.Assert(status.Code(err), Equals, codes.InvalidArgument)
+	_, err = unaryInterceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/test"},
+		func(ctx context.Context, req interface{}) (interface{}, error) { panic("test") })
+	c.Assert(status.Code(err), Equals, codes.Internal)
+
+	// Calls are rejected until the server is queryable
+	atomic.StoreUint32(&Queryable, 0)
+	_, err = cl.ListSymbols(ctx, &proto.ListSymbolsRequest{})
+	atomic.StoreUint32(&Queryable, 1)
+	c.Assert(status.Code(err), Equals, codes.Unavailable)
+
+	info, err := cl.GetInfo(ctx, &proto.MultiKeyRequest{Requests: []*proto.KeyRequest{{Key: key}}})
+	c.Assert(err, IsNil)
+	c.Assert(info.Responses, HasLen, 1)
+	c.Assert(info.Responses[0].RecordType, Equals, "fixed")
+	c.Assert(info.Responses[0].DataShapes[0], DeepEquals, &proto.DataShape{Name: "Epoch", Type: "int64"})
+
+	queried, err = cl.Query(ctx, &proto.MultiQueryRequest{
+		Requests: []*proto.QueryRequest{{Destination: "GRPC/1Min/OHLC"}},
+	})
+	c.Assert(err, IsNil)
+	csm, err = DatasetFromProto(queried.Responses[0].Result).ToColumnSeriesMap()
+	c.Assert(err, IsNil)
+	c.Assert(csm[*tbk].GetByName("Close"), DeepEquals, cs.GetByName("Close"))
+
+	destroyed, err := cl.Destroy(ctx, &proto.MultiKeyRequest{Requests: []*proto.KeyRequest{{Key: key}}})
+	c.Assert(err, IsNil)
+	c.Assert(destroyed.Responses[0].Error, Equals, "")
+
+	// Stream
+	st, err := cl.Stream(ctx)
+	c.Assert(err, IsNil)
+	c.Assert(st.Send(&proto.StreamRequest{Streams: []string{"invalid"}}), IsNil)
+	resp, err := st.Recv()
+	c.Assert(err, IsNil)
+	c.Assert(resp.Error, Not(Equals), "")
+	c.Assert(st.Send(&proto.StreamRequest{Streams: []string{"*/1Min/OHLC"}}), IsNil)
+	resp, err = st.Recv()
+	c.Assert(err, IsNil)
+	c.Assert(resp.Streams, DeepEquals, []string{"*/1Min/OHLC"})
+
+	stream.Push(*io.NewTimeBucketKey("USDJPY/1D/OHLC"), map[string]float64{"Close": 1})
+	stream.Push(*tbk, map[string]float64{"Close": 2})
+	resp, err = st.Recv()
+	c.Assert(err, IsNil)
+	c.Assert(resp.Key, Equals, "GRPC/1Min/OHLC")
+	var data map[string]float64
+	c.Assert(msgpack.Unmarshal(resp.Data, &data), IsNil)
+	c.Assert(data["Close"], Equals, 2.0)
+	c.Assert(st.CloseSend(), IsNil)
+}
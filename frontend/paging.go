@@ -0,0 +1,193 @@
+package frontend
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/alpacahq/marketstore/executor"
+	"github.com/alpacahq/marketstore/utils/io"
+	"github.com/vmihailenco/msgpack"
+)
+
+/*
+	Paged queries
+
+	A paged query returns at most PageSize rows per TimeBucketKey in each response,
+	along with an opaque cursor.  The client resends the same request with the
+	cursor set to obtain the next page, until the returned cursor is empty.
+
+	The cursor records, for each TimeBucketKey not yet exhausted, the position of
+	the next row as stored: the year file, the interval index within it and how
+	many rows of that interval have already been returned.  The next page is read
+	from the start of that interval and the already returned rows are skipped,
+	which keeps paging exact for variable length buckets that store several
+	records within the same interval.
+*/
+
+// cursorPosition is the resume point of a single TimeBucketKey, a zero Year
+// resumes from the start of the query range
+type cursorPosition struct {
+	Year   int16 `msgpack:"year"`
+	Index  int64 `msgpack:"index"`
+	Offset int   `msgpack:"offset"`
+}
+
+type queryCursor struct {
+	Positions map[string]cursorPosition `msgpack:"positions"`
+}
+
+func (qc *queryCursor) encode() (string, error) {
+	if len(qc.Positions) == 0 {
+		return "", nil
+	}
+	buf, err := msgpack.Marshal(qc)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func decodeCursor(token string) (*queryCursor, error) {
+	buf, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid query cursor: %s", err.Error())
+	}
+	qc := &queryCursor{}
+	if err = msgpack.Unmarshal(buf, qc); err != nil {
+		return nil, fmt.Errorf("invalid query cursor: %s", err.Error())
+	}
+	return qc, nil
+}
+
+// executePagedQuery reads one page of a native query, bounded to PageSize rows
+// for each TimeBucketKey in the destination
+func executePagedQuery(req *QueryRequest) (*QueryResponse, error) {
+	pageSize := *req.PageSize
+	if pageSize <= 0 {
+		return nil, fmt.Errorf("page_size must be positive, have: %d", pageSize)
+	}
+	if req.LimitRecordCount != nil || len(req.Functions) != 0 {
+		return nil, fmt.Errorf("page_size can not be combined with limit_record_count or functions")
+	}
+
+	epochStart, epochEnd := req.epochRange()
+
+	var positions map[string]cursorPosition
+	if req.Cursor != "" {
+		qc, err := decodeCursor(req.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		positions = qc.Positions
+	} else {
+		dest, err := resolveDestination(req)
+		if err != nil {
+			return nil, err
+		}
+		positions = make(map[string]cursorPosition)
+		for _, symbol := range dest.GetMultiItemInCategory("Symbol") {
+			tbk := io.NewTimeBucketKey(dest.GetItemKey(), dest.GetCatKey())
+			tbk.SetItemInCategory("Symbol", symbol)
+			positions[tbk.String()] = cursorPosition{}
+		}
+	}
+
+	// Sort the keys so that pages are laid out in a stable order
+	keys := make([]string, 0, len(positions))
+	for key := range positions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	columns := make([]string, 0)
+	if req.Columns != nil {
+		columns = req.Columns
+	}
+
+	stop := io.ToSystemTimezone(time.Unix(epochEnd, 0))
+	next := &queryCursor{Positions: make(map[string]cursorPosition)}
+	var nmds *io.NumpyMultiDataset
+	for _, key := range keys {
+		pos := positions[key]
+		tbk := io.NewTimeBucketKeyFromString(key)
+		tbi, err := executor.ThisInstance.CatalogDir.GetLatestTimeBucketInfoFromKey(tbk)
+		if err != nil {
+			continue
+		}
+		tf := tbi.GetTimeframe()
+		start := io.ToSystemTimezone(time.Unix(epochStart, 0))
+		if pos.Year != 0 {
+			if resume := io.IndexToTime(pos.Index, tf, pos.Year); resume.After(start) {
+				start = resume
+			}
+		}
+		// One more row than the page tells whether the key is exhausted
+		csm, err := executeQuery(tbk, start, stop, pos.Offset+pageSize+1, true, columns)
+		if err != nil {
+			if err.Error() == "No files returned from query parse" {
+				continue
+			}
+			return nil, err
+		}
+		for resultKey, cs := range csm {
+			// Drop the rows of the resumed interval returned by the previous pages
+			if pos.Offset > 0 {
+				if pos.Offset >= cs.Len() {
+					cs = io.NewColumnSeries()
+				} else if err = cs.RestrictLength(cs.Len()-pos.Offset, io.LAST); err != nil {
+					return nil, err
+				}
+			}
+			if cs.Len() == 0 {
+				continue
+			}
+			if cs.Len() > pageSize {
+				next.Positions[key] = nextPosition(pos, cs.GetEpoch(), pageSize, tf)
+				if err = cs.RestrictLength(pageSize, io.FIRST); err != nil {
+					return nil, err
+				}
+			}
+			if nmds == nil {
+				nds, err := io.NewNumpyDataset(cs)
+				if err != nil {
+					return nil, err
+				}
+				if nmds, err = io.NewNumpyMultiDataset(nds, resultKey); err != nil {
+					return nil, err
+				}
+			} else if err = nmds.Append(cs, resultKey); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	cursor, err := next.encode()
+	if err != nil {
+		return nil, err
+	}
+	return &QueryResponse{Result: nmds, Cursor: cursor}, nil
+}
+
+// nextPosition computes the position of the row following a page, given the
+// epochs of the page and of at least that row
+func nextPosition(pos cursorPosition, epochs []int64, pageSize int, tf time.Duration) cursorPosition {
+	position := func(epoch int64) (year int16, index int64) {
+		t := io.ToSystemTimezone(time.Unix(epoch, 0))
+		return int16(t.Year()), io.TimeToIndex(t, tf)
+	}
+	year, index := position(epochs[pageSize])
+	next := cursorPosition{Year: year, Index: index}
+	for i := pageSize - 1; i >= 0; i-- {
+		if y, idx := position(epochs[i]); y != year || idx != index {
+			return next
+		}
+		next.Offset++
+	}
+	// The whole page is in the interval we resumed from, keep skipping past the earlier pages
+	if pos.Year == year && pos.Index == index {
+		next.Offset += pos.Offset
+	}
+	return next
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math"
 	"net/http"
+	"regexp"
 	"strings"
 	"sync/atomic"
 	"time"
@@ -37,8 +38,20 @@ type QueryRequest struct {
 	// Array of column names to be returned
 	Columns []string `msgpack:"columns,omitempty"`
 
-	// Support for functions is experimental and subject to change
+	// Support for functions is experimental and subject to change. Window
+	// functions add a column and use the SQL syntax with an OVER clause, as in
+	// "avg(Close) OVER (ROWS 19 PRECEDING) AS MA20"
 	Functions []string `msgpack:"functions,omitempty"`
+
+	// Maximum number of rows per TimeBucketKey returned in one response. When set,
+	// the query is paged and QueryResponse.Cursor is used to fetch the next page
+	PageSize *int `msgpack:"page_size,omitempty"`
+	// Continuation token from a previous paged QueryResponse, resumes the query
+	Cursor string `msgpack:"cursor,omitempty"`
+
+	// Output format of the result, either numpy (the default), arrow for the
+	// Arrow IPC streaming format or arrow-file for the Arrow IPC file format
+	Format string `msgpack:"format,omitempty"`
 }
 
 type MultiQueryRequest struct {
@@ -50,6 +63,10 @@ type MultiQueryRequest struct {
 
 type QueryResponse struct {
 	Result *io.NumpyMultiDataset `msgpack:"result"`
+	// Continuation token for paged queries, empty once all pages have been returned
+	Cursor string `msgpack:"cursor,omitempty"`
+	// Result encoded in the Arrow format requested, which leaves Result unset
+	Arrow []byte `msgpack:"arrow,omitempty"`
 }
 
 type MultiQueryResponse struct {
@@ -70,6 +87,9 @@ func (resp *MultiQueryResponse) ToColumnSeriesMap() (*io.ColumnSeriesMap, error)
 
 	for _, ds := range resp.Responses { // Datasets are packed in a slice, each has a NumpyMultiDataset inside
 		nmds := ds.Result
+		if nmds == nil {
+			continue
+		}
 		for tbkStr, startIndex := range nmds.StartIndex {
 			cs, err := nmds.ToColumnSeries(startIndex, nmds.Lengths[tbkStr])
 			if err != nil {
@@ -87,8 +107,15 @@ func (s *DataService) Query(r *http.Request, reqs *MultiQueryRequest, response *
 	response.Version = utils.GitHash
 	response.Timezone = utils.InstanceConfig.Timezone.String()
 	for _, req := range reqs.Requests {
+		if err = CheckFormat(req.Format); err != nil {
+			return err
+		}
+		var resp QueryResponse
 		switch req.IsSQLStatement {
 		case true:
+			if req.PageSize != nil {
+				return fmt.Errorf("paged queries are not supported for SQL statements")
+			}
 			ast, err := sqlparser.NewAstBuilder(req.SQLStatement)
 			if err != nil {
 				return err
@@ -101,61 +128,28 @@ func (s *DataService) Query(r *http.Request, reqs *MultiQueryRequest, response *
 			if err != nil {
 				return err
 			}
-			nds, err := io.NewNumpyDataset(cs)
-			if err != nil {
-				return err
-			}
-			tbk := io.NewTimeBucketKeyFromString(req.SQLStatement + ":SQL")
-			nmds, err := io.NewNumpyMultiDataset(nds, *tbk)
+			nmds, err := sqlResultDataset(req.SQLStatement, cs)
 			if err != nil {
 				return err
 			}
-			response.Responses = append(response.Responses,
-				QueryResponse{
-					nmds,
-				})
+			resp.Result = nmds
 
 		case false:
-			/*
-				Assumption: Within each TimeBucketKey, we have one or more of each category, with the exception of
-				the AttributeGroup (aka Record Format) and Timeframe
-				Within each TimeBucketKey in the request, we allow for a comma separated list of items, e.g.:
-					destination1.items := "TSLA,AAPL,CG/1Min/OHLCV"
-				Constraints:
-				- If there is more than one record format in a single destination, we return an error
-				- If there is more than one Timeframe in a single destination, we return an error
-			*/
-			dest := io.NewTimeBucketKey(req.Destination, req.KeyCategory)
-			/*
-				All destinations in a request must share the same record format (AttributeGroup) and Timeframe
-			*/
-			RecordFormat := dest.GetItemInCategory("AttributeGroup")
-			Timeframe := dest.GetItemInCategory("Timeframe")
-			Symbols := dest.GetMultiItemInCategory("Symbol")
-
-			if len(Timeframe) == 0 || len(RecordFormat) == 0 || len(Symbols) == 0 {
-				return fmt.Errorf("destinations must have a Symbol, Timeframe and AttributeGroup, have: %s",
-					dest.String())
-			} else if len(Symbols) == 1 && Symbols[0] == "*" {
-				// replace the * "symbol" with a list all known actual symbols
-				allSymbols := executor.ThisInstance.CatalogDir.GatherCategoriesAndItems()["Symbol"]
-				symbols := make([]string, 0, len(allSymbols))
-				for symbol := range allSymbols {
-					symbols = append(symbols, symbol)
+			if req.PageSize != nil {
+				paged, err := executePagedQuery(&req)
+				if err != nil {
+					return err
 				}
-				keyParts := []string{strings.Join(symbols, ","), Timeframe, RecordFormat}
-				itemKey := strings.Join(keyParts, "/")
-				dest = io.NewTimeBucketKey(itemKey, req.KeyCategory)
+				resp = *paged
+				break
 			}
 
-			epochStart := int64(0)
-			epochEnd := int64(math.MaxInt64)
-			if req.EpochStart != nil {
-				epochStart = *req.EpochStart
-			}
-			if req.EpochEnd != nil {
-				epochEnd = *req.EpochEnd
+			dest, err := resolveDestination(&req)
+			if err != nil {
+				return err
 			}
+
+			epochStart, epochEnd := req.epochRange()
 			limitRecordCount := 0
 			if req.LimitRecordCount != nil {
 				limitRecordCount = *req.LimitRecordCount
@@ -213,16 +207,16 @@ func (s *DataService) Query(r *http.Request, reqs *MultiQueryRequest, response *
 				}
 			}
 
-			/*
-				Append the NumpyMultiDataset to the MultiResponse
-			*/
-
-			response.Responses = append(response.Responses,
-				QueryResponse{
-					nmds,
-				})
+			resp.Result = nmds
+		}
 
+		/*
+			Append the result to the MultiResponse, in the requested format
+		*/
+		if err = resp.EncodeFormat(req.Format); err != nil {
+			return err
 		}
+		response.Responses = append(response.Responses, resp)
 	}
 	return nil
 }
@@ -247,6 +241,57 @@ func (s *DataService) ListSymbols(r *http.Request, args *ListSymbolsArgs, respon
 Utility functions
 */
 
+// resolveDestination builds the TimeBucketKey targeted by a native query,
+// expanding the "*" symbol to every symbol known to the catalog
+func resolveDestination(req *QueryRequest) (*io.TimeBucketKey, error) {
+	/*
+		Assumption: Within each TimeBucketKey, we have one or more of each category, with the exception of
+		the AttributeGroup (aka Record Format) and Timeframe
+		Within each TimeBucketKey in the request, we allow for a comma separated list of items, e.g.:
+			destination1.items := "TSLA,AAPL,CG/1Min/OHLCV"
+		Constraints:
+		- If there is more than one record format in a single destination, we return an error
+		- If there is more than one Timeframe in a single destination, we return an error
+	*/
+	dest := io.NewTimeBucketKey(req.Destination, req.KeyCategory)
+	/*
+		All destinations in a request must share the same record format (AttributeGroup) and Timeframe
+	*/
+	RecordFormat := dest.GetItemInCategory("AttributeGroup")
+	Timeframe := dest.GetItemInCategory("Timeframe")
+	Symbols := dest.GetMultiItemInCategory("Symbol")
+
+	if len(Timeframe) == 0 || len(RecordFormat) == 0 || len(Symbols) == 0 {
+		return nil, fmt.Errorf("destinations must have a Symbol, Timeframe and AttributeGroup, have: %s",
+			dest.String())
+	} else if len(Symbols) == 1 && Symbols[0] == "*" {
+		// replace the * "symbol" with a list all known actual symbols
+		allSymbols := executor.ThisInstance.CatalogDir.GatherCategoriesAndItems()["Symbol"]
+		symbols := make([]string, 0, len(allSymbols))
+		for symbol := range allSymbols {
+			symbols = append(symbols, symbol)
+		}
+		keyParts := []string{strings.Join(symbols, ","), Timeframe, RecordFormat}
+		itemKey := strings.Join(keyParts, "/")
+		dest = io.NewTimeBucketKey(itemKey, req.KeyCategory)
+	}
+
+	return dest, nil
+}
+
+// epochRange returns the requested time predicates, defaulting to an unbounded range
+func (req *QueryRequest) epochRange() (epochStart, epochEnd int64) {
+	epochStart = int64(0)
+	epochEnd = int64(math.MaxInt64)
+	if req.EpochStart != nil {
+		epochStart = *req.EpochStart
+	}
+	if req.EpochEnd != nil {
+		epochEnd = *req.EpochEnd
+	}
+	return epochStart, epochEnd
+}
+
 func executeQuery(tbk *io.TimeBucketKey, start, end time.Time, LimitRecordCount int,
 	LimitFromStart bool, columns []string) (io.ColumnSeriesMap, error) {
 
@@ -310,6 +355,12 @@ func runAggFunctions(callChain []string, csInput *io.ColumnSeries) (cs *io.Colum
 		if cs != nil {
 			csInput = cs
 		}
+		if windowCall.MatchString(call) {
+			if cs, err = runWindowFunction(call, csInput); err != nil {
+				return nil, err
+			}
+			continue
+		}
 		aggName, literalList, parameterList, err := parseFunctionCall(call)
 		if err != nil {
 			return nil, err
@@ -342,7 +393,9 @@ func runAggFunctions(callChain []string, csInput *io.ColumnSeries) (cs *io.Colum
 				len(literalList),
 			)
 		}
-		aggfunc.Init(literalList)
+		if err = aggfunc.Init(literalList); err != nil {
+			return nil, fmt.Errorf("%s: %s", aggName, err.Error())
+		}
 
 		/*
 			Execute the aggregate function
@@ -360,6 +413,29 @@ func runAggFunctions(callChain []string, csInput *io.ColumnSeries) (cs *io.Colum
 	return cs, nil
 }
 
+var windowCall = regexp.MustCompile(`(?i)\)\s*OVER\s*\(`)
+
+/*
+runWindowFunction adds the output of a window function to the input as a
+column named by the alias of the call, or by the function name
+*/
+func runWindowFunction(call string, csInput *io.ColumnSeries) (cs *io.ColumnSeries, err error) {
+	wf, alias, err := sqlparser.NewWindowFunction(call)
+	if err != nil {
+		return nil, err
+	}
+	output, err := wf.Evaluate(csInput)
+	if err != nil {
+		return nil, err
+	}
+	cs = io.NewColumnSeries()
+	for _, name := range csInput.GetColumnNames() {
+		cs.AddColumn(name, csInput.GetColumn(name))
+	}
+	cs.AddColumn(alias, output)
+	return cs, nil
+}
+
 func parseFunctionCall(call string) (funcName string, literalList, parameterList []string, err error) {
 	call = strings.Trim(call, " ")
 	left := strings.Index(call, "(")
@@ -398,3 +474,26 @@ func parseFunctionCall(call string) (funcName string, literalList, parameterList
 	}
 	return funcName, literalList, parameterList, nil
 }
+
+/*
+sqlResultDataset packs the result of a SQL statement. Results over several
+symbols carry a string Symbol column, which numpy datasets can not hold, so
+it is replaced by the index of each row's symbol in the key, such as
+"AAPL,MSFT:Symbol", keeping the rows in the order of the statement
+*/
+func sqlResultDataset(statement string, cs *io.ColumnSeries) (nmds *io.NumpyMultiDataset, err error) {
+	tbk := io.NewTimeBucketKeyFromString(statement + ":SQL")
+	symbols, indexed, ok := sqlparser.IndexSymbols(cs)
+	switch {
+	case ok && len(symbols) != 0:
+		cs = indexed
+		tbk = io.NewTimeBucketKeyFromString(strings.Join(symbols, ",") + ":Symbol")
+	case ok:
+		cs.Remove("Symbol")
+	}
+	nds, err := io.NewNumpyDataset(cs)
+	if err != nil {
+		return nil, err
+	}
+	return io.NewNumpyMultiDataset(nds, *tbk)
+}
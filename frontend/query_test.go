@@ -1,8 +1,14 @@
 package frontend
 
 import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/alpacahq/marketstore/sqlparser"
 	"github.com/alpacahq/marketstore/utils/io"
 	"github.com/alpacahq/marketstore/utils/test"
+	"github.com/vmihailenco/msgpack"
 
 	"time"
 
@@ -254,6 +260,43 @@ func (s *ServerTestSuite) TestQueryMulti(c *C) {
 	c.Assert(t, Equals, tref)
 }
 
+func (s *ServerTestSuite) TestQuerySQLMultiSymbol(c *C) {
+	service := &DataService{}
+	service.Init()
+
+	args := &MultiQueryRequest{
+		Requests: []QueryRequest{
+			{
+				IsSQLStatement: true,
+				SQLStatement:   "SELECT Epoch, Close, Symbol FROM `USDJPY,EURUSD/1Min/OHLC` ORDER BY Epoch DESC LIMIT 10;",
+			},
+		},
+	}
+
+	var response MultiQueryResponse
+	if err := service.Query(nil, args, &response); err != nil {
+		c.Fatalf("error returned: %s", err)
+	}
+
+	csm, err := response.Responses[0].Result.ToColumnSeriesMap()
+	c.Assert(err, IsNil)
+	c.Assert(len(csm), Equals, 1)
+	for tbk, cs := range csm {
+		symbols := tbk.GetMultiItemInCategory("Symbol")
+		c.Assert(len(symbols), Equals, 2)
+		c.Assert(sqlparser.ResolveSymbols(symbols, cs), IsNil)
+		c.Assert(cs.Len(), Equals, 10)
+		// The rows keep the order of the statement across symbols
+		epochs := cs.GetEpoch()
+		for i := 1; i < len(epochs); i++ {
+			c.Assert(epochs[i] <= epochs[i-1], Equals, true)
+		}
+		for _, symbol := range cs.GetColumn("Symbol").([]string) {
+			c.Assert(symbol == "USDJPY" || symbol == "EURUSD", Equals, true)
+		}
+	}
+}
+
 func (s *ServerTestSuite) TestListSymbols(c *C) {
 	service := &DataService{}
 	service.Init()
@@ -341,6 +384,51 @@ func (s *ServerTestSuite) TestFunctions(c *C) {
 	t := time.Unix(lastTime, 0).UTC()
 	tref := time.Date(2002, time.December, 31, 23, 55, 0, 0, time.UTC)
 	c.Assert(t, Equals, tref)
+
+	// Window functions add a column to the result
+	args = &MultiQueryRequest{
+		Requests: []QueryRequest{
+			(NewQueryRequestBuilder("USDJPY/1Min/OHLC").
+				LimitRecordCount(200).
+				Functions([]string{"avg(Close) OVER (ROWS 4 PRECEDING) AS MA5"}).
+				End()),
+		},
+	}
+	response = MultiQueryResponse{}
+	if err := service.Query(nil, args, &response); err != nil {
+		c.Fatalf("error returned: %s", err)
+	}
+	cs, err = response.Responses[0].Result.ToColumnSeries()
+	c.Assert(err == nil, Equals, true)
+	c.Assert(cs.Len(), Equals, 200)
+	ma5, ok := cs.GetByName("MA5").([]float64)
+	c.Assert(ok, Equals, true)
+	closes := cs.GetByName("Close").([]float32)
+	var sum float64
+	for _, value := range closes[195:] {
+		sum += float64(value)
+	}
+	c.Assert(math.Abs(ma5[199]-sum/5) < 1e-9, Equals, true)
+
+	// Indicators output a row per input row
+	args = &MultiQueryRequest{
+		Requests: []QueryRequest{
+			(NewQueryRequestBuilder("USDJPY/1Min/OHLC").
+				LimitRecordCount(200).
+				Functions([]string{"rsi('14', Close)"}).
+				End()),
+		},
+	}
+	response = MultiQueryResponse{}
+	if err := service.Query(nil, args, &response); err != nil {
+		c.Fatalf("error returned: %s", err)
+	}
+	cs, err = response.Responses[0].Result.ToColumnSeries()
+	c.Assert(err == nil, Equals, true)
+	c.Assert(cs.Len(), Equals, 200)
+	rsi := cs.GetByName("RSI").([]float64)
+	c.Assert(math.IsNaN(rsi[13]), Equals, true)
+	c.Assert(rsi[14] >= 0 && rsi[14] <= 100, Equals, true)
 }
 
 func printFuncParams(fname string, l_list, p_list []string) {
@@ -352,3 +440,161 @@ func printFuncParams(fname string, l_list, p_list []string) {
 		fmt.Printf("LAL param[%d]=:%s:\n", i, val)
 	}
 }
+
+func (s *ServerTestSuite) TestQueryPaged(c *C) {
+	service := &DataService{}
+	service.Init()
+
+	pageSize := 100
+	req := NewQueryRequestBuilder("USDJPY,EURUSD/1Min/OHLC").
+		EpochStart(time.Date(2002, time.October, 1, 10, 5, 0, 0, time.UTC).Unix()).
+		EpochEnd(time.Date(2002, time.October, 1, 15, 5, 0, 0, time.UTC).Unix()).
+		End()
+	req.PageSize = &pageSize
+
+	total := map[string]int{}
+	lastEpoch := map[string]int64{}
+	pages := 0
+	for {
+		var response MultiQueryResponse
+		args := &MultiQueryRequest{Requests: []QueryRequest{req}}
+		if err := service.Query(nil, args, &response); err != nil {
+			c.Fatalf("error returned: %s", err)
+		}
+		pages++
+		csm, err := response.Responses[0].Result.ToColumnSeriesMap()
+		c.Assert(err, IsNil)
+		for tbk, cs := range csm {
+			c.Assert(cs.Len() <= pageSize, Equals, true)
+			epochs := cs.GetEpoch()
+			// pages must continue exactly where the previous one stopped
+			if last, ok := lastEpoch[tbk.String()]; ok {
+				c.Assert(epochs[0], Equals, last+60)
+			}
+			lastEpoch[tbk.String()] = epochs[len(epochs)-1]
+			total[tbk.String()] += cs.Len()
+		}
+		if response.Responses[0].Cursor == "" {
+			break
+		}
+		req.Cursor = response.Responses[0].Cursor
+	}
+	c.Assert(pages, Equals, 4)
+	c.Assert(len(total), Equals, 2)
+	for _, n := range total {
+		c.Assert(n, Equals, 301)
+	}
+
+	// paging can not be mixed with a row limit
+	req = NewQueryRequestBuilder("USDJPY/1Min/OHLC").LimitRecordCount(10).End()
+	req.PageSize = &pageSize
+	var response MultiQueryResponse
+	err := service.Query(nil, &MultiQueryRequest{Requests: []QueryRequest{req}}, &response)
+	c.Assert(err, NotNil)
+}
+
+func (s *ServerTestSuite) TestQueryPagedVariable(c *C) {
+	service := &DataService{}
+	service.Init()
+
+	creqs := &MultiCreateRequest{
+		Requests: []CreateRequest{
+			{Key: "PAGED/1Min/TICK:Symbol/Timeframe/AttributeGroup", DataShapes: "Bid,Ask/float32",
+				RowType: "variable"},
+		},
+	}
+	var cresponse MultiServerResponse
+	c.Assert(service.Create(nil, creqs, &cresponse), IsNil)
+	c.Assert(cresponse.Responses[0].Error, Equals, "")
+
+	// Several records within the same interval, spanning the page boundaries
+	base := time.Date(2005, time.March, 1, 10, 0, 0, 0, time.UTC).Unix()
+	epochs := []int64{base + 1, base + 2, base + 3, base + 61, base + 62, base + 121, base + 122}
+	bids := make([]float32, len(epochs))
+	for i := range bids {
+		bids[i] = float32(i)
+	}
+	tbk := io.NewTimeBucketKey("PAGED/1Min/TICK")
+	cs := io.NewColumnSeries()
+	cs.AddColumn("Epoch", epochs)
+	cs.AddColumn("Bid", bids)
+	cs.AddColumn("Ask", bids)
+	nds, err := io.NewNumpyDataset(cs)
+	c.Assert(err, IsNil)
+	nmds, err := io.NewNumpyMultiDataset(nds, *tbk)
+	c.Assert(err, IsNil)
+	var response MultiServerResponse
+	wargs := &MultiWriteRequest{Requests: []WriteRequest{{Data: nmds, IsVariableLength: true}}}
+	c.Assert(service.Write(nil, wargs, &response), IsNil)
+	c.Assert(response.Responses, HasLen, 0)
+
+	pageSize := 2
+	req := NewQueryRequestBuilder("PAGED/1Min/TICK").EpochStart(base).End()
+	req.PageSize = &pageSize
+	var got []float32
+	pages := 0
+	for {
+		var qresponse MultiQueryResponse
+		c.Assert(service.Query(nil, &MultiQueryRequest{Requests: []QueryRequest{req}}, &qresponse), IsNil)
+		pages++
+		csm, err := qresponse.Responses[0].Result.ToColumnSeriesMap()
+		c.Assert(err, IsNil)
+		for _, cs := range csm {
+			got = append(got, cs.GetByName("Bid").([]float32)...)
+		}
+		if qresponse.Responses[0].Cursor == "" {
+			break
+		}
+		req.Cursor = qresponse.Responses[0].Cursor
+	}
+	c.Assert(pages, Equals, 4)
+	c.Assert(got, DeepEquals, bids)
+}
+
+func (s *ServerTestSuite) TestNextPosition(c *C) {
+	base := time.Date(2002, time.January, 1, 0, 0, 0, 0, time.UTC).Unix()
+	pos := nextPosition(cursorPosition{}, []int64{base, base + 60, base + 61, base + 62}, 3, time.Minute)
+	c.Assert(pos, Equals, cursorPosition{Year: 2002, Index: 2, Offset: 2})
+
+	// a page within the interval resumed from keeps accumulating the rows to skip
+	pos = nextPosition(pos, []int64{base + 62, base + 63, base + 64}, 2, time.Minute)
+	c.Assert(pos, Equals, cursorPosition{Year: 2002, Index: 2, Offset: 4})
+
+	qc := &queryCursor{Positions: map[string]cursorPosition{"A/1Min/OHLC": pos}}
+	token, err := qc.encode()
+	c.Assert(err, IsNil)
+	decoded, err := decodeCursor(token)
+	c.Assert(err, IsNil)
+	c.Assert(decoded.Positions["A/1Min/OHLC"], Equals, pos)
+
+	_, err = decodeCursor("not a cursor")
+	c.Assert(err, NotNil)
+}
+
+func (s *ServerTestSuite) TestQueryStreamHandler(c *C) {
+	pageSize := 1000
+	req := NewQueryRequestBuilder("USDJPY/1Min/OHLC").
+		EpochStart(test.ParseT("2002-12-30 00:00:00").Unix()).
+		End()
+	req.PageSize = &pageSize
+	body, err := msgpack.Marshal(&MultiQueryRequest{Requests: []QueryRequest{req}})
+	c.Assert(err, IsNil)
+
+	rec := httptest.NewRecorder()
+	QueryStreamHandler(rec, httptest.NewRequest("POST", "/query/stream", bytes.NewBuffer(body)))
+	c.Assert(rec.Code, Equals, http.StatusOK)
+
+	dec := msgpack.NewDecoder(rec.Body)
+	chunks, rows := 0, 0
+	for {
+		msg := QueryStreamMessage{}
+		if err := dec.Decode(&msg); err != nil {
+			break
+		}
+		c.Assert(msg.Error, Equals, "")
+		chunks++
+		rows += msg.Result.Len()
+	}
+	c.Assert(chunks, Equals, 3)
+	c.Assert(rows, Equals, 2*1440)
+}
@@ -77,11 +77,32 @@ func NewCatalog() *Catalog {
 // manage a given stream client
 type Subscriber struct {
 	sync.RWMutex
-	c       *websocket.Conn
+	c *websocket.Conn
+	// push delivers the payloads to the subscribers without a connection
+	push    func(Payload) error
 	done    chan struct{}
 	streams map[string]struct{}
 }
 
+// NewSubscriber adds a subscriber receiving the payloads of its streams
+// through push rather than over a websocket, such as a gRPC stream. It must
+// be removed with Close once done.
+func NewSubscriber(push func(Payload) error) *Subscriber {
+	s := &Subscriber{push: push}
+	catalog.Add(s)
+	return s
+}
+
+// Subscribe validates the streams and replaces the ones of the subscriber
+func (s *Subscriber) Subscribe(streams []string) error {
+	return s.handleInbound(SubscribeMessage{Streams: streams})
+}
+
+// Close removes the subscriber from the catalog
+func (s *Subscriber) Close() {
+	catalog.Remove(s)
+}
+
 // Subscribed matches the subscriber's subscribed streams
 // with the supplied timebucket key string.
 func (s *Subscriber) Subscribed(itemKey string) bool {
@@ -216,7 +237,12 @@ func stream() {
 
 		for s := range catalog.subs {
 			if s.Subscribed(payload.Key) {
-				if err := s.handleOutbound(buf); err != nil {
+				if s.push != nil {
+					err = s.push(payload)
+				} else {
+					err = s.handleOutbound(buf)
+				}
+				if err != nil {
 					log.Error("failed to stream outbound (%s)", err)
 				}
 			}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/alpacahq/marketstore/utils"
 	"github.com/alpacahq/marketstore/utils/log"
+	"github.com/alpacahq/marketstore/utils/stats"
 )
 
 var Queryable uint32 // treated as bool
@@ -20,6 +21,19 @@ type HeartbeatMessage struct {
 	Uptime  string `json:"uptime"`
 }
 
+type StatsMessage struct {
+	TotalQueries            uint64 `json:"total_queries"`
+	RetentionFilesRemoved   uint64 `json:"retention_files_removed"`
+	RetentionRecordsDeleted uint64 `json:"retention_records_deleted"`
+	RetentionBytesFreed     uint64 `json:"retention_bytes_freed"`
+	WALSyncRequests         uint64 `json:"wal_sync_requests"`
+	WALGroupCommits         uint64 `json:"wal_group_commits"`
+	ReplicationTGsShipped   uint64 `json:"replication_tgs_shipped"`
+	ReplicationTGsApplied   uint64 `json:"replication_tgs_applied"`
+	ReplicationCatchUps     uint64 `json:"replication_catch_ups"`
+	ReplicationLagMillis    uint64 `json:"replication_lag_ms"`
+}
+
 func init() {
 	Queryable = uint32(0)
 }
@@ -28,6 +42,9 @@ func Utilities(url string) {
 	// heartbeat
 	http.HandleFunc("/heartbeat", heartbeat)
 
+	// counters
+	http.HandleFunc("/stats", statsHandler)
+
 	// profiling
 	http.HandleFunc("/pprof/", pprof.Index)
 	http.HandleFunc("/pprof/cmdline", pprof.Cmdline)
@@ -71,3 +88,22 @@ func heartbeat(rw http.ResponseWriter, r *http.Request) {
 		}
 	}
 }
+
+func statsHandler(rw http.ResponseWriter, r *http.Request) {
+	rw.WriteHeader(http.StatusOK)
+	err := json.NewEncoder(rw).Encode(StatsMessage{
+		TotalQueries:            atomic.LoadUint64(&stats.TotalQueries),
+		RetentionFilesRemoved:   atomic.LoadUint64(&stats.RetentionFilesRemoved),
+		RetentionRecordsDeleted: atomic.LoadUint64(&stats.RetentionRecordsDeleted),
+		RetentionBytesFreed:     atomic.LoadUint64(&stats.RetentionBytesFreed),
+		WALSyncRequests:         atomic.LoadUint64(&stats.WALSyncRequests),
+		WALGroupCommits:         atomic.LoadUint64(&stats.WALGroupCommits),
+		ReplicationTGsShipped:   atomic.LoadUint64(&stats.ReplicationTGsShipped),
+		ReplicationTGsApplied:   atomic.LoadUint64(&stats.ReplicationTGsApplied),
+		ReplicationCatchUps:     atomic.LoadUint64(&stats.ReplicationCatchUps),
+		ReplicationLagMillis:    atomic.LoadUint64(&stats.ReplicationLagMillis),
+	})
+	if err != nil {
+		log.Error("Failed to write stats message - Error: %v", err)
+	}
+}
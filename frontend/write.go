@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"fmt"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -22,6 +23,8 @@ type MultiWriteRequest struct {
 		A multi-request allows for different Timeframes and record formats for each request
 	*/
 	Requests []WriteRequest `msgpack:"requests"`
+	// Sync makes the write return only once the data is durable in the WAL
+	Sync bool `msgpack:"sync"`
 }
 
 type ServerResponse struct {
@@ -47,6 +50,12 @@ func (s *DataService) Write(r *http.Request, reqs *MultiWriteRequest, response *
 		//TODO: There should be an error response for every server request, need to add the below commented line
 		//appendResponse(err, response)
 	}
+	if reqs.Sync {
+		// The writes are not known to be durable, whether they failed or not
+		if err = executor.ThisInstance.WALFile.RequestSync(); err != nil {
+			response.appendResponse(fmt.Errorf("sync failed: %v", err))
+		}
+	}
 	return nil
 }
 
@@ -55,12 +64,23 @@ func (s *DataService) Write(r *http.Request, reqs *MultiWriteRequest, response *
 */
 type CreateRequest struct {
 	Key, DataShapes, RowType string
+	// Compression is "none" or "columnar", for fixed row types only
+	Compression string
+	// WritePolicy is "overwrite", "keep-first", "reject" or "merge", for
+	// fixed row types only
+	WritePolicy string
+	// TimestampFormat is "ticks" or "nanoseconds", for variable row types
+	// only. Nanoseconds keep the exact time of each row
+	TimestampFormat string
 }
 type MultiCreateRequest struct {
 	Requests []CreateRequest
 }
 
 func (s *DataService) Create(r *http.Request, reqs *MultiCreateRequest, response *MultiServerResponse) (err error) {
+	if executor.ThisInstance.ReadOnly {
+		return executor.ReadOnlyError("Create")
+	}
 	for _, req := range reqs.Requests {
 		// Construct a time bucket key from the input string
 		parts := strings.Split(req.Key, ":")
@@ -93,6 +113,28 @@ func (s *DataService) Create(r *http.Request, reqs *MultiCreateRequest, response
 			continue
 		}
 
+		compression := io.EnumCompressionTypeByName(req.Compression)
+		if compression == io.UNKNOWNCOMPRESSION {
+			err = fmt.Errorf("compression \"%s\" is not one of none or columnar\n", req.Compression)
+			response.appendResponse(err)
+			continue
+		}
+
+		writePolicy := io.EnumWritePolicyByName(req.WritePolicy)
+		if writePolicy == io.UNKNOWNWRITEPOLICY {
+			err = fmt.Errorf("write policy \"%s\" is not one of overwrite, keep-first, reject or merge\n",
+				req.WritePolicy)
+			response.appendResponse(err)
+			continue
+		}
+
+		timestampFormat := io.EnumTimestampFormatByName(req.TimestampFormat)
+		if timestampFormat == io.UNKNOWNTIMESTAMPFORMAT {
+			err = fmt.Errorf("timestamp format \"%s\" is not one of ticks or nanoseconds\n", req.TimestampFormat)
+			response.appendResponse(err)
+			continue
+		}
+
 		rootDir := executor.ThisInstance.RootDir
 		year := int16(time.Now().Year())
 		tf, err := tbk.GetTimeFrame()
@@ -102,6 +144,18 @@ func (s *DataService) Create(r *http.Request, reqs *MultiCreateRequest, response
 		}
 		rt := io.EnumRecordTypeByName(rowType)
 		tbinfo := io.NewTimeBucketInfo(*tf, tbk.GetPathToYearFiles(rootDir), "Default", year, dsv, rt)
+		if err = tbinfo.SetCompression(compression); err != nil {
+			response.appendResponse(err)
+			continue
+		}
+		if err = tbinfo.SetWritePolicy(writePolicy); err != nil {
+			response.appendResponse(err)
+			continue
+		}
+		if err = tbinfo.SetTimestampFormat(timestampFormat); err != nil {
+			response.appendResponse(err)
+			continue
+		}
 
 		err = executor.ThisInstance.CatalogDir.AddTimeBucket(tbk, tbinfo)
 		if err != nil {
@@ -109,6 +163,7 @@ func (s *DataService) Create(r *http.Request, reqs *MultiCreateRequest, response
 			response.appendResponse(err)
 			continue
 		}
+		executor.Replication.PublishCreate(tbk, tbinfo)
 		response.appendResponse(err)
 	}
 	return nil
@@ -122,11 +177,18 @@ type MultiKeyRequest struct {
 }
 
 type GetInfoResponse struct {
-	LatestYear int
-	TimeFrame  time.Duration
-	DSV        []io.DataShape
-	RecordType io.EnumRecordType
-	ServerResp ServerResponse
+	LatestYear  int
+	TimeFrame   time.Duration
+	DSV         []io.DataShape
+	RecordType  io.EnumRecordType
+	Compression io.EnumCompressionType
+	UsedBytes   int64 // Disk space allocated to the year files of the bucket
+	// SchemaVersion is incremented each time the columns are altered
+	SchemaVersion int64
+	WritePolicy   io.EnumWritePolicy
+	// TimestampFormat is how the timestamps of variable rows are stored
+	TimestampFormat io.EnumTimestampFormat
+	ServerResp      ServerResponse
 }
 
 type MultiGetInfoResponse struct {
@@ -147,23 +209,30 @@ func (s *DataService) GetInfo(r *http.Request, reqs *MultiKeyRequest, response *
 		tbk := io.NewTimeBucketKey(parts[0], parts[1])
 		if tbk == nil {
 			err = fmt.Errorf(errorString, req.Key)
-			response.appendResponse(nil, err)
+			response.appendResponse(nil, 0, err)
 			continue
 		}
 
 		tbi, err := executor.ThisInstance.CatalogDir.GetLatestTimeBucketInfoFromKey(tbk)
 		if err != nil {
 			err = fmt.Errorf("unable to get info about key %s: %s", req.Key, err.Error())
-			response.appendResponse(nil, err)
+			response.appendResponse(nil, 0, err)
 			continue
 		}
-		response.appendResponse(tbi, err)
+		usedBytes, err := bucketUsedBytes(tbk)
+		if err != nil {
+			err = fmt.Errorf("unable to get disk usage of key %s: %s", req.Key, err.Error())
+		}
+		response.appendResponse(tbi, usedBytes, err)
 	}
 
 	return nil
 }
 
 func (s *DataService) Destroy(r *http.Request, reqs *MultiKeyRequest, response *MultiServerResponse) (err error) {
+	if executor.ThisInstance.ReadOnly {
+		return executor.ReadOnlyError("Destroy")
+	}
 	errorString := "key \"%s\" is not in proper format, should be like: TSLA/1Min/OHLCV"
 
 	for _, req := range reqs.Requests {
@@ -181,22 +250,343 @@ func (s *DataService) Destroy(r *http.Request, reqs *MultiKeyRequest, response *
 			continue
 		}
 
+		// The snapshots being taken keep the files
+		if tbis, err := executor.ThisInstance.CatalogDir.GetTimeBucketInfoSliceFromKey(tbk); err == nil {
+			executor.PreserveYearFiles(tbis)
+		}
 		err = executor.ThisInstance.CatalogDir.RemoveTimeBucket(tbk)
 		if err != nil {
 			err = fmt.Errorf("removal of catalog entry failed: %s", err.Error())
 			response.appendResponse(err)
 			continue
 		}
+		executor.Replication.Resync()
+		response.appendResponse(err)
+	}
+
+	return nil
+}
+
+/*
+AlterBucket: Adds, drops and renames the columns of an existing bucket, and
+changes its write policy
+*/
+type AlterRequest struct {
+	Key string
+	// AddShapes are the columns to add, formatted as CreateRequest.DataShapes
+	AddShapes   string
+	DropColumns []string
+	// RenameColumns maps current column names to new ones
+	RenameColumns map[string]string
+	// WritePolicy is the new write policy, formatted as in CreateRequest,
+	// or empty to keep the current one
+	WritePolicy string
+}
+type MultiAlterRequest struct {
+	Requests []AlterRequest
+}
+
+func (s *DataService) AlterBucket(r *http.Request, reqs *MultiAlterRequest, response *MultiServerResponse) (err error) {
+	if executor.ThisInstance.ReadOnly {
+		return executor.ReadOnlyError("AlterBucket")
+	}
+	errorString := "key \"%s\" is not in proper format, should be like: TSLA/1Min/OHLCV"
+
+	for _, req := range reqs.Requests {
+		parts := strings.Split(req.Key, ":")
+		if len(parts) < 2 {
+			parts = append(parts, "")
+		}
+
+		tbk := io.NewTimeBucketKey(parts[0], parts[1])
+		if tbk == nil {
+			response.appendResponse(fmt.Errorf(errorString, req.Key))
+			continue
+		}
+
+		var add []io.DataShape
+		if req.AddShapes != "" {
+			if add, err = io.DataShapesFromInputString(req.AddShapes); err != nil {
+				response.appendResponse(err)
+				continue
+			}
+		}
+
+		writePolicy := io.EnumWritePolicyByName(req.WritePolicy)
+		if writePolicy == io.UNKNOWNWRITEPOLICY {
+			err = fmt.Errorf("write policy \"%s\" is not one of overwrite, keep-first, reject or merge",
+				req.WritePolicy)
+			response.appendResponse(err)
+			continue
+		}
+		alterColumns := len(add) > 0 || len(req.DropColumns) > 0 || len(req.RenameColumns) > 0
+
+		if _, err = executor.ThisInstance.CatalogDir.GetTimeBucketInfoSliceFromKey(tbk); err != nil {
+			err = fmt.Errorf("unable to find key %s: %s", req.Key, err.Error())
+			response.appendResponse(err)
+			continue
+		}
+
+		if req.WritePolicy != "" {
+			err = executor.UpdateBucketWritePolicy(tbk, writePolicy)
+		}
+		if err == nil && alterColumns {
+			err = executor.AlterBucket(tbk, add, req.DropColumns, req.RenameColumns)
+		}
 		response.appendResponse(err)
 	}
 
 	return nil
 }
 
+/*
+Delete: Deletes the records of a time range through the WAL
+*/
+type DeleteRequest struct {
+	// Destination is <symbol>/<timeframe>/<attributegroup>, where the symbol
+	// can be a comma separated list or "*"
+	Destination string `msgpack:"destination"`
+	// Symbols replaces the symbols of the destination when set
+	Symbols []string `msgpack:"symbols,omitempty"`
+	// Time range of the deleted records (i.e. start <= index <= end) in unix epoch second
+	EpochStart int64 `msgpack:"epoch_start"`
+	EpochEnd   int64 `msgpack:"epoch_end"`
+}
+type MultiDeleteRequest struct {
+	Requests []DeleteRequest `msgpack:"requests"`
+}
+type DeleteResponse struct {
+	// Deleted is the number of records, or of intervals for variable buckets
+	Deleted    int64
+	ServerResp ServerResponse
+}
+type MultiDeleteResponse struct {
+	Responses []DeleteResponse `msgpack:"responses"`
+}
+
+func (s *DataService) Delete(r *http.Request, reqs *MultiDeleteRequest, response *MultiDeleteResponse) (err error) {
+	for _, req := range reqs.Requests {
+		if req.EpochStart > req.EpochEnd {
+			response.appendResponse(0, fmt.Errorf("epoch_start %d is after epoch_end %d",
+				req.EpochStart, req.EpochEnd))
+			continue
+		}
+		destination := req.Destination
+		if len(req.Symbols) != 0 {
+			parts := strings.SplitN(destination, "/", 2)
+			if len(parts) != 2 {
+				response.appendResponse(0, fmt.Errorf("destination %s must have a Timeframe and AttributeGroup",
+					destination))
+				continue
+			}
+			destination = strings.Join(req.Symbols, ",") + "/" + parts[1]
+		}
+		tbk, err := resolveDestination(&QueryRequest{Destination: destination})
+		if err != nil {
+			response.appendResponse(0, err)
+			continue
+		}
+		deleted, err := executor.DeleteRange(tbk, req.EpochStart, req.EpochEnd)
+		response.appendResponse(deleted, err)
+	}
+
+	return nil
+}
+
+/*
+Compact: Frees the disk space of the empty intervals of fixed buckets
+*/
+type CompactResponse struct {
+	FreedBytes int64
+	UsedBytes  int64
+	ServerResp ServerResponse
+}
+type MultiCompactResponse struct {
+	Responses []CompactResponse `msgpack:"responses"`
+}
+
+func (s *DataService) Compact(r *http.Request, reqs *MultiKeyRequest, response *MultiCompactResponse) (err error) {
+	if executor.ThisInstance.ReadOnly {
+		return executor.ReadOnlyError("Compact")
+	}
+	errorString := "key \"%s\" is not in proper format, should be like: TSLA/1Min/OHLCV"
+
+	for _, req := range reqs.Requests {
+		parts := strings.Split(req.Key, ":")
+		if len(parts) < 2 {
+			parts = append(parts, "")
+		}
+
+		tbk := io.NewTimeBucketKey(parts[0], parts[1])
+		if tbk == nil {
+			response.appendResponse(0, 0, fmt.Errorf(errorString, req.Key))
+			continue
+		}
+
+		tbis, err := executor.ThisInstance.CatalogDir.GetTimeBucketInfoSliceFromKey(tbk)
+		if err != nil {
+			err = fmt.Errorf("unable to find key %s: %s", req.Key, err.Error())
+			response.appendResponse(0, 0, err)
+			continue
+		}
+		var freedBytes, freed int64
+		for _, tbi := range tbis {
+			if freed, err = executor.Compact(tbi); err != nil {
+				err = fmt.Errorf("compaction of %s failed: %s", tbi.Path, err.Error())
+				break
+			}
+			freedBytes += freed
+		}
+		if err != nil {
+			response.appendResponse(freedBytes, 0, err)
+			continue
+		}
+		usedBytes, err := bucketUsedBytes(tbk)
+		response.appendResponse(freedBytes, usedBytes, err)
+	}
+
+	return nil
+}
+
+/*
+Backup: Writes a consistent snapshot of the database on the server
+*/
+type BackupRequest struct {
+	// Destination is a directory, or a tarball when it ends with ".tar.gz",
+	// under the backup_dir of the server, relative to it. It must not exist
+	// yet, unless the backup is incremental, in which case it is the directory
+	// storing the snapshots.
+	Destination string `msgpack:"destination"`
+	Incremental bool   `msgpack:"incremental"`
+}
+type BackupResponse struct {
+	Files int
+	Bytes int64
+	// Snapshot, FilesChanged and BytesWritten are only set by incremental
+	// backups, Snapshot being the key of the manifest in the store
+	Snapshot     string
+	FilesChanged int
+	BytesWritten int64
+	ServerResp   ServerResponse
+}
+
+func (s *DataService) Backup(r *http.Request, req *BackupRequest, response *BackupResponse) (err error) {
+	if req.Destination == "" {
+		response.setResponse(fmt.Errorf("a backup destination is required"))
+		return nil
+	}
+	dest, err := backupDestination(req.Destination)
+	if err != nil {
+		response.setResponse(err)
+		return nil
+	}
+	if req.Incremental {
+		result, err := executor.BackupIncremental(dest)
+		if err == nil {
+			response.Files = len(result.Manifest.Files)
+			response.Bytes = result.Manifest.TotalSize()
+			response.Snapshot = result.Key
+			response.FilesChanged = result.FilesChanged
+			response.BytesWritten = result.BytesWritten
+		}
+		response.setResponse(err)
+		return nil
+	}
+	manifest, err := executor.Backup(dest)
+	if err == nil {
+		response.Files = len(manifest.Files)
+		response.Bytes = manifest.TotalSize()
+	}
+	response.setResponse(err)
+	return nil
+}
+
+/*
+backupDestination resolves the destination of a backup under the configured
+backup directory, so that a client can not write anywhere on the server.
+*/
+func backupDestination(dest string) (string, error) {
+	if utils.InstanceConfig.BackupDir == "" {
+		return "", fmt.Errorf("backups are disabled, as backup_dir is not configured")
+	}
+	dir, err := filepath.Abs(utils.InstanceConfig.BackupDir)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Clean(dest)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	if rel, err := filepath.Rel(dir, path); err != nil || rel == "." || rel == ".." ||
+		strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("backup destination %s is not under backup_dir", dest)
+	}
+	return path, nil
+}
+
 /*
 Utility functions
 */
 
+func bucketUsedBytes(tbk *io.TimeBucketKey) (usedBytes int64, err error) {
+	tbis, err := executor.ThisInstance.CatalogDir.GetTimeBucketInfoSliceFromKey(tbk)
+	if err != nil {
+		return 0, err
+	}
+	for _, tbi := range tbis {
+		used, err := io.UsedBytes(tbi.Path)
+		if err != nil {
+			return 0, err
+		}
+		usedBytes += used
+	}
+	return usedBytes, nil
+}
+
+func (mc *MultiCompactResponse) appendResponse(freedBytes, usedBytes int64, err error) {
+	var errorText string
+	if err != nil {
+		errorText = err.Error()
+	}
+	mc.Responses = append(mc.Responses,
+		CompactResponse{
+			FreedBytes: freedBytes,
+			UsedBytes:  usedBytes,
+			ServerResp: ServerResponse{
+				errorText,
+				utils.GitHash,
+			},
+		},
+	)
+}
+
+func (md *MultiDeleteResponse) appendResponse(deleted int64, err error) {
+	var errorText string
+	if err != nil {
+		errorText = err.Error()
+	}
+	md.Responses = append(md.Responses,
+		DeleteResponse{
+			Deleted: deleted,
+			ServerResp: ServerResponse{
+				errorText,
+				utils.GitHash,
+			},
+		},
+	)
+}
+
+func (br *BackupResponse) setResponse(err error) {
+	var errorText string
+	if err != nil {
+		errorText = err.Error()
+	}
+	br.ServerResp = ServerResponse{
+		errorText,
+		utils.GitHash,
+	}
+}
+
 func (mr *MultiServerResponse) appendResponse(err error) {
 	var errorText string
 	if err == nil {
@@ -212,7 +602,7 @@ func (mr *MultiServerResponse) appendResponse(err error) {
 	)
 }
 
-func (mg *MultiGetInfoResponse) appendResponse(tbi *io.TimeBucketInfo, err error) {
+func (mg *MultiGetInfoResponse) appendResponse(tbi *io.TimeBucketInfo, usedBytes int64, err error) {
 	var errorText string
 	if err == nil {
 		errorText = ""
@@ -222,10 +612,15 @@ func (mg *MultiGetInfoResponse) appendResponse(tbi *io.TimeBucketInfo, err error
 	if tbi != nil {
 		mg.Responses = append(mg.Responses,
 			GetInfoResponse{
-				LatestYear: int(tbi.Year),
-				TimeFrame:  tbi.GetTimeframe(),
-				DSV:        tbi.GetDataShapesWithEpoch(),
-				RecordType: tbi.GetRecordType(),
+				LatestYear:      int(tbi.Year),
+				TimeFrame:       tbi.GetTimeframe(),
+				DSV:             tbi.GetDataShapesWithEpoch(),
+				RecordType:      tbi.GetRecordType(),
+				Compression:     tbi.GetCompression(),
+				UsedBytes:       usedBytes,
+				SchemaVersion:   tbi.GetSchemaVersion(),
+				WritePolicy:     tbi.GetWritePolicy(),
+				TimestampFormat: tbi.GetTimestampFormat(),
 				ServerResp: ServerResponse{
 					errorText,
 					utils.GitHash,
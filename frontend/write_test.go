@@ -4,12 +4,16 @@ import (
 	"github.com/alpacahq/marketstore/utils/io"
 
 	"fmt"
+	"os"
+	"path/filepath"
 
 	"strconv"
 
 	"time"
 
 	. "gopkg.in/check.v1"
+
+	"github.com/alpacahq/marketstore/utils"
 )
 
 func (s *ServerTestSuite) TestWrite(c *C) {
@@ -101,3 +105,304 @@ func (s *ServerTestSuite) TestWrite(c *C) {
 	}
 
 }
+
+func (s *ServerTestSuite) TestAlterBucket(c *C) {
+	service := &DataService{}
+	service.Init()
+
+	query := func(key string) io.ColumnSeriesMap {
+		qargs := &MultiQueryRequest{
+			Requests: []QueryRequest{
+				NewQueryRequestBuilder(key).LimitRecordCount(100).End(),
+			},
+		}
+		var qresponse MultiQueryResponse
+		c.Assert(service.Query(nil, qargs, &qresponse), IsNil)
+		csm, err := qresponse.Responses[0].Result.ToColumnSeriesMap()
+		c.Assert(err, IsNil)
+		return csm
+	}
+
+	// Copy some data into a bucket of its own
+	qargs := &MultiQueryRequest{
+		Requests: []QueryRequest{
+			NewQueryRequestBuilder("USDJPY/1Min/OHLC").LimitRecordCount(100).End(),
+		},
+	}
+	var qresponse MultiQueryResponse
+	c.Assert(service.Query(nil, qargs, &qresponse), IsNil)
+	nmds := qresponse.Responses[0].Result
+	for tbkStr, start := range nmds.StartIndex {
+		nmds.StartIndex = map[string]int{"ALTER/1Min/OHLC": start}
+		nmds.Lengths = map[string]int{"ALTER/1Min/OHLC": nmds.Lengths[tbkStr]}
+	}
+	var response MultiServerResponse
+	c.Assert(service.Write(nil, &MultiWriteRequest{Requests: []WriteRequest{{Data: nmds}}}, &response), IsNil)
+	c.Assert(response.Responses, HasLen, 0)
+	before := query("ALTER/1Min/OHLC")[*io.NewTimeBucketKey("ALTER/1Min/OHLC")]
+	c.Assert(before.Len(), Equals, 100)
+
+	areqs := &MultiAlterRequest{
+		Requests: []AlterRequest{{
+			Key:           "ALTER/1Min/OHLC",
+			AddShapes:     "Trades/int32",
+			DropColumns:   []string{"High"},
+			RenameColumns: map[string]string{"Low": "Bottom"},
+		}},
+	}
+	response = MultiServerResponse{}
+	c.Assert(service.AlterBucket(nil, areqs, &response), IsNil)
+	c.Assert(response.Responses[0].Error, Equals, "")
+
+	after := query("ALTER/1Min/OHLC")[*io.NewTimeBucketKey("ALTER/1Min/OHLC")]
+	c.Assert(after.GetColumnNames(), DeepEquals, []string{"Epoch", "Open", "Bottom", "Close", "Trades"})
+	c.Assert(after.GetEpoch(), DeepEquals, before.GetEpoch())
+	c.Assert(after.GetColumn("Open"), DeepEquals, before.GetColumn("Open"))
+	c.Assert(after.GetColumn("Bottom"), DeepEquals, before.GetColumn("Low"))
+	c.Assert(after.GetColumn("Trades"), DeepEquals, make([]int32, 100))
+
+	var info MultiGetInfoResponse
+	c.Assert(service.GetInfo(nil, &MultiKeyRequest{Requests: []KeyRequest{{Key: "ALTER/1Min/OHLC"}}}, &info), IsNil)
+	c.Assert(info.Responses[0].SchemaVersion, Equals, int64(1))
+
+	// Unknown and duplicate columns are rejected
+	areqs.Requests[0] = AlterRequest{Key: "ALTER/1Min/OHLC", DropColumns: []string{"High"}}
+	response = MultiServerResponse{}
+	c.Assert(service.AlterBucket(nil, areqs, &response), IsNil)
+	c.Assert(response.Responses[0].Error, Not(Equals), "")
+	areqs.Requests[0] = AlterRequest{Key: "ALTER/1Min/OHLC", AddShapes: "Open/float32"}
+	response = MultiServerResponse{}
+	c.Assert(service.AlterBucket(nil, areqs, &response), IsNil)
+	c.Assert(response.Responses[0].Error, Not(Equals), "")
+}
+
+func (s *ServerTestSuite) TestDelete(c *C) {
+	service := &DataService{}
+	service.Init()
+
+	// Copy some data into buckets of their own
+	qargs := &MultiQueryRequest{
+		Requests: []QueryRequest{
+			NewQueryRequestBuilder("USDJPY/1Min/OHLC").LimitRecordCount(100).End(),
+		},
+	}
+	var qresponse MultiQueryResponse
+	c.Assert(service.Query(nil, qargs, &qresponse), IsNil)
+	nmds := qresponse.Responses[0].Result
+	csm, err := nmds.ToColumnSeriesMap()
+	c.Assert(err, IsNil)
+	epoch := csm[*io.NewTimeBucketKey("USDJPY/1Min/OHLC")].GetEpoch()
+	for _, symbol := range []string{"DELETE0", "DELETE1"} {
+		for tbkStr, start := range nmds.StartIndex {
+			key := symbol + "/1Min/OHLC"
+			nmds.StartIndex = map[string]int{key: start}
+			nmds.Lengths = map[string]int{key: nmds.Lengths[tbkStr]}
+		}
+		var response MultiServerResponse
+		c.Assert(service.Write(nil, &MultiWriteRequest{Requests: []WriteRequest{{Data: nmds}}}, &response), IsNil)
+		c.Assert(response.Responses, HasLen, 0)
+	}
+
+	dargs := &MultiDeleteRequest{
+		Requests: []DeleteRequest{
+			{Destination: "*/1Min/OHLC", Symbols: []string{"DELETE0", "DELETE1"},
+				EpochStart: epoch[10], EpochEnd: epoch[29]},
+			{Destination: "DELETE0/1Min/OHLC", EpochStart: epoch[50], EpochEnd: epoch[40]},
+			{Destination: "DELETE0/1Min/OHLC", EpochStart: epoch[90], EpochEnd: epoch[99]},
+		},
+	}
+	var dresponse MultiDeleteResponse
+	c.Assert(service.Delete(nil, dargs, &dresponse), IsNil)
+	c.Assert(dresponse.Responses, HasLen, 3)
+	c.Assert(dresponse.Responses[0].ServerResp.Error, Equals, "")
+	c.Assert(dresponse.Responses[0].Deleted, Equals, int64(40))
+	c.Assert(dresponse.Responses[1].ServerResp.Error, Not(Equals), "")
+	c.Assert(dresponse.Responses[2].Deleted, Equals, int64(10))
+
+	qargs = &MultiQueryRequest{
+		Requests: []QueryRequest{
+			NewQueryRequestBuilder("DELETE0,DELETE1/1Min/OHLC").LimitRecordCount(200).End(),
+		},
+	}
+	qresponse = MultiQueryResponse{}
+	c.Assert(service.Query(nil, qargs, &qresponse), IsNil)
+	csm, err = qresponse.Responses[0].Result.ToColumnSeriesMap()
+	c.Assert(err, IsNil)
+	deleted0 := csm[*io.NewTimeBucketKey("DELETE0/1Min/OHLC")].GetEpoch()
+	deleted1 := csm[*io.NewTimeBucketKey("DELETE1/1Min/OHLC")].GetEpoch()
+	c.Assert(deleted0, DeepEquals, append(append([]int64{}, epoch[:10]...), epoch[30:90]...))
+	c.Assert(deleted1, DeepEquals, append(append([]int64{}, epoch[:10]...), epoch[30:]...))
+}
+
+func (s *ServerTestSuite) TestWritePolicy(c *C) {
+	service := &DataService{}
+	service.Init()
+
+	creqs := &MultiCreateRequest{
+		Requests: []CreateRequest{
+			{Key: "REJECT/1Min/OHLC:Symbol/Timeframe/AttributeGroup", DataShapes: "Open,High,Low,Close/float32",
+				RowType: "fixed", WritePolicy: "reject"},
+			{Key: "BADPOLICY/1Min/OHLC:Symbol/Timeframe/AttributeGroup", DataShapes: "Open,High,Low,Close/float32",
+				RowType: "fixed", WritePolicy: "ignore"},
+			{Key: "BADPOLICY/1Min/TICK:Symbol/Timeframe/AttributeGroup", DataShapes: "Bid,Ask/float32",
+				RowType: "variable", WritePolicy: "merge"},
+		},
+	}
+	var cresponse MultiServerResponse
+	c.Assert(service.Create(nil, creqs, &cresponse), IsNil)
+	c.Assert(cresponse.Responses, HasLen, 3)
+	c.Assert(cresponse.Responses[0].Error, Equals, "")
+	c.Assert(cresponse.Responses[1].Error, Not(Equals), "")
+	c.Assert(cresponse.Responses[2].Error, Not(Equals), "")
+
+	qargs := &MultiQueryRequest{
+		Requests: []QueryRequest{
+			NewQueryRequestBuilder("USDJPY/1Min/OHLC").LimitRecordCount(10).End(),
+		},
+	}
+	var qresponse MultiQueryResponse
+	c.Assert(service.Query(nil, qargs, &qresponse), IsNil)
+	nmds := qresponse.Responses[0].Result
+	for tbkStr, start := range nmds.StartIndex {
+		nmds.StartIndex = map[string]int{"REJECT/1Min/OHLC": start}
+		nmds.Lengths = map[string]int{"REJECT/1Min/OHLC": nmds.Lengths[tbkStr]}
+	}
+	wargs := &MultiWriteRequest{Requests: []WriteRequest{{Data: nmds}}}
+	var response MultiServerResponse
+	c.Assert(service.Write(nil, wargs, &response), IsNil)
+	c.Assert(response.Responses, HasLen, 0)
+
+	// Writing the same bars again is rejected
+	response = MultiServerResponse{}
+	c.Assert(service.Write(nil, wargs, &response), IsNil)
+	c.Assert(response.Responses, HasLen, 1)
+	c.Assert(response.Responses[0].Error, Not(Equals), "")
+
+	// Until the policy is changed
+	areqs := &MultiAlterRequest{Requests: []AlterRequest{{Key: "REJECT/1Min/OHLC", WritePolicy: "overwrite"}}}
+	var aresponse MultiServerResponse
+	c.Assert(service.AlterBucket(nil, areqs, &aresponse), IsNil)
+	c.Assert(aresponse.Responses[0].Error, Equals, "")
+	var info MultiGetInfoResponse
+	c.Assert(service.GetInfo(nil, &MultiKeyRequest{Requests: []KeyRequest{{Key: "REJECT/1Min/OHLC"}}}, &info), IsNil)
+	c.Assert(info.Responses[0].WritePolicy, Equals, io.OVERWRITE)
+	c.Assert(info.Responses[0].SchemaVersion, Equals, int64(0))
+	response = MultiServerResponse{}
+	c.Assert(service.Write(nil, wargs, &response), IsNil)
+	c.Assert(response.Responses, HasLen, 0)
+}
+
+func (s *ServerTestSuite) TestWriteNanoseconds(c *C) {
+	service := &DataService{}
+	service.Init()
+
+	creqs := &MultiCreateRequest{
+		Requests: []CreateRequest{
+			{Key: "NANO/1Min/TICK:Symbol/Timeframe/AttributeGroup", DataShapes: "Bid,Ask/float32",
+				RowType: "variable", TimestampFormat: "nanoseconds"},
+			{Key: "BADNANO/1Min/OHLC:Symbol/Timeframe/AttributeGroup", DataShapes: "Open,High,Low,Close/float32",
+				RowType: "fixed", TimestampFormat: "nanoseconds"},
+			{Key: "BADNANO/1Min/TICK:Symbol/Timeframe/AttributeGroup", DataShapes: "Bid,Ask/float32",
+				RowType: "variable", TimestampFormat: "picoseconds"},
+		},
+	}
+	var cresponse MultiServerResponse
+	c.Assert(service.Create(nil, creqs, &cresponse), IsNil)
+	c.Assert(cresponse.Responses, HasLen, 3)
+	c.Assert(cresponse.Responses[0].Error, Equals, "")
+	c.Assert(cresponse.Responses[1].Error, Not(Equals), "")
+	c.Assert(cresponse.Responses[2].Error, Not(Equals), "")
+
+	// Two prints in the same microsecond
+	epoch := time.Now().Unix()
+	tbk := io.NewTimeBucketKey("NANO/1Min/TICK")
+	cs := io.NewColumnSeries()
+	cs.AddColumn("Epoch", []int64{epoch, epoch})
+	cs.AddColumn("Bid", []float32{1, 2})
+	cs.AddColumn("Ask", []float32{3, 4})
+	cs.AddColumn("Nanoseconds", []int32{5001, 5000})
+	nds, err := io.NewNumpyDataset(cs)
+	c.Assert(err, IsNil)
+	nmds, err := io.NewNumpyMultiDataset(nds, *tbk)
+	c.Assert(err, IsNil)
+	var response MultiServerResponse
+	wargs := &MultiWriteRequest{Requests: []WriteRequest{{Data: nmds, IsVariableLength: true}}}
+	c.Assert(service.Write(nil, wargs, &response), IsNil)
+	c.Assert(response.Responses, HasLen, 0)
+
+	var info MultiGetInfoResponse
+	c.Assert(service.GetInfo(nil, &MultiKeyRequest{Requests: []KeyRequest{{Key: "NANO/1Min/TICK"}}}, &info), IsNil)
+	c.Assert(info.Responses[0].TimestampFormat, Equals, io.NANOSECONDS)
+
+	qargs := &MultiQueryRequest{
+		Requests: []QueryRequest{NewQueryRequestBuilder("NANO/1Min/TICK").EpochStart(epoch).End()},
+	}
+	var qresponse MultiQueryResponse
+	c.Assert(service.Query(nil, qargs, &qresponse), IsNil)
+	csm, err := qresponse.Responses[0].Result.ToColumnSeriesMap()
+	c.Assert(err, IsNil)
+	c.Assert(csm[*tbk].GetByName("Nanoseconds"), DeepEquals, []int32{5000, 5001})
+	c.Assert(csm[*tbk].GetByName("Bid"), DeepEquals, []float32{2, 1})
+}
+
+func (s *ServerTestSuite) TestWriteSync(c *C) {
+	service := &DataService{}
+	service.Init()
+
+	epoch := time.Now().Unix()
+	tbk := io.NewTimeBucketKey("SYNC/1Min/OHLC")
+	cs := io.NewColumnSeries()
+	cs.AddColumn("Epoch", []int64{epoch})
+	cs.AddColumn("Open", []float32{1})
+	cs.AddColumn("High", []float32{2})
+	cs.AddColumn("Low", []float32{3})
+	cs.AddColumn("Close", []float32{4})
+	nds, err := io.NewNumpyDataset(cs)
+	c.Assert(err, IsNil)
+	nmds, err := io.NewNumpyMultiDataset(nds, *tbk)
+	c.Assert(err, IsNil)
+	var response MultiServerResponse
+	wargs := &MultiWriteRequest{Requests: []WriteRequest{{Data: nmds}}, Sync: true}
+	c.Assert(service.Write(nil, wargs, &response), IsNil)
+	c.Assert(response.Responses, HasLen, 0)
+
+	qargs := &MultiQueryRequest{
+		Requests: []QueryRequest{NewQueryRequestBuilder("SYNC/1Min/OHLC").EpochStart(epoch).End()},
+	}
+	var qresponse MultiQueryResponse
+	c.Assert(service.Query(nil, qargs, &qresponse), IsNil)
+	csm, err := qresponse.Responses[0].Result.ToColumnSeriesMap()
+	c.Assert(err, IsNil)
+	c.Assert(csm[*tbk].GetByName("Close"), DeepEquals, []float32{4})
+}
+
+func (s *ServerTestSuite) TestBackup(c *C) {
+	service := &DataService{}
+	service.Init()
+
+	var response BackupResponse
+	c.Assert(service.Backup(nil, &BackupRequest{}, &response), IsNil)
+	c.Assert(response.ServerResp.Error, Equals, "a backup destination is required")
+
+	response = BackupResponse{}
+	c.Assert(service.Backup(nil, &BackupRequest{Destination: "snapshot.tar.gz"}, &response), IsNil)
+	c.Assert(response.ServerResp.Error, Equals, "backups are disabled, as backup_dir is not configured")
+
+	// Backups are only written under the backup directory
+	backupDir := c.MkDir()
+	utils.InstanceConfig.BackupDir = backupDir
+	defer func() { utils.InstanceConfig.BackupDir = "" }()
+	for _, dest := range []string{"../snapshot.tar.gz", c.MkDir() + "/snapshot.tar.gz", backupDir} {
+		response = BackupResponse{}
+		c.Assert(service.Backup(nil, &BackupRequest{Destination: dest}, &response), IsNil)
+		c.Assert(response.ServerResp.Error, Matches, "backup destination .* is not under backup_dir")
+	}
+
+	response = BackupResponse{}
+	c.Assert(service.Backup(nil, &BackupRequest{Destination: "snapshot.tar.gz"}, &response), IsNil)
+	c.Assert(response.ServerResp.Error, Equals, "")
+	_, err := os.Stat(filepath.Join(backupDir, "snapshot.tar.gz"))
+	c.Assert(err, IsNil)
+	c.Assert(response.Files > 0, Equals, true)
+	c.Assert(response.Bytes > 0, Equals, true)
+}
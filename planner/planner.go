@@ -131,7 +131,8 @@ func (pr *ParseResult) GetRowLen() (rlenMap map[TimeBucketKey]int) {
 		case FIXED:
 			rlenMap[qf.Key] = int(qf.File.GetRecordLength())
 		case VARIABLE:
-			rlenMap[qf.Key] = int(qf.File.GetVariableRecordLength())
+			// Variable rows are read with a 4 byte Nanoseconds column in place of the timestamp
+			rlenMap[qf.Key] = int(qf.File.GetVariableRecordLength()) - qf.File.GetTimestampFormat().Size() + 4
 		}
 	}
 	return rlenMap
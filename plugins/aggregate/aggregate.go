@@ -0,0 +1,47 @@
+// Package aggregate provides interface for aggregate (UDA) plugins.  An
+// aggregate plugin has to implement the following function.
+// NewAggregate(config map[string]interface{}) (uda.AggInterface, error)
+//
+// The aggregate returned by this function is registered under the configured
+// name at startup, before the query interface is started, and can then be
+// called like the built-in aggregates in SQL and in the function pipeline of
+// queries.  It is used as a template only; every call creates a new instance
+// with its New() method, so it should not keep state of its own.  The plugin
+// must be built against the same version of the uda package as the server.
+//
+// Configuration is as follows.
+//  udas:
+//    - module: xxxAggregate.so
+//      name: myagg
+//      config: <according to the plugin>
+package aggregate
+
+import (
+	"fmt"
+
+	"github.com/alpacahq/marketstore/uda"
+)
+
+// SymbolLoader is an interface to retrieve symbol object from plugin
+type SymbolLoader interface {
+	LoadSymbol(symbolName string) (interface{}, error)
+}
+
+// Load loads new aggregate instance using loader, and initializes it with config.
+func Load(loader SymbolLoader, config map[string]interface{}) (uda.AggInterface, error) {
+	symbolName := "NewAggregate"
+	sym, err := loader.LoadSymbol(symbolName)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to load %s", symbolName)
+	}
+
+	newFunc, ok := sym.(func(map[string]interface{}) (uda.AggInterface, error))
+	if !ok {
+		return nil, fmt.Errorf("%s does not comply function spec", symbolName)
+	}
+	agg, err := newFunc(config)
+	if err == nil && agg == nil {
+		return nil, fmt.Errorf("%s returned no aggregate", symbolName)
+	}
+	return agg, err
+}
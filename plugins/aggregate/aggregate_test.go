@@ -0,0 +1,55 @@
+package aggregate
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/alpacahq/marketstore/uda"
+	"github.com/alpacahq/marketstore/uda/count"
+	. "gopkg.in/check.v1"
+)
+
+// Hook up gocheck into the "go test" runner.
+func Test(t *testing.T) { TestingT(t) }
+
+type TestSuite struct {
+}
+
+var _ = Suite(&TestSuite{})
+
+type testLoader map[string]interface{}
+
+func (l testLoader) LoadSymbol(symbolName string) (interface{}, error) {
+	sym, ok := l[symbolName]
+	if !ok {
+		return nil, fmt.Errorf("symbol %s not found", symbolName)
+	}
+	return sym, nil
+}
+
+func (s *TestSuite) TestLoad(c *C) {
+	var conf map[string]interface{}
+	loader := testLoader{
+		"NewAggregate": func(config map[string]interface{}) (uda.AggInterface, error) {
+			conf = config
+			return &count.Count{}, nil
+		},
+	}
+	agg, err := Load(loader, map[string]interface{}{"key": "value"})
+	c.Assert(err, IsNil)
+	_, ok := agg.(*count.Count)
+	c.Assert(ok, Equals, true)
+	c.Assert(conf["key"], Equals, "value")
+
+	_, err = Load(testLoader{}, nil)
+	c.Assert(err, NotNil)
+
+	_, err = Load(testLoader{"NewAggregate": func() {}}, nil)
+	c.Assert(err, NotNil)
+
+	loader["NewAggregate"] = func(config map[string]interface{}) (uda.AggInterface, error) {
+		return nil, fmt.Errorf("bad config")
+	}
+	_, err = Load(loader, nil)
+	c.Assert(err, ErrorMatches, "bad config")
+}
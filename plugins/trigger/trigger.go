@@ -17,6 +17,7 @@
 //
 // The "on" value is matched with the file path to decide whether the trigger
 // is fired or not.  It can contain wildcard character "*".
+// Triggers implementing DeleteTrigger are also fired on the deletion of records.
 // As of now, trigger fires only on the running state.  Trigger on WAL replay
 // may be added later.
 package trigger
@@ -39,6 +40,15 @@ type Trigger interface {
 	Fire(keyPath string, records []Record)
 }
 
+// DeleteTrigger is an optional interface of trigger plugins, to be notified
+// of deleted records.
+type DeleteTrigger interface {
+	// FireDelete is called when records have been deleted from the target
+	// file.  keyPath is the same as in Fire, and indexes contains the
+	// indexes of the deleted rows.
+	FireDelete(keyPath string, indexes []int64)
+}
+
 // TriggerMatcher checks if the trigger should be fired or not.
 type TriggerMatcher struct {
 	Trigger Trigger
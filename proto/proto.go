@@ -0,0 +1,5 @@
+// Package proto holds the protobuf definitions of the gRPC API of marketstore,
+// served by frontend.GRPCService, and the code generated from them.
+package proto
+
+//go:generate protoc --go_out=plugins=grpc,paths=source_relative:. marketstore.proto
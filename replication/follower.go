@@ -0,0 +1,170 @@
+package replication
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/alpacahq/marketstore/executor"
+	"github.com/alpacahq/marketstore/utils/log"
+	"github.com/alpacahq/marketstore/utils/stats"
+)
+
+/*
+Follower applies the transaction groups streamed from a primary to this
+instance, which is read-only.
+*/
+type Follower struct {
+	// Primary is the replication address of the primary at "hostname:port"
+	Primary string
+	// RetryInterval is the delay before reconnecting to the primary
+	RetryInterval time.Duration
+	client        *http.Client
+	caughtUp      time.Time // last time all the entries of the primary were applied
+}
+
+func NewFollower(primary string) *Follower {
+	return &Follower{
+		Primary:       primary,
+		RetryInterval: 5 * time.Second,
+		client:        &http.Client{},
+	}
+}
+
+// Run follows the primary until stop is closed, reconnecting to it after
+// each error
+func (f *Follower) Run(stop <-chan struct{}) {
+	executor.ThisInstance.ReadOnly = true
+	for {
+		err := f.follow(stop)
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		if err == nil {
+			// Caught up from a snapshot, streaming from there
+			continue
+		}
+		log.Error("replication from %s interrupted: %v", f.Primary, err)
+		select {
+		case <-stop:
+			return
+		case <-time.After(f.RetryInterval):
+		}
+	}
+}
+
+// follow streams the replication log of the primary from the position of the
+// follower until an error, or until it catches up from a snapshot, which is
+// needed when its position is gone from the log or the catalog changed
+func (f *Follower) follow(stop <-chan struct{}) error {
+	position, err := executor.ReplicationPosition()
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("http://%s%s?from=%d", f.Primary, StreamPath, position)
+	resp, err := f.client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusGone:
+		return f.catchUp()
+	default:
+		body, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("response error (%d): %s", resp.StatusCode, string(body))
+	}
+	log.Info("replicating from %s at position %d", f.Primary, position)
+	f.caughtUp = time.Now()
+
+	// Closing the body interrupts the read of the next frame
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-stop:
+			resp.Body.Close()
+		case <-done:
+		}
+	}()
+
+	for {
+		fr, err := readFrame(resp.Body)
+		if err != nil {
+			return err
+		}
+		switch fr.Type {
+		case transactionGroupFrame:
+			if err = executor.ApplyTransactionGroup(fr.Position, fr.Payload); err != nil {
+				log.Error("unable to apply the transaction group at %d, catching up: %v", fr.Position, err)
+				return f.catchUp()
+			}
+			position = fr.Position
+		case catalogCreateFrame:
+			if err = executor.ApplyCatalogCreate(fr.Position, fr.Payload); err != nil {
+				log.Error("unable to apply the catalog create at %d, catching up: %v", fr.Position, err)
+				return f.catchUp()
+			}
+			position = fr.Position
+		case resyncFrame:
+			return f.catchUp()
+		case heartbeatFrame:
+			f.setLag(position, fr.Position)
+		default:
+			return fmt.Errorf("Unknown replication frame type %d", fr.Type)
+		}
+	}
+}
+
+// catchUp replaces the data of the follower with a snapshot of the primary
+func (f *Follower) catchUp() error {
+	resp, err := f.client.Get(fmt.Sprintf("http://%s%s", f.Primary, SnapshotPath))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("response error (%d): %s", resp.StatusCode, string(body))
+	}
+	position, err := strconv.ParseInt(resp.Header.Get(PositionHeader), 10, 64)
+	if err != nil {
+		return fmt.Errorf("Invalid snapshot position: %v", err)
+	}
+
+	fp, err := ioutil.TempFile("", "marketstore-replication-*.tar.gz")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(fp.Name())
+	_, err = io.Copy(fp, resp.Body)
+	if closeErr := fp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if err = executor.ReplaceFromSnapshot(fp.Name(), position); err != nil {
+		return err
+	}
+	log.Info("caught up from a snapshot of %s at position %d", f.Primary, position)
+	return nil
+}
+
+// setLag records the lag of the follower, as the time since it last applied
+// all the entries of the primary, when told the position of the latest one
+func (f *Follower) setLag(position, primary int64) {
+	now := time.Now()
+	if position >= primary {
+		f.caughtUp = now
+	}
+	atomic.StoreUint64(&stats.ReplicationLagMillis, uint64(now.Sub(f.caughtUp)/time.Millisecond))
+}
@@ -0,0 +1,126 @@
+package replication
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/alpacahq/marketstore/executor"
+	"github.com/alpacahq/marketstore/utils/log"
+)
+
+// HeartbeatInterval is the frequency of the heartbeats of idle streams
+var HeartbeatInterval = time.Second
+
+// entryFrames maps the types of the entries of the replication log to the
+// types of their frames
+var entryFrames = map[executor.ReplicationEntryType]frameType{
+	executor.TransactionGroupEntry: transactionGroupFrame,
+	executor.CatalogCreateEntry:    catalogCreateFrame,
+	executor.ResyncEntry:           resyncFrame,
+}
+
+/*
+Serve serves the replication log and the snapshots of this instance to the
+followers at url, which is kept apart from the address of the API so that it
+can be restricted to the followers.
+*/
+func Serve(url string) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc(StreamPath, StreamHandler)
+	mux.HandleFunc(SnapshotPath, SnapshotHandler)
+	return http.ListenAndServe(url, mux)
+}
+
+// StreamHandler streams the entries of the replication log to a follower
+func StreamHandler(w http.ResponseWriter, r *http.Request) {
+	from, err := strconv.ParseInt(r.URL.Query().Get("from"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid replication position: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	entries, _, wait, err := executor.Replication.Since(from)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusGone)
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+
+	heartbeat := time.NewTicker(HeartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		for _, entry := range entries {
+			f := frame{Type: entryFrames[entry.Type], Position: entry.Position, Payload: entry.Data}
+			if err = writeFrame(w, f); err != nil {
+				return
+			}
+			from = entry.Position
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+
+		select {
+		case <-wait:
+		case <-heartbeat.C:
+			f := frame{Type: heartbeatFrame, Position: executor.Replication.Last()}
+			if err = writeFrame(w, f); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		}
+		entries, _, wait, err = executor.Replication.Since(from)
+		if err != nil {
+			// The follower falling behind gets the status on reconnection
+			log.Warn("replication follower %s fell behind: %v", r.RemoteAddr, err)
+			return
+		}
+	}
+}
+
+// snapshots bounds the number of snapshots written at once
+var snapshots = make(chan struct{}, 1)
+
+// SnapshotHandler serves a snapshot of the primary for a follower to catch up
+// from, along with its position in the replication log. The followers asking
+// for one while another one is written are told to retry later.
+func SnapshotHandler(w http.ResponseWriter, r *http.Request) {
+	select {
+	case snapshots <- struct{}{}:
+		defer func() { <-snapshots }()
+	default:
+		http.Error(w, "a snapshot is being written, retry later", http.StatusServiceUnavailable)
+		return
+	}
+	// The snapshot is written to disk first, so that the files are only
+	// preserved from the writes while it is written rather than sent
+	dir, err := ioutil.TempDir("", "marketstore-replication")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "snapshot.tar.gz")
+	position, err := executor.ReplicationSnapshot(path)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	fp, err := os.Open(path)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer fp.Close()
+	log.Info("sending a snapshot at replication position %d to %s", position, r.RemoteAddr)
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set(PositionHeader, strconv.FormatInt(position, 10))
+	io.Copy(w, fp)
+}
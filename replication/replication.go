@@ -0,0 +1,86 @@
+/*
+Package replication streams the transaction groups written to the WAL of a
+primary to read-only followers.
+
+A follower asks the primary for the entries of its replication log following
+its position, and applies each transaction group to its own primary files.
+The primary answers with the status 410 (Gone) when some of the entries are
+no longer retained, in which case the follower catches up from a snapshot of
+the primary and streams the entries following it. The creation of buckets and
+year files is streamed as well, while the other changes made outside of the
+WAL, such as the removal or alteration of files, are replicated by catching up.
+
+The stream is a sequence of frames, each made of its type (int8), a position
+in the replication log (int64), the length of its payload (int64) and the
+payload, in little endian. Heartbeats carry the position of the latest entry
+of the primary, for the follower to measure its lag.
+*/
+package replication
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+const (
+	// StreamPath streams the replication log from the position given by the
+	// "from" query parameter
+	StreamPath = "/replication/stream"
+	// SnapshotPath serves a snapshot of the primary as a gzipped tarball
+	SnapshotPath = "/replication/snapshot"
+	// PositionHeader holds the position of the snapshot in the replication log
+	PositionHeader = "X-Replication-Position"
+)
+
+type frameType int8
+
+const (
+	transactionGroupFrame frameType = iota + 1
+	resyncFrame
+	heartbeatFrame
+	catalogCreateFrame
+)
+
+const frameHeaderSize = 1 + 8 + 8
+
+// maxPayload bounds the payload read by followers, to reject corrupt frames
+const maxPayload = 1 << 30
+
+type frame struct {
+	Type     frameType
+	Position int64
+	Payload  []byte
+}
+
+func writeFrame(w io.Writer, f frame) error {
+	var header [frameHeaderSize]byte
+	header[0] = byte(f.Type)
+	binary.LittleEndian.PutUint64(header[1:9], uint64(f.Position))
+	binary.LittleEndian.PutUint64(header[9:17], uint64(len(f.Payload)))
+	if _, err := w.Write(header[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(f.Payload)
+	return err
+}
+
+func readFrame(r io.Reader) (f frame, err error) {
+	var header [frameHeaderSize]byte
+	if _, err = io.ReadFull(r, header[:]); err != nil {
+		return f, err
+	}
+	f.Type = frameType(header[0])
+	f.Position = int64(binary.LittleEndian.Uint64(header[1:9]))
+	length := int64(binary.LittleEndian.Uint64(header[9:17]))
+	if length < 0 || length > maxPayload {
+		return f, fmt.Errorf("Invalid replication frame length %d", length)
+	}
+	if length > 0 {
+		f.Payload = make([]byte, length)
+		if _, err = io.ReadFull(r, f.Payload); err != nil {
+			return f, err
+		}
+	}
+	return f, nil
+}
@@ -0,0 +1,186 @@
+package replication
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/alpacahq/marketstore/executor"
+	"github.com/alpacahq/marketstore/planner"
+	"github.com/alpacahq/marketstore/utils/io"
+	"github.com/alpacahq/marketstore/utils/test"
+)
+
+// Hook up gocheck into the "go test" runner.
+func Test(t *testing.T) { TestingT(t) }
+
+type ReplicationTestSuite struct{}
+
+var _ = Suite(&ReplicationTestSuite{})
+
+func (s *ReplicationTestSuite) TestFrame(c *C) {
+	var buffer bytes.Buffer
+	frames := []frame{
+		{Type: transactionGroupFrame, Position: 42, Payload: []byte{1, 2, 3}},
+		{Type: resyncFrame, Position: 43},
+		{Type: heartbeatFrame, Position: 43},
+	}
+	for _, f := range frames {
+		c.Assert(writeFrame(&buffer, f), IsNil)
+	}
+	for _, f := range frames {
+		got, err := readFrame(&buffer)
+		c.Assert(err, IsNil)
+		c.Assert(got, DeepEquals, f)
+	}
+	_, err := readFrame(&buffer)
+	c.Assert(err, NotNil)
+}
+
+/*
+TestReplication records what the primary serves to a follower, then replays it
+from a stand-in primary to a follower, as both share the executor instance.
+*/
+func (s *ReplicationTestSuite) TestReplication(c *C) {
+	HeartbeatInterval = 50 * time.Millisecond
+	primaryDir := c.MkDir()
+	test.MakeDummyCurrencyDir(primaryDir, false, false)
+	executor.ThisInstance = nil
+	executor.NewInstanceSetup(primaryDir, true, true, false)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(StreamPath, StreamHandler)
+	mux.HandleFunc(SnapshotPath, SnapshotHandler)
+	primary := httptest.NewServer(mux)
+
+	// The position of a new follower is gone
+	resp, err := http.Get(primary.URL + StreamPath + "?from=0")
+	c.Assert(err, IsNil)
+	resp.Body.Close()
+	c.Assert(resp.StatusCode, Equals, http.StatusGone)
+
+	resp, err = http.Get(primary.URL + SnapshotPath)
+	c.Assert(err, IsNil)
+	c.Assert(resp.StatusCode, Equals, http.StatusOK)
+	snapshot, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	c.Assert(err, IsNil)
+	position, err := strconv.ParseInt(resp.Header.Get(PositionHeader), 10, 64)
+	c.Assert(err, IsNil)
+
+	resp, err = http.Get(primary.URL + StreamPath + "?from=" + strconv.FormatInt(position, 10))
+	c.Assert(err, IsNil)
+	c.Assert(resp.StatusCode, Equals, http.StatusOK)
+	ts := time.Date(2002, time.March, 1, 0, 0, 0, 0, time.UTC)
+	tbk := io.NewTimeBucketKey("EURUSD/1Min/OHLC")
+	cs := io.NewColumnSeries()
+	cs.AddColumn("Epoch", []int64{ts.Unix()})
+	cs.AddColumn("Open", []float32{1})
+	cs.AddColumn("High", []float32{2})
+	cs.AddColumn("Low", []float32{3})
+	cs.AddColumn("Close", []float32{4})
+	csm := io.NewColumnSeriesMap()
+	csm.AddColumnSeries(*tbk, cs)
+	c.Assert(executor.WriteCSM(csm, false), IsNil)
+	// A new year file is created on the followers
+	next := ts.AddDate(1, 0, 0)
+	cs.GetColumn("Epoch").([]int64)[0] = next.Unix()
+	c.Assert(executor.WriteCSM(csm, false), IsNil)
+	executor.Replication.Resync()
+
+	var frames []frame
+	for _, frameType := range []frameType{transactionGroupFrame, catalogCreateFrame, transactionGroupFrame} {
+		fr, err := readFrame(resp.Body)
+		c.Assert(err, IsNil)
+		c.Assert(fr.Type, Equals, frameType)
+		c.Assert(fr.Position, Equals, position+int64(len(frames))+1)
+		frames = append(frames, fr)
+	}
+	last := frames[len(frames)-1].Position
+	resync, err := readFrame(resp.Body)
+	c.Assert(err, IsNil)
+	c.Assert(resync, DeepEquals, frame{Type: resyncFrame, Position: last + 1})
+	heartbeat, err := readFrame(resp.Body)
+	c.Assert(err, IsNil)
+	c.Assert(heartbeat, DeepEquals, frame{Type: heartbeatFrame, Position: last + 1})
+	resp.Body.Close()
+	primary.Close()
+	executor.Shutdown()
+	executor.FinishAndWait()
+
+	// The stand-in primary serves the snapshot and the frames following it
+	standIn := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == SnapshotPath {
+			w.Header().Set(PositionHeader, strconv.FormatInt(position, 10))
+			w.Write(snapshot)
+			return
+		}
+		from, _ := strconv.ParseInt(r.URL.Query().Get("from"), 10, 64)
+		if from < position {
+			http.Error(w, "gone", http.StatusGone)
+			return
+		}
+		for _, fr := range frames {
+			if fr.Position > from {
+				writeFrame(w, fr)
+			}
+		}
+		writeFrame(w, frame{Type: heartbeatFrame, Position: last})
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer standIn.Close()
+
+	followerDir := c.MkDir()
+	executor.ThisInstance = nil
+	executor.NewInstanceSetup(followerDir, true, true, false)
+	u, _ := url.Parse(standIn.URL)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		NewFollower(u.Host).Run(stop)
+		close(done)
+	}()
+	for i := 0; i < 100; i++ {
+		if p, _ := executor.ReplicationPosition(); p == last {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	close(stop)
+	<-done
+
+	applied, err := executor.ReplicationPosition()
+	c.Assert(err, IsNil)
+	c.Assert(applied, Equals, last)
+
+	q := planner.NewQuery(executor.ThisInstance.CatalogDir)
+	q.AddTargetKey(tbk)
+	q.SetRange(ts.Unix(), next.Unix())
+	parsed, err := q.Parse()
+	c.Assert(err, IsNil)
+	reader, err := executor.NewReader(parsed)
+	c.Assert(err, IsNil)
+	result, err := reader.Read()
+	c.Assert(err, IsNil)
+	c.Assert(result[*tbk].GetEpoch(), DeepEquals, []int64{ts.Unix(), next.Unix()})
+
+	// The follower refuses the writes
+	c.Assert(executor.WriteCSM(csm, false), NotNil)
+}
+
+func (s *ReplicationTestSuite) TestSnapshotLimit(c *C) {
+	// A follower asking for a snapshot while another one is written retries
+	snapshots <- struct{}{}
+	defer func() { <-snapshots }()
+	w := httptest.NewRecorder()
+	SnapshotHandler(w, httptest.NewRequest(http.MethodGet, SnapshotPath, nil))
+	c.Assert(w.Code, Equals, http.StatusServiceUnavailable)
+}
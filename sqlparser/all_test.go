@@ -5,14 +5,18 @@ import (
 
 	"fmt"
 
+	"math"
+
 	"github.com/alpacahq/marketstore/catalog"
 	"github.com/alpacahq/marketstore/executor"
+	"github.com/alpacahq/marketstore/uda/count"
 	"github.com/alpacahq/marketstore/utils/io"
 	. "github.com/alpacahq/marketstore/utils/test"
 
 	"time"
 
 	"reflect"
+	"strings"
 
 	. "gopkg.in/check.v1"
 )
@@ -338,6 +342,802 @@ func (s *TestSuite) TestCount(c *C) {
 	evalAndPrint(c, err, true, stmt)
 }
 
+func (s *TestSuite) TestJoin(c *C) {
+	/*
+		Inner join on Epoch with table aliases
+	*/
+	stmt := "SELECT Epoch, a.Close, b.Close AS BClose FROM `AAPL/1Min/OHLCV` a JOIN `BBPL/1Min/OHLCV` b ON a.Epoch = b.Epoch WHERE Epoch BETWEEN '2000-01-05-12:30' AND '2000-01-05-13:00';"
+	ast, err := NewAstBuilder(stmt)
+	evalAndPrint(c, err, false, stmt)
+	T_PrintExplain(ast.Mtree, stmt)
+	es, err := NewExecutableStatement(ast.Mtree)
+	evalAndPrint(c, err, false, stmt)
+	cs, err := es.Materialize()
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.Len(), Equals, 29)
+	c.Assert(cs.GetColumnNames(), DeepEquals, []string{"Epoch", "a.Close", "BClose"})
+	c.Assert(cs.GetColumn("a.Close"), DeepEquals, cs.GetColumn("BClose"))
+
+	stmt = "SELECT * FROM `AAPL/1Min/OHLCV` a INNER JOIN `BBPL/1Min/OHLCV` b USING (Epoch) WHERE Epoch BETWEEN '2000-01-05-12:30' AND '2000-01-05-13:00' AND b.Volume > 0;"
+	ast, err = NewAstBuilder(stmt)
+	evalAndPrint(c, err, false, stmt)
+	es, err = NewExecutableStatement(ast.Mtree)
+	evalAndPrint(c, err, false, stmt)
+	cs, err = es.Materialize()
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.Len(), Equals, 29)
+	c.Assert(cs.Exists("a.Open"), Equals, true)
+	c.Assert(cs.Exists("b.Volume"), Equals, true)
+
+	/*
+		As-of join, each minute matched with the latest 5 minute bar
+	*/
+	for _, criteria := range []string{"a.Epoch >= b.Epoch", "b.Epoch <= a.Epoch"} {
+		stmt = "SELECT Epoch, a.Close, b.Epoch FROM `AAPL/1Min/OHLCV` a JOIN `AAPL/5Min/OHLCV` b ON " +
+			criteria + " WHERE Epoch BETWEEN '2000-01-05-12:30' AND '2000-01-05-13:00';"
+		ast, err = NewAstBuilder(stmt)
+		evalAndPrint(c, err, false, stmt)
+		es, err = NewExecutableStatement(ast.Mtree)
+		evalAndPrint(c, err, false, stmt)
+		cs, err = es.Materialize()
+		evalAndPrint(c, err, false, stmt)
+		c.Assert(cs.Len(), Equals, 29)
+		epoch := cs.GetEpoch()
+		rightEpoch := cs.GetColumn("b.Epoch").([]int64)
+		for i := range epoch {
+			c.Assert(rightEpoch[i] <= epoch[i], Equals, true)
+			c.Assert(epoch[i]-rightEpoch[i] < 300, Equals, true)
+		}
+	}
+
+	// Strictly previous bar
+	stmt = "SELECT Epoch, b.Epoch FROM `AAPL/1Min/OHLCV` a JOIN `AAPL/5Min/OHLCV` b ON a.Epoch > b.Epoch WHERE Epoch BETWEEN '2000-01-05-12:30' AND '2000-01-05-13:00';"
+	ast, err = NewAstBuilder(stmt)
+	evalAndPrint(c, err, false, stmt)
+	es, err = NewExecutableStatement(ast.Mtree)
+	evalAndPrint(c, err, false, stmt)
+	cs, err = es.Materialize()
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.Len(), Equals, 29)
+	epoch := cs.GetEpoch()
+	rightEpoch := cs.GetColumn("b.Epoch").([]int64)
+	for i := range epoch {
+		c.Assert(rightEpoch[i] < epoch[i], Equals, true)
+		c.Assert(epoch[i]-rightEpoch[i] <= 300, Equals, true)
+	}
+
+	/*
+		As-of joins read the right side rows in the range and the last one
+		before it, there may be none
+	*/
+	stmt = "SELECT Epoch, b.Epoch FROM `AAPL/1Min/OHLCV` a JOIN `AAPL/5Min/OHLCV` b ON a.Epoch >= b.Epoch WHERE Epoch BETWEEN '2000-01-05-12:33' AND '2000-01-05-12:44';"
+	cs, err = materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.Len(), Equals, 10)
+	c.Assert(cs.GetColumn("b.Epoch").([]int64)[0], Equals, cs.GetEpoch()[0]-240)
+
+	stmt = "SELECT Epoch, b.Epoch FROM `AAPL/1Min/OHLCV` a JOIN `AAPL/5Min/OHLCV` b ON a.Epoch >= b.Epoch WHERE Epoch BETWEEN '1999-12-31' AND '2000-01-01-00:10';"
+	cs, err = materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.Len() > 0, Equals, true)
+	epoch = cs.GetEpoch()
+	rightEpoch = cs.GetColumn("b.Epoch").([]int64)
+	for i := range epoch {
+		c.Assert(rightEpoch[i] <= epoch[i], Equals, true)
+		c.Assert(epoch[i]-rightEpoch[i] < 300, Equals, true)
+	}
+
+	/*
+		Join inputs are matched in Epoch order whatever the order of a
+		derived table
+	*/
+	stmt = "SELECT Epoch, a.Close, BClose FROM `AAPL/1Min/OHLCV` a JOIN (SELECT Epoch, Close AS BClose FROM `BBPL/1Min/OHLCV` WHERE Epoch BETWEEN '2000-01-05-12:30' AND '2000-01-05-13:00' ORDER BY Epoch DESC) b ON a.Epoch = b.Epoch WHERE Epoch BETWEEN '2000-01-05-12:30' AND '2000-01-05-13:00';"
+	cs, err = materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.Len(), Equals, 29)
+	c.Assert(cs.GetColumn("a.Close"), DeepEquals, cs.GetColumn("BClose"))
+
+	stmt = "SELECT Epoch, b.Epoch FROM `AAPL/1Min/OHLCV` a JOIN (SELECT Epoch, Close FROM `AAPL/5Min/OHLCV` ORDER BY Epoch DESC) b ON a.Epoch >= b.Epoch WHERE Epoch BETWEEN '2000-01-05-12:30' AND '2000-01-05-13:00';"
+	cs, err = materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.Len(), Equals, 29)
+	epoch = cs.GetEpoch()
+	rightEpoch = cs.GetColumn("b.Epoch").([]int64)
+	for i := range epoch {
+		c.Assert(rightEpoch[i] <= epoch[i], Equals, true)
+		c.Assert(epoch[i]-rightEpoch[i] < 300, Equals, true)
+	}
+
+	/*
+		Unqualified columns resolve to the only joined relation having them
+	*/
+	stmt = "SELECT Epoch, BClose FROM `AAPL/1Min/OHLCV` a JOIN (SELECT Epoch, Close AS BClose FROM `BBPL/1Min/OHLCV`) b ON a.Epoch = b.Epoch WHERE Epoch BETWEEN '2000-01-05-12:30' AND '2000-01-05-13:00' AND BClose > 0;"
+	ast, err = NewAstBuilder(stmt)
+	evalAndPrint(c, err, false, stmt)
+	es, err = NewExecutableStatement(ast.Mtree)
+	evalAndPrint(c, err, false, stmt)
+	cs, err = es.Materialize()
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.Len(), Equals, 29)
+	c.Assert(cs.GetColumnNames(), DeepEquals, []string{"Epoch", "BClose"})
+
+	stmt = "SELECT Epoch, Close FROM `AAPL/1Min/OHLCV` a JOIN `BBPL/1Min/OHLCV` b ON a.Epoch = b.Epoch;"
+	ast, err = NewAstBuilder(stmt)
+	evalAndPrint(c, err, false, stmt)
+	es, err = NewExecutableStatement(ast.Mtree)
+	evalAndPrint(c, err, false, stmt)
+	_, err = es.Materialize()
+	c.Assert(err, NotNil)
+	c.Assert(strings.Contains(err.Error(), "ambiguous"), Equals, true)
+	c.Assert(strings.Contains(err.Error(), "a.Close, b.Close"), Equals, true)
+
+	// The columns of the join are listed once
+	stmt = "SELECT Epoch, a.Foo FROM `AAPL/1Min/OHLCV` a JOIN `BBPL/1Min/OHLCV` b ON a.Epoch = b.Epoch;"
+	ast, err = NewAstBuilder(stmt)
+	evalAndPrint(c, err, false, stmt)
+	es, err = NewExecutableStatement(ast.Mtree)
+	evalAndPrint(c, err, false, stmt)
+	_, err = es.Materialize()
+	c.Assert(err, NotNil)
+	c.Assert(strings.Count(err.Error(), " Epoch:"), Equals, 1)
+
+	/*
+		Alias on a single table
+	*/
+	stmt = "SELECT t.Epoch, t.Close FROM `AAPL/1Min/OHLCV` t WHERE t.Epoch BETWEEN '2000-01-05-12:30' AND '2000-01-05-13:00';"
+	ast, err = NewAstBuilder(stmt)
+	evalAndPrint(c, err, false, stmt)
+	es, err = NewExecutableStatement(ast.Mtree)
+	evalAndPrint(c, err, false, stmt)
+	cs, err = es.Materialize()
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.Len(), Equals, 29)
+	c.Assert(cs.GetColumnNames(), DeepEquals, []string{"Epoch", "Close"})
+
+	/*
+		Unsupported joins
+	*/
+	for _, stmt := range []string{
+		"SELECT * FROM `AAPL/1Min/OHLCV` a JOIN `BBPL/1Min/OHLCV` b ON a.Close = b.Close;",
+		"SELECT * FROM `AAPL/1Min/OHLCV` a LEFT JOIN `BBPL/1Min/OHLCV` b ON a.Epoch = b.Epoch;",
+		"SELECT * FROM `AAPL/1Min/OHLCV` a JOIN `BBPL/1Min/OHLCV` b ON a.Epoch < b.Epoch;",
+		"SELECT * FROM `AAPL/1Min/OHLCV` a JOIN `BBPL/1Min/OHLCV` b ON a.Epoch = c.Epoch;",
+		"SELECT * FROM `AAPL/1Min/OHLCV` JOIN `AAPL/1Min/OHLCV` ON Epoch = Epoch;",
+		"SELECT * FROM `AAPL/1Min/OHLCV` a JOIN `BBPL/1Min/OHLCV` b;",
+		"SELECT * FROM `AAPL/1Min/OHLCV`, `BBPL/1Min/OHLCV`;",
+	} {
+		ast, err = NewAstBuilder(stmt)
+		evalAndPrint(c, err, false, stmt)
+		_, err = NewExecutableStatement(ast.Mtree)
+		evalAndPrint(c, err, true, stmt)
+	}
+}
+
+func (s *TestSuite) TestOrderGroupLimit(c *C) {
+	/*
+		ORDER BY any column, including ones not in the select list
+	*/
+	stmt := "SELECT Epoch, Close FROM `AAPL/1Min/OHLCV` WHERE Epoch BETWEEN '2000-01-05-12:30' AND '2000-01-05-13:00' ORDER BY Close DESC;"
+	cs, err := materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.Len(), Equals, 29)
+	closes := cs.GetColumn("Close").([]float32)
+	for i := 1; i < len(closes); i++ {
+		c.Assert(closes[i-1] >= closes[i], Equals, true)
+	}
+
+	stmt = "SELECT Epoch, Close AS C FROM `AAPL/1Min/OHLCV` WHERE Epoch BETWEEN '2000-01-05-12:30' AND '2000-01-05-13:00' ORDER BY Open, C;"
+	cs, err = materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.Len(), Equals, 29)
+	c.Assert(cs.GetColumnNames(), DeepEquals, []string{"Epoch", "C"})
+
+	/*
+		LIMIT and OFFSET
+	*/
+	stmt = "SELECT Epoch, Close FROM `AAPL/1Min/OHLCV` WHERE Epoch BETWEEN '2000-01-05-12:30' AND '2000-01-05-13:00';"
+	all, err := materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	stmt = "SELECT Epoch, Close FROM `AAPL/1Min/OHLCV` WHERE Epoch BETWEEN '2000-01-05-12:30' AND '2000-01-05-13:00' LIMIT 5 offset 10;"
+	cs, err = materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.Len(), Equals, 5)
+	c.Assert(cs.GetEpoch(), DeepEquals, all.GetEpoch()[10:15])
+
+	stmt = "SELECT Epoch, Close FROM `AAPL/1Min/OHLCV` WHERE Epoch BETWEEN '2000-01-05-12:30' AND '2000-01-05-13:00' ORDER BY Epoch DESC OFFSET 100;"
+	cs, err = materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.Len(), Equals, 0)
+
+	/*
+		GROUP BY a time bucket
+	*/
+	stmt = "SELECT time_bucket('5Min', Epoch) AS Bucket, count(*), max(High) AS MaxHigh FROM `AAPL/1Min/OHLCV` WHERE Epoch BETWEEN '2000-01-05-12:30' AND '2000-01-05-13:00' GROUP BY time_bucket('5Min', Epoch);"
+	cs, err = materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.Len(), Equals, 6)
+	c.Assert(cs.GetColumnNames(), DeepEquals, []string{"Epoch", "Bucket", "Count", "MaxHigh"})
+	c.Assert(cs.GetColumn("Count"), DeepEquals, []int64{4, 5, 5, 5, 5, 5})
+	epoch := cs.GetEpoch()
+	for i := range epoch {
+		c.Assert(epoch[i]%300, Equals, int64(0))
+	}
+
+	stmt = "SELECT count(*) FROM `AAPL/1Min/OHLCV` WHERE Epoch BETWEEN '2000-01-05-12:30' AND '2000-01-05-13:00' GROUP BY time_bucket('5Min', Epoch) ORDER BY Count DESC LIMIT 2;"
+	cs, err = materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.GetColumn("Count"), DeepEquals, []int64{5, 5})
+
+	/*
+		GROUP BY Symbol over a table with several symbols
+	*/
+	stmt = "SELECT Symbol, count(*) FROM `AAPL,BBPL/1Min/OHLCV` WHERE Epoch BETWEEN '2000-01-05-12:30' AND '2000-01-05-13:00' GROUP BY Symbol;"
+	cs, err = materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.GetColumn("Symbol"), DeepEquals, []string{"AAPL", "BBPL"})
+	c.Assert(cs.GetColumn("Count"), DeepEquals, []int64{29, 29})
+
+	stmt = "SELECT Symbol, count(*) FROM `AAPL/1Min/OHLCV` WHERE Epoch BETWEEN '2000-01-05-12:30' AND '2000-01-05-13:00' GROUP BY Symbol;"
+	cs, err = materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.GetColumn("Symbol"), DeepEquals, []string{"AAPL"})
+
+	stmt = "SELECT Epoch, Close, Symbol FROM `AAPL,BBPL/1Min/OHLCV` WHERE Epoch BETWEEN '2000-01-05-12:30' AND '2000-01-05-13:00' ORDER BY Epoch;"
+	cs, err = materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.Len(), Equals, 58)
+	symbols, indexed, ok := IndexSymbols(cs)
+	c.Assert(ok, Equals, true)
+	c.Assert(symbols, DeepEquals, []string{"AAPL", "BBPL"})
+	c.Assert(indexed.GetColumn("Symbol").([]int32)[:2], DeepEquals, []int32{0, 1})
+	c.Assert(ResolveSymbols(symbols, indexed), IsNil)
+	c.Assert(indexed.GetColumn("Symbol"), DeepEquals, cs.GetColumn("Symbol"))
+
+	/*
+		Empty results carry the columns of the select list
+	*/
+	stmt = "SELECT Symbol, count(*) FROM `AAPL/1Min/OHLCV` WHERE Epoch BETWEEN '1990-01-05-12:30' AND '1990-01-05-13:00' GROUP BY Symbol;"
+	cs, err = materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.Len(), Equals, 0)
+	c.Assert(cs.Exists("Symbol"), Equals, true)
+	c.Assert(cs.Exists("Count"), Equals, true)
+
+	stmt = "SELECT Epoch, Close AS C FROM `AAPL/1Min/OHLCV` WHERE Epoch BETWEEN '1990-01-05-12:30' AND '1990-01-05-13:00';"
+	cs, err = materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.Len(), Equals, 0)
+	c.Assert(cs.GetColumnNames(), DeepEquals, []string{"Epoch", "C"})
+
+	/*
+		Unsupported grouping and ordering
+	*/
+	for _, stmt := range []string{
+		"SELECT * FROM `AAPL/1Min/OHLCV` GROUP BY Symbol;",
+		"SELECT Close, count(*) FROM `AAPL/1Min/OHLCV` GROUP BY Symbol;",
+		"SELECT count(*) FROM `AAPL/1Min/OHLCV` GROUP BY time_bucket('5Min');",
+		"SELECT count(*) FROM `AAPL/1Min/OHLCV` GROUP BY time_bucket('5Parsecs', Epoch);",
+		"SELECT count(*) FROM `AAPL/1Min/OHLCV` GROUP BY Symbol HAVING count(*) > 1;",
+		"SELECT Epoch, Close FROM `AAPL/1Min/OHLCV` ORDER BY Nothing;",
+		"SELECT time_bucket('5Min', Epoch) FROM `AAPL/1Min/OHLCV`;",
+	} {
+		_, err = materialize(stmt)
+		evalAndPrint(c, err, true, stmt)
+	}
+}
+
+func (s *TestSuite) TestEpochPushdown(c *C) {
+	start := time.Date(2000, time.January, 5, 12, 30, 0, 0, time.UTC).Unix()
+	end := time.Date(2000, time.January, 5, 13, 0, 0, 0, time.UTC).Unix()
+
+	/*
+		Inclusive bounds keep the boundary rows
+	*/
+	stmt := "SELECT Epoch, Close FROM `AAPL/1Min/OHLCV` WHERE Epoch >= '2000-01-05-12:30' AND Epoch <= '2000-01-05-13:00';"
+	cs, err := materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.Len(), Equals, 31)
+	c.Assert(cs.GetEpoch()[0], Equals, start)
+	c.Assert(cs.GetEpoch()[30], Equals, end)
+
+	stmt = "SELECT Epoch, Close FROM `AAPL/1Min/OHLCV` WHERE Epoch > '2000-01-05-12:30' AND Epoch < '2000-01-05-13:00';"
+	cs, err = materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.Len(), Equals, 29)
+	c.Assert(cs.GetEpoch()[0], Equals, start+60)
+
+	stmt = "SELECT Epoch, Close FROM `AAPL/1Min/OHLCV` WHERE Epoch = '2000-01-05-12:30';"
+	cs, err = materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.GetEpoch(), DeepEquals, []int64{start})
+
+	/*
+		IN lists, alone and combined with a range
+	*/
+	stmt = "SELECT Epoch, Close FROM `AAPL/1Min/OHLCV` WHERE Epoch IN ('2000-01-05-12:30', '2000-01-05-13:00', '2001-03-01');"
+	cs, err = materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.Len(), Equals, 3)
+	c.Assert(cs.GetEpoch()[:2], DeepEquals, []int64{start, end})
+
+	stmt = "SELECT Epoch, Close FROM `AAPL/1Min/OHLCV` WHERE Epoch IN ('2000-01-05-12:30', '2000-01-05-13:00') AND Epoch > '2000-01-05-12:30';"
+	cs, err = materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.GetEpoch(), DeepEquals, []int64{end})
+
+	stmt = "SELECT Epoch, Close FROM `AAPL/1Min/OHLCV` WHERE Epoch IN ('2000-01-05-12:30') AND Epoch IN ('2000-01-05-13:00');"
+	cs, err = materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.Len(), Equals, 0)
+
+	/*
+		Epoch only predicates allow the LIMIT to be pushed down
+	*/
+	stmt = "SELECT Epoch, Close FROM `AAPL/1Min/OHLCV` WHERE Epoch >= '2000-01-05-12:30' LIMIT 3;"
+	cs, err = materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.GetEpoch(), DeepEquals, []int64{start, start + 60, start + 120})
+
+	stmt = "SELECT * FROM `AAPL/1Min/OHLCV` WHERE Epoch IN ('tomorrow');"
+	_, err = materialize(stmt)
+	evalAndPrint(c, err, true, stmt)
+}
+
+func (s *TestSuite) TestRuntimePredicates(c *C) {
+	const inRange = "Epoch BETWEEN '2000-01-05-12:30' AND '2000-01-05-13:00'"
+	all, err := materialize("SELECT * FROM `AAPL/1Min/OHLCV` WHERE " + inRange + ";")
+	c.Assert(err, IsNil)
+	c.Assert(all.Len(), Equals, 29)
+	open := all.GetColumn("Open").([]float32)
+	close := all.GetColumn("Close").([]float32)
+	volume := all.GetColumn("Volume").([]int32)
+
+	count := func(keep func(i int) bool) (n int) {
+		for i := range open {
+			if keep(i) {
+				n++
+			}
+		}
+		return n
+	}
+
+	/*
+		Column to column comparisons, OR and NOT
+	*/
+	stmt := "SELECT * FROM `AAPL/1Min/OHLCV` WHERE " + inRange + " AND Close > Open;"
+	cs, err := materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.Len(), Equals, count(func(i int) bool { return close[i] > open[i] }))
+
+	stmt = "SELECT * FROM `AAPL/1Min/OHLCV` WHERE " + inRange + " AND (Close > Open OR Volume > 50);"
+	cs, err = materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.Len(), Equals, count(func(i int) bool { return close[i] > open[i] || volume[i] > 50 }))
+
+	stmt = "SELECT * FROM `AAPL/1Min/OHLCV` WHERE " + inRange + " AND NOT Close > Open;"
+	cs, err = materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.Len(), Equals, count(func(i int) bool { return !(close[i] > open[i]) }))
+
+	// AND binds tighter than OR
+	stmt = "SELECT * FROM `AAPL/1Min/OHLCV` WHERE Close > Open AND " + inRange + " OR Epoch = '2000-01-05-12:00';"
+	cs, err = materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.Len(), Equals, count(func(i int) bool { return close[i] > open[i] })+1)
+
+	stmt = "SELECT * FROM `AAPL/1Min/OHLCV` WHERE Epoch = '2000-01-05-12:00' OR Close > Open and " + inRange + ";"
+	cs, err = materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.Len(), Equals, count(func(i int) bool { return close[i] > open[i] })+1)
+
+	/*
+		NOT BETWEEN and NOT IN
+	*/
+	stmt = "SELECT * FROM `AAPL/1Min/OHLCV` WHERE " + inRange + " AND Epoch NOT BETWEEN '2000-01-05-12:35' AND '2000-01-05-12:50';"
+	cs, err = materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.Len(), Equals, 29-14)
+
+	stmt = "SELECT * FROM `AAPL/1Min/OHLCV` WHERE " + inRange + " AND Epoch NOT IN ('2000-01-05-12:31', '2000-01-05-12:32');"
+	cs, err = materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.Len(), Equals, 27)
+
+	/*
+		Parenthesized conditions, negated and leading, and exponents
+	*/
+	stmt = "SELECT * FROM `AAPL/1Min/OHLCV` WHERE NOT (Close > Open) AND " + inRange + ";"
+	cs, err = materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.Len(), Equals, count(func(i int) bool { return !(close[i] > open[i]) }))
+
+	stmt = "SELECT * FROM `AAPL/1Min/OHLCV` WHERE (Close > Open OR Volume > 50) AND " + inRange + ";"
+	cs, err = materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.Len(), Equals, count(func(i int) bool { return close[i] > open[i] || volume[i] > 50 }))
+
+	stmt = "SELECT * FROM `AAPL/1Min/OHLCV` WHERE " + inRange + " AND NOT ((Close > Open OR Volume > 50) AND Volume < 5e1);"
+	cs, err = materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.Len(), Equals, count(func(i int) bool { return !((close[i] > open[i] || volume[i] > 50) && volume[i] < 50) }))
+
+	stmt = "SELECT * FROM `AAPL/1Min/OHLCV` WHERE " + inRange + " AND Volume > 5e1;"
+	cs, err = materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.Len(), Equals, count(func(i int) bool { return volume[i] > 50 }))
+
+	/*
+		An Epoch range bounding every OR branch is pushed down
+	*/
+	epochSP := func(stmt string) *StaticPredicate {
+		ast, err := NewAstBuilder(stmt)
+		c.Assert(err, IsNil)
+		es, err := NewExecutableStatement(ast.Mtree)
+		c.Assert(err, IsNil)
+		var node IMSTree = es
+		for {
+			if ex, ok := node.(*ExecutableStatement); ok {
+				if sr, ok := ex.payload.(*SelectRelation); ok {
+					return sr.StaticPredicates["Epoch"]
+				}
+			}
+			c.Assert(node.GetChildCount(), Not(Equals), 0)
+			node = node.GetChild(0)
+		}
+	}
+	stmt = "SELECT * FROM `AAPL/1Min/OHLCV` WHERE (Epoch >= '2000-01-05-12:30' AND Epoch <= '2000-01-05-12:40' AND Close > Open) OR (Epoch >= '2000-01-05-12:50' AND Epoch <= '2000-01-05-13:00');"
+	sp := epochSP(stmt)
+	c.Assert(sp, NotNil)
+	start, end, hasStart, hasEnd, err := sp.EpochRange()
+	c.Assert(err, IsNil)
+	c.Assert(hasStart && hasEnd, Equals, true)
+	c.Assert(start, Equals, time.Date(2000, time.January, 5, 12, 30, 0, 0, time.UTC).Unix())
+	c.Assert(end, Equals, time.Date(2000, time.January, 5, 13, 0, 0, 0, time.UTC).Unix())
+	cs, err = materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	first, err := materialize("SELECT * FROM `AAPL/1Min/OHLCV` WHERE Epoch >= '2000-01-05-12:30' AND Epoch <= '2000-01-05-12:40' AND Close > Open;")
+	c.Assert(err, IsNil)
+	c.Assert(cs.Len(), Equals, first.Len()+11)
+
+	stmt = "SELECT * FROM `AAPL/1Min/OHLCV` WHERE Epoch >= '2000-01-05-12:30' OR Close > Open;"
+	c.Assert(epochSP(stmt), IsNil)
+
+	/*
+		Runtime predicates with joins and string columns
+	*/
+	stmt = "SELECT Epoch FROM `AAPL/1Min/OHLCV` a JOIN `BBPL/1Min/OHLCV` b ON a.Epoch = b.Epoch WHERE " + inRange + " AND a.Close <> b.Open;"
+	_, err = materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+
+	stmt = "SELECT Epoch, Symbol FROM `AAPL,BBPL/1Min/OHLCV` WHERE " + inRange + " AND Symbol = 'BBPL';"
+	cs, err = materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.Len(), Equals, 29)
+
+	for _, stmt := range []string{
+		"SELECT * FROM `AAPL/1Min/OHLCV` WHERE Close > Nothing OR Open > 1;",
+		"SELECT * FROM `AAPL,BBPL/1Min/OHLCV` WHERE Symbol > 1 OR Open > 1;",
+		"SELECT * FROM `AAPL/1Min/OHLCV` WHERE Close OR Open > 1;",
+	} {
+		_, err = materialize(stmt)
+		evalAndPrint(c, err, true, stmt)
+	}
+}
+
+func (s *TestSuite) TestSubqueries(c *C) {
+	const inRange = "Epoch BETWEEN '2000-01-05-12:30' AND '2000-01-05-13:00'"
+
+	/*
+		Derived tables in FROM
+	*/
+	stmt := "SELECT Epoch, t.Close FROM (SELECT * FROM `AAPL/1Min/OHLCV` WHERE " + inRange + ") t WHERE t.Close > 0;"
+	cs, err := materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.Len(), Equals, 29)
+	c.Assert(cs.GetColumnNames(), DeepEquals, []string{"Epoch", "Close"})
+
+	stmt = "SELECT Epoch, a.Close, b.Close AS BClose FROM `AAPL/1Min/OHLCV` a JOIN (SELECT Epoch, Close FROM `BBPL/1Min/OHLCV` WHERE " +
+		inRange + ") b ON a.Epoch = b.Epoch WHERE Epoch > '2000-01-05-12:00';"
+	cs, err = materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.Len(), Equals, 29)
+	c.Assert(cs.GetColumn("a.Close"), DeepEquals, cs.GetColumn("BClose"))
+
+	/*
+		IN (subquery), bars for the symbols whose daily volume exceeded a level
+	*/
+	stmt = "SELECT Epoch, Symbol FROM `AAPL,BBPL,CCPL/1Min/OHLCV` WHERE " + inRange +
+		" AND Symbol IN (SELECT Symbol FROM `AAPL,BBPL/1D/OHLCV` WHERE Volume > 0 GROUP BY Symbol);"
+	cs, err = materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.Len(), Equals, 58)
+	for _, symbol := range cs.GetColumn("Symbol").([]string) {
+		c.Assert(symbol == "AAPL" || symbol == "BBPL", Equals, true)
+	}
+
+	stmt = "SELECT Epoch, Symbol FROM `AAPL,BBPL,CCPL/1Min/OHLCV` WHERE " + inRange +
+		" AND Symbol NOT IN (SELECT Symbol FROM `AAPL,BBPL/1D/OHLCV` GROUP BY Symbol);"
+	cs, err = materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.Len(), Equals, 29)
+
+	stmt = "SELECT * FROM `AAPL/1Min/OHLCV` WHERE Epoch IN (SELECT Epoch FROM `AAPL/5Min/OHLCV` WHERE " + inRange + ");"
+	cs, err = materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.Len(), Equals, 5)
+
+	/*
+		EXISTS (subquery)
+	*/
+	stmt = "SELECT * FROM `AAPL/1Min/OHLCV` WHERE " + inRange + " AND EXISTS (SELECT * FROM `BBPL/1D/OHLCV`);"
+	cs, err = materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.Len(), Equals, 29)
+
+	stmt = "SELECT * FROM `AAPL/1Min/OHLCV` WHERE " + inRange + " AND NOT EXISTS (SELECT * FROM `BBPL/1D/OHLCV`);"
+	cs, err = materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.Len(), Equals, 0)
+
+	stmt = "SELECT * FROM `AAPL/1Min/OHLCV` WHERE EXISTS (SELECT * FROM `BBPL/1D/OHLCV` WHERE Epoch > '2010-01-01') OR " + inRange + ";"
+	cs, err = materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.Len(), Equals, 29)
+
+	for _, stmt := range []string{
+		"SELECT * FROM `AAPL/1Min/OHLCV` WHERE Close IN (SELECT Open, Close FROM `BBPL/1D/OHLCV`);",
+		"SELECT * FROM `AAPL/1Min/OHLCV` WHERE Close IN (SELECT Symbol FROM `AAPL,BBPL/1D/OHLCV` GROUP BY Symbol);",
+		"SELECT * FROM `AAPL/1Min/OHLCV` WHERE EXISTS (SELECT * FROM `NONE/1D/OHLCV`);",
+	} {
+		_, err = materialize(stmt)
+		evalAndPrint(c, err, true, stmt)
+	}
+}
+
+func (s *TestSuite) TestExpressions(c *C) {
+	const inRange = "Epoch BETWEEN '2000-01-05-12:30' AND '2000-01-05-13:00'"
+	all, err := materialize("SELECT * FROM `AAPL/1Min/OHLCV` WHERE " + inRange + ";")
+	c.Assert(err, IsNil)
+	high := all.GetColumn("High").([]float32)
+	low := all.GetColumn("Low").([]float32)
+	close := all.GetColumn("Close").([]float32)
+	volume := all.GetColumn("Volume").([]int32)
+
+	/*
+		Arithmetic, with aliases and generated names
+	*/
+	stmt := "SELECT Epoch, (High + Low) / 2 AS Mid, Volume * 2 - 1, -Close FROM `AAPL/1Min/OHLCV` WHERE " + inRange + ";"
+	cs, err := materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.GetColumnNames(), DeepEquals, []string{"Epoch", "Mid", "_col2", "_col3"})
+	mid := cs.GetColumn("Mid").([]float64)
+	twice := cs.GetColumn("_col2").([]int64)
+	negated := cs.GetColumn("_col3").([]float64)
+	c.Assert(len(mid), Equals, 29)
+	for i := range mid {
+		c.Assert(mid[i], Equals, (float64(high[i])+float64(low[i]))/2)
+		c.Assert(twice[i], Equals, int64(volume[i])*2-1)
+		c.Assert(negated[i], Equals, -float64(close[i]))
+	}
+
+	// Integer division truncates, constants are folded
+	stmt = "SELECT Volume / 2 AS Half, 7 % 4 AS Three FROM `AAPL/1Min/OHLCV` WHERE " + inRange + ";"
+	cs, err = materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.GetColumn("Half").([]int64)[0], Equals, int64(volume[0]/2))
+	c.Assert(cs.GetColumn("Three").([]int64)[0], Equals, int64(3))
+
+	/*
+		Casts and scalar functions
+	*/
+	stmt = "SELECT CAST(Close AS INTEGER) AS C, CAST(Volume AS DOUBLE) AS V, CAST(Volume AS VARCHAR) AS S, " +
+		"abs(Low - High) AS Range, round(log(Close), 2) AS L, coalesce(Close, 0.0) AS Co, date_trunc('hour', Epoch) AS Hour " +
+		"FROM `AAPL/1Min/OHLCV` WHERE " + inRange + " ORDER BY Range DESC;"
+	cs, err = materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.Len(), Equals, 29)
+	_, ok := cs.GetColumn("C").([]int32)
+	c.Assert(ok, Equals, true)
+	_, ok = cs.GetColumn("V").([]float64)
+	c.Assert(ok, Equals, true)
+	_, ok = cs.GetColumn("S").([]string)
+	c.Assert(ok, Equals, true)
+	ranges := cs.GetColumn("Range").([]float64)
+	for i := 1; i < len(ranges); i++ {
+		c.Assert(ranges[i-1] >= ranges[i], Equals, true)
+	}
+	for _, hour := range cs.GetColumn("Hour").([]int64) {
+		c.Assert(hour%3600, Equals, int64(0))
+		c.Assert(time.Unix(hour, 0).UTC().Hour(), Equals, 12)
+	}
+
+	/*
+		Expressions in WHERE
+	*/
+	stmt = "SELECT * FROM `AAPL/1Min/OHLCV` WHERE " + inRange + " AND abs(Close - Close) < 1 AND Volume * 0 = 0;"
+	cs, err = materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.Len(), Equals, 29)
+
+	for _, stmt := range []string{
+		"SELECT Close + Symbol FROM `AAPL,BBPL/1Min/OHLCV`;",
+		"SELECT Volume / 0 FROM `AAPL/1Min/OHLCV` WHERE " + inRange + ";",
+		"SELECT CAST(Close AS ROW(a INTEGER)) FROM `AAPL/1Min/OHLCV`;",
+		"SELECT date_trunc('fortnight', Epoch) FROM `AAPL/1Min/OHLCV` WHERE " + inRange + ";",
+		"SELECT max(Close) + 1 FROM `AAPL/1Min/OHLCV`;",
+		"SELECT Close * 2, count(*) FROM `AAPL/1Min/OHLCV`;",
+		"SELECT Nothing * 2 FROM `AAPL/1Min/OHLCV`;",
+	} {
+		_, err = materialize(stmt)
+		evalAndPrint(c, err, true, stmt)
+	}
+}
+
+func (s *TestSuite) TestWindowFunctions(c *C) {
+	const inRange = "Epoch BETWEEN '2000-01-05-12:30' AND '2000-01-05-13:00'"
+	all, err := materialize("SELECT * FROM `AAPL/1Min/OHLCV` WHERE " + inRange + ";")
+	c.Assert(err, IsNil)
+	close := all.GetColumn("Close").([]float32)
+
+	/*
+		LAG, LEAD and returns
+	*/
+	stmt := "SELECT Epoch, LAG(Close, 1) OVER (ORDER BY Epoch) AS Prev, LEAD(Close, 2, 0) OVER (ORDER BY Epoch) AS Next, " +
+		"Close / LAG(Close) OVER (ORDER BY Epoch) - 1 AS Return FROM `AAPL/1Min/OHLCV` WHERE " + inRange + ";"
+	cs, err := materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.Len(), Equals, 29)
+	prev := cs.GetColumn("Prev").([]float64)
+	next := cs.GetColumn("Next").([]float64)
+	returns := cs.GetColumn("Return").([]float64)
+	c.Assert(math.IsNaN(prev[0]), Equals, true)
+	c.Assert(math.IsNaN(returns[0]), Equals, true)
+	for i := 1; i < len(prev); i++ {
+		c.Assert(prev[i], Equals, float64(close[i-1]))
+		c.Assert(returns[i], Equals, float64(close[i])/float64(close[i-1])-1)
+	}
+	for i := 0; i < len(next)-2; i++ {
+		c.Assert(next[i], Equals, float64(close[i+2]))
+	}
+	c.Assert(next[len(next)-1], Equals, 0.0)
+
+	/*
+		Rolling aggregates over frames
+	*/
+	stmt = "SELECT Epoch, AVG(Close) OVER (ORDER BY Epoch ROWS 2 PRECEDING) AS MA, " +
+		"MAX(Close) OVER (ORDER BY Epoch ROWS BETWEEN 2 PRECEDING AND CURRENT ROW) AS Hi, " +
+		"COUNT(Close) OVER (ROWS BETWEEN 1 PRECEDING AND 1 FOLLOWING) AS N, " +
+		"SUM(Close) OVER (ORDER BY Epoch) AS Cumulative, " +
+		"STDDEV(Close) OVER (ORDER BY Epoch ROWS 2 PRECEDING) AS Vol " +
+		"FROM `AAPL/1Min/OHLCV` WHERE " + inRange + ";"
+	cs, err = materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	ma := cs.GetColumn("MA").([]float64)
+	hi := cs.GetColumn("Hi").([]float64)
+	n := cs.GetColumn("N").([]int64)
+	cumulative := cs.GetColumn("Cumulative").([]float64)
+	vol := cs.GetColumn("Vol").([]float64)
+	var sum float64
+	for i := range close {
+		first := i - 2
+		if first < 0 {
+			first = 0
+		}
+		var frameSum, frameMax float64
+		frameMax = math.Inf(-1)
+		for j := first; j <= i; j++ {
+			frameSum += float64(close[j])
+			frameMax = math.Max(frameMax, float64(close[j]))
+		}
+		frameLen := float64(i - first + 1)
+		mean := frameSum / frameLen
+		c.Assert(math.Abs(ma[i]-mean) < 1e-9, Equals, true)
+		c.Assert(hi[i], Equals, frameMax)
+		if i == 0 {
+			c.Assert(math.IsNaN(vol[i]), Equals, true)
+		} else {
+			var squares float64
+			for j := first; j <= i; j++ {
+				squares += (float64(close[j]) - mean) * (float64(close[j]) - mean)
+			}
+			c.Assert(math.Abs(vol[i]-math.Sqrt(squares/(frameLen-1))) < 1e-6, Equals, true)
+		}
+		sum += float64(close[i])
+		c.Assert(math.Abs(cumulative[i]-sum) < 1e-6, Equals, true)
+	}
+	c.Assert(n[0], Equals, int64(2))
+	c.Assert(n[1], Equals, int64(3))
+	c.Assert(n[len(n)-1], Equals, int64(2))
+
+	/*
+		Partitions, and a LIMIT applied after the window is computed
+	*/
+	stmt = "SELECT Epoch, Symbol, LAG(Close) OVER (PARTITION BY Symbol ORDER BY Epoch) AS Prev FROM `AAPL,BBPL/1Min/OHLCV` WHERE " + inRange + ";"
+	cs, err = materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.Len(), Equals, 58)
+	var missing int
+	for _, value := range cs.GetColumn("Prev").([]float64) {
+		if math.IsNaN(value) {
+			missing++
+		}
+	}
+	c.Assert(missing, Equals, 2)
+
+	stmt = "SELECT Epoch, LEAD(Close) OVER (ORDER BY Epoch) AS Next FROM `AAPL/1Min/OHLCV` WHERE " + inRange + " LIMIT 5;"
+	cs, err = materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.Len(), Equals, 5)
+	c.Assert(cs.GetColumn("Next").([]float64)[4], Equals, float64(close[5]))
+
+	for _, stmt := range []string{
+		"SELECT AVG(Close) OVER (ORDER BY Epoch RANGE 2 PRECEDING) FROM `AAPL/1Min/OHLCV`;",
+		"SELECT Epoch, MEDIAN(Close) OVER (ORDER BY Epoch) FROM `AAPL/1Min/OHLCV`;",
+		"SELECT Epoch, LAG(Close, 'one') OVER (ORDER BY Epoch) FROM `AAPL/1Min/OHLCV` WHERE " + inRange + ";",
+		"SELECT Epoch, LAG(Close) OVER (ORDER BY Nothing) FROM `AAPL/1Min/OHLCV` WHERE " + inRange + ";",
+	} {
+		_, err = materialize(stmt)
+		evalAndPrint(c, err, true, stmt)
+	}
+}
+
+func (s *TestSuite) TestIndicators(c *C) {
+	const inRange = "Epoch BETWEEN '2000-01-05-12:30' AND '2000-01-05-13:00'"
+
+	// Close rises for 14 rows, then drops
+	stmt := "SELECT rsi('5', Close) AS RSI FROM `AAPL/1Min/OHLCV` WHERE " + inRange + ";"
+	cs, err := materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.Len(), Equals, 29)
+	c.Assert(cs.GetColumnNames(), DeepEquals, []string{"Epoch", "RSI"})
+	rsi := cs.GetColumn("RSI").([]float64)
+	for i := 0; i < 5; i++ {
+		c.Assert(math.IsNaN(rsi[i]), Equals, true)
+	}
+	for i := 5; i < 14; i++ {
+		c.Assert(rsi[i], Equals, 100.0)
+	}
+	c.Assert(rsi[14] < 100, Equals, true)
+
+	stmt = "SELECT macd('3', '6', '2', Close) FROM `AAPL/1Min/OHLCV` WHERE " + inRange + ";"
+	cs, err = materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.GetColumnNames(), DeepEquals, []string{"Epoch", "MACD", "Signal", "Histogram"})
+	c.Assert(cs.Len(), Equals, 29)
+
+	stmt = "SELECT atr(High, Low, Close) FROM `AAPL/1Min/OHLCV` WHERE " + inRange + ";"
+	cs, err = materialize(stmt)
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.Len(), Equals, 29)
+
+	for _, stmt := range []string{
+		"SELECT rsi('x', Close) FROM `AAPL/1Min/OHLCV`;",
+		"SELECT macd('26', '12', Close) FROM `AAPL/1Min/OHLCV`;",
+		"SELECT ema(Close) FROM `AAPL/1Min/OHLCV`;",
+	} {
+		_, err = materialize(stmt)
+		evalAndPrint(c, err, true, stmt)
+	}
+}
+
+func (s *TestSuite) TestRegisterAggregate(c *C) {
+	c.Assert(RegisterAggregate("MyCount", &count.Count{}), IsNil)
+	defer delete(AggRegistry, "mycount")
+
+	c.Assert(RegisterAggregate("mycount", &count.Count{}), NotNil)
+	c.Assert(RegisterAggregate("Avg", &count.Count{}), NotNil)
+	c.Assert(RegisterAggregate("", &count.Count{}), NotNil)
+
+	stmt := "SELECT MYCOUNT(*) FROM `AAPL/1Min/OHLCV` WHERE Epoch BETWEEN '2000-01-05-12:30' AND '2000-01-05-13:00';"
+	ast, err := NewAstBuilder(stmt)
+	c.Assert(err, IsNil)
+	es, err := NewExecutableStatement(ast.Mtree)
+	c.Assert(err, IsNil)
+	cs, err := es.Materialize()
+	evalAndPrint(c, err, false, stmt)
+	c.Assert(cs.GetColumn("Count"), DeepEquals, []int64{29})
+}
+
 /*
 Utility functions
 */
@@ -499,7 +1299,7 @@ var otherTestStatements = []TestStmt{
 	{n: 8, stmt: "SELECT * from mytable where a between 2012-10-01 and 2013-11-02;", expectErr: false},
 	{n: 9, stmt: "SELECT * from mytable where a > ALL ( select b from c );", expectErr: false}, // TODO: Subquery not supported, implied Join
 	{n: 10, stmt: "SELECT * from mytable where a in (1, 2, 'Apples');", expectErr: false},
-	{n: 11, stmt: "SELECT * from mytable where a in (select a from b);", expectErr: false}, // TODO: Subquery not supported, implied join
+	{n: 11, stmt: "SELECT * from mytable where a in (select a from b);", expectErr: false},
 	{n: 12, stmt: "SELECT * from mytable where a like 'abc%fine' escape '+' ;", expectErr: false},
 	{n: 13, stmt: "SELECT * from mytable where a is NULL;", expectErr: false},
 	{n: 14, stmt: "SELECT * from mytable where a is distinct from NULL;", expectErr: false},
@@ -509,7 +1309,7 @@ var otherTestStatements = []TestStmt{
 	{n: 16, stmt: "SELECT a AS b, c AS d, d from mytable;", expectErr: false},
 	{n: 17, stmt: "SELECT a from AAPL.`1Min`.OHLCV;", expectErr: false},
 	{n: 18, stmt: "SELECT a from \"AAPL/1Min/OHLCV\";", expectErr: false},
-	{n: 19, stmt: "SELECT a from (select b from (select c from (select d from T)));", expectErr: false}, // TODO: JOIN
+	{n: 19, stmt: "SELECT a from (select b from (select c from (select d from T)));", expectErr: false},
 
 	// JOIN
 	{n: 20, stmt: "SELECT T1.a, T2.b from T1, T2 where T1.a = T2.b;", expectErr: false}, // TODO: JOIN
@@ -532,6 +1332,18 @@ func T_PrintExplain(mtree IMSTree, stmt string) {
 	}
 }
 
+func materialize(stmt string) (cs *io.ColumnSeries, err error) {
+	ast, err := NewAstBuilder(stmt)
+	if err != nil {
+		return nil, err
+	}
+	es, err := NewExecutableStatement(ast.Mtree)
+	if err != nil {
+		return nil, err
+	}
+	return es.Materialize()
+}
+
 func evalAndPrint(c *C, err error, shouldErr bool, msg ...string) {
 	if err != nil {
 		if len(msg) == 0 { // Default is to print only the error
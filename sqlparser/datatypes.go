@@ -43,6 +43,7 @@ const (
 )
 
 type SortItem struct {
+	Name      string
 	Order     SortOrderEnum
 	NullOrder NullOrderEnum
 }
@@ -120,6 +121,8 @@ const (
 	LEFT_OUTER
 	RIGHT_OUTER
 	FULL_OUTER
+	CROSS
+	NATURAL
 )
 
 type StatementTypeEnum uint8
@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"fmt"
 	"reflect"
+	"strings"
 	"time"
 
+	"github.com/alpacahq/marketstore/uda"
 	"github.com/alpacahq/marketstore/utils/io"
 )
 
@@ -169,21 +171,34 @@ func (es *ExecutableStatement) VisitQueryParse(ctx *QueryParse) interface{} {
 	return ctx.queryNoWith
 }
 func (es *ExecutableStatement) VisitQueryNoWithParse(ctx *QueryNoWithParse) interface{} {
-	if ctx.sortItems != nil {
-		// TODO: Support ORDER BY
-		return fmt.Errorf("Unsupported statement type: %s", "Query with ORDER BY")
-	}
-
 	sr := NewSelectRelation()
 	sr.Limit = ctx.limit
+	sr.Offset = ctx.offset
+
+	for _, item := range ctx.sortItems {
+		si := item.(*SortItemParse)
+		cr, ok := es.nodeCursor.Visit(si.expression).(*ColumnReference)
+		if !ok {
+			return fmt.Errorf("Unsupported option: ORDER BY is only supported on columns")
+		}
+		order := si.sortOrdering
+		if order == 0 {
+			order = ASCENDING
+		}
+		sr.OrderBy = append(sr.OrderBy, SortItem{
+			Name:      cr.Value.PrimaryName,
+			Order:     order,
+			NullOrder: si.nullOrdering,
+		})
+	}
 
 	es.nodeCursor.payload = sr // For retrieval of the dynamic type later
 	return ctx.queryTerm
 }
 func (es *ExecutableStatement) VisitQueryTermParse(ctx *QueryTermParse) interface{} {
 	if ctx.queryPrimary == nil {
-		// TODO: Support JOIN
-		return fmt.Errorf("Unsupported statement type: %s", "Join")
+		// TODO: Support set operations
+		return fmt.Errorf("Unsupported statement type: %s", "UNION, INTERSECT or EXCEPT")
 	}
 	return ctx.queryPrimary
 }
@@ -224,7 +239,7 @@ func (es *ExecutableStatement) VisitQuerySpecificationParse(ctx *QuerySpecificat
 	/*
 		Gather Select list
 	*/
-	for _, item := range ctx.selectItems {
+	for position, item := range ctx.selectItems {
 		cctx := item.(*SelectItemParse)
 		if cctx.IsSelectAll {
 			sr.IsSelectAll = true
@@ -254,6 +269,21 @@ func (es *ExecutableStatement) VisitQuerySpecificationParse(ctx *QuerySpecificat
 				ai.IsAliased = true
 			}
 			sr.SelectList = append(sr.SelectList, ai)
+		case *Literal, RuntimeValue:
+			rv, err := newOperand(cr)
+			if err != nil {
+				return err
+			}
+			ai := NewAliasedIdentifier()
+			ai.AddRuntimeExpression(rv)
+			// Unnamed expressions are named by their position
+			ai.Alias = fmt.Sprintf("_col%d", position)
+			if len(aliasName) != 0 {
+				ai.AddAlias(aliasName)
+			}
+			sr.SelectList = append(sr.SelectList, ai)
+		case error:
+			return cr
 		}
 	}
 	if sr.IsSelectAll && len(ctx.selectItems) > 1 {
@@ -263,12 +293,25 @@ func (es *ExecutableStatement) VisitQuerySpecificationParse(ctx *QuerySpecificat
 	/*
 		Gather table references
 	*/
+	if len(ctx.relations) > 1 {
+		return fmt.Errorf("Unsupported option: Multiple relations in FROM, use JOIN ... ON")
+	}
+	var tableAlias string
 	for _, item := range ctx.relations {
 		i_tableName := es.nodeCursor.Visit(item)
 		//fmt.Println("Gathering relations: ", i_tableName, item, reflect.ValueOf(item).Type())
 		switch value := i_tableName.(type) {
 		case string:
 			sr.PrimaryTargetName = append(sr.PrimaryTargetName, value)
+		case *TableReference:
+			sr.PrimaryTargetName = append(sr.PrimaryTargetName, value.Name)
+			tableAlias = value.Alias
+		case *JoinRelation:
+			sr.Join = value
+		case *DerivedTable:
+			sr.IsPrimary = false
+			sr.Subquery = value.Query
+			tableAlias = value.Alias
 		case *SelectRelation:
 			//fmt.Println("Gathered subquery")
 			sr.IsPrimary = false
@@ -289,13 +332,72 @@ func (es *ExecutableStatement) VisitQuerySpecificationParse(ctx *QuerySpecificat
 
 	*/
 	if ctx.where != nil {
-		i_err := es.nodeCursor.Visit(ctx.where) // BooleanExpression
-		if err, ok := i_err.(error); ok {
+		if err := es.visitWhere(sr, ctx.where.(*BooleanExpressionParse)); err != nil {
+			return err
+		}
+	}
+
+	/*
+		Gather the GROUP BY keys
+	*/
+	if ctx.having != nil {
+		return fmt.Errorf("Unsupported option: HAVING")
+	}
+	if ctx.groupBy != nil {
+		if err := es.visitGroupBy(sr, ctx.groupBy.(*GroupByParse)); err != nil {
 			return err
 		}
 	}
+
+	/*
+		Columns of a single aliased table may be qualified by the alias,
+		strip the qualifier so they match the table's column names
+	*/
+	if len(tableAlias) != 0 {
+		sr.UnqualifyColumns(tableAlias)
+	}
 	return nil
 }
+
+/*
+visitGroupBy gathers the grouping expressions, which can be columns or a time
+bucket of the Epoch column:
+
+	GROUP BY Symbol, time_bucket('5Min', Epoch)
+*/
+func (es *ExecutableStatement) visitGroupBy(sr *SelectRelation, ctx *GroupByParse) error {
+	if ctx.setQuantifier == DISTINCT_SET {
+		return fmt.Errorf("Unsupported option: DISTINCT in GROUP BY")
+	}
+	for _, item := range ctx.groupingElements {
+		ge := item.(*GroupingElementParse)
+		if ge.groupingExp == nil {
+			return fmt.Errorf("Unsupported option: ROLLUP, CUBE or GROUPING SETS")
+		}
+		for _, exp := range ge.groupingExp.(*GroupingExpressionsParse).expressions {
+			switch value := es.nodeCursor.Visit(exp).(type) {
+			case *ColumnReference:
+				sr.GroupBy = append(sr.GroupBy, &GroupKey{Name: value.Value.PrimaryName})
+			case *FunctionCallReference:
+				if !isTimeBucket(value) {
+					return fmt.Errorf("Unsupported option: GROUP BY function %s, only time_bucket is supported",
+						value.Name)
+				}
+				gk, err := NewTimeBucketGroupKey(value)
+				if err != nil {
+					return err
+				}
+				sr.GroupBy = append(sr.GroupBy, gk)
+			case error:
+				return value
+			default:
+				return fmt.Errorf("Unsupported option: GROUP BY expression")
+			}
+		}
+	}
+	return nil
+}
+
 func (es *ExecutableStatement) VisitExpressionParse(ctx *ExpressionParse) interface{} {
 	/*
 		1 Child, one of ValueExpression or BooleanExpression
@@ -310,10 +412,72 @@ func (es *ExecutableStatement) VisitValueExpressionParse(ctx *ValueExpressionPar
 	switch cctx := child.(type) {
 	case *PrimaryExpressionParse: // Primary Expression
 		return es.nodeCursor.Visit(cctx)
+	case *ArithmeticBinaryParse, *ArithmeticUnaryParse:
+		return es.nodeCursor.Visit(cctx)
 	default:
-		// TODO: Support non primary expressions
-		return fmt.Errorf("Only Primary Expressions supported")
+		return fmt.Errorf("Unsupported option: AT TIME ZONE and || expressions")
+	}
+}
+func (es *ExecutableStatement) VisitArithmeticBinaryParse(ctx *ArithmeticBinaryParse) interface{} {
+	left, err := newOperand(es.nodeCursor.Visit(ctx.left))
+	if err != nil {
+		return err
+	}
+	right, err := newOperand(es.nodeCursor.Visit(ctx.right))
+	if err != nil {
+		return err
+	}
+	ae := &ArithmeticExpression{Operator: ctx.operator, Left: left, Right: right}
+	if isConstant(left, right) {
+		return foldConstant(ae)
 	}
+	return ae
+}
+func (es *ExecutableStatement) VisitArithmeticUnaryParse(ctx *ArithmeticUnaryParse) interface{} {
+	value, err := newOperand(es.nodeCursor.Visit(ctx.value))
+	if err != nil {
+		return err
+	}
+	if ctx.operator == PLUS {
+		return value
+	}
+	// Negation is a subtraction from zero, which keeps integers as integers
+	ae := &ArithmeticExpression{
+		Operator: MINUS,
+		Left:     &LiteralOperand{Literal: NewLiteral(int64(0), INTEGER_LITERAL)},
+		Right:    value,
+	}
+	if isConstant(value) {
+		return foldConstant(ae)
+	}
+	return ae
+}
+func (es *ExecutableStatement) VisitCastParse(ctx *CastParse) interface{} {
+	value, err := newOperand(es.nodeCursor.Visit(ctx.expression))
+	if err != nil {
+		return err
+	}
+	var typeName string
+	if tt := ctx.type_t.(*TypeTParse); tt.baseType != nil {
+		baseType := tt.baseType.(*BaseTypeParse)
+		switch {
+		case baseType.type_id == DOUBLE_PRECISION:
+			typeName = "DOUBLE"
+		case baseType.GetChildCount() != 0:
+			typeName, _ = es.nodeCursor.Visit(baseType.GetChild(0)).(string)
+		}
+	}
+	if len(typeName) == 0 {
+		return fmt.Errorf("Unsupported option: CAST to a structured type")
+	}
+	ce, err := NewCastExpression(value, typeName)
+	if err != nil {
+		return err
+	}
+	if isConstant(value) {
+		return foldConstant(ce)
+	}
+	return ce
 }
 func (es *ExecutableStatement) VisitPrimaryExpressionParse(ctx *PrimaryExpressionParse) interface{} {
 	/*
@@ -334,12 +498,15 @@ func (es *ExecutableStatement) VisitPrimaryExpressionParse(ctx *PrimaryExpressio
 	case FUNCTION_CALL:
 		retval := es.nodeCursor.Visit(ctx.GetChild(0))
 		switch value := retval.(type) {
-		case *FunctionCallReference:
+		case *FunctionCallReference, RuntimeValue, *Literal, error:
+			// Scalar functions are runtime values, or literals when folded
 			return value
 		default:
 			return fmt.Errorf("Unexpected non FunctionCall returned")
 		}
-	case PARENTHESIZED_EXPRESSION:
+	case DEREFERENCE:
+		return es.nodeCursor.Visit(ctx.GetChild(0))
+	case PARENTHESIZED_EXPRESSION, CAST:
 		return es.nodeCursor.Visit(ctx.GetChild(0))
 	default:
 		// TODO: Support other than column refs
@@ -347,21 +514,107 @@ func (es *ExecutableStatement) VisitPrimaryExpressionParse(ctx *PrimaryExpressio
 			ctx.primaryType.String())
 	}
 }
+func (es *ExecutableStatement) VisitDereferenceParse(ctx *DereferenceParse) interface{} {
+	/*
+		A qualified column reference like "alias.column"
+	*/
+	retval := es.nodeCursor.Visit(ctx.base)
+	base, ok := retval.(*ColumnReference)
+	if !ok {
+		if err, ok := retval.(error); ok {
+			return err
+		}
+		return fmt.Errorf("Only column references can be qualified")
+	}
+	fieldName, ok := es.nodeCursor.Visit(ctx.fieldName).(string)
+	if !ok {
+		return fmt.Errorf("Non string returned as column reference")
+	}
+	return NewColumnReference(base.GetName() + "." + fieldName)
+}
 func (es *ExecutableStatement) VisitIDParse(ctx *IDParse) interface{} {
 	return ctx.name
 }
 func (es *ExecutableStatement) VisitRelationParse(ctx *RelationParse) interface{} {
-	return es.nodeCursor.Visit(ctx.sampled)
+	if ctx.sampled != nil {
+		return es.nodeCursor.Visit(ctx.sampled)
+	}
+	/*
+		JOIN of a left and right relation
+	*/
+	var sources []JoinSource
+	for _, node := range []IMSTree{ctx.left, ctx.right} {
+		switch value := es.nodeCursor.Visit(node).(type) {
+		case string:
+			sources = append(sources, NewTableReference(value, ""))
+		case JoinSource:
+			sources = append(sources, value)
+		case error:
+			return value
+		default:
+			return fmt.Errorf("Unsupported relation type in JOIN")
+		}
+	}
+	jr, err := NewJoinRelation(sources[0], sources[1], ctx.joinType)
+	if err != nil {
+		return err
+	}
+	criteria, ok := ctx.criteria.(*JoinCriteriaParse)
+	if !ok {
+		return fmt.Errorf("JOIN requires an ON or USING (Epoch) clause")
+	}
+	if err = es.visitJoinCriteria(jr, criteria); err != nil {
+		return err
+	}
+	return jr
+}
+func (es *ExecutableStatement) visitJoinCriteria(jr *JoinRelation, ctx *JoinCriteriaParse) error {
+	if ctx.onExpression == nil { // USING (column list)
+		if len(ctx.identifiers) != 1 {
+			return fmt.Errorf("Joins are only supported on the Epoch column")
+		}
+		name, _ := es.nodeCursor.Visit(ctx.identifiers[0]).(string)
+		return jr.SetCriteria(name, name, io.EQ)
+	}
+	/*
+		ON criteria must be a single comparison between two Epoch columns
+	*/
+	be := ctx.onExpression.(*BooleanExpressionParse)
+	var comparison *ComparisonParse
+	if be.predicate != nil && be.right == nil && !be.IsNot {
+		comparison, _ = be.predicate.GetChild(0).(*ComparisonParse)
+	}
+	if comparison == nil {
+		return fmt.Errorf("Join criteria must be a single comparison of Epoch columns")
+	}
+	left, ok := es.nodeCursor.Visit(be.left).(*ColumnReference)
+	if !ok {
+		return fmt.Errorf("Join criteria must be a single comparison of Epoch columns")
+	}
+	right, ok := es.nodeCursor.Visit(comparison.right).(*ColumnReference)
+	if !ok {
+		return fmt.Errorf("Join criteria must be a single comparison of Epoch columns")
+	}
+	return jr.SetCriteria(left.GetName(), right.GetName(), comparison.comparisonOperator)
 }
 func (es *ExecutableStatement) VisitSampledRelationParse(ctx *SampledRelationParse) interface{} {
 	return es.nodeCursor.Visit(ctx.aliasedRelation)
 }
 func (es *ExecutableStatement) VisitAliasedRelationParse(ctx *AliasedRelationParse) interface{} {
 	if ctx.hasAliases {
-		// TODO: Support table aliases
-		return fmt.Errorf("Table Aliases not supported")
+		return fmt.Errorf("Column aliases for tables not supported")
 	}
-	return es.nodeCursor.Visit(ctx.relationPrimary)
+	retval := es.nodeCursor.Visit(ctx.relationPrimary)
+	if ctx.hasID {
+		alias, _ := es.nodeCursor.Visit(ctx.identifier).(string)
+		switch value := retval.(type) {
+		case string:
+			return NewTableReference(value, alias)
+		case *SelectRelation:
+			return NewDerivedTable(value, alias)
+		}
+	}
+	return retval
 }
 func (es *ExecutableStatement) VisitRelationPrimaryParse(ctx *RelationPrimaryParse) interface{} {
 	switch {
@@ -383,22 +636,32 @@ func (es *ExecutableStatement) VisitRelationPrimaryParse(ctx *RelationPrimaryPar
 				return fmt.Errorf("Unable to load subquery")
 			}
 		*/
-		if sr, ok := es.payload.(*SelectRelation); ok {
-			newNode, _ := NewExecutableStatement()
-			retval := QueryWalk(newNode, ctx.GetChild(0).(*QueryParse))
-			if err, ok := retval.(error); ok {
-				return err
-			}
-			// Get the subquery relation
-			if srSub, ok := newNode.payload.(*SelectRelation); ok {
-				sr.Subquery = srSub
-				return srSub
-			}
+		srSub, err := newSubquery(ctx.GetChild(0))
+		if err != nil {
+			return err
 		}
+		return srSub
 	default:
 		return fmt.Errorf("Unsupported Primary Relation type")
 	}
-	return nil
+}
+
+/*
+newSubquery builds the select relation of a nested query, which is
+materialized on its own
+*/
+func newSubquery(query IMSTree) (sr *SelectRelation, err error) {
+	newNode, _ := NewExecutableStatement()
+	retval := QueryWalk(newNode, query)
+	if err, ok := retval.(error); ok {
+		return nil, err
+	}
+	// Get the subquery relation
+	sr, ok := newNode.payload.(*SelectRelation)
+	if !ok {
+		return nil, fmt.Errorf("Unable to load subquery")
+	}
+	return sr, nil
 }
 func (es *ExecutableStatement) VisitQualifiedNameParse(ctx *QualifiedNameParse) interface{} {
 	var buffer bytes.Buffer
@@ -475,14 +738,404 @@ func (es *ExecutableStatement) VisitBooleanExpressionParse(ctx *BooleanExpressio
 	}
 	return nil
 }
+
+/*
+visitWhere splits the WHERE condition into the conjuncts that can be held
+by static predicates, which are pushed down, and a runtime predicate for the
+rest. With any OR at the top level the whole condition is evaluated at runtime,
+and only an Epoch range that holds for every branch is pushed down
+*/
+func (es *ExecutableStatement) visitWhere(sr *SelectRelation, where *BooleanExpressionParse) error {
+	terms, operators := flattenBooleanChain(where)
+	var hasOr bool
+	for _, op := range operators {
+		if op == OR_OP {
+			hasOr = true
+		}
+	}
+
+	var runtimeTerms []RuntimePredicate
+	for i, term := range terms {
+		terms[i] = unwrapTrueComparison(term)
+		rp, err := es.newRuntimeTerm(terms[i])
+		if err != nil {
+			return err
+		}
+		if !hasOr && isStaticEligible(rp) {
+			i_err := es.nodeCursor.Visit(terms[i]) // BooleanExpression
+			if err, ok := i_err.(error); ok {
+				return err
+			}
+			continue
+		}
+		runtimeTerms = append(runtimeTerms, rp)
+	}
+	if hasOr {
+		sr.RuntimePredicate = combineBooleanChain(runtimeTerms, operators)
+		return es.pushDownSharedEpochRange(sr, terms, operators)
+	}
+	for _, rp := range runtimeTerms {
+		if sr.RuntimePredicate == nil {
+			sr.RuntimePredicate = rp
+		} else {
+			sr.RuntimePredicate = &LogicalPredicate{Operator: AND_OP, Left: sr.RuntimePredicate, Right: rp}
+		}
+	}
+	return nil
+}
+
+/*
+pushDownSharedEpochRange computes the Epoch range of each OR branch of a
+flattened chain from its static eligible terms, and pushes down the range
+covering all of them. Nothing is pushed down if a branch does not bound Epoch
+*/
+func (es *ExecutableStatement) pushDownSharedEpochRange(sr *SelectRelation,
+	terms []IMSTree, operators []BinaryOperatorEnum) error {
+	var column *ColumnReference
+	var start, end int64
+	var hasStart, hasEnd bool
+	saved := sr.StaticPredicates
+	defer func() { sr.StaticPredicates = saved }()
+	for first := 0; first < len(terms); {
+		last := first
+		for last < len(operators) && operators[last] == AND_OP {
+			last++
+		}
+		// Visit the terms of the branch into a group of its own
+		sr.StaticPredicates = NewStaticPredicateGroup()
+		for i := first; i <= last; i++ {
+			if err := es.visitStaticConjuncts(terms[i]); err != nil {
+				return err
+			}
+		}
+		sp, ok := sr.StaticPredicates["Epoch"]
+		if !ok {
+			return nil
+		}
+		branchStart, branchEnd, branchHasStart, branchHasEnd, err := sp.EpochRange()
+		if err != nil {
+			return err
+		}
+		if first == 0 {
+			column = sp.Column
+			start, end, hasStart, hasEnd = branchStart, branchEnd, branchHasStart, branchHasEnd
+		} else {
+			if hasStart = hasStart && branchHasStart; hasStart && branchStart < start {
+				start = branchStart
+			}
+			if hasEnd = hasEnd && branchHasEnd; hasEnd && branchEnd > end {
+				end = branchEnd
+			}
+		}
+		first = last + 1
+	}
+	if hasStart {
+		saved.AddComparison(column, io.GTE, start)
+	}
+	if hasEnd {
+		saved.AddComparison(column, io.LTE, end)
+	}
+	return nil
+}
+
+/*
+visitStaticConjuncts adds the static eligible terms of a condition to the
+pending static predicates, descending into parenthesized conjunctions
+*/
+func (es *ExecutableStatement) visitStaticConjuncts(term IMSTree) error {
+	term = unwrapTrueComparison(term)
+	if _, ok := term.(*BooleanExpressionParse); !ok {
+		if inner := unwrapBooleanExpression(term); inner != nil {
+			return es.visitStaticConjuncts(inner)
+		}
+	}
+	if be, ok := term.(*BooleanExpressionParse); ok && be.right != nil && !be.IsNot {
+		terms, operators := flattenBooleanChain(be)
+		for _, op := range operators {
+			if op != AND_OP {
+				return nil
+			}
+		}
+		for _, t := range terms {
+			if err := es.visitStaticConjuncts(t); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	rp, err := es.newRuntimeTerm(term)
+	if err != nil || !isStaticEligible(rp) {
+		return err
+	}
+	if err, ok := es.nodeCursor.Visit(term).(error); ok {
+		return err
+	}
+	return nil
+}
+
+/*
+flattenBooleanChain returns the terms and operators of a chain of AND/OR in
+the order they were written. The grammar nests the chain to the right, so
+"a AND b OR c" parses as "a AND (b OR c)", precedence is applied after
+flattening. Parenthesized conditions are kept as a single term
+*/
+func flattenBooleanChain(be *BooleanExpressionParse) (terms []IMSTree, operators []BinaryOperatorEnum) {
+	if be.right == nil || be.IsNot {
+		return []IMSTree{be}, nil
+	}
+	terms, operators = flattenBooleanChain(be.left.(*BooleanExpressionParse))
+	operators = append(operators, be.operator)
+	var right IMSTree = be.right
+	if ep, ok := be.right.(*ExpressionParse); ok && ep.GetChildCount() != 0 {
+		right = ep.GetChild(0)
+	}
+	if rbe, ok := right.(*BooleanExpressionParse); ok {
+		rightTerms, rightOperators := flattenBooleanChain(rbe)
+		return append(terms, rightTerms...), append(operators, rightOperators...)
+	}
+	return append(terms, right), operators
+}
+
+/*
+combineBooleanChain builds the predicate for a flattened chain, with AND
+taking precedence over OR
+*/
+func combineBooleanChain(terms []RuntimePredicate, operators []BinaryOperatorEnum) (rp RuntimePredicate) {
+	conjunction := terms[0]
+	for i, op := range operators {
+		if op == AND_OP {
+			conjunction = &LogicalPredicate{Operator: AND_OP, Left: conjunction, Right: terms[i+1]}
+			continue
+		}
+		if rp == nil {
+			rp = conjunction
+		} else {
+			rp = &LogicalPredicate{Operator: OR_OP, Left: rp, Right: conjunction}
+		}
+		conjunction = terms[i+1]
+	}
+	if rp == nil {
+		return conjunction
+	}
+	return &LogicalPredicate{Operator: OR_OP, Left: rp, Right: conjunction}
+}
+
+/*
+newRuntimeTerm builds the runtime predicate for a single term of a boolean
+chain, which may be a parenthesized condition
+*/
+func (es *ExecutableStatement) newRuntimeTerm(node IMSTree) (rp RuntimePredicate, err error) {
+	node = unwrapTrueComparison(node)
+	be, ok := node.(*BooleanExpressionParse)
+	if !ok {
+		if be = unwrapBooleanExpression(node); be == nil {
+			return nil, fmt.Errorf("Unsupported expression in WHERE, a condition is required")
+		}
+	}
+	switch {
+	case be.IsNot:
+		inner := *be
+		inner.IsNot = false
+		if rp, err = es.newRuntimeTerm(&inner); err != nil {
+			return nil, err
+		}
+		return &NotPredicate{Predicate: rp}, nil
+	case be.IsLiteral:
+		return &ConstantPredicate{Value: be.value}, nil
+	case be.right != nil:
+		terms, operators := flattenBooleanChain(be)
+		var rps []RuntimePredicate
+		for _, term := range terms {
+			if rp, err = es.newRuntimeTerm(term); err != nil {
+				return nil, err
+			}
+			rps = append(rps, rp)
+		}
+		return combineBooleanChain(rps, operators), nil
+	}
+
+	value, err := es.newRuntimeValue(be.left)
+	if err != nil {
+		return nil, err
+	}
+	switch pred := be.predicate.GetChild(0).(type) {
+	case *ComparisonParse:
+		right, err := es.newRuntimeValue(pred.right)
+		if err != nil {
+			return nil, err
+		}
+		return &ComparisonPredicate{Left: value, Right: right, Operator: pred.comparisonOperator}, nil
+	case *BetweenParse:
+		lower, err := es.newRuntimeValue(pred.lower)
+		if err != nil {
+			return nil, err
+		}
+		upper, err := es.newRuntimeValue(pred.upper)
+		if err != nil {
+			return nil, err
+		}
+		return &BetweenPredicate{Value: value, Lower: lower, Upper: upper, IsNot: pred.IsNot}, nil
+	case *InSubqueryParse:
+		query, err := newSubquery(pred.query)
+		if err != nil {
+			return nil, err
+		}
+		return &InSubqueryPredicate{Value: value, Query: query, IsNot: pred.IsNot}, nil
+	case *InListParse:
+		ip := &InListPredicate{Value: value, IsNot: pred.IsNot}
+		for _, item := range pred.inlist {
+			rv, err := es.newRuntimeValue(item)
+			if err != nil {
+				return nil, err
+			}
+			ip.List = append(ip.List, rv)
+		}
+		return ip, nil
+	case *QuantifiedComparisonParse:
+		return nil, fmt.Errorf("Quantified Comparisons (ALL/ANY/SOME) not supported")
+	}
+	return nil, fmt.Errorf("Unsupported predicate type")
+}
+
+func (es *ExecutableStatement) newRuntimeValue(node IMSTree) (rv RuntimeValue, err error) {
+	if unwrapBooleanExpression(node) != nil {
+		return nil, fmt.Errorf("Unsupported option: conditions as values")
+	}
+	if pe := primaryExpressionOf(node); pe != nil && pe.primaryType == EXISTS {
+		query, err := newSubquery(pe.GetChild(0))
+		if err != nil {
+			return nil, err
+		}
+		return &ExistsOperand{Query: query}, nil
+	}
+	return newOperand(es.nodeCursor.Visit(node))
+}
+
+/*
+unwrapBooleanExpression returns the condition inside a parenthesized
+expression, or nil if the node is not one
+*/
+func unwrapBooleanExpression(node IMSTree) *BooleanExpressionParse {
+	for {
+		switch value := node.(type) {
+		case *BooleanExpressionParse:
+			return value
+		case *ValueExpressionParse, *ExpressionParse:
+			if value.GetChildCount() == 0 {
+				return nil
+			}
+			node = value.GetChild(0)
+		case *PrimaryExpressionParse:
+			if value.primaryType != PARENTHESIZED_EXPRESSION || value.GetChildCount() == 0 {
+				return nil
+			}
+			node = value.GetChild(0)
+		default:
+			return nil
+		}
+	}
+}
+
+/*
+unwrapTrueComparison returns the condition inside "(condition) = TRUE", as
+written by rewriteConditions, or the node itself if it is not one
+*/
+func unwrapTrueComparison(node IMSTree) IMSTree {
+	be, ok := node.(*BooleanExpressionParse)
+	if !ok {
+		if ep, isExpr := node.(*ExpressionParse); isExpr && ep.GetChildCount() != 0 {
+			be, ok = ep.GetChild(0).(*BooleanExpressionParse)
+		}
+	}
+	if !ok || be.right != nil || be.IsNot || be.IsLiteral || be.predicate == nil {
+		return node
+	}
+	comparison, ok := be.predicate.GetChild(0).(*ComparisonParse)
+	if !ok || comparison.comparisonOperator != io.EQ {
+		return node
+	}
+	if pe := primaryExpressionOf(comparison.right); pe == nil || pe.primaryType != BOOLEAN_LITERAL ||
+		pe.payload != true {
+		return node
+	}
+	if inner := unwrapBooleanExpression(be.left); inner != nil {
+		return unwrapTrueComparison(inner)
+	}
+	return node
+}
+
+func primaryExpressionOf(node IMSTree) *PrimaryExpressionParse {
+	for {
+		switch value := node.(type) {
+		case *PrimaryExpressionParse:
+			return value
+		case *ValueExpressionParse, *ExpressionParse:
+			if value.GetChildCount() == 0 {
+				return nil
+			}
+			node = value.GetChild(0)
+		default:
+			return nil
+		}
+	}
+}
+
+/*
+isStaticEligible returns true for a comparison of a column with literals
+that a static predicate can hold. Strings are only read as dates for Epoch
+*/
+func isStaticEligible(rp RuntimePredicate) bool {
+	var value RuntimeValue
+	var bounds []RuntimeValue
+	switch pred := rp.(type) {
+	case *ComparisonPredicate:
+		if pred.Operator == io.NEQ {
+			return false
+		}
+		value, bounds = pred.Left, []RuntimeValue{pred.Right}
+	case *BetweenPredicate:
+		if pred.IsNot {
+			return false
+		}
+		value, bounds = pred.Value, []RuntimeValue{pred.Lower, pred.Upper}
+	case *InListPredicate:
+		if pred.IsNot {
+			return false
+		}
+		value, bounds = pred.Value, pred.List
+	default:
+		return false
+	}
+	column, ok := value.(*ColumnOperand)
+	if !ok {
+		return false
+	}
+	for _, bound := range bounds {
+		literal, ok := bound.(*LiteralOperand)
+		if !ok {
+			return false
+		}
+		switch literal.Literal.Type {
+		case INTEGER_LITERAL, DECIMAL_LITERAL:
+		case STRING_LITERAL:
+			if column.Name != "Epoch" {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
 func (es *ExecutableStatement) VisitPredicateParse(ctx *PredicateParse) interface{} {
 	node := ctx.GetChild(0)
 	switch node.(type) {
-	case *ComparisonParse, *BetweenParse:
+	case *ComparisonParse, *BetweenParse, *InListParse:
 		return es.nodeCursor.Visit(node)
 	case *QuantifiedComparisonParse:
 		return fmt.Errorf("Quantified Comparisons (ALL/ANY/SOME) not supported")
-	case *InListParse, *InSubqueryParse, *LikeParse, *NullPredicateParse, *DistinctFromParse:
+	case *InSubqueryParse, *LikeParse, *NullPredicateParse, *DistinctFromParse:
 		// TODO: Implement dynamic predicates (and inlist)
 		return fmt.Errorf("Unsupported predicate type, only static types are supported")
 	}
@@ -490,6 +1143,10 @@ func (es *ExecutableStatement) VisitPredicateParse(ctx *PredicateParse) interfac
 }
 
 func (es *ExecutableStatement) VisitBetweenParse(ctx *BetweenParse) interface{} {
+	if ctx.IsNot {
+		// A disjunction, which static predicates can not hold, see isStaticEligible
+		return fmt.Errorf("Unsupported predicate type: %s", "NOT BETWEEN")
+	}
 	i_literal := es.nodeCursor.Visit(ctx.lower)
 	if literal, ok := i_literal.(*Literal); !ok {
 		return fmt.Errorf("Dynamic predicate bounds not supported")
@@ -501,11 +1158,7 @@ func (es *ExecutableStatement) VisitBetweenParse(ctx *BetweenParse) interface{}
 		if err != nil {
 			return err
 		}
-		if ctx.IsNot {
-			es.nodeCursor.pendingSP.AddComparison(io.LTE, literal.Value)
-		} else {
-			es.nodeCursor.pendingSP.AddComparison(io.GT, literal.Value)
-		}
+		es.nodeCursor.pendingSP.AddComparison(io.GT, literal.Value)
 	}
 
 	i_literal = es.nodeCursor.Visit(ctx.upper)
@@ -519,12 +1172,31 @@ func (es *ExecutableStatement) VisitBetweenParse(ctx *BetweenParse) interface{}
 		if err != nil {
 			return err
 		}
-		if ctx.IsNot {
-			es.nodeCursor.pendingSP.AddComparison(io.GTE, literal.Value)
-		} else {
-			es.nodeCursor.pendingSP.AddComparison(io.LT, literal.Value)
+		es.nodeCursor.pendingSP.AddComparison(io.LT, literal.Value)
+	}
+	return nil
+}
+
+func (es *ExecutableStatement) VisitInListParse(ctx *InListParse) interface{} {
+	if ctx.IsNot {
+		// A disjunction, which static predicates can not hold, see isStaticEligible
+		return fmt.Errorf("Unsupported predicate type: %s", "NOT IN")
+	}
+	var inlist []interface{}
+	for _, item := range ctx.inlist {
+		literal, ok := es.nodeCursor.Visit(item).(*Literal)
+		if !ok {
+			return fmt.Errorf("Dynamic predicate bounds not supported")
 		}
+		/*
+			Make sure that the literal represents a numeric quantity
+		*/
+		if err := CoerceToNumeric(literal); err != nil {
+			return err
+		}
+		inlist = append(inlist, literal.Value)
 	}
+	es.nodeCursor.pendingSP.AddInlist(inlist)
 	return nil
 }
 
@@ -552,6 +1224,32 @@ func (es *ExecutableStatement) VisitFunctionCallParse(ctx *FunctionCallParse) in
 		return fmt.Errorf("Error parsing function name")
 	}
 
+	if ctx.over != nil {
+		return es.newWindowFunction(name, ctx)
+	}
+
+	/*
+		Scalar functions compute a value for each row, other names are
+		aggregates looked up when the relation is materialized
+	*/
+	if function, ok := ScalarRegistry[strings.ToLower(name)]; ok {
+		if ctx.hasAsterisk {
+			return fmt.Errorf("%s does not take *", name)
+		}
+		sf := &ScalarFunctionCall{Name: name, Function: function}
+		for _, expr := range ctx.expressionList {
+			arg, err := newOperand(es.nodeCursor.Visit(expr))
+			if err != nil {
+				return err
+			}
+			sf.Args = append(sf.Args, arg)
+		}
+		if isConstant(sf.Args...) {
+			return foldConstant(sf)
+		}
+		return sf
+	}
+
 	var args []interface{}
 	if ctx.hasAsterisk {
 		fc := NewFunctionCallReference(name, args)
@@ -572,6 +1270,114 @@ func (es *ExecutableStatement) VisitFunctionCallParse(ctx *FunctionCallParse) in
 	return NewFunctionCallReference(name, args)
 }
 
+/*
+newWindowFunction builds a call to a window function from its arguments and
+the partitions, order and frame of the OVER clause
+*/
+func (es *ExecutableStatement) newWindowFunction(name string, ctx *FunctionCallParse) interface{} {
+	function, ok := WindowRegistry[strings.ToLower(name)]
+	if !ok {
+		return fmt.Errorf("No window function named \"%s\"", name)
+	}
+	if ctx.hasAsterisk || ctx.hasFilter || ctx.hasSetQuantifier {
+		return fmt.Errorf("Unsupported option: *, FILTER or DISTINCT in window function %s", name)
+	}
+	wf := &WindowFunctionCall{Name: name, Function: function}
+	for _, expr := range ctx.expressionList {
+		arg, err := newOperand(es.nodeCursor.Visit(expr))
+		if err != nil {
+			return err
+		}
+		wf.Args = append(wf.Args, arg)
+	}
+
+	over := ctx.over.(*OverParse)
+	for _, expr := range over.partitions {
+		rv, err := newOperand(es.nodeCursor.Visit(expr))
+		if err != nil {
+			return err
+		}
+		wf.PartitionBy = append(wf.PartitionBy, rv)
+	}
+	for _, item := range over.sortItems {
+		si := item.(*SortItemParse)
+		cr, ok := es.nodeCursor.Visit(si.expression).(*ColumnReference)
+		if !ok {
+			return fmt.Errorf("Unsupported option: window ORDER BY is only supported on columns")
+		}
+		order := si.sortOrdering
+		if order == 0 {
+			order = ASCENDING
+		}
+		wf.OrderBy = append(wf.OrderBy, SortItem{Name: cr.GetName(), Order: order})
+	}
+
+	/*
+		Without a frame, ordered windows run from the start of the partition
+		to the current row and unordered windows cover the partition
+	*/
+	wf.Frame = uda.NewDefaultWindowFrame()
+	if len(wf.OrderBy) == 0 {
+		wf.Frame.UnboundedFollowing = true
+	}
+	if over.GetChildCount() != 0 {
+		frame, err := es.newWindowFrame(over.GetChild(0).(*WindowFrameParse))
+		if err != nil {
+			return err
+		}
+		wf.Frame = frame
+	}
+	return wf
+}
+
+/*
+newWindowFrame reads a ROWS frame. RANGE frames are the same as ROWS without
+peer rows, so only their unbounded and current row forms are supported
+*/
+func (es *ExecutableStatement) newWindowFrame(ctx *WindowFrameParse) (frame uda.WindowFrame, err error) {
+	bounds := make([]*FrameBoundParse, ctx.GetChildCount())
+	for i := range bounds {
+		bounds[i] = ctx.GetChild(i).(*FrameBoundParse)
+	}
+	if !ctx.IsBetween {
+		// A single bound is the start of a frame ending at the current row
+		bounds = append(bounds, &FrameBoundParse{IsCurrentRow: true})
+	}
+	for i, bound := range bounds {
+		var rows int
+		switch {
+		case bound.IsUnbounded:
+		case bound.IsCurrentRow:
+		default:
+			if ctx.IsRange {
+				return frame, fmt.Errorf("Unsupported option: RANGE frames with offsets, use ROWS")
+			}
+			literal, ok := es.nodeCursor.Visit(bound.GetChild(0)).(*Literal)
+			if !ok || literal.Type != INTEGER_LITERAL || literal.Value.(int64) < 0 {
+				return frame, fmt.Errorf("Window frame offsets must be non negative integers")
+			}
+			rows = int(literal.Value.(int64))
+		}
+		if bound.IsPreceding {
+			rows = -rows
+		}
+		if i == 0 {
+			if bound.IsUnbounded && bound.IsFollowing {
+				return frame, fmt.Errorf("A window frame can not start with UNBOUNDED FOLLOWING")
+			}
+			frame.UnboundedPreceding = bound.IsUnbounded
+			frame.Preceding = -rows
+		} else {
+			if bound.IsUnbounded && bound.IsPreceding {
+				return frame, fmt.Errorf("A window frame can not end with UNBOUNDED PRECEDING")
+			}
+			frame.UnboundedFollowing = bound.IsUnbounded
+			frame.Following = rows
+		}
+	}
+	return frame, nil
+}
+
 /*
 Primary Expression Datatypes
 */
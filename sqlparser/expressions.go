@@ -0,0 +1,375 @@
+package sqlparser
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/alpacahq/marketstore/utils/io"
+)
+
+/*
+Expressions are runtime values computed row by row from columns and
+literals, as in:
+
+	SELECT Epoch, (High + Low) / 2 AS Mid, round(log(Close), 2) FROM ...
+
+When all operands are literals the result is a *Literal, which lets
+constant expressions be folded when the statement is built
+*/
+
+/*
+ArithmeticExpression applies +, -, *, / or % to two values. Integer operands
+produce int64 values, with integer division, otherwise the result is float64
+*/
+type ArithmeticExpression struct {
+	Operator    ArithmeticOperatorEnum
+	Left, Right RuntimeValue
+}
+
+func (ae *ArithmeticExpression) Evaluate(cs *io.ColumnSeries) (interface{}, error) {
+	left, err := ae.Left.Evaluate(cs)
+	if err != nil {
+		return nil, err
+	}
+	right, err := ae.Right.Evaluate(cs)
+	if err != nil {
+		return nil, err
+	}
+	lv, err := newNumericVector(left, "arithmetic")
+	if err != nil {
+		return nil, err
+	}
+	rv, err := newNumericVector(right, "arithmetic")
+	if err != nil {
+		return nil, err
+	}
+	length := vectorLength(cs, lv, rv)
+
+	if lv.kind == intVector && rv.kind == intVector {
+		ints := make([]int64, length)
+		for i := range ints {
+			l, r := lv.ints[lv.index(i)], rv.ints[rv.index(i)]
+			switch ae.Operator {
+			case PLUS:
+				ints[i] = l + r
+			case MINUS:
+				ints[i] = l - r
+			case MULTIPLY:
+				ints[i] = l * r
+			case DIVIDE, PERCENT:
+				if r == 0 {
+					return nil, fmt.Errorf("Division by zero")
+				}
+				if ae.Operator == DIVIDE {
+					ints[i] = l / r
+				} else {
+					ints[i] = l % r
+				}
+			}
+		}
+		return numericResult(ints, nil, lv.isScalar && rv.isScalar), nil
+	}
+
+	floats := make([]float64, length)
+	for i := range floats {
+		l, r := lv.float(i), rv.float(i)
+		switch ae.Operator {
+		case PLUS:
+			floats[i] = l + r
+		case MINUS:
+			floats[i] = l - r
+		case MULTIPLY:
+			floats[i] = l * r
+		case DIVIDE:
+			floats[i] = l / r
+		case PERCENT:
+			floats[i] = math.Mod(l, r)
+		}
+	}
+	return numericResult(nil, floats, lv.isScalar && rv.isScalar), nil
+}
+
+func (ae *ArithmeticExpression) mapColumns(mapName func(string) string) {
+	ae.Left.mapColumns(mapName)
+	ae.Right.mapColumns(mapName)
+}
+
+/*
+CastExpression converts a value to one of the column types
+*/
+type CastExpression struct {
+	Value RuntimeValue
+	Type  io.EnumElementType
+}
+
+/*
+castTypes maps SQL type names to the column type they cast to
+*/
+var castTypes = map[string]io.EnumElementType{
+	"TINYINT":  io.BYTE,
+	"SMALLINT": io.INT16,
+	"INT":      io.INT32,
+	"INTEGER":  io.INT32,
+	"BIGINT":   io.INT64,
+	"REAL":     io.FLOAT32,
+	"FLOAT":    io.FLOAT32,
+	"DOUBLE":   io.FLOAT64,
+	"VARCHAR":  io.STRING,
+	"BOOLEAN":  io.BOOL,
+}
+
+func NewCastExpression(value RuntimeValue, typeName string) (*CastExpression, error) {
+	castType, ok := castTypes[strings.ToUpper(typeName)]
+	if !ok {
+		return nil, fmt.Errorf("Unsupported option: CAST to %s", typeName)
+	}
+	return &CastExpression{Value: value, Type: castType}, nil
+}
+
+func (ce *CastExpression) Evaluate(cs *io.ColumnSeries) (interface{}, error) {
+	value, err := ce.Value.Evaluate(cs)
+	if err != nil {
+		return nil, err
+	}
+	vv, err := newValueVector(value)
+	if err != nil {
+		return nil, err
+	}
+	length := vectorLength(cs, vv)
+
+	/*
+		Strings are parsed as numbers, numbers are formatted from the
+		source column so the float32 values keep their short form
+	*/
+	var ints []int64
+	var floats []float64
+	switch {
+	case ce.Type == io.STRING:
+		strs := make([]string, length)
+		for i := range strs {
+			switch {
+			case vv.kind == stringVector:
+				strs[i] = vv.strs[vv.index(i)]
+			case vv.isScalar:
+				strs[i] = fmt.Sprint(value.(*Literal).Value)
+			default:
+				strs[i] = fmt.Sprint(reflect.ValueOf(value).Index(i).Interface())
+			}
+		}
+		if vv.isScalar {
+			return NewLiteral("'"+strs[0]+"'", STRING_LITERAL), nil
+		}
+		return strs, nil
+	case vv.kind == stringVector:
+		floats = make([]float64, length)
+		for i := range floats {
+			str := strings.TrimSpace(vv.strs[vv.index(i)])
+			if floats[i], err = strconv.ParseFloat(str, 64); err != nil {
+				return nil, fmt.Errorf("Unable to cast \"%s\" to a number", str)
+			}
+		}
+	case vv.kind == intVector:
+		ints = vv.ints
+	default:
+		floats = vv.floats
+	}
+	if ce.Type == io.BOOL {
+		bools := make([]bool, length)
+		for i := range bools {
+			if ints != nil {
+				bools[i] = ints[vv.index(i)] != 0
+			} else {
+				bools[i] = floats[vv.index(i)] != 0
+			}
+		}
+		if vv.isScalar {
+			return NewLiteral(bools[0], BOOLEAN_LITERAL), nil
+		}
+		return bools, nil
+	}
+
+	if vv.isScalar {
+		if ce.Type == io.FLOAT32 || ce.Type == io.FLOAT64 {
+			if ints != nil {
+				return NewLiteral(float64(ints[0]), DECIMAL_LITERAL), nil
+			}
+			return NewLiteral(floats[0], DECIMAL_LITERAL), nil
+		}
+		if ints != nil {
+			return NewLiteral(ints[0], INTEGER_LITERAL), nil
+		}
+		return NewLiteral(int64(floats[0]), INTEGER_LITERAL), nil
+	}
+	column := reflect.MakeSlice(reflect.SliceOf(ce.Type.TypeOf()), length, length)
+	for i := 0; i < length; i++ {
+		if ints != nil {
+			column.Index(i).Set(reflect.ValueOf(ints[i]).Convert(column.Type().Elem()))
+		} else {
+			column.Index(i).Set(reflect.ValueOf(floats[i]).Convert(column.Type().Elem()))
+		}
+	}
+	return column.Interface(), nil
+}
+
+func (ce *CastExpression) mapColumns(mapName func(string) string) {
+	ce.Value.mapColumns(mapName)
+}
+
+/*
+ScalarFunctionCall is a call to a function in the ScalarRegistry
+*/
+type ScalarFunctionCall struct {
+	Name     string
+	Function ScalarFunction
+	Args     []RuntimeValue
+}
+
+func (sf *ScalarFunctionCall) Evaluate(cs *io.ColumnSeries) (interface{}, error) {
+	args := make([]interface{}, len(sf.Args))
+	for i, arg := range sf.Args {
+		value, err := arg.Evaluate(cs)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = value
+	}
+	result, err := sf.Function(cs, args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", sf.Name, err.Error())
+	}
+	return result, nil
+}
+
+func (sf *ScalarFunctionCall) mapColumns(mapName func(string) string) {
+	for _, arg := range sf.Args {
+		arg.mapColumns(mapName)
+	}
+}
+
+/*
+newOperand converts a visited expression into a runtime value
+*/
+func newOperand(value interface{}) (RuntimeValue, error) {
+	switch operand := value.(type) {
+	case *ColumnReference:
+		return &ColumnOperand{Name: operand.GetName()}, nil
+	case *Literal:
+		return &LiteralOperand{Literal: operand}, nil
+	case RuntimeValue:
+		return operand, nil
+	case *FunctionCallReference:
+		return nil, fmt.Errorf("Aggregate function %s can not be used in an expression", operand.Name)
+	case error:
+		return nil, operand
+	}
+	return nil, fmt.Errorf("Unsupported expression")
+}
+
+/*
+foldConstant evaluates an expression of literals to a single literal
+*/
+func foldConstant(rv RuntimeValue) interface{} {
+	value, err := rv.Evaluate(nil)
+	if err != nil {
+		return err
+	}
+	return value
+}
+
+func isConstant(operands ...RuntimeValue) bool {
+	for _, operand := range operands {
+		if _, ok := operand.(*LiteralOperand); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+RuntimeValueColumns returns the names of the columns used by a value
+*/
+func RuntimeValueColumns(rv RuntimeValue) (names []string) {
+	rv.mapColumns(func(name string) string {
+		names = append(names, name)
+		return name
+	})
+	return names
+}
+
+/*
+literalColumn repeats a literal value to fill a column
+*/
+func literalColumn(literal *Literal, length int) (interface{}, error) {
+	vv, err := newValueVector(literal)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case literal.Type == BOOLEAN_LITERAL:
+		bools := make([]bool, length)
+		for i := range bools {
+			bools[i] = vv.ints[0] != 0
+		}
+		return bools, nil
+	case vv.kind == stringVector:
+		strs := make([]string, length)
+		for i := range strs {
+			strs[i] = vv.strs[0]
+		}
+		return strs, nil
+	case vv.kind == intVector:
+		ints := make([]int64, length)
+		for i := range ints {
+			ints[i] = vv.ints[0]
+		}
+		return ints, nil
+	}
+	floats := make([]float64, length)
+	for i := range floats {
+		floats[i] = vv.floats[0]
+	}
+	return floats, nil
+}
+
+func newNumericVector(value interface{}, usage string) (*valueVector, error) {
+	vv, err := newValueVector(value)
+	if err != nil {
+		return nil, err
+	}
+	if vv.kind == stringVector {
+		return nil, fmt.Errorf("Numeric values are required for %s", usage)
+	}
+	return vv, nil
+}
+
+/*
+vectorLength is the number of rows of a result, a single row when all the
+operands are scalars
+*/
+func vectorLength(cs *io.ColumnSeries, vectors ...*valueVector) int {
+	for _, vv := range vectors {
+		if !vv.isScalar {
+			return cs.Len()
+		}
+	}
+	return 1
+}
+
+/*
+numericResult returns the values as a column, or as a literal when all the
+operands were scalars
+*/
+func numericResult(ints []int64, floats []float64, isScalar bool) interface{} {
+	switch {
+	case isScalar && ints != nil:
+		return NewLiteral(ints[0], INTEGER_LITERAL)
+	case isScalar:
+		return NewLiteral(floats[0], DECIMAL_LITERAL)
+	case ints != nil:
+		return ints
+	}
+	return floats
+}
@@ -0,0 +1,315 @@
+package sqlparser
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alpacahq/marketstore/utils"
+	"github.com/alpacahq/marketstore/utils/io"
+)
+
+/*
+GroupKey is one expression in a GROUP BY clause, either a column such as
+Symbol, or a time bucket of the Epoch column like time_bucket('5Min', Epoch)
+*/
+type GroupKey struct {
+	Name      string
+	Timeframe string // Set for time buckets
+}
+
+func (gk *GroupKey) IsTimeBucket() bool {
+	return len(gk.Timeframe) != 0
+}
+
+/*
+NewTimeBucketGroupKey validates a time_bucket() function call and returns
+the matching group key
+*/
+func NewTimeBucketGroupKey(fc *FunctionCallReference) (gk *GroupKey, err error) {
+	literals := fc.GetLiterals()
+	ids := fc.GetIDs()
+	if len(literals) != 1 || len(ids) != 1 || ids[0] != "Epoch" {
+		return nil, fmt.Errorf("time_bucket requires a timeframe and the Epoch column, as in time_bucket('5Min', Epoch)")
+	}
+	tf, ok := literals[0].Value.(string)
+	if !ok || literals[0].Type != STRING_LITERAL {
+		return nil, fmt.Errorf("time_bucket timeframe must be a string like '5Min'")
+	}
+	tf = tf[1 : len(tf)-1] // Strip the quotes
+	if utils.CandleDurationFromString(tf) == nil {
+		return nil, fmt.Errorf("Invalid time_bucket timeframe: %s", tf)
+	}
+	return &GroupKey{Name: "Epoch", Timeframe: tf}, nil
+}
+
+func isTimeBucket(fc *FunctionCallReference) bool {
+	return strings.EqualFold(fc.Name, "time_bucket")
+}
+
+/*
+groupAndAggregate splits the input rows into groups by the GROUP BY keys and
+runs the select list aggregates over each group. The output has one row per
+group, ordered by the group keys. The Epoch of a row is the time bucket
+when grouping by time, otherwise the latest Epoch in the group
+*/
+func (sr *SelectRelation) groupAndAggregate(input *io.ColumnSeries) (cs *io.ColumnSeries, err error) {
+	if sr.IsSelectAll {
+		return nil, fmt.Errorf("SELECT * can not be used with GROUP BY")
+	}
+	/*
+		Compute the key values of each row
+	*/
+	epoch := input.GetEpoch()
+	keyColumns := make([]interface{}, len(sr.GroupBy))
+	for i, gk := range sr.GroupBy {
+		if gk.IsTimeBucket() {
+			cd := utils.CandleDurationFromString(gk.Timeframe)
+			buckets := make([]int64, len(epoch))
+			for j, ep := range epoch {
+				t := time.Unix(ep, 0).In(utils.InstanceConfig.Timezone)
+				buckets[j] = cd.Truncate(t).Unix()
+			}
+			keyColumns[i] = buckets
+		} else {
+			if !input.Exists(gk.Name) {
+				return nil, fmt.Errorf("GROUP BY column %s not found", gk.Name)
+			}
+			keyColumns[i] = input.GetColumn(gk.Name)
+		}
+	}
+
+	/*
+		Gather the row indexes of each group
+	*/
+	groups := groupRows(keyColumns, input.Len())
+	sort.SliceStable(groups, func(i, j int) bool {
+		for _, col := range keyColumns {
+			cv := reflect.ValueOf(col)
+			if c := compareValues(cv.Index(groups[i][0]), cv.Index(groups[j][0])); c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+
+	/*
+		Output the group keys, then the aggregates evaluated over each group
+	*/
+	firstRows := make([]int, len(groups))
+	epochOut := make([]int64, len(groups))
+	for g, rows := range groups {
+		firstRows[g] = rows[0]
+		epochOut[g] = epoch[rows[len(rows)-1]]
+	}
+	keyValues := make(map[string]interface{})
+	for i, gk := range sr.GroupBy {
+		values := gatherColumn(keyColumns[i], firstRows)
+		if gk.IsTimeBucket() {
+			epochOut = values.([]int64)
+			continue
+		}
+		keyValues[gk.Name] = values
+	}
+
+	cs = io.NewColumnSeries()
+	cs.AddColumn("Epoch", epochOut)
+	for _, sl := range sr.SelectList {
+		switch {
+		case sl.IsFunctionCall && isTimeBucket(sl.FunctionCall):
+			if sl.IsAliased {
+				cs.AddColumn(sl.Alias, epochOut)
+			}
+		case sl.IsFunctionCall:
+			aggName := sl.FunctionCall.Name
+			var outCols []string
+			outValues := make(map[string]reflect.Value)
+			for _, rows := range groups {
+				aggfunc, err := sr.newAggregate(sl)
+				if err != nil {
+					return nil, err
+				}
+				if err = aggfunc.Accum(takeRows(input, rows)); err != nil {
+					return nil, err
+				}
+				result := aggfunc.Output()
+				if result == nil || result.Len() != 1 {
+					return nil, fmt.Errorf("Aggregate %s must return one row per group", aggName)
+				}
+				if outCols == nil {
+					for _, name := range result.GetColumnNames() {
+						if name != "Epoch" {
+							outCols = append(outCols, name)
+						}
+					}
+				}
+				for _, name := range outCols {
+					col := reflect.ValueOf(result.GetColumn(name))
+					if _, ok := outValues[name]; !ok {
+						outValues[name] = reflect.MakeSlice(col.Type(), 0, len(groups))
+					}
+					outValues[name] = reflect.Append(outValues[name], col.Index(0))
+				}
+			}
+			if len(groups) == 0 {
+				// Evaluate the aggregate over no rows for the names and types of its columns
+				aggfunc, err := sr.newAggregate(sl)
+				if err != nil {
+					return nil, err
+				}
+				if err = aggfunc.Accum(takeRows(input, nil)); err != nil {
+					return nil, err
+				}
+				if result := aggfunc.Output(); result != nil {
+					for _, name := range result.GetColumnNames() {
+						if name != "Epoch" {
+							outCols = append(outCols, name)
+							col := reflect.ValueOf(result.GetColumn(name))
+							outValues[name] = reflect.MakeSlice(col.Type(), 0, 0)
+						}
+					}
+				}
+			}
+			for _, name := range outCols {
+				outname := name
+				if sl.IsAliased {
+					outname = sl.Alias
+				}
+				cs.AddColumn(outname, outValues[name].Interface())
+			}
+		case sl.IsPrimary:
+			if sl.PrimaryName == "Epoch" {
+				continue
+			}
+			values, ok := keyValues[sl.PrimaryName]
+			if !ok {
+				return nil, fmt.Errorf("Column %s must appear in GROUP BY or be used in an aggregate",
+					sl.PrimaryName)
+			}
+			outname := sl.PrimaryName
+			if sl.IsAliased {
+				outname = sl.Alias
+			}
+			cs.AddColumn(outname, values)
+		}
+	}
+	return cs, nil
+}
+
+/*
+sortColumnSeries orders the rows of the column series by the sort items
+*/
+func sortColumnSeries(cs *io.ColumnSeries, orderBy []SortItem) error {
+	keys := make([]reflect.Value, len(orderBy))
+	for i, item := range orderBy {
+		col := cs.GetColumn(item.Name)
+		if col == nil {
+			return fmt.Errorf("ORDER BY column %s not found", item.Name)
+		}
+		keys[i] = reflect.ValueOf(col)
+	}
+	index := make([]int, cs.Len())
+	for i := range index {
+		index[i] = i
+	}
+	sortRows(index, keys, orderBy)
+	for _, name := range cs.GetColumnNames() {
+		if err := cs.Replace(name, gatherColumn(cs.GetColumn(name), index)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+sortRows stably sorts row indexes by the key columns, in the order of the
+sort items
+*/
+func sortRows(index []int, keys []reflect.Value, orderBy []SortItem) {
+	sort.SliceStable(index, func(i, j int) bool {
+		for k, item := range orderBy {
+			c := compareValues(keys[k].Index(index[i]), keys[k].Index(index[j]))
+			if c == 0 {
+				continue
+			}
+			if item.Order == DESCENDING {
+				return c > 0
+			}
+			return c < 0
+		}
+		return false
+	})
+}
+
+func compareValues(left, right reflect.Value) int {
+	switch left.Kind() {
+	case reflect.Float32, reflect.Float64:
+		l, r := left.Float(), right.Float()
+		switch {
+		case l < r:
+			return -1
+		case l > r:
+			return 1
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		l, r := left.Int(), right.Int()
+		switch {
+		case l < r:
+			return -1
+		case l > r:
+			return 1
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		l, r := left.Uint(), right.Uint()
+		switch {
+		case l < r:
+			return -1
+		case l > r:
+			return 1
+		}
+	case reflect.String:
+		return strings.Compare(left.String(), right.String())
+	case reflect.Bool:
+		l, r := left.Bool(), right.Bool()
+		switch {
+		case !l && r:
+			return -1
+		case l && !r:
+			return 1
+		}
+	}
+	return 0
+}
+
+/*
+groupRows returns the row indexes of each distinct combination of key
+values, in order of first appearance
+*/
+func groupRows(keyColumns []interface{}, length int) (groups [][]int) {
+	groupIndex := make(map[string]int)
+	var keyString strings.Builder
+	for row := 0; row < length; row++ {
+		keyString.Reset()
+		for _, col := range keyColumns {
+			fmt.Fprintf(&keyString, "%v\x00", reflect.ValueOf(col).Index(row).Interface())
+		}
+		g, ok := groupIndex[keyString.String()]
+		if !ok {
+			g = len(groups)
+			groupIndex[keyString.String()] = g
+			groups = append(groups, nil)
+		}
+		groups[g] = append(groups[g], row)
+	}
+	return groups
+}
+
+func takeRows(cs *io.ColumnSeries, rows []int) *io.ColumnSeries {
+	out := io.NewColumnSeries()
+	for _, name := range cs.GetColumnNames() {
+		out.AddColumn(name, gatherColumn(cs.GetColumn(name), rows))
+	}
+	return out
+}
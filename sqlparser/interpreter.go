@@ -2,6 +2,7 @@ package sqlparser
 
 import (
 	"fmt"
+	"sort"
 
 	. "github.com/alpacahq/marketstore/sqlparser/parser"
 	. "github.com/antlr/antlr4/runtime/Go/antlr"
@@ -19,6 +20,7 @@ type AstBuilder struct {
 }
 
 func NewAstBuilder(sourceString string) (ast *AstBuilder, err error) {
+	sourceString = rewriteConditions(rewriteExists(sourceString))
 	ast = &AstBuilder{statementSource: sourceString}
 
 	input := NewInputStream(ast.statementSource)
@@ -53,6 +55,154 @@ func NewAstBuilder(sourceString string) (ast *AstBuilder, err error) {
 	return ast, nil
 }
 
+/*
+rewriteExists appends "= TRUE" to EXISTS (subquery) conditions. The grammar
+requires a predicate after the value in a condition, so a bare EXISTS does
+not parse, while the comparison does and evaluates the same
+*/
+func rewriteExists(source string) string {
+	tokens := defaultChannelTokens(source)
+	var inserts []int // Rune positions following the closing parenthesis
+	for i, token := range tokens {
+		if token.GetTokenType() != SQLBaseLexerEXISTS || i == 0 || i+1 == len(tokens) {
+			continue
+		}
+		switch tokens[i-1].GetTokenType() {
+		case SQLBaseLexerWHERE, SQLBaseLexerAND, SQLBaseLexerOR, SQLBaseLexerNOT,
+			SQLBaseLexerHAVING, SQLBaseLexerWHEN, SQLBaseLexerON:
+		default:
+			if tokens[i-1].GetText() != "(" {
+				continue
+			}
+		}
+		if tokens[i+1].GetText() != "(" {
+			continue
+		}
+		var depth int
+		closing := -1
+		for j := i + 1; j < len(tokens) && closing == -1; j++ {
+			switch tokens[j].GetText() {
+			case "(":
+				depth++
+			case ")":
+				if depth--; depth == 0 {
+					closing = j
+				}
+			}
+		}
+		if closing == -1 {
+			continue
+		}
+		if closing+1 < len(tokens) {
+			switch tokens[closing+1].GetTokenType() {
+			case SQLBaseLexerEQ, SQLBaseLexerNEQ, SQLBaseLexerLT, SQLBaseLexerLTE,
+				SQLBaseLexerGT, SQLBaseLexerGTE, SQLBaseLexerIN, SQLBaseLexerBETWEEN,
+				SQLBaseLexerLIKE, SQLBaseLexerIS:
+				continue
+			}
+		}
+		inserts = append(inserts, tokens[closing].GetStop()+1)
+	}
+	return insertTrueComparisons(source, inserts)
+}
+
+/*
+rewriteConditions appends "= TRUE" to parenthesized conditions, such as in
+"NOT (a > 1)" or "(a OR b) AND c", which do not parse on their own for the
+same reason as EXISTS. The comparison is removed again when the predicates
+are built, see unwrapTrueComparison
+*/
+func rewriteConditions(source string) string {
+	tokens := defaultChannelTokens(source)
+	var inserts []int
+	for i, token := range tokens {
+		if token.GetText() != "(" || i == 0 {
+			continue
+		}
+		switch tokens[i-1].GetTokenType() {
+		case SQLBaseLexerWHERE, SQLBaseLexerAND, SQLBaseLexerOR, SQLBaseLexerNOT,
+			SQLBaseLexerHAVING, SQLBaseLexerWHEN, SQLBaseLexerON:
+		default:
+			if tokens[i-1].GetText() != "(" {
+				continue
+			}
+		}
+		// Only a group holding a condition at its own level is one
+		var depth int
+		var isCondition bool
+		closing := -1
+		for j := i; j < len(tokens) && closing == -1; j++ {
+			switch tokens[j].GetText() {
+			case "(":
+				depth++
+				continue
+			case ")":
+				if depth--; depth == 0 {
+					closing = j
+				}
+				continue
+			}
+			if depth != 1 {
+				continue
+			}
+			switch tokens[j].GetTokenType() {
+			case SQLBaseLexerSELECT:
+				closing = -2 // A subquery
+			case SQLBaseLexerAND, SQLBaseLexerOR, SQLBaseLexerNOT, SQLBaseLexerEQ,
+				SQLBaseLexerNEQ, SQLBaseLexerLT, SQLBaseLexerLTE, SQLBaseLexerGT,
+				SQLBaseLexerGTE, SQLBaseLexerIN, SQLBaseLexerBETWEEN, SQLBaseLexerLIKE,
+				SQLBaseLexerIS, SQLBaseLexerEXISTS:
+				isCondition = true
+			}
+		}
+		if closing < 0 || !isCondition {
+			continue
+		}
+		// The group must end the condition, rather than be compared or computed with
+		if closing+1 < len(tokens) {
+			switch tokens[closing+1].GetTokenType() {
+			case SQLBaseLexerAND, SQLBaseLexerOR, SQLBaseLexerSEMICOLON, SQLBaseLexerTHEN,
+				SQLBaseLexerORDER, SQLBaseLexerGROUP, SQLBaseLexerHAVING, SQLBaseLexerLIMIT,
+				SQLBaseLexerOFFSET, SQLBaseLexerUNION, SQLBaseLexerEXCEPT, SQLBaseLexerINTERSECT:
+			default:
+				if tokens[closing+1].GetText() != ")" {
+					continue
+				}
+			}
+		}
+		inserts = append(inserts, tokens[closing].GetStop()+1)
+	}
+	sort.Ints(inserts)
+	return insertTrueComparisons(source, inserts)
+}
+
+// insertTrueComparisons inserts "= TRUE" at each of the sorted rune positions
+func insertTrueComparisons(source string, inserts []int) string {
+	if len(inserts) == 0 {
+		return source
+	}
+	runes := []rune(source)
+	var rewritten []rune
+	var last int
+	for _, pos := range inserts {
+		rewritten = append(rewritten, runes[last:pos]...)
+		rewritten = append(rewritten, []rune(" = TRUE")...)
+		last = pos
+	}
+	return string(append(rewritten, runes[last:]...))
+}
+
+func defaultChannelTokens(source string) (tokens []Token) {
+	lexer := NewSQLBaseLexer(NewInputStream(source))
+	lexer.RemoveErrorListeners()
+	for _, token := range lexer.GetAllTokens() {
+		if token.GetChannel() == TokenDefaultChannel {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens
+}
+
 /*
 func print_tree(input Tree, lev int) {
 	for i := 0; i < lev; i++ {
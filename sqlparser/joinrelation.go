@@ -0,0 +1,417 @@
+package sqlparser
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/alpacahq/marketstore/utils/io"
+)
+
+/*
+JoinSource is a relation that can be used as one side of a JOIN. The
+column series it produces has an "Epoch" column used as the join key, and
+all other columns are qualified by the relation alias, as in "a.Close"
+*/
+type JoinSource interface {
+	MaterializeQualified(epochSP *StaticPredicate) (cs *io.ColumnSeries, err error)
+	GetAliases() []string
+}
+
+/*
+TableReference is a table named in a FROM clause along with its alias
+*/
+type TableReference struct {
+	Name, Alias string
+}
+
+func NewTableReference(name, alias string) *TableReference {
+	return &TableReference{Name: name, Alias: alias}
+}
+
+func (tr *TableReference) GetAlias() string {
+	if len(tr.Alias) != 0 {
+		return tr.Alias
+	}
+	return tr.Name
+}
+
+func (tr *TableReference) GetAliases() []string {
+	return []string{tr.GetAlias()}
+}
+
+func (tr *TableReference) MaterializeQualified(epochSP *StaticPredicate) (cs *io.ColumnSeries, err error) {
+	key, err := tableKey(tr.Name)
+	if err != nil {
+		return nil, err
+	}
+	if isMultiSymbol(key) {
+		return nil, fmt.Errorf("Tables with multiple symbols can not be joined: %s", tr.Name)
+	}
+	input, err := queryTimeBucket(key, epochSP, 0, io.FIRST)
+	if err != nil {
+		return nil, err
+	}
+	return qualifyColumns(input, tr.GetAlias()), nil
+}
+
+/*
+materializePreceding returns the last row of the table before the epoch,
+or an empty column series if there is none
+*/
+func (tr *TableReference) materializePreceding(epoch int64) (cs *io.ColumnSeries, err error) {
+	key, err := tableKey(tr.Name)
+	if err != nil {
+		return nil, err
+	}
+	sp := NewStaticPredicate(NewColumnReference("Epoch"))
+	sp.SetMax(epoch, false)
+	input, err := queryTimeBucket(key, sp, 1, io.LAST)
+	if err != nil {
+		return nil, err
+	}
+	return qualifyColumns(input, tr.GetAlias()), nil
+}
+
+/*
+DerivedTable is a subquery in a FROM clause along with its alias
+*/
+type DerivedTable struct {
+	Query *SelectRelation
+	Alias string
+}
+
+func NewDerivedTable(query *SelectRelation, alias string) *DerivedTable {
+	return &DerivedTable{Query: query, Alias: alias}
+}
+
+func (dt *DerivedTable) GetAliases() []string {
+	return []string{dt.Alias}
+}
+
+func (dt *DerivedTable) MaterializeQualified(epochSP *StaticPredicate) (cs *io.ColumnSeries, err error) {
+	input, err := dt.Query.Materialize()
+	if err != nil {
+		return nil, err
+	}
+	if !input.Exists("Epoch") {
+		return nil, fmt.Errorf("Subquery %s must return the Epoch column to be joined", dt.Alias)
+	}
+	if epochSP != nil {
+		StaticPredicateGroup{"Epoch": epochSP}.Apply(input)
+	}
+	return qualifyColumns(input, dt.Alias), nil
+}
+
+/*
+precedingRowSource is a JoinSource able to fetch its last row before an
+Epoch without reading its whole history
+*/
+type precedingRowSource interface {
+	materializePreceding(epoch int64) (cs *io.ColumnSeries, err error)
+}
+
+/*
+JoinRelation joins two relations on their Epoch columns. An inner join
+matches rows with equal Epochs, an as-of join matches each row on the left
+with the latest row on the right at or before (strictly before if
+IsStrict) the left Epoch
+*/
+type JoinRelation struct {
+	Left, Right      JoinSource
+	JoinType         JoinTypeEnum
+	IsAsOf, IsStrict bool
+}
+
+func NewJoinRelation(left, right JoinSource, joinType JoinTypeEnum) (jr *JoinRelation, err error) {
+	if joinType != INNER {
+		return nil, fmt.Errorf("Only inner and as-of joins are supported")
+	}
+	for _, alias := range right.GetAliases() {
+		if hasAlias(left, alias) {
+			return nil, fmt.Errorf("Relation %s appears more than once in join, use an alias", alias)
+		}
+	}
+	jr = new(JoinRelation)
+	jr.Left = left
+	jr.Right = right
+	jr.JoinType = joinType
+	return jr, nil
+}
+
+func (jr *JoinRelation) GetAliases() []string {
+	return append(jr.Left.GetAliases(), jr.Right.GetAliases()...)
+}
+
+/*
+SetCriteria configures the join from a comparison between the Epoch
+columns of the two relations:
+
+	a.Epoch = b.Epoch	inner join
+	a.Epoch >= b.Epoch	as-of join, nearest previous or equal Epoch in b
+	a.Epoch > b.Epoch	as-of join, nearest strictly previous Epoch in b
+*/
+func (jr *JoinRelation) SetCriteria(leftName, rightName string, op io.ComparisonOperatorEnum) error {
+	leftAlias, leftColumn := splitQualifiedName(leftName)
+	rightAlias, rightColumn := splitQualifiedName(rightName)
+	if leftColumn != "Epoch" || rightColumn != "Epoch" {
+		return fmt.Errorf("Joins are only supported on the Epoch column")
+	}
+
+	leftSide, err := jr.sideOf(leftAlias)
+	if err != nil {
+		return err
+	}
+	rightSide, err := jr.sideOf(rightAlias)
+	if err != nil {
+		return err
+	}
+	// Put the comparison in left relation / right relation order
+	if leftSide == jr.Right || rightSide == jr.Left {
+		leftSide, rightSide = rightSide, leftSide
+		op = flipComparison(op)
+	}
+	if leftSide != nil && leftSide == rightSide {
+		return fmt.Errorf("Join criteria must compare the two joined relations")
+	}
+
+	switch op {
+	case io.EQ:
+		jr.IsAsOf = false
+	case io.GTE, io.GT:
+		jr.IsAsOf = true
+		jr.IsStrict = (op == io.GT)
+	default:
+		return fmt.Errorf("Unsupported join comparison %s, use = for inner or >= and > for as-of joins",
+			op.String())
+	}
+	return nil
+}
+
+func (jr *JoinRelation) sideOf(alias string) (JoinSource, error) {
+	switch {
+	case len(alias) == 0:
+		return nil, nil
+	case hasAlias(jr.Left, alias):
+		return jr.Left, nil
+	case hasAlias(jr.Right, alias):
+		return jr.Right, nil
+	}
+	return nil, fmt.Errorf("Unknown relation %s in join criteria", alias)
+}
+
+func (jr *JoinRelation) MaterializeQualified(epochSP *StaticPredicate) (cs *io.ColumnSeries, err error) {
+	left, err := jr.Left.MaterializeQualified(epochSP)
+	if err != nil {
+		return nil, err
+	}
+	right, err := jr.materializeRight(epochSP)
+	if err != nil {
+		return nil, err
+	}
+	/*
+		The matching below walks both sides in Epoch order, which a derived
+		table with an ORDER BY does not follow
+	*/
+	if err = sortByEpoch(left); err != nil {
+		return nil, err
+	}
+	if err = sortByEpoch(right); err != nil {
+		return nil, err
+	}
+
+	var leftIndex, rightIndex []int
+	if jr.IsAsOf {
+		leftIndex, rightIndex = asOfMatch(left.GetEpoch(), right.GetEpoch(), jr.IsStrict)
+	} else {
+		leftIndex, rightIndex = equalityMatch(left.GetEpoch(), right.GetEpoch())
+	}
+
+	cs = io.NewColumnSeries()
+	for _, name := range left.GetColumnNames() {
+		cs.AddColumn(name, gatherColumn(left.GetColumn(name), leftIndex))
+	}
+	for _, name := range right.GetColumnNames() {
+		if name == "Epoch" {
+			continue
+		}
+		cs.AddColumn(name, gatherColumn(right.GetColumn(name), rightIndex))
+	}
+	return cs, nil
+}
+
+/*
+materializeRight reads the right side of the join. An as-of join needs the
+right side row preceding the start of the range, so the right side gets
+the range and, when it can fetch it alone, the row before it. Otherwise
+only the upper Epoch bound is pushed down
+*/
+func (jr *JoinRelation) materializeRight(epochSP *StaticPredicate) (cs *io.ColumnSeries, err error) {
+	if !jr.IsAsOf || epochSP == nil {
+		return jr.Right.MaterializeQualified(epochSP)
+	}
+	start, end, hasStart, hasEnd, err := epochSP.EpochRange()
+	if err != nil {
+		return nil, err
+	}
+	rightSP := NewStaticPredicate(epochSP.Column)
+	if hasEnd {
+		rightSP.SetMax(end, true)
+	}
+	source, ok := jr.Right.(precedingRowSource)
+	if !hasStart || !ok {
+		return jr.Right.MaterializeQualified(rightSP)
+	}
+	rightSP.SetMin(start, true)
+	preceding, err := source.materializePreceding(start)
+	if err != nil {
+		return nil, err
+	}
+	cs, err = jr.Right.MaterializeQualified(rightSP)
+	if err != nil {
+		return nil, err
+	}
+	return appendRows(preceding, cs), nil
+}
+
+/*
+Utility functions
+*/
+
+func tableKey(tableName string) (key *io.TimeBucketKey, err error) {
+	key = io.NewTimeBucketKey(tableName, "Symbol/Timeframe/AttributeGroup")
+	if key == nil {
+		return nil, fmt.Errorf("Table name must match \"one/two/three\" for three directory levels")
+	}
+	return key, nil
+}
+
+/*
+qualifyColumns prefixes the column names with the relation alias, keeping
+an unqualified Epoch column as the join key
+*/
+func qualifyColumns(input *io.ColumnSeries, alias string) (cs *io.ColumnSeries) {
+	cs = io.NewColumnSeries()
+	cs.AddColumn("Epoch", input.GetEpoch())
+	for _, name := range input.GetColumnNames() {
+		cs.AddColumn(alias+"."+name, input.GetColumn(name))
+	}
+	return cs
+}
+
+func hasAlias(source JoinSource, alias string) bool {
+	for _, name := range source.GetAliases() {
+		if name == alias {
+			return true
+		}
+	}
+	return false
+}
+
+func splitQualifiedName(name string) (qualifier, column string) {
+	if i := strings.LastIndex(name, "."); i != -1 {
+		return name[:i], name[i+1:]
+	}
+	return "", name
+}
+
+func flipComparison(op io.ComparisonOperatorEnum) io.ComparisonOperatorEnum {
+	switch op {
+	case io.LT:
+		return io.GT
+	case io.LTE:
+		return io.GTE
+	case io.GT:
+		return io.LT
+	case io.GTE:
+		return io.LTE
+	}
+	return op
+}
+
+/*
+equalityMatch returns the index pairs of rows with equal epochs, producing
+all combinations when an epoch is repeated on both sides
+*/
+func equalityMatch(left, right []int64) (leftIndex, rightIndex []int) {
+	var i, j int
+	for i < len(left) && j < len(right) {
+		switch {
+		case left[i] < right[j]:
+			i++
+		case left[i] > right[j]:
+			j++
+		default:
+			iEnd, jEnd := i, j
+			for iEnd < len(left) && left[iEnd] == left[i] {
+				iEnd++
+			}
+			for jEnd < len(right) && right[jEnd] == right[j] {
+				jEnd++
+			}
+			for ii := i; ii < iEnd; ii++ {
+				for jj := j; jj < jEnd; jj++ {
+					leftIndex = append(leftIndex, ii)
+					rightIndex = append(rightIndex, jj)
+				}
+			}
+			i, j = iEnd, jEnd
+		}
+	}
+	return leftIndex, rightIndex
+}
+
+/*
+asOfMatch returns for each left row the index of the latest right row
+with an epoch at or before it. Left rows with no preceding right row are
+not matched
+*/
+func asOfMatch(left, right []int64, strict bool) (leftIndex, rightIndex []int) {
+	var j int
+	for i, epoch := range left {
+		for j < len(right) && (right[j] < epoch || (!strict && right[j] == epoch)) {
+			j++
+		}
+		if j == 0 {
+			continue
+		}
+		leftIndex = append(leftIndex, i)
+		rightIndex = append(rightIndex, j-1)
+	}
+	return leftIndex, rightIndex
+}
+
+/*
+appendRows returns the rows of head followed by the rows of tail, both
+having the same columns
+*/
+func appendRows(head, tail *io.ColumnSeries) (cs *io.ColumnSeries) {
+	if head.Len() == 0 {
+		return tail
+	}
+	cs = io.NewColumnSeries()
+	for _, name := range head.GetColumnNames() {
+		col := reflect.AppendSlice(reflect.ValueOf(head.GetColumn(name)),
+			reflect.ValueOf(tail.GetColumn(name)))
+		cs.AddColumn(name, col.Interface())
+	}
+	return cs
+}
+
+func sortByEpoch(cs *io.ColumnSeries) error {
+	epochs := cs.GetEpoch()
+	if sort.SliceIsSorted(epochs, func(i, j int) bool { return epochs[i] < epochs[j] }) {
+		return nil
+	}
+	return sortColumnSeries(cs, []SortItem{{Name: "Epoch", Order: ASCENDING}})
+}
+
+func gatherColumn(col interface{}, indexes []int) interface{} {
+	iv := reflect.ValueOf(col)
+	slc := reflect.MakeSlice(reflect.TypeOf(col), len(indexes), len(indexes))
+	for i, index := range indexes {
+		slc.Index(i).Set(iv.Index(index))
+	}
+	return slc.Interface()
+}
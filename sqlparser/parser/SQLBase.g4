@@ -95,6 +95,7 @@ queryNoWith:
       queryTerm
       (ORDER BY sortItem (',' sortItem)*)?
       (LIMIT limit=INTEGER_VALUE)?
+      (OFFSET offset=INTEGER_VALUE)?
     ;
 
 queryTerm
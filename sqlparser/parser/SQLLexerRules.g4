@@ -20,6 +20,7 @@ ROLLUP:  [Rr][Oo][Ll][Ll][Uu][Pp];
 ORDER:  [Oo][Rr][Dd][Ee][Rr];
 HAVING:  [Hh][Aa][Vv][Ii][Nn][Gg];
 LIMIT:  [Ll][Ii][Mm][Ii][Tt];
+OFFSET:  [Oo][Ff][Ff][Ss][Ee][Tt];
 AT:  [Aa][Tt];
 OR:  [Oo][Rr];
 AND:  [Aa][Nn][Dd];
@@ -248,7 +249,7 @@ BACKQUOTED_IDENTIFIER
     ;
 
 fragment EXPONENT
-    : 'E' [+-]? DIGIT+
+    : [Ee] [+-]? DIGIT+
     ;
 
 fragment DIGIT
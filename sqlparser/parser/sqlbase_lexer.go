@@ -14,7 +14,7 @@ var _ = fmt.Printf
 var _ = unicode.IsLetter
 
 var serializedLexerAtn = []uint16{
-	3, 1072, 54993, 33286, 44333, 17431, 44785, 36224, 43741, 2, 214, 1940,
+	3, 1072, 54993, 33286, 44333, 17431, 44785, 36224, 43741, 2, 215, 1949,
 	8, 1, 4, 2, 9, 2, 4, 3, 9, 3, 4, 4, 9, 4, 4, 5, 9, 5, 4, 6, 9, 6, 4, 7,
 	9, 7, 4, 8, 9, 8, 4, 9, 9, 9, 4, 10, 9, 10, 4, 11, 9, 11, 4, 12, 9, 12,
 	4, 13, 9, 13, 4, 14, 9, 14, 4, 15, 9, 15, 4, 16, 9, 16, 4, 17, 9, 17, 4,
@@ -221,7 +221,8 @@ var serializedLexerAtn = []uint16{
 	5, 214, 1913, 10, 214, 3, 214, 5, 214, 1916, 10, 214, 3, 214, 3, 214, 3,
 	215, 3, 215, 3, 215, 3, 215, 7, 215, 1924, 10, 215, 12, 215, 14, 215, 1927,
 	11, 215, 3, 215, 3, 215, 3, 215, 3, 215, 3, 215, 3, 216, 6, 216, 1935,
-	10, 216, 13, 216, 14, 216, 1936, 3, 216, 3, 216, 3, 1925, 2, 217, 3, 3,
+	10, 216, 13, 216, 14, 216, 1936, 3, 216, 3, 216, 4, 217, 9, 217, 3, 217,
+	3, 217, 3, 217, 3, 217, 3, 217, 3, 217, 3, 217, 3, 1925, 2, 218, 3, 3,
 	5, 4, 7, 5, 9, 6, 11, 7, 13, 8, 15, 9, 17, 10, 19, 11, 21, 12, 23, 13,
 	25, 14, 27, 15, 29, 16, 31, 17, 33, 18, 35, 19, 37, 20, 39, 21, 41, 22,
 	43, 23, 45, 24, 47, 25, 49, 26, 51, 27, 53, 28, 55, 29, 57, 30, 59, 31,
@@ -248,646 +249,649 @@ var serializedLexerAtn = []uint16{
 	188, 375, 189, 377, 190, 379, 191, 381, 192, 383, 193, 385, 194, 387, 195,
 	389, 196, 391, 197, 393, 198, 395, 199, 397, 200, 399, 201, 401, 202, 403,
 	203, 405, 204, 407, 205, 409, 206, 411, 207, 413, 208, 415, 209, 417, 210,
-	419, 211, 421, 2, 423, 2, 425, 2, 427, 212, 429, 213, 431, 214, 3, 2, 36,
-	4, 2, 85, 85, 117, 117, 4, 2, 71, 71, 103, 103, 4, 2, 78, 78, 110, 110,
-	4, 2, 69, 69, 101, 101, 4, 2, 86, 86, 118, 118, 4, 2, 72, 72, 104, 104,
-	4, 2, 84, 84, 116, 116, 4, 2, 81, 81, 113, 113, 4, 2, 79, 79, 111, 111,
-	4, 2, 67, 67, 99, 99, 4, 2, 70, 70, 102, 102, 4, 2, 80, 80, 112, 112, 4,
-	2, 91, 91, 123, 123, 4, 2, 75, 75, 107, 107, 4, 2, 89, 89, 121, 121, 4,
-	2, 74, 74, 106, 106, 4, 2, 73, 73, 105, 105, 4, 2, 87, 87, 119, 119, 4,
-	2, 82, 82, 114, 114, 4, 2, 68, 68, 100, 100, 4, 2, 88, 88, 120, 120, 4,
-	2, 90, 90, 122, 122, 4, 2, 77, 77, 109, 109, 4, 2, 92, 92, 124, 124, 4,
-	2, 76, 76, 108, 108, 3, 2, 41, 41, 5, 2, 60, 60, 66, 66, 97, 97, 3, 2,
-	36, 36, 3, 2, 98, 98, 4, 2, 45, 45, 47, 47, 3, 2, 50, 59, 4, 2, 67, 92,
-	99, 124, 4, 2, 12, 12, 15, 15, 5, 2, 11, 12, 15, 15, 34, 34, 1969, 2, 3,
-	3, 2, 2, 2, 2, 5, 3, 2, 2, 2, 2, 7, 3, 2, 2, 2, 2, 9, 3, 2, 2, 2, 2, 11,
-	3, 2, 2, 2, 2, 13, 3, 2, 2, 2, 2, 15, 3, 2, 2, 2, 2, 17, 3, 2, 2, 2, 2,
-	19, 3, 2, 2, 2, 2, 21, 3, 2, 2, 2, 2, 23, 3, 2, 2, 2, 2, 25, 3, 2, 2, 2,
-	2, 27, 3, 2, 2, 2, 2, 29, 3, 2, 2, 2, 2, 31, 3, 2, 2, 2, 2, 33, 3, 2, 2,
-	2, 2, 35, 3, 2, 2, 2, 2, 37, 3, 2, 2, 2, 2, 39, 3, 2, 2, 2, 2, 41, 3, 2,
-	2, 2, 2, 43, 3, 2, 2, 2, 2, 45, 3, 2, 2, 2, 2, 47, 3, 2, 2, 2, 2, 49, 3,
-	2, 2, 2, 2, 51, 3, 2, 2, 2, 2, 53, 3, 2, 2, 2, 2, 55, 3, 2, 2, 2, 2, 57,
-	3, 2, 2, 2, 2, 59, 3, 2, 2, 2, 2, 61, 3, 2, 2, 2, 2, 63, 3, 2, 2, 2, 2,
-	65, 3, 2, 2, 2, 2, 67, 3, 2, 2, 2, 2, 69, 3, 2, 2, 2, 2, 71, 3, 2, 2, 2,
-	2, 73, 3, 2, 2, 2, 2, 75, 3, 2, 2, 2, 2, 77, 3, 2, 2, 2, 2, 79, 3, 2, 2,
-	2, 2, 81, 3, 2, 2, 2, 2, 83, 3, 2, 2, 2, 2, 85, 3, 2, 2, 2, 2, 87, 3, 2,
-	2, 2, 2, 89, 3, 2, 2, 2, 2, 91, 3, 2, 2, 2, 2, 93, 3, 2, 2, 2, 2, 95, 3,
-	2, 2, 2, 2, 97, 3, 2, 2, 2, 2, 99, 3, 2, 2, 2, 2, 101, 3, 2, 2, 2, 2, 103,
-	3, 2, 2, 2, 2, 105, 3, 2, 2, 2, 2, 107, 3, 2, 2, 2, 2, 109, 3, 2, 2, 2,
-	2, 111, 3, 2, 2, 2, 2, 113, 3, 2, 2, 2, 2, 115, 3, 2, 2, 2, 2, 117, 3,
-	2, 2, 2, 2, 119, 3, 2, 2, 2, 2, 121, 3, 2, 2, 2, 2, 123, 3, 2, 2, 2, 2,
-	125, 3, 2, 2, 2, 2, 127, 3, 2, 2, 2, 2, 129, 3, 2, 2, 2, 2, 131, 3, 2,
-	2, 2, 2, 133, 3, 2, 2, 2, 2, 135, 3, 2, 2, 2, 2, 137, 3, 2, 2, 2, 2, 139,
-	3, 2, 2, 2, 2, 141, 3, 2, 2, 2, 2, 143, 3, 2, 2, 2, 2, 145, 3, 2, 2, 2,
-	2, 147, 3, 2, 2, 2, 2, 149, 3, 2, 2, 2, 2, 151, 3, 2, 2, 2, 2, 153, 3,
-	2, 2, 2, 2, 155, 3, 2, 2, 2, 2, 157, 3, 2, 2, 2, 2, 159, 3, 2, 2, 2, 2,
-	161, 3, 2, 2, 2, 2, 163, 3, 2, 2, 2, 2, 165, 3, 2, 2, 2, 2, 167, 3, 2,
-	2, 2, 2, 169, 3, 2, 2, 2, 2, 171, 3, 2, 2, 2, 2, 173, 3, 2, 2, 2, 2, 175,
-	3, 2, 2, 2, 2, 177, 3, 2, 2, 2, 2, 179, 3, 2, 2, 2, 2, 181, 3, 2, 2, 2,
-	2, 183, 3, 2, 2, 2, 2, 185, 3, 2, 2, 2, 2, 187, 3, 2, 2, 2, 2, 189, 3,
-	2, 2, 2, 2, 191, 3, 2, 2, 2, 2, 193, 3, 2, 2, 2, 2, 195, 3, 2, 2, 2, 2,
-	197, 3, 2, 2, 2, 2, 199, 3, 2, 2, 2, 2, 201, 3, 2, 2, 2, 2, 203, 3, 2,
-	2, 2, 2, 205, 3, 2, 2, 2, 2, 207, 3, 2, 2, 2, 2, 209, 3, 2, 2, 2, 2, 211,
-	3, 2, 2, 2, 2, 213, 3, 2, 2, 2, 2, 215, 3, 2, 2, 2, 2, 217, 3, 2, 2, 2,
-	2, 219, 3, 2, 2, 2, 2, 221, 3, 2, 2, 2, 2, 223, 3, 2, 2, 2, 2, 225, 3,
-	2, 2, 2, 2, 227, 3, 2, 2, 2, 2, 229, 3, 2, 2, 2, 2, 231, 3, 2, 2, 2, 2,
-	233, 3, 2, 2, 2, 2, 235, 3, 2, 2, 2, 2, 237, 3, 2, 2, 2, 2, 239, 3, 2,
-	2, 2, 2, 241, 3, 2, 2, 2, 2, 243, 3, 2, 2, 2, 2, 245, 3, 2, 2, 2, 2, 247,
-	3, 2, 2, 2, 2, 249, 3, 2, 2, 2, 2, 251, 3, 2, 2, 2, 2, 253, 3, 2, 2, 2,
-	2, 255, 3, 2, 2, 2, 2, 257, 3, 2, 2, 2, 2, 259, 3, 2, 2, 2, 2, 261, 3,
-	2, 2, 2, 2, 263, 3, 2, 2, 2, 2, 265, 3, 2, 2, 2, 2, 267, 3, 2, 2, 2, 2,
-	269, 3, 2, 2, 2, 2, 271, 3, 2, 2, 2, 2, 273, 3, 2, 2, 2, 2, 275, 3, 2,
-	2, 2, 2, 277, 3, 2, 2, 2, 2, 279, 3, 2, 2, 2, 2, 281, 3, 2, 2, 2, 2, 283,
-	3, 2, 2, 2, 2, 285, 3, 2, 2, 2, 2, 287, 3, 2, 2, 2, 2, 289, 3, 2, 2, 2,
-	2, 291, 3, 2, 2, 2, 2, 293, 3, 2, 2, 2, 2, 295, 3, 2, 2, 2, 2, 297, 3,
-	2, 2, 2, 2, 299, 3, 2, 2, 2, 2, 301, 3, 2, 2, 2, 2, 303, 3, 2, 2, 2, 2,
-	305, 3, 2, 2, 2, 2, 307, 3, 2, 2, 2, 2, 309, 3, 2, 2, 2, 2, 311, 3, 2,
-	2, 2, 2, 313, 3, 2, 2, 2, 2, 315, 3, 2, 2, 2, 2, 317, 3, 2, 2, 2, 2, 319,
-	3, 2, 2, 2, 2, 321, 3, 2, 2, 2, 2, 323, 3, 2, 2, 2, 2, 325, 3, 2, 2, 2,
-	2, 327, 3, 2, 2, 2, 2, 329, 3, 2, 2, 2, 2, 331, 3, 2, 2, 2, 2, 333, 3,
-	2, 2, 2, 2, 335, 3, 2, 2, 2, 2, 337, 3, 2, 2, 2, 2, 339, 3, 2, 2, 2, 2,
-	341, 3, 2, 2, 2, 2, 343, 3, 2, 2, 2, 2, 345, 3, 2, 2, 2, 2, 347, 3, 2,
-	2, 2, 2, 349, 3, 2, 2, 2, 2, 351, 3, 2, 2, 2, 2, 353, 3, 2, 2, 2, 2, 355,
-	3, 2, 2, 2, 2, 357, 3, 2, 2, 2, 2, 359, 3, 2, 2, 2, 2, 361, 3, 2, 2, 2,
-	2, 363, 3, 2, 2, 2, 2, 365, 3, 2, 2, 2, 2, 367, 3, 2, 2, 2, 2, 369, 3,
-	2, 2, 2, 2, 371, 3, 2, 2, 2, 2, 373, 3, 2, 2, 2, 2, 375, 3, 2, 2, 2, 2,
-	377, 3, 2, 2, 2, 2, 379, 3, 2, 2, 2, 2, 381, 3, 2, 2, 2, 2, 383, 3, 2,
-	2, 2, 2, 385, 3, 2, 2, 2, 2, 387, 3, 2, 2, 2, 2, 389, 3, 2, 2, 2, 2, 391,
-	3, 2, 2, 2, 2, 393, 3, 2, 2, 2, 2, 395, 3, 2, 2, 2, 2, 397, 3, 2, 2, 2,
-	2, 399, 3, 2, 2, 2, 2, 401, 3, 2, 2, 2, 2, 403, 3, 2, 2, 2, 2, 405, 3,
-	2, 2, 2, 2, 407, 3, 2, 2, 2, 2, 409, 3, 2, 2, 2, 2, 411, 3, 2, 2, 2, 2,
-	413, 3, 2, 2, 2, 2, 415, 3, 2, 2, 2, 2, 417, 3, 2, 2, 2, 2, 419, 3, 2,
-	2, 2, 2, 427, 3, 2, 2, 2, 2, 429, 3, 2, 2, 2, 2, 431, 3, 2, 2, 2, 3, 433,
-	3, 2, 2, 2, 5, 435, 3, 2, 2, 2, 7, 437, 3, 2, 2, 2, 9, 439, 3, 2, 2, 2,
-	11, 441, 3, 2, 2, 2, 13, 444, 3, 2, 2, 2, 15, 446, 3, 2, 2, 2, 17, 448,
-	3, 2, 2, 2, 19, 451, 3, 2, 2, 2, 21, 453, 3, 2, 2, 2, 23, 460, 3, 2, 2,
-	2, 25, 465, 3, 2, 2, 2, 27, 469, 3, 2, 2, 2, 29, 472, 3, 2, 2, 2, 31, 476,
-	3, 2, 2, 2, 33, 481, 3, 2, 2, 2, 35, 485, 3, 2, 2, 2, 37, 494, 3, 2, 2,
-	2, 39, 500, 3, 2, 2, 2, 41, 506, 3, 2, 2, 2, 43, 509, 3, 2, 2, 2, 45, 518,
-	3, 2, 2, 2, 47, 523, 3, 2, 2, 2, 49, 528, 3, 2, 2, 2, 51, 535, 3, 2, 2,
-	2, 53, 541, 3, 2, 2, 2, 55, 548, 3, 2, 2, 2, 57, 554, 3, 2, 2, 2, 59, 557,
-	3, 2, 2, 2, 61, 560, 3, 2, 2, 2, 63, 564, 3, 2, 2, 2, 65, 567, 3, 2, 2,
-	2, 67, 571, 3, 2, 2, 2, 69, 574, 3, 2, 2, 2, 71, 581, 3, 2, 2, 2, 73, 589,
-	3, 2, 2, 2, 75, 594, 3, 2, 2, 2, 77, 597, 3, 2, 2, 2, 79, 602, 3, 2, 2,
-	2, 81, 607, 3, 2, 2, 2, 83, 613, 3, 2, 2, 2, 85, 619, 3, 2, 2, 2, 87, 625,
-	3, 2, 2, 2, 89, 630, 3, 2, 2, 2, 91, 637, 3, 2, 2, 2, 93, 641, 3, 2, 2,
-	2, 95, 646, 3, 2, 2, 2, 97, 656, 3, 2, 2, 2, 99, 665, 3, 2, 2, 2, 101,
-	669, 3, 2, 2, 2, 103, 677, 3, 2, 2, 2, 105, 686, 3, 2, 2, 2, 107, 694,
-	3, 2, 2, 2, 109, 699, 3, 2, 2, 2, 111, 704, 3, 2, 2, 2, 113, 714, 3, 2,
-	2, 2, 115, 723, 3, 2, 2, 2, 117, 728, 3, 2, 2, 2, 119, 734, 3, 2, 2, 2,
-	121, 738, 3, 2, 2, 2, 123, 743, 3, 2, 2, 2, 125, 750, 3, 2, 2, 2, 127,
-	757, 3, 2, 2, 2, 129, 762, 3, 2, 2, 2, 131, 775, 3, 2, 2, 2, 133, 788,
-	3, 2, 2, 2, 135, 806, 3, 2, 2, 2, 137, 816, 3, 2, 2, 2, 139, 831, 3, 2,
-	2, 2, 141, 839, 3, 2, 2, 2, 143, 844, 3, 2, 2, 2, 145, 849, 3, 2, 2, 2,
-	147, 854, 3, 2, 2, 2, 149, 859, 3, 2, 2, 2, 151, 863, 3, 2, 2, 2, 153,
-	868, 3, 2, 2, 2, 155, 874, 3, 2, 2, 2, 157, 880, 3, 2, 2, 2, 159, 886,
-	3, 2, 2, 2, 161, 891, 3, 2, 2, 2, 163, 897, 3, 2, 2, 2, 165, 902, 3, 2,
-	2, 2, 167, 910, 3, 2, 2, 2, 169, 916, 3, 2, 2, 2, 171, 919, 3, 2, 2, 2,
-	173, 926, 3, 2, 2, 2, 175, 931, 3, 2, 2, 2, 177, 941, 3, 2, 2, 2, 179,
-	947, 3, 2, 2, 2, 181, 952, 3, 2, 2, 2, 183, 962, 3, 2, 2, 2, 185, 972,
-	3, 2, 2, 2, 187, 982, 3, 2, 2, 2, 189, 990, 3, 2, 2, 2, 191, 994, 3, 2,
-	2, 2, 193, 999, 3, 2, 2, 2, 195, 1009, 3, 2, 2, 2, 197, 1016, 3, 2, 2,
-	2, 199, 1023, 3, 2, 2, 2, 201, 1030, 3, 2, 2, 2, 203, 1036, 3, 2, 2, 2,
-	205, 1044, 3, 2, 2, 2, 207, 1049, 3, 2, 2, 2, 209, 1057, 3, 2, 2, 2, 211,
-	1064, 3, 2, 2, 2, 213, 1071, 3, 2, 2, 2, 215, 1076, 3, 2, 2, 2, 217, 1087,
-	3, 2, 2, 2, 219, 1096, 3, 2, 2, 2, 221, 1102, 3, 2, 2, 2, 223, 1109, 3,
-	2, 2, 2, 225, 1120, 3, 2, 2, 2, 227, 1127, 3, 2, 2, 2, 229, 1134, 3, 2,
-	2, 2, 231, 1142, 3, 2, 2, 2, 233, 1150, 3, 2, 2, 2, 235, 1157, 3, 2, 2,
-	2, 237, 1162, 3, 2, 2, 2, 239, 1167, 3, 2, 2, 2, 241, 1176, 3, 2, 2, 2,
-	243, 1184, 3, 2, 2, 2, 245, 1196, 3, 2, 2, 2, 247, 1205, 3, 2, 2, 2, 249,
-	1210, 3, 2, 2, 2, 251, 1219, 3, 2, 2, 2, 253, 1224, 3, 2, 2, 2, 255, 1231,
-	3, 2, 2, 2, 257, 1239, 3, 2, 2, 2, 259, 1248, 3, 2, 2, 2, 261, 1256, 3,
-	2, 2, 2, 263, 1263, 3, 2, 2, 2, 265, 1267, 3, 2, 2, 2, 267, 1278, 3, 2,
-	2, 2, 269, 1288, 3, 2, 2, 2, 271, 1293, 3, 2, 2, 2, 273, 1299, 3, 2, 2,
-	2, 275, 1306, 3, 2, 2, 2, 277, 1316, 3, 2, 2, 2, 279, 1319, 3, 2, 2, 2,
-	281, 1326, 3, 2, 2, 2, 283, 1336, 3, 2, 2, 2, 285, 1348, 3, 2, 2, 2, 287,
-	1360, 3, 2, 2, 2, 289, 1366, 3, 2, 2, 2, 291, 1373, 3, 2, 2, 2, 293, 1380,
-	3, 2, 2, 2, 295, 1391, 3, 2, 2, 2, 297, 1397, 3, 2, 2, 2, 299, 1401, 3,
-	2, 2, 2, 301, 1405, 3, 2, 2, 2, 303, 1411, 3, 2, 2, 2, 305, 1419, 3, 2,
-	2, 2, 307, 1424, 3, 2, 2, 2, 309, 1430, 3, 2, 2, 2, 311, 1442, 3, 2, 2,
-	2, 313, 1449, 3, 2, 2, 2, 315, 1458, 3, 2, 2, 2, 317, 1463, 3, 2, 2, 2,
-	319, 1473, 3, 2, 2, 2, 321, 1479, 3, 2, 2, 2, 323, 1492, 3, 2, 2, 2, 325,
-	1503, 3, 2, 2, 2, 327, 1513, 3, 2, 2, 2, 329, 1525, 3, 2, 2, 2, 331, 1530,
-	3, 2, 2, 2, 333, 1536, 3, 2, 2, 2, 335, 1541, 3, 2, 2, 2, 337, 1546, 3,
-	2, 2, 2, 339, 1554, 3, 2, 2, 2, 341, 1565, 3, 2, 2, 2, 343, 1573, 3, 2,
-	2, 2, 345, 1579, 3, 2, 2, 2, 347, 1586, 3, 2, 2, 2, 349, 1594, 3, 2, 2,
-	2, 351, 1603, 3, 2, 2, 2, 353, 1613, 3, 2, 2, 2, 355, 1623, 3, 2, 2, 2,
-	357, 1634, 3, 2, 2, 2, 359, 1644, 3, 2, 2, 2, 361, 1648, 3, 2, 2, 2, 363,
-	1652, 3, 2, 2, 2, 365, 1657, 3, 2, 2, 2, 367, 1662, 3, 2, 2, 2, 369, 1665,
-	3, 2, 2, 2, 371, 1672, 3, 2, 2, 2, 373, 1681, 3, 2, 2, 2, 375, 1701, 3,
-	2, 2, 2, 377, 1726, 3, 2, 2, 2, 379, 1743, 3, 2, 2, 2, 381, 1749, 3, 2,
-	2, 2, 383, 1751, 3, 2, 2, 2, 385, 1753, 3, 2, 2, 2, 387, 1756, 3, 2, 2,
-	2, 389, 1758, 3, 2, 2, 2, 391, 1761, 3, 2, 2, 2, 393, 1763, 3, 2, 2, 2,
-	395, 1765, 3, 2, 2, 2, 397, 1767, 3, 2, 2, 2, 399, 1769, 3, 2, 2, 2, 401,
-	1771, 3, 2, 2, 2, 403, 1774, 3, 2, 2, 2, 405, 1776, 3, 2, 2, 2, 407, 1787,
-	3, 2, 2, 2, 409, 1799, 3, 2, 2, 2, 411, 1845, 3, 2, 2, 2, 413, 1849, 3,
-	2, 2, 2, 415, 1859, 3, 2, 2, 2, 417, 1867, 3, 2, 2, 2, 419, 1878, 3, 2,
-	2, 2, 421, 1889, 3, 2, 2, 2, 423, 1898, 3, 2, 2, 2, 425, 1900, 3, 2, 2,
-	2, 427, 1902, 3, 2, 2, 2, 429, 1919, 3, 2, 2, 2, 431, 1934, 3, 2, 2, 2,
-	433, 434, 7, 42, 2, 2, 434, 4, 3, 2, 2, 2, 435, 436, 7, 46, 2, 2, 436,
-	6, 3, 2, 2, 2, 437, 438, 7, 43, 2, 2, 438, 8, 3, 2, 2, 2, 439, 440, 7,
-	65, 2, 2, 440, 10, 3, 2, 2, 2, 441, 442, 7, 47, 2, 2, 442, 443, 7, 64,
-	2, 2, 443, 12, 3, 2, 2, 2, 444, 445, 7, 93, 2, 2, 445, 14, 3, 2, 2, 2,
-	446, 447, 7, 95, 2, 2, 447, 16, 3, 2, 2, 2, 448, 449, 7, 63, 2, 2, 449,
-	450, 7, 64, 2, 2, 450, 18, 3, 2, 2, 2, 451, 452, 7, 61, 2, 2, 452, 20,
-	3, 2, 2, 2, 453, 454, 9, 2, 2, 2, 454, 455, 9, 3, 2, 2, 455, 456, 9, 4,
-	2, 2, 456, 457, 9, 3, 2, 2, 457, 458, 9, 5, 2, 2, 458, 459, 9, 6, 2, 2,
-	459, 22, 3, 2, 2, 2, 460, 461, 9, 7, 2, 2, 461, 462, 9, 8, 2, 2, 462, 463,
-	9, 9, 2, 2, 463, 464, 9, 10, 2, 2, 464, 24, 3, 2, 2, 2, 465, 466, 9, 11,
-	2, 2, 466, 467, 9, 12, 2, 2, 467, 468, 9, 12, 2, 2, 468, 26, 3, 2, 2, 2,
-	469, 470, 9, 11, 2, 2, 470, 471, 9, 2, 2, 2, 471, 28, 3, 2, 2, 2, 472,
-	473, 9, 11, 2, 2, 473, 474, 9, 4, 2, 2, 474, 475, 9, 4, 2, 2, 475, 30,
-	3, 2, 2, 2, 476, 477, 9, 2, 2, 2, 477, 478, 9, 9, 2, 2, 478, 479, 9, 10,
-	2, 2, 479, 480, 9, 3, 2, 2, 480, 32, 3, 2, 2, 2, 481, 482, 9, 11, 2, 2,
-	482, 483, 9, 13, 2, 2, 483, 484, 9, 14, 2, 2, 484, 34, 3, 2, 2, 2, 485,
-	486, 9, 12, 2, 2, 486, 487, 9, 15, 2, 2, 487, 488, 9, 2, 2, 2, 488, 489,
-	9, 6, 2, 2, 489, 490, 9, 15, 2, 2, 490, 491, 9, 13, 2, 2, 491, 492, 9,
-	5, 2, 2, 492, 493, 9, 6, 2, 2, 493, 36, 3, 2, 2, 2, 494, 495, 9, 16, 2,
-	2, 495, 496, 9, 17, 2, 2, 496, 497, 9, 3, 2, 2, 497, 498, 9, 8, 2, 2, 498,
-	499, 9, 3, 2, 2, 499, 38, 3, 2, 2, 2, 500, 501, 9, 18, 2, 2, 501, 502,
-	9, 8, 2, 2, 502, 503, 9, 9, 2, 2, 503, 504, 9, 19, 2, 2, 504, 505, 9, 20,
-	2, 2, 505, 40, 3, 2, 2, 2, 506, 507, 9, 21, 2, 2, 507, 508, 9, 14, 2, 2,
-	508, 42, 3, 2, 2, 2, 509, 510, 9, 18, 2, 2, 510, 511, 9, 8, 2, 2, 511,
-	512, 9, 9, 2, 2, 512, 513, 9, 19, 2, 2, 513, 514, 9, 20, 2, 2, 514, 515,
-	9, 15, 2, 2, 515, 516, 9, 13, 2, 2, 516, 517, 9, 18, 2, 2, 517, 44, 3,
-	2, 2, 2, 518, 519, 9, 2, 2, 2, 519, 520, 9, 3, 2, 2, 520, 521, 9, 6, 2,
-	2, 521, 522, 9, 2, 2, 2, 522, 46, 3, 2, 2, 2, 523, 524, 9, 5, 2, 2, 524,
-	525, 9, 19, 2, 2, 525, 526, 9, 21, 2, 2, 526, 527, 9, 3, 2, 2, 527, 48,
-	3, 2, 2, 2, 528, 529, 9, 8, 2, 2, 529, 530, 9, 9, 2, 2, 530, 531, 9, 4,
-	2, 2, 531, 532, 9, 4, 2, 2, 532, 533, 9, 19, 2, 2, 533, 534, 9, 20, 2,
-	2, 534, 50, 3, 2, 2, 2, 535, 536, 9, 9, 2, 2, 536, 537, 9, 8, 2, 2, 537,
-	538, 9, 12, 2, 2, 538, 539, 9, 3, 2, 2, 539, 540, 9, 8, 2, 2, 540, 52,
-	3, 2, 2, 2, 541, 542, 9, 17, 2, 2, 542, 543, 9, 11, 2, 2, 543, 544, 9,
-	22, 2, 2, 544, 545, 9, 15, 2, 2, 545, 546, 9, 13, 2, 2, 546, 547, 9, 18,
-	2, 2, 547, 54, 3, 2, 2, 2, 548, 549, 9, 4, 2, 2, 549, 550, 9, 15, 2, 2,
-	550, 551, 9, 10, 2, 2, 551, 552, 9, 15, 2, 2, 552, 553, 9, 6, 2, 2, 553,
-	56, 3, 2, 2, 2, 554, 555, 9, 11, 2, 2, 555, 556, 9, 6, 2, 2, 556, 58, 3,
-	2, 2, 2, 557, 558, 9, 9, 2, 2, 558, 559, 9, 8, 2, 2, 559, 60, 3, 2, 2,
-	2, 560, 561, 9, 11, 2, 2, 561, 562, 9, 13, 2, 2, 562, 563, 9, 12, 2, 2,
-	563, 62, 3, 2, 2, 2, 564, 565, 9, 15, 2, 2, 565, 566, 9, 13, 2, 2, 566,
-	64, 3, 2, 2, 2, 567, 568, 9, 13, 2, 2, 568, 569, 9, 9, 2, 2, 569, 570,
-	9, 6, 2, 2, 570, 66, 3, 2, 2, 2, 571, 572, 9, 13, 2, 2, 572, 573, 9, 9,
-	2, 2, 573, 68, 3, 2, 2, 2, 574, 575, 9, 3, 2, 2, 575, 576, 9, 23, 2, 2,
-	576, 577, 9, 15, 2, 2, 577, 578, 9, 2, 2, 2, 578, 579, 9, 6, 2, 2, 579,
-	580, 9, 2, 2, 2, 580, 70, 3, 2, 2, 2, 581, 582, 9, 21, 2, 2, 582, 583,
-	9, 3, 2, 2, 583, 584, 9, 6, 2, 2, 584, 585, 9, 16, 2, 2, 585, 586, 9, 3,
-	2, 2, 586, 587, 9, 3, 2, 2, 587, 588, 9, 13, 2, 2, 588, 72, 3, 2, 2, 2,
-	589, 590, 9, 4, 2, 2, 590, 591, 9, 15, 2, 2, 591, 592, 9, 24, 2, 2, 592,
-	593, 9, 3, 2, 2, 593, 74, 3, 2, 2, 2, 594, 595, 9, 15, 2, 2, 595, 596,
-	9, 2, 2, 2, 596, 76, 3, 2, 2, 2, 597, 598, 9, 13, 2, 2, 598, 599, 9, 19,
-	2, 2, 599, 600, 9, 4, 2, 2, 600, 601, 9, 4, 2, 2, 601, 78, 3, 2, 2, 2,
-	602, 603, 9, 6, 2, 2, 603, 604, 9, 8, 2, 2, 604, 605, 9, 19, 2, 2, 605,
-	606, 9, 3, 2, 2, 606, 80, 3, 2, 2, 2, 607, 608, 9, 7, 2, 2, 608, 609, 9,
-	11, 2, 2, 609, 610, 9, 4, 2, 2, 610, 611, 9, 2, 2, 2, 611, 612, 9, 3, 2,
-	2, 612, 82, 3, 2, 2, 2, 613, 614, 9, 13, 2, 2, 614, 615, 9, 19, 2, 2, 615,
-	616, 9, 4, 2, 2, 616, 617, 9, 4, 2, 2, 617, 618, 9, 2, 2, 2, 618, 84, 3,
-	2, 2, 2, 619, 620, 9, 7, 2, 2, 620, 621, 9, 15, 2, 2, 621, 622, 9, 8, 2,
-	2, 622, 623, 9, 2, 2, 2, 623, 624, 9, 6, 2, 2, 624, 86, 3, 2, 2, 2, 625,
-	626, 9, 4, 2, 2, 626, 627, 9, 11, 2, 2, 627, 628, 9, 2, 2, 2, 628, 629,
-	9, 6, 2, 2, 629, 88, 3, 2, 2, 2, 630, 631, 9, 3, 2, 2, 631, 632, 9, 2,
-	2, 2, 632, 633, 9, 5, 2, 2, 633, 634, 9, 11, 2, 2, 634, 635, 9, 20, 2,
-	2, 635, 636, 9, 3, 2, 2, 636, 90, 3, 2, 2, 2, 637, 638, 9, 11, 2, 2, 638,
-	639, 9, 2, 2, 2, 639, 640, 9, 5, 2, 2, 640, 92, 3, 2, 2, 2, 641, 642, 9,
-	12, 2, 2, 642, 643, 9, 3, 2, 2, 643, 644, 9, 2, 2, 2, 644, 645, 9, 5, 2,
-	2, 645, 94, 3, 2, 2, 2, 646, 647, 9, 2, 2, 2, 647, 648, 9, 19, 2, 2, 648,
-	649, 9, 21, 2, 2, 649, 650, 9, 2, 2, 2, 650, 651, 9, 6, 2, 2, 651, 652,
-	9, 8, 2, 2, 652, 653, 9, 15, 2, 2, 653, 654, 9, 13, 2, 2, 654, 655, 9,
-	18, 2, 2, 655, 96, 3, 2, 2, 2, 656, 657, 9, 20, 2, 2, 657, 658, 9, 9, 2,
-	2, 658, 659, 9, 2, 2, 2, 659, 660, 9, 15, 2, 2, 660, 661, 9, 6, 2, 2, 661,
-	662, 9, 15, 2, 2, 662, 663, 9, 9, 2, 2, 663, 664, 9, 13, 2, 2, 664, 98,
-	3, 2, 2, 2, 665, 666, 9, 7, 2, 2, 666, 667, 9, 9, 2, 2, 667, 668, 9, 8,
-	2, 2, 668, 100, 3, 2, 2, 2, 669, 670, 9, 6, 2, 2, 670, 671, 9, 15, 2, 2,
-	671, 672, 9, 13, 2, 2, 672, 673, 9, 14, 2, 2, 673, 674, 9, 15, 2, 2, 674,
-	675, 9, 13, 2, 2, 675, 676, 9, 6, 2, 2, 676, 102, 3, 2, 2, 2, 677, 678,
-	9, 2, 2, 2, 678, 679, 9, 10, 2, 2, 679, 680, 9, 11, 2, 2, 680, 681, 9,
-	4, 2, 2, 681, 682, 9, 4, 2, 2, 682, 683, 9, 15, 2, 2, 683, 684, 9, 13,
-	2, 2, 684, 685, 9, 6, 2, 2, 685, 104, 3, 2, 2, 2, 686, 687, 9, 15, 2, 2,
-	687, 688, 9, 13, 2, 2, 688, 689, 9, 6, 2, 2, 689, 690, 9, 3, 2, 2, 690,
-	691, 9, 18, 2, 2, 691, 692, 9, 3, 2, 2, 692, 693, 9, 8, 2, 2, 693, 106,
-	3, 2, 2, 2, 694, 695, 9, 12, 2, 2, 695, 696, 9, 11, 2, 2, 696, 697, 9,
-	6, 2, 2, 697, 698, 9, 3, 2, 2, 698, 108, 3, 2, 2, 2, 699, 700, 9, 6, 2,
-	2, 700, 701, 9, 15, 2, 2, 701, 702, 9, 10, 2, 2, 702, 703, 9, 3, 2, 2,
-	703, 110, 3, 2, 2, 2, 704, 705, 9, 6, 2, 2, 705, 706, 9, 15, 2, 2, 706,
-	707, 9, 10, 2, 2, 707, 708, 9, 3, 2, 2, 708, 709, 9, 2, 2, 2, 709, 710,
-	9, 6, 2, 2, 710, 711, 9, 11, 2, 2, 711, 712, 9, 10, 2, 2, 712, 713, 9,
-	20, 2, 2, 713, 112, 3, 2, 2, 2, 714, 715, 9, 15, 2, 2, 715, 716, 9, 13,
-	2, 2, 716, 717, 9, 6, 2, 2, 717, 718, 9, 3, 2, 2, 718, 719, 9, 8, 2, 2,
-	719, 720, 9, 22, 2, 2, 720, 721, 9, 11, 2, 2, 721, 722, 9, 4, 2, 2, 722,
-	114, 3, 2, 2, 2, 723, 724, 9, 14, 2, 2, 724, 725, 9, 3, 2, 2, 725, 726,
-	9, 11, 2, 2, 726, 727, 9, 8, 2, 2, 727, 116, 3, 2, 2, 2, 728, 729, 9, 10,
-	2, 2, 729, 730, 9, 9, 2, 2, 730, 731, 9, 13, 2, 2, 731, 732, 9, 6, 2, 2,
-	732, 733, 9, 17, 2, 2, 733, 118, 3, 2, 2, 2, 734, 735, 9, 12, 2, 2, 735,
-	736, 9, 11, 2, 2, 736, 737, 9, 14, 2, 2, 737, 120, 3, 2, 2, 2, 738, 739,
-	9, 17, 2, 2, 739, 740, 9, 9, 2, 2, 740, 741, 9, 19, 2, 2, 741, 742, 9,
-	8, 2, 2, 742, 122, 3, 2, 2, 2, 743, 744, 9, 10, 2, 2, 744, 745, 9, 15,
-	2, 2, 745, 746, 9, 13, 2, 2, 746, 747, 9, 19, 2, 2, 747, 748, 9, 6, 2,
-	2, 748, 749, 9, 3, 2, 2, 749, 124, 3, 2, 2, 2, 750, 751, 9, 2, 2, 2, 751,
-	752, 9, 3, 2, 2, 752, 753, 9, 5, 2, 2, 753, 754, 9, 9, 2, 2, 754, 755,
-	9, 13, 2, 2, 755, 756, 9, 12, 2, 2, 756, 126, 3, 2, 2, 2, 757, 758, 9,
-	25, 2, 2, 758, 759, 9, 9, 2, 2, 759, 760, 9, 13, 2, 2, 760, 761, 9, 3,
-	2, 2, 761, 128, 3, 2, 2, 2, 762, 763, 9, 5, 2, 2, 763, 764, 9, 19, 2, 2,
-	764, 765, 9, 8, 2, 2, 765, 766, 9, 8, 2, 2, 766, 767, 9, 3, 2, 2, 767,
-	768, 9, 13, 2, 2, 768, 769, 9, 6, 2, 2, 769, 770, 7, 97, 2, 2, 770, 771,
-	9, 12, 2, 2, 771, 772, 9, 11, 2, 2, 772, 773, 9, 6, 2, 2, 773, 774, 9,
-	3, 2, 2, 774, 130, 3, 2, 2, 2, 775, 776, 9, 5, 2, 2, 776, 777, 9, 19, 2,
-	2, 777, 778, 9, 8, 2, 2, 778, 779, 9, 8, 2, 2, 779, 780, 9, 3, 2, 2, 780,
-	781, 9, 13, 2, 2, 781, 782, 9, 6, 2, 2, 782, 783, 7, 97, 2, 2, 783, 784,
-	9, 6, 2, 2, 784, 785, 9, 15, 2, 2, 785, 786, 9, 10, 2, 2, 786, 787, 9,
-	3, 2, 2, 787, 132, 3, 2, 2, 2, 788, 789, 9, 5, 2, 2, 789, 790, 9, 19, 2,
-	2, 790, 791, 9, 8, 2, 2, 791, 792, 9, 8, 2, 2, 792, 793, 9, 3, 2, 2, 793,
-	794, 9, 13, 2, 2, 794, 795, 9, 6, 2, 2, 795, 796, 7, 97, 2, 2, 796, 797,
-	9, 6, 2, 2, 797, 798, 9, 15, 2, 2, 798, 799, 9, 10, 2, 2, 799, 800, 9,
-	3, 2, 2, 800, 801, 9, 2, 2, 2, 801, 802, 9, 6, 2, 2, 802, 803, 9, 11, 2,
-	2, 803, 804, 9, 10, 2, 2, 804, 805, 9, 20, 2, 2, 805, 134, 3, 2, 2, 2,
-	806, 807, 9, 4, 2, 2, 807, 808, 9, 9, 2, 2, 808, 809, 9, 5, 2, 2, 809,
-	810, 9, 11, 2, 2, 810, 811, 9, 4, 2, 2, 811, 812, 9, 6, 2, 2, 812, 813,
-	9, 15, 2, 2, 813, 814, 9, 10, 2, 2, 814, 815, 9, 3, 2, 2, 815, 136, 3,
-	2, 2, 2, 816, 817, 9, 4, 2, 2, 817, 818, 9, 9, 2, 2, 818, 819, 9, 5, 2,
-	2, 819, 820, 9, 11, 2, 2, 820, 821, 9, 4, 2, 2, 821, 822, 9, 6, 2, 2, 822,
-	823, 9, 15, 2, 2, 823, 824, 9, 10, 2, 2, 824, 825, 9, 3, 2, 2, 825, 826,
-	9, 2, 2, 2, 826, 827, 9, 6, 2, 2, 827, 828, 9, 11, 2, 2, 828, 829, 9, 10,
-	2, 2, 829, 830, 9, 20, 2, 2, 830, 138, 3, 2, 2, 2, 831, 832, 9, 3, 2, 2,
-	832, 833, 9, 23, 2, 2, 833, 834, 9, 6, 2, 2, 834, 835, 9, 8, 2, 2, 835,
-	836, 9, 11, 2, 2, 836, 837, 9, 5, 2, 2, 837, 838, 9, 6, 2, 2, 838, 140,
-	3, 2, 2, 2, 839, 840, 9, 5, 2, 2, 840, 841, 9, 11, 2, 2, 841, 842, 9, 2,
-	2, 2, 842, 843, 9, 3, 2, 2, 843, 142, 3, 2, 2, 2, 844, 845, 9, 16, 2, 2,
-	845, 846, 9, 17, 2, 2, 846, 847, 9, 3, 2, 2, 847, 848, 9, 13, 2, 2, 848,
-	144, 3, 2, 2, 2, 849, 850, 9, 6, 2, 2, 850, 851, 9, 17, 2, 2, 851, 852,
-	9, 3, 2, 2, 852, 853, 9, 13, 2, 2, 853, 146, 3, 2, 2, 2, 854, 855, 9, 3,
-	2, 2, 855, 856, 9, 4, 2, 2, 856, 857, 9, 2, 2, 2, 857, 858, 9, 3, 2, 2,
-	858, 148, 3, 2, 2, 2, 859, 860, 9, 3, 2, 2, 860, 861, 9, 13, 2, 2, 861,
-	862, 9, 12, 2, 2, 862, 150, 3, 2, 2, 2, 863, 864, 9, 26, 2, 2, 864, 865,
-	9, 9, 2, 2, 865, 866, 9, 15, 2, 2, 866, 867, 9, 13, 2, 2, 867, 152, 3,
-	2, 2, 2, 868, 869, 9, 5, 2, 2, 869, 870, 9, 8, 2, 2, 870, 871, 9, 9, 2,
-	2, 871, 872, 9, 2, 2, 2, 872, 873, 9, 2, 2, 2, 873, 154, 3, 2, 2, 2, 874,
-	875, 9, 9, 2, 2, 875, 876, 9, 19, 2, 2, 876, 877, 9, 6, 2, 2, 877, 878,
-	9, 3, 2, 2, 878, 879, 9, 8, 2, 2, 879, 156, 3, 2, 2, 2, 880, 881, 9, 15,
-	2, 2, 881, 882, 9, 13, 2, 2, 882, 883, 9, 13, 2, 2, 883, 884, 9, 3, 2,
-	2, 884, 885, 9, 8, 2, 2, 885, 158, 3, 2, 2, 2, 886, 887, 9, 4, 2, 2, 887,
-	888, 9, 3, 2, 2, 888, 889, 9, 7, 2, 2, 889, 890, 9, 6, 2, 2, 890, 160,
-	3, 2, 2, 2, 891, 892, 9, 8, 2, 2, 892, 893, 9, 15, 2, 2, 893, 894, 9, 18,
-	2, 2, 894, 895, 9, 17, 2, 2, 895, 896, 9, 6, 2, 2, 896, 162, 3, 2, 2, 2,
-	897, 898, 9, 7, 2, 2, 898, 899, 9, 19, 2, 2, 899, 900, 9, 4, 2, 2, 900,
-	901, 9, 4, 2, 2, 901, 164, 3, 2, 2, 2, 902, 903, 9, 13, 2, 2, 903, 904,
-	9, 11, 2, 2, 904, 905, 9, 6, 2, 2, 905, 906, 9, 19, 2, 2, 906, 907, 9,
-	8, 2, 2, 907, 908, 9, 11, 2, 2, 908, 909, 9, 4, 2, 2, 909, 166, 3, 2, 2,
-	2, 910, 911, 9, 19, 2, 2, 911, 912, 9, 2, 2, 2, 912, 913, 9, 15, 2, 2,
-	913, 914, 9, 13, 2, 2, 914, 915, 9, 18, 2, 2, 915, 168, 3, 2, 2, 2, 916,
-	917, 9, 9, 2, 2, 917, 918, 9, 13, 2, 2, 918, 170, 3, 2, 2, 2, 919, 920,
-	9, 7, 2, 2, 920, 921, 9, 15, 2, 2, 921, 922, 9, 4, 2, 2, 922, 923, 9, 6,
-	2, 2, 923, 924, 9, 3, 2, 2, 924, 925, 9, 8, 2, 2, 925, 172, 3, 2, 2, 2,
-	926, 927, 9, 9, 2, 2, 927, 928, 9, 22, 2, 2, 928, 929, 9, 3, 2, 2, 929,
-	930, 9, 8, 2, 2, 930, 174, 3, 2, 2, 2, 931, 932, 9, 20, 2, 2, 932, 933,
-	9, 11, 2, 2, 933, 934, 9, 8, 2, 2, 934, 935, 9, 6, 2, 2, 935, 936, 9, 15,
-	2, 2, 936, 937, 9, 6, 2, 2, 937, 938, 9, 15, 2, 2, 938, 939, 9, 9, 2, 2,
-	939, 940, 9, 13, 2, 2, 940, 176, 3, 2, 2, 2, 941, 942, 9, 8, 2, 2, 942,
-	943, 9, 11, 2, 2, 943, 944, 9, 13, 2, 2, 944, 945, 9, 18, 2, 2, 945, 946,
-	9, 3, 2, 2, 946, 178, 3, 2, 2, 2, 947, 948, 9, 8, 2, 2, 948, 949, 9, 9,
-	2, 2, 949, 950, 9, 16, 2, 2, 950, 951, 9, 2, 2, 2, 951, 180, 3, 2, 2, 2,
-	952, 953, 9, 19, 2, 2, 953, 954, 9, 13, 2, 2, 954, 955, 9, 21, 2, 2, 955,
-	956, 9, 9, 2, 2, 956, 957, 9, 19, 2, 2, 957, 958, 9, 13, 2, 2, 958, 959,
-	9, 12, 2, 2, 959, 960, 9, 3, 2, 2, 960, 961, 9, 12, 2, 2, 961, 182, 3,
-	2, 2, 2, 962, 963, 9, 20, 2, 2, 963, 964, 9, 8, 2, 2, 964, 965, 9, 3, 2,
-	2, 965, 966, 9, 5, 2, 2, 966, 967, 9, 3, 2, 2, 967, 968, 9, 12, 2, 2, 968,
-	969, 9, 15, 2, 2, 969, 970, 9, 13, 2, 2, 970, 971, 9, 18, 2, 2, 971, 184,
-	3, 2, 2, 2, 972, 973, 9, 7, 2, 2, 973, 974, 9, 9, 2, 2, 974, 975, 9, 4,
-	2, 2, 975, 976, 9, 4, 2, 2, 976, 977, 9, 9, 2, 2, 977, 978, 9, 16, 2, 2,
-	978, 979, 9, 15, 2, 2, 979, 980, 9, 13, 2, 2, 980, 981, 9, 18, 2, 2, 981,
-	186, 3, 2, 2, 2, 982, 983, 9, 5, 2, 2, 983, 984, 9, 19, 2, 2, 984, 985,
-	9, 8, 2, 2, 985, 986, 9, 8, 2, 2, 986, 987, 9, 3, 2, 2, 987, 988, 9, 13,
-	2, 2, 988, 989, 9, 6, 2, 2, 989, 188, 3, 2, 2, 2, 990, 991, 9, 8, 2, 2,
-	991, 992, 9, 9, 2, 2, 992, 993, 9, 16, 2, 2, 993, 190, 3, 2, 2, 2, 994,
-	995, 9, 16, 2, 2, 995, 996, 9, 15, 2, 2, 996, 997, 9, 6, 2, 2, 997, 998,
-	9, 17, 2, 2, 998, 192, 3, 2, 2, 2, 999, 1000, 9, 8, 2, 2, 1000, 1001, 9,
-	3, 2, 2, 1001, 1002, 9, 5, 2, 2, 1002, 1003, 9, 19, 2, 2, 1003, 1004, 9,
-	8, 2, 2, 1004, 1005, 9, 2, 2, 2, 1005, 1006, 9, 15, 2, 2, 1006, 1007, 9,
-	22, 2, 2, 1007, 1008, 9, 3, 2, 2, 1008, 194, 3, 2, 2, 2, 1009, 1010, 9,
-	22, 2, 2, 1010, 1011, 9, 11, 2, 2, 1011, 1012, 9, 4, 2, 2, 1012, 1013,
-	9, 19, 2, 2, 1013, 1014, 9, 3, 2, 2, 1014, 1015, 9, 2, 2, 2, 1015, 196,
-	3, 2, 2, 2, 1016, 1017, 9, 5, 2, 2, 1017, 1018, 9, 8, 2, 2, 1018, 1019,
-	9, 3, 2, 2, 1019, 1020, 9, 11, 2, 2, 1020, 1021, 9, 6, 2, 2, 1021, 1022,
-	9, 3, 2, 2, 1022, 198, 3, 2, 2, 2, 1023, 1024, 9, 2, 2, 2, 1024, 1025,
-	9, 5, 2, 2, 1025, 1026, 9, 17, 2, 2, 1026, 1027, 9, 3, 2, 2, 1027, 1028,
-	9, 10, 2, 2, 1028, 1029, 9, 11, 2, 2, 1029, 200, 3, 2, 2, 2, 1030, 1031,
-	9, 6, 2, 2, 1031, 1032, 9, 11, 2, 2, 1032, 1033, 9, 21, 2, 2, 1033, 1034,
-	9, 4, 2, 2, 1034, 1035, 9, 3, 2, 2, 1035, 202, 3, 2, 2, 2, 1036, 1037,
-	9, 5, 2, 2, 1037, 1038, 9, 9, 2, 2, 1038, 1039, 9, 10, 2, 2, 1039, 1040,
-	9, 10, 2, 2, 1040, 1041, 9, 3, 2, 2, 1041, 1042, 9, 13, 2, 2, 1042, 1043,
-	9, 6, 2, 2, 1043, 204, 3, 2, 2, 2, 1044, 1045, 9, 22, 2, 2, 1045, 1046,
-	9, 15, 2, 2, 1046, 1047, 9, 3, 2, 2, 1047, 1048, 9, 16, 2, 2, 1048, 206,
-	3, 2, 2, 2, 1049, 1050, 9, 8, 2, 2, 1050, 1051, 9, 3, 2, 2, 1051, 1052,
-	9, 20, 2, 2, 1052, 1053, 9, 4, 2, 2, 1053, 1054, 9, 11, 2, 2, 1054, 1055,
-	9, 5, 2, 2, 1055, 1056, 9, 3, 2, 2, 1056, 208, 3, 2, 2, 2, 1057, 1058,
-	9, 15, 2, 2, 1058, 1059, 9, 13, 2, 2, 1059, 1060, 9, 2, 2, 2, 1060, 1061,
-	9, 3, 2, 2, 1061, 1062, 9, 8, 2, 2, 1062, 1063, 9, 6, 2, 2, 1063, 210,
-	3, 2, 2, 2, 1064, 1065, 9, 12, 2, 2, 1065, 1066, 9, 3, 2, 2, 1066, 1067,
-	9, 4, 2, 2, 1067, 1068, 9, 3, 2, 2, 1068, 1069, 9, 6, 2, 2, 1069, 1070,
-	9, 3, 2, 2, 1070, 212, 3, 2, 2, 2, 1071, 1072, 9, 15, 2, 2, 1072, 1073,
-	9, 13, 2, 2, 1073, 1074, 9, 6, 2, 2, 1074, 1075, 9, 9, 2, 2, 1075, 214,
-	3, 2, 2, 2, 1076, 1077, 9, 5, 2, 2, 1077, 1078, 9, 9, 2, 2, 1078, 1079,
-	9, 13, 2, 2, 1079, 1080, 9, 2, 2, 2, 1080, 1081, 9, 6, 2, 2, 1081, 1082,
-	9, 8, 2, 2, 1082, 1083, 9, 11, 2, 2, 1083, 1084, 9, 15, 2, 2, 1084, 1085,
-	9, 13, 2, 2, 1085, 1086, 9, 6, 2, 2, 1086, 216, 3, 2, 2, 2, 1087, 1088,
-	9, 12, 2, 2, 1088, 1089, 9, 3, 2, 2, 1089, 1090, 9, 2, 2, 2, 1090, 1091,
-	9, 5, 2, 2, 1091, 1092, 9, 8, 2, 2, 1092, 1093, 9, 15, 2, 2, 1093, 1094,
-	9, 21, 2, 2, 1094, 1095, 9, 3, 2, 2, 1095, 218, 3, 2, 2, 2, 1096, 1097,
-	9, 18, 2, 2, 1097, 1098, 9, 8, 2, 2, 1098, 1099, 9, 11, 2, 2, 1099, 1100,
-	9, 13, 2, 2, 1100, 1101, 9, 6, 2, 2, 1101, 220, 3, 2, 2, 2, 1102, 1103,
-	9, 8, 2, 2, 1103, 1104, 9, 3, 2, 2, 1104, 1105, 9, 22, 2, 2, 1105, 1106,
-	9, 9, 2, 2, 1106, 1107, 9, 24, 2, 2, 1107, 1108, 9, 3, 2, 2, 1108, 222,
-	3, 2, 2, 2, 1109, 1110, 9, 20, 2, 2, 1110, 1111, 9, 8, 2, 2, 1111, 1112,
-	9, 15, 2, 2, 1112, 1113, 9, 22, 2, 2, 1113, 1114, 9, 15, 2, 2, 1114, 1115,
-	9, 4, 2, 2, 1115, 1116, 9, 3, 2, 2, 1116, 1117, 9, 18, 2, 2, 1117, 1118,
-	9, 3, 2, 2, 1118, 1119, 9, 2, 2, 2, 1119, 224, 3, 2, 2, 2, 1120, 1121,
-	9, 20, 2, 2, 1121, 1122, 9, 19, 2, 2, 1122, 1123, 9, 21, 2, 2, 1123, 1124,
-	9, 4, 2, 2, 1124, 1125, 9, 15, 2, 2, 1125, 1126, 9, 5, 2, 2, 1126, 226,
-	3, 2, 2, 2, 1127, 1128, 9, 9, 2, 2, 1128, 1129, 9, 20, 2, 2, 1129, 1130,
-	9, 6, 2, 2, 1130, 1131, 9, 15, 2, 2, 1131, 1132, 9, 9, 2, 2, 1132, 1133,
-	9, 13, 2, 2, 1133, 228, 3, 2, 2, 2, 1134, 1135, 9, 3, 2, 2, 1135, 1136,
-	9, 23, 2, 2, 1136, 1137, 9, 20, 2, 2, 1137, 1138, 9, 4, 2, 2, 1138, 1139,
-	9, 11, 2, 2, 1139, 1140, 9, 15, 2, 2, 1140, 1141, 9, 13, 2, 2, 1141, 230,
-	3, 2, 2, 2, 1142, 1143, 9, 11, 2, 2, 1143, 1144, 9, 13, 2, 2, 1144, 1145,
-	9, 11, 2, 2, 1145, 1146, 9, 4, 2, 2, 1146, 1147, 9, 14, 2, 2, 1147, 1148,
-	9, 25, 2, 2, 1148, 1149, 9, 3, 2, 2, 1149, 232, 3, 2, 2, 2, 1150, 1151,
-	9, 7, 2, 2, 1151, 1152, 9, 9, 2, 2, 1152, 1153, 9, 8, 2, 2, 1153, 1154,
-	9, 10, 2, 2, 1154, 1155, 9, 11, 2, 2, 1155, 1156, 9, 6, 2, 2, 1156, 234,
-	3, 2, 2, 2, 1157, 1158, 9, 6, 2, 2, 1158, 1159, 9, 14, 2, 2, 1159, 1160,
-	9, 20, 2, 2, 1160, 1161, 9, 3, 2, 2, 1161, 236, 3, 2, 2, 2, 1162, 1163,
-	9, 6, 2, 2, 1163, 1164, 9, 3, 2, 2, 1164, 1165, 9, 23, 2, 2, 1165, 1166,
-	9, 6, 2, 2, 1166, 238, 3, 2, 2, 2, 1167, 1168, 9, 18, 2, 2, 1168, 1169,
-	9, 8, 2, 2, 1169, 1170, 9, 11, 2, 2, 1170, 1171, 9, 20, 2, 2, 1171, 1172,
-	9, 17, 2, 2, 1172, 1173, 9, 22, 2, 2, 1173, 1174, 9, 15, 2, 2, 1174, 1175,
-	9, 25, 2, 2, 1175, 240, 3, 2, 2, 2, 1176, 1177, 9, 4, 2, 2, 1177, 1178,
-	9, 9, 2, 2, 1178, 1179, 9, 18, 2, 2, 1179, 1180, 9, 15, 2, 2, 1180, 1181,
-	9, 5, 2, 2, 1181, 1182, 9, 11, 2, 2, 1182, 1183, 9, 4, 2, 2, 1183, 242,
-	3, 2, 2, 2, 1184, 1185, 9, 12, 2, 2, 1185, 1186, 9, 15, 2, 2, 1186, 1187,
-	9, 2, 2, 2, 1187, 1188, 9, 6, 2, 2, 1188, 1189, 9, 8, 2, 2, 1189, 1190,
-	9, 15, 2, 2, 1190, 1191, 9, 21, 2, 2, 1191, 1192, 9, 19, 2, 2, 1192, 1193,
-	9, 6, 2, 2, 1193, 1194, 9, 3, 2, 2, 1194, 1195, 9, 12, 2, 2, 1195, 244,
-	3, 2, 2, 2, 1196, 1197, 9, 22, 2, 2, 1197, 1198, 9, 11, 2, 2, 1198, 1199,
-	9, 4, 2, 2, 1199, 1200, 9, 15, 2, 2, 1200, 1201, 9, 12, 2, 2, 1201, 1202,
-	9, 11, 2, 2, 1202, 1203, 9, 6, 2, 2, 1203, 1204, 9, 3, 2, 2, 1204, 246,
-	3, 2, 2, 2, 1205, 1206, 9, 5, 2, 2, 1206, 1207, 9, 11, 2, 2, 1207, 1208,
-	9, 2, 2, 2, 1208, 1209, 9, 6, 2, 2, 1209, 248, 3, 2, 2, 2, 1210, 1211,
-	9, 6, 2, 2, 1211, 1212, 9, 8, 2, 2, 1212, 1213, 9, 14, 2, 2, 1213, 1214,
-	7, 97, 2, 2, 1214, 1215, 9, 5, 2, 2, 1215, 1216, 9, 11, 2, 2, 1216, 1217,
-	9, 2, 2, 2, 1217, 1218, 9, 6, 2, 2, 1218, 250, 3, 2, 2, 2, 1219, 1220,
-	9, 2, 2, 2, 1220, 1221, 9, 17, 2, 2, 1221, 1222, 9, 9, 2, 2, 1222, 1223,
-	9, 16, 2, 2, 1223, 252, 3, 2, 2, 2, 1224, 1225, 9, 6, 2, 2, 1225, 1226,
-	9, 11, 2, 2, 1226, 1227, 9, 21, 2, 2, 1227, 1228, 9, 4, 2, 2, 1228, 1229,
-	9, 3, 2, 2, 1229, 1230, 9, 2, 2, 2, 1230, 254, 3, 2, 2, 2, 1231, 1232,
-	9, 2, 2, 2, 1232, 1233, 9, 5, 2, 2, 1233, 1234, 9, 17, 2, 2, 1234, 1235,
-	9, 3, 2, 2, 1235, 1236, 9, 10, 2, 2, 1236, 1237, 9, 11, 2, 2, 1237, 1238,
-	9, 2, 2, 2, 1238, 256, 3, 2, 2, 2, 1239, 1240, 9, 5, 2, 2, 1240, 1241,
-	9, 11, 2, 2, 1241, 1242, 9, 6, 2, 2, 1242, 1243, 9, 11, 2, 2, 1243, 1244,
-	9, 4, 2, 2, 1244, 1245, 9, 9, 2, 2, 1245, 1246, 9, 18, 2, 2, 1246, 1247,
-	9, 2, 2, 2, 1247, 258, 3, 2, 2, 2, 1248, 1249, 9, 5, 2, 2, 1249, 1250,
-	9, 9, 2, 2, 1250, 1251, 9, 4, 2, 2, 1251, 1252, 9, 19, 2, 2, 1252, 1253,
-	9, 10, 2, 2, 1253, 1254, 9, 13, 2, 2, 1254, 1255, 9, 2, 2, 2, 1255, 260,
-	3, 2, 2, 2, 1256, 1257, 9, 5, 2, 2, 1257, 1258, 9, 9, 2, 2, 1258, 1259,
-	9, 4, 2, 2, 1259, 1260, 9, 19, 2, 2, 1260, 1261, 9, 10, 2, 2, 1261, 1262,
-	9, 13, 2, 2, 1262, 262, 3, 2, 2, 2, 1263, 1264, 9, 19, 2, 2, 1264, 1265,
-	9, 2, 2, 2, 1265, 1266, 9, 3, 2, 2, 1266, 264, 3, 2, 2, 2, 1267, 1268,
-	9, 20, 2, 2, 1268, 1269, 9, 11, 2, 2, 1269, 1270, 9, 8, 2, 2, 1270, 1271,
-	9, 6, 2, 2, 1271, 1272, 9, 15, 2, 2, 1272, 1273, 9, 6, 2, 2, 1273, 1274,
-	9, 15, 2, 2, 1274, 1275, 9, 9, 2, 2, 1275, 1276, 9, 13, 2, 2, 1276, 1277,
-	9, 2, 2, 2, 1277, 266, 3, 2, 2, 2, 1278, 1279, 9, 7, 2, 2, 1279, 1280,
-	9, 19, 2, 2, 1280, 1281, 9, 13, 2, 2, 1281, 1282, 9, 5, 2, 2, 1282, 1283,
-	9, 6, 2, 2, 1283, 1284, 9, 15, 2, 2, 1284, 1285, 9, 9, 2, 2, 1285, 1286,
-	9, 13, 2, 2, 1286, 1287, 9, 2, 2, 2, 1287, 268, 3, 2, 2, 2, 1288, 1289,
-	9, 12, 2, 2, 1289, 1290, 9, 8, 2, 2, 1290, 1291, 9, 9, 2, 2, 1291, 1292,
-	9, 20, 2, 2, 1292, 270, 3, 2, 2, 2, 1293, 1294, 9, 19, 2, 2, 1294, 1295,
-	9, 13, 2, 2, 1295, 1296, 9, 15, 2, 2, 1296, 1297, 9, 9, 2, 2, 1297, 1298,
-	9, 13, 2, 2, 1298, 272, 3, 2, 2, 2, 1299, 1300, 9, 3, 2, 2, 1300, 1301,
-	9, 23, 2, 2, 1301, 1302, 9, 5, 2, 2, 1302, 1303, 9, 3, 2, 2, 1303, 1304,
-	9, 20, 2, 2, 1304, 1305, 9, 6, 2, 2, 1305, 274, 3, 2, 2, 2, 1306, 1307,
-	9, 15, 2, 2, 1307, 1308, 9, 13, 2, 2, 1308, 1309, 9, 6, 2, 2, 1309, 1310,
-	9, 3, 2, 2, 1310, 1311, 9, 8, 2, 2, 1311, 1312, 9, 2, 2, 2, 1312, 1313,
-	9, 3, 2, 2, 1313, 1314, 9, 5, 2, 2, 1314, 1315, 9, 6, 2, 2, 1315, 276,
-	3, 2, 2, 2, 1316, 1317, 9, 6, 2, 2, 1317, 1318, 9, 9, 2, 2, 1318, 278,
-	3, 2, 2, 2, 1319, 1320, 9, 2, 2, 2, 1320, 1321, 9, 14, 2, 2, 1321, 1322,
-	9, 2, 2, 2, 1322, 1323, 9, 6, 2, 2, 1323, 1324, 9, 3, 2, 2, 1324, 1325,
-	9, 10, 2, 2, 1325, 280, 3, 2, 2, 2, 1326, 1327, 9, 21, 2, 2, 1327, 1328,
-	9, 3, 2, 2, 1328, 1329, 9, 8, 2, 2, 1329, 1330, 9, 13, 2, 2, 1330, 1331,
-	9, 9, 2, 2, 1331, 1332, 9, 19, 2, 2, 1332, 1333, 9, 4, 2, 2, 1333, 1334,
-	9, 4, 2, 2, 1334, 1335, 9, 15, 2, 2, 1335, 282, 3, 2, 2, 2, 1336, 1337,
-	9, 20, 2, 2, 1337, 1338, 9, 9, 2, 2, 1338, 1339, 9, 15, 2, 2, 1339, 1340,
-	9, 2, 2, 2, 1340, 1341, 9, 2, 2, 2, 1341, 1342, 9, 9, 2, 2, 1342, 1343,
-	9, 13, 2, 2, 1343, 1344, 9, 15, 2, 2, 1344, 1345, 9, 25, 2, 2, 1345, 1346,
-	9, 3, 2, 2, 1346, 1347, 9, 12, 2, 2, 1347, 284, 3, 2, 2, 2, 1348, 1349,
-	9, 6, 2, 2, 1349, 1350, 9, 11, 2, 2, 1350, 1351, 9, 21, 2, 2, 1351, 1352,
-	9, 4, 2, 2, 1352, 1353, 9, 3, 2, 2, 1353, 1354, 9, 2, 2, 2, 1354, 1355,
-	9, 11, 2, 2, 1355, 1356, 9, 10, 2, 2, 1356, 1357, 9, 20, 2, 2, 1357, 1358,
-	9, 4, 2, 2, 1358, 1359, 9, 3, 2, 2, 1359, 286, 3, 2, 2, 2, 1360, 1361,
-	9, 11, 2, 2, 1361, 1362, 9, 4, 2, 2, 1362, 1363, 9, 6, 2, 2, 1363, 1364,
-	9, 3, 2, 2, 1364, 1365, 9, 8, 2, 2, 1365, 288, 3, 2, 2, 2, 1366, 1367,
-	9, 8, 2, 2, 1367, 1368, 9, 3, 2, 2, 1368, 1369, 9, 13, 2, 2, 1369, 1370,
-	9, 11, 2, 2, 1370, 1371, 9, 10, 2, 2, 1371, 1372, 9, 3, 2, 2, 1372, 290,
-	3, 2, 2, 2, 1373, 1374, 9, 19, 2, 2, 1374, 1375, 9, 13, 2, 2, 1375, 1376,
-	9, 13, 2, 2, 1376, 1377, 9, 3, 2, 2, 1377, 1378, 9, 2, 2, 2, 1378, 1379,
-	9, 6, 2, 2, 1379, 292, 3, 2, 2, 2, 1380, 1381, 9, 9, 2, 2, 1381, 1382,
-	9, 8, 2, 2, 1382, 1383, 9, 12, 2, 2, 1383, 1384, 9, 15, 2, 2, 1384, 1385,
-	9, 13, 2, 2, 1385, 1386, 9, 11, 2, 2, 1386, 1387, 9, 4, 2, 2, 1387, 1388,
-	9, 15, 2, 2, 1388, 1389, 9, 6, 2, 2, 1389, 1390, 9, 14, 2, 2, 1390, 294,
-	3, 2, 2, 2, 1391, 1392, 9, 11, 2, 2, 1392, 1393, 9, 8, 2, 2, 1393, 1394,
-	9, 8, 2, 2, 1394, 1395, 9, 11, 2, 2, 1395, 1396, 9, 14, 2, 2, 1396, 296,
-	3, 2, 2, 2, 1397, 1398, 9, 10, 2, 2, 1398, 1399, 9, 11, 2, 2, 1399, 1400,
-	9, 20, 2, 2, 1400, 298, 3, 2, 2, 2, 1401, 1402, 9, 2, 2, 2, 1402, 1403,
-	9, 3, 2, 2, 1403, 1404, 9, 6, 2, 2, 1404, 300, 3, 2, 2, 2, 1405, 1406,
-	9, 8, 2, 2, 1406, 1407, 9, 3, 2, 2, 1407, 1408, 9, 2, 2, 2, 1408, 1409,
-	9, 3, 2, 2, 1409, 1410, 9, 6, 2, 2, 1410, 302, 3, 2, 2, 2, 1411, 1412,
-	9, 2, 2, 2, 1412, 1413, 9, 3, 2, 2, 1413, 1414, 9, 2, 2, 2, 1414, 1415,
-	9, 2, 2, 2, 1415, 1416, 9, 15, 2, 2, 1416, 1417, 9, 9, 2, 2, 1417, 1418,
-	9, 13, 2, 2, 1418, 304, 3, 2, 2, 2, 1419, 1420, 9, 12, 2, 2, 1420, 1421,
-	9, 11, 2, 2, 1421, 1422, 9, 6, 2, 2, 1422, 1423, 9, 11, 2, 2, 1423, 306,
-	3, 2, 2, 2, 1424, 1425, 9, 2, 2, 2, 1425, 1426, 9, 6, 2, 2, 1426, 1427,
-	9, 11, 2, 2, 1427, 1428, 9, 8, 2, 2, 1428, 1429, 9, 6, 2, 2, 1429, 308,
-	3, 2, 2, 2, 1430, 1431, 9, 6, 2, 2, 1431, 1432, 9, 8, 2, 2, 1432, 1433,
-	9, 11, 2, 2, 1433, 1434, 9, 13, 2, 2, 1434, 1435, 9, 2, 2, 2, 1435, 1436,
-	9, 11, 2, 2, 1436, 1437, 9, 5, 2, 2, 1437, 1438, 9, 6, 2, 2, 1438, 1439,
-	9, 15, 2, 2, 1439, 1440, 9, 9, 2, 2, 1440, 1441, 9, 13, 2, 2, 1441, 310,
-	3, 2, 2, 2, 1442, 1443, 9, 5, 2, 2, 1443, 1444, 9, 9, 2, 2, 1444, 1445,
-	9, 10, 2, 2, 1445, 1446, 9, 10, 2, 2, 1446, 1447, 9, 15, 2, 2, 1447, 1448,
-	9, 6, 2, 2, 1448, 312, 3, 2, 2, 2, 1449, 1450, 9, 8, 2, 2, 1450, 1451,
-	9, 9, 2, 2, 1451, 1452, 9, 4, 2, 2, 1452, 1453, 9, 4, 2, 2, 1453, 1454,
-	9, 21, 2, 2, 1454, 1455, 9, 11, 2, 2, 1455, 1456, 9, 5, 2, 2, 1456, 1457,
-	9, 24, 2, 2, 1457, 314, 3, 2, 2, 2, 1458, 1459, 9, 16, 2, 2, 1459, 1460,
-	9, 9, 2, 2, 1460, 1461, 9, 8, 2, 2, 1461, 1462, 9, 24, 2, 2, 1462, 316,
-	3, 2, 2, 2, 1463, 1464, 9, 15, 2, 2, 1464, 1465, 9, 2, 2, 2, 1465, 1466,
-	9, 9, 2, 2, 1466, 1467, 9, 4, 2, 2, 1467, 1468, 9, 11, 2, 2, 1468, 1469,
-	9, 6, 2, 2, 1469, 1470, 9, 15, 2, 2, 1470, 1471, 9, 9, 2, 2, 1471, 1472,
-	9, 13, 2, 2, 1472, 318, 3, 2, 2, 2, 1473, 1474, 9, 4, 2, 2, 1474, 1475,
-	9, 3, 2, 2, 1475, 1476, 9, 22, 2, 2, 1476, 1477, 9, 3, 2, 2, 1477, 1478,
-	9, 4, 2, 2, 1478, 320, 3, 2, 2, 2, 1479, 1480, 9, 2, 2, 2, 1480, 1481,
-	9, 3, 2, 2, 1481, 1482, 9, 8, 2, 2, 1482, 1483, 9, 15, 2, 2, 1483, 1484,
-	9, 11, 2, 2, 1484, 1485, 9, 4, 2, 2, 1485, 1486, 9, 15, 2, 2, 1486, 1487,
-	9, 25, 2, 2, 1487, 1488, 9, 11, 2, 2, 1488, 1489, 9, 21, 2, 2, 1489, 1490,
-	9, 4, 2, 2, 1490, 1491, 9, 3, 2, 2, 1491, 322, 3, 2, 2, 2, 1492, 1493,
-	9, 8, 2, 2, 1493, 1494, 9, 3, 2, 2, 1494, 1495, 9, 20, 2, 2, 1495, 1496,
-	9, 3, 2, 2, 1496, 1497, 9, 11, 2, 2, 1497, 1498, 9, 6, 2, 2, 1498, 1499,
-	9, 11, 2, 2, 1499, 1500, 9, 21, 2, 2, 1500, 1501, 9, 4, 2, 2, 1501, 1502,
-	9, 3, 2, 2, 1502, 324, 3, 2, 2, 2, 1503, 1504, 9, 5, 2, 2, 1504, 1505,
-	9, 9, 2, 2, 1505, 1506, 9, 10, 2, 2, 1506, 1507, 9, 10, 2, 2, 1507, 1508,
-	9, 15, 2, 2, 1508, 1509, 9, 6, 2, 2, 1509, 1510, 9, 6, 2, 2, 1510, 1511,
-	9, 3, 2, 2, 1511, 1512, 9, 12, 2, 2, 1512, 326, 3, 2, 2, 2, 1513, 1514,
-	9, 19, 2, 2, 1514, 1515, 9, 13, 2, 2, 1515, 1516, 9, 5, 2, 2, 1516, 1517,
-	9, 9, 2, 2, 1517, 1518, 9, 10, 2, 2, 1518, 1519, 9, 10, 2, 2, 1519, 1520,
-	9, 15, 2, 2, 1520, 1521, 9, 6, 2, 2, 1521, 1522, 9, 6, 2, 2, 1522, 1523,
-	9, 3, 2, 2, 1523, 1524, 9, 12, 2, 2, 1524, 328, 3, 2, 2, 2, 1525, 1526,
-	9, 8, 2, 2, 1526, 1527, 9, 3, 2, 2, 1527, 1528, 9, 11, 2, 2, 1528, 1529,
-	9, 12, 2, 2, 1529, 330, 3, 2, 2, 2, 1530, 1531, 9, 16, 2, 2, 1531, 1532,
-	9, 8, 2, 2, 1532, 1533, 9, 15, 2, 2, 1533, 1534, 9, 6, 2, 2, 1534, 1535,
-	9, 3, 2, 2, 1535, 332, 3, 2, 2, 2, 1536, 1537, 9, 9, 2, 2, 1537, 1538,
-	9, 13, 2, 2, 1538, 1539, 9, 4, 2, 2, 1539, 1540, 9, 14, 2, 2, 1540, 334,
-	3, 2, 2, 2, 1541, 1542, 9, 5, 2, 2, 1542, 1543, 9, 11, 2, 2, 1543, 1544,
-	9, 4, 2, 2, 1544, 1545, 9, 4, 2, 2, 1545, 336, 3, 2, 2, 2, 1546, 1547,
-	9, 20, 2, 2, 1547, 1548, 9, 8, 2, 2, 1548, 1549, 9, 3, 2, 2, 1549, 1550,
-	9, 20, 2, 2, 1550, 1551, 9, 11, 2, 2, 1551, 1552, 9, 8, 2, 2, 1552, 1553,
-	9, 3, 2, 2, 1553, 338, 3, 2, 2, 2, 1554, 1555, 9, 12, 2, 2, 1555, 1556,
-	9, 3, 2, 2, 1556, 1557, 9, 11, 2, 2, 1557, 1558, 9, 4, 2, 2, 1558, 1559,
-	9, 4, 2, 2, 1559, 1560, 9, 9, 2, 2, 1560, 1561, 9, 5, 2, 2, 1561, 1562,
-	9, 11, 2, 2, 1562, 1563, 9, 6, 2, 2, 1563, 1564, 9, 3, 2, 2, 1564, 340,
-	3, 2, 2, 2, 1565, 1566, 9, 3, 2, 2, 1566, 1567, 9, 23, 2, 2, 1567, 1568,
-	9, 3, 2, 2, 1568, 1569, 9, 5, 2, 2, 1569, 1570, 9, 19, 2, 2, 1570, 1571,
-	9, 6, 2, 2, 1571, 1572, 9, 3, 2, 2, 1572, 342, 3, 2, 2, 2, 1573, 1574,
-	9, 15, 2, 2, 1574, 1575, 9, 13, 2, 2, 1575, 1576, 9, 20, 2, 2, 1576, 1577,
-	9, 19, 2, 2, 1577, 1578, 9, 6, 2, 2, 1578, 344, 3, 2, 2, 2, 1579, 1580,
-	9, 9, 2, 2, 1580, 1581, 9, 19, 2, 2, 1581, 1582, 9, 6, 2, 2, 1582, 1583,
-	9, 20, 2, 2, 1583, 1584, 9, 19, 2, 2, 1584, 1585, 9, 6, 2, 2, 1585, 346,
-	3, 2, 2, 2, 1586, 1587, 9, 5, 2, 2, 1587, 1588, 9, 11, 2, 2, 1588, 1589,
-	9, 2, 2, 2, 1589, 1590, 9, 5, 2, 2, 1590, 1591, 9, 11, 2, 2, 1591, 1592,
-	9, 12, 2, 2, 1592, 1593, 9, 3, 2, 2, 1593, 348, 3, 2, 2, 2, 1594, 1595,
-	9, 8, 2, 2, 1595, 1596, 9, 3, 2, 2, 1596, 1597, 9, 2, 2, 2, 1597, 1598,
-	9, 6, 2, 2, 1598, 1599, 9, 8, 2, 2, 1599, 1600, 9, 15, 2, 2, 1600, 1601,
-	9, 5, 2, 2, 1601, 1602, 9, 6, 2, 2, 1602, 350, 3, 2, 2, 2, 1603, 1604,
-	9, 15, 2, 2, 1604, 1605, 9, 13, 2, 2, 1605, 1606, 9, 5, 2, 2, 1606, 1607,
-	9, 4, 2, 2, 1607, 1608, 9, 19, 2, 2, 1608, 1609, 9, 12, 2, 2, 1609, 1610,
-	9, 15, 2, 2, 1610, 1611, 9, 13, 2, 2, 1611, 1612, 9, 18, 2, 2, 1612, 352,
-	3, 2, 2, 2, 1613, 1614, 9, 3, 2, 2, 1614, 1615, 9, 23, 2, 2, 1615, 1616,
-	9, 5, 2, 2, 1616, 1617, 9, 4, 2, 2, 1617, 1618, 9, 19, 2, 2, 1618, 1619,
-	9, 12, 2, 2, 1619, 1620, 9, 15, 2, 2, 1620, 1621, 9, 13, 2, 2, 1621, 1622,
-	9, 18, 2, 2, 1622, 354, 3, 2, 2, 2, 1623, 1624, 9, 20, 2, 2, 1624, 1625,
-	9, 8, 2, 2, 1625, 1626, 9, 9, 2, 2, 1626, 1627, 9, 20, 2, 2, 1627, 1628,
-	9, 3, 2, 2, 1628, 1629, 9, 8, 2, 2, 1629, 1630, 9, 6, 2, 2, 1630, 1631,
-	9, 15, 2, 2, 1631, 1632, 9, 3, 2, 2, 1632, 1633, 9, 2, 2, 2, 1633, 356,
-	3, 2, 2, 2, 1634, 1635, 9, 13, 2, 2, 1635, 1636, 9, 9, 2, 2, 1636, 1637,
-	9, 8, 2, 2, 1637, 1638, 9, 10, 2, 2, 1638, 1639, 9, 11, 2, 2, 1639, 1640,
-	9, 4, 2, 2, 1640, 1641, 9, 15, 2, 2, 1641, 1642, 9, 25, 2, 2, 1642, 1643,
-	9, 3, 2, 2, 1643, 358, 3, 2, 2, 2, 1644, 1645, 9, 13, 2, 2, 1645, 1646,
-	9, 7, 2, 2, 1646, 1647, 9, 12, 2, 2, 1647, 360, 3, 2, 2, 2, 1648, 1649,
-	9, 13, 2, 2, 1649, 1650, 9, 7, 2, 2, 1650, 1651, 9, 5, 2, 2, 1651, 362,
-	3, 2, 2, 2, 1652, 1653, 9, 13, 2, 2, 1653, 1654, 9, 7, 2, 2, 1654, 1655,
-	9, 24, 2, 2, 1655, 1656, 9, 12, 2, 2, 1656, 364, 3, 2, 2, 2, 1657, 1658,
-	9, 13, 2, 2, 1658, 1659, 9, 7, 2, 2, 1659, 1660, 9, 24, 2, 2, 1660, 1661,
-	9, 5, 2, 2, 1661, 366, 3, 2, 2, 2, 1662, 1663, 9, 15, 2, 2, 1663, 1664,
-	9, 7, 2, 2, 1664, 368, 3, 2, 2, 2, 1665, 1666, 9, 13, 2, 2, 1666, 1667,
-	9, 19, 2, 2, 1667, 1668, 9, 4, 2, 2, 1668, 1669, 9, 4, 2, 2, 1669, 1670,
-	9, 15, 2, 2, 1670, 1671, 9, 7, 2, 2, 1671, 370, 3, 2, 2, 2, 1672, 1673,
-	9, 5, 2, 2, 1673, 1674, 9, 9, 2, 2, 1674, 1675, 9, 11, 2, 2, 1675, 1676,
-	9, 4, 2, 2, 1676, 1677, 9, 3, 2, 2, 1677, 1678, 9, 2, 2, 2, 1678, 1679,
-	9, 5, 2, 2, 1679, 1680, 9, 3, 2, 2, 1680, 372, 3, 2, 2, 2, 1681, 1682,
-	9, 6, 2, 2, 1682, 1683, 9, 15, 2, 2, 1683, 1684, 9, 10, 2, 2, 1684, 1685,
-	9, 3, 2, 2, 1685, 1686, 5, 431, 216, 2, 1686, 1687, 9, 16, 2, 2, 1687,
-	1688, 9, 15, 2, 2, 1688, 1689, 9, 6, 2, 2, 1689, 1690, 9, 17, 2, 2, 1690,
-	1691, 5, 431, 216, 2, 1691, 1692, 9, 6, 2, 2, 1692, 1693, 9, 15, 2, 2,
-	1693, 1694, 9, 10, 2, 2, 1694, 1695, 9, 3, 2, 2, 1695, 1696, 5, 431, 216,
-	2, 1696, 1697, 9, 25, 2, 2, 1697, 1698, 9, 9, 2, 2, 1698, 1699, 9, 13,
-	2, 2, 1699, 1700, 9, 3, 2, 2, 1700, 374, 3, 2, 2, 2, 1701, 1702, 9, 6,
-	2, 2, 1702, 1703, 9, 15, 2, 2, 1703, 1704, 9, 10, 2, 2, 1704, 1705, 9,
-	3, 2, 2, 1705, 1706, 9, 2, 2, 2, 1706, 1707, 9, 6, 2, 2, 1707, 1708, 9,
-	11, 2, 2, 1708, 1709, 9, 10, 2, 2, 1709, 1710, 9, 20, 2, 2, 1710, 1711,
-	5, 431, 216, 2, 1711, 1712, 9, 16, 2, 2, 1712, 1713, 9, 15, 2, 2, 1713,
-	1714, 9, 6, 2, 2, 1714, 1715, 9, 17, 2, 2, 1715, 1716, 5, 431, 216, 2,
-	1716, 1717, 9, 6, 2, 2, 1717, 1718, 9, 15, 2, 2, 1718, 1719, 9, 10, 2,
-	2, 1719, 1720, 9, 3, 2, 2, 1720, 1721, 5, 431, 216, 2, 1721, 1722, 9, 25,
-	2, 2, 1722, 1723, 9, 9, 2, 2, 1723, 1724, 9, 13, 2, 2, 1724, 1725, 9, 3,
-	2, 2, 1725, 376, 3, 2, 2, 2, 1726, 1727, 9, 12, 2, 2, 1727, 1728, 9, 9,
-	2, 2, 1728, 1729, 9, 19, 2, 2, 1729, 1730, 9, 21, 2, 2, 1730, 1731, 9,
-	4, 2, 2, 1731, 1732, 9, 3, 2, 2, 1732, 1733, 5, 431, 216, 2, 1733, 1734,
-	9, 20, 2, 2, 1734, 1735, 9, 8, 2, 2, 1735, 1736, 9, 3, 2, 2, 1736, 1737,
-	9, 5, 2, 2, 1737, 1738, 9, 15, 2, 2, 1738, 1739, 9, 2, 2, 2, 1739, 1740,
-	9, 15, 2, 2, 1740, 1741, 9, 9, 2, 2, 1741, 1742, 9, 13, 2, 2, 1742, 378,
-	3, 2, 2, 2, 1743, 1744, 7, 63, 2, 2, 1744, 380, 3, 2, 2, 2, 1745, 1746,
-	7, 62, 2, 2, 1746, 1750, 7, 64, 2, 2, 1747, 1748, 7, 35, 2, 2, 1748, 1750,
-	7, 63, 2, 2, 1749, 1745, 3, 2, 2, 2, 1749, 1747, 3, 2, 2, 2, 1750, 382,
-	3, 2, 2, 2, 1751, 1752, 7, 62, 2, 2, 1752, 384, 3, 2, 2, 2, 1753, 1754,
-	7, 62, 2, 2, 1754, 1755, 7, 63, 2, 2, 1755, 386, 3, 2, 2, 2, 1756, 1757,
-	7, 64, 2, 2, 1757, 388, 3, 2, 2, 2, 1758, 1759, 7, 64, 2, 2, 1759, 1760,
-	7, 63, 2, 2, 1760, 390, 3, 2, 2, 2, 1761, 1762, 7, 45, 2, 2, 1762, 392,
-	3, 2, 2, 2, 1763, 1764, 7, 47, 2, 2, 1764, 394, 3, 2, 2, 2, 1765, 1766,
-	7, 44, 2, 2, 1766, 396, 3, 2, 2, 2, 1767, 1768, 7, 49, 2, 2, 1768, 398,
-	3, 2, 2, 2, 1769, 1770, 7, 39, 2, 2, 1770, 400, 3, 2, 2, 2, 1771, 1772,
-	7, 126, 2, 2, 1772, 1773, 7, 126, 2, 2, 1773, 402, 3, 2, 2, 2, 1774, 1775,
-	7, 48, 2, 2, 1775, 404, 3, 2, 2, 2, 1776, 1782, 7, 41, 2, 2, 1777, 1781,
-	10, 27, 2, 2, 1778, 1779, 7, 41, 2, 2, 1779, 1781, 7, 41, 2, 2, 1780, 1777,
-	3, 2, 2, 2, 1780, 1778, 3, 2, 2, 2, 1781, 1784, 3, 2, 2, 2, 1782, 1780,
-	3, 2, 2, 2, 1782, 1783, 3, 2, 2, 2, 1783, 1785, 3, 2, 2, 2, 1784, 1782,
-	3, 2, 2, 2, 1785, 1786, 7, 41, 2, 2, 1786, 406, 3, 2, 2, 2, 1787, 1788,
-	7, 90, 2, 2, 1788, 1789, 7, 41, 2, 2, 1789, 1793, 3, 2, 2, 2, 1790, 1792,
-	10, 27, 2, 2, 1791, 1790, 3, 2, 2, 2, 1792, 1795, 3, 2, 2, 2, 1793, 1791,
-	3, 2, 2, 2, 1793, 1794, 3, 2, 2, 2, 1794, 1796, 3, 2, 2, 2, 1795, 1793,
-	3, 2, 2, 2, 1796, 1797, 7, 41, 2, 2, 1797, 408, 3, 2, 2, 2, 1798, 1800,
-	5, 423, 212, 2, 1799, 1798, 3, 2, 2, 2, 1800, 1801, 3, 2, 2, 2, 1801, 1799,
-	3, 2, 2, 2, 1801, 1802, 3, 2, 2, 2, 1802, 410, 3, 2, 2, 2, 1803, 1805,
-	5, 423, 212, 2, 1804, 1803, 3, 2, 2, 2, 1805, 1806, 3, 2, 2, 2, 1806, 1804,
-	3, 2, 2, 2, 1806, 1807, 3, 2, 2, 2, 1807, 1808, 3, 2, 2, 2, 1808, 1812,
-	7, 48, 2, 2, 1809, 1811, 5, 423, 212, 2, 1810, 1809, 3, 2, 2, 2, 1811,
-	1814, 3, 2, 2, 2, 1812, 1810, 3, 2, 2, 2, 1812, 1813, 3, 2, 2, 2, 1813,
-	1846, 3, 2, 2, 2, 1814, 1812, 3, 2, 2, 2, 1815, 1817, 7, 48, 2, 2, 1816,
-	1818, 5, 423, 212, 2, 1817, 1816, 3, 2, 2, 2, 1818, 1819, 3, 2, 2, 2, 1819,
-	1817, 3, 2, 2, 2, 1819, 1820, 3, 2, 2, 2, 1820, 1846, 3, 2, 2, 2, 1821,
-	1823, 5, 423, 212, 2, 1822, 1821, 3, 2, 2, 2, 1823, 1824, 3, 2, 2, 2, 1824,
-	1822, 3, 2, 2, 2, 1824, 1825, 3, 2, 2, 2, 1825, 1833, 3, 2, 2, 2, 1826,
-	1830, 7, 48, 2, 2, 1827, 1829, 5, 423, 212, 2, 1828, 1827, 3, 2, 2, 2,
-	1829, 1832, 3, 2, 2, 2, 1830, 1828, 3, 2, 2, 2, 1830, 1831, 3, 2, 2, 2,
-	1831, 1834, 3, 2, 2, 2, 1832, 1830, 3, 2, 2, 2, 1833, 1826, 3, 2, 2, 2,
-	1833, 1834, 3, 2, 2, 2, 1834, 1835, 3, 2, 2, 2, 1835, 1836, 5, 421, 211,
-	2, 1836, 1846, 3, 2, 2, 2, 1837, 1839, 7, 48, 2, 2, 1838, 1840, 5, 423,
-	212, 2, 1839, 1838, 3, 2, 2, 2, 1840, 1841, 3, 2, 2, 2, 1841, 1839, 3,
-	2, 2, 2, 1841, 1842, 3, 2, 2, 2, 1842, 1843, 3, 2, 2, 2, 1843, 1844, 5,
-	421, 211, 2, 1844, 1846, 3, 2, 2, 2, 1845, 1804, 3, 2, 2, 2, 1845, 1815,
-	3, 2, 2, 2, 1845, 1822, 3, 2, 2, 2, 1845, 1837, 3, 2, 2, 2, 1846, 412,
-	3, 2, 2, 2, 1847, 1850, 5, 425, 213, 2, 1848, 1850, 7, 97, 2, 2, 1849,
-	1847, 3, 2, 2, 2, 1849, 1848, 3, 2, 2, 2, 1850, 1856, 3, 2, 2, 2, 1851,
-	1855, 5, 425, 213, 2, 1852, 1855, 5, 423, 212, 2, 1853, 1855, 9, 28, 2,
-	2, 1854, 1851, 3, 2, 2, 2, 1854, 1852, 3, 2, 2, 2, 1854, 1853, 3, 2, 2,
-	2, 1855, 1858, 3, 2, 2, 2, 1856, 1854, 3, 2, 2, 2, 1856, 1857, 3, 2, 2,
-	2, 1857, 414, 3, 2, 2, 2, 1858, 1856, 3, 2, 2, 2, 1859, 1863, 5, 423, 212,
-	2, 1860, 1864, 5, 425, 213, 2, 1861, 1864, 5, 423, 212, 2, 1862, 1864,
-	9, 28, 2, 2, 1863, 1860, 3, 2, 2, 2, 1863, 1861, 3, 2, 2, 2, 1863, 1862,
-	3, 2, 2, 2, 1864, 1865, 3, 2, 2, 2, 1865, 1863, 3, 2, 2, 2, 1865, 1866,
-	3, 2, 2, 2, 1866, 416, 3, 2, 2, 2, 1867, 1873, 7, 36, 2, 2, 1868, 1872,
-	10, 29, 2, 2, 1869, 1870, 7, 36, 2, 2, 1870, 1872, 7, 36, 2, 2, 1871, 1868,
-	3, 2, 2, 2, 1871, 1869, 3, 2, 2, 2, 1872, 1875, 3, 2, 2, 2, 1873, 1871,
-	3, 2, 2, 2, 1873, 1874, 3, 2, 2, 2, 1874, 1876, 3, 2, 2, 2, 1875, 1873,
-	3, 2, 2, 2, 1876, 1877, 7, 36, 2, 2, 1877, 418, 3, 2, 2, 2, 1878, 1884,
-	7, 98, 2, 2, 1879, 1883, 10, 30, 2, 2, 1880, 1881, 7, 98, 2, 2, 1881, 1883,
-	7, 98, 2, 2, 1882, 1879, 3, 2, 2, 2, 1882, 1880, 3, 2, 2, 2, 1883, 1886,
-	3, 2, 2, 2, 1884, 1882, 3, 2, 2, 2, 1884, 1885, 3, 2, 2, 2, 1885, 1887,
-	3, 2, 2, 2, 1886, 1884, 3, 2, 2, 2, 1887, 1888, 7, 98, 2, 2, 1888, 420,
-	3, 2, 2, 2, 1889, 1891, 7, 71, 2, 2, 1890, 1892, 9, 31, 2, 2, 1891, 1890,
-	3, 2, 2, 2, 1891, 1892, 3, 2, 2, 2, 1892, 1894, 3, 2, 2, 2, 1893, 1895,
-	5, 423, 212, 2, 1894, 1893, 3, 2, 2, 2, 1895, 1896, 3, 2, 2, 2, 1896, 1894,
-	3, 2, 2, 2, 1896, 1897, 3, 2, 2, 2, 1897, 422, 3, 2, 2, 2, 1898, 1899,
-	9, 32, 2, 2, 1899, 424, 3, 2, 2, 2, 1900, 1901, 9, 33, 2, 2, 1901, 426,
-	3, 2, 2, 2, 1902, 1903, 7, 47, 2, 2, 1903, 1904, 7, 47, 2, 2, 1904, 1908,
-	3, 2, 2, 2, 1905, 1907, 10, 34, 2, 2, 1906, 1905, 3, 2, 2, 2, 1907, 1910,
-	3, 2, 2, 2, 1908, 1906, 3, 2, 2, 2, 1908, 1909, 3, 2, 2, 2, 1909, 1912,
-	3, 2, 2, 2, 1910, 1908, 3, 2, 2, 2, 1911, 1913, 7, 15, 2, 2, 1912, 1911,
-	3, 2, 2, 2, 1912, 1913, 3, 2, 2, 2, 1913, 1915, 3, 2, 2, 2, 1914, 1916,
-	7, 12, 2, 2, 1915, 1914, 3, 2, 2, 2, 1915, 1916, 3, 2, 2, 2, 1916, 1917,
-	3, 2, 2, 2, 1917, 1918, 8, 214, 2, 2, 1918, 428, 3, 2, 2, 2, 1919, 1920,
-	7, 49, 2, 2, 1920, 1921, 7, 44, 2, 2, 1921, 1925, 3, 2, 2, 2, 1922, 1924,
-	11, 2, 2, 2, 1923, 1922, 3, 2, 2, 2, 1924, 1927, 3, 2, 2, 2, 1925, 1926,
-	3, 2, 2, 2, 1925, 1923, 3, 2, 2, 2, 1926, 1928, 3, 2, 2, 2, 1927, 1925,
-	3, 2, 2, 2, 1928, 1929, 7, 44, 2, 2, 1929, 1930, 7, 49, 2, 2, 1930, 1931,
-	3, 2, 2, 2, 1931, 1932, 8, 215, 2, 2, 1932, 430, 3, 2, 2, 2, 1933, 1935,
-	9, 35, 2, 2, 1934, 1933, 3, 2, 2, 2, 1935, 1936, 3, 2, 2, 2, 1936, 1934,
-	3, 2, 2, 2, 1936, 1937, 3, 2, 2, 2, 1937, 1938, 3, 2, 2, 2, 1938, 1939,
-	8, 216, 2, 2, 1939, 432, 3, 2, 2, 2, 32, 2, 1749, 1780, 1782, 1793, 1801,
-	1806, 1812, 1819, 1824, 1830, 1833, 1841, 1845, 1849, 1854, 1856, 1863,
-	1865, 1871, 1873, 1882, 1884, 1891, 1896, 1908, 1912, 1915, 1925, 1936,
-	3, 2, 3, 2,
+	419, 211, 421, 2, 423, 2, 425, 2, 427, 212, 429, 213, 431, 214, 1940, 215,
+	3, 2, 36, 4, 2, 85, 85, 117, 117, 4, 2, 71, 71, 103, 103, 4, 2, 78, 78,
+	110, 110, 4, 2, 69, 69, 101, 101, 4, 2, 86, 86, 118, 118, 4, 2, 72, 72,
+	104, 104, 4, 2, 84, 84, 116, 116, 4, 2, 81, 81, 113, 113, 4, 2, 79, 79,
+	111, 111, 4, 2, 67, 67, 99, 99, 4, 2, 70, 70, 102, 102, 4, 2, 80, 80, 112,
+	112, 4, 2, 91, 91, 123, 123, 4, 2, 75, 75, 107, 107, 4, 2, 89, 89, 121,
+	121, 4, 2, 74, 74, 106, 106, 4, 2, 73, 73, 105, 105, 4, 2, 87, 87, 119,
+	119, 4, 2, 82, 82, 114, 114, 4, 2, 68, 68, 100, 100, 4, 2, 88, 88, 120,
+	120, 4, 2, 90, 90, 122, 122, 4, 2, 77, 77, 109, 109, 4, 2, 92, 92, 124,
+	124, 4, 2, 76, 76, 108, 108, 3, 2, 41, 41, 5, 2, 60, 60, 66, 66, 97, 97,
+	3, 2, 36, 36, 3, 2, 98, 98, 4, 2, 45, 45, 47, 47, 3, 2, 50, 59, 4, 2, 67,
+	92, 99, 124, 4, 2, 12, 12, 15, 15, 5, 2, 11, 12, 15, 15, 34, 34, 1978,
+	2, 3, 3, 2, 2, 2, 2, 5, 3, 2, 2, 2, 2, 7, 3, 2, 2, 2, 2, 9, 3, 2, 2, 2,
+	2, 11, 3, 2, 2, 2, 2, 13, 3, 2, 2, 2, 2, 15, 3, 2, 2, 2, 2, 17, 3, 2, 2,
+	2, 2, 19, 3, 2, 2, 2, 2, 21, 3, 2, 2, 2, 2, 23, 3, 2, 2, 2, 2, 25, 3, 2,
+	2, 2, 2, 27, 3, 2, 2, 2, 2, 29, 3, 2, 2, 2, 2, 31, 3, 2, 2, 2, 2, 33, 3,
+	2, 2, 2, 2, 35, 3, 2, 2, 2, 2, 37, 3, 2, 2, 2, 2, 39, 3, 2, 2, 2, 2, 41,
+	3, 2, 2, 2, 2, 43, 3, 2, 2, 2, 2, 45, 3, 2, 2, 2, 2, 47, 3, 2, 2, 2, 2,
+	49, 3, 2, 2, 2, 2, 51, 3, 2, 2, 2, 2, 53, 3, 2, 2, 2, 2, 55, 3, 2, 2, 2,
+	2, 1940, 3, 2, 2, 2, 2, 57, 3, 2, 2, 2, 2, 59, 3, 2, 2, 2, 2, 61, 3, 2,
+	2, 2, 2, 63, 3, 2, 2, 2, 2, 65, 3, 2, 2, 2, 2, 67, 3, 2, 2, 2, 2, 69, 3,
+	2, 2, 2, 2, 71, 3, 2, 2, 2, 2, 73, 3, 2, 2, 2, 2, 75, 3, 2, 2, 2, 2, 77,
+	3, 2, 2, 2, 2, 79, 3, 2, 2, 2, 2, 81, 3, 2, 2, 2, 2, 83, 3, 2, 2, 2, 2,
+	85, 3, 2, 2, 2, 2, 87, 3, 2, 2, 2, 2, 89, 3, 2, 2, 2, 2, 91, 3, 2, 2, 2,
+	2, 93, 3, 2, 2, 2, 2, 95, 3, 2, 2, 2, 2, 97, 3, 2, 2, 2, 2, 99, 3, 2, 2,
+	2, 2, 101, 3, 2, 2, 2, 2, 103, 3, 2, 2, 2, 2, 105, 3, 2, 2, 2, 2, 107,
+	3, 2, 2, 2, 2, 109, 3, 2, 2, 2, 2, 111, 3, 2, 2, 2, 2, 113, 3, 2, 2, 2,
+	2, 115, 3, 2, 2, 2, 2, 117, 3, 2, 2, 2, 2, 119, 3, 2, 2, 2, 2, 121, 3,
+	2, 2, 2, 2, 123, 3, 2, 2, 2, 2, 125, 3, 2, 2, 2, 2, 127, 3, 2, 2, 2, 2,
+	129, 3, 2, 2, 2, 2, 131, 3, 2, 2, 2, 2, 133, 3, 2, 2, 2, 2, 135, 3, 2,
+	2, 2, 2, 137, 3, 2, 2, 2, 2, 139, 3, 2, 2, 2, 2, 141, 3, 2, 2, 2, 2, 143,
+	3, 2, 2, 2, 2, 145, 3, 2, 2, 2, 2, 147, 3, 2, 2, 2, 2, 149, 3, 2, 2, 2,
+	2, 151, 3, 2, 2, 2, 2, 153, 3, 2, 2, 2, 2, 155, 3, 2, 2, 2, 2, 157, 3,
+	2, 2, 2, 2, 159, 3, 2, 2, 2, 2, 161, 3, 2, 2, 2, 2, 163, 3, 2, 2, 2, 2,
+	165, 3, 2, 2, 2, 2, 167, 3, 2, 2, 2, 2, 169, 3, 2, 2, 2, 2, 171, 3, 2,
+	2, 2, 2, 173, 3, 2, 2, 2, 2, 175, 3, 2, 2, 2, 2, 177, 3, 2, 2, 2, 2, 179,
+	3, 2, 2, 2, 2, 181, 3, 2, 2, 2, 2, 183, 3, 2, 2, 2, 2, 185, 3, 2, 2, 2,
+	2, 187, 3, 2, 2, 2, 2, 189, 3, 2, 2, 2, 2, 191, 3, 2, 2, 2, 2, 193, 3,
+	2, 2, 2, 2, 195, 3, 2, 2, 2, 2, 197, 3, 2, 2, 2, 2, 199, 3, 2, 2, 2, 2,
+	201, 3, 2, 2, 2, 2, 203, 3, 2, 2, 2, 2, 205, 3, 2, 2, 2, 2, 207, 3, 2,
+	2, 2, 2, 209, 3, 2, 2, 2, 2, 211, 3, 2, 2, 2, 2, 213, 3, 2, 2, 2, 2, 215,
+	3, 2, 2, 2, 2, 217, 3, 2, 2, 2, 2, 219, 3, 2, 2, 2, 2, 221, 3, 2, 2, 2,
+	2, 223, 3, 2, 2, 2, 2, 225, 3, 2, 2, 2, 2, 227, 3, 2, 2, 2, 2, 229, 3,
+	2, 2, 2, 2, 231, 3, 2, 2, 2, 2, 233, 3, 2, 2, 2, 2, 235, 3, 2, 2, 2, 2,
+	237, 3, 2, 2, 2, 2, 239, 3, 2, 2, 2, 2, 241, 3, 2, 2, 2, 2, 243, 3, 2,
+	2, 2, 2, 245, 3, 2, 2, 2, 2, 247, 3, 2, 2, 2, 2, 249, 3, 2, 2, 2, 2, 251,
+	3, 2, 2, 2, 2, 253, 3, 2, 2, 2, 2, 255, 3, 2, 2, 2, 2, 257, 3, 2, 2, 2,
+	2, 259, 3, 2, 2, 2, 2, 261, 3, 2, 2, 2, 2, 263, 3, 2, 2, 2, 2, 265, 3,
+	2, 2, 2, 2, 267, 3, 2, 2, 2, 2, 269, 3, 2, 2, 2, 2, 271, 3, 2, 2, 2, 2,
+	273, 3, 2, 2, 2, 2, 275, 3, 2, 2, 2, 2, 277, 3, 2, 2, 2, 2, 279, 3, 2,
+	2, 2, 2, 281, 3, 2, 2, 2, 2, 283, 3, 2, 2, 2, 2, 285, 3, 2, 2, 2, 2, 287,
+	3, 2, 2, 2, 2, 289, 3, 2, 2, 2, 2, 291, 3, 2, 2, 2, 2, 293, 3, 2, 2, 2,
+	2, 295, 3, 2, 2, 2, 2, 297, 3, 2, 2, 2, 2, 299, 3, 2, 2, 2, 2, 301, 3,
+	2, 2, 2, 2, 303, 3, 2, 2, 2, 2, 305, 3, 2, 2, 2, 2, 307, 3, 2, 2, 2, 2,
+	309, 3, 2, 2, 2, 2, 311, 3, 2, 2, 2, 2, 313, 3, 2, 2, 2, 2, 315, 3, 2,
+	2, 2, 2, 317, 3, 2, 2, 2, 2, 319, 3, 2, 2, 2, 2, 321, 3, 2, 2, 2, 2, 323,
+	3, 2, 2, 2, 2, 325, 3, 2, 2, 2, 2, 327, 3, 2, 2, 2, 2, 329, 3, 2, 2, 2,
+	2, 331, 3, 2, 2, 2, 2, 333, 3, 2, 2, 2, 2, 335, 3, 2, 2, 2, 2, 337, 3,
+	2, 2, 2, 2, 339, 3, 2, 2, 2, 2, 341, 3, 2, 2, 2, 2, 343, 3, 2, 2, 2, 2,
+	345, 3, 2, 2, 2, 2, 347, 3, 2, 2, 2, 2, 349, 3, 2, 2, 2, 2, 351, 3, 2,
+	2, 2, 2, 353, 3, 2, 2, 2, 2, 355, 3, 2, 2, 2, 2, 357, 3, 2, 2, 2, 2, 359,
+	3, 2, 2, 2, 2, 361, 3, 2, 2, 2, 2, 363, 3, 2, 2, 2, 2, 365, 3, 2, 2, 2,
+	2, 367, 3, 2, 2, 2, 2, 369, 3, 2, 2, 2, 2, 371, 3, 2, 2, 2, 2, 373, 3,
+	2, 2, 2, 2, 375, 3, 2, 2, 2, 2, 377, 3, 2, 2, 2, 2, 379, 3, 2, 2, 2, 2,
+	381, 3, 2, 2, 2, 2, 383, 3, 2, 2, 2, 2, 385, 3, 2, 2, 2, 2, 387, 3, 2,
+	2, 2, 2, 389, 3, 2, 2, 2, 2, 391, 3, 2, 2, 2, 2, 393, 3, 2, 2, 2, 2, 395,
+	3, 2, 2, 2, 2, 397, 3, 2, 2, 2, 2, 399, 3, 2, 2, 2, 2, 401, 3, 2, 2, 2,
+	2, 403, 3, 2, 2, 2, 2, 405, 3, 2, 2, 2, 2, 407, 3, 2, 2, 2, 2, 409, 3,
+	2, 2, 2, 2, 411, 3, 2, 2, 2, 2, 413, 3, 2, 2, 2, 2, 415, 3, 2, 2, 2, 2,
+	417, 3, 2, 2, 2, 2, 419, 3, 2, 2, 2, 2, 427, 3, 2, 2, 2, 2, 429, 3, 2,
+	2, 2, 2, 431, 3, 2, 2, 2, 3, 433, 3, 2, 2, 2, 5, 435, 3, 2, 2, 2, 7, 437,
+	3, 2, 2, 2, 9, 439, 3, 2, 2, 2, 11, 441, 3, 2, 2, 2, 13, 444, 3, 2, 2,
+	2, 15, 446, 3, 2, 2, 2, 17, 448, 3, 2, 2, 2, 19, 451, 3, 2, 2, 2, 21, 453,
+	3, 2, 2, 2, 23, 460, 3, 2, 2, 2, 25, 465, 3, 2, 2, 2, 27, 469, 3, 2, 2,
+	2, 29, 472, 3, 2, 2, 2, 31, 476, 3, 2, 2, 2, 33, 481, 3, 2, 2, 2, 35, 485,
+	3, 2, 2, 2, 37, 494, 3, 2, 2, 2, 39, 500, 3, 2, 2, 2, 41, 506, 3, 2, 2,
+	2, 43, 509, 3, 2, 2, 2, 45, 518, 3, 2, 2, 2, 47, 523, 3, 2, 2, 2, 49, 528,
+	3, 2, 2, 2, 51, 535, 3, 2, 2, 2, 53, 541, 3, 2, 2, 2, 55, 548, 3, 2, 2,
+	2, 57, 554, 3, 2, 2, 2, 59, 557, 3, 2, 2, 2, 61, 560, 3, 2, 2, 2, 63, 564,
+	3, 2, 2, 2, 65, 567, 3, 2, 2, 2, 67, 571, 3, 2, 2, 2, 69, 574, 3, 2, 2,
+	2, 71, 581, 3, 2, 2, 2, 73, 589, 3, 2, 2, 2, 75, 594, 3, 2, 2, 2, 77, 597,
+	3, 2, 2, 2, 79, 602, 3, 2, 2, 2, 81, 607, 3, 2, 2, 2, 83, 613, 3, 2, 2,
+	2, 85, 619, 3, 2, 2, 2, 87, 625, 3, 2, 2, 2, 89, 630, 3, 2, 2, 2, 91, 637,
+	3, 2, 2, 2, 93, 641, 3, 2, 2, 2, 95, 646, 3, 2, 2, 2, 97, 656, 3, 2, 2,
+	2, 99, 665, 3, 2, 2, 2, 101, 669, 3, 2, 2, 2, 103, 677, 3, 2, 2, 2, 105,
+	686, 3, 2, 2, 2, 107, 694, 3, 2, 2, 2, 109, 699, 3, 2, 2, 2, 111, 704,
+	3, 2, 2, 2, 113, 714, 3, 2, 2, 2, 115, 723, 3, 2, 2, 2, 117, 728, 3, 2,
+	2, 2, 119, 734, 3, 2, 2, 2, 121, 738, 3, 2, 2, 2, 123, 743, 3, 2, 2, 2,
+	125, 750, 3, 2, 2, 2, 127, 757, 3, 2, 2, 2, 129, 762, 3, 2, 2, 2, 131,
+	775, 3, 2, 2, 2, 133, 788, 3, 2, 2, 2, 135, 806, 3, 2, 2, 2, 137, 816,
+	3, 2, 2, 2, 139, 831, 3, 2, 2, 2, 141, 839, 3, 2, 2, 2, 143, 844, 3, 2,
+	2, 2, 145, 849, 3, 2, 2, 2, 147, 854, 3, 2, 2, 2, 149, 859, 3, 2, 2, 2,
+	151, 863, 3, 2, 2, 2, 153, 868, 3, 2, 2, 2, 155, 874, 3, 2, 2, 2, 157,
+	880, 3, 2, 2, 2, 159, 886, 3, 2, 2, 2, 161, 891, 3, 2, 2, 2, 163, 897,
+	3, 2, 2, 2, 165, 902, 3, 2, 2, 2, 167, 910, 3, 2, 2, 2, 169, 916, 3, 2,
+	2, 2, 171, 919, 3, 2, 2, 2, 173, 926, 3, 2, 2, 2, 175, 931, 3, 2, 2, 2,
+	177, 941, 3, 2, 2, 2, 179, 947, 3, 2, 2, 2, 181, 952, 3, 2, 2, 2, 183,
+	962, 3, 2, 2, 2, 185, 972, 3, 2, 2, 2, 187, 982, 3, 2, 2, 2, 189, 990,
+	3, 2, 2, 2, 191, 994, 3, 2, 2, 2, 193, 999, 3, 2, 2, 2, 195, 1009, 3, 2,
+	2, 2, 197, 1016, 3, 2, 2, 2, 199, 1023, 3, 2, 2, 2, 201, 1030, 3, 2, 2,
+	2, 203, 1036, 3, 2, 2, 2, 205, 1044, 3, 2, 2, 2, 207, 1049, 3, 2, 2, 2,
+	209, 1057, 3, 2, 2, 2, 211, 1064, 3, 2, 2, 2, 213, 1071, 3, 2, 2, 2, 215,
+	1076, 3, 2, 2, 2, 217, 1087, 3, 2, 2, 2, 219, 1096, 3, 2, 2, 2, 221, 1102,
+	3, 2, 2, 2, 223, 1109, 3, 2, 2, 2, 225, 1120, 3, 2, 2, 2, 227, 1127, 3,
+	2, 2, 2, 229, 1134, 3, 2, 2, 2, 231, 1142, 3, 2, 2, 2, 233, 1150, 3, 2,
+	2, 2, 235, 1157, 3, 2, 2, 2, 237, 1162, 3, 2, 2, 2, 239, 1167, 3, 2, 2,
+	2, 241, 1176, 3, 2, 2, 2, 243, 1184, 3, 2, 2, 2, 245, 1196, 3, 2, 2, 2,
+	247, 1205, 3, 2, 2, 2, 249, 1210, 3, 2, 2, 2, 251, 1219, 3, 2, 2, 2, 253,
+	1224, 3, 2, 2, 2, 255, 1231, 3, 2, 2, 2, 257, 1239, 3, 2, 2, 2, 259, 1248,
+	3, 2, 2, 2, 261, 1256, 3, 2, 2, 2, 263, 1263, 3, 2, 2, 2, 265, 1267, 3,
+	2, 2, 2, 267, 1278, 3, 2, 2, 2, 269, 1288, 3, 2, 2, 2, 271, 1293, 3, 2,
+	2, 2, 273, 1299, 3, 2, 2, 2, 275, 1306, 3, 2, 2, 2, 277, 1316, 3, 2, 2,
+	2, 279, 1319, 3, 2, 2, 2, 281, 1326, 3, 2, 2, 2, 283, 1336, 3, 2, 2, 2,
+	285, 1348, 3, 2, 2, 2, 287, 1360, 3, 2, 2, 2, 289, 1366, 3, 2, 2, 2, 291,
+	1373, 3, 2, 2, 2, 293, 1380, 3, 2, 2, 2, 295, 1391, 3, 2, 2, 2, 297, 1397,
+	3, 2, 2, 2, 299, 1401, 3, 2, 2, 2, 301, 1405, 3, 2, 2, 2, 303, 1411, 3,
+	2, 2, 2, 305, 1419, 3, 2, 2, 2, 307, 1424, 3, 2, 2, 2, 309, 1430, 3, 2,
+	2, 2, 311, 1442, 3, 2, 2, 2, 313, 1449, 3, 2, 2, 2, 315, 1458, 3, 2, 2,
+	2, 317, 1463, 3, 2, 2, 2, 319, 1473, 3, 2, 2, 2, 321, 1479, 3, 2, 2, 2,
+	323, 1492, 3, 2, 2, 2, 325, 1503, 3, 2, 2, 2, 327, 1513, 3, 2, 2, 2, 329,
+	1525, 3, 2, 2, 2, 331, 1530, 3, 2, 2, 2, 333, 1536, 3, 2, 2, 2, 335, 1541,
+	3, 2, 2, 2, 337, 1546, 3, 2, 2, 2, 339, 1554, 3, 2, 2, 2, 341, 1565, 3,
+	2, 2, 2, 343, 1573, 3, 2, 2, 2, 345, 1579, 3, 2, 2, 2, 347, 1586, 3, 2,
+	2, 2, 349, 1594, 3, 2, 2, 2, 351, 1603, 3, 2, 2, 2, 353, 1613, 3, 2, 2,
+	2, 355, 1623, 3, 2, 2, 2, 357, 1634, 3, 2, 2, 2, 359, 1644, 3, 2, 2, 2,
+	361, 1648, 3, 2, 2, 2, 363, 1652, 3, 2, 2, 2, 365, 1657, 3, 2, 2, 2, 367,
+	1662, 3, 2, 2, 2, 369, 1665, 3, 2, 2, 2, 371, 1672, 3, 2, 2, 2, 373, 1681,
+	3, 2, 2, 2, 375, 1701, 3, 2, 2, 2, 377, 1726, 3, 2, 2, 2, 379, 1743, 3,
+	2, 2, 2, 381, 1749, 3, 2, 2, 2, 383, 1751, 3, 2, 2, 2, 385, 1753, 3, 2,
+	2, 2, 387, 1756, 3, 2, 2, 2, 389, 1758, 3, 2, 2, 2, 391, 1761, 3, 2, 2,
+	2, 393, 1763, 3, 2, 2, 2, 395, 1765, 3, 2, 2, 2, 397, 1767, 3, 2, 2, 2,
+	399, 1769, 3, 2, 2, 2, 401, 1771, 3, 2, 2, 2, 403, 1774, 3, 2, 2, 2, 405,
+	1776, 3, 2, 2, 2, 407, 1787, 3, 2, 2, 2, 409, 1799, 3, 2, 2, 2, 411, 1845,
+	3, 2, 2, 2, 413, 1849, 3, 2, 2, 2, 415, 1859, 3, 2, 2, 2, 417, 1867, 3,
+	2, 2, 2, 419, 1878, 3, 2, 2, 2, 421, 1889, 3, 2, 2, 2, 423, 1898, 3, 2,
+	2, 2, 425, 1900, 3, 2, 2, 2, 427, 1902, 3, 2, 2, 2, 429, 1919, 3, 2, 2,
+	2, 431, 1934, 3, 2, 2, 2, 433, 434, 7, 42, 2, 2, 434, 4, 3, 2, 2, 2, 435,
+	436, 7, 46, 2, 2, 436, 6, 3, 2, 2, 2, 437, 438, 7, 43, 2, 2, 438, 8, 3,
+	2, 2, 2, 439, 440, 7, 65, 2, 2, 440, 10, 3, 2, 2, 2, 441, 442, 7, 47, 2,
+	2, 442, 443, 7, 64, 2, 2, 443, 12, 3, 2, 2, 2, 444, 445, 7, 93, 2, 2, 445,
+	14, 3, 2, 2, 2, 446, 447, 7, 95, 2, 2, 447, 16, 3, 2, 2, 2, 448, 449, 7,
+	63, 2, 2, 449, 450, 7, 64, 2, 2, 450, 18, 3, 2, 2, 2, 451, 452, 7, 61,
+	2, 2, 452, 20, 3, 2, 2, 2, 453, 454, 9, 2, 2, 2, 454, 455, 9, 3, 2, 2,
+	455, 456, 9, 4, 2, 2, 456, 457, 9, 3, 2, 2, 457, 458, 9, 5, 2, 2, 458,
+	459, 9, 6, 2, 2, 459, 22, 3, 2, 2, 2, 460, 461, 9, 7, 2, 2, 461, 462, 9,
+	8, 2, 2, 462, 463, 9, 9, 2, 2, 463, 464, 9, 10, 2, 2, 464, 24, 3, 2, 2,
+	2, 465, 466, 9, 11, 2, 2, 466, 467, 9, 12, 2, 2, 467, 468, 9, 12, 2, 2,
+	468, 26, 3, 2, 2, 2, 469, 470, 9, 11, 2, 2, 470, 471, 9, 2, 2, 2, 471,
+	28, 3, 2, 2, 2, 472, 473, 9, 11, 2, 2, 473, 474, 9, 4, 2, 2, 474, 475,
+	9, 4, 2, 2, 475, 30, 3, 2, 2, 2, 476, 477, 9, 2, 2, 2, 477, 478, 9, 9,
+	2, 2, 478, 479, 9, 10, 2, 2, 479, 480, 9, 3, 2, 2, 480, 32, 3, 2, 2, 2,
+	481, 482, 9, 11, 2, 2, 482, 483, 9, 13, 2, 2, 483, 484, 9, 14, 2, 2, 484,
+	34, 3, 2, 2, 2, 485, 486, 9, 12, 2, 2, 486, 487, 9, 15, 2, 2, 487, 488,
+	9, 2, 2, 2, 488, 489, 9, 6, 2, 2, 489, 490, 9, 15, 2, 2, 490, 491, 9, 13,
+	2, 2, 491, 492, 9, 5, 2, 2, 492, 493, 9, 6, 2, 2, 493, 36, 3, 2, 2, 2,
+	494, 495, 9, 16, 2, 2, 495, 496, 9, 17, 2, 2, 496, 497, 9, 3, 2, 2, 497,
+	498, 9, 8, 2, 2, 498, 499, 9, 3, 2, 2, 499, 38, 3, 2, 2, 2, 500, 501, 9,
+	18, 2, 2, 501, 502, 9, 8, 2, 2, 502, 503, 9, 9, 2, 2, 503, 504, 9, 19,
+	2, 2, 504, 505, 9, 20, 2, 2, 505, 40, 3, 2, 2, 2, 506, 507, 9, 21, 2, 2,
+	507, 508, 9, 14, 2, 2, 508, 42, 3, 2, 2, 2, 509, 510, 9, 18, 2, 2, 510,
+	511, 9, 8, 2, 2, 511, 512, 9, 9, 2, 2, 512, 513, 9, 19, 2, 2, 513, 514,
+	9, 20, 2, 2, 514, 515, 9, 15, 2, 2, 515, 516, 9, 13, 2, 2, 516, 517, 9,
+	18, 2, 2, 517, 44, 3, 2, 2, 2, 518, 519, 9, 2, 2, 2, 519, 520, 9, 3, 2,
+	2, 520, 521, 9, 6, 2, 2, 521, 522, 9, 2, 2, 2, 522, 46, 3, 2, 2, 2, 523,
+	524, 9, 5, 2, 2, 524, 525, 9, 19, 2, 2, 525, 526, 9, 21, 2, 2, 526, 527,
+	9, 3, 2, 2, 527, 48, 3, 2, 2, 2, 528, 529, 9, 8, 2, 2, 529, 530, 9, 9,
+	2, 2, 530, 531, 9, 4, 2, 2, 531, 532, 9, 4, 2, 2, 532, 533, 9, 19, 2, 2,
+	533, 534, 9, 20, 2, 2, 534, 50, 3, 2, 2, 2, 535, 536, 9, 9, 2, 2, 536,
+	537, 9, 8, 2, 2, 537, 538, 9, 12, 2, 2, 538, 539, 9, 3, 2, 2, 539, 540,
+	9, 8, 2, 2, 540, 52, 3, 2, 2, 2, 541, 542, 9, 17, 2, 2, 542, 543, 9, 11,
+	2, 2, 543, 544, 9, 22, 2, 2, 544, 545, 9, 15, 2, 2, 545, 546, 9, 13, 2,
+	2, 546, 547, 9, 18, 2, 2, 547, 54, 3, 2, 2, 2, 548, 549, 9, 4, 2, 2, 549,
+	550, 9, 15, 2, 2, 550, 551, 9, 10, 2, 2, 551, 552, 9, 15, 2, 2, 552, 553,
+	9, 6, 2, 2, 553, 56, 3, 2, 2, 2, 554, 555, 9, 11, 2, 2, 555, 556, 9, 6,
+	2, 2, 556, 58, 3, 2, 2, 2, 557, 558, 9, 9, 2, 2, 558, 559, 9, 8, 2, 2,
+	559, 60, 3, 2, 2, 2, 560, 561, 9, 11, 2, 2, 561, 562, 9, 13, 2, 2, 562,
+	563, 9, 12, 2, 2, 563, 62, 3, 2, 2, 2, 564, 565, 9, 15, 2, 2, 565, 566,
+	9, 13, 2, 2, 566, 64, 3, 2, 2, 2, 567, 568, 9, 13, 2, 2, 568, 569, 9, 9,
+	2, 2, 569, 570, 9, 6, 2, 2, 570, 66, 3, 2, 2, 2, 571, 572, 9, 13, 2, 2,
+	572, 573, 9, 9, 2, 2, 573, 68, 3, 2, 2, 2, 574, 575, 9, 3, 2, 2, 575, 576,
+	9, 23, 2, 2, 576, 577, 9, 15, 2, 2, 577, 578, 9, 2, 2, 2, 578, 579, 9,
+	6, 2, 2, 579, 580, 9, 2, 2, 2, 580, 70, 3, 2, 2, 2, 581, 582, 9, 21, 2,
+	2, 582, 583, 9, 3, 2, 2, 583, 584, 9, 6, 2, 2, 584, 585, 9, 16, 2, 2, 585,
+	586, 9, 3, 2, 2, 586, 587, 9, 3, 2, 2, 587, 588, 9, 13, 2, 2, 588, 72,
+	3, 2, 2, 2, 589, 590, 9, 4, 2, 2, 590, 591, 9, 15, 2, 2, 591, 592, 9, 24,
+	2, 2, 592, 593, 9, 3, 2, 2, 593, 74, 3, 2, 2, 2, 594, 595, 9, 15, 2, 2,
+	595, 596, 9, 2, 2, 2, 596, 76, 3, 2, 2, 2, 597, 598, 9, 13, 2, 2, 598,
+	599, 9, 19, 2, 2, 599, 600, 9, 4, 2, 2, 600, 601, 9, 4, 2, 2, 601, 78,
+	3, 2, 2, 2, 602, 603, 9, 6, 2, 2, 603, 604, 9, 8, 2, 2, 604, 605, 9, 19,
+	2, 2, 605, 606, 9, 3, 2, 2, 606, 80, 3, 2, 2, 2, 607, 608, 9, 7, 2, 2,
+	608, 609, 9, 11, 2, 2, 609, 610, 9, 4, 2, 2, 610, 611, 9, 2, 2, 2, 611,
+	612, 9, 3, 2, 2, 612, 82, 3, 2, 2, 2, 613, 614, 9, 13, 2, 2, 614, 615,
+	9, 19, 2, 2, 615, 616, 9, 4, 2, 2, 616, 617, 9, 4, 2, 2, 617, 618, 9, 2,
+	2, 2, 618, 84, 3, 2, 2, 2, 619, 620, 9, 7, 2, 2, 620, 621, 9, 15, 2, 2,
+	621, 622, 9, 8, 2, 2, 622, 623, 9, 2, 2, 2, 623, 624, 9, 6, 2, 2, 624,
+	86, 3, 2, 2, 2, 625, 626, 9, 4, 2, 2, 626, 627, 9, 11, 2, 2, 627, 628,
+	9, 2, 2, 2, 628, 629, 9, 6, 2, 2, 629, 88, 3, 2, 2, 2, 630, 631, 9, 3,
+	2, 2, 631, 632, 9, 2, 2, 2, 632, 633, 9, 5, 2, 2, 633, 634, 9, 11, 2, 2,
+	634, 635, 9, 20, 2, 2, 635, 636, 9, 3, 2, 2, 636, 90, 3, 2, 2, 2, 637,
+	638, 9, 11, 2, 2, 638, 639, 9, 2, 2, 2, 639, 640, 9, 5, 2, 2, 640, 92,
+	3, 2, 2, 2, 641, 642, 9, 12, 2, 2, 642, 643, 9, 3, 2, 2, 643, 644, 9, 2,
+	2, 2, 644, 645, 9, 5, 2, 2, 645, 94, 3, 2, 2, 2, 646, 647, 9, 2, 2, 2,
+	647, 648, 9, 19, 2, 2, 648, 649, 9, 21, 2, 2, 649, 650, 9, 2, 2, 2, 650,
+	651, 9, 6, 2, 2, 651, 652, 9, 8, 2, 2, 652, 653, 9, 15, 2, 2, 653, 654,
+	9, 13, 2, 2, 654, 655, 9, 18, 2, 2, 655, 96, 3, 2, 2, 2, 656, 657, 9, 20,
+	2, 2, 657, 658, 9, 9, 2, 2, 658, 659, 9, 2, 2, 2, 659, 660, 9, 15, 2, 2,
+	660, 661, 9, 6, 2, 2, 661, 662, 9, 15, 2, 2, 662, 663, 9, 9, 2, 2, 663,
+	664, 9, 13, 2, 2, 664, 98, 3, 2, 2, 2, 665, 666, 9, 7, 2, 2, 666, 667,
+	9, 9, 2, 2, 667, 668, 9, 8, 2, 2, 668, 100, 3, 2, 2, 2, 669, 670, 9, 6,
+	2, 2, 670, 671, 9, 15, 2, 2, 671, 672, 9, 13, 2, 2, 672, 673, 9, 14, 2,
+	2, 673, 674, 9, 15, 2, 2, 674, 675, 9, 13, 2, 2, 675, 676, 9, 6, 2, 2,
+	676, 102, 3, 2, 2, 2, 677, 678, 9, 2, 2, 2, 678, 679, 9, 10, 2, 2, 679,
+	680, 9, 11, 2, 2, 680, 681, 9, 4, 2, 2, 681, 682, 9, 4, 2, 2, 682, 683,
+	9, 15, 2, 2, 683, 684, 9, 13, 2, 2, 684, 685, 9, 6, 2, 2, 685, 104, 3,
+	2, 2, 2, 686, 687, 9, 15, 2, 2, 687, 688, 9, 13, 2, 2, 688, 689, 9, 6,
+	2, 2, 689, 690, 9, 3, 2, 2, 690, 691, 9, 18, 2, 2, 691, 692, 9, 3, 2, 2,
+	692, 693, 9, 8, 2, 2, 693, 106, 3, 2, 2, 2, 694, 695, 9, 12, 2, 2, 695,
+	696, 9, 11, 2, 2, 696, 697, 9, 6, 2, 2, 697, 698, 9, 3, 2, 2, 698, 108,
+	3, 2, 2, 2, 699, 700, 9, 6, 2, 2, 700, 701, 9, 15, 2, 2, 701, 702, 9, 10,
+	2, 2, 702, 703, 9, 3, 2, 2, 703, 110, 3, 2, 2, 2, 704, 705, 9, 6, 2, 2,
+	705, 706, 9, 15, 2, 2, 706, 707, 9, 10, 2, 2, 707, 708, 9, 3, 2, 2, 708,
+	709, 9, 2, 2, 2, 709, 710, 9, 6, 2, 2, 710, 711, 9, 11, 2, 2, 711, 712,
+	9, 10, 2, 2, 712, 713, 9, 20, 2, 2, 713, 112, 3, 2, 2, 2, 714, 715, 9,
+	15, 2, 2, 715, 716, 9, 13, 2, 2, 716, 717, 9, 6, 2, 2, 717, 718, 9, 3,
+	2, 2, 718, 719, 9, 8, 2, 2, 719, 720, 9, 22, 2, 2, 720, 721, 9, 11, 2,
+	2, 721, 722, 9, 4, 2, 2, 722, 114, 3, 2, 2, 2, 723, 724, 9, 14, 2, 2, 724,
+	725, 9, 3, 2, 2, 725, 726, 9, 11, 2, 2, 726, 727, 9, 8, 2, 2, 727, 116,
+	3, 2, 2, 2, 728, 729, 9, 10, 2, 2, 729, 730, 9, 9, 2, 2, 730, 731, 9, 13,
+	2, 2, 731, 732, 9, 6, 2, 2, 732, 733, 9, 17, 2, 2, 733, 118, 3, 2, 2, 2,
+	734, 735, 9, 12, 2, 2, 735, 736, 9, 11, 2, 2, 736, 737, 9, 14, 2, 2, 737,
+	120, 3, 2, 2, 2, 738, 739, 9, 17, 2, 2, 739, 740, 9, 9, 2, 2, 740, 741,
+	9, 19, 2, 2, 741, 742, 9, 8, 2, 2, 742, 122, 3, 2, 2, 2, 743, 744, 9, 10,
+	2, 2, 744, 745, 9, 15, 2, 2, 745, 746, 9, 13, 2, 2, 746, 747, 9, 19, 2,
+	2, 747, 748, 9, 6, 2, 2, 748, 749, 9, 3, 2, 2, 749, 124, 3, 2, 2, 2, 750,
+	751, 9, 2, 2, 2, 751, 752, 9, 3, 2, 2, 752, 753, 9, 5, 2, 2, 753, 754,
+	9, 9, 2, 2, 754, 755, 9, 13, 2, 2, 755, 756, 9, 12, 2, 2, 756, 126, 3,
+	2, 2, 2, 757, 758, 9, 25, 2, 2, 758, 759, 9, 9, 2, 2, 759, 760, 9, 13,
+	2, 2, 760, 761, 9, 3, 2, 2, 761, 128, 3, 2, 2, 2, 762, 763, 9, 5, 2, 2,
+	763, 764, 9, 19, 2, 2, 764, 765, 9, 8, 2, 2, 765, 766, 9, 8, 2, 2, 766,
+	767, 9, 3, 2, 2, 767, 768, 9, 13, 2, 2, 768, 769, 9, 6, 2, 2, 769, 770,
+	7, 97, 2, 2, 770, 771, 9, 12, 2, 2, 771, 772, 9, 11, 2, 2, 772, 773, 9,
+	6, 2, 2, 773, 774, 9, 3, 2, 2, 774, 130, 3, 2, 2, 2, 775, 776, 9, 5, 2,
+	2, 776, 777, 9, 19, 2, 2, 777, 778, 9, 8, 2, 2, 778, 779, 9, 8, 2, 2, 779,
+	780, 9, 3, 2, 2, 780, 781, 9, 13, 2, 2, 781, 782, 9, 6, 2, 2, 782, 783,
+	7, 97, 2, 2, 783, 784, 9, 6, 2, 2, 784, 785, 9, 15, 2, 2, 785, 786, 9,
+	10, 2, 2, 786, 787, 9, 3, 2, 2, 787, 132, 3, 2, 2, 2, 788, 789, 9, 5, 2,
+	2, 789, 790, 9, 19, 2, 2, 790, 791, 9, 8, 2, 2, 791, 792, 9, 8, 2, 2, 792,
+	793, 9, 3, 2, 2, 793, 794, 9, 13, 2, 2, 794, 795, 9, 6, 2, 2, 795, 796,
+	7, 97, 2, 2, 796, 797, 9, 6, 2, 2, 797, 798, 9, 15, 2, 2, 798, 799, 9,
+	10, 2, 2, 799, 800, 9, 3, 2, 2, 800, 801, 9, 2, 2, 2, 801, 802, 9, 6, 2,
+	2, 802, 803, 9, 11, 2, 2, 803, 804, 9, 10, 2, 2, 804, 805, 9, 20, 2, 2,
+	805, 134, 3, 2, 2, 2, 806, 807, 9, 4, 2, 2, 807, 808, 9, 9, 2, 2, 808,
+	809, 9, 5, 2, 2, 809, 810, 9, 11, 2, 2, 810, 811, 9, 4, 2, 2, 811, 812,
+	9, 6, 2, 2, 812, 813, 9, 15, 2, 2, 813, 814, 9, 10, 2, 2, 814, 815, 9,
+	3, 2, 2, 815, 136, 3, 2, 2, 2, 816, 817, 9, 4, 2, 2, 817, 818, 9, 9, 2,
+	2, 818, 819, 9, 5, 2, 2, 819, 820, 9, 11, 2, 2, 820, 821, 9, 4, 2, 2, 821,
+	822, 9, 6, 2, 2, 822, 823, 9, 15, 2, 2, 823, 824, 9, 10, 2, 2, 824, 825,
+	9, 3, 2, 2, 825, 826, 9, 2, 2, 2, 826, 827, 9, 6, 2, 2, 827, 828, 9, 11,
+	2, 2, 828, 829, 9, 10, 2, 2, 829, 830, 9, 20, 2, 2, 830, 138, 3, 2, 2,
+	2, 831, 832, 9, 3, 2, 2, 832, 833, 9, 23, 2, 2, 833, 834, 9, 6, 2, 2, 834,
+	835, 9, 8, 2, 2, 835, 836, 9, 11, 2, 2, 836, 837, 9, 5, 2, 2, 837, 838,
+	9, 6, 2, 2, 838, 140, 3, 2, 2, 2, 839, 840, 9, 5, 2, 2, 840, 841, 9, 11,
+	2, 2, 841, 842, 9, 2, 2, 2, 842, 843, 9, 3, 2, 2, 843, 142, 3, 2, 2, 2,
+	844, 845, 9, 16, 2, 2, 845, 846, 9, 17, 2, 2, 846, 847, 9, 3, 2, 2, 847,
+	848, 9, 13, 2, 2, 848, 144, 3, 2, 2, 2, 849, 850, 9, 6, 2, 2, 850, 851,
+	9, 17, 2, 2, 851, 852, 9, 3, 2, 2, 852, 853, 9, 13, 2, 2, 853, 146, 3,
+	2, 2, 2, 854, 855, 9, 3, 2, 2, 855, 856, 9, 4, 2, 2, 856, 857, 9, 2, 2,
+	2, 857, 858, 9, 3, 2, 2, 858, 148, 3, 2, 2, 2, 859, 860, 9, 3, 2, 2, 860,
+	861, 9, 13, 2, 2, 861, 862, 9, 12, 2, 2, 862, 150, 3, 2, 2, 2, 863, 864,
+	9, 26, 2, 2, 864, 865, 9, 9, 2, 2, 865, 866, 9, 15, 2, 2, 866, 867, 9,
+	13, 2, 2, 867, 152, 3, 2, 2, 2, 868, 869, 9, 5, 2, 2, 869, 870, 9, 8, 2,
+	2, 870, 871, 9, 9, 2, 2, 871, 872, 9, 2, 2, 2, 872, 873, 9, 2, 2, 2, 873,
+	154, 3, 2, 2, 2, 874, 875, 9, 9, 2, 2, 875, 876, 9, 19, 2, 2, 876, 877,
+	9, 6, 2, 2, 877, 878, 9, 3, 2, 2, 878, 879, 9, 8, 2, 2, 879, 156, 3, 2,
+	2, 2, 880, 881, 9, 15, 2, 2, 881, 882, 9, 13, 2, 2, 882, 883, 9, 13, 2,
+	2, 883, 884, 9, 3, 2, 2, 884, 885, 9, 8, 2, 2, 885, 158, 3, 2, 2, 2, 886,
+	887, 9, 4, 2, 2, 887, 888, 9, 3, 2, 2, 888, 889, 9, 7, 2, 2, 889, 890,
+	9, 6, 2, 2, 890, 160, 3, 2, 2, 2, 891, 892, 9, 8, 2, 2, 892, 893, 9, 15,
+	2, 2, 893, 894, 9, 18, 2, 2, 894, 895, 9, 17, 2, 2, 895, 896, 9, 6, 2,
+	2, 896, 162, 3, 2, 2, 2, 897, 898, 9, 7, 2, 2, 898, 899, 9, 19, 2, 2, 899,
+	900, 9, 4, 2, 2, 900, 901, 9, 4, 2, 2, 901, 164, 3, 2, 2, 2, 902, 903,
+	9, 13, 2, 2, 903, 904, 9, 11, 2, 2, 904, 905, 9, 6, 2, 2, 905, 906, 9,
+	19, 2, 2, 906, 907, 9, 8, 2, 2, 907, 908, 9, 11, 2, 2, 908, 909, 9, 4,
+	2, 2, 909, 166, 3, 2, 2, 2, 910, 911, 9, 19, 2, 2, 911, 912, 9, 2, 2, 2,
+	912, 913, 9, 15, 2, 2, 913, 914, 9, 13, 2, 2, 914, 915, 9, 18, 2, 2, 915,
+	168, 3, 2, 2, 2, 916, 917, 9, 9, 2, 2, 917, 918, 9, 13, 2, 2, 918, 170,
+	3, 2, 2, 2, 919, 920, 9, 7, 2, 2, 920, 921, 9, 15, 2, 2, 921, 922, 9, 4,
+	2, 2, 922, 923, 9, 6, 2, 2, 923, 924, 9, 3, 2, 2, 924, 925, 9, 8, 2, 2,
+	925, 172, 3, 2, 2, 2, 926, 927, 9, 9, 2, 2, 927, 928, 9, 22, 2, 2, 928,
+	929, 9, 3, 2, 2, 929, 930, 9, 8, 2, 2, 930, 174, 3, 2, 2, 2, 931, 932,
+	9, 20, 2, 2, 932, 933, 9, 11, 2, 2, 933, 934, 9, 8, 2, 2, 934, 935, 9,
+	6, 2, 2, 935, 936, 9, 15, 2, 2, 936, 937, 9, 6, 2, 2, 937, 938, 9, 15,
+	2, 2, 938, 939, 9, 9, 2, 2, 939, 940, 9, 13, 2, 2, 940, 176, 3, 2, 2, 2,
+	941, 942, 9, 8, 2, 2, 942, 943, 9, 11, 2, 2, 943, 944, 9, 13, 2, 2, 944,
+	945, 9, 18, 2, 2, 945, 946, 9, 3, 2, 2, 946, 178, 3, 2, 2, 2, 947, 948,
+	9, 8, 2, 2, 948, 949, 9, 9, 2, 2, 949, 950, 9, 16, 2, 2, 950, 951, 9, 2,
+	2, 2, 951, 180, 3, 2, 2, 2, 952, 953, 9, 19, 2, 2, 953, 954, 9, 13, 2,
+	2, 954, 955, 9, 21, 2, 2, 955, 956, 9, 9, 2, 2, 956, 957, 9, 19, 2, 2,
+	957, 958, 9, 13, 2, 2, 958, 959, 9, 12, 2, 2, 959, 960, 9, 3, 2, 2, 960,
+	961, 9, 12, 2, 2, 961, 182, 3, 2, 2, 2, 962, 963, 9, 20, 2, 2, 963, 964,
+	9, 8, 2, 2, 964, 965, 9, 3, 2, 2, 965, 966, 9, 5, 2, 2, 966, 967, 9, 3,
+	2, 2, 967, 968, 9, 12, 2, 2, 968, 969, 9, 15, 2, 2, 969, 970, 9, 13, 2,
+	2, 970, 971, 9, 18, 2, 2, 971, 184, 3, 2, 2, 2, 972, 973, 9, 7, 2, 2, 973,
+	974, 9, 9, 2, 2, 974, 975, 9, 4, 2, 2, 975, 976, 9, 4, 2, 2, 976, 977,
+	9, 9, 2, 2, 977, 978, 9, 16, 2, 2, 978, 979, 9, 15, 2, 2, 979, 980, 9,
+	13, 2, 2, 980, 981, 9, 18, 2, 2, 981, 186, 3, 2, 2, 2, 982, 983, 9, 5,
+	2, 2, 983, 984, 9, 19, 2, 2, 984, 985, 9, 8, 2, 2, 985, 986, 9, 8, 2, 2,
+	986, 987, 9, 3, 2, 2, 987, 988, 9, 13, 2, 2, 988, 989, 9, 6, 2, 2, 989,
+	188, 3, 2, 2, 2, 990, 991, 9, 8, 2, 2, 991, 992, 9, 9, 2, 2, 992, 993,
+	9, 16, 2, 2, 993, 190, 3, 2, 2, 2, 994, 995, 9, 16, 2, 2, 995, 996, 9,
+	15, 2, 2, 996, 997, 9, 6, 2, 2, 997, 998, 9, 17, 2, 2, 998, 192, 3, 2,
+	2, 2, 999, 1000, 9, 8, 2, 2, 1000, 1001, 9, 3, 2, 2, 1001, 1002, 9, 5,
+	2, 2, 1002, 1003, 9, 19, 2, 2, 1003, 1004, 9, 8, 2, 2, 1004, 1005, 9, 2,
+	2, 2, 1005, 1006, 9, 15, 2, 2, 1006, 1007, 9, 22, 2, 2, 1007, 1008, 9,
+	3, 2, 2, 1008, 194, 3, 2, 2, 2, 1009, 1010, 9, 22, 2, 2, 1010, 1011, 9,
+	11, 2, 2, 1011, 1012, 9, 4, 2, 2, 1012, 1013, 9, 19, 2, 2, 1013, 1014,
+	9, 3, 2, 2, 1014, 1015, 9, 2, 2, 2, 1015, 196, 3, 2, 2, 2, 1016, 1017,
+	9, 5, 2, 2, 1017, 1018, 9, 8, 2, 2, 1018, 1019, 9, 3, 2, 2, 1019, 1020,
+	9, 11, 2, 2, 1020, 1021, 9, 6, 2, 2, 1021, 1022, 9, 3, 2, 2, 1022, 198,
+	3, 2, 2, 2, 1023, 1024, 9, 2, 2, 2, 1024, 1025, 9, 5, 2, 2, 1025, 1026,
+	9, 17, 2, 2, 1026, 1027, 9, 3, 2, 2, 1027, 1028, 9, 10, 2, 2, 1028, 1029,
+	9, 11, 2, 2, 1029, 200, 3, 2, 2, 2, 1030, 1031, 9, 6, 2, 2, 1031, 1032,
+	9, 11, 2, 2, 1032, 1033, 9, 21, 2, 2, 1033, 1034, 9, 4, 2, 2, 1034, 1035,
+	9, 3, 2, 2, 1035, 202, 3, 2, 2, 2, 1036, 1037, 9, 5, 2, 2, 1037, 1038,
+	9, 9, 2, 2, 1038, 1039, 9, 10, 2, 2, 1039, 1040, 9, 10, 2, 2, 1040, 1041,
+	9, 3, 2, 2, 1041, 1042, 9, 13, 2, 2, 1042, 1043, 9, 6, 2, 2, 1043, 204,
+	3, 2, 2, 2, 1044, 1045, 9, 22, 2, 2, 1045, 1046, 9, 15, 2, 2, 1046, 1047,
+	9, 3, 2, 2, 1047, 1048, 9, 16, 2, 2, 1048, 206, 3, 2, 2, 2, 1049, 1050,
+	9, 8, 2, 2, 1050, 1051, 9, 3, 2, 2, 1051, 1052, 9, 20, 2, 2, 1052, 1053,
+	9, 4, 2, 2, 1053, 1054, 9, 11, 2, 2, 1054, 1055, 9, 5, 2, 2, 1055, 1056,
+	9, 3, 2, 2, 1056, 208, 3, 2, 2, 2, 1057, 1058, 9, 15, 2, 2, 1058, 1059,
+	9, 13, 2, 2, 1059, 1060, 9, 2, 2, 2, 1060, 1061, 9, 3, 2, 2, 1061, 1062,
+	9, 8, 2, 2, 1062, 1063, 9, 6, 2, 2, 1063, 210, 3, 2, 2, 2, 1064, 1065,
+	9, 12, 2, 2, 1065, 1066, 9, 3, 2, 2, 1066, 1067, 9, 4, 2, 2, 1067, 1068,
+	9, 3, 2, 2, 1068, 1069, 9, 6, 2, 2, 1069, 1070, 9, 3, 2, 2, 1070, 212,
+	3, 2, 2, 2, 1071, 1072, 9, 15, 2, 2, 1072, 1073, 9, 13, 2, 2, 1073, 1074,
+	9, 6, 2, 2, 1074, 1075, 9, 9, 2, 2, 1075, 214, 3, 2, 2, 2, 1076, 1077,
+	9, 5, 2, 2, 1077, 1078, 9, 9, 2, 2, 1078, 1079, 9, 13, 2, 2, 1079, 1080,
+	9, 2, 2, 2, 1080, 1081, 9, 6, 2, 2, 1081, 1082, 9, 8, 2, 2, 1082, 1083,
+	9, 11, 2, 2, 1083, 1084, 9, 15, 2, 2, 1084, 1085, 9, 13, 2, 2, 1085, 1086,
+	9, 6, 2, 2, 1086, 216, 3, 2, 2, 2, 1087, 1088, 9, 12, 2, 2, 1088, 1089,
+	9, 3, 2, 2, 1089, 1090, 9, 2, 2, 2, 1090, 1091, 9, 5, 2, 2, 1091, 1092,
+	9, 8, 2, 2, 1092, 1093, 9, 15, 2, 2, 1093, 1094, 9, 21, 2, 2, 1094, 1095,
+	9, 3, 2, 2, 1095, 218, 3, 2, 2, 2, 1096, 1097, 9, 18, 2, 2, 1097, 1098,
+	9, 8, 2, 2, 1098, 1099, 9, 11, 2, 2, 1099, 1100, 9, 13, 2, 2, 1100, 1101,
+	9, 6, 2, 2, 1101, 220, 3, 2, 2, 2, 1102, 1103, 9, 8, 2, 2, 1103, 1104,
+	9, 3, 2, 2, 1104, 1105, 9, 22, 2, 2, 1105, 1106, 9, 9, 2, 2, 1106, 1107,
+	9, 24, 2, 2, 1107, 1108, 9, 3, 2, 2, 1108, 222, 3, 2, 2, 2, 1109, 1110,
+	9, 20, 2, 2, 1110, 1111, 9, 8, 2, 2, 1111, 1112, 9, 15, 2, 2, 1112, 1113,
+	9, 22, 2, 2, 1113, 1114, 9, 15, 2, 2, 1114, 1115, 9, 4, 2, 2, 1115, 1116,
+	9, 3, 2, 2, 1116, 1117, 9, 18, 2, 2, 1117, 1118, 9, 3, 2, 2, 1118, 1119,
+	9, 2, 2, 2, 1119, 224, 3, 2, 2, 2, 1120, 1121, 9, 20, 2, 2, 1121, 1122,
+	9, 19, 2, 2, 1122, 1123, 9, 21, 2, 2, 1123, 1124, 9, 4, 2, 2, 1124, 1125,
+	9, 15, 2, 2, 1125, 1126, 9, 5, 2, 2, 1126, 226, 3, 2, 2, 2, 1127, 1128,
+	9, 9, 2, 2, 1128, 1129, 9, 20, 2, 2, 1129, 1130, 9, 6, 2, 2, 1130, 1131,
+	9, 15, 2, 2, 1131, 1132, 9, 9, 2, 2, 1132, 1133, 9, 13, 2, 2, 1133, 228,
+	3, 2, 2, 2, 1134, 1135, 9, 3, 2, 2, 1135, 1136, 9, 23, 2, 2, 1136, 1137,
+	9, 20, 2, 2, 1137, 1138, 9, 4, 2, 2, 1138, 1139, 9, 11, 2, 2, 1139, 1140,
+	9, 15, 2, 2, 1140, 1141, 9, 13, 2, 2, 1141, 230, 3, 2, 2, 2, 1142, 1143,
+	9, 11, 2, 2, 1143, 1144, 9, 13, 2, 2, 1144, 1145, 9, 11, 2, 2, 1145, 1146,
+	9, 4, 2, 2, 1146, 1147, 9, 14, 2, 2, 1147, 1148, 9, 25, 2, 2, 1148, 1149,
+	9, 3, 2, 2, 1149, 232, 3, 2, 2, 2, 1150, 1151, 9, 7, 2, 2, 1151, 1152,
+	9, 9, 2, 2, 1152, 1153, 9, 8, 2, 2, 1153, 1154, 9, 10, 2, 2, 1154, 1155,
+	9, 11, 2, 2, 1155, 1156, 9, 6, 2, 2, 1156, 234, 3, 2, 2, 2, 1157, 1158,
+	9, 6, 2, 2, 1158, 1159, 9, 14, 2, 2, 1159, 1160, 9, 20, 2, 2, 1160, 1161,
+	9, 3, 2, 2, 1161, 236, 3, 2, 2, 2, 1162, 1163, 9, 6, 2, 2, 1163, 1164,
+	9, 3, 2, 2, 1164, 1165, 9, 23, 2, 2, 1165, 1166, 9, 6, 2, 2, 1166, 238,
+	3, 2, 2, 2, 1167, 1168, 9, 18, 2, 2, 1168, 1169, 9, 8, 2, 2, 1169, 1170,
+	9, 11, 2, 2, 1170, 1171, 9, 20, 2, 2, 1171, 1172, 9, 17, 2, 2, 1172, 1173,
+	9, 22, 2, 2, 1173, 1174, 9, 15, 2, 2, 1174, 1175, 9, 25, 2, 2, 1175, 240,
+	3, 2, 2, 2, 1176, 1177, 9, 4, 2, 2, 1177, 1178, 9, 9, 2, 2, 1178, 1179,
+	9, 18, 2, 2, 1179, 1180, 9, 15, 2, 2, 1180, 1181, 9, 5, 2, 2, 1181, 1182,
+	9, 11, 2, 2, 1182, 1183, 9, 4, 2, 2, 1183, 242, 3, 2, 2, 2, 1184, 1185,
+	9, 12, 2, 2, 1185, 1186, 9, 15, 2, 2, 1186, 1187, 9, 2, 2, 2, 1187, 1188,
+	9, 6, 2, 2, 1188, 1189, 9, 8, 2, 2, 1189, 1190, 9, 15, 2, 2, 1190, 1191,
+	9, 21, 2, 2, 1191, 1192, 9, 19, 2, 2, 1192, 1193, 9, 6, 2, 2, 1193, 1194,
+	9, 3, 2, 2, 1194, 1195, 9, 12, 2, 2, 1195, 244, 3, 2, 2, 2, 1196, 1197,
+	9, 22, 2, 2, 1197, 1198, 9, 11, 2, 2, 1198, 1199, 9, 4, 2, 2, 1199, 1200,
+	9, 15, 2, 2, 1200, 1201, 9, 12, 2, 2, 1201, 1202, 9, 11, 2, 2, 1202, 1203,
+	9, 6, 2, 2, 1203, 1204, 9, 3, 2, 2, 1204, 246, 3, 2, 2, 2, 1205, 1206,
+	9, 5, 2, 2, 1206, 1207, 9, 11, 2, 2, 1207, 1208, 9, 2, 2, 2, 1208, 1209,
+	9, 6, 2, 2, 1209, 248, 3, 2, 2, 2, 1210, 1211, 9, 6, 2, 2, 1211, 1212,
+	9, 8, 2, 2, 1212, 1213, 9, 14, 2, 2, 1213, 1214, 7, 97, 2, 2, 1214, 1215,
+	9, 5, 2, 2, 1215, 1216, 9, 11, 2, 2, 1216, 1217, 9, 2, 2, 2, 1217, 1218,
+	9, 6, 2, 2, 1218, 250, 3, 2, 2, 2, 1219, 1220, 9, 2, 2, 2, 1220, 1221,
+	9, 17, 2, 2, 1221, 1222, 9, 9, 2, 2, 1222, 1223, 9, 16, 2, 2, 1223, 252,
+	3, 2, 2, 2, 1224, 1225, 9, 6, 2, 2, 1225, 1226, 9, 11, 2, 2, 1226, 1227,
+	9, 21, 2, 2, 1227, 1228, 9, 4, 2, 2, 1228, 1229, 9, 3, 2, 2, 1229, 1230,
+	9, 2, 2, 2, 1230, 254, 3, 2, 2, 2, 1231, 1232, 9, 2, 2, 2, 1232, 1233,
+	9, 5, 2, 2, 1233, 1234, 9, 17, 2, 2, 1234, 1235, 9, 3, 2, 2, 1235, 1236,
+	9, 10, 2, 2, 1236, 1237, 9, 11, 2, 2, 1237, 1238, 9, 2, 2, 2, 1238, 256,
+	3, 2, 2, 2, 1239, 1240, 9, 5, 2, 2, 1240, 1241, 9, 11, 2, 2, 1241, 1242,
+	9, 6, 2, 2, 1242, 1243, 9, 11, 2, 2, 1243, 1244, 9, 4, 2, 2, 1244, 1245,
+	9, 9, 2, 2, 1245, 1246, 9, 18, 2, 2, 1246, 1247, 9, 2, 2, 2, 1247, 258,
+	3, 2, 2, 2, 1248, 1249, 9, 5, 2, 2, 1249, 1250, 9, 9, 2, 2, 1250, 1251,
+	9, 4, 2, 2, 1251, 1252, 9, 19, 2, 2, 1252, 1253, 9, 10, 2, 2, 1253, 1254,
+	9, 13, 2, 2, 1254, 1255, 9, 2, 2, 2, 1255, 260, 3, 2, 2, 2, 1256, 1257,
+	9, 5, 2, 2, 1257, 1258, 9, 9, 2, 2, 1258, 1259, 9, 4, 2, 2, 1259, 1260,
+	9, 19, 2, 2, 1260, 1261, 9, 10, 2, 2, 1261, 1262, 9, 13, 2, 2, 1262, 262,
+	3, 2, 2, 2, 1263, 1264, 9, 19, 2, 2, 1264, 1265, 9, 2, 2, 2, 1265, 1266,
+	9, 3, 2, 2, 1266, 264, 3, 2, 2, 2, 1267, 1268, 9, 20, 2, 2, 1268, 1269,
+	9, 11, 2, 2, 1269, 1270, 9, 8, 2, 2, 1270, 1271, 9, 6, 2, 2, 1271, 1272,
+	9, 15, 2, 2, 1272, 1273, 9, 6, 2, 2, 1273, 1274, 9, 15, 2, 2, 1274, 1275,
+	9, 9, 2, 2, 1275, 1276, 9, 13, 2, 2, 1276, 1277, 9, 2, 2, 2, 1277, 266,
+	3, 2, 2, 2, 1278, 1279, 9, 7, 2, 2, 1279, 1280, 9, 19, 2, 2, 1280, 1281,
+	9, 13, 2, 2, 1281, 1282, 9, 5, 2, 2, 1282, 1283, 9, 6, 2, 2, 1283, 1284,
+	9, 15, 2, 2, 1284, 1285, 9, 9, 2, 2, 1285, 1286, 9, 13, 2, 2, 1286, 1287,
+	9, 2, 2, 2, 1287, 268, 3, 2, 2, 2, 1288, 1289, 9, 12, 2, 2, 1289, 1290,
+	9, 8, 2, 2, 1290, 1291, 9, 9, 2, 2, 1291, 1292, 9, 20, 2, 2, 1292, 270,
+	3, 2, 2, 2, 1293, 1294, 9, 19, 2, 2, 1294, 1295, 9, 13, 2, 2, 1295, 1296,
+	9, 15, 2, 2, 1296, 1297, 9, 9, 2, 2, 1297, 1298, 9, 13, 2, 2, 1298, 272,
+	3, 2, 2, 2, 1299, 1300, 9, 3, 2, 2, 1300, 1301, 9, 23, 2, 2, 1301, 1302,
+	9, 5, 2, 2, 1302, 1303, 9, 3, 2, 2, 1303, 1304, 9, 20, 2, 2, 1304, 1305,
+	9, 6, 2, 2, 1305, 274, 3, 2, 2, 2, 1306, 1307, 9, 15, 2, 2, 1307, 1308,
+	9, 13, 2, 2, 1308, 1309, 9, 6, 2, 2, 1309, 1310, 9, 3, 2, 2, 1310, 1311,
+	9, 8, 2, 2, 1311, 1312, 9, 2, 2, 2, 1312, 1313, 9, 3, 2, 2, 1313, 1314,
+	9, 5, 2, 2, 1314, 1315, 9, 6, 2, 2, 1315, 276, 3, 2, 2, 2, 1316, 1317,
+	9, 6, 2, 2, 1317, 1318, 9, 9, 2, 2, 1318, 278, 3, 2, 2, 2, 1319, 1320,
+	9, 2, 2, 2, 1320, 1321, 9, 14, 2, 2, 1321, 1322, 9, 2, 2, 2, 1322, 1323,
+	9, 6, 2, 2, 1323, 1324, 9, 3, 2, 2, 1324, 1325, 9, 10, 2, 2, 1325, 280,
+	3, 2, 2, 2, 1326, 1327, 9, 21, 2, 2, 1327, 1328, 9, 3, 2, 2, 1328, 1329,
+	9, 8, 2, 2, 1329, 1330, 9, 13, 2, 2, 1330, 1331, 9, 9, 2, 2, 1331, 1332,
+	9, 19, 2, 2, 1332, 1333, 9, 4, 2, 2, 1333, 1334, 9, 4, 2, 2, 1334, 1335,
+	9, 15, 2, 2, 1335, 282, 3, 2, 2, 2, 1336, 1337, 9, 20, 2, 2, 1337, 1338,
+	9, 9, 2, 2, 1338, 1339, 9, 15, 2, 2, 1339, 1340, 9, 2, 2, 2, 1340, 1341,
+	9, 2, 2, 2, 1341, 1342, 9, 9, 2, 2, 1342, 1343, 9, 13, 2, 2, 1343, 1344,
+	9, 15, 2, 2, 1344, 1345, 9, 25, 2, 2, 1345, 1346, 9, 3, 2, 2, 1346, 1347,
+	9, 12, 2, 2, 1347, 284, 3, 2, 2, 2, 1348, 1349, 9, 6, 2, 2, 1349, 1350,
+	9, 11, 2, 2, 1350, 1351, 9, 21, 2, 2, 1351, 1352, 9, 4, 2, 2, 1352, 1353,
+	9, 3, 2, 2, 1353, 1354, 9, 2, 2, 2, 1354, 1355, 9, 11, 2, 2, 1355, 1356,
+	9, 10, 2, 2, 1356, 1357, 9, 20, 2, 2, 1357, 1358, 9, 4, 2, 2, 1358, 1359,
+	9, 3, 2, 2, 1359, 286, 3, 2, 2, 2, 1360, 1361, 9, 11, 2, 2, 1361, 1362,
+	9, 4, 2, 2, 1362, 1363, 9, 6, 2, 2, 1363, 1364, 9, 3, 2, 2, 1364, 1365,
+	9, 8, 2, 2, 1365, 288, 3, 2, 2, 2, 1366, 1367, 9, 8, 2, 2, 1367, 1368,
+	9, 3, 2, 2, 1368, 1369, 9, 13, 2, 2, 1369, 1370, 9, 11, 2, 2, 1370, 1371,
+	9, 10, 2, 2, 1371, 1372, 9, 3, 2, 2, 1372, 290, 3, 2, 2, 2, 1373, 1374,
+	9, 19, 2, 2, 1374, 1375, 9, 13, 2, 2, 1375, 1376, 9, 13, 2, 2, 1376, 1377,
+	9, 3, 2, 2, 1377, 1378, 9, 2, 2, 2, 1378, 1379, 9, 6, 2, 2, 1379, 292,
+	3, 2, 2, 2, 1380, 1381, 9, 9, 2, 2, 1381, 1382, 9, 8, 2, 2, 1382, 1383,
+	9, 12, 2, 2, 1383, 1384, 9, 15, 2, 2, 1384, 1385, 9, 13, 2, 2, 1385, 1386,
+	9, 11, 2, 2, 1386, 1387, 9, 4, 2, 2, 1387, 1388, 9, 15, 2, 2, 1388, 1389,
+	9, 6, 2, 2, 1389, 1390, 9, 14, 2, 2, 1390, 294, 3, 2, 2, 2, 1391, 1392,
+	9, 11, 2, 2, 1392, 1393, 9, 8, 2, 2, 1393, 1394, 9, 8, 2, 2, 1394, 1395,
+	9, 11, 2, 2, 1395, 1396, 9, 14, 2, 2, 1396, 296, 3, 2, 2, 2, 1397, 1398,
+	9, 10, 2, 2, 1398, 1399, 9, 11, 2, 2, 1399, 1400, 9, 20, 2, 2, 1400, 298,
+	3, 2, 2, 2, 1401, 1402, 9, 2, 2, 2, 1402, 1403, 9, 3, 2, 2, 1403, 1404,
+	9, 6, 2, 2, 1404, 300, 3, 2, 2, 2, 1405, 1406, 9, 8, 2, 2, 1406, 1407,
+	9, 3, 2, 2, 1407, 1408, 9, 2, 2, 2, 1408, 1409, 9, 3, 2, 2, 1409, 1410,
+	9, 6, 2, 2, 1410, 302, 3, 2, 2, 2, 1411, 1412, 9, 2, 2, 2, 1412, 1413,
+	9, 3, 2, 2, 1413, 1414, 9, 2, 2, 2, 1414, 1415, 9, 2, 2, 2, 1415, 1416,
+	9, 15, 2, 2, 1416, 1417, 9, 9, 2, 2, 1417, 1418, 9, 13, 2, 2, 1418, 304,
+	3, 2, 2, 2, 1419, 1420, 9, 12, 2, 2, 1420, 1421, 9, 11, 2, 2, 1421, 1422,
+	9, 6, 2, 2, 1422, 1423, 9, 11, 2, 2, 1423, 306, 3, 2, 2, 2, 1424, 1425,
+	9, 2, 2, 2, 1425, 1426, 9, 6, 2, 2, 1426, 1427, 9, 11, 2, 2, 1427, 1428,
+	9, 8, 2, 2, 1428, 1429, 9, 6, 2, 2, 1429, 308, 3, 2, 2, 2, 1430, 1431,
+	9, 6, 2, 2, 1431, 1432, 9, 8, 2, 2, 1432, 1433, 9, 11, 2, 2, 1433, 1434,
+	9, 13, 2, 2, 1434, 1435, 9, 2, 2, 2, 1435, 1436, 9, 11, 2, 2, 1436, 1437,
+	9, 5, 2, 2, 1437, 1438, 9, 6, 2, 2, 1438, 1439, 9, 15, 2, 2, 1439, 1440,
+	9, 9, 2, 2, 1440, 1441, 9, 13, 2, 2, 1441, 310, 3, 2, 2, 2, 1442, 1443,
+	9, 5, 2, 2, 1443, 1444, 9, 9, 2, 2, 1444, 1445, 9, 10, 2, 2, 1445, 1446,
+	9, 10, 2, 2, 1446, 1447, 9, 15, 2, 2, 1447, 1448, 9, 6, 2, 2, 1448, 312,
+	3, 2, 2, 2, 1449, 1450, 9, 8, 2, 2, 1450, 1451, 9, 9, 2, 2, 1451, 1452,
+	9, 4, 2, 2, 1452, 1453, 9, 4, 2, 2, 1453, 1454, 9, 21, 2, 2, 1454, 1455,
+	9, 11, 2, 2, 1455, 1456, 9, 5, 2, 2, 1456, 1457, 9, 24, 2, 2, 1457, 314,
+	3, 2, 2, 2, 1458, 1459, 9, 16, 2, 2, 1459, 1460, 9, 9, 2, 2, 1460, 1461,
+	9, 8, 2, 2, 1461, 1462, 9, 24, 2, 2, 1462, 316, 3, 2, 2, 2, 1463, 1464,
+	9, 15, 2, 2, 1464, 1465, 9, 2, 2, 2, 1465, 1466, 9, 9, 2, 2, 1466, 1467,
+	9, 4, 2, 2, 1467, 1468, 9, 11, 2, 2, 1468, 1469, 9, 6, 2, 2, 1469, 1470,
+	9, 15, 2, 2, 1470, 1471, 9, 9, 2, 2, 1471, 1472, 9, 13, 2, 2, 1472, 318,
+	3, 2, 2, 2, 1473, 1474, 9, 4, 2, 2, 1474, 1475, 9, 3, 2, 2, 1475, 1476,
+	9, 22, 2, 2, 1476, 1477, 9, 3, 2, 2, 1477, 1478, 9, 4, 2, 2, 1478, 320,
+	3, 2, 2, 2, 1479, 1480, 9, 2, 2, 2, 1480, 1481, 9, 3, 2, 2, 1481, 1482,
+	9, 8, 2, 2, 1482, 1483, 9, 15, 2, 2, 1483, 1484, 9, 11, 2, 2, 1484, 1485,
+	9, 4, 2, 2, 1485, 1486, 9, 15, 2, 2, 1486, 1487, 9, 25, 2, 2, 1487, 1488,
+	9, 11, 2, 2, 1488, 1489, 9, 21, 2, 2, 1489, 1490, 9, 4, 2, 2, 1490, 1491,
+	9, 3, 2, 2, 1491, 322, 3, 2, 2, 2, 1492, 1493, 9, 8, 2, 2, 1493, 1494,
+	9, 3, 2, 2, 1494, 1495, 9, 20, 2, 2, 1495, 1496, 9, 3, 2, 2, 1496, 1497,
+	9, 11, 2, 2, 1497, 1498, 9, 6, 2, 2, 1498, 1499, 9, 11, 2, 2, 1499, 1500,
+	9, 21, 2, 2, 1500, 1501, 9, 4, 2, 2, 1501, 1502, 9, 3, 2, 2, 1502, 324,
+	3, 2, 2, 2, 1503, 1504, 9, 5, 2, 2, 1504, 1505, 9, 9, 2, 2, 1505, 1506,
+	9, 10, 2, 2, 1506, 1507, 9, 10, 2, 2, 1507, 1508, 9, 15, 2, 2, 1508, 1509,
+	9, 6, 2, 2, 1509, 1510, 9, 6, 2, 2, 1510, 1511, 9, 3, 2, 2, 1511, 1512,
+	9, 12, 2, 2, 1512, 326, 3, 2, 2, 2, 1513, 1514, 9, 19, 2, 2, 1514, 1515,
+	9, 13, 2, 2, 1515, 1516, 9, 5, 2, 2, 1516, 1517, 9, 9, 2, 2, 1517, 1518,
+	9, 10, 2, 2, 1518, 1519, 9, 10, 2, 2, 1519, 1520, 9, 15, 2, 2, 1520, 1521,
+	9, 6, 2, 2, 1521, 1522, 9, 6, 2, 2, 1522, 1523, 9, 3, 2, 2, 1523, 1524,
+	9, 12, 2, 2, 1524, 328, 3, 2, 2, 2, 1525, 1526, 9, 8, 2, 2, 1526, 1527,
+	9, 3, 2, 2, 1527, 1528, 9, 11, 2, 2, 1528, 1529, 9, 12, 2, 2, 1529, 330,
+	3, 2, 2, 2, 1530, 1531, 9, 16, 2, 2, 1531, 1532, 9, 8, 2, 2, 1532, 1533,
+	9, 15, 2, 2, 1533, 1534, 9, 6, 2, 2, 1534, 1535, 9, 3, 2, 2, 1535, 332,
+	3, 2, 2, 2, 1536, 1537, 9, 9, 2, 2, 1537, 1538, 9, 13, 2, 2, 1538, 1539,
+	9, 4, 2, 2, 1539, 1540, 9, 14, 2, 2, 1540, 334, 3, 2, 2, 2, 1541, 1542,
+	9, 5, 2, 2, 1542, 1543, 9, 11, 2, 2, 1543, 1544, 9, 4, 2, 2, 1544, 1545,
+	9, 4, 2, 2, 1545, 336, 3, 2, 2, 2, 1546, 1547, 9, 20, 2, 2, 1547, 1548,
+	9, 8, 2, 2, 1548, 1549, 9, 3, 2, 2, 1549, 1550, 9, 20, 2, 2, 1550, 1551,
+	9, 11, 2, 2, 1551, 1552, 9, 8, 2, 2, 1552, 1553, 9, 3, 2, 2, 1553, 338,
+	3, 2, 2, 2, 1554, 1555, 9, 12, 2, 2, 1555, 1556, 9, 3, 2, 2, 1556, 1557,
+	9, 11, 2, 2, 1557, 1558, 9, 4, 2, 2, 1558, 1559, 9, 4, 2, 2, 1559, 1560,
+	9, 9, 2, 2, 1560, 1561, 9, 5, 2, 2, 1561, 1562, 9, 11, 2, 2, 1562, 1563,
+	9, 6, 2, 2, 1563, 1564, 9, 3, 2, 2, 1564, 340, 3, 2, 2, 2, 1565, 1566,
+	9, 3, 2, 2, 1566, 1567, 9, 23, 2, 2, 1567, 1568, 9, 3, 2, 2, 1568, 1569,
+	9, 5, 2, 2, 1569, 1570, 9, 19, 2, 2, 1570, 1571, 9, 6, 2, 2, 1571, 1572,
+	9, 3, 2, 2, 1572, 342, 3, 2, 2, 2, 1573, 1574, 9, 15, 2, 2, 1574, 1575,
+	9, 13, 2, 2, 1575, 1576, 9, 20, 2, 2, 1576, 1577, 9, 19, 2, 2, 1577, 1578,
+	9, 6, 2, 2, 1578, 344, 3, 2, 2, 2, 1579, 1580, 9, 9, 2, 2, 1580, 1581,
+	9, 19, 2, 2, 1581, 1582, 9, 6, 2, 2, 1582, 1583, 9, 20, 2, 2, 1583, 1584,
+	9, 19, 2, 2, 1584, 1585, 9, 6, 2, 2, 1585, 346, 3, 2, 2, 2, 1586, 1587,
+	9, 5, 2, 2, 1587, 1588, 9, 11, 2, 2, 1588, 1589, 9, 2, 2, 2, 1589, 1590,
+	9, 5, 2, 2, 1590, 1591, 9, 11, 2, 2, 1591, 1592, 9, 12, 2, 2, 1592, 1593,
+	9, 3, 2, 2, 1593, 348, 3, 2, 2, 2, 1594, 1595, 9, 8, 2, 2, 1595, 1596,
+	9, 3, 2, 2, 1596, 1597, 9, 2, 2, 2, 1597, 1598, 9, 6, 2, 2, 1598, 1599,
+	9, 8, 2, 2, 1599, 1600, 9, 15, 2, 2, 1600, 1601, 9, 5, 2, 2, 1601, 1602,
+	9, 6, 2, 2, 1602, 350, 3, 2, 2, 2, 1603, 1604, 9, 15, 2, 2, 1604, 1605,
+	9, 13, 2, 2, 1605, 1606, 9, 5, 2, 2, 1606, 1607, 9, 4, 2, 2, 1607, 1608,
+	9, 19, 2, 2, 1608, 1609, 9, 12, 2, 2, 1609, 1610, 9, 15, 2, 2, 1610, 1611,
+	9, 13, 2, 2, 1611, 1612, 9, 18, 2, 2, 1612, 352, 3, 2, 2, 2, 1613, 1614,
+	9, 3, 2, 2, 1614, 1615, 9, 23, 2, 2, 1615, 1616, 9, 5, 2, 2, 1616, 1617,
+	9, 4, 2, 2, 1617, 1618, 9, 19, 2, 2, 1618, 1619, 9, 12, 2, 2, 1619, 1620,
+	9, 15, 2, 2, 1620, 1621, 9, 13, 2, 2, 1621, 1622, 9, 18, 2, 2, 1622, 354,
+	3, 2, 2, 2, 1623, 1624, 9, 20, 2, 2, 1624, 1625, 9, 8, 2, 2, 1625, 1626,
+	9, 9, 2, 2, 1626, 1627, 9, 20, 2, 2, 1627, 1628, 9, 3, 2, 2, 1628, 1629,
+	9, 8, 2, 2, 1629, 1630, 9, 6, 2, 2, 1630, 1631, 9, 15, 2, 2, 1631, 1632,
+	9, 3, 2, 2, 1632, 1633, 9, 2, 2, 2, 1633, 356, 3, 2, 2, 2, 1634, 1635,
+	9, 13, 2, 2, 1635, 1636, 9, 9, 2, 2, 1636, 1637, 9, 8, 2, 2, 1637, 1638,
+	9, 10, 2, 2, 1638, 1639, 9, 11, 2, 2, 1639, 1640, 9, 4, 2, 2, 1640, 1641,
+	9, 15, 2, 2, 1641, 1642, 9, 25, 2, 2, 1642, 1643, 9, 3, 2, 2, 1643, 358,
+	3, 2, 2, 2, 1644, 1645, 9, 13, 2, 2, 1645, 1646, 9, 7, 2, 2, 1646, 1647,
+	9, 12, 2, 2, 1647, 360, 3, 2, 2, 2, 1648, 1649, 9, 13, 2, 2, 1649, 1650,
+	9, 7, 2, 2, 1650, 1651, 9, 5, 2, 2, 1651, 362, 3, 2, 2, 2, 1652, 1653,
+	9, 13, 2, 2, 1653, 1654, 9, 7, 2, 2, 1654, 1655, 9, 24, 2, 2, 1655, 1656,
+	9, 12, 2, 2, 1656, 364, 3, 2, 2, 2, 1657, 1658, 9, 13, 2, 2, 1658, 1659,
+	9, 7, 2, 2, 1659, 1660, 9, 24, 2, 2, 1660, 1661, 9, 5, 2, 2, 1661, 366,
+	3, 2, 2, 2, 1662, 1663, 9, 15, 2, 2, 1663, 1664, 9, 7, 2, 2, 1664, 368,
+	3, 2, 2, 2, 1665, 1666, 9, 13, 2, 2, 1666, 1667, 9, 19, 2, 2, 1667, 1668,
+	9, 4, 2, 2, 1668, 1669, 9, 4, 2, 2, 1669, 1670, 9, 15, 2, 2, 1670, 1671,
+	9, 7, 2, 2, 1671, 370, 3, 2, 2, 2, 1672, 1673, 9, 5, 2, 2, 1673, 1674,
+	9, 9, 2, 2, 1674, 1675, 9, 11, 2, 2, 1675, 1676, 9, 4, 2, 2, 1676, 1677,
+	9, 3, 2, 2, 1677, 1678, 9, 2, 2, 2, 1678, 1679, 9, 5, 2, 2, 1679, 1680,
+	9, 3, 2, 2, 1680, 372, 3, 2, 2, 2, 1681, 1682, 9, 6, 2, 2, 1682, 1683,
+	9, 15, 2, 2, 1683, 1684, 9, 10, 2, 2, 1684, 1685, 9, 3, 2, 2, 1685, 1686,
+	5, 431, 216, 2, 1686, 1687, 9, 16, 2, 2, 1687, 1688, 9, 15, 2, 2, 1688,
+	1689, 9, 6, 2, 2, 1689, 1690, 9, 17, 2, 2, 1690, 1691, 5, 431, 216, 2,
+	1691, 1692, 9, 6, 2, 2, 1692, 1693, 9, 15, 2, 2, 1693, 1694, 9, 10, 2,
+	2, 1694, 1695, 9, 3, 2, 2, 1695, 1696, 5, 431, 216, 2, 1696, 1697, 9, 25,
+	2, 2, 1697, 1698, 9, 9, 2, 2, 1698, 1699, 9, 13, 2, 2, 1699, 1700, 9, 3,
+	2, 2, 1700, 374, 3, 2, 2, 2, 1701, 1702, 9, 6, 2, 2, 1702, 1703, 9, 15,
+	2, 2, 1703, 1704, 9, 10, 2, 2, 1704, 1705, 9, 3, 2, 2, 1705, 1706, 9, 2,
+	2, 2, 1706, 1707, 9, 6, 2, 2, 1707, 1708, 9, 11, 2, 2, 1708, 1709, 9, 10,
+	2, 2, 1709, 1710, 9, 20, 2, 2, 1710, 1711, 5, 431, 216, 2, 1711, 1712,
+	9, 16, 2, 2, 1712, 1713, 9, 15, 2, 2, 1713, 1714, 9, 6, 2, 2, 1714, 1715,
+	9, 17, 2, 2, 1715, 1716, 5, 431, 216, 2, 1716, 1717, 9, 6, 2, 2, 1717,
+	1718, 9, 15, 2, 2, 1718, 1719, 9, 10, 2, 2, 1719, 1720, 9, 3, 2, 2, 1720,
+	1721, 5, 431, 216, 2, 1721, 1722, 9, 25, 2, 2, 1722, 1723, 9, 9, 2, 2,
+	1723, 1724, 9, 13, 2, 2, 1724, 1725, 9, 3, 2, 2, 1725, 376, 3, 2, 2, 2,
+	1726, 1727, 9, 12, 2, 2, 1727, 1728, 9, 9, 2, 2, 1728, 1729, 9, 19, 2,
+	2, 1729, 1730, 9, 21, 2, 2, 1730, 1731, 9, 4, 2, 2, 1731, 1732, 9, 3, 2,
+	2, 1732, 1733, 5, 431, 216, 2, 1733, 1734, 9, 20, 2, 2, 1734, 1735, 9,
+	8, 2, 2, 1735, 1736, 9, 3, 2, 2, 1736, 1737, 9, 5, 2, 2, 1737, 1738, 9,
+	15, 2, 2, 1738, 1739, 9, 2, 2, 2, 1739, 1740, 9, 15, 2, 2, 1740, 1741,
+	9, 9, 2, 2, 1741, 1742, 9, 13, 2, 2, 1742, 378, 3, 2, 2, 2, 1743, 1744,
+	7, 63, 2, 2, 1744, 380, 3, 2, 2, 2, 1745, 1746, 7, 62, 2, 2, 1746, 1750,
+	7, 64, 2, 2, 1747, 1748, 7, 35, 2, 2, 1748, 1750, 7, 63, 2, 2, 1749, 1745,
+	3, 2, 2, 2, 1749, 1747, 3, 2, 2, 2, 1750, 382, 3, 2, 2, 2, 1751, 1752,
+	7, 62, 2, 2, 1752, 384, 3, 2, 2, 2, 1753, 1754, 7, 62, 2, 2, 1754, 1755,
+	7, 63, 2, 2, 1755, 386, 3, 2, 2, 2, 1756, 1757, 7, 64, 2, 2, 1757, 388,
+	3, 2, 2, 2, 1758, 1759, 7, 64, 2, 2, 1759, 1760, 7, 63, 2, 2, 1760, 390,
+	3, 2, 2, 2, 1761, 1762, 7, 45, 2, 2, 1762, 392, 3, 2, 2, 2, 1763, 1764,
+	7, 47, 2, 2, 1764, 394, 3, 2, 2, 2, 1765, 1766, 7, 44, 2, 2, 1766, 396,
+	3, 2, 2, 2, 1767, 1768, 7, 49, 2, 2, 1768, 398, 3, 2, 2, 2, 1769, 1770,
+	7, 39, 2, 2, 1770, 400, 3, 2, 2, 2, 1771, 1772, 7, 126, 2, 2, 1772, 1773,
+	7, 126, 2, 2, 1773, 402, 3, 2, 2, 2, 1774, 1775, 7, 48, 2, 2, 1775, 404,
+	3, 2, 2, 2, 1776, 1782, 7, 41, 2, 2, 1777, 1781, 10, 27, 2, 2, 1778, 1779,
+	7, 41, 2, 2, 1779, 1781, 7, 41, 2, 2, 1780, 1777, 3, 2, 2, 2, 1780, 1778,
+	3, 2, 2, 2, 1781, 1784, 3, 2, 2, 2, 1782, 1780, 3, 2, 2, 2, 1782, 1783,
+	3, 2, 2, 2, 1783, 1785, 3, 2, 2, 2, 1784, 1782, 3, 2, 2, 2, 1785, 1786,
+	7, 41, 2, 2, 1786, 406, 3, 2, 2, 2, 1787, 1788, 7, 90, 2, 2, 1788, 1789,
+	7, 41, 2, 2, 1789, 1793, 3, 2, 2, 2, 1790, 1792, 10, 27, 2, 2, 1791, 1790,
+	3, 2, 2, 2, 1792, 1795, 3, 2, 2, 2, 1793, 1791, 3, 2, 2, 2, 1793, 1794,
+	3, 2, 2, 2, 1794, 1796, 3, 2, 2, 2, 1795, 1793, 3, 2, 2, 2, 1796, 1797,
+	7, 41, 2, 2, 1797, 408, 3, 2, 2, 2, 1798, 1800, 5, 423, 212, 2, 1799, 1798,
+	3, 2, 2, 2, 1800, 1801, 3, 2, 2, 2, 1801, 1799, 3, 2, 2, 2, 1801, 1802,
+	3, 2, 2, 2, 1802, 410, 3, 2, 2, 2, 1803, 1805, 5, 423, 212, 2, 1804, 1803,
+	3, 2, 2, 2, 1805, 1806, 3, 2, 2, 2, 1806, 1804, 3, 2, 2, 2, 1806, 1807,
+	3, 2, 2, 2, 1807, 1808, 3, 2, 2, 2, 1808, 1812, 7, 48, 2, 2, 1809, 1811,
+	5, 423, 212, 2, 1810, 1809, 3, 2, 2, 2, 1811, 1814, 3, 2, 2, 2, 1812, 1810,
+	3, 2, 2, 2, 1812, 1813, 3, 2, 2, 2, 1813, 1846, 3, 2, 2, 2, 1814, 1812,
+	3, 2, 2, 2, 1815, 1817, 7, 48, 2, 2, 1816, 1818, 5, 423, 212, 2, 1817,
+	1816, 3, 2, 2, 2, 1818, 1819, 3, 2, 2, 2, 1819, 1817, 3, 2, 2, 2, 1819,
+	1820, 3, 2, 2, 2, 1820, 1846, 3, 2, 2, 2, 1821, 1823, 5, 423, 212, 2, 1822,
+	1821, 3, 2, 2, 2, 1823, 1824, 3, 2, 2, 2, 1824, 1822, 3, 2, 2, 2, 1824,
+	1825, 3, 2, 2, 2, 1825, 1833, 3, 2, 2, 2, 1826, 1830, 7, 48, 2, 2, 1827,
+	1829, 5, 423, 212, 2, 1828, 1827, 3, 2, 2, 2, 1829, 1832, 3, 2, 2, 2, 1830,
+	1828, 3, 2, 2, 2, 1830, 1831, 3, 2, 2, 2, 1831, 1834, 3, 2, 2, 2, 1832,
+	1830, 3, 2, 2, 2, 1833, 1826, 3, 2, 2, 2, 1833, 1834, 3, 2, 2, 2, 1834,
+	1835, 3, 2, 2, 2, 1835, 1836, 5, 421, 211, 2, 1836, 1846, 3, 2, 2, 2, 1837,
+	1839, 7, 48, 2, 2, 1838, 1840, 5, 423, 212, 2, 1839, 1838, 3, 2, 2, 2,
+	1840, 1841, 3, 2, 2, 2, 1841, 1839, 3, 2, 2, 2, 1841, 1842, 3, 2, 2, 2,
+	1842, 1843, 3, 2, 2, 2, 1843, 1844, 5, 421, 211, 2, 1844, 1846, 3, 2, 2,
+	2, 1845, 1804, 3, 2, 2, 2, 1845, 1815, 3, 2, 2, 2, 1845, 1822, 3, 2, 2,
+	2, 1845, 1837, 3, 2, 2, 2, 1846, 412, 3, 2, 2, 2, 1847, 1850, 5, 425, 213,
+	2, 1848, 1850, 7, 97, 2, 2, 1849, 1847, 3, 2, 2, 2, 1849, 1848, 3, 2, 2,
+	2, 1850, 1856, 3, 2, 2, 2, 1851, 1855, 5, 425, 213, 2, 1852, 1855, 5, 423,
+	212, 2, 1853, 1855, 9, 28, 2, 2, 1854, 1851, 3, 2, 2, 2, 1854, 1852, 3,
+	2, 2, 2, 1854, 1853, 3, 2, 2, 2, 1855, 1858, 3, 2, 2, 2, 1856, 1854, 3,
+	2, 2, 2, 1856, 1857, 3, 2, 2, 2, 1857, 414, 3, 2, 2, 2, 1858, 1856, 3,
+	2, 2, 2, 1859, 1863, 5, 423, 212, 2, 1860, 1864, 5, 425, 213, 2, 1861,
+	1864, 5, 423, 212, 2, 1862, 1864, 9, 28, 2, 2, 1863, 1860, 3, 2, 2, 2,
+	1863, 1861, 3, 2, 2, 2, 1863, 1862, 3, 2, 2, 2, 1864, 1865, 3, 2, 2, 2,
+	1865, 1863, 3, 2, 2, 2, 1865, 1866, 3, 2, 2, 2, 1866, 416, 3, 2, 2, 2,
+	1867, 1873, 7, 36, 2, 2, 1868, 1872, 10, 29, 2, 2, 1869, 1870, 7, 36, 2,
+	2, 1870, 1872, 7, 36, 2, 2, 1871, 1868, 3, 2, 2, 2, 1871, 1869, 3, 2, 2,
+	2, 1872, 1875, 3, 2, 2, 2, 1873, 1871, 3, 2, 2, 2, 1873, 1874, 3, 2, 2,
+	2, 1874, 1876, 3, 2, 2, 2, 1875, 1873, 3, 2, 2, 2, 1876, 1877, 7, 36, 2,
+	2, 1877, 418, 3, 2, 2, 2, 1878, 1884, 7, 98, 2, 2, 1879, 1883, 10, 30,
+	2, 2, 1880, 1881, 7, 98, 2, 2, 1881, 1883, 7, 98, 2, 2, 1882, 1879, 3,
+	2, 2, 2, 1882, 1880, 3, 2, 2, 2, 1883, 1886, 3, 2, 2, 2, 1884, 1882, 3,
+	2, 2, 2, 1884, 1885, 3, 2, 2, 2, 1885, 1887, 3, 2, 2, 2, 1886, 1884, 3,
+	2, 2, 2, 1887, 1888, 7, 98, 2, 2, 1888, 420, 3, 2, 2, 2, 1889, 1891, 9,
+	3, 2, 2, 1890, 1892, 9, 31, 2, 2, 1891, 1890, 3, 2, 2, 2, 1891, 1892, 3,
+	2, 2, 2, 1892, 1894, 3, 2, 2, 2, 1893, 1895, 5, 423, 212, 2, 1894, 1893,
+	3, 2, 2, 2, 1895, 1896, 3, 2, 2, 2, 1896, 1894, 3, 2, 2, 2, 1896, 1897,
+	3, 2, 2, 2, 1897, 422, 3, 2, 2, 2, 1898, 1899, 9, 32, 2, 2, 1899, 424,
+	3, 2, 2, 2, 1900, 1901, 9, 33, 2, 2, 1901, 426, 3, 2, 2, 2, 1902, 1903,
+	7, 47, 2, 2, 1903, 1904, 7, 47, 2, 2, 1904, 1908, 3, 2, 2, 2, 1905, 1907,
+	10, 34, 2, 2, 1906, 1905, 3, 2, 2, 2, 1907, 1910, 3, 2, 2, 2, 1908, 1906,
+	3, 2, 2, 2, 1908, 1909, 3, 2, 2, 2, 1909, 1912, 3, 2, 2, 2, 1910, 1908,
+	3, 2, 2, 2, 1911, 1913, 7, 15, 2, 2, 1912, 1911, 3, 2, 2, 2, 1912, 1913,
+	3, 2, 2, 2, 1913, 1915, 3, 2, 2, 2, 1914, 1916, 7, 12, 2, 2, 1915, 1914,
+	3, 2, 2, 2, 1915, 1916, 3, 2, 2, 2, 1916, 1917, 3, 2, 2, 2, 1917, 1918,
+	8, 214, 2, 2, 1918, 428, 3, 2, 2, 2, 1919, 1920, 7, 49, 2, 2, 1920, 1921,
+	7, 44, 2, 2, 1921, 1925, 3, 2, 2, 2, 1922, 1924, 11, 2, 2, 2, 1923, 1922,
+	3, 2, 2, 2, 1924, 1927, 3, 2, 2, 2, 1925, 1926, 3, 2, 2, 2, 1925, 1923,
+	3, 2, 2, 2, 1926, 1928, 3, 2, 2, 2, 1927, 1925, 3, 2, 2, 2, 1928, 1929,
+	7, 44, 2, 2, 1929, 1930, 7, 49, 2, 2, 1930, 1931, 3, 2, 2, 2, 1931, 1932,
+	8, 215, 2, 2, 1932, 430, 3, 2, 2, 2, 1933, 1935, 9, 35, 2, 2, 1934, 1933,
+	3, 2, 2, 2, 1935, 1936, 3, 2, 2, 2, 1936, 1934, 3, 2, 2, 2, 1936, 1937,
+	3, 2, 2, 2, 1937, 1938, 3, 2, 2, 2, 1938, 1939, 8, 216, 2, 2, 1939, 432,
+	3, 2, 2, 2, 1940, 1942, 3, 2, 2, 2, 1942, 1943, 9, 9, 2, 2, 1943, 1944,
+	9, 7, 2, 2, 1944, 1945, 9, 7, 2, 2, 1945, 1946, 9, 2, 2, 2, 1946, 1947,
+	9, 3, 2, 2, 1947, 1948, 9, 6, 2, 2, 1948, 1941, 3, 2, 2, 2, 32, 2, 1749,
+	1780, 1782, 1793, 1801, 1806, 1812, 1819, 1824, 1830, 1833, 1841, 1845,
+	1849, 1854, 1856, 1863, 1865, 1871, 1873, 1882, 1884, 1891, 1896, 1908,
+	1912, 1915, 1925, 1936, 3, 2, 3, 2,
 }
 
 var lexerDeserializer = antlr.NewATNDeserializer(nil)
@@ -942,7 +946,7 @@ var lexerSymbolicNames = []string{
 	"EQ", "NEQ", "LT", "LTE", "GT", "GTE", "PLUS", "MINUS", "ASTERISK", "SLASH",
 	"PERCENT", "CONCAT", "DOT", "STRING", "BINARY_LITERAL", "INTEGER_VALUE",
 	"DECIMAL_VALUE", "IDENTIFIER", "DIGIT_IDENTIFIER", "QUOTED_IDENTIFIER",
-	"BACKQUOTED_IDENTIFIER", "SIMPLE_COMMENT", "BRACKETED_COMMENT", "WS",
+	"BACKQUOTED_IDENTIFIER", "SIMPLE_COMMENT", "BRACKETED_COMMENT", "WS", "OFFSET",
 }
 
 var lexerRuleNames = []string{
@@ -975,7 +979,7 @@ var lexerRuleNames = []string{
 	"PERCENT", "CONCAT", "DOT", "STRING", "BINARY_LITERAL", "INTEGER_VALUE",
 	"DECIMAL_VALUE", "IDENTIFIER", "DIGIT_IDENTIFIER", "QUOTED_IDENTIFIER",
 	"BACKQUOTED_IDENTIFIER", "EXPONENT", "DIGIT", "LETTER", "SIMPLE_COMMENT",
-	"BRACKETED_COMMENT", "WS",
+	"BRACKETED_COMMENT", "WS", "OFFSET",
 }
 
 type SQLBaseLexer struct {
@@ -1220,4 +1224,5 @@ const (
 	SQLBaseLexerSIMPLE_COMMENT           = 210
 	SQLBaseLexerBRACKETED_COMMENT        = 211
 	SQLBaseLexerWS                       = 212
+	SQLBaseLexerOFFSET                   = 213
 )
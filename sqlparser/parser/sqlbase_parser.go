@@ -16,7 +16,7 @@ var _ = reflect.Copy
 var _ = strconv.Itoa
 
 var parserATN = []uint16{
-	3, 1072, 54993, 33286, 44333, 17431, 44785, 36224, 43741, 3, 214, 1312,
+	3, 1072, 54993, 33286, 44333, 17431, 44785, 36224, 43741, 3, 215, 1316,
 	4, 2, 9, 2, 4, 3, 9, 3, 4, 4, 9, 4, 4, 5, 9, 5, 4, 6, 9, 6, 4, 7, 9, 7,
 	4, 8, 9, 8, 4, 9, 9, 9, 4, 10, 9, 10, 4, 11, 9, 11, 4, 12, 9, 12, 4, 13,
 	9, 13, 4, 14, 9, 14, 4, 15, 9, 15, 4, 16, 9, 16, 4, 17, 9, 17, 4, 18, 9,
@@ -156,221 +156,223 @@ var parserATN = []uint16{
 	3, 52, 3, 52, 3, 52, 3, 52, 5, 52, 1287, 10, 52, 3, 53, 3, 53, 3, 53, 3,
 	53, 5, 53, 1293, 10, 53, 3, 54, 3, 54, 3, 54, 7, 54, 1298, 10, 54, 12,
 	54, 14, 54, 1301, 11, 54, 3, 55, 3, 55, 3, 55, 3, 55, 3, 55, 5, 55, 1308,
-	10, 55, 3, 56, 3, 56, 3, 56, 2, 8, 22, 44, 60, 66, 68, 80, 57, 2, 4, 6,
-	8, 10, 12, 14, 16, 18, 20, 22, 24, 26, 28, 30, 32, 34, 36, 38, 40, 42,
-	44, 46, 48, 50, 52, 54, 56, 58, 60, 62, 64, 66, 68, 70, 72, 74, 76, 78,
-	80, 82, 84, 86, 88, 90, 92, 94, 96, 98, 100, 102, 104, 106, 108, 110, 2,
-	24, 4, 2, 13, 13, 33, 33, 4, 2, 48, 48, 110, 110, 3, 2, 177, 178, 3, 2,
-	137, 139, 3, 2, 47, 48, 3, 2, 44, 45, 4, 2, 16, 16, 19, 19, 3, 2, 141,
-	143, 3, 2, 31, 32, 3, 2, 41, 42, 3, 2, 197, 198, 3, 2, 197, 201, 3, 2,
-	181, 184, 3, 2, 191, 196, 3, 2, 16, 18, 3, 2, 59, 64, 3, 2, 90, 91, 3,
-	2, 93, 94, 3, 2, 120, 121, 3, 2, 122, 124, 3, 2, 167, 168, 18, 2, 14, 14,
-	16, 18, 30, 30, 35, 35, 49, 50, 52, 65, 87, 91, 93, 96, 101, 101, 103,
-	105, 111, 124, 127, 135, 140, 144, 149, 169, 173, 179, 181, 187, 1514,
-	2, 112, 3, 2, 2, 2, 4, 426, 3, 2, 2, 2, 6, 429, 3, 2, 2, 2, 8, 433, 3,
-	2, 2, 2, 10, 447, 3, 2, 2, 2, 12, 449, 3, 2, 2, 2, 14, 455, 3, 2, 2, 2,
-	16, 461, 3, 2, 2, 2, 18, 472, 3, 2, 2, 2, 20, 476, 3, 2, 2, 2, 22, 493,
-	3, 2, 2, 2, 24, 523, 3, 2, 2, 2, 26, 525, 3, 2, 2, 2, 28, 533, 3, 2, 2,
-	2, 30, 570, 3, 2, 2, 2, 32, 620, 3, 2, 2, 2, 34, 635, 3, 2, 2, 2, 36, 650,
-	3, 2, 2, 2, 38, 652, 3, 2, 2, 2, 40, 661, 3, 2, 2, 2, 42, 675, 3, 2, 2,
-	2, 44, 677, 3, 2, 2, 2, 46, 704, 3, 2, 2, 2, 48, 720, 3, 2, 2, 2, 50, 722,
-	3, 2, 2, 2, 52, 731, 3, 2, 2, 2, 54, 741, 3, 2, 2, 2, 56, 776, 3, 2, 2,
-	2, 58, 780, 3, 2, 2, 2, 60, 789, 3, 2, 2, 2, 62, 799, 3, 2, 2, 2, 64, 862,
-	3, 2, 2, 2, 66, 868, 3, 2, 2, 2, 68, 1097, 3, 2, 2, 2, 70, 1118, 3, 2,
-	2, 2, 72, 1120, 3, 2, 2, 2, 74, 1122, 3, 2, 2, 2, 76, 1124, 3, 2, 2, 2,
-	78, 1134, 3, 2, 2, 2, 80, 1178, 3, 2, 2, 2, 82, 1189, 3, 2, 2, 2, 84, 1196,
-	3, 2, 2, 2, 86, 1198, 3, 2, 2, 2, 88, 1203, 3, 2, 2, 2, 90, 1209, 3, 2,
-	2, 2, 92, 1248, 3, 2, 2, 2, 94, 1257, 3, 2, 2, 2, 96, 1263, 3, 2, 2, 2,
-	98, 1270, 3, 2, 2, 2, 100, 1279, 3, 2, 2, 2, 102, 1286, 3, 2, 2, 2, 104,
-	1292, 3, 2, 2, 2, 106, 1294, 3, 2, 2, 2, 108, 1307, 3, 2, 2, 2, 110, 1309,
-	3, 2, 2, 2, 112, 113, 5, 4, 3, 2, 113, 114, 7, 11, 2, 2, 114, 3, 3, 2,
-	2, 2, 115, 427, 5, 6, 4, 2, 116, 117, 7, 100, 2, 2, 117, 121, 7, 102, 2,
-	2, 118, 119, 7, 185, 2, 2, 119, 120, 7, 34, 2, 2, 120, 122, 7, 36, 2, 2,
-	121, 118, 3, 2, 2, 2, 121, 122, 3, 2, 2, 2, 122, 123, 3, 2, 2, 2, 123,
-	124, 5, 106, 54, 2, 124, 125, 7, 3, 2, 2, 125, 130, 5, 10, 6, 2, 126, 127,
-	7, 4, 2, 2, 127, 129, 5, 10, 6, 2, 128, 126, 3, 2, 2, 2, 129, 132, 3, 2,
-	2, 2, 130, 128, 3, 2, 2, 2, 130, 131, 3, 2, 2, 2, 131, 133, 3, 2, 2, 2,
-	132, 130, 3, 2, 2, 2, 133, 136, 7, 5, 2, 2, 134, 135, 7, 97, 2, 2, 135,
-	137, 5, 16, 9, 2, 136, 134, 3, 2, 2, 2, 136, 137, 3, 2, 2, 2, 137, 427,
-	3, 2, 2, 2, 138, 139, 7, 100, 2, 2, 139, 143, 7, 102, 2, 2, 140, 141, 7,
-	185, 2, 2, 141, 142, 7, 34, 2, 2, 142, 144, 7, 36, 2, 2, 143, 140, 3, 2,
-	2, 2, 143, 144, 3, 2, 2, 2, 144, 145, 3, 2, 2, 2, 145, 148, 5, 106, 54,
-	2, 146, 147, 7, 97, 2, 2, 147, 149, 5, 16, 9, 2, 148, 146, 3, 2, 2, 2,
-	148, 149, 3, 2, 2, 2, 149, 150, 3, 2, 2, 2, 150, 151, 7, 15, 2, 2, 151,
-	152, 5, 6, 4, 2, 152, 427, 3, 2, 2, 2, 153, 154, 7, 136, 2, 2, 154, 157,
-	7, 102, 2, 2, 155, 156, 7, 185, 2, 2, 156, 158, 7, 36, 2, 2, 157, 155,
-	3, 2, 2, 2, 157, 158, 3, 2, 2, 2, 158, 159, 3, 2, 2, 2, 159, 427, 5, 106,
-	54, 2, 160, 161, 7, 106, 2, 2, 161, 162, 7, 108, 2, 2, 162, 164, 5, 106,
-	54, 2, 163, 165, 5, 54, 28, 2, 164, 163, 3, 2, 2, 2, 164, 165, 3, 2, 2,
-	2, 165, 166, 3, 2, 2, 2, 166, 167, 5, 6, 4, 2, 167, 427, 3, 2, 2, 2, 168,
-	169, 7, 107, 2, 2, 169, 170, 7, 13, 2, 2, 170, 173, 5, 106, 54, 2, 171,
-	172, 7, 20, 2, 2, 172, 174, 5, 60, 31, 2, 173, 171, 3, 2, 2, 2, 173, 174,
-	3, 2, 2, 2, 174, 427, 3, 2, 2, 2, 175, 176, 7, 145, 2, 2, 176, 177, 7,
-	102, 2, 2, 177, 178, 5, 106, 54, 2, 178, 179, 7, 146, 2, 2, 179, 180, 7,
-	140, 2, 2, 180, 181, 5, 106, 54, 2, 181, 427, 3, 2, 2, 2, 182, 183, 7,
-	145, 2, 2, 183, 184, 7, 102, 2, 2, 184, 185, 5, 106, 54, 2, 185, 186, 7,
-	146, 2, 2, 186, 187, 7, 132, 2, 2, 187, 188, 5, 108, 55, 2, 188, 189, 7,
-	140, 2, 2, 189, 190, 5, 108, 55, 2, 190, 427, 3, 2, 2, 2, 191, 192, 7,
-	145, 2, 2, 192, 193, 7, 102, 2, 2, 193, 194, 5, 106, 54, 2, 194, 195, 7,
-	14, 2, 2, 195, 196, 7, 132, 2, 2, 196, 197, 5, 12, 7, 2, 197, 427, 3, 2,
-	2, 2, 198, 201, 7, 100, 2, 2, 199, 200, 7, 31, 2, 2, 200, 202, 7, 105,
-	2, 2, 201, 199, 3, 2, 2, 2, 201, 202, 3, 2, 2, 2, 202, 203, 3, 2, 2, 2,
-	203, 204, 7, 104, 2, 2, 204, 206, 5, 106, 54, 2, 205, 207, 5, 54, 28, 2,
-	206, 205, 3, 2, 2, 2, 206, 207, 3, 2, 2, 2, 207, 208, 3, 2, 2, 2, 208,
-	209, 7, 15, 2, 2, 209, 210, 5, 6, 4, 2, 210, 427, 3, 2, 2, 2, 211, 212,
-	7, 136, 2, 2, 212, 215, 7, 104, 2, 2, 213, 214, 7, 185, 2, 2, 214, 216,
-	7, 36, 2, 2, 215, 213, 3, 2, 2, 2, 215, 216, 3, 2, 2, 2, 216, 217, 3, 2,
-	2, 2, 217, 427, 5, 106, 54, 2, 218, 219, 7, 169, 2, 2, 219, 220, 5, 106,
-	54, 2, 220, 229, 7, 3, 2, 2, 221, 226, 5, 102, 52, 2, 222, 223, 7, 4, 2,
-	2, 223, 225, 5, 102, 52, 2, 224, 222, 3, 2, 2, 2, 225, 228, 3, 2, 2, 2,
-	226, 224, 3, 2, 2, 2, 226, 227, 3, 2, 2, 2, 227, 230, 3, 2, 2, 2, 228,
-	226, 3, 2, 2, 2, 229, 221, 3, 2, 2, 2, 229, 230, 3, 2, 2, 2, 230, 231,
-	3, 2, 2, 2, 231, 232, 7, 5, 2, 2, 232, 427, 3, 2, 2, 2, 233, 244, 7, 111,
-	2, 2, 234, 239, 5, 104, 53, 2, 235, 236, 7, 4, 2, 2, 236, 238, 5, 104,
-	53, 2, 237, 235, 3, 2, 2, 2, 238, 241, 3, 2, 2, 2, 239, 237, 3, 2, 2, 2,
-	239, 240, 3, 2, 2, 2, 240, 245, 3, 2, 2, 2, 241, 239, 3, 2, 2, 2, 242,
-	243, 7, 16, 2, 2, 243, 245, 7, 113, 2, 2, 244, 234, 3, 2, 2, 2, 244, 242,
-	3, 2, 2, 2, 245, 246, 3, 2, 2, 2, 246, 248, 7, 86, 2, 2, 247, 249, 7, 102,
-	2, 2, 248, 247, 3, 2, 2, 2, 248, 249, 3, 2, 2, 2, 249, 250, 3, 2, 2, 2,
-	250, 251, 5, 106, 54, 2, 251, 252, 7, 140, 2, 2, 252, 256, 5, 108, 55,
-	2, 253, 254, 7, 97, 2, 2, 254, 255, 7, 111, 2, 2, 255, 257, 7, 115, 2,
-	2, 256, 253, 3, 2, 2, 2, 256, 257, 3, 2, 2, 2, 257, 427, 3, 2, 2, 2, 258,
-	262, 7, 112, 2, 2, 259, 260, 7, 111, 2, 2, 260, 261, 7, 115, 2, 2, 261,
-	263, 7, 51, 2, 2, 262, 259, 3, 2, 2, 2, 262, 263, 3, 2, 2, 2, 263, 274,
-	3, 2, 2, 2, 264, 269, 5, 104, 53, 2, 265, 266, 7, 4, 2, 2, 266, 268, 5,
-	104, 53, 2, 267, 265, 3, 2, 2, 2, 268, 271, 3, 2, 2, 2, 269, 267, 3, 2,
-	2, 2, 269, 270, 3, 2, 2, 2, 270, 275, 3, 2, 2, 2, 271, 269, 3, 2, 2, 2,
-	272, 273, 7, 16, 2, 2, 273, 275, 7, 113, 2, 2, 274, 264, 3, 2, 2, 2, 274,
-	272, 3, 2, 2, 2, 275, 276, 3, 2, 2, 2, 276, 278, 7, 86, 2, 2, 277, 279,
-	7, 102, 2, 2, 278, 277, 3, 2, 2, 2, 278, 279, 3, 2, 2, 2, 279, 280, 3,
-	2, 2, 2, 280, 281, 5, 106, 54, 2, 281, 282, 7, 13, 2, 2, 282, 283, 5, 108,
-	55, 2, 283, 427, 3, 2, 2, 2, 284, 286, 7, 116, 2, 2, 285, 287, 7, 117,
-	2, 2, 286, 285, 3, 2, 2, 2, 286, 287, 3, 2, 2, 2, 287, 299, 3, 2, 2, 2,
-	288, 289, 7, 3, 2, 2, 289, 294, 5, 96, 49, 2, 290, 291, 7, 4, 2, 2, 291,
-	293, 5, 96, 49, 2, 292, 290, 3, 2, 2, 2, 293, 296, 3, 2, 2, 2, 294, 292,
-	3, 2, 2, 2, 294, 295, 3, 2, 2, 2, 295, 297, 3, 2, 2, 2, 296, 294, 3, 2,
-	2, 2, 297, 298, 7, 5, 2, 2, 298, 300, 3, 2, 2, 2, 299, 288, 3, 2, 2, 2,
-	299, 300, 3, 2, 2, 2, 300, 301, 3, 2, 2, 2, 301, 427, 5, 4, 3, 2, 302,
-	303, 7, 127, 2, 2, 303, 304, 7, 100, 2, 2, 304, 305, 7, 102, 2, 2, 305,
-	427, 5, 106, 54, 2, 306, 307, 7, 127, 2, 2, 307, 308, 7, 100, 2, 2, 308,
-	309, 7, 104, 2, 2, 309, 427, 5, 106, 54, 2, 310, 311, 7, 127, 2, 2, 311,
-	314, 7, 128, 2, 2, 312, 313, 9, 2, 2, 2, 313, 315, 5, 106, 54, 2, 314,
-	312, 3, 2, 2, 2, 314, 315, 3, 2, 2, 2, 315, 318, 3, 2, 2, 2, 316, 317,
-	7, 38, 2, 2, 317, 319, 7, 204, 2, 2, 318, 316, 3, 2, 2, 2, 318, 319, 3,
-	2, 2, 2, 319, 427, 3, 2, 2, 2, 320, 321, 7, 127, 2, 2, 321, 324, 7, 129,
-	2, 2, 322, 323, 9, 2, 2, 2, 323, 325, 5, 108, 55, 2, 324, 322, 3, 2, 2,
-	2, 324, 325, 3, 2, 2, 2, 325, 328, 3, 2, 2, 2, 326, 327, 7, 38, 2, 2, 327,
-	329, 7, 204, 2, 2, 328, 326, 3, 2, 2, 2, 328, 329, 3, 2, 2, 2, 329, 427,
-	3, 2, 2, 2, 330, 331, 7, 127, 2, 2, 331, 334, 7, 130, 2, 2, 332, 333, 7,
-	38, 2, 2, 333, 335, 7, 204, 2, 2, 334, 332, 3, 2, 2, 2, 334, 335, 3, 2,
-	2, 2, 335, 427, 3, 2, 2, 2, 336, 337, 7, 127, 2, 2, 337, 338, 7, 131, 2,
-	2, 338, 339, 9, 2, 2, 2, 339, 427, 5, 106, 54, 2, 340, 341, 9, 3, 2, 2,
-	341, 427, 5, 106, 54, 2, 342, 343, 7, 127, 2, 2, 343, 427, 7, 135, 2, 2,
-	344, 345, 7, 127, 2, 2, 345, 427, 7, 153, 2, 2, 346, 347, 7, 151, 2, 2,
-	347, 348, 7, 153, 2, 2, 348, 349, 5, 106, 54, 2, 349, 350, 7, 191, 2, 2,
-	350, 351, 5, 58, 30, 2, 351, 427, 3, 2, 2, 2, 352, 353, 7, 152, 2, 2, 353,
-	354, 7, 153, 2, 2, 354, 427, 5, 106, 54, 2, 355, 356, 7, 155, 2, 2, 356,
-	365, 7, 156, 2, 2, 357, 362, 5, 98, 50, 2, 358, 359, 7, 4, 2, 2, 359, 361,
-	5, 98, 50, 2, 360, 358, 3, 2, 2, 2, 361, 364, 3, 2, 2, 2, 362, 360, 3,
-	2, 2, 2, 362, 363, 3, 2, 2, 2, 363, 366, 3, 2, 2, 2, 364, 362, 3, 2, 2,
-	2, 365, 357, 3, 2, 2, 2, 365, 366, 3, 2, 2, 2, 366, 427, 3, 2, 2, 2, 367,
-	369, 7, 157, 2, 2, 368, 370, 7, 159, 2, 2, 369, 368, 3, 2, 2, 2, 369, 370,
-	3, 2, 2, 2, 370, 427, 3, 2, 2, 2, 371, 373, 7, 158, 2, 2, 372, 374, 7,
-	159, 2, 2, 373, 372, 3, 2, 2, 2, 373, 374, 3, 2, 2, 2, 374, 427, 3, 2,
-	2, 2, 375, 376, 7, 127, 2, 2, 376, 377, 7, 134, 2, 2, 377, 378, 9, 2, 2,
-	2, 378, 381, 5, 106, 54, 2, 379, 380, 7, 20, 2, 2, 380, 382, 5, 60, 31,
-	2, 381, 379, 3, 2, 2, 2, 381, 382, 3, 2, 2, 2, 382, 393, 3, 2, 2, 2, 383,
-	384, 7, 27, 2, 2, 384, 385, 7, 22, 2, 2, 385, 390, 5, 26, 14, 2, 386, 387,
-	7, 4, 2, 2, 387, 389, 5, 26, 14, 2, 388, 386, 3, 2, 2, 2, 389, 392, 3,
-	2, 2, 2, 390, 388, 3, 2, 2, 2, 390, 391, 3, 2, 2, 2, 391, 394, 3, 2, 2,
-	2, 392, 390, 3, 2, 2, 2, 393, 383, 3, 2, 2, 2, 393, 394, 3, 2, 2, 2, 394,
-	397, 3, 2, 2, 2, 395, 396, 7, 29, 2, 2, 396, 398, 7, 206, 2, 2, 397, 395,
-	3, 2, 2, 2, 397, 398, 3, 2, 2, 2, 398, 427, 3, 2, 2, 2, 399, 400, 7, 170,
-	2, 2, 400, 401, 5, 108, 55, 2, 401, 402, 7, 13, 2, 2, 402, 403, 5, 4, 3,
-	2, 403, 427, 3, 2, 2, 2, 404, 405, 7, 171, 2, 2, 405, 406, 7, 170, 2, 2,
-	406, 427, 5, 108, 55, 2, 407, 408, 7, 172, 2, 2, 408, 418, 5, 108, 55,
-	2, 409, 410, 7, 85, 2, 2, 410, 415, 5, 58, 30, 2, 411, 412, 7, 4, 2, 2,
-	412, 414, 5, 58, 30, 2, 413, 411, 3, 2, 2, 2, 414, 417, 3, 2, 2, 2, 415,
-	413, 3, 2, 2, 2, 415, 416, 3, 2, 2, 2, 416, 419, 3, 2, 2, 2, 417, 415,
-	3, 2, 2, 2, 418, 409, 3, 2, 2, 2, 418, 419, 3, 2, 2, 2, 419, 427, 3, 2,
-	2, 2, 420, 421, 7, 110, 2, 2, 421, 422, 7, 173, 2, 2, 422, 427, 5, 108,
-	55, 2, 423, 424, 7, 110, 2, 2, 424, 425, 7, 174, 2, 2, 425, 427, 5, 108,
-	55, 2, 426, 115, 3, 2, 2, 2, 426, 116, 3, 2, 2, 2, 426, 138, 3, 2, 2, 2,
-	426, 153, 3, 2, 2, 2, 426, 160, 3, 2, 2, 2, 426, 168, 3, 2, 2, 2, 426,
-	175, 3, 2, 2, 2, 426, 182, 3, 2, 2, 2, 426, 191, 3, 2, 2, 2, 426, 198,
-	3, 2, 2, 2, 426, 211, 3, 2, 2, 2, 426, 218, 3, 2, 2, 2, 426, 233, 3, 2,
-	2, 2, 426, 258, 3, 2, 2, 2, 426, 284, 3, 2, 2, 2, 426, 302, 3, 2, 2, 2,
-	426, 306, 3, 2, 2, 2, 426, 310, 3, 2, 2, 2, 426, 320, 3, 2, 2, 2, 426,
-	330, 3, 2, 2, 2, 426, 336, 3, 2, 2, 2, 426, 340, 3, 2, 2, 2, 426, 342,
-	3, 2, 2, 2, 426, 344, 3, 2, 2, 2, 426, 346, 3, 2, 2, 2, 426, 352, 3, 2,
-	2, 2, 426, 355, 3, 2, 2, 2, 426, 367, 3, 2, 2, 2, 426, 371, 3, 2, 2, 2,
-	426, 375, 3, 2, 2, 2, 426, 399, 3, 2, 2, 2, 426, 404, 3, 2, 2, 2, 426,
-	407, 3, 2, 2, 2, 426, 420, 3, 2, 2, 2, 426, 423, 3, 2, 2, 2, 427, 5, 3,
-	2, 2, 2, 428, 430, 5, 8, 5, 2, 429, 428, 3, 2, 2, 2, 429, 430, 3, 2, 2,
-	2, 430, 431, 3, 2, 2, 2, 431, 432, 5, 20, 11, 2, 432, 7, 3, 2, 2, 2, 433,
-	435, 7, 97, 2, 2, 434, 436, 7, 98, 2, 2, 435, 434, 3, 2, 2, 2, 435, 436,
-	3, 2, 2, 2, 436, 437, 3, 2, 2, 2, 437, 442, 5, 38, 20, 2, 438, 439, 7,
-	4, 2, 2, 439, 441, 5, 38, 20, 2, 440, 438, 3, 2, 2, 2, 441, 444, 3, 2,
-	2, 2, 442, 440, 3, 2, 2, 2, 442, 443, 3, 2, 2, 2, 443, 9, 3, 2, 2, 2, 444,
-	442, 3, 2, 2, 2, 445, 448, 5, 12, 7, 2, 446, 448, 5, 14, 8, 2, 447, 445,
-	3, 2, 2, 2, 447, 446, 3, 2, 2, 2, 448, 11, 3, 2, 2, 2, 449, 450, 5, 108,
-	55, 2, 450, 453, 5, 80, 41, 2, 451, 452, 7, 103, 2, 2, 452, 454, 7, 204,
-	2, 2, 453, 451, 3, 2, 2, 2, 453, 454, 3, 2, 2, 2, 454, 13, 3, 2, 2, 2,
-	455, 456, 7, 38, 2, 2, 456, 459, 5, 106, 54, 2, 457, 458, 9, 4, 2, 2, 458,
-	460, 7, 179, 2, 2, 459, 457, 3, 2, 2, 2, 459, 460, 3, 2, 2, 2, 460, 15,
-	3, 2, 2, 2, 461, 462, 7, 3, 2, 2, 462, 467, 5, 18, 10, 2, 463, 464, 7,
-	4, 2, 2, 464, 466, 5, 18, 10, 2, 465, 463, 3, 2, 2, 2, 466, 469, 3, 2,
-	2, 2, 467, 465, 3, 2, 2, 2, 467, 468, 3, 2, 2, 2, 468, 470, 3, 2, 2, 2,
-	469, 467, 3, 2, 2, 2, 470, 471, 7, 5, 2, 2, 471, 17, 3, 2, 2, 2, 472, 473,
-	5, 108, 55, 2, 473, 474, 7, 191, 2, 2, 474, 475, 5, 58, 30, 2, 475, 19,
-	3, 2, 2, 2, 476, 487, 5, 22, 12, 2, 477, 478, 7, 27, 2, 2, 478, 479, 7,
-	22, 2, 2, 479, 484, 5, 26, 14, 2, 480, 481, 7, 4, 2, 2, 481, 483, 5, 26,
-	14, 2, 482, 480, 3, 2, 2, 2, 483, 486, 3, 2, 2, 2, 484, 482, 3, 2, 2, 2,
-	484, 485, 3, 2, 2, 2, 485, 488, 3, 2, 2, 2, 486, 484, 3, 2, 2, 2, 487,
-	477, 3, 2, 2, 2, 487, 488, 3, 2, 2, 2, 488, 491, 3, 2, 2, 2, 489, 490,
-	7, 29, 2, 2, 490, 492, 7, 206, 2, 2, 491, 489, 3, 2, 2, 2, 491, 492, 3,
-	2, 2, 2, 492, 21, 3, 2, 2, 2, 493, 494, 8, 12, 1, 2, 494, 495, 5, 24, 13,
-	2, 495, 504, 3, 2, 2, 2, 496, 497, 12, 3, 2, 2, 497, 499, 9, 5, 2, 2, 498,
-	500, 5, 40, 21, 2, 499, 498, 3, 2, 2, 2, 499, 500, 3, 2, 2, 2, 500, 501,
-	3, 2, 2, 2, 501, 503, 5, 22, 12, 4, 502, 496, 3, 2, 2, 2, 503, 506, 3,
-	2, 2, 2, 504, 502, 3, 2, 2, 2, 504, 505, 3, 2, 2, 2, 505, 23, 3, 2, 2,
-	2, 506, 504, 3, 2, 2, 2, 507, 524, 5, 28, 15, 2, 508, 509, 7, 102, 2, 2,
-	509, 524, 5, 106, 54, 2, 510, 511, 7, 99, 2, 2, 511, 516, 5, 58, 30, 2,
-	512, 513, 7, 4, 2, 2, 513, 515, 5, 58, 30, 2, 514, 512, 3, 2, 2, 2, 515,
-	518, 3, 2, 2, 2, 516, 514, 3, 2, 2, 2, 516, 517, 3, 2, 2, 2, 517, 524,
-	3, 2, 2, 2, 518, 516, 3, 2, 2, 2, 519, 520, 7, 3, 2, 2, 520, 521, 5, 20,
-	11, 2, 521, 522, 7, 5, 2, 2, 522, 524, 3, 2, 2, 2, 523, 507, 3, 2, 2, 2,
-	523, 508, 3, 2, 2, 2, 523, 510, 3, 2, 2, 2, 523, 519, 3, 2, 2, 2, 524,
-	25, 3, 2, 2, 2, 525, 527, 5, 58, 30, 2, 526, 528, 9, 6, 2, 2, 527, 526,
-	3, 2, 2, 2, 527, 528, 3, 2, 2, 2, 528, 531, 3, 2, 2, 2, 529, 530, 7, 43,
-	2, 2, 530, 532, 9, 7, 2, 2, 531, 529, 3, 2, 2, 2, 531, 532, 3, 2, 2, 2,
-	532, 27, 3, 2, 2, 2, 533, 535, 7, 12, 2, 2, 534, 536, 5, 40, 21, 2, 535,
-	534, 3, 2, 2, 2, 535, 536, 3, 2, 2, 2, 536, 537, 3, 2, 2, 2, 537, 542,
-	5, 42, 22, 2, 538, 539, 7, 4, 2, 2, 539, 541, 5, 42, 22, 2, 540, 538, 3,
-	2, 2, 2, 541, 544, 3, 2, 2, 2, 542, 540, 3, 2, 2, 2, 542, 543, 3, 2, 2,
-	2, 543, 554, 3, 2, 2, 2, 544, 542, 3, 2, 2, 2, 545, 546, 7, 13, 2, 2, 546,
-	551, 5, 44, 23, 2, 547, 548, 7, 4, 2, 2, 548, 550, 5, 44, 23, 2, 549, 547,
-	3, 2, 2, 2, 550, 553, 3, 2, 2, 2, 551, 549, 3, 2, 2, 2, 551, 552, 3, 2,
-	2, 2, 552, 555, 3, 2, 2, 2, 553, 551, 3, 2, 2, 2, 554, 545, 3, 2, 2, 2,
-	554, 555, 3, 2, 2, 2, 555, 558, 3, 2, 2, 2, 556, 557, 7, 20, 2, 2, 557,
-	559, 5, 60, 31, 2, 558, 556, 3, 2, 2, 2, 558, 559, 3, 2, 2, 2, 559, 563,
-	3, 2, 2, 2, 560, 561, 7, 21, 2, 2, 561, 562, 7, 22, 2, 2, 562, 564, 5,
-	30, 16, 2, 563, 560, 3, 2, 2, 2, 563, 564, 3, 2, 2, 2, 564, 567, 3, 2,
-	2, 2, 565, 566, 7, 28, 2, 2, 566, 568, 5, 60, 31, 2, 567, 565, 3, 2, 2,
-	2, 567, 568, 3, 2, 2, 2, 568, 29, 3, 2, 2, 2, 569, 571, 5, 40, 21, 2, 570,
-	569, 3, 2, 2, 2, 570, 571, 3, 2, 2, 2, 571, 572, 3, 2, 2, 2, 572, 577,
-	5, 32, 17, 2, 573, 574, 7, 4, 2, 2, 574, 576, 5, 32, 17, 2, 575, 573, 3,
-	2, 2, 2, 576, 579, 3, 2, 2, 2, 577, 575, 3, 2, 2, 2, 577, 578, 3, 2, 2,
-	2, 578, 31, 3, 2, 2, 2, 579, 577, 3, 2, 2, 2, 580, 621, 5, 34, 18, 2, 581,
-	582, 7, 26, 2, 2, 582, 591, 7, 3, 2, 2, 583, 588, 5, 106, 54, 2, 584, 585,
-	7, 4, 2, 2, 585, 587, 5, 106, 54, 2, 586, 584, 3, 2, 2, 2, 587, 590, 3,
-	2, 2, 2, 588, 586, 3, 2, 2, 2, 588, 589, 3, 2, 2, 2, 589, 592, 3, 2, 2,
-	2, 590, 588, 3, 2, 2, 2, 591, 583, 3, 2, 2, 2, 591, 592, 3, 2, 2, 2, 592,
-	593, 3, 2, 2, 2, 593, 621, 7, 5, 2, 2, 594, 595, 7, 25, 2, 2, 595, 604,
-	7, 3, 2, 2, 596, 601, 5, 106, 54, 2, 597, 598, 7, 4, 2, 2, 598, 600, 5,
-	106, 54, 2, 599, 597, 3, 2, 2, 2, 600, 603, 3, 2, 2, 2, 601, 599, 3, 2,
-	2, 2, 601, 602, 3, 2, 2, 2, 602, 605, 3, 2, 2, 2, 603, 601, 3, 2, 2, 2,
-	604, 596, 3, 2, 2, 2, 604, 605, 3, 2, 2, 2, 605, 606, 3, 2, 2, 2, 606,
+	10, 55, 3, 56, 3, 56, 3, 56, 3, 11, 3, 11, 5, 11, 1315, 10, 11, 2, 8, 22,
+	44, 60, 66, 68, 80, 57, 2, 4, 6, 8, 10, 12, 14, 16, 18, 20, 22, 24, 26,
+	28, 30, 32, 34, 36, 38, 40, 42, 44, 46, 48, 50, 52, 54, 56, 58, 60, 62,
+	64, 66, 68, 70, 72, 74, 76, 78, 80, 82, 84, 86, 88, 90, 92, 94, 96, 98,
+	100, 102, 104, 106, 108, 110, 2, 24, 4, 2, 13, 13, 33, 33, 4, 2, 48, 48,
+	110, 110, 3, 2, 177, 178, 3, 2, 137, 139, 3, 2, 47, 48, 3, 2, 44, 45, 4,
+	2, 16, 16, 19, 19, 3, 2, 141, 143, 3, 2, 31, 32, 3, 2, 41, 42, 3, 2, 197,
+	198, 3, 2, 197, 201, 3, 2, 181, 184, 3, 2, 191, 196, 3, 2, 16, 18, 3, 2,
+	59, 64, 3, 2, 90, 91, 3, 2, 93, 94, 3, 2, 120, 121, 3, 2, 122, 124, 3,
+	2, 167, 168, 18, 2, 14, 14, 16, 18, 30, 30, 35, 35, 49, 50, 52, 65, 87,
+	91, 93, 96, 101, 101, 103, 105, 111, 124, 127, 135, 140, 144, 149, 169,
+	173, 179, 181, 187, 1519, 2, 112, 3, 2, 2, 2, 4, 426, 3, 2, 2, 2, 6, 429,
+	3, 2, 2, 2, 8, 433, 3, 2, 2, 2, 10, 447, 3, 2, 2, 2, 12, 449, 3, 2, 2,
+	2, 14, 455, 3, 2, 2, 2, 16, 461, 3, 2, 2, 2, 18, 472, 3, 2, 2, 2, 20, 476,
+	3, 2, 2, 2, 22, 493, 3, 2, 2, 2, 24, 523, 3, 2, 2, 2, 26, 525, 3, 2, 2,
+	2, 28, 533, 3, 2, 2, 2, 30, 570, 3, 2, 2, 2, 32, 620, 3, 2, 2, 2, 34, 635,
+	3, 2, 2, 2, 36, 650, 3, 2, 2, 2, 38, 652, 3, 2, 2, 2, 40, 661, 3, 2, 2,
+	2, 42, 675, 3, 2, 2, 2, 44, 677, 3, 2, 2, 2, 46, 704, 3, 2, 2, 2, 48, 720,
+	3, 2, 2, 2, 50, 722, 3, 2, 2, 2, 52, 731, 3, 2, 2, 2, 54, 741, 3, 2, 2,
+	2, 56, 776, 3, 2, 2, 2, 58, 780, 3, 2, 2, 2, 60, 789, 3, 2, 2, 2, 62, 799,
+	3, 2, 2, 2, 64, 862, 3, 2, 2, 2, 66, 868, 3, 2, 2, 2, 68, 1097, 3, 2, 2,
+	2, 70, 1118, 3, 2, 2, 2, 72, 1120, 3, 2, 2, 2, 74, 1122, 3, 2, 2, 2, 76,
+	1124, 3, 2, 2, 2, 78, 1134, 3, 2, 2, 2, 80, 1178, 3, 2, 2, 2, 82, 1189,
+	3, 2, 2, 2, 84, 1196, 3, 2, 2, 2, 86, 1198, 3, 2, 2, 2, 88, 1203, 3, 2,
+	2, 2, 90, 1209, 3, 2, 2, 2, 92, 1248, 3, 2, 2, 2, 94, 1257, 3, 2, 2, 2,
+	96, 1263, 3, 2, 2, 2, 98, 1270, 3, 2, 2, 2, 100, 1279, 3, 2, 2, 2, 102,
+	1286, 3, 2, 2, 2, 104, 1292, 3, 2, 2, 2, 106, 1294, 3, 2, 2, 2, 108, 1307,
+	3, 2, 2, 2, 110, 1309, 3, 2, 2, 2, 112, 113, 5, 4, 3, 2, 113, 114, 7, 11,
+	2, 2, 114, 3, 3, 2, 2, 2, 115, 427, 5, 6, 4, 2, 116, 117, 7, 100, 2, 2,
+	117, 121, 7, 102, 2, 2, 118, 119, 7, 185, 2, 2, 119, 120, 7, 34, 2, 2,
+	120, 122, 7, 36, 2, 2, 121, 118, 3, 2, 2, 2, 121, 122, 3, 2, 2, 2, 122,
+	123, 3, 2, 2, 2, 123, 124, 5, 106, 54, 2, 124, 125, 7, 3, 2, 2, 125, 130,
+	5, 10, 6, 2, 126, 127, 7, 4, 2, 2, 127, 129, 5, 10, 6, 2, 128, 126, 3,
+	2, 2, 2, 129, 132, 3, 2, 2, 2, 130, 128, 3, 2, 2, 2, 130, 131, 3, 2, 2,
+	2, 131, 133, 3, 2, 2, 2, 132, 130, 3, 2, 2, 2, 133, 136, 7, 5, 2, 2, 134,
+	135, 7, 97, 2, 2, 135, 137, 5, 16, 9, 2, 136, 134, 3, 2, 2, 2, 136, 137,
+	3, 2, 2, 2, 137, 427, 3, 2, 2, 2, 138, 139, 7, 100, 2, 2, 139, 143, 7,
+	102, 2, 2, 140, 141, 7, 185, 2, 2, 141, 142, 7, 34, 2, 2, 142, 144, 7,
+	36, 2, 2, 143, 140, 3, 2, 2, 2, 143, 144, 3, 2, 2, 2, 144, 145, 3, 2, 2,
+	2, 145, 148, 5, 106, 54, 2, 146, 147, 7, 97, 2, 2, 147, 149, 5, 16, 9,
+	2, 148, 146, 3, 2, 2, 2, 148, 149, 3, 2, 2, 2, 149, 150, 3, 2, 2, 2, 150,
+	151, 7, 15, 2, 2, 151, 152, 5, 6, 4, 2, 152, 427, 3, 2, 2, 2, 153, 154,
+	7, 136, 2, 2, 154, 157, 7, 102, 2, 2, 155, 156, 7, 185, 2, 2, 156, 158,
+	7, 36, 2, 2, 157, 155, 3, 2, 2, 2, 157, 158, 3, 2, 2, 2, 158, 159, 3, 2,
+	2, 2, 159, 427, 5, 106, 54, 2, 160, 161, 7, 106, 2, 2, 161, 162, 7, 108,
+	2, 2, 162, 164, 5, 106, 54, 2, 163, 165, 5, 54, 28, 2, 164, 163, 3, 2,
+	2, 2, 164, 165, 3, 2, 2, 2, 165, 166, 3, 2, 2, 2, 166, 167, 5, 6, 4, 2,
+	167, 427, 3, 2, 2, 2, 168, 169, 7, 107, 2, 2, 169, 170, 7, 13, 2, 2, 170,
+	173, 5, 106, 54, 2, 171, 172, 7, 20, 2, 2, 172, 174, 5, 60, 31, 2, 173,
+	171, 3, 2, 2, 2, 173, 174, 3, 2, 2, 2, 174, 427, 3, 2, 2, 2, 175, 176,
+	7, 145, 2, 2, 176, 177, 7, 102, 2, 2, 177, 178, 5, 106, 54, 2, 178, 179,
+	7, 146, 2, 2, 179, 180, 7, 140, 2, 2, 180, 181, 5, 106, 54, 2, 181, 427,
+	3, 2, 2, 2, 182, 183, 7, 145, 2, 2, 183, 184, 7, 102, 2, 2, 184, 185, 5,
+	106, 54, 2, 185, 186, 7, 146, 2, 2, 186, 187, 7, 132, 2, 2, 187, 188, 5,
+	108, 55, 2, 188, 189, 7, 140, 2, 2, 189, 190, 5, 108, 55, 2, 190, 427,
+	3, 2, 2, 2, 191, 192, 7, 145, 2, 2, 192, 193, 7, 102, 2, 2, 193, 194, 5,
+	106, 54, 2, 194, 195, 7, 14, 2, 2, 195, 196, 7, 132, 2, 2, 196, 197, 5,
+	12, 7, 2, 197, 427, 3, 2, 2, 2, 198, 201, 7, 100, 2, 2, 199, 200, 7, 31,
+	2, 2, 200, 202, 7, 105, 2, 2, 201, 199, 3, 2, 2, 2, 201, 202, 3, 2, 2,
+	2, 202, 203, 3, 2, 2, 2, 203, 204, 7, 104, 2, 2, 204, 206, 5, 106, 54,
+	2, 205, 207, 5, 54, 28, 2, 206, 205, 3, 2, 2, 2, 206, 207, 3, 2, 2, 2,
+	207, 208, 3, 2, 2, 2, 208, 209, 7, 15, 2, 2, 209, 210, 5, 6, 4, 2, 210,
+	427, 3, 2, 2, 2, 211, 212, 7, 136, 2, 2, 212, 215, 7, 104, 2, 2, 213, 214,
+	7, 185, 2, 2, 214, 216, 7, 36, 2, 2, 215, 213, 3, 2, 2, 2, 215, 216, 3,
+	2, 2, 2, 216, 217, 3, 2, 2, 2, 217, 427, 5, 106, 54, 2, 218, 219, 7, 169,
+	2, 2, 219, 220, 5, 106, 54, 2, 220, 229, 7, 3, 2, 2, 221, 226, 5, 102,
+	52, 2, 222, 223, 7, 4, 2, 2, 223, 225, 5, 102, 52, 2, 224, 222, 3, 2, 2,
+	2, 225, 228, 3, 2, 2, 2, 226, 224, 3, 2, 2, 2, 226, 227, 3, 2, 2, 2, 227,
+	230, 3, 2, 2, 2, 228, 226, 3, 2, 2, 2, 229, 221, 3, 2, 2, 2, 229, 230,
+	3, 2, 2, 2, 230, 231, 3, 2, 2, 2, 231, 232, 7, 5, 2, 2, 232, 427, 3, 2,
+	2, 2, 233, 244, 7, 111, 2, 2, 234, 239, 5, 104, 53, 2, 235, 236, 7, 4,
+	2, 2, 236, 238, 5, 104, 53, 2, 237, 235, 3, 2, 2, 2, 238, 241, 3, 2, 2,
+	2, 239, 237, 3, 2, 2, 2, 239, 240, 3, 2, 2, 2, 240, 245, 3, 2, 2, 2, 241,
+	239, 3, 2, 2, 2, 242, 243, 7, 16, 2, 2, 243, 245, 7, 113, 2, 2, 244, 234,
+	3, 2, 2, 2, 244, 242, 3, 2, 2, 2, 245, 246, 3, 2, 2, 2, 246, 248, 7, 86,
+	2, 2, 247, 249, 7, 102, 2, 2, 248, 247, 3, 2, 2, 2, 248, 249, 3, 2, 2,
+	2, 249, 250, 3, 2, 2, 2, 250, 251, 5, 106, 54, 2, 251, 252, 7, 140, 2,
+	2, 252, 256, 5, 108, 55, 2, 253, 254, 7, 97, 2, 2, 254, 255, 7, 111, 2,
+	2, 255, 257, 7, 115, 2, 2, 256, 253, 3, 2, 2, 2, 256, 257, 3, 2, 2, 2,
+	257, 427, 3, 2, 2, 2, 258, 262, 7, 112, 2, 2, 259, 260, 7, 111, 2, 2, 260,
+	261, 7, 115, 2, 2, 261, 263, 7, 51, 2, 2, 262, 259, 3, 2, 2, 2, 262, 263,
+	3, 2, 2, 2, 263, 274, 3, 2, 2, 2, 264, 269, 5, 104, 53, 2, 265, 266, 7,
+	4, 2, 2, 266, 268, 5, 104, 53, 2, 267, 265, 3, 2, 2, 2, 268, 271, 3, 2,
+	2, 2, 269, 267, 3, 2, 2, 2, 269, 270, 3, 2, 2, 2, 270, 275, 3, 2, 2, 2,
+	271, 269, 3, 2, 2, 2, 272, 273, 7, 16, 2, 2, 273, 275, 7, 113, 2, 2, 274,
+	264, 3, 2, 2, 2, 274, 272, 3, 2, 2, 2, 275, 276, 3, 2, 2, 2, 276, 278,
+	7, 86, 2, 2, 277, 279, 7, 102, 2, 2, 278, 277, 3, 2, 2, 2, 278, 279, 3,
+	2, 2, 2, 279, 280, 3, 2, 2, 2, 280, 281, 5, 106, 54, 2, 281, 282, 7, 13,
+	2, 2, 282, 283, 5, 108, 55, 2, 283, 427, 3, 2, 2, 2, 284, 286, 7, 116,
+	2, 2, 285, 287, 7, 117, 2, 2, 286, 285, 3, 2, 2, 2, 286, 287, 3, 2, 2,
+	2, 287, 299, 3, 2, 2, 2, 288, 289, 7, 3, 2, 2, 289, 294, 5, 96, 49, 2,
+	290, 291, 7, 4, 2, 2, 291, 293, 5, 96, 49, 2, 292, 290, 3, 2, 2, 2, 293,
+	296, 3, 2, 2, 2, 294, 292, 3, 2, 2, 2, 294, 295, 3, 2, 2, 2, 295, 297,
+	3, 2, 2, 2, 296, 294, 3, 2, 2, 2, 297, 298, 7, 5, 2, 2, 298, 300, 3, 2,
+	2, 2, 299, 288, 3, 2, 2, 2, 299, 300, 3, 2, 2, 2, 300, 301, 3, 2, 2, 2,
+	301, 427, 5, 4, 3, 2, 302, 303, 7, 127, 2, 2, 303, 304, 7, 100, 2, 2, 304,
+	305, 7, 102, 2, 2, 305, 427, 5, 106, 54, 2, 306, 307, 7, 127, 2, 2, 307,
+	308, 7, 100, 2, 2, 308, 309, 7, 104, 2, 2, 309, 427, 5, 106, 54, 2, 310,
+	311, 7, 127, 2, 2, 311, 314, 7, 128, 2, 2, 312, 313, 9, 2, 2, 2, 313, 315,
+	5, 106, 54, 2, 314, 312, 3, 2, 2, 2, 314, 315, 3, 2, 2, 2, 315, 318, 3,
+	2, 2, 2, 316, 317, 7, 38, 2, 2, 317, 319, 7, 204, 2, 2, 318, 316, 3, 2,
+	2, 2, 318, 319, 3, 2, 2, 2, 319, 427, 3, 2, 2, 2, 320, 321, 7, 127, 2,
+	2, 321, 324, 7, 129, 2, 2, 322, 323, 9, 2, 2, 2, 323, 325, 5, 108, 55,
+	2, 324, 322, 3, 2, 2, 2, 324, 325, 3, 2, 2, 2, 325, 328, 3, 2, 2, 2, 326,
+	327, 7, 38, 2, 2, 327, 329, 7, 204, 2, 2, 328, 326, 3, 2, 2, 2, 328, 329,
+	3, 2, 2, 2, 329, 427, 3, 2, 2, 2, 330, 331, 7, 127, 2, 2, 331, 334, 7,
+	130, 2, 2, 332, 333, 7, 38, 2, 2, 333, 335, 7, 204, 2, 2, 334, 332, 3,
+	2, 2, 2, 334, 335, 3, 2, 2, 2, 335, 427, 3, 2, 2, 2, 336, 337, 7, 127,
+	2, 2, 337, 338, 7, 131, 2, 2, 338, 339, 9, 2, 2, 2, 339, 427, 5, 106, 54,
+	2, 340, 341, 9, 3, 2, 2, 341, 427, 5, 106, 54, 2, 342, 343, 7, 127, 2,
+	2, 343, 427, 7, 135, 2, 2, 344, 345, 7, 127, 2, 2, 345, 427, 7, 153, 2,
+	2, 346, 347, 7, 151, 2, 2, 347, 348, 7, 153, 2, 2, 348, 349, 5, 106, 54,
+	2, 349, 350, 7, 191, 2, 2, 350, 351, 5, 58, 30, 2, 351, 427, 3, 2, 2, 2,
+	352, 353, 7, 152, 2, 2, 353, 354, 7, 153, 2, 2, 354, 427, 5, 106, 54, 2,
+	355, 356, 7, 155, 2, 2, 356, 365, 7, 156, 2, 2, 357, 362, 5, 98, 50, 2,
+	358, 359, 7, 4, 2, 2, 359, 361, 5, 98, 50, 2, 360, 358, 3, 2, 2, 2, 361,
+	364, 3, 2, 2, 2, 362, 360, 3, 2, 2, 2, 362, 363, 3, 2, 2, 2, 363, 366,
+	3, 2, 2, 2, 364, 362, 3, 2, 2, 2, 365, 357, 3, 2, 2, 2, 365, 366, 3, 2,
+	2, 2, 366, 427, 3, 2, 2, 2, 367, 369, 7, 157, 2, 2, 368, 370, 7, 159, 2,
+	2, 369, 368, 3, 2, 2, 2, 369, 370, 3, 2, 2, 2, 370, 427, 3, 2, 2, 2, 371,
+	373, 7, 158, 2, 2, 372, 374, 7, 159, 2, 2, 373, 372, 3, 2, 2, 2, 373, 374,
+	3, 2, 2, 2, 374, 427, 3, 2, 2, 2, 375, 376, 7, 127, 2, 2, 376, 377, 7,
+	134, 2, 2, 377, 378, 9, 2, 2, 2, 378, 381, 5, 106, 54, 2, 379, 380, 7,
+	20, 2, 2, 380, 382, 5, 60, 31, 2, 381, 379, 3, 2, 2, 2, 381, 382, 3, 2,
+	2, 2, 382, 393, 3, 2, 2, 2, 383, 384, 7, 27, 2, 2, 384, 385, 7, 22, 2,
+	2, 385, 390, 5, 26, 14, 2, 386, 387, 7, 4, 2, 2, 387, 389, 5, 26, 14, 2,
+	388, 386, 3, 2, 2, 2, 389, 392, 3, 2, 2, 2, 390, 388, 3, 2, 2, 2, 390,
+	391, 3, 2, 2, 2, 391, 394, 3, 2, 2, 2, 392, 390, 3, 2, 2, 2, 393, 383,
+	3, 2, 2, 2, 393, 394, 3, 2, 2, 2, 394, 397, 3, 2, 2, 2, 395, 396, 7, 29,
+	2, 2, 396, 398, 7, 206, 2, 2, 397, 395, 3, 2, 2, 2, 397, 398, 3, 2, 2,
+	2, 398, 427, 3, 2, 2, 2, 399, 400, 7, 170, 2, 2, 400, 401, 5, 108, 55,
+	2, 401, 402, 7, 13, 2, 2, 402, 403, 5, 4, 3, 2, 403, 427, 3, 2, 2, 2, 404,
+	405, 7, 171, 2, 2, 405, 406, 7, 170, 2, 2, 406, 427, 5, 108, 55, 2, 407,
+	408, 7, 172, 2, 2, 408, 418, 5, 108, 55, 2, 409, 410, 7, 85, 2, 2, 410,
+	415, 5, 58, 30, 2, 411, 412, 7, 4, 2, 2, 412, 414, 5, 58, 30, 2, 413, 411,
+	3, 2, 2, 2, 414, 417, 3, 2, 2, 2, 415, 413, 3, 2, 2, 2, 415, 416, 3, 2,
+	2, 2, 416, 419, 3, 2, 2, 2, 417, 415, 3, 2, 2, 2, 418, 409, 3, 2, 2, 2,
+	418, 419, 3, 2, 2, 2, 419, 427, 3, 2, 2, 2, 420, 421, 7, 110, 2, 2, 421,
+	422, 7, 173, 2, 2, 422, 427, 5, 108, 55, 2, 423, 424, 7, 110, 2, 2, 424,
+	425, 7, 174, 2, 2, 425, 427, 5, 108, 55, 2, 426, 115, 3, 2, 2, 2, 426,
+	116, 3, 2, 2, 2, 426, 138, 3, 2, 2, 2, 426, 153, 3, 2, 2, 2, 426, 160,
+	3, 2, 2, 2, 426, 168, 3, 2, 2, 2, 426, 175, 3, 2, 2, 2, 426, 182, 3, 2,
+	2, 2, 426, 191, 3, 2, 2, 2, 426, 198, 3, 2, 2, 2, 426, 211, 3, 2, 2, 2,
+	426, 218, 3, 2, 2, 2, 426, 233, 3, 2, 2, 2, 426, 258, 3, 2, 2, 2, 426,
+	284, 3, 2, 2, 2, 426, 302, 3, 2, 2, 2, 426, 306, 3, 2, 2, 2, 426, 310,
+	3, 2, 2, 2, 426, 320, 3, 2, 2, 2, 426, 330, 3, 2, 2, 2, 426, 336, 3, 2,
+	2, 2, 426, 340, 3, 2, 2, 2, 426, 342, 3, 2, 2, 2, 426, 344, 3, 2, 2, 2,
+	426, 346, 3, 2, 2, 2, 426, 352, 3, 2, 2, 2, 426, 355, 3, 2, 2, 2, 426,
+	367, 3, 2, 2, 2, 426, 371, 3, 2, 2, 2, 426, 375, 3, 2, 2, 2, 426, 399,
+	3, 2, 2, 2, 426, 404, 3, 2, 2, 2, 426, 407, 3, 2, 2, 2, 426, 420, 3, 2,
+	2, 2, 426, 423, 3, 2, 2, 2, 427, 5, 3, 2, 2, 2, 428, 430, 5, 8, 5, 2, 429,
+	428, 3, 2, 2, 2, 429, 430, 3, 2, 2, 2, 430, 431, 3, 2, 2, 2, 431, 432,
+	5, 20, 11, 2, 432, 7, 3, 2, 2, 2, 433, 435, 7, 97, 2, 2, 434, 436, 7, 98,
+	2, 2, 435, 434, 3, 2, 2, 2, 435, 436, 3, 2, 2, 2, 436, 437, 3, 2, 2, 2,
+	437, 442, 5, 38, 20, 2, 438, 439, 7, 4, 2, 2, 439, 441, 5, 38, 20, 2, 440,
+	438, 3, 2, 2, 2, 441, 444, 3, 2, 2, 2, 442, 440, 3, 2, 2, 2, 442, 443,
+	3, 2, 2, 2, 443, 9, 3, 2, 2, 2, 444, 442, 3, 2, 2, 2, 445, 448, 5, 12,
+	7, 2, 446, 448, 5, 14, 8, 2, 447, 445, 3, 2, 2, 2, 447, 446, 3, 2, 2, 2,
+	448, 11, 3, 2, 2, 2, 449, 450, 5, 108, 55, 2, 450, 453, 5, 80, 41, 2, 451,
+	452, 7, 103, 2, 2, 452, 454, 7, 204, 2, 2, 453, 451, 3, 2, 2, 2, 453, 454,
+	3, 2, 2, 2, 454, 13, 3, 2, 2, 2, 455, 456, 7, 38, 2, 2, 456, 459, 5, 106,
+	54, 2, 457, 458, 9, 4, 2, 2, 458, 460, 7, 179, 2, 2, 459, 457, 3, 2, 2,
+	2, 459, 460, 3, 2, 2, 2, 460, 15, 3, 2, 2, 2, 461, 462, 7, 3, 2, 2, 462,
+	467, 5, 18, 10, 2, 463, 464, 7, 4, 2, 2, 464, 466, 5, 18, 10, 2, 465, 463,
+	3, 2, 2, 2, 466, 469, 3, 2, 2, 2, 467, 465, 3, 2, 2, 2, 467, 468, 3, 2,
+	2, 2, 468, 470, 3, 2, 2, 2, 469, 467, 3, 2, 2, 2, 470, 471, 7, 5, 2, 2,
+	471, 17, 3, 2, 2, 2, 472, 473, 5, 108, 55, 2, 473, 474, 7, 191, 2, 2, 474,
+	475, 5, 58, 30, 2, 475, 19, 3, 2, 2, 2, 476, 487, 5, 22, 12, 2, 477, 478,
+	7, 27, 2, 2, 478, 479, 7, 22, 2, 2, 479, 484, 5, 26, 14, 2, 480, 481, 7,
+	4, 2, 2, 481, 483, 5, 26, 14, 2, 482, 480, 3, 2, 2, 2, 483, 486, 3, 2,
+	2, 2, 484, 482, 3, 2, 2, 2, 484, 485, 3, 2, 2, 2, 485, 488, 3, 2, 2, 2,
+	486, 484, 3, 2, 2, 2, 487, 477, 3, 2, 2, 2, 487, 488, 3, 2, 2, 2, 488,
+	491, 3, 2, 2, 2, 489, 490, 7, 29, 2, 2, 490, 492, 7, 206, 2, 2, 491, 489,
+	3, 2, 2, 2, 491, 492, 3, 2, 2, 2, 492, 1314, 3, 2, 2, 2, 1312, 1313, 7,
+	215, 2, 2, 1313, 1315, 7, 206, 2, 2, 1314, 1312, 3, 2, 2, 2, 1314, 1315,
+	3, 2, 2, 2, 1315, 21, 3, 2, 2, 2, 493, 494, 8, 12, 1, 2, 494, 495, 5, 24,
+	13, 2, 495, 504, 3, 2, 2, 2, 496, 497, 12, 3, 2, 2, 497, 499, 9, 5, 2,
+	2, 498, 500, 5, 40, 21, 2, 499, 498, 3, 2, 2, 2, 499, 500, 3, 2, 2, 2,
+	500, 501, 3, 2, 2, 2, 501, 503, 5, 22, 12, 4, 502, 496, 3, 2, 2, 2, 503,
+	506, 3, 2, 2, 2, 504, 502, 3, 2, 2, 2, 504, 505, 3, 2, 2, 2, 505, 23, 3,
+	2, 2, 2, 506, 504, 3, 2, 2, 2, 507, 524, 5, 28, 15, 2, 508, 509, 7, 102,
+	2, 2, 509, 524, 5, 106, 54, 2, 510, 511, 7, 99, 2, 2, 511, 516, 5, 58,
+	30, 2, 512, 513, 7, 4, 2, 2, 513, 515, 5, 58, 30, 2, 514, 512, 3, 2, 2,
+	2, 515, 518, 3, 2, 2, 2, 516, 514, 3, 2, 2, 2, 516, 517, 3, 2, 2, 2, 517,
+	524, 3, 2, 2, 2, 518, 516, 3, 2, 2, 2, 519, 520, 7, 3, 2, 2, 520, 521,
+	5, 20, 11, 2, 521, 522, 7, 5, 2, 2, 522, 524, 3, 2, 2, 2, 523, 507, 3,
+	2, 2, 2, 523, 508, 3, 2, 2, 2, 523, 510, 3, 2, 2, 2, 523, 519, 3, 2, 2,
+	2, 524, 25, 3, 2, 2, 2, 525, 527, 5, 58, 30, 2, 526, 528, 9, 6, 2, 2, 527,
+	526, 3, 2, 2, 2, 527, 528, 3, 2, 2, 2, 528, 531, 3, 2, 2, 2, 529, 530,
+	7, 43, 2, 2, 530, 532, 9, 7, 2, 2, 531, 529, 3, 2, 2, 2, 531, 532, 3, 2,
+	2, 2, 532, 27, 3, 2, 2, 2, 533, 535, 7, 12, 2, 2, 534, 536, 5, 40, 21,
+	2, 535, 534, 3, 2, 2, 2, 535, 536, 3, 2, 2, 2, 536, 537, 3, 2, 2, 2, 537,
+	542, 5, 42, 22, 2, 538, 539, 7, 4, 2, 2, 539, 541, 5, 42, 22, 2, 540, 538,
+	3, 2, 2, 2, 541, 544, 3, 2, 2, 2, 542, 540, 3, 2, 2, 2, 542, 543, 3, 2,
+	2, 2, 543, 554, 3, 2, 2, 2, 544, 542, 3, 2, 2, 2, 545, 546, 7, 13, 2, 2,
+	546, 551, 5, 44, 23, 2, 547, 548, 7, 4, 2, 2, 548, 550, 5, 44, 23, 2, 549,
+	547, 3, 2, 2, 2, 550, 553, 3, 2, 2, 2, 551, 549, 3, 2, 2, 2, 551, 552,
+	3, 2, 2, 2, 552, 555, 3, 2, 2, 2, 553, 551, 3, 2, 2, 2, 554, 545, 3, 2,
+	2, 2, 554, 555, 3, 2, 2, 2, 555, 558, 3, 2, 2, 2, 556, 557, 7, 20, 2, 2,
+	557, 559, 5, 60, 31, 2, 558, 556, 3, 2, 2, 2, 558, 559, 3, 2, 2, 2, 559,
+	563, 3, 2, 2, 2, 560, 561, 7, 21, 2, 2, 561, 562, 7, 22, 2, 2, 562, 564,
+	5, 30, 16, 2, 563, 560, 3, 2, 2, 2, 563, 564, 3, 2, 2, 2, 564, 567, 3,
+	2, 2, 2, 565, 566, 7, 28, 2, 2, 566, 568, 5, 60, 31, 2, 567, 565, 3, 2,
+	2, 2, 567, 568, 3, 2, 2, 2, 568, 29, 3, 2, 2, 2, 569, 571, 5, 40, 21, 2,
+	570, 569, 3, 2, 2, 2, 570, 571, 3, 2, 2, 2, 571, 572, 3, 2, 2, 2, 572,
+	577, 5, 32, 17, 2, 573, 574, 7, 4, 2, 2, 574, 576, 5, 32, 17, 2, 575, 573,
+	3, 2, 2, 2, 576, 579, 3, 2, 2, 2, 577, 575, 3, 2, 2, 2, 577, 578, 3, 2,
+	2, 2, 578, 31, 3, 2, 2, 2, 579, 577, 3, 2, 2, 2, 580, 621, 5, 34, 18, 2,
+	581, 582, 7, 26, 2, 2, 582, 591, 7, 3, 2, 2, 583, 588, 5, 106, 54, 2, 584,
+	585, 7, 4, 2, 2, 585, 587, 5, 106, 54, 2, 586, 584, 3, 2, 2, 2, 587, 590,
+	3, 2, 2, 2, 588, 586, 3, 2, 2, 2, 588, 589, 3, 2, 2, 2, 589, 592, 3, 2,
+	2, 2, 590, 588, 3, 2, 2, 2, 591, 583, 3, 2, 2, 2, 591, 592, 3, 2, 2, 2,
+	592, 593, 3, 2, 2, 2, 593, 621, 7, 5, 2, 2, 594, 595, 7, 25, 2, 2, 595,
+	604, 7, 3, 2, 2, 596, 601, 5, 106, 54, 2, 597, 598, 7, 4, 2, 2, 598, 600,
+	5, 106, 54, 2, 599, 597, 3, 2, 2, 2, 600, 603, 3, 2, 2, 2, 601, 599, 3,
+	2, 2, 2, 601, 602, 3, 2, 2, 2, 602, 605, 3, 2, 2, 2, 603, 601, 3, 2, 2,
+	2, 604, 596, 3, 2, 2, 2, 604, 605, 3, 2, 2, 2, 605, 606, 3, 2, 2, 2, 606,
 	621, 7, 5, 2, 2, 607, 608, 7, 23, 2, 2, 608, 609, 7, 24, 2, 2, 609, 610,
 	7, 3, 2, 2, 610, 615, 5, 36, 19, 2, 611, 612, 7, 4, 2, 2, 612, 614, 5,
 	36, 19, 2, 613, 611, 3, 2, 2, 2, 614, 617, 3, 2, 2, 2, 615, 613, 3, 2,
@@ -647,7 +649,7 @@ var parserATN = []uint16{
 	1308, 7, 210, 2, 2, 1305, 1308, 7, 211, 2, 2, 1306, 1308, 5, 110, 56, 2,
 	1307, 1302, 3, 2, 2, 2, 1307, 1303, 3, 2, 2, 2, 1307, 1304, 3, 2, 2, 2,
 	1307, 1305, 3, 2, 2, 2, 1307, 1306, 3, 2, 2, 2, 1308, 109, 3, 2, 2, 2,
-	1309, 1310, 9, 23, 2, 2, 1310, 111, 3, 2, 2, 2, 162, 121, 130, 136, 143,
+	1309, 1310, 9, 23, 2, 2, 1310, 111, 3, 2, 2, 2, 163, 121, 130, 136, 143,
 	148, 157, 164, 173, 201, 206, 215, 226, 229, 239, 244, 248, 256, 262, 269,
 	274, 278, 286, 294, 299, 314, 318, 324, 328, 334, 362, 365, 369, 373, 381,
 	390, 393, 397, 415, 418, 426, 429, 435, 442, 447, 453, 459, 467, 484, 487,
@@ -659,6 +661,7 @@ var parserATN = []uint16{
 	1009, 1034, 1037, 1046, 1052, 1058, 1064, 1073, 1082, 1097, 1107, 1109,
 	1118, 1126, 1132, 1159, 1171, 1176, 1178, 1184, 1189, 1196, 1218, 1221,
 	1230, 1233, 1236, 1248, 1257, 1263, 1270, 1279, 1286, 1292, 1299, 1307,
+	1314,
 }
 
 var deserializer = antlr.NewATNDeserializer(nil)
@@ -710,7 +713,7 @@ var symbolicNames = []string{
 	"EQ", "NEQ", "LT", "LTE", "GT", "GTE", "PLUS", "MINUS", "ASTERISK", "SLASH",
 	"PERCENT", "CONCAT", "DOT", "STRING", "BINARY_LITERAL", "INTEGER_VALUE",
 	"DECIMAL_VALUE", "IDENTIFIER", "DIGIT_IDENTIFIER", "QUOTED_IDENTIFIER",
-	"BACKQUOTED_IDENTIFIER", "SIMPLE_COMMENT", "BRACKETED_COMMENT", "WS",
+	"BACKQUOTED_IDENTIFIER", "SIMPLE_COMMENT", "BRACKETED_COMMENT", "WS", "OFFSET",
 }
 
 var ruleNames = []string{
@@ -968,6 +971,7 @@ const (
 	SQLBaseParserSIMPLE_COMMENT           = 210
 	SQLBaseParserBRACKETED_COMMENT        = 211
 	SQLBaseParserWS                       = 212
+	SQLBaseParserOFFSET                   = 213
 )
 
 // SQLBaseParser rules.
@@ -5685,9 +5689,15 @@ type IQueryNoWithContext interface {
 	// GetLimit returns the limit token.
 	GetLimit() antlr.Token
 
+	// GetOffset returns the offset token.
+	GetOffset() antlr.Token
+
 	// SetLimit sets the limit token.
 	SetLimit(antlr.Token)
 
+	// SetOffset sets the offset token.
+	SetOffset(antlr.Token)
+
 	// IsQueryNoWithContext differentiates from other interfaces.
 	IsQueryNoWithContext()
 }
@@ -5696,6 +5706,7 @@ type QueryNoWithContext struct {
 	*antlr.BaseParserRuleContext
 	parser antlr.Parser
 	limit  antlr.Token
+	offset antlr.Token
 }
 
 func NewEmptyQueryNoWithContext() *QueryNoWithContext {
@@ -5722,8 +5733,12 @@ func (s *QueryNoWithContext) GetParser() antlr.Parser { return s.parser }
 
 func (s *QueryNoWithContext) GetLimit() antlr.Token { return s.limit }
 
+func (s *QueryNoWithContext) GetOffset() antlr.Token { return s.offset }
+
 func (s *QueryNoWithContext) SetLimit(v antlr.Token) { s.limit = v }
 
+func (s *QueryNoWithContext) SetOffset(v antlr.Token) { s.offset = v }
+
 func (s *QueryNoWithContext) QueryTerm() IQueryTermContext {
 	var t = s.GetTypedRuleContext(reflect.TypeOf((*IQueryTermContext)(nil)).Elem(), 0)
 
@@ -5769,8 +5784,16 @@ func (s *QueryNoWithContext) LIMIT() antlr.TerminalNode {
 	return s.GetToken(SQLBaseParserLIMIT, 0)
 }
 
-func (s *QueryNoWithContext) INTEGER_VALUE() antlr.TerminalNode {
-	return s.GetToken(SQLBaseParserINTEGER_VALUE, 0)
+func (s *QueryNoWithContext) AllINTEGER_VALUE() []antlr.TerminalNode {
+	return s.GetTokens(SQLBaseParserINTEGER_VALUE)
+}
+
+func (s *QueryNoWithContext) INTEGER_VALUE(i int) antlr.TerminalNode {
+	return s.GetToken(SQLBaseParserINTEGER_VALUE, i)
+}
+
+func (s *QueryNoWithContext) OFFSET() antlr.TerminalNode {
+	return s.GetToken(SQLBaseParserOFFSET, 0)
 }
 
 func (s *QueryNoWithContext) GetRuleContext() antlr.RuleContext {
@@ -5873,6 +5896,24 @@ func (p *SQLBaseParser) QueryNoWith() (localctx IQueryNoWithContext) {
 			localctx.(*QueryNoWithContext).limit = _m
 		}
 
+	}
+	p.SetState(1312)
+	p.GetErrorHandler().Sync(p)
+	_la = p.GetTokenStream().LA(1)
+
+	if _la == SQLBaseParserOFFSET {
+		{
+			p.SetState(1310)
+			p.Match(SQLBaseParserOFFSET)
+		}
+		{
+			p.SetState(1311)
+
+			var _m = p.Match(SQLBaseParserINTEGER_VALUE)
+
+			localctx.(*QueryNoWithContext).offset = _m
+		}
+
 	}
 
 	return localctx
@@ -1,14 +1,19 @@
 package sqlparser
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/alpacahq/marketstore/contrib/candler/candlecandler"
 	"github.com/alpacahq/marketstore/contrib/candler/tickcandler"
 	"github.com/alpacahq/marketstore/uda"
 	"github.com/alpacahq/marketstore/uda/avg"
 	"github.com/alpacahq/marketstore/uda/count"
 	"github.com/alpacahq/marketstore/uda/gap"
+	"github.com/alpacahq/marketstore/uda/indicators"
 	"github.com/alpacahq/marketstore/uda/max"
 	"github.com/alpacahq/marketstore/uda/min"
+	"github.com/alpacahq/marketstore/uda/window"
 )
 
 var AggRegistry = map[string]uda.AggInterface{
@@ -26,4 +31,60 @@ var AggRegistry = map[string]uda.AggInterface{
 	"avg":           &avg.Avg{},
 	"Gap":           &gap.Gap{},
 	"gap":           &gap.Gap{},
+	"EMA":           &indicators.EMA{},
+	"ema":           &indicators.EMA{},
+	"RSI":           &indicators.RSI{},
+	"rsi":           &indicators.RSI{},
+	"MACD":          &indicators.MACD{},
+	"macd":          &indicators.MACD{},
+	"Bollinger":     &indicators.Bollinger{},
+	"bollinger":     &indicators.Bollinger{},
+	"VWAP":          &indicators.VWAP{},
+	"vwap":          &indicators.VWAP{},
+	"ATR":           &indicators.ATR{},
+	"atr":           &indicators.ATR{},
+}
+
+/*
+RegisterAggregate adds an aggregate to the AggRegistry, as the aggregates
+loaded from plugins at startup. Names are matched regardless of case and can
+not replace an aggregate that is already registered
+*/
+func RegisterAggregate(name string, agg uda.AggInterface) error {
+	if name == "" || agg == nil {
+		return fmt.Errorf("An aggregate needs a name and an implementation")
+	}
+	key := strings.ToLower(name)
+	if _, ok := AggRegistry[key]; ok {
+		return fmt.Errorf("An aggregate named \"%s\" is already registered", name)
+	}
+	AggRegistry[key] = agg
+	return nil
+}
+
+/*
+ScalarRegistry holds the functions that compute a value for each row, keyed
+by lower case name
+*/
+var ScalarRegistry = map[string]ScalarFunction{
+	"abs":        absFunction,
+	"log":        logFunction,
+	"round":      roundFunction,
+	"coalesce":   coalesceFunction,
+	"date_trunc": dateTruncFunction,
+}
+
+/*
+WindowRegistry holds the functions that can be called with an OVER clause,
+keyed by lower case name
+*/
+var WindowRegistry = map[string]uda.WindowInterface{
+	"lag":    &window.Lag{},
+	"lead":   &window.Lag{IsLead: true},
+	"avg":    &window.Rolling{Operator: window.AVG},
+	"sum":    &window.Rolling{Operator: window.SUM},
+	"min":    &window.Rolling{Operator: window.MIN},
+	"max":    &window.Rolling{Operator: window.MAX},
+	"count":  &window.Rolling{Operator: window.COUNT},
+	"stddev": &window.Rolling{Operator: window.STDDEV},
 }
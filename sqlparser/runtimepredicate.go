@@ -0,0 +1,548 @@
+package sqlparser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alpacahq/marketstore/utils/io"
+)
+
+/*
+RuntimePredicate is a WHERE condition evaluated over the rows of a column
+series. It is used for conditions that static predicates can not hold, like
+OR, NOT and comparisons between two columns:
+
+	WHERE Close > Open OR Volume > 1e6
+
+Evaluate returns a mask with true for each row that satisfies the condition
+*/
+type RuntimePredicate interface {
+	Evaluate(cs *io.ColumnSeries) (mask []bool, err error)
+	mapColumns(mapName func(string) string)
+}
+
+/*
+RuntimeValue is an operand of a runtime predicate. Evaluate returns either a
+column of the series or a *Literal, which compares with every row
+*/
+type RuntimeValue interface {
+	Evaluate(cs *io.ColumnSeries) (value interface{}, err error)
+	mapColumns(mapName func(string) string)
+}
+
+/*
+ApplyRuntimePredicate removes the rows of the column series that do not
+satisfy the predicate
+*/
+func ApplyRuntimePredicate(rp RuntimePredicate, cs *io.ColumnSeries) error {
+	if cs.Len() == 0 {
+		return nil
+	}
+	mask, err := rp.Evaluate(cs)
+	if err != nil {
+		return err
+	}
+	removalBitmap := make([]bool, len(mask))
+	for i, keep := range mask {
+		removalBitmap[i] = !keep
+	}
+	return cs.RestrictViaBitmap(removalBitmap)
+}
+
+/*
+Operands
+*/
+
+type ColumnOperand struct {
+	Name string
+}
+
+func (co *ColumnOperand) Evaluate(cs *io.ColumnSeries) (interface{}, error) {
+	col := cs.GetColumn(co.Name)
+	if col == nil {
+		return nil, fmt.Errorf("Column %s not found", co.Name)
+	}
+	return col, nil
+}
+
+func (co *ColumnOperand) mapColumns(mapName func(string) string) {
+	co.Name = mapName(co.Name)
+}
+
+type LiteralOperand struct {
+	Literal *Literal
+}
+
+func (lo *LiteralOperand) Evaluate(cs *io.ColumnSeries) (interface{}, error) {
+	return lo.Literal, nil
+}
+
+func (lo *LiteralOperand) mapColumns(mapName func(string) string) {}
+
+/*
+ExistsOperand is an uncorrelated EXISTS (subquery), true when the subquery
+returns any row
+*/
+type ExistsOperand struct {
+	Query *SelectRelation
+}
+
+func (eo *ExistsOperand) Evaluate(cs *io.ColumnSeries) (interface{}, error) {
+	result, err := eo.Query.Materialize()
+	if err != nil {
+		return nil, err
+	}
+	return NewLiteral(result.Len() > 0, BOOLEAN_LITERAL), nil
+}
+
+func (eo *ExistsOperand) mapColumns(mapName func(string) string) {}
+
+/*
+Predicates
+*/
+
+type LogicalPredicate struct {
+	Operator    BinaryOperatorEnum
+	Left, Right RuntimePredicate
+}
+
+func (lp *LogicalPredicate) Evaluate(cs *io.ColumnSeries) (mask []bool, err error) {
+	if mask, err = lp.Left.Evaluate(cs); err != nil {
+		return nil, err
+	}
+	right, err := lp.Right.Evaluate(cs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range mask {
+		if lp.Operator == OR_OP {
+			mask[i] = mask[i] || right[i]
+		} else {
+			mask[i] = mask[i] && right[i]
+		}
+	}
+	return mask, nil
+}
+
+func (lp *LogicalPredicate) mapColumns(mapName func(string) string) {
+	lp.Left.mapColumns(mapName)
+	lp.Right.mapColumns(mapName)
+}
+
+type NotPredicate struct {
+	Predicate RuntimePredicate
+}
+
+func (np *NotPredicate) Evaluate(cs *io.ColumnSeries) (mask []bool, err error) {
+	if mask, err = np.Predicate.Evaluate(cs); err != nil {
+		return nil, err
+	}
+	for i := range mask {
+		mask[i] = !mask[i]
+	}
+	return mask, nil
+}
+
+func (np *NotPredicate) mapColumns(mapName func(string) string) {
+	np.Predicate.mapColumns(mapName)
+}
+
+type ConstantPredicate struct {
+	Value bool
+}
+
+func (cp *ConstantPredicate) Evaluate(cs *io.ColumnSeries) (mask []bool, err error) {
+	mask = make([]bool, cs.Len())
+	for i := range mask {
+		mask[i] = cp.Value
+	}
+	return mask, nil
+}
+
+func (cp *ConstantPredicate) mapColumns(mapName func(string) string) {}
+
+type ComparisonPredicate struct {
+	Left, Right RuntimeValue
+	Operator    io.ComparisonOperatorEnum
+}
+
+func (cp *ComparisonPredicate) Evaluate(cs *io.ColumnSeries) (mask []bool, err error) {
+	left, err := cp.Left.Evaluate(cs)
+	if err != nil {
+		return nil, err
+	}
+	right, err := cp.Right.Evaluate(cs)
+	if err != nil {
+		return nil, err
+	}
+	return compareValueVectors(left, right, cs.Len(), cp.Operator)
+}
+
+func (cp *ComparisonPredicate) mapColumns(mapName func(string) string) {
+	cp.Left.mapColumns(mapName)
+	cp.Right.mapColumns(mapName)
+}
+
+/*
+BetweenPredicate excludes the bounds, like the static BETWEEN predicate
+*/
+type BetweenPredicate struct {
+	Value, Lower, Upper RuntimeValue
+	IsNot               bool
+}
+
+func (bp *BetweenPredicate) Evaluate(cs *io.ColumnSeries) (mask []bool, err error) {
+	within := &LogicalPredicate{
+		Operator: AND_OP,
+		Left:     &ComparisonPredicate{Left: bp.Value, Right: bp.Lower, Operator: io.GT},
+		Right:    &ComparisonPredicate{Left: bp.Value, Right: bp.Upper, Operator: io.LT},
+	}
+	if bp.IsNot {
+		return (&NotPredicate{Predicate: within}).Evaluate(cs)
+	}
+	return within.Evaluate(cs)
+}
+
+func (bp *BetweenPredicate) mapColumns(mapName func(string) string) {
+	bp.Value.mapColumns(mapName)
+	bp.Lower.mapColumns(mapName)
+	bp.Upper.mapColumns(mapName)
+}
+
+type InListPredicate struct {
+	Value RuntimeValue
+	List  []RuntimeValue
+	IsNot bool
+}
+
+func (ip *InListPredicate) Evaluate(cs *io.ColumnSeries) (mask []bool, err error) {
+	mask = make([]bool, cs.Len())
+	for _, item := range ip.List {
+		match, err := (&ComparisonPredicate{Left: ip.Value, Right: item, Operator: io.EQ}).Evaluate(cs)
+		if err != nil {
+			return nil, err
+		}
+		for i := range mask {
+			mask[i] = mask[i] || match[i]
+		}
+	}
+	if ip.IsNot {
+		for i := range mask {
+			mask[i] = !mask[i]
+		}
+	}
+	return mask, nil
+}
+
+func (ip *InListPredicate) mapColumns(mapName func(string) string) {
+	ip.Value.mapColumns(mapName)
+	for _, item := range ip.List {
+		item.mapColumns(mapName)
+	}
+}
+
+/*
+InSubqueryPredicate matches the value against the single column returned
+by an uncorrelated subquery
+*/
+type InSubqueryPredicate struct {
+	Value RuntimeValue
+	Query *SelectRelation
+	IsNot bool
+}
+
+func (ip *InSubqueryPredicate) Evaluate(cs *io.ColumnSeries) (mask []bool, err error) {
+	result, err := ip.Query.Materialize()
+	if err != nil {
+		return nil, err
+	}
+	var name string
+	for _, colName := range result.GetColumnNames() {
+		if strings.EqualFold(colName, "Epoch") {
+			continue
+		}
+		if name != "" {
+			return nil, fmt.Errorf("Subquery in IN must return a single column")
+		}
+		name = colName
+	}
+	if name == "" {
+		name = "Epoch"
+	}
+	list, err := newValueVector(result.GetColumn(name))
+	if err != nil {
+		return nil, err
+	}
+	value, err := ip.Value.Evaluate(cs)
+	if err != nil {
+		return nil, err
+	}
+	vv, err := newValueVector(value)
+	if err != nil {
+		return nil, err
+	}
+	if (vv.kind == stringVector) != (list.kind == stringVector) {
+		return nil, fmt.Errorf("Can not compare strings with numbers")
+	}
+
+	mask = make([]bool, cs.Len())
+	switch {
+	case vv.kind == stringVector:
+		set := make(map[string]bool, len(list.strs))
+		for _, val := range list.strs {
+			set[val] = true
+		}
+		for i := range mask {
+			mask[i] = set[vv.strs[vv.index(i)]]
+		}
+	case vv.kind == intVector && list.kind == intVector:
+		set := make(map[int64]bool, len(list.ints))
+		for _, val := range list.ints {
+			set[val] = true
+		}
+		for i := range mask {
+			mask[i] = set[vv.ints[vv.index(i)]]
+		}
+	default:
+		set := make(map[float64]bool)
+		for i := 0; i < len(list.ints)+len(list.floats); i++ {
+			set[list.float(i)] = true
+		}
+		for i := range mask {
+			mask[i] = set[vv.float(i)]
+		}
+	}
+	if ip.IsNot {
+		for i := range mask {
+			mask[i] = !mask[i]
+		}
+	}
+	return mask, nil
+}
+
+func (ip *InSubqueryPredicate) mapColumns(mapName func(string) string) {
+	ip.Value.mapColumns(mapName)
+}
+
+/*
+Vectorized comparison
+*/
+
+type vectorKind uint8
+
+const (
+	_ vectorKind = iota
+	intVector
+	floatVector
+	stringVector
+)
+
+/*
+valueVector holds a column or a scalar converted to one of the comparable
+kinds. A scalar has a single value that is used for every row
+*/
+type valueVector struct {
+	kind     vectorKind
+	ints     []int64
+	floats   []float64
+	strs     []string
+	isScalar bool
+}
+
+func (vv *valueVector) index(i int) int {
+	if vv.isScalar {
+		return 0
+	}
+	return i
+}
+
+func (vv *valueVector) float(i int) float64 {
+	if vv.kind == intVector {
+		return float64(vv.ints[vv.index(i)])
+	}
+	return vv.floats[vv.index(i)]
+}
+
+func newValueVector(value interface{}) (vv *valueVector, err error) {
+	vv = new(valueVector)
+	switch col := value.(type) {
+	case *Literal:
+		vv.isScalar = true
+		switch val := col.Value.(type) {
+		case int64:
+			vv.kind, vv.ints = intVector, []int64{val}
+		case float64:
+			vv.kind, vv.floats = floatVector, []float64{val}
+		case string:
+			if col.Type == STRING_LITERAL {
+				val = val[1 : len(val)-1] // Strip the quotes
+			}
+			vv.kind, vv.strs = stringVector, []string{val}
+		case bool:
+			vv.kind, vv.ints = intVector, []int64{boolToInt64(val)}
+		default:
+			return nil, fmt.Errorf("Unsupported literal in predicate: %v", col.Value)
+		}
+	case []int64:
+		vv.kind, vv.ints = intVector, col
+	case []int32:
+		vv.kind, vv.ints = intVector, make([]int64, len(col))
+		for i, val := range col {
+			vv.ints[i] = int64(val)
+		}
+	case []int16:
+		vv.kind, vv.ints = intVector, make([]int64, len(col))
+		for i, val := range col {
+			vv.ints[i] = int64(val)
+		}
+	case []int8:
+		vv.kind, vv.ints = intVector, make([]int64, len(col))
+		for i, val := range col {
+			vv.ints[i] = int64(val)
+		}
+	case []int:
+		vv.kind, vv.ints = intVector, make([]int64, len(col))
+		for i, val := range col {
+			vv.ints[i] = int64(val)
+		}
+	case []uint64:
+		vv.kind, vv.floats = floatVector, make([]float64, len(col))
+		for i, val := range col {
+			vv.floats[i] = float64(val)
+		}
+	case []uint32:
+		vv.kind, vv.ints = intVector, make([]int64, len(col))
+		for i, val := range col {
+			vv.ints[i] = int64(val)
+		}
+	case []uint16:
+		vv.kind, vv.ints = intVector, make([]int64, len(col))
+		for i, val := range col {
+			vv.ints[i] = int64(val)
+		}
+	case []uint8:
+		vv.kind, vv.ints = intVector, make([]int64, len(col))
+		for i, val := range col {
+			vv.ints[i] = int64(val)
+		}
+	case []float64:
+		vv.kind, vv.floats = floatVector, col
+	case []float32:
+		vv.kind, vv.floats = floatVector, make([]float64, len(col))
+		for i, val := range col {
+			vv.floats[i] = float64(val)
+		}
+	case []string:
+		vv.kind, vv.strs = stringVector, col
+	case []bool:
+		vv.kind, vv.ints = intVector, make([]int64, len(col))
+		for i, val := range col {
+			vv.ints[i] = boolToInt64(val)
+		}
+	default:
+		return nil, fmt.Errorf("Unsupported column type in predicate")
+	}
+	return vv, nil
+}
+
+/*
+compareValueVectors compares two operands row by row. A string literal
+compared with a numeric column is read as a date, as in static predicates
+*/
+func compareValueVectors(left, right interface{}, length int,
+	op io.ComparisonOperatorEnum) (mask []bool, err error) {
+	if left, err = coerceDateLiteral(left, right); err != nil {
+		return nil, err
+	}
+	if right, err = coerceDateLiteral(right, left); err != nil {
+		return nil, err
+	}
+	lv, err := newValueVector(left)
+	if err != nil {
+		return nil, err
+	}
+	rv, err := newValueVector(right)
+	if err != nil {
+		return nil, err
+	}
+
+	mask = make([]bool, length)
+	switch {
+	case lv.kind == stringVector || rv.kind == stringVector:
+		if lv.kind != rv.kind {
+			return nil, fmt.Errorf("Can not compare strings with numbers")
+		}
+		for i := range mask {
+			mask[i] = compareResult(strings.Compare(lv.strs[lv.index(i)], rv.strs[rv.index(i)]), op)
+		}
+	case lv.kind == intVector && rv.kind == intVector:
+		for i := range mask {
+			l, r := lv.ints[lv.index(i)], rv.ints[rv.index(i)]
+			mask[i] = compareResult(compareInt64(l, r), op)
+		}
+	default:
+		for i := range mask {
+			l, r := lv.float(i), rv.float(i)
+			var c int
+			switch {
+			case l < r:
+				c = -1
+			case l > r:
+				c = 1
+			}
+			mask[i] = compareResult(c, op)
+		}
+	}
+	return mask, nil
+}
+
+func coerceDateLiteral(value, other interface{}) (interface{}, error) {
+	literal, ok := value.(*Literal)
+	if !ok || literal.Type != STRING_LITERAL {
+		return value, nil
+	}
+	switch other.(type) {
+	case *Literal, []string:
+		return value, nil
+	}
+	coerced := NewLiteral(literal.Value, literal.Type)
+	if err := CoerceToNumeric(coerced); err != nil {
+		return nil, err
+	}
+	return coerced, nil
+}
+
+func boolToInt64(val bool) int64 {
+	if val {
+		return 1
+	}
+	return 0
+}
+
+func compareInt64(l, r int64) int {
+	switch {
+	case l < r:
+		return -1
+	case l > r:
+		return 1
+	}
+	return 0
+}
+
+func compareResult(c int, op io.ComparisonOperatorEnum) bool {
+	switch op {
+	case io.EQ:
+		return c == 0
+	case io.NEQ:
+		return c != 0
+	case io.LT:
+		return c < 0
+	case io.LTE:
+		return c <= 0
+	case io.GT:
+		return c > 0
+	case io.GTE:
+		return c >= 0
+	}
+	return false
+}
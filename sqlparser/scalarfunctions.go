@@ -0,0 +1,243 @@
+package sqlparser
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/alpacahq/marketstore/utils/io"
+)
+
+/*
+ScalarFunction computes one value per row from its arguments, each one a
+column of the series or a *Literal. The result is a column, or a *Literal
+when every argument is a literal
+*/
+type ScalarFunction func(cs *io.ColumnSeries, args []interface{}) (interface{}, error)
+
+func checkArgCount(args []interface{}, min, max int) error {
+	if len(args) < min || len(args) > max {
+		if min == max {
+			return fmt.Errorf("Takes %d arguments, have %d", min, len(args))
+		}
+		return fmt.Errorf("Takes %d to %d arguments, have %d", min, max, len(args))
+	}
+	return nil
+}
+
+/*
+abs(x)
+*/
+func absFunction(cs *io.ColumnSeries, args []interface{}) (interface{}, error) {
+	if err := checkArgCount(args, 1, 1); err != nil {
+		return nil, err
+	}
+	vv, err := newNumericVector(args[0], "abs")
+	if err != nil {
+		return nil, err
+	}
+	length := vectorLength(cs, vv)
+	if vv.kind == intVector {
+		ints := make([]int64, length)
+		for i := range ints {
+			if ints[i] = vv.ints[vv.index(i)]; ints[i] < 0 {
+				ints[i] = -ints[i]
+			}
+		}
+		return numericResult(ints, nil, vv.isScalar), nil
+	}
+	floats := make([]float64, length)
+	for i := range floats {
+		floats[i] = math.Abs(vv.floats[vv.index(i)])
+	}
+	return numericResult(nil, floats, vv.isScalar), nil
+}
+
+/*
+log(x) is the natural logarithm, log(b, x) the logarithm of x in base b
+*/
+func logFunction(cs *io.ColumnSeries, args []interface{}) (interface{}, error) {
+	if err := checkArgCount(args, 1, 2); err != nil {
+		return nil, err
+	}
+	vv, err := newNumericVector(args[len(args)-1], "log")
+	if err != nil {
+		return nil, err
+	}
+	base := &valueVector{kind: floatVector, floats: []float64{math.E}, isScalar: true}
+	if len(args) == 2 {
+		if base, err = newNumericVector(args[0], "log"); err != nil {
+			return nil, err
+		}
+	}
+	length := vectorLength(cs, vv, base)
+	floats := make([]float64, length)
+	for i := range floats {
+		floats[i] = math.Log(vv.float(i)) / math.Log(base.float(i))
+	}
+	return numericResult(nil, floats, vv.isScalar && base.isScalar), nil
+}
+
+/*
+round(x) rounds to the nearest integer, round(x, d) to d decimal places
+*/
+func roundFunction(cs *io.ColumnSeries, args []interface{}) (interface{}, error) {
+	if err := checkArgCount(args, 1, 2); err != nil {
+		return nil, err
+	}
+	vv, err := newNumericVector(args[0], "round")
+	if err != nil {
+		return nil, err
+	}
+	var places int64
+	if len(args) == 2 {
+		literal, ok := args[1].(*Literal)
+		if !ok || literal.Type != INTEGER_LITERAL {
+			return nil, fmt.Errorf("The number of decimal places must be an integer literal")
+		}
+		places = literal.Value.(int64)
+	}
+	length := vectorLength(cs, vv)
+	if vv.kind == intVector && places >= 0 {
+		return numericResult(vv.ints, nil, vv.isScalar), nil
+	}
+	scale := math.Pow(10, float64(places))
+	floats := make([]float64, length)
+	for i := range floats {
+		floats[i] = math.Round(vv.float(i)*scale) / scale
+	}
+	return numericResult(nil, floats, vv.isScalar), nil
+}
+
+/*
+coalesce(x, y, ...) returns the first of its arguments that is not null. The
+columns hold no nulls, so NaN floats and empty strings are read as nulls
+*/
+func coalesceFunction(cs *io.ColumnSeries, args []interface{}) (interface{}, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("Takes at least 1 argument")
+	}
+	var vectors []*valueVector
+	kind := intVector
+	for _, arg := range args {
+		if literal, ok := arg.(*Literal); ok && literal.Type == NULL_LITERAL {
+			continue
+		}
+		vv, err := newValueVector(arg)
+		if err != nil {
+			return nil, err
+		}
+		if len(vectors) != 0 && (vv.kind == stringVector) != (kind == stringVector) {
+			return nil, fmt.Errorf("Can not combine strings with numbers")
+		}
+		if vv.kind != intVector {
+			kind = vv.kind
+		}
+		vectors = append(vectors, vv)
+	}
+	if len(vectors) == 0 {
+		return NewLiteral(nil, NULL_LITERAL), nil
+	}
+	length := vectorLength(cs, vectors...)
+	isScalar := allScalar(vectors)
+
+	switch kind {
+	case intVector:
+		// Integers are never null
+		return numericResult(vectors[0].ints, nil, vectors[0].isScalar), nil
+	case stringVector:
+		strs := make([]string, length)
+		for i := range strs {
+			for _, vv := range vectors {
+				if strs[i] = vv.strs[vv.index(i)]; len(strs[i]) != 0 {
+					break
+				}
+			}
+		}
+		if isScalar {
+			return NewLiteral("'"+strs[0]+"'", STRING_LITERAL), nil
+		}
+		return strs, nil
+	}
+	floats := make([]float64, length)
+	for i := range floats {
+		for _, vv := range vectors {
+			if floats[i] = vv.float(i); !math.IsNaN(floats[i]) {
+				break
+			}
+		}
+	}
+	return numericResult(nil, floats, isScalar), nil
+}
+
+func allScalar(vectors []*valueVector) bool {
+	for _, vv := range vectors {
+		if !vv.isScalar {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+date_trunc(unit, Epoch) truncates epoch seconds to the start of the unit,
+one of second, minute, hour, day, week, month, quarter or year, in UTC
+*/
+func dateTruncFunction(cs *io.ColumnSeries, args []interface{}) (interface{}, error) {
+	if err := checkArgCount(args, 2, 2); err != nil {
+		return nil, err
+	}
+	literal, ok := args[0].(*Literal)
+	if !ok || literal.Type != STRING_LITERAL {
+		return nil, fmt.Errorf("The unit must be a string literal")
+	}
+	unit := strings.ToLower(strings.Trim(literal.Value.(string), "'"))
+	vv, err := newNumericVector(args[1], "date_trunc")
+	if err != nil {
+		return nil, err
+	}
+	if vv.kind != intVector {
+		return nil, fmt.Errorf("Epoch seconds are required")
+	}
+	var truncate func(t time.Time) time.Time
+	switch unit {
+	case "second":
+		truncate = func(t time.Time) time.Time { return t }
+	case "minute":
+		truncate = func(t time.Time) time.Time { return t.Truncate(time.Minute) }
+	case "hour":
+		truncate = func(t time.Time) time.Time { return t.Truncate(time.Hour) }
+	case "day":
+		truncate = func(t time.Time) time.Time {
+			return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		}
+	case "week":
+		truncate = func(t time.Time) time.Time {
+			// Weeks start on Monday
+			days := (int(t.Weekday()) + 6) % 7
+			return time.Date(t.Year(), t.Month(), t.Day()-days, 0, 0, 0, 0, time.UTC)
+		}
+	case "month":
+		truncate = func(t time.Time) time.Time {
+			return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+		}
+	case "quarter":
+		truncate = func(t time.Time) time.Time {
+			month := time.Month((int(t.Month())-1)/3*3 + 1)
+			return time.Date(t.Year(), month, 1, 0, 0, 0, 0, time.UTC)
+		}
+	case "year":
+		truncate = func(t time.Time) time.Time {
+			return time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+		}
+	default:
+		return nil, fmt.Errorf("Unknown unit %s", unit)
+	}
+	length := vectorLength(cs, vv)
+	ints := make([]int64, length)
+	for i := range ints {
+		ints[i] = truncate(time.Unix(vv.ints[vv.index(i)], 0).UTC()).Unix()
+	}
+	return numericResult(ints, nil, vv.isScalar), nil
+}
@@ -4,22 +4,27 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"strings"
 	"time"
 
 	"github.com/alpacahq/marketstore/executor"
 	"github.com/alpacahq/marketstore/planner"
+	"github.com/alpacahq/marketstore/uda"
 	"github.com/alpacahq/marketstore/utils/io"
 )
 
 type SelectRelation struct {
 	ExecutableStatement
-	Limit                  int
+	Limit, Offset          int
 	OrderBy                []SortItem
+	GroupBy                []*GroupKey
+	RuntimePredicate       RuntimePredicate
 	SelectList             []*AliasedIdentifier
 	IsPrimary, IsSelectAll bool
 	PrimaryTargetName      []string
 	Subquery               *SelectRelation
+	Join                   *JoinRelation
 	WherePredicate         IMSTree // Runtime predicates
 	SetQuantifier          SetQuantifierEnum
 	StaticPredicates       StaticPredicateGroup
@@ -74,26 +79,41 @@ func (sr *SelectRelation) Materialize() (outputColumnSeries *io.ColumnSeries, er
 	*/
 	var dsv []io.DataShape
 	var key *io.TimeBucketKey
-	if inputColumnSeries != nil {
+	switch {
+	case inputColumnSeries != nil:
 		dsv = inputColumnSeries.GetDataShapes()
-	} else {
+	case sr.Join != nil:
+		inputColumnSeries, err = sr.Join.MaterializeQualified(sr.StaticPredicates["Epoch"])
+		if err != nil {
+			return nil, err
+		}
+		if err = sr.QualifyJoinColumns(inputColumnSeries.GetColumnNames()); err != nil {
+			return nil, err
+		}
+		dsv = inputColumnSeries.GetDataShapes()
+	default:
 		if len(sr.PrimaryTargetName) == 0 {
 			return nil, fmt.Errorf("Unable to retrieve table name")
 		}
-		key = io.NewTimeBucketKey(sr.PrimaryTargetName[0], "Symbol/Timeframe/AttributeGroup")
-		if key == nil {
-			return nil, fmt.Errorf("Table name must match \"one/two/three\" for three directory levels")
+		key, err = tableKey(sr.PrimaryTargetName[0])
+		if err != nil {
+			return nil, err
 		}
-		d := executor.ThisInstance.CatalogDir
-		dsv, err = d.GetDataShapes(key)
+		dsv, err = tableDataShapes(key)
 		if err != nil {
 			return nil, err
 		}
+		if sr.groupsBySymbol() && !isMultiSymbol(key) {
+			dsv = append(dsv, io.DataShape{Name: "Symbol", Type: io.STRING})
+		}
 	}
 
 	/*
 		Validate the SELECT list
 	*/
+	if sr.hasExpressions() && (len(sr.GroupBy) != 0 || sr.hasFunctionCalls()) {
+		return nil, fmt.Errorf("Unsupported option: expressions along with aggregate functions")
+	}
 	var valid bool
 	var keepList, missing []string // List of all columns needed in the output result
 	if !sr.IsSelectAll {
@@ -101,7 +121,13 @@ func (sr *SelectRelation) Materialize() (outputColumnSeries *io.ColumnSeries, er
 			Set up a validator via a map of the primary data columns to the
 			relation output names
 		*/
-		dsv = append(dsv, io.DataShape{Name: "Epoch", Type: io.INT64})
+		hasEpoch := false
+		for _, shape := range dsv {
+			hasEpoch = hasEpoch || shape.Name == "Epoch"
+		}
+		if !hasEpoch {
+			dsv = append(dsv, io.DataShape{Name: "Epoch", Type: io.INT64})
+		}
 		valid, missing, keepList, _, err = SourceValidator(dsv, sr.SelectList)
 		if err != nil {
 			return nil, err
@@ -129,284 +155,77 @@ func (sr *SelectRelation) Materialize() (outputColumnSeries *io.ColumnSeries, er
 	if inputColumnSeries != nil {
 		outputColumnSeries = inputColumnSeries
 	} else {
-		q := planner.NewQuery(executor.ThisInstance.CatalogDir)
-		q.AddTargetKey(key)
-
-		/*
-			Search for time/Epoch predicates and push them down to the IO query
-		*/
-		if sp, ok := sr.StaticPredicates["Epoch"]; ok {
-			if sp.ContentsEnum.IsSet(MINBOUND) {
-				val, err := io.GetValueAsInt64(sp.min)
-				if err != nil {
-					return nil, fmt.Errorf("Non date predicate found for Epoch")
-				}
-				if sp.ContentsEnum.IsSet(INCLUSIVEMIN) {
-					val += 1
-				}
-				q.SetStart(val)
+		checkForPredicatesAndFunctions := func() bool {
+			// First check for predicates - only those on Epoch are pushed down
+			if sr.RuntimePredicate != nil {
+				return true
 			}
-			if sp.ContentsEnum.IsSet(MAXBOUND) {
-				val, err := io.GetValueAsInt64(sp.max)
-				if err != nil {
-					return nil, fmt.Errorf("Non date predicate found for Epoch")
+			for name := range sr.StaticPredicates {
+				if name != "Epoch" {
+					return true
 				}
-				if sp.ContentsEnum.IsSet(INCLUSIVEMAX) {
-					val -= 1
-				}
-				q.SetEnd(val)
 			}
-		}
-
-		// TODO: push down range predicates on Epoch column
-		checkForPredicatesAndFunctions := func() bool {
-			// First check for predicates - we don't push these down (even though we can for Epoch predicates)
-			if len(sr.StaticPredicates) != 0 {
+			// Grouping, ordering and offsets need all rows, as do multiple symbols
+			if len(sr.GroupBy) != 0 || len(sr.OrderBy) != 0 || sr.Offset != 0 {
 				return true
 			}
-			// Check for functions on the relation
-			if !sr.IsSelectAll {
-				for _, sl := range sr.SelectList {
-					if sl.IsFunctionCall {
-						return true
-					}
+			// Window functions compute each row from the rows around it
+			for _, sl := range sr.SelectList {
+				if sl.IsExpression && hasWindowFunction(sl.RuntimeExpression) {
+					return true
 				}
 			}
-			return false
+			if isMultiSymbol(key) {
+				return true
+			}
+			// Check for functions on the relation
+			return sr.hasFunctionCalls()
 		}
+		var limit int
 		if !checkForPredicatesAndFunctions() {
-			if sr.Limit != 0 {
-				q.SetRowLimit(io.FIRST, sr.Limit)
-			}
+			limit = sr.Limit
 		}
 
-		parsed, err := q.Parse()
-		if err != nil {
-			return nil, err
-		}
-		scanner, err := executor.NewReader(parsed)
+		outputColumnSeries, err = queryTimeBucket(key, sr.StaticPredicates["Epoch"], limit, io.FIRST)
 		if err != nil {
 			return nil, err
 		}
-		csm, err := scanner.Read()
-		if err != nil {
-			return nil, err
-		}
-		if len(csm) == 0 {
-			return nil, fmt.Errorf("No results returned from query")
-		}
+	}
 
-		outputColumnSeries = csm[*key]
-		if outputColumnSeries.Len() == 0 {
-			return outputColumnSeries, nil
+	/*
+		Evaluate all predicates on final results set
+	*/
+	sr.StaticPredicates.Apply(outputColumnSeries)
+	if sr.RuntimePredicate != nil {
+		if err = ApplyRuntimePredicate(sr.RuntimePredicate, outputColumnSeries); err != nil {
+			return nil, err
 		}
+	}
 
-		/*
-			Evaluate all predicates on final results set
-		*/
-		totalLength := outputColumnSeries.Len()
-		removalBitmap := make([]bool, totalLength, totalLength) // true means we ditch the value, default is keep
-		for _, name := range outputColumnSeries.GetColumnNames() {
-			if sp, ok := sr.StaticPredicates[name]; ok {
-				i_col := outputColumnSeries.GetColumn(name)
-				switch col := i_col.(type) {
-				case []float32:
-					if sp.ContentsEnum.IsSet(EQUALITY) {
-						eqval, _ := io.GetValueAsFloat64(sp.equal)
-						for i, val := range col {
-							if val != float32(eqval) {
-								removalBitmap[i] = true // remove
-							}
-						}
-					}
-					if sp.ContentsEnum.IsSet(MINBOUND) {
-						minval, _ := io.GetValueAsFloat64(sp.min)
-						for i, val := range col {
-							if sp.ContentsEnum.IsSet(INCLUSIVEMIN) {
-								if val < float32(minval) {
-									removalBitmap[i] = true // remove
-								}
-							} else {
-								if val <= float32(minval) {
-									removalBitmap[i] = true // remove
-								}
-							}
-						}
-					}
-					if sp.ContentsEnum.IsSet(MAXBOUND) {
-						maxval, _ := io.GetValueAsFloat64(sp.max)
-						for i, val := range col {
-							if sp.ContentsEnum.IsSet(INCLUSIVEMAX) {
-								if val > float32(maxval) {
-									removalBitmap[i] = true // remove
-								}
-							} else {
-								if val >= float32(maxval) {
-									removalBitmap[i] = true // remove
-								}
-							}
-						}
-					}
-				case []float64:
-					if sp.ContentsEnum.IsSet(EQUALITY) {
-						eqval, _ := io.GetValueAsFloat64(sp.equal)
-						for i, val := range col {
-							if val != eqval {
-								removalBitmap[i] = true // remove
-							}
-						}
-					}
-					if sp.ContentsEnum.IsSet(MINBOUND) {
-						minval, _ := io.GetValueAsFloat64(sp.min)
-						for i, val := range col {
-							if sp.ContentsEnum.IsSet(INCLUSIVEMIN) {
-								if val < minval {
-									removalBitmap[i] = true // remove
-								}
-							} else {
-								if val <= minval {
-									removalBitmap[i] = true // remove
-								}
-							}
-						}
-					}
-					if sp.ContentsEnum.IsSet(MAXBOUND) {
-						maxval, _ := io.GetValueAsFloat64(sp.max)
-						for i, val := range col {
-							if sp.ContentsEnum.IsSet(INCLUSIVEMAX) {
-								if val > maxval {
-									removalBitmap[i] = true // remove
-								}
-							} else {
-								if val >= maxval {
-									removalBitmap[i] = true // remove
-								}
-							}
-						}
-					}
-				case []int:
-					if sp.ContentsEnum.IsSet(EQUALITY) {
-						eqval, _ := io.GetValueAsInt64(sp.equal)
-						for i, val := range col {
-							if val != int(eqval) {
-								removalBitmap[i] = true // remove
-							}
-						}
-					}
-					if sp.ContentsEnum.IsSet(MINBOUND) {
-						minval, _ := io.GetValueAsInt64(sp.min)
-						for i, val := range col {
-							if sp.ContentsEnum.IsSet(INCLUSIVEMIN) {
-								if val < int(minval) {
-									removalBitmap[i] = true // remove
-								}
-							} else {
-								if val <= int(minval) {
-									removalBitmap[i] = true // remove
-								}
-							}
-						}
-					}
-					if sp.ContentsEnum.IsSet(MAXBOUND) {
-						maxval, _ := io.GetValueAsInt64(sp.max)
-						for i, val := range col {
-							if sp.ContentsEnum.IsSet(INCLUSIVEMAX) {
-								if val > int(maxval) {
-									removalBitmap[i] = true // remove
-								}
-							} else {
-								if val >= int(maxval) {
-									removalBitmap[i] = true // remove
-								}
-							}
-						}
-					}
-				case []int32:
-					if sp.ContentsEnum.IsSet(EQUALITY) {
-						eqval, _ := io.GetValueAsInt64(sp.equal)
-						for i, val := range col {
-							if val != int32(eqval) {
-								removalBitmap[i] = true // remove
-							}
-						}
-					}
-					if sp.ContentsEnum.IsSet(MINBOUND) {
-						minval, _ := io.GetValueAsInt64(sp.min)
-						for i, val := range col {
-							if sp.ContentsEnum.IsSet(INCLUSIVEMIN) {
-								if val < int32(minval) {
-									removalBitmap[i] = true // remove
-								}
-							} else {
-								if val <= int32(minval) {
-									removalBitmap[i] = true // remove
-								}
-							}
-						}
-					}
-					if sp.ContentsEnum.IsSet(MAXBOUND) {
-						maxval, _ := io.GetValueAsInt64(sp.max)
-						for i, val := range col {
-							if sp.ContentsEnum.IsSet(INCLUSIVEMAX) {
-								if val > int32(maxval) {
-									removalBitmap[i] = true // remove
-								}
-							} else {
-								if val >= int32(maxval) {
-									removalBitmap[i] = true // remove
-								}
-							}
-						}
-					}
-				case []int64:
-					if sp.ContentsEnum.IsSet(EQUALITY) {
-						eqval, _ := io.GetValueAsInt64(sp.equal)
-						for i, val := range col {
-							if val != eqval {
-								removalBitmap[i] = true // remove
-							}
-						}
-					}
-					if sp.ContentsEnum.IsSet(MINBOUND) {
-						minval, _ := io.GetValueAsInt64(sp.min)
-						for i, val := range col {
-							if sp.ContentsEnum.IsSet(INCLUSIVEMIN) {
-								if val < minval {
-									removalBitmap[i] = true // remove
-								}
-							} else {
-								if val <= minval {
-									removalBitmap[i] = true // remove
-								}
-							}
-						}
-					}
-					if sp.ContentsEnum.IsSet(MAXBOUND) {
-						maxval, _ := io.GetValueAsInt64(sp.max)
-						for i, val := range col {
-							if sp.ContentsEnum.IsSet(INCLUSIVEMAX) {
-								if val > maxval {
-									removalBitmap[i] = true // remove
-								}
-							} else {
-								if val >= maxval {
-									removalBitmap[i] = true // remove
-								}
-							}
-						}
-					}
-				}
+	/*
+		Handle GROUP BY, aggregating each group
+	*/
+	var selectListOutput *io.ColumnSeries
+	var skipProjection bool // TODO: Only skip for SRF
+	if len(sr.GroupBy) != 0 {
+		if sr.groupsBySymbol() && !outputColumnSeries.Exists("Symbol") && key != nil {
+			symbols := make([]string, outputColumnSeries.Len())
+			for i := range symbols {
+				symbols[i] = key.GetItemInCategory("Symbol")
 			}
+			outputColumnSeries.AddColumn("Symbol", symbols)
+		}
+		outputColumnSeries, err = sr.groupAndAggregate(outputColumnSeries)
+		if err != nil {
+			return nil, err
 		}
-		outputColumnSeries.RestrictViaBitmap(removalBitmap)
+		skipProjection = true
 	}
 
 	/*
 		Handle functions in Select List
 	*/
-	var selectListOutput *io.ColumnSeries
-	var skipProjection bool // TODO: Only skip for SRF
-	if !sr.IsSelectAll {
+	if !sr.IsSelectAll && len(sr.GroupBy) == 0 {
 		for _, sl := range sr.SelectList {
 			if sl.IsFunctionCall {
 				if selectListOutput == nil {
@@ -415,60 +234,18 @@ func (sr *SelectRelation) Materialize() (outputColumnSeries *io.ColumnSeries, er
 				// TODO: This only handles SRF
 				skipProjection = true
 				aggName := sl.FunctionCall.Name
-				agg := AggRegistry[strings.ToLower(aggName)]
-				if agg == nil {
-					return nil, fmt.Errorf("No function in the UDA Registry named \"%s\"", aggName)
-				}
-				aggfunc, argMap := agg.New()
-
-				if sl.FunctionCall.IsAsterisk {
-					/*
-						If an asterisk is provided, use Epoch as the mapped input column
-					*/
-					argMap.MapRequiredColumn("*", io.DataShape{
-						Name: "Epoch", Type: io.INT64,
-					})
-				} else {
-					idList := sl.FunctionCall.GetIDs()
-					err = argMap.PrepareArguments(idList)
-					if err != nil {
-						return nil, fmt.Errorf("Argument mapping error for %s: %s", aggName, err.Error())
-					}
-				}
-
-				/*
-					Initialize the Aggregate
-						An agg may have init parameters, which are used only to initialize it
-						These are single value literals (like '1Min')
-				*/
-				requiredInitDSV := aggfunc.GetInitArgs()
-				requiredInitNames := io.GetNamesFromDSV(requiredInitDSV)
-
-				initList := sl.FunctionCall.GetLiterals()
-				if len(requiredInitNames) > len(initList) {
-					return nil, fmt.Errorf(
-						"Not enough init arguments for %s, need %d have %d",
-						aggName,
-						len(requiredInitNames),
-						len(initList),
-					)
+				if isTimeBucket(sl.FunctionCall) {
+					return nil, fmt.Errorf("time_bucket can only be used with GROUP BY")
 				}
-				// TODO: Handle different argument types from string
-				var initArgList []string
-				for _, lit := range initList {
-					value := lit.Value.(string)
-					value = value[1 : len(value)-1] // Strip the quotes
-					initArgList = append(
-						initArgList,
-						value,
-					)
+				aggfunc, err := sr.newAggregate(sl)
+				if err != nil {
+					return nil, err
 				}
-				aggfunc.Init(initArgList)
 
 				/*
 					Execute the aggregate function
 				*/
-				err := aggfunc.Accum(outputColumnSeries)
+				err = aggfunc.Accum(outputColumnSeries)
 				if err != nil {
 					return nil, err
 				}
@@ -512,19 +289,52 @@ func (sr *SelectRelation) Materialize() (outputColumnSeries *io.ColumnSeries, er
 		}
 	}
 
+	/*
+		Evaluate the expressions in the select list, before ORDER BY so
+		that their names can be used
+	*/
+	var projection []string
+	if sr.hasExpressions() {
+		if projection, err = sr.evaluateExpressions(outputColumnSeries); err != nil {
+			return nil, err
+		}
+	}
+
+	/*
+		Handle ORDER BY, before projection so that columns not in the
+		select list can be used
+	*/
+	if len(sr.OrderBy) != 0 {
+		orderBy := sr.OrderBy
+		if !sr.IsSelectAll && !skipProjection {
+			orderBy = sr.resolveOrderByAliases()
+		}
+		if err = sortColumnSeries(outputColumnSeries, orderBy); err != nil {
+			return nil, err
+		}
+	}
+
 	/*
 		Handle column projection and aliases
 	*/
 	if !sr.IsSelectAll && !skipProjection {
 		// Column projection
-		err = outputColumnSeries.Project(keepList)
+		if projection == nil {
+			projection = keepList
+		}
+		err = outputColumnSeries.Project(projection)
 		if err != nil {
 			return nil, err
 		}
 		// Column alias remapping on exit
-		for _, item := range sr.SelectList {
-			if item.IsAliased {
-				err := outputColumnSeries.Rename(item.Alias, item.PrimaryName)
+		for i, item := range sr.SelectList {
+			oldName := item.PrimaryName
+			if item.IsExpression {
+				// The name of the expression column may have been made unique
+				oldName = projection[i]
+			}
+			if item.Alias != oldName && (item.IsAliased || item.IsExpression) {
+				err := outputColumnSeries.Rename(item.Alias, oldName)
 				if err != nil {
 					return nil, err
 				}
@@ -533,8 +343,15 @@ func (sr *SelectRelation) Materialize() (outputColumnSeries *io.ColumnSeries, er
 	}
 
 	/*
-		Enforce LIMIT on the final results
+		Enforce OFFSET and LIMIT on the final results
 	*/
+	if sr.Offset != 0 {
+		remaining := outputColumnSeries.Len() - sr.Offset
+		if remaining < 0 {
+			remaining = 0
+		}
+		outputColumnSeries.RestrictLength(remaining, io.LAST)
+	}
 	if sr.Limit != 0 {
 		outputColumnSeries.RestrictLength(sr.Limit, io.FIRST)
 	}
@@ -542,6 +359,310 @@ func (sr *SelectRelation) Materialize() (outputColumnSeries *io.ColumnSeries, er
 	return outputColumnSeries, nil
 }
 
+/*
+queryTimeBucket reads a time bucket from the catalog, pushing down the
+Epoch predicate bounds and an optional row limit, counted from the first
+or last rows of the range, to the IO query
+*/
+func queryTimeBucket(key *io.TimeBucketKey, epochSP *StaticPredicate, limit int,
+	direction io.DirectionEnum) (cs *io.ColumnSeries, err error) {
+	q := planner.NewQuery(executor.ThisInstance.CatalogDir)
+	q.AddTargetKey(key)
+
+	/*
+		Search for time/Epoch predicates and push them down to the IO query
+	*/
+	if sp := epochSP; sp != nil {
+		start, end, hasStart, hasEnd, err := sp.EpochRange()
+		if err != nil {
+			return nil, err
+		}
+		if hasStart {
+			q.SetStart(start)
+		}
+		if hasEnd {
+			q.SetEnd(end)
+		}
+		if sp.ContentsEnum.IsSet(INLIST) {
+			allowed := make(map[int64]bool, len(sp.inlist))
+			for _, item := range sp.inlist {
+				val, _ := io.GetValueAsInt64(item)
+				allowed[val] = true
+			}
+			q.AddTimeQual(func(epoch int64) bool {
+				return allowed[epoch]
+			})
+		}
+	}
+	if limit != 0 {
+		q.SetRowLimit(direction, limit)
+	}
+
+	parsed, err := q.Parse()
+	if err != nil {
+		return nil, err
+	}
+	scanner, err := executor.NewReader(parsed)
+	if err != nil {
+		return nil, err
+	}
+	csm, err := scanner.Read()
+	if err != nil {
+		return nil, err
+	}
+	if len(csm) == 0 {
+		return nil, fmt.Errorf("No results returned from query")
+	}
+	if isMultiSymbol(key) {
+		return CombineBySymbol(csm), nil
+	}
+	return csm[*key], nil
+}
+
+/*
+tableDataShapes returns the columns of a table, including the Symbol column
+added when the table names several symbols
+*/
+func tableDataShapes(key *io.TimeBucketKey) (dsv []io.DataShape, err error) {
+	d := executor.ThisInstance.CatalogDir
+	symbols := key.GetMultiItemInCategory("Symbol")
+	if len(symbols) <= 1 {
+		return d.GetDataShapes(key)
+	}
+	first := io.NewTimeBucketKey(key.GetItemKey(), key.GetCatKey())
+	first.SetItemInCategory("Symbol", symbols[0])
+	dsv, err = d.GetDataShapes(first)
+	if err != nil {
+		return nil, err
+	}
+	return append(dsv, io.DataShape{Name: "Symbol", Type: io.STRING}), nil
+}
+
+func (sr *SelectRelation) hasFunctionCalls() bool {
+	if sr.IsSelectAll {
+		return false
+	}
+	for _, sl := range sr.SelectList {
+		if sl.IsFunctionCall {
+			return true
+		}
+	}
+	return false
+}
+
+func (sr *SelectRelation) hasExpressions() bool {
+	if sr.IsSelectAll {
+		return false
+	}
+	for _, sl := range sr.SelectList {
+		if sl.IsExpression {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+evaluateExpressions adds a column to the series for each expression in the
+select list and returns the projection of the select list, with the names
+the expression columns were added under
+*/
+func (sr *SelectRelation) evaluateExpressions(cs *io.ColumnSeries) (projection []string, err error) {
+	for _, sl := range sr.SelectList {
+		if !sl.IsExpression {
+			projection = append(projection, sl.PrimaryName)
+			continue
+		}
+		value, err := sl.RuntimeExpression.Evaluate(cs)
+		if err != nil {
+			return nil, err
+		}
+		if literal, ok := value.(*Literal); ok {
+			if value, err = literalColumn(literal, cs.Len()); err != nil {
+				return nil, err
+			}
+		}
+		projection = append(projection, cs.AddColumn(sl.Alias, value))
+	}
+	return projection, nil
+}
+
+func (sr *SelectRelation) groupsBySymbol() bool {
+	for _, gk := range sr.GroupBy {
+		if gk.Name == "Symbol" {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+newAggregate creates and initializes the UDA for a function call in the
+select list
+*/
+func (sr *SelectRelation) newAggregate(sl *AliasedIdentifier) (aggfunc uda.AggInterface, err error) {
+	aggName := sl.FunctionCall.Name
+	agg := AggRegistry[strings.ToLower(aggName)]
+	if agg == nil {
+		return nil, fmt.Errorf("No function in the UDA Registry named \"%s\"", aggName)
+	}
+	aggfunc, argMap := agg.New()
+
+	if sl.FunctionCall.IsAsterisk {
+		/*
+			If an asterisk is provided, use Epoch as the mapped input column
+		*/
+		argMap.MapRequiredColumn("*", io.DataShape{
+			Name: "Epoch", Type: io.INT64,
+		})
+	} else {
+		idList := sl.FunctionCall.GetIDs()
+		err = argMap.PrepareArguments(idList)
+		if err != nil {
+			return nil, fmt.Errorf("Argument mapping error for %s: %s", aggName, err.Error())
+		}
+	}
+
+	/*
+		Initialize the Aggregate
+			An agg may have init parameters, which are used only to initialize it
+			These are single value literals (like '1Min')
+	*/
+	requiredInitDSV := aggfunc.GetInitArgs()
+	requiredInitNames := io.GetNamesFromDSV(requiredInitDSV)
+
+	initList := sl.FunctionCall.GetLiterals()
+	if len(requiredInitNames) > len(initList) {
+		return nil, fmt.Errorf(
+			"Not enough init arguments for %s, need %d have %d",
+			aggName,
+			len(requiredInitNames),
+			len(initList),
+		)
+	}
+	// TODO: Handle different argument types from string
+	var initArgList []string
+	for _, lit := range initList {
+		value := lit.Value.(string)
+		value = value[1 : len(value)-1] // Strip the quotes
+		initArgList = append(
+			initArgList,
+			value,
+		)
+	}
+	if err = aggfunc.Init(initArgList); err != nil {
+		return nil, fmt.Errorf("%s: %s", aggName, err.Error())
+	}
+	return aggfunc, nil
+}
+
+/*
+resolveOrderByAliases maps ORDER BY names that are select list aliases to
+the source column names
+*/
+func (sr *SelectRelation) resolveOrderByAliases() (orderBy []SortItem) {
+	for _, item := range sr.OrderBy {
+		for _, sl := range sr.SelectList {
+			if sl.IsAliased && sl.IsPrimary && sl.Alias == item.Name {
+				item.Name = sl.PrimaryName
+				break
+			}
+		}
+		orderBy = append(orderBy, item)
+	}
+	return orderBy
+}
+
+/*
+UnqualifyColumns strips the "alias." qualifier from the column names
+referenced in the select list and predicates
+*/
+func (sr *SelectRelation) UnqualifyColumns(alias string) {
+	prefix := alias + "."
+	sr.mapColumns(func(name string) string {
+		return strings.TrimPrefix(name, prefix)
+	})
+}
+
+/*
+QualifyJoinColumns qualifies the unqualified column names referenced in the
+select list and predicates with the relation of the only joined column of
+that name, given the columns of the join. The select list keeps the
+unqualified names as output names.
+*/
+func (sr *SelectRelation) QualifyJoinColumns(columns []string) (err error) {
+	have := make(map[string]bool, len(columns))
+	for _, name := range columns {
+		have[name] = true
+	}
+	qualify := func(name string) string {
+		if have[name] || strings.Contains(name, ".") {
+			return name
+		}
+		var candidates []string
+		for _, column := range columns {
+			if strings.HasSuffix(column, "."+name) {
+				candidates = append(candidates, column)
+			}
+		}
+		switch {
+		case len(candidates) == 1:
+			return candidates[0]
+		case len(candidates) > 1 && err == nil:
+			err = fmt.Errorf("Column %s is ambiguous in join, qualify it as one of: %s",
+				name, strings.Join(candidates, ", "))
+		}
+		return name
+	}
+	for _, ai := range sr.SelectList {
+		if ai.IsPrimary && !ai.IsAliased {
+			if name := qualify(ai.PrimaryName); name != ai.PrimaryName {
+				ai.IsAliased = true
+				ai.Alias = ai.PrimaryName
+			}
+		}
+	}
+	sr.mapColumns(qualify)
+	return err
+}
+
+// mapColumns renames the columns referenced in the select list and predicates
+func (sr *SelectRelation) mapColumns(mapName func(string) string) {
+	for _, ai := range sr.SelectList {
+		ai.PrimaryName = mapName(ai.PrimaryName)
+		if ai.IsExpression {
+			ai.RuntimeExpression.mapColumns(mapName)
+		}
+		if ai.IsFunctionCall {
+			for _, i_arg := range ai.FunctionCall.Args {
+				if arg, ok := i_arg.(*AliasedIdentifier); ok {
+					arg.PrimaryName = mapName(arg.PrimaryName)
+				}
+			}
+		}
+	}
+	spg := NewStaticPredicateGroup()
+	for name, sp := range sr.StaticPredicates {
+		name = mapName(name)
+		sp.Column.Value.PrimaryName = name
+		if _, ok := spg[name]; ok {
+			spg.Merge(sp, false)
+		} else {
+			spg[name] = sp
+		}
+	}
+	sr.StaticPredicates = spg
+	for _, gk := range sr.GroupBy {
+		gk.Name = mapName(gk.Name)
+	}
+	if sr.RuntimePredicate != nil {
+		sr.RuntimePredicate.mapColumns(mapName)
+	}
+	for i := range sr.OrderBy {
+		sr.OrderBy[i].Name = mapName(sr.OrderBy[i].Name)
+	}
+}
+
 func (sr *SelectRelation) Explain() string {
 	if sr != nil {
 		jsonStruct, _ := json.Marshal(*sr)
@@ -600,18 +721,14 @@ func (spg StaticPredicateGroup) Merge(sp *StaticPredicate, IsOr bool) error {
 	}
 	tgtSP := spg.Add(sp.Column) // Adds a new SP if not already there
 	if sp.ContentsEnum.IsSet(MINBOUND) {
-		tgtSP.ContentsEnum.AddOption(MINBOUND)
 		if sp.ContentsEnum.IsSet(INCLUSIVEMIN) {
-			tgtSP.ContentsEnum.AddOption(INCLUSIVEMIN)
 			tgtSP.AddComparison(io.GTE, sp.min)
 		} else {
 			tgtSP.AddComparison(io.GT, sp.min)
 		}
 	}
 	if sp.ContentsEnum.IsSet(MAXBOUND) {
-		tgtSP.ContentsEnum.AddOption(MAXBOUND)
 		if sp.ContentsEnum.IsSet(INCLUSIVEMAX) {
-			tgtSP.ContentsEnum.AddOption(INCLUSIVEMAX)
 			tgtSP.AddComparison(io.LTE, sp.max)
 		} else {
 			tgtSP.AddComparison(io.LT, sp.max)
@@ -626,14 +743,219 @@ func (spg StaticPredicateGroup) Merge(sp *StaticPredicate, IsOr bool) error {
 		tgtSP.SetLike(sp.likePattern, sp.likeEsc)
 	}
 	if sp.ContentsEnum.IsSet(INLIST) {
-		tgtSP.ContentsEnum.AddOption(INLIST)
-		for _, item := range sp.inlist {
-			tgtSP.inlist = append(tgtSP.inlist, item)
-		}
+		tgtSP.AddInlist(sp.inlist)
 	}
 	return nil
 }
 
+/*
+Apply removes the rows of the column series that do not satisfy the
+predicates in the group
+*/
+func (spg StaticPredicateGroup) Apply(cs *io.ColumnSeries) {
+	if cs.Len() == 0 {
+		return
+	}
+	totalLength := cs.Len()
+	removalBitmap := make([]bool, totalLength, totalLength) // true means we ditch the value, default is keep
+	for _, name := range cs.GetColumnNames() {
+		if sp, ok := spg[name]; ok {
+			i_col := cs.GetColumn(name)
+			if sp.ContentsEnum.IsSet(INLIST) {
+				sp.applyInlist(i_col, removalBitmap)
+			}
+			switch col := i_col.(type) {
+			case []float32:
+				if sp.ContentsEnum.IsSet(EQUALITY) {
+					eqval, _ := io.GetValueAsFloat64(sp.equal)
+					for i, val := range col {
+						if val != float32(eqval) {
+							removalBitmap[i] = true // remove
+						}
+					}
+				}
+				if sp.ContentsEnum.IsSet(MINBOUND) {
+					minval, _ := io.GetValueAsFloat64(sp.min)
+					for i, val := range col {
+						if sp.ContentsEnum.IsSet(INCLUSIVEMIN) {
+							if val < float32(minval) {
+								removalBitmap[i] = true // remove
+							}
+						} else {
+							if val <= float32(minval) {
+								removalBitmap[i] = true // remove
+							}
+						}
+					}
+				}
+				if sp.ContentsEnum.IsSet(MAXBOUND) {
+					maxval, _ := io.GetValueAsFloat64(sp.max)
+					for i, val := range col {
+						if sp.ContentsEnum.IsSet(INCLUSIVEMAX) {
+							if val > float32(maxval) {
+								removalBitmap[i] = true // remove
+							}
+						} else {
+							if val >= float32(maxval) {
+								removalBitmap[i] = true // remove
+							}
+						}
+					}
+				}
+			case []float64:
+				if sp.ContentsEnum.IsSet(EQUALITY) {
+					eqval, _ := io.GetValueAsFloat64(sp.equal)
+					for i, val := range col {
+						if val != eqval {
+							removalBitmap[i] = true // remove
+						}
+					}
+				}
+				if sp.ContentsEnum.IsSet(MINBOUND) {
+					minval, _ := io.GetValueAsFloat64(sp.min)
+					for i, val := range col {
+						if sp.ContentsEnum.IsSet(INCLUSIVEMIN) {
+							if val < minval {
+								removalBitmap[i] = true // remove
+							}
+						} else {
+							if val <= minval {
+								removalBitmap[i] = true // remove
+							}
+						}
+					}
+				}
+				if sp.ContentsEnum.IsSet(MAXBOUND) {
+					maxval, _ := io.GetValueAsFloat64(sp.max)
+					for i, val := range col {
+						if sp.ContentsEnum.IsSet(INCLUSIVEMAX) {
+							if val > maxval {
+								removalBitmap[i] = true // remove
+							}
+						} else {
+							if val >= maxval {
+								removalBitmap[i] = true // remove
+							}
+						}
+					}
+				}
+			case []int:
+				if sp.ContentsEnum.IsSet(EQUALITY) {
+					eqval, _ := io.GetValueAsInt64(sp.equal)
+					for i, val := range col {
+						if val != int(eqval) {
+							removalBitmap[i] = true // remove
+						}
+					}
+				}
+				if sp.ContentsEnum.IsSet(MINBOUND) {
+					minval, _ := io.GetValueAsInt64(sp.min)
+					for i, val := range col {
+						if sp.ContentsEnum.IsSet(INCLUSIVEMIN) {
+							if val < int(minval) {
+								removalBitmap[i] = true // remove
+							}
+						} else {
+							if val <= int(minval) {
+								removalBitmap[i] = true // remove
+							}
+						}
+					}
+				}
+				if sp.ContentsEnum.IsSet(MAXBOUND) {
+					maxval, _ := io.GetValueAsInt64(sp.max)
+					for i, val := range col {
+						if sp.ContentsEnum.IsSet(INCLUSIVEMAX) {
+							if val > int(maxval) {
+								removalBitmap[i] = true // remove
+							}
+						} else {
+							if val >= int(maxval) {
+								removalBitmap[i] = true // remove
+							}
+						}
+					}
+				}
+			case []int32:
+				if sp.ContentsEnum.IsSet(EQUALITY) {
+					eqval, _ := io.GetValueAsInt64(sp.equal)
+					for i, val := range col {
+						if val != int32(eqval) {
+							removalBitmap[i] = true // remove
+						}
+					}
+				}
+				if sp.ContentsEnum.IsSet(MINBOUND) {
+					minval, _ := io.GetValueAsInt64(sp.min)
+					for i, val := range col {
+						if sp.ContentsEnum.IsSet(INCLUSIVEMIN) {
+							if val < int32(minval) {
+								removalBitmap[i] = true // remove
+							}
+						} else {
+							if val <= int32(minval) {
+								removalBitmap[i] = true // remove
+							}
+						}
+					}
+				}
+				if sp.ContentsEnum.IsSet(MAXBOUND) {
+					maxval, _ := io.GetValueAsInt64(sp.max)
+					for i, val := range col {
+						if sp.ContentsEnum.IsSet(INCLUSIVEMAX) {
+							if val > int32(maxval) {
+								removalBitmap[i] = true // remove
+							}
+						} else {
+							if val >= int32(maxval) {
+								removalBitmap[i] = true // remove
+							}
+						}
+					}
+				}
+			case []int64:
+				if sp.ContentsEnum.IsSet(EQUALITY) {
+					eqval, _ := io.GetValueAsInt64(sp.equal)
+					for i, val := range col {
+						if val != eqval {
+							removalBitmap[i] = true // remove
+						}
+					}
+				}
+				if sp.ContentsEnum.IsSet(MINBOUND) {
+					minval, _ := io.GetValueAsInt64(sp.min)
+					for i, val := range col {
+						if sp.ContentsEnum.IsSet(INCLUSIVEMIN) {
+							if val < minval {
+								removalBitmap[i] = true // remove
+							}
+						} else {
+							if val <= minval {
+								removalBitmap[i] = true // remove
+							}
+						}
+					}
+				}
+				if sp.ContentsEnum.IsSet(MAXBOUND) {
+					maxval, _ := io.GetValueAsInt64(sp.max)
+					for i, val := range col {
+						if sp.ContentsEnum.IsSet(INCLUSIVEMAX) {
+							if val > maxval {
+								removalBitmap[i] = true // remove
+							}
+						} else {
+							if val >= maxval {
+								removalBitmap[i] = true // remove
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	cs.RestrictViaBitmap(removalBitmap)
+}
+
 type StaticPredicate struct {
 	/*
 		This stores the right hand side of an evaluation such as:
@@ -656,6 +978,13 @@ func (sp *StaticPredicate) IsFalse() bool {
 	/*
 		If this predicate is provably false internally, return true
 	*/
+	if sp.ContentsEnum.IsSet(INLIST) && len(sp.inlist) == 0 {
+		return true
+	}
+	if !sp.ContentsEnum.IsSet(INCLUSIVEMIN, INCLUSIVEMAX) {
+		match, _ := io.GenericComparison(sp.min, sp.max, io.GTE)
+		return match
+	}
 	match, _ := io.GenericComparison(sp.min, sp.max, io.GT)
 	return match
 }
@@ -665,6 +994,8 @@ func (sp *StaticPredicate) SetMin(newMin interface{}, inclusive bool) {
 	sp.ContentsEnum.AddOption(MINBOUND)
 	if inclusive {
 		sp.ContentsEnum.AddOption(INCLUSIVEMIN)
+	} else {
+		sp.ContentsEnum.DelOption(INCLUSIVEMIN)
 	}
 
 }
@@ -673,6 +1004,8 @@ func (sp *StaticPredicate) SetMax(newMax interface{}, inclusive bool) {
 	sp.ContentsEnum.AddOption(MAXBOUND)
 	if inclusive {
 		sp.ContentsEnum.AddOption(INCLUSIVEMAX)
+	} else {
+		sp.ContentsEnum.DelOption(INCLUSIVEMAX)
 	}
 }
 func (sp *StaticPredicate) SetEqual(newEQ interface{}) {
@@ -689,6 +1022,120 @@ func (sp *StaticPredicate) SetInlist(inlist []interface{}) {
 	sp.ContentsEnum.AddOption(INLIST)
 }
 
+/*
+AddInlist sets the list of allowed values, or intersects it with the
+existing list as both must hold
+*/
+func (sp *StaticPredicate) AddInlist(inlist []interface{}) {
+	if !sp.ContentsEnum.IsSet(INLIST) {
+		sp.SetInlist(inlist)
+		return
+	}
+	var common []interface{}
+	for _, item := range sp.inlist {
+		for _, other := range inlist {
+			if match, _ := io.GenericComparison(item, other, io.EQ); match {
+				common = append(common, item)
+				break
+			}
+		}
+	}
+	sp.SetInlist(common)
+}
+
+func (sp *StaticPredicate) applyInlist(i_col interface{}, removalBitmap []bool) {
+	switch col := i_col.(type) {
+	case []int64:
+		allowed := make(map[int64]bool, len(sp.inlist))
+		for _, item := range sp.inlist {
+			if val, err := io.GetValueAsInt64(item); err == nil {
+				allowed[val] = true
+			}
+		}
+		for i, val := range col {
+			if !allowed[val] {
+				removalBitmap[i] = true // remove
+			}
+		}
+	default:
+		colValue := reflect.ValueOf(col)
+		allowed := make(map[float64]bool, len(sp.inlist))
+		for _, item := range sp.inlist {
+			if val, err := io.GetValueAsFloat64(item); err == nil {
+				allowed[val] = true
+			}
+		}
+		for i := 0; i < colValue.Len(); i++ {
+			val, err := io.GetValueAsFloat64(colValue.Index(i).Interface())
+			if err != nil || !allowed[val] {
+				removalBitmap[i] = true // remove
+			}
+		}
+	}
+}
+
+/*
+EpochRange returns the range of epochs allowed by a predicate on the Epoch
+column, with bounds made inclusive so they can be used as a query range
+*/
+func (sp *StaticPredicate) EpochRange() (start, end int64, hasStart, hasEnd bool, err error) {
+	narrow := func(val int64) {
+		if !hasStart || val > start {
+			start, hasStart = val, true
+		}
+		if !hasEnd || val < end {
+			end, hasEnd = val, true
+		}
+	}
+	if sp.ContentsEnum.IsSet(MINBOUND) {
+		if start, err = io.GetValueAsInt64(sp.min); err != nil {
+			return 0, 0, false, false, fmt.Errorf("Non date predicate found for Epoch")
+		}
+		if !sp.ContentsEnum.IsSet(INCLUSIVEMIN) {
+			start++
+		}
+		hasStart = true
+	}
+	if sp.ContentsEnum.IsSet(MAXBOUND) {
+		if end, err = io.GetValueAsInt64(sp.max); err != nil {
+			return 0, 0, false, false, fmt.Errorf("Non date predicate found for Epoch")
+		}
+		if !sp.ContentsEnum.IsSet(INCLUSIVEMAX) {
+			end--
+		}
+		hasEnd = true
+	}
+	if sp.ContentsEnum.IsSet(EQUALITY) {
+		val, err := io.GetValueAsInt64(sp.equal)
+		if err != nil {
+			return 0, 0, false, false, fmt.Errorf("Non date predicate found for Epoch")
+		}
+		narrow(val)
+	}
+	if sp.ContentsEnum.IsSet(INLIST) && len(sp.inlist) != 0 {
+		var first, last int64
+		for i, item := range sp.inlist {
+			val, err := io.GetValueAsInt64(item)
+			if err != nil {
+				return 0, 0, false, false, fmt.Errorf("Non date predicate found for Epoch")
+			}
+			if i == 0 || val < first {
+				first = val
+			}
+			if i == 0 || val > last {
+				last = val
+			}
+		}
+		if !hasStart || first > start {
+			start, hasStart = first, true
+		}
+		if !hasEnd || last < end {
+			end, hasEnd = last, true
+		}
+	}
+	return start, end, hasStart, hasEnd, nil
+}
+
 type StaticPredicateContentsEnum uint16
 
 const (
@@ -769,10 +1216,10 @@ func (sp *StaticPredicate) AddComparison(op io.ComparisonOperatorEnum,
 }
 
 type AliasedIdentifier struct {
-	IsPrimary, IsAliased, IsFunctionCall bool
-	PrimaryName, Alias                   string
-	RuntimeExpression                    *ExpressionParse
-	FunctionCall                         *FunctionCallReference
+	IsPrimary, IsAliased, IsFunctionCall, IsExpression bool
+	PrimaryName, Alias                                 string
+	RuntimeExpression                                  RuntimeValue
+	FunctionCall                                       *FunctionCallReference
 }
 
 func NewAliasedIdentifier(name ...string) (ai *AliasedIdentifier) {
@@ -784,8 +1231,9 @@ func NewAliasedIdentifier(name ...string) (ai *AliasedIdentifier) {
 	return ai
 }
 
-func (ai *AliasedIdentifier) AddRuntimeExpression(ep *ExpressionParse) {
-	ai.RuntimeExpression = ep
+func (ai *AliasedIdentifier) AddRuntimeExpression(rv RuntimeValue) {
+	ai.RuntimeExpression = rv
+	ai.IsExpression = true
 }
 func (ai *AliasedIdentifier) AddFunctionCall(fc *FunctionCallReference) {
 	ai.FunctionCall = fc
@@ -832,6 +1280,8 @@ func SourceValidator(sourceDSV []io.DataShape, selectList []*AliasedIdentifier)
 					keepList = append(keepList, args[len(args)-1])
 				}
 			}
+		case id.IsExpression:
+			keepList = append(keepList, RuntimeValueColumns(id.RuntimeExpression)...)
 		case id.IsPrimary:
 			keepList = append(keepList, id.PrimaryName)
 		}
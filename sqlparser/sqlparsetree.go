@@ -457,6 +457,7 @@ type QueryNoWithParse struct {
 	queryTerm IMSTree
 	sortItems []IMSTree
 	limit     int
+	offset    int
 }
 
 func NewQueryNoWithParse(node antlr.Tree) (term *QueryNoWithParse) {
@@ -468,7 +469,10 @@ func NewQueryNoWithParse(node antlr.Tree) (term *QueryNoWithParse) {
 			NewSortItemParse(cctx))
 	}
 	if ctx.LIMIT() != nil {
-		term.limit, _ = strconv.Atoi(ctx.INTEGER_VALUE().GetText())
+		term.limit, _ = strconv.Atoi(ctx.GetLimit().GetText())
+	}
+	if ctx.OFFSET() != nil {
+		term.offset, _ = strconv.Atoi(ctx.GetOffset().GetText())
 	}
 	return term
 }
@@ -670,9 +674,8 @@ type GroupingElementParse struct {
 }
 
 func NewGroupingElementParse(node antlr.Tree) (term *GroupingElementParse) {
-	ictx := node.GetChild(0)
 	term = new(GroupingElementParse)
-	switch ctx := ictx.(type) {
+	switch ctx := node.(type) {
 	case *parser.SingleGroupingSetContext:
 		term.groupingExp = NewGroupingExpressionsParse(ctx.GroupingExpressions())
 	case *parser.RollupContext:
@@ -817,7 +820,13 @@ func NewRelationParse(node antlr.Tree) (term *RelationParse) {
 				term.joinType = RIGHT_OUTER
 			case cctx.FULL() != nil:
 				term.joinType = FULL_OUTER
+			case cctx.CROSS() != nil:
+				term.joinType = CROSS
+			case cctx.NATURAL() != nil:
+				term.joinType = NATURAL
 			}
+		} else {
+			term.joinType = INNER
 		}
 	case *parser.RelationDefaultContext:
 		term.sampled = NewSampledRelationParse(ctx.SampledRelation())
@@ -841,7 +850,9 @@ type JoinCriteriaParse struct {
 func NewJoinCriteriaParse(node antlr.Tree) (term *JoinCriteriaParse) {
 	ctx := node.(*parser.JoinCriteriaContext)
 	term = new(JoinCriteriaParse)
-	term.onExpression = NewBooleanExpressionParse(ctx.BooleanExpression())
+	if ctx.BooleanExpression() != nil {
+		term.onExpression = NewBooleanExpressionParse(ctx.BooleanExpression())
+	}
 	for _, cctx := range ctx.AllIdentifier() {
 		term.identifiers = append(term.identifiers, NewIDParse(cctx))
 	}
@@ -1020,15 +1031,14 @@ func NewBooleanExpressionParse(node antlr.Tree) (term *BooleanExpressionParse) {
 	for {
 		switch ctx := node.(type) {
 		case *parser.LogicalNotContext:
-			term.IsNot = true
-			node = node.GetChild(0) // Iterate over child node
+			term.IsNot = !term.IsNot
+			node = ctx.BooleanExpression() // Iterate over child node
 		case *parser.LogicalBinaryContext:
 			term.right = NewExpressionParse(ctx.GetRight())
-			switch ctx.GetOperator().GetText() {
+			switch strings.ToUpper(ctx.GetOperator().GetText()) {
 			case "AND":
 				term.operator = AND_OP
 			case "OR":
-				// TODO: Properly handle OR with quantitative predicates
 				term.operator = OR_OP
 			}
 			term.left = NewBooleanExpressionParse(ctx.GetLeft())
@@ -2081,26 +2091,25 @@ type FrameBoundParse struct {
 
 func NewFrameBoundParse(node antlr.Tree) (term *FrameBoundParse) {
 	term = new(FrameBoundParse)
-	ictx := node.GetChild(0)
-	switch childCtx := ictx.(type) {
+	switch ctx := node.(type) {
 	case *parser.CurrentRowBoundContext:
 		term.IsCurrentRow = true
 	case *parser.UnboundedFrameContext:
 		term.IsUnbounded = true
 		switch {
-		case strings.EqualFold(childCtx.GetBoundType().GetText(), "PRECEDING"):
-			term.IsPreceding = true
-		case strings.EqualFold(childCtx.GetBoundType().GetText(), "FOLLOWING"):
+		case strings.EqualFold(ctx.GetBoundType().GetText(), "PRECEDING"):
 			term.IsPreceding = true
+		case strings.EqualFold(ctx.GetBoundType().GetText(), "FOLLOWING"):
+			term.IsFollowing = true
 		}
 	case *parser.BoundedFrameContext:
 		switch {
-		case strings.EqualFold(childCtx.GetBoundType().GetText(), "PRECEDING"):
-			term.IsPreceding = true
-		case strings.EqualFold(childCtx.GetBoundType().GetText(), "FOLLOWING"):
+		case strings.EqualFold(ctx.GetBoundType().GetText(), "PRECEDING"):
 			term.IsPreceding = true
+		case strings.EqualFold(ctx.GetBoundType().GetText(), "FOLLOWING"):
+			term.IsFollowing = true
 		}
-		term.AddChild(NewExpressionParse(childCtx))
+		term.AddChild(NewExpressionParse(ctx.Expression()))
 	}
 	return term
 }
@@ -2222,6 +2231,8 @@ func NewIDParse(node antlr.Tree) (term *IDParse) {
 		term.name = ctx.BACKQUOTED_IDENTIFIER().GetText()
 		term.name = term.name[1 : len(term.name)-1]
 	case *parser.NonReservedIdentifierContext:
+		// Keywords like INTEGER or DATE can also be identifiers
+		term.name = ctx.GetText()
 		term.AddChild(NewNonReservedParse(ctx.NonReserved()))
 	}
 	return term
@@ -0,0 +1,105 @@
+package sqlparser
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/alpacahq/marketstore/utils/io"
+)
+
+/*
+Tables can name several symbols, as in `AAPL,MSFT/1Min/OHLCV`. The rows of
+all symbols are returned in one column series with an added Symbol column
+*/
+
+/*
+CombineBySymbol concatenates the column series of each key, ordered by
+symbol, and adds a Symbol column holding the symbol of each row
+*/
+func CombineBySymbol(csm io.ColumnSeriesMap) (cs *io.ColumnSeries) {
+	keys := csm.GetMetadataKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].GetItemInCategory("Symbol") < keys[j].GetItemInCategory("Symbol")
+	})
+
+	cs = io.NewColumnSeries()
+	var symbols []string
+	for _, key := range keys {
+		sub := csm[key]
+		symbol := key.GetItemInCategory("Symbol")
+		for i := 0; i < sub.Len(); i++ {
+			symbols = append(symbols, symbol)
+		}
+		for _, name := range sub.GetColumnNames() {
+			col := sub.GetColumn(name)
+			if !cs.Exists(name) {
+				cs.AddColumn(name, reflect.MakeSlice(reflect.TypeOf(col), 0, 0).Interface())
+			}
+			combined := reflect.AppendSlice(reflect.ValueOf(cs.GetColumn(name)), reflect.ValueOf(col))
+			cs.Replace(name, combined.Interface())
+		}
+	}
+	if symbols == nil {
+		symbols = []string{}
+	}
+	cs.AddColumn("Symbol", symbols)
+	return cs
+}
+
+/*
+IndexSymbols replaces the string Symbol column with the index of each row's
+symbol in the returned list of symbols, ordered by first appearance, so that
+the result can be packed as numbers without reordering its rows. If there is
+no Symbol column, ok is false
+*/
+func IndexSymbols(cs *io.ColumnSeries) (symbols []string, indexed *io.ColumnSeries, ok bool) {
+	symbolCol, ok := cs.GetColumn("Symbol").([]string)
+	if !ok {
+		return nil, nil, false
+	}
+	indexBySymbol := make(map[string]int32)
+	indexes := make([]int32, len(symbolCol))
+	for i, symbol := range symbolCol {
+		index, found := indexBySymbol[symbol]
+		if !found {
+			index = int32(len(symbols))
+			indexBySymbol[symbol] = index
+			symbols = append(symbols, symbol)
+		}
+		indexes[i] = index
+	}
+	indexed = io.NewColumnSeries()
+	for _, name := range cs.GetColumnNames() {
+		if name == "Symbol" {
+			indexed.AddColumn(name, indexes)
+			continue
+		}
+		indexed.AddColumn(name, cs.GetColumn(name))
+	}
+	return symbols, indexed, true
+}
+
+/*
+ResolveSymbols is the inverse of IndexSymbols, it replaces the Symbol column
+of indexes with the symbols they refer to
+*/
+func ResolveSymbols(symbols []string, cs *io.ColumnSeries) error {
+	indexes, ok := cs.GetColumn("Symbol").([]int32)
+	if !ok {
+		return fmt.Errorf("Result has no Symbol index column")
+	}
+	symbolCol := make([]string, len(indexes))
+	for i, index := range indexes {
+		if index < 0 || int(index) >= len(symbols) {
+			return fmt.Errorf("Symbol index %d out of range of %d symbols", index, len(symbols))
+		}
+		symbolCol[i] = symbols[index]
+	}
+	cs.Replace("Symbol", symbolCol)
+	return nil
+}
+
+func isMultiSymbol(key *io.TimeBucketKey) bool {
+	return len(key.GetMultiItemInCategory("Symbol")) > 1
+}
@@ -0,0 +1,205 @@
+package sqlparser
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/alpacahq/marketstore/uda"
+	"github.com/alpacahq/marketstore/utils/io"
+)
+
+/*
+WindowFunctionCall is a call to a function in the WindowRegistry with an
+OVER clause:
+
+	LAG(Close, 1) OVER (PARTITION BY Symbol ORDER BY Epoch)
+	AVG(Close) OVER (ROWS 20 PRECEDING)
+
+The rows of each partition are passed to the function in the window order,
+or in the order of the result without ORDER BY. The result has one value per
+row, so window calls can be used in expressions:
+
+	Close / LAG(Close) OVER (ORDER BY Epoch) - 1 AS Return
+*/
+type WindowFunctionCall struct {
+	Name        string
+	Function    uda.WindowInterface
+	Args        []RuntimeValue
+	PartitionBy []RuntimeValue
+	OrderBy     []SortItem
+	Frame       uda.WindowFrame
+}
+
+func (wf *WindowFunctionCall) Evaluate(cs *io.ColumnSeries) (interface{}, error) {
+	/*
+		Columns are mapped as the function inputs, literals are used to
+		initialize it
+	*/
+	input := io.NewColumnSeries()
+	var idList, initArgs []string
+	for i, arg := range wf.Args {
+		value, err := arg.Evaluate(cs)
+		if err != nil {
+			return nil, err
+		}
+		if literal, ok := value.(*Literal); ok {
+			initArgs = append(initArgs, literalString(literal))
+			continue
+		}
+		name := fmt.Sprintf("_arg%d", i)
+		if co, ok := arg.(*ColumnOperand); ok {
+			name = co.Name
+		}
+		idList = append(idList, input.AddColumn(name, value))
+	}
+	function, argMap := wf.Function.New()
+	if err := argMap.PrepareArguments(idList); err != nil {
+		return nil, fmt.Errorf("Argument mapping error for %s: %s", wf.Name, err.Error())
+	}
+	if err := function.Init(stringsToInterfaces(initArgs)...); err != nil {
+		return nil, fmt.Errorf("%s: %s", wf.Name, err.Error())
+	}
+	if cs.Len() == 0 {
+		return []float64{}, nil
+	}
+
+	partitions, err := wf.partitionRows(cs)
+	if err != nil {
+		return nil, err
+	}
+
+	/*
+		Apply the function to each partition and place the results back in
+		the rows of the partition
+	*/
+	var output reflect.Value
+	for _, rows := range partitions {
+		result, err := function.Apply(takeRows(input, rows), wf.Frame)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", wf.Name, err.Error())
+		}
+		if result.GetNumColumns() != 1 || result.Len() != len(rows) {
+			return nil, fmt.Errorf("%s must output one column with a value per row", wf.Name)
+		}
+		column := reflect.ValueOf(result.GetColumn(result.GetColumnNames()[0]))
+		if !output.IsValid() {
+			output = reflect.MakeSlice(column.Type(), cs.Len(), cs.Len())
+		}
+		for i, row := range rows {
+			output.Index(row).Set(column.Index(i))
+		}
+	}
+	return output.Interface(), nil
+}
+
+/*
+partitionRows returns the row indexes of each partition in the window order
+*/
+func (wf *WindowFunctionCall) partitionRows(cs *io.ColumnSeries) (partitions [][]int, err error) {
+	keyColumns := make([]interface{}, len(wf.PartitionBy))
+	for i, rv := range wf.PartitionBy {
+		value, err := rv.Evaluate(cs)
+		if err != nil {
+			return nil, err
+		}
+		if literal, ok := value.(*Literal); ok {
+			if value, err = literalColumn(literal, cs.Len()); err != nil {
+				return nil, err
+			}
+		}
+		keyColumns[i] = value
+	}
+	partitions = groupRows(keyColumns, cs.Len())
+
+	if len(wf.OrderBy) != 0 {
+		keys := make([]reflect.Value, len(wf.OrderBy))
+		for i, item := range wf.OrderBy {
+			col := cs.GetColumn(item.Name)
+			if col == nil {
+				return nil, fmt.Errorf("Window ORDER BY column %s not found", item.Name)
+			}
+			keys[i] = reflect.ValueOf(col)
+		}
+		for _, rows := range partitions {
+			sortRows(rows, keys, wf.OrderBy)
+		}
+	}
+	return partitions, nil
+}
+
+func (wf *WindowFunctionCall) mapColumns(mapName func(string) string) {
+	for _, arg := range wf.Args {
+		arg.mapColumns(mapName)
+	}
+	for _, rv := range wf.PartitionBy {
+		rv.mapColumns(mapName)
+	}
+	for i := range wf.OrderBy {
+		wf.OrderBy[i].Name = mapName(wf.OrderBy[i].Name)
+	}
+}
+
+/*
+hasWindowFunction returns true if a window function is used in a value
+*/
+func hasWindowFunction(rv RuntimeValue) bool {
+	switch value := rv.(type) {
+	case *WindowFunctionCall:
+		return true
+	case *ArithmeticExpression:
+		return hasWindowFunction(value.Left) || hasWindowFunction(value.Right)
+	case *CastExpression:
+		return hasWindowFunction(value.Value)
+	case *ScalarFunctionCall:
+		for _, arg := range value.Args {
+			if hasWindowFunction(arg) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+/*
+NewWindowFunction parses a window function call with its OVER clause and an
+optional alias, as used in the function pipeline of queries:
+
+	avg(Close) OVER (ROWS 19 PRECEDING) AS MA20
+*/
+func NewWindowFunction(call string) (wf *WindowFunctionCall, alias string, err error) {
+	ast, err := NewAstBuilder("SELECT " + call + " FROM t;")
+	if err != nil {
+		return nil, "", err
+	}
+	es, err := NewExecutableStatement(ast.Mtree)
+	if err != nil {
+		return nil, "", err
+	}
+	sr, ok := es.payload.(*SelectRelation)
+	if !ok || len(sr.SelectList) != 1 || !sr.SelectList[0].IsExpression {
+		return nil, "", fmt.Errorf("Unable to parse window function %s", call)
+	}
+	if wf, ok = sr.SelectList[0].RuntimeExpression.(*WindowFunctionCall); !ok {
+		return nil, "", fmt.Errorf("%s is not a window function call", call)
+	}
+	alias = wf.Name
+	if sr.SelectList[0].IsAliased {
+		alias = sr.SelectList[0].Alias
+	}
+	return wf, alias, nil
+}
+
+func literalString(literal *Literal) string {
+	if literal.Type == STRING_LITERAL {
+		value := literal.Value.(string)
+		return value[1 : len(value)-1] // Strip the quotes
+	}
+	return fmt.Sprint(literal.Value)
+}
+
+func stringsToInterfaces(values []string) (out []interface{}) {
+	for _, value := range values {
+		out = append(out, value)
+	}
+	return out
+}
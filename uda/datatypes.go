@@ -51,6 +51,60 @@ type AggInterface interface {
 	Reset()
 }
 
+/*
+A window function computes one output value for each input row, from a
+frame of the rows around it, where an aggregate reduces its input rows. For
+example LAG(Close, 1) outputs the previous Close for each row and a rolling
+average over 20 rows outputs the average of each row and the 19 before it.
+
+The input rows are one partition of the query result, in the window order.
+*/
+type WindowInterface interface {
+	FunctionInterface
+	/*
+		Returns the required arguments with a validator, as in AggInterface
+	*/
+	New() (WindowInterface, *functions.ArgumentMap)
+	/*
+		Input arguments, followed by a custom set of arguments
+	*/
+	Init(args ...interface{}) error
+	/*
+		Apply() returns a column of the same length as the input, computing
+		each row from the rows in its frame
+	*/
+	Apply(cols io.ColumnInterface, frame WindowFrame) (*io.ColumnSeries, error)
+}
+
+/*
+WindowFrame bounds the rows used for each output row, as a number of rows
+preceding and following it. The default frame goes from the first row of
+the partition to the current row
+*/
+type WindowFrame struct {
+	Preceding, Following                   int
+	UnboundedPreceding, UnboundedFollowing bool
+}
+
+func NewDefaultWindowFrame() WindowFrame {
+	return WindowFrame{UnboundedPreceding: true}
+}
+
+/*
+Bounds returns the first and last row, inclusive, of the frame of a row in a
+partition of length rows
+*/
+func (wf WindowFrame) Bounds(row, length int) (first, last int) {
+	first, last = row-wf.Preceding, row+wf.Following
+	if wf.UnboundedPreceding || first < 0 {
+		first = 0
+	}
+	if wf.UnboundedFollowing || last > length-1 {
+		last = length - 1
+	}
+	return first, last
+}
+
 //TODO: This is where we break out a UDF API
 type FunctionInterface interface {
 	GetRequiredArgs() []io.DataShape
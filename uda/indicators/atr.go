@@ -0,0 +1,102 @@
+package indicators
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/alpacahq/marketstore/uda"
+	"github.com/alpacahq/marketstore/utils/functions"
+	"github.com/alpacahq/marketstore/utils/io"
+)
+
+/*
+ATR is Wilder's average true range over 14 periods by default:
+
+	atr('14', High, Low, Close)
+
+The true range is the high minus the low, extended to the previous close
+when it is outside of the range. The first row has no previous close, so
+its true range is the high minus the low
+*/
+type ATR struct {
+	uda.AggInterface
+
+	// Input arguments mapping
+	ArgMap *functions.ArgumentMap
+
+	Period int
+	Input  history
+}
+
+var (
+	atrColumns = []io.DataShape{
+		{Name: "High", Type: io.FLOAT32},
+		{Name: "Low", Type: io.FLOAT32},
+		{Name: "Close", Type: io.FLOAT32},
+	}
+
+	atrInitArgs = []io.DataShape{}
+)
+
+func (a *ATR) GetRequiredArgs() []io.DataShape {
+	return atrColumns
+}
+func (a *ATR) GetOptionalArgs() []io.DataShape {
+	return optionalColumns
+}
+func (a *ATR) GetInitArgs() []io.DataShape {
+	return atrInitArgs
+}
+
+/*
+	Creates a new ATR using the arguments of the specific implementation
+	for inputColumns and optionalInputColumns
+*/
+func (a ATR) New() (out uda.AggInterface, am *functions.ArgumentMap) {
+	ax := &ATR{}
+	ax.ArgMap = functions.NewArgumentMap(atrColumns, optionalColumns...)
+	return ax, ax.ArgMap
+}
+
+func (a *ATR) Init(args ...interface{}) error {
+	if unmapped := a.ArgMap.Validate(); unmapped != nil {
+		return fmt.Errorf("Unmapped columns: %s", unmapped)
+	}
+	a.Reset()
+	periods, err := initPeriods("ATR", args, 14)
+	if err != nil {
+		return err
+	}
+	a.Period = periods[0]
+	return nil
+}
+
+/*
+	Accum() sends new data to the aggregate
+*/
+func (a *ATR) Accum(cols io.ColumnInterface) error {
+	return a.Input.accum(a.ArgMap, cols, "High", "Low", "Close")
+}
+
+/*
+	Output() returns the currently valid output of this aggregate
+*/
+func (a *ATR) Output() *io.ColumnSeries {
+	high, low, close := a.Input.Columns["High"], a.Input.Columns["Low"], a.Input.Columns["Close"]
+	trueRange := make([]float64, len(close))
+	for i := range trueRange {
+		trueRange[i] = high[i] - low[i]
+		if i > 0 {
+			trueRange[i] = math.Max(trueRange[i], math.Abs(high[i]-close[i-1]))
+			trueRange[i] = math.Max(trueRange[i], math.Abs(low[i]-close[i-1]))
+		}
+	}
+	return a.Input.output([]string{"ATR"}, wilder(trueRange, a.Period))
+}
+
+/*
+	Reset() puts the aggregate state back to "new"
+*/
+func (a *ATR) Reset() {
+	a.Input.reset()
+}
@@ -0,0 +1,107 @@
+package indicators
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/alpacahq/marketstore/uda"
+	"github.com/alpacahq/marketstore/utils/functions"
+	"github.com/alpacahq/marketstore/utils/io"
+)
+
+/*
+Bollinger outputs Bollinger bands, a simple moving average with bands a
+number of standard deviations above and below it, 20 periods and 2 standard
+deviations by default:
+
+	bollinger('20', '2', Close)
+
+The output columns are Middle, Upper and Lower. The standard deviation is
+the population standard deviation of the period
+*/
+type Bollinger struct {
+	uda.AggInterface
+
+	// Input arguments mapping
+	ArgMap *functions.ArgumentMap
+
+	Period int
+	Width  float64
+	Input  history
+}
+
+var bollingerInitArgs = []io.DataShape{}
+
+func (b *Bollinger) GetRequiredArgs() []io.DataShape {
+	return priceColumns
+}
+func (b *Bollinger) GetOptionalArgs() []io.DataShape {
+	return optionalColumns
+}
+func (b *Bollinger) GetInitArgs() []io.DataShape {
+	return bollingerInitArgs
+}
+
+/*
+	Creates new Bollinger bands using the arguments of the specific
+	implementation for inputColumns and optionalInputColumns
+*/
+func (b Bollinger) New() (out uda.AggInterface, am *functions.ArgumentMap) {
+	bx := &Bollinger{}
+	bx.ArgMap = functions.NewArgumentMap(priceColumns, optionalColumns...)
+	return bx, bx.ArgMap
+}
+
+func (b *Bollinger) Init(args ...interface{}) error {
+	if unmapped := b.ArgMap.Validate(); unmapped != nil {
+		return fmt.Errorf("Unmapped columns: %s", unmapped)
+	}
+	b.Reset()
+	values, err := uda.InitStrings(args)
+	if err != nil {
+		return err
+	}
+	if len(values) > 2 {
+		return fmt.Errorf("Bollinger takes a period and a width, have %d init arguments", len(values))
+	}
+	b.Width = 2
+	if len(values) == 2 {
+		if b.Width, err = strconv.ParseFloat(values[1], 64); err != nil || b.Width <= 0 {
+			return fmt.Errorf("The Bollinger width must be a positive number, have %s", values[1])
+		}
+		values = values[:1]
+	}
+	periods, err := initPeriods("Bollinger", []interface{}{values}, 20)
+	if err != nil {
+		return err
+	}
+	b.Period = periods[0]
+	return nil
+}
+
+/*
+	Accum() sends new data to the aggregate
+*/
+func (b *Bollinger) Accum(cols io.ColumnInterface) error {
+	return b.Input.accum(b.ArgMap, cols, "*")
+}
+
+/*
+	Output() returns the currently valid output of this aggregate
+*/
+func (b *Bollinger) Output() *io.ColumnSeries {
+	middle, stddev := rollingMeanStdDev(b.Input.Columns["*"], b.Period)
+	upper, lower := make([]float64, len(middle)), make([]float64, len(middle))
+	for i := range middle {
+		upper[i] = middle[i] + b.Width*stddev[i]
+		lower[i] = middle[i] - b.Width*stddev[i]
+	}
+	return b.Input.output([]string{"Middle", "Upper", "Lower"}, middle, upper, lower)
+}
+
+/*
+	Reset() puts the aggregate state back to "new"
+*/
+func (b *Bollinger) Reset() {
+	b.Input.reset()
+}
@@ -0,0 +1,84 @@
+package indicators
+
+import (
+	"fmt"
+
+	"github.com/alpacahq/marketstore/uda"
+	"github.com/alpacahq/marketstore/utils/functions"
+	"github.com/alpacahq/marketstore/utils/io"
+)
+
+/*
+EMA is the exponential moving average of a column over a number of periods:
+
+	ema('20', Close)
+*/
+type EMA struct {
+	uda.AggInterface
+
+	// Input arguments mapping
+	ArgMap *functions.ArgumentMap
+
+	Period int
+	Input  history
+}
+
+var emaInitArgs = []io.DataShape{
+	{Name: "Period", Type: io.INT64},
+}
+
+func (e *EMA) GetRequiredArgs() []io.DataShape {
+	return priceColumns
+}
+func (e *EMA) GetOptionalArgs() []io.DataShape {
+	return optionalColumns
+}
+func (e *EMA) GetInitArgs() []io.DataShape {
+	return emaInitArgs
+}
+
+/*
+	Creates a new EMA using the arguments of the specific implementation
+	for inputColumns and optionalInputColumns
+*/
+func (e EMA) New() (out uda.AggInterface, am *functions.ArgumentMap) {
+	ex := &EMA{}
+	ex.ArgMap = functions.NewArgumentMap(priceColumns, optionalColumns...)
+	return ex, ex.ArgMap
+}
+
+func (e *EMA) Init(args ...interface{}) error {
+	if unmapped := e.ArgMap.Validate(); unmapped != nil {
+		return fmt.Errorf("Unmapped columns: %s", unmapped)
+	}
+	e.Reset()
+	periods, err := initPeriods("EMA", args, 0)
+	if err != nil {
+		return err
+	}
+	if e.Period = periods[0]; e.Period == 0 {
+		return fmt.Errorf("EMA needs a period")
+	}
+	return nil
+}
+
+/*
+	Accum() sends new data to the aggregate
+*/
+func (e *EMA) Accum(cols io.ColumnInterface) error {
+	return e.Input.accum(e.ArgMap, cols, "*")
+}
+
+/*
+	Output() returns the currently valid output of this aggregate
+*/
+func (e *EMA) Output() *io.ColumnSeries {
+	return e.Input.output([]string{"EMA"}, ema(e.Input.Columns["*"], e.Period))
+}
+
+/*
+	Reset() puts the aggregate state back to "new"
+*/
+func (e *EMA) Reset() {
+	e.Input.reset()
+}
@@ -0,0 +1,179 @@
+package indicators
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/alpacahq/marketstore/uda"
+	"github.com/alpacahq/marketstore/utils/functions"
+	"github.com/alpacahq/marketstore/utils/io"
+)
+
+/*
+Technical indicators computed over the rows passed to Accum, in time order
+
+Each indicator outputs one row per input row, with the input Epoch when there
+is one. Rows before the indicator has enough history, like the first 13 rows
+of a 14 period EMA, are NaN. The periods are init arguments, as in:
+
+	rsi('14', Close)
+	macd('12', '26', '9', Close)
+	SELECT Epoch, bollinger('20', '2', Close) FROM `AAPL/1Min/OHLCV`;
+*/
+
+var (
+	priceColumns = []io.DataShape{
+		{Name: "*", Type: io.FLOAT32},
+	}
+
+	optionalColumns = []io.DataShape{}
+)
+
+/*
+history holds the Epoch and the mapped input columns of all the rows passed
+to Accum
+*/
+type history struct {
+	Epoch   []int64
+	Columns map[string][]float64
+}
+
+func (h *history) accum(am *functions.ArgumentMap, cols io.ColumnInterface, names ...string) error {
+	if cols.Len() == 0 {
+		return nil
+	}
+	if h.Columns == nil {
+		h.Columns = make(map[string][]float64)
+	}
+	for _, name := range names {
+		mapped := am.GetMappedColumns(name)
+		if len(mapped) == 0 {
+			return fmt.Errorf("Unmapped input column %s", name)
+		}
+		values, err := uda.ColumnToFloat64(cols, mapped[0].Name)
+		if err != nil {
+			return err
+		}
+		if values == nil {
+			return fmt.Errorf("Column %s is not numeric", mapped[0].Name)
+		}
+		h.Columns[name] = append(h.Columns[name], values...)
+	}
+	if epoch, ok := cols.GetColumn("Epoch").([]int64); ok {
+		h.Epoch = append(h.Epoch, epoch...)
+	}
+	return nil
+}
+
+func (h *history) reset() {
+	h.Epoch = nil
+	h.Columns = nil
+}
+
+/*
+output returns the Epoch of the input rows followed by the named columns
+*/
+func (h *history) output(names []string, columns ...[]float64) *io.ColumnSeries {
+	cs := io.NewColumnSeries()
+	if h.Epoch != nil {
+		cs.AddColumn("Epoch", h.Epoch)
+	}
+	for i, name := range names {
+		cs.AddColumn(name, columns[i])
+	}
+	return cs
+}
+
+/*
+initPeriods parses the init arguments as positive integers, using the
+defaults for the ones not passed
+*/
+func initPeriods(name string, args []interface{}, defaults ...int) (periods []int, err error) {
+	values, err := uda.InitStrings(args)
+	if err != nil {
+		return nil, err
+	}
+	if len(values) > len(defaults) {
+		return nil, fmt.Errorf("%s takes at most %d init arguments, have %d", name, len(defaults), len(values))
+	}
+	periods = append(periods, defaults...)
+	for i, value := range values {
+		if periods[i], err = strconv.Atoi(value); err != nil || periods[i] <= 0 {
+			return nil, fmt.Errorf("The %s period must be a positive integer, have %s", name, value)
+		}
+	}
+	return periods, nil
+}
+
+func nans(n int) []float64 {
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = math.NaN()
+	}
+	return values
+}
+
+/*
+ema computes the exponential moving average with a smoothing factor of
+2/(period+1), starting from the average of the first period values. Leading
+NaN values are skipped, so that an EMA can be taken of another indicator
+*/
+func ema(values []float64, period int) []float64 {
+	return smoothed(values, period, 2/float64(period+1))
+}
+
+/*
+wilder computes Wilder's moving average, used by RSI and ATR, which is an EMA
+with a smoothing factor of 1/period
+*/
+func wilder(values []float64, period int) []float64 {
+	return smoothed(values, period, 1/float64(period))
+}
+
+func smoothed(values []float64, period int, alpha float64) []float64 {
+	output := nans(len(values))
+	start := 0
+	for start < len(values) && math.IsNaN(values[start]) {
+		start++
+	}
+	if len(values)-start < period {
+		return output
+	}
+	var sum float64
+	for _, value := range values[start : start+period] {
+		sum += value
+	}
+	previous := sum / float64(period)
+	output[start+period-1] = previous
+	for i := start + period; i < len(values); i++ {
+		previous = alpha*values[i] + (1-alpha)*previous
+		output[i] = previous
+	}
+	return output
+}
+
+/*
+rollingMeanStdDev computes the simple moving average and the population
+standard deviation of each row and the period-1 rows before it. The
+deviations are summed per window, as running sums of squares lose precision
+on prices with a small variance
+*/
+func rollingMeanStdDev(values []float64, period int) (means, stddevs []float64) {
+	means, stddevs = nans(len(values)), nans(len(values))
+	for i := period - 1; i < len(values); i++ {
+		window := values[i-period+1 : i+1]
+		var sum float64
+		for _, value := range window {
+			sum += value
+		}
+		mean := sum / float64(period)
+		var squares float64
+		for _, value := range window {
+			squares += (value - mean) * (value - mean)
+		}
+		means[i] = mean
+		stddevs[i] = math.Sqrt(squares / float64(period))
+	}
+	return means, stddevs
+}
@@ -0,0 +1,200 @@
+package indicators
+
+import (
+	"math"
+	"testing"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/alpacahq/marketstore/uda"
+	"github.com/alpacahq/marketstore/utils/io"
+)
+
+// Hook up gocheck into the "go test" runner.
+func Test(t *testing.T) { TestingT(t) }
+
+var _ = Suite(&TestSuite{})
+
+type TestSuite struct{}
+
+/*
+The closes of the StockCharts RSI example, with the 14 period RSI computed
+without rounding the averages, as TA-Lib does
+*/
+var (
+	referenceCloses = []float64{
+		44.34, 44.09, 44.15, 43.61, 44.33, 44.83, 45.10, 45.42, 45.84, 46.08,
+		45.89, 46.03, 45.61, 46.28, 46.28, 46.00, 46.03, 46.41, 46.22, 45.64,
+		46.21, 46.25, 45.71, 46.45, 45.78, 45.35, 44.03, 44.18, 44.22, 44.57,
+		43.42, 42.66, 43.13,
+	}
+	referenceRSI = []float64{
+		70.46, 66.25, 66.48, 69.35, 66.29, 57.92, 62.88, 63.21, 56.01, 62.34,
+		54.67, 50.39, 40.02, 41.49, 41.90, 45.50, 37.32, 33.09, 37.79,
+	}
+)
+
+func run(c *C, agg uda.AggInterface, cs *io.ColumnSeries, inputs []string, args ...interface{}) *io.ColumnSeries {
+	aggfunc, am := agg.New()
+	c.Assert(am.PrepareArguments(inputs), IsNil)
+	c.Assert(aggfunc.Init(args...), IsNil)
+	c.Assert(aggfunc.Accum(cs), IsNil)
+	return aggfunc.Output()
+}
+
+func series(names []string, columns ...[]float64) *io.ColumnSeries {
+	cs := io.NewColumnSeries()
+	epoch := make([]int64, len(columns[0]))
+	for i := range epoch {
+		epoch[i] = int64(i * 60)
+	}
+	cs.AddColumn("Epoch", epoch)
+	for i, name := range names {
+		cs.AddColumn(name, columns[i])
+	}
+	return cs
+}
+
+func linear(n int) []float64 {
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = float64(i)
+	}
+	return values
+}
+
+func assertNaN(c *C, values []float64, n int) {
+	for _, value := range values[:n] {
+		c.Assert(math.IsNaN(value), Equals, true)
+	}
+}
+
+func assertClose(c *C, have, want, tolerance float64) {
+	c.Assert(math.Abs(have-want) <= tolerance, Equals, true, Commentf("have %v, want %v", have, want))
+}
+
+func (s *TestSuite) TestEMA(c *C) {
+	cs := series([]string{"Close"}, []float64{1, 2, 3, 4, 5, 6})
+	out := run(c, &EMA{}, cs, []string{"Close"}, "3")
+	c.Assert(out.GetColumn("Epoch"), DeepEquals, cs.GetColumn("Epoch"))
+	values := out.GetColumn("EMA").([]float64)
+	assertNaN(c, values, 2)
+	c.Assert(values[2:], DeepEquals, []float64{2, 3, 4, 5})
+
+	// The EMA of a line lags it by (period-1)/2
+	cs = series([]string{"Close"}, linear(100))
+	values = run(c, &EMA{}, cs, []string{"Close"}, []string{"20"}).GetColumn("EMA").([]float64)
+	assertNaN(c, values, 19)
+	for i := 19; i < len(values); i++ {
+		assertClose(c, values[i], float64(i)-9.5, 1e-9)
+	}
+
+	aggfunc, am := EMA{}.New()
+	c.Assert(am.PrepareArguments([]string{"Close"}), IsNil)
+	c.Assert(aggfunc.Init(), NotNil)
+	c.Assert(aggfunc.Init("0"), NotNil)
+	c.Assert(aggfunc.Init("x"), NotNil)
+}
+
+func (s *TestSuite) TestRSI(c *C) {
+	cs := series([]string{"Close"}, referenceCloses)
+	values := run(c, &RSI{}, cs, []string{"Close"}).GetColumn("RSI").([]float64)
+	c.Assert(len(values), Equals, len(referenceCloses))
+	assertNaN(c, values, 14)
+	for i, want := range referenceRSI {
+		assertClose(c, values[i+14], want, 0.005)
+	}
+
+	// Only gains
+	cs = series([]string{"Close"}, linear(10))
+	values = run(c, &RSI{}, cs, []string{"Close"}, "5").GetColumn("RSI").([]float64)
+	assertNaN(c, values, 5)
+	for _, value := range values[5:] {
+		c.Assert(value, Equals, 100.0)
+	}
+}
+
+func (s *TestSuite) TestMACD(c *C) {
+	// The fast and slow EMAs of a line lag it by 5.5 and 12.5
+	cs := series([]string{"Close"}, linear(60))
+	out := run(c, &MACD{}, cs, []string{"Close"})
+	macd := out.GetColumn("MACD").([]float64)
+	signal := out.GetColumn("Signal").([]float64)
+	histogram := out.GetColumn("Histogram").([]float64)
+	assertNaN(c, macd, 25)
+	assertNaN(c, signal, 33)
+	for i := 25; i < len(macd); i++ {
+		assertClose(c, macd[i], 7, 1e-9)
+	}
+	for i := 33; i < len(macd); i++ {
+		assertClose(c, signal[i], 7, 1e-9)
+		assertClose(c, histogram[i], 0, 1e-9)
+	}
+
+	aggfunc, am := MACD{}.New()
+	c.Assert(am.PrepareArguments([]string{"Close"}), IsNil)
+	c.Assert(aggfunc.Init("26", "12"), NotNil)
+}
+
+func (s *TestSuite) TestBollinger(c *C) {
+	// The standard deviation of n consecutive integers is sqrt((n*n-1)/12)
+	cs := series([]string{"Close"}, linear(30))
+	out := run(c, &Bollinger{}, cs, []string{"Close"})
+	middle := out.GetColumn("Middle").([]float64)
+	upper := out.GetColumn("Upper").([]float64)
+	lower := out.GetColumn("Lower").([]float64)
+	assertNaN(c, middle, 19)
+	width := 2 * math.Sqrt(399.0/12)
+	for i := 19; i < len(middle); i++ {
+		assertClose(c, middle[i], float64(i)-9.5, 1e-9)
+		assertClose(c, upper[i], float64(i)-9.5+width, 1e-9)
+		assertClose(c, lower[i], float64(i)-9.5-width, 1e-9)
+	}
+
+	cs = series([]string{"Close"}, []float64{1, 3, 1, 3})
+	out = run(c, &Bollinger{}, cs, []string{"Close"}, "2", "1.5")
+	c.Assert(out.GetColumn("Upper").([]float64)[1:], DeepEquals, []float64{3.5, 3.5, 3.5})
+	c.Assert(out.GetColumn("Lower").([]float64)[1:], DeepEquals, []float64{0.5, 0.5, 0.5})
+}
+
+func (s *TestSuite) TestVWAP(c *C) {
+	cs := series([]string{"Close", "Volume"},
+		[]float64{10, 20, 30, 40},
+		[]float64{1, 3, 0, 4},
+	)
+	values := run(c, &VWAP{}, cs, []string{"Close", "Volume"}).GetColumn("VWAP").([]float64)
+	c.Assert(values, DeepEquals, []float64{10, 17.5, 17.5, 230.0 / 8})
+
+	values = run(c, &VWAP{}, cs, []string{"Close", "Volume"}, "2").GetColumn("VWAP").([]float64)
+	assertNaN(c, values, 1)
+	c.Assert(values[1:], DeepEquals, []float64{17.5, 20, 40})
+}
+
+func (s *TestSuite) TestATR(c *C) {
+	/*
+		True ranges: 2, 4 from the gap up over the previous close, 3 down to
+		the low, then 1 and 2
+	*/
+	cs := series([]string{"High", "Low", "Close"},
+		[]float64{11, 14, 13, 12, 14},
+		[]float64{9, 12, 10, 11, 12},
+		[]float64{10, 13, 11, 12, 13},
+	)
+	values := run(c, &ATR{}, cs, []string{"High", "Low", "Close"}, "3").GetColumn("ATR").([]float64)
+	assertNaN(c, values, 2)
+	assertClose(c, values[2], 3, 1e-9)
+	assertClose(c, values[3], (3*2+1)/3.0, 1e-9)
+	assertClose(c, values[4], ((3*2+1)/3.0*2+2)/3, 1e-9)
+}
+
+func (s *TestSuite) TestAccumulates(c *C) {
+	aggfunc, am := RSI{}.New()
+	c.Assert(am.PrepareArguments([]string{"Close"}), IsNil)
+	c.Assert(aggfunc.Init(), IsNil)
+	half := len(referenceCloses) / 2
+	c.Assert(aggfunc.Accum(series([]string{"Close"}, referenceCloses[:half])), IsNil)
+	c.Assert(aggfunc.Accum(series([]string{"Close"}, referenceCloses[half:])), IsNil)
+	values := aggfunc.Output().GetColumn("RSI").([]float64)
+	c.Assert(len(values), Equals, len(referenceCloses))
+	assertClose(c, values[len(values)-1], referenceRSI[len(referenceRSI)-1], 0.005)
+}
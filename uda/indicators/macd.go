@@ -0,0 +1,98 @@
+package indicators
+
+import (
+	"fmt"
+
+	"github.com/alpacahq/marketstore/uda"
+	"github.com/alpacahq/marketstore/utils/functions"
+	"github.com/alpacahq/marketstore/utils/io"
+)
+
+/*
+MACD is the moving average convergence divergence, the difference of a fast
+and a slow EMA, with a signal line that is an EMA of the MACD. The periods
+are 12, 26 and 9 by default:
+
+	macd('12', '26', '9', Close)
+
+The output columns are MACD, Signal and Histogram, the MACD minus the signal
+*/
+type MACD struct {
+	uda.AggInterface
+
+	// Input arguments mapping
+	ArgMap *functions.ArgumentMap
+
+	Fast, Slow, Signal int
+	Input              history
+}
+
+var macdInitArgs = []io.DataShape{}
+
+func (m *MACD) GetRequiredArgs() []io.DataShape {
+	return priceColumns
+}
+func (m *MACD) GetOptionalArgs() []io.DataShape {
+	return optionalColumns
+}
+func (m *MACD) GetInitArgs() []io.DataShape {
+	return macdInitArgs
+}
+
+/*
+	Creates a new MACD using the arguments of the specific implementation
+	for inputColumns and optionalInputColumns
+*/
+func (m MACD) New() (out uda.AggInterface, am *functions.ArgumentMap) {
+	mx := &MACD{}
+	mx.ArgMap = functions.NewArgumentMap(priceColumns, optionalColumns...)
+	return mx, mx.ArgMap
+}
+
+func (m *MACD) Init(args ...interface{}) error {
+	if unmapped := m.ArgMap.Validate(); unmapped != nil {
+		return fmt.Errorf("Unmapped columns: %s", unmapped)
+	}
+	m.Reset()
+	periods, err := initPeriods("MACD", args, 12, 26, 9)
+	if err != nil {
+		return err
+	}
+	m.Fast, m.Slow, m.Signal = periods[0], periods[1], periods[2]
+	if m.Fast >= m.Slow {
+		return fmt.Errorf("The MACD fast period must be less than the slow period, have %d and %d", m.Fast, m.Slow)
+	}
+	return nil
+}
+
+/*
+	Accum() sends new data to the aggregate
+*/
+func (m *MACD) Accum(cols io.ColumnInterface) error {
+	return m.Input.accum(m.ArgMap, cols, "*")
+}
+
+/*
+	Output() returns the currently valid output of this aggregate
+*/
+func (m *MACD) Output() *io.ColumnSeries {
+	prices := m.Input.Columns["*"]
+	fast, slow := ema(prices, m.Fast), ema(prices, m.Slow)
+	macd := make([]float64, len(prices))
+	for i := range macd {
+		macd[i] = fast[i] - slow[i] // NaN until the slow EMA starts
+	}
+	signal := ema(macd, m.Signal)
+	histogram := make([]float64, len(prices))
+	for i := range histogram {
+		histogram[i] = macd[i] - signal[i]
+	}
+	return m.Input.output([]string{"MACD", "Signal", "Histogram"}, macd, signal, histogram)
+}
+
+/*
+	Reset() puts the aggregate state back to "new"
+*/
+func (m *MACD) Reset() {
+	m.Input.reset()
+}
@@ -0,0 +1,105 @@
+package indicators
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/alpacahq/marketstore/uda"
+	"github.com/alpacahq/marketstore/utils/functions"
+	"github.com/alpacahq/marketstore/utils/io"
+)
+
+/*
+RSI is Wilder's relative strength index, from 0 to 100, over 14 periods by
+default:
+
+	rsi('14', Close)
+
+The average gains and losses start as the average of the first period
+changes and are then smoothed with Wilder's moving average, so the first
+value is at the row after the first period rows
+*/
+type RSI struct {
+	uda.AggInterface
+
+	// Input arguments mapping
+	ArgMap *functions.ArgumentMap
+
+	Period int
+	Input  history
+}
+
+var rsiInitArgs = []io.DataShape{}
+
+func (r *RSI) GetRequiredArgs() []io.DataShape {
+	return priceColumns
+}
+func (r *RSI) GetOptionalArgs() []io.DataShape {
+	return optionalColumns
+}
+func (r *RSI) GetInitArgs() []io.DataShape {
+	return rsiInitArgs
+}
+
+/*
+	Creates a new RSI using the arguments of the specific implementation
+	for inputColumns and optionalInputColumns
+*/
+func (r RSI) New() (out uda.AggInterface, am *functions.ArgumentMap) {
+	rx := &RSI{}
+	rx.ArgMap = functions.NewArgumentMap(priceColumns, optionalColumns...)
+	return rx, rx.ArgMap
+}
+
+func (r *RSI) Init(args ...interface{}) error {
+	if unmapped := r.ArgMap.Validate(); unmapped != nil {
+		return fmt.Errorf("Unmapped columns: %s", unmapped)
+	}
+	r.Reset()
+	periods, err := initPeriods("RSI", args, 14)
+	if err != nil {
+		return err
+	}
+	r.Period = periods[0]
+	return nil
+}
+
+/*
+	Accum() sends new data to the aggregate
+*/
+func (r *RSI) Accum(cols io.ColumnInterface) error {
+	return r.Input.accum(r.ArgMap, cols, "*")
+}
+
+/*
+	Output() returns the currently valid output of this aggregate
+*/
+func (r *RSI) Output() *io.ColumnSeries {
+	prices := r.Input.Columns["*"]
+	gains, losses := nans(len(prices)), nans(len(prices))
+	for i := 1; i < len(prices); i++ {
+		change := prices[i] - prices[i-1]
+		gains[i], losses[i] = math.Max(change, 0), math.Max(-change, 0)
+	}
+	avgGains, avgLosses := wilder(gains, r.Period), wilder(losses, r.Period)
+	rsi := nans(len(prices))
+	for i := range rsi {
+		switch {
+		case math.IsNaN(avgGains[i]):
+		case avgLosses[i] == 0 && avgGains[i] == 0:
+			rsi[i] = 50
+		case avgLosses[i] == 0:
+			rsi[i] = 100
+		default:
+			rsi[i] = 100 - 100/(1+avgGains[i]/avgLosses[i])
+		}
+	}
+	return r.Input.output([]string{"RSI"}, rsi)
+}
+
+/*
+	Reset() puts the aggregate state back to "new"
+*/
+func (r *RSI) Reset() {
+	r.Input.reset()
+}
@@ -0,0 +1,112 @@
+package indicators
+
+import (
+	"fmt"
+
+	"github.com/alpacahq/marketstore/uda"
+	"github.com/alpacahq/marketstore/utils/functions"
+	"github.com/alpacahq/marketstore/utils/io"
+)
+
+/*
+VWAP is the volume weighted average price, cumulative from the first row,
+or over a number of periods when one is passed:
+
+	vwap(Close, Volume)
+	vwap('20', Close, Volume)
+
+Rows without volume so far are NaN
+*/
+type VWAP struct {
+	uda.AggInterface
+
+	// Input arguments mapping
+	ArgMap *functions.ArgumentMap
+
+	Period int // Zero for a cumulative VWAP
+	Input  history
+}
+
+var (
+	vwapColumns = []io.DataShape{
+		{Name: "Price", Type: io.FLOAT32},
+		{Name: "Volume", Type: io.FLOAT32},
+	}
+
+	vwapInitArgs = []io.DataShape{}
+)
+
+func (v *VWAP) GetRequiredArgs() []io.DataShape {
+	return vwapColumns
+}
+func (v *VWAP) GetOptionalArgs() []io.DataShape {
+	return optionalColumns
+}
+func (v *VWAP) GetInitArgs() []io.DataShape {
+	return vwapInitArgs
+}
+
+/*
+	Creates a new VWAP using the arguments of the specific implementation
+	for inputColumns and optionalInputColumns
+*/
+func (v VWAP) New() (out uda.AggInterface, am *functions.ArgumentMap) {
+	vx := &VWAP{}
+	vx.ArgMap = functions.NewArgumentMap(vwapColumns, optionalColumns...)
+	return vx, vx.ArgMap
+}
+
+func (v *VWAP) Init(args ...interface{}) error {
+	if unmapped := v.ArgMap.Validate(); unmapped != nil {
+		return fmt.Errorf("Unmapped columns: %s", unmapped)
+	}
+	v.Reset()
+	periods, err := initPeriods("VWAP", args, 0)
+	if err != nil {
+		return err
+	}
+	v.Period = periods[0]
+	return nil
+}
+
+/*
+	Accum() sends new data to the aggregate
+*/
+func (v *VWAP) Accum(cols io.ColumnInterface) error {
+	return v.Input.accum(v.ArgMap, cols, "Price", "Volume")
+}
+
+/*
+	Output() returns the currently valid output of this aggregate
+*/
+func (v *VWAP) Output() *io.ColumnSeries {
+	prices, volumes := v.Input.Columns["Price"], v.Input.Columns["Volume"]
+	vwap := nans(len(prices))
+	var value, volume float64
+	for i := range prices {
+		if v.Period == 0 {
+			value += prices[i] * volumes[i]
+			volume += volumes[i]
+		} else {
+			if i < v.Period-1 {
+				continue
+			}
+			value, volume = 0, 0
+			for j := i - v.Period + 1; j <= i; j++ {
+				value += prices[j] * volumes[j]
+				volume += volumes[j]
+			}
+		}
+		if volume > 0 {
+			vwap[i] = value / volume
+		}
+	}
+	return v.Input.output([]string{"VWAP"}, vwap)
+}
+
+/*
+	Reset() puts the aggregate state back to "new"
+*/
+func (v *VWAP) Reset() {
+	v.Input.reset()
+}
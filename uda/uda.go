@@ -69,3 +69,21 @@ func ColumnToFloat64(cols io.ColumnInterface, name string) (outCol []float64, er
 	}
 	return outCol, nil
 }
+
+/*
+InitStrings reads the literal arguments passed to Init, either as strings or
+as a single list of strings
+*/
+func InitStrings(args []interface{}) (values []string, err error) {
+	for _, arg := range args {
+		switch val := arg.(type) {
+		case string:
+			values = append(values, val)
+		case []string:
+			values = append(values, val...)
+		default:
+			return nil, fmt.Errorf("Init arguments must be strings")
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,112 @@
+package window
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/alpacahq/marketstore/uda"
+	"github.com/alpacahq/marketstore/utils/functions"
+	"github.com/alpacahq/marketstore/utils/io"
+)
+
+/*
+Lag outputs the value of the row a number of rows before each row, or after
+it for Lead:
+
+	LAG(Close)          the previous Close
+	LAG(Close, 5)       the Close five rows before
+	LEAD(Close, 1, 0)   the next Close, with 0 for the last row
+
+The frame does not apply, only the offset within the partition
+*/
+type Lag struct {
+	uda.WindowInterface
+
+	// Input arguments mapping
+	ArgMap *functions.ArgumentMap
+
+	Offset  int
+	Default float64
+	IsLead  bool
+}
+
+var lagInitArgs = []io.DataShape{}
+
+func (la *Lag) GetRequiredArgs() []io.DataShape {
+	return requiredColumns
+}
+func (la *Lag) GetOptionalArgs() []io.DataShape {
+	return optionalColumns
+}
+func (la *Lag) GetInitArgs() []io.DataShape {
+	return lagInitArgs
+}
+
+/*
+	Creates a new lag using the arguments of the specific implementation
+	for inputColumns and optionalInputColumns
+*/
+func (la Lag) New() (out uda.WindowInterface, am *functions.ArgumentMap) {
+	lag := &Lag{IsLead: la.IsLead}
+	lag.ArgMap = functions.NewArgumentMap(requiredColumns, optionalColumns...)
+	return lag, lag.ArgMap
+}
+
+/*
+	Init takes an optional offset, 1 by default, and the value used when the
+	offset is outside the partition, NaN by default
+*/
+func (la *Lag) Init(args ...interface{}) error {
+	if unmapped := la.ArgMap.Validate(); unmapped != nil {
+		return fmt.Errorf("Unmapped columns: %s", unmapped)
+	}
+	values, err := uda.InitStrings(args)
+	if err != nil {
+		return err
+	}
+	if len(values) > 2 {
+		return fmt.Errorf("Takes an offset and a default value, have %d arguments", len(values))
+	}
+	la.Offset, la.Default = 1, math.NaN()
+	if len(values) > 0 {
+		if la.Offset, err = parseInt("offset", values[0]); err != nil {
+			return err
+		}
+		if la.Offset < 0 {
+			return fmt.Errorf("The offset can not be negative")
+		}
+	}
+	if len(values) > 1 {
+		if la.Default, err = strconv.ParseFloat(values[1], 64); err != nil {
+			return fmt.Errorf("The default value must be a number, have %s", values[1])
+		}
+	}
+	return nil
+}
+
+func (la *Lag) Apply(cols io.ColumnInterface, frame uda.WindowFrame) (*io.ColumnSeries, error) {
+	input, err := inputColumn(la.ArgMap, cols)
+	if err != nil {
+		return nil, err
+	}
+	offset := -la.Offset
+	if la.IsLead {
+		offset = la.Offset
+	}
+	output := make([]float64, len(input))
+	for i := range output {
+		if j := i + offset; j >= 0 && j < len(input) {
+			output[i] = input[j]
+		} else {
+			output[i] = la.Default
+		}
+	}
+	cs := io.NewColumnSeries()
+	if la.IsLead {
+		cs.AddColumn("Lead", output)
+	} else {
+		cs.AddColumn("Lag", output)
+	}
+	return cs, nil
+}
@@ -0,0 +1,193 @@
+package window
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/alpacahq/marketstore/uda"
+	"github.com/alpacahq/marketstore/utils/functions"
+	"github.com/alpacahq/marketstore/utils/io"
+)
+
+type RollingOperator uint8
+
+const (
+	_ RollingOperator = iota
+	AVG
+	SUM
+	MIN
+	MAX
+	COUNT
+	STDDEV
+)
+
+func (ro RollingOperator) String() string {
+	switch ro {
+	case AVG:
+		return "Avg"
+	case SUM:
+		return "Sum"
+	case MIN:
+		return "Min"
+	case MAX:
+		return "Max"
+	case COUNT:
+		return "Count"
+	case STDDEV:
+		return "StdDev"
+	}
+	return "Unknown"
+}
+
+/*
+Rolling aggregates the rows in the frame of each row, as in moving averages
+and rolling volatility:
+
+	AVG(Close) OVER (ORDER BY Epoch ROWS 19 PRECEDING)
+	STDDEV(Close) OVER (ROWS BETWEEN 9 PRECEDING AND CURRENT ROW)
+
+STDDEV is the sample standard deviation, NaN for frames of less than 2 rows.
+An empty frame outputs NaN, or 0 for COUNT
+*/
+type Rolling struct {
+	uda.WindowInterface
+
+	// Input arguments mapping
+	ArgMap *functions.ArgumentMap
+
+	Operator RollingOperator
+}
+
+var rollingInitArgs = []io.DataShape{}
+
+func (ro *Rolling) GetRequiredArgs() []io.DataShape {
+	return requiredColumns
+}
+func (ro *Rolling) GetOptionalArgs() []io.DataShape {
+	return optionalColumns
+}
+func (ro *Rolling) GetInitArgs() []io.DataShape {
+	return rollingInitArgs
+}
+
+/*
+	Creates a new rolling aggregate using the arguments of the specific
+	implementation for inputColumns and optionalInputColumns
+*/
+func (ro Rolling) New() (out uda.WindowInterface, am *functions.ArgumentMap) {
+	rolling := &Rolling{Operator: ro.Operator}
+	rolling.ArgMap = functions.NewArgumentMap(requiredColumns, optionalColumns...)
+	return rolling, rolling.ArgMap
+}
+
+func (ro *Rolling) Init(args ...interface{}) error {
+	if unmapped := ro.ArgMap.Validate(); unmapped != nil {
+		return fmt.Errorf("Unmapped columns: %s", unmapped)
+	}
+	values, err := uda.InitStrings(args)
+	if err != nil {
+		return err
+	}
+	if len(values) != 0 {
+		return fmt.Errorf("%s takes no arguments other than the input column", ro.Operator)
+	}
+	return nil
+}
+
+func (ro *Rolling) Apply(cols io.ColumnInterface, frame uda.WindowFrame) (*io.ColumnSeries, error) {
+	input, err := inputColumn(ro.ArgMap, cols)
+	if err != nil {
+		return nil, err
+	}
+	var output interface{}
+	switch ro.Operator {
+	case MIN, MAX:
+		output = rollingExtreme(input, frame, ro.Operator == MAX)
+	case COUNT:
+		counts := make([]int64, len(input))
+		for i := range counts {
+			first, last := frame.Bounds(i, len(input))
+			if last >= first {
+				counts[i] = int64(last - first + 1)
+			}
+		}
+		output = counts
+	default:
+		output = rollingMoments(input, frame, ro.Operator)
+	}
+	cs := io.NewColumnSeries()
+	cs.AddColumn(ro.Operator.String(), output)
+	return cs, nil
+}
+
+/*
+rollingMoments computes sums, averages and standard deviations from running
+sums of the values and of their squares
+*/
+func rollingMoments(input []float64, frame uda.WindowFrame, op RollingOperator) []float64 {
+	sums := make([]float64, len(input)+1)
+	squares := make([]float64, len(input)+1)
+	for i, value := range input {
+		sums[i+1] = sums[i] + value
+		squares[i+1] = squares[i] + value*value
+	}
+	output := make([]float64, len(input))
+	for i := range output {
+		first, last := frame.Bounds(i, len(input))
+		n := float64(last - first + 1)
+		if n <= 0 {
+			output[i] = math.NaN()
+			continue
+		}
+		sum := sums[last+1] - sums[first]
+		switch op {
+		case SUM:
+			output[i] = sum
+		case AVG:
+			output[i] = sum / n
+		case STDDEV:
+			if n < 2 {
+				output[i] = math.NaN()
+				continue
+			}
+			mean := sum / n
+			variance := (squares[last+1] - squares[first] - n*mean*mean) / (n - 1)
+			output[i] = math.Sqrt(math.Max(variance, 0))
+		}
+	}
+	return output
+}
+
+/*
+rollingExtreme computes the minimum or maximum of each frame with a queue of
+the candidate rows, as the frame bounds only move forward
+*/
+func rollingExtreme(input []float64, frame uda.WindowFrame, isMax bool) []float64 {
+	better := func(a, b float64) bool {
+		if isMax {
+			return a >= b
+		}
+		return a <= b
+	}
+	output := make([]float64, len(input))
+	var queue []int // Rows with values in decreasing order of preference
+	next := 0       // Next row to add to the queue
+	for i := range output {
+		first, last := frame.Bounds(i, len(input))
+		for ; next <= last; next++ {
+			for len(queue) != 0 && better(input[next], input[queue[len(queue)-1]]) {
+				queue = queue[:len(queue)-1]
+			}
+			queue = append(queue, next)
+		}
+		for len(queue) != 0 && queue[0] < first {
+			queue = queue[1:]
+		}
+		if last < first || len(queue) == 0 {
+			output[i] = math.NaN()
+			continue
+		}
+		output[i] = input[queue[0]]
+	}
+	return output
+}
@@ -0,0 +1,41 @@
+package window
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/alpacahq/marketstore/uda"
+	"github.com/alpacahq/marketstore/utils/functions"
+	"github.com/alpacahq/marketstore/utils/io"
+)
+
+/*
+Window functions over an ordered partition of rows
+
+Each function maps its input to the "*" argument and outputs float64 values,
+or int64 for COUNT. Rows without a value, like the first row of a LAG, are NaN
+*/
+
+var (
+	requiredColumns = []io.DataShape{
+		{Name: "*", Type: io.FLOAT32},
+	}
+
+	optionalColumns = []io.DataShape{}
+)
+
+func inputColumn(am *functions.ArgumentMap, cols io.ColumnInterface) ([]float64, error) {
+	mapped := am.GetMappedColumns(requiredColumns[0].Name)
+	if len(mapped) == 0 {
+		return nil, fmt.Errorf("Unmapped input column")
+	}
+	return uda.ColumnToFloat64(cols, mapped[0].Name)
+}
+
+func parseInt(name, value string) (int, error) {
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("The %s must be an integer, have %s", name, value)
+	}
+	return i, nil
+}
@@ -29,14 +29,44 @@ type BgWorkerSetting struct {
 	Config map[string]interface{}
 }
 
+type UDASetting struct {
+	Module string
+	Name   string
+	Config map[string]interface{}
+}
+
+// RetentionSetting removes the data older than MaxAge from the buckets whose
+// key matches On, e.g. "*/1Min/OHLCV"
+type RetentionSetting struct {
+	On     string
+	MaxAge time.Duration
+}
+
+// Policies for fsyncing the WAL file, see MktsConfig.WALSyncPolicy
+const (
+	WALSyncAlways   = "always"   // fsync each transaction group as it is written
+	WALSyncInterval = "interval" // fsync every WALSyncInterval
+	WALSyncNever    = "never"    // fsync only at checkpoints and for sync writes
+)
+
+// Roles of an instance in replication, see MktsConfig.ReplicationRole
+const (
+	ReplicationRolePrimary  = "primary"  // serve the WAL to the followers
+	ReplicationRoleFollower = "follower" // apply the WAL of the primary, read-only
+)
+
 type MktsConfig struct {
 	RootDirectory              string
 	ListenURL                  string
+	GRPCListenURL              string
 	UtilitiesURL               string
 	Timezone                   *time.Location
 	Queryable                  bool
 	StopGracePeriod            time.Duration
 	WALRotateInterval          int
+	WALFlushInterval           time.Duration
+	WALSyncPolicy              string
+	WALSyncInterval            time.Duration
 	EnableAdd                  bool
 	EnableRemove               bool
 	EnableLastKnown            bool
@@ -46,9 +76,18 @@ type MktsConfig struct {
 	BackgroundSync             bool
 	WALBypass                  bool
 	ClusterMode                bool
+	ReplicationRole            string
+	ReplicationPrimary         string
+	ReplicationLogSize         int64
+	ReplicationURL             string
+	BackupDir                  string
 	StartTime                  time.Time
 	Triggers                   []*TriggerSetting
 	BgWorkers                  []*BgWorkerSetting
+	UDAs                       []*UDASetting
+	Retention                  []*RetentionSetting
+	RetentionInterval          time.Duration
+	ClusterNodes               []string
 }
 
 func (m *MktsConfig) Parse(data []byte) error {
@@ -58,12 +97,16 @@ func (m *MktsConfig) Parse(data []byte) error {
 			RootDirectory              string `yaml:"root_directory"`
 			ListenHost                 string `yaml:"listen_host"`
 			ListenPort                 string `yaml:"listen_port"`
+			GRPCListenPort             string `yaml:"grpc_listen_port"`
 			UtilitiesURL               string `yaml:"utilities_url"`
 			Timezone                   string `yaml:"timezone"`
 			LogLevel                   string `yaml:"log_level"`
 			Queryable                  string `yaml:"queryable"`
 			StopGracePeriod            int    `yaml:"stop_grace_period"`
 			WALRotateInterval          int    `yaml:"wal_rotate_interval"`
+			WALFlushInterval           int    `yaml:"wal_flush_interval"`
+			WALSyncPolicy              string `yaml:"wal_sync_policy"`
+			WALSyncInterval            int    `yaml:"wal_sync_interval"`
 			EnableAdd                  string `yaml:"enable_add"`
 			EnableRemove               string `yaml:"enable_remove"`
 			EnableLastKnown            string `yaml:"enable_last_known"`
@@ -73,6 +116,11 @@ func (m *MktsConfig) Parse(data []byte) error {
 			BackgroundSync             string `yaml:"background_sync"`
 			WALBypass                  string `yaml:"wal_bypass"`
 			ClusterMode                string `yaml:"cluster_mode"`
+			ReplicationRole            string `yaml:"replication_role"`
+			ReplicationPrimary         string `yaml:"replication_primary"`
+			ReplicationLogSize         int    `yaml:"replication_log_size"`
+			ReplicationURL             string `yaml:"replication_url"`
+			BackupDir                  string `yaml:"backup_dir"`
 			Triggers                   []struct {
 				Module string                 `yaml:"module"`
 				On     string                 `yaml:"on"`
@@ -83,6 +131,17 @@ func (m *MktsConfig) Parse(data []byte) error {
 				Name   string                 `yaml:"name"`
 				Config map[string]interface{} `yaml:"config"`
 			} `yaml:"bgworkers"`
+			UDAs []struct {
+				Module string                 `yaml:"module"`
+				Name   string                 `yaml:"name"`
+				Config map[string]interface{} `yaml:"config"`
+			} `yaml:"udas"`
+			Retention []struct {
+				On     string `yaml:"on"`
+				MaxAge string `yaml:"max_age"`
+			} `yaml:"retention"`
+			RetentionInterval int      `yaml:"retention_interval"`
+			ClusterNodes      []string `yaml:"cluster_nodes"`
 		}
 	)
 
@@ -113,6 +172,26 @@ func (m *MktsConfig) Parse(data []byte) error {
 		m.WALRotateInterval = aux.WALRotateInterval
 	}
 
+	m.WALFlushInterval = 500 * time.Millisecond
+	if aux.WALFlushInterval > 0 {
+		m.WALFlushInterval = time.Duration(aux.WALFlushInterval) * time.Millisecond
+	}
+
+	switch policy := strings.ToLower(aux.WALSyncPolicy); policy {
+	case "":
+		m.WALSyncPolicy = WALSyncAlways
+	case WALSyncAlways, WALSyncInterval, WALSyncNever:
+		m.WALSyncPolicy = policy
+	default:
+		log.Error("Invalid value: %v for wal_sync_policy. Syncing always...", aux.WALSyncPolicy)
+		m.WALSyncPolicy = WALSyncAlways
+	}
+
+	m.WALSyncInterval = time.Second
+	if aux.WALSyncInterval > 0 {
+		m.WALSyncInterval = time.Duration(aux.WALSyncInterval) * time.Millisecond
+	}
+
 	if aux.Queryable != "" {
 		queryable, err := strconv.ParseBool(aux.Queryable)
 		if err != nil {
@@ -221,8 +300,47 @@ func (m *MktsConfig) Parse(data []byte) error {
 		}
 	}
 
+	switch role := strings.ToLower(aux.ReplicationRole); role {
+	case "":
+		m.ReplicationRole = ReplicationRolePrimary
+	case ReplicationRolePrimary, ReplicationRoleFollower:
+		m.ReplicationRole = role
+	default:
+		return fmt.Errorf("Invalid value: %v for replication_role", aux.ReplicationRole)
+	}
+	if m.ReplicationRole == ReplicationRoleFollower {
+		if !m.ClusterMode {
+			return errors.New("A replication follower requires cluster_mode")
+		}
+		if aux.ReplicationPrimary == "" {
+			return errors.New("A replication follower requires replication_primary")
+		}
+	}
+	m.ReplicationPrimary = aux.ReplicationPrimary
+	m.ReplicationURL = aux.ReplicationURL
+
+	// A coordinator shards the symbols across the cluster nodes
+	if len(aux.ClusterNodes) > 0 {
+		if !m.ClusterMode {
+			return errors.New("A cluster coordinator requires cluster_mode")
+		}
+		if m.ReplicationRole == ReplicationRoleFollower {
+			return errors.New("A replication follower can not be a cluster coordinator")
+		}
+	}
+	m.ClusterNodes = aux.ClusterNodes
+
+	m.ReplicationLogSize = 64 * 1024 * 1024
+	if aux.ReplicationLogSize > 0 {
+		m.ReplicationLogSize = int64(aux.ReplicationLogSize) * 1024 * 1024
+	}
+
 	m.RootDirectory = aux.RootDirectory
+	m.BackupDir = aux.BackupDir
 	m.ListenURL = fmt.Sprintf("%v:%v", aux.ListenHost, aux.ListenPort)
+	if aux.GRPCListenPort != "" {
+		m.GRPCListenURL = fmt.Sprintf("%v:%v", aux.ListenHost, aux.GRPCListenPort)
+	}
 	m.UtilitiesURL = fmt.Sprintf("%v", aux.UtilitiesURL)
 
 	for _, trig := range aux.Triggers {
@@ -243,5 +361,53 @@ func (m *MktsConfig) Parse(data []byte) error {
 		m.BgWorkers = append(m.BgWorkers, bgWorkerSetting)
 	}
 
+	for _, u := range aux.UDAs {
+		udaSetting := &UDASetting{
+			Module: u.Module,
+			Name:   u.Name,
+			Config: u.Config,
+		}
+		m.UDAs = append(m.UDAs, udaSetting)
+	}
+
+	for _, r := range aux.Retention {
+		maxAge, err := ParseMaxAge(r.MaxAge)
+		if err != nil || r.On == "" {
+			log.Error("Invalid retention setting on \"%v\" with max_age %v, ignoring...", r.On, r.MaxAge)
+			continue
+		}
+		m.Retention = append(m.Retention, &RetentionSetting{
+			On:     r.On,
+			MaxAge: maxAge,
+		})
+	}
+
+	m.RetentionInterval = time.Hour
+	if aux.RetentionInterval > 0 {
+		m.RetentionInterval = time.Duration(aux.RetentionInterval) * time.Second
+	}
+
 	return err
 }
+
+// ParseMaxAge parses a retention age, given in days such as "90d", or as a
+// duration such as "36h"
+func ParseMaxAge(s string) (time.Duration, error) {
+	var age time.Duration
+	if strings.HasSuffix(s, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(s, "d"))
+		if err != nil {
+			return 0, err
+		}
+		age = time.Duration(days) * 24 * time.Hour
+	} else {
+		var err error
+		if age, err = time.ParseDuration(s); err != nil {
+			return 0, err
+		}
+	}
+	if age <= 0 {
+		return 0, fmt.Errorf("max age %v is not positive", s)
+	}
+	return age, nil
+}
@@ -28,6 +28,133 @@ func EnumRecordTypeByName(name string) EnumRecordType {
 	}
 }
 
+/*
+EnumCompressionType is the storage format of the records of a fixed length
+time bucket file. Files written before compression was added read as
+NOCOMPRESSION
+*/
+type EnumCompressionType int8
+
+const (
+	NOCOMPRESSION EnumCompressionType = iota
+	COLUMNAR
+	UNKNOWNCOMPRESSION
+)
+
+func EnumCompressionTypeByName(name string) EnumCompressionType {
+	name = strings.ToLower(name)
+	switch name {
+	case "", "none":
+		return NOCOMPRESSION
+	case "columnar":
+		return COLUMNAR
+	default:
+		return UNKNOWNCOMPRESSION
+	}
+}
+
+func (c EnumCompressionType) String() string {
+	switch c {
+	case NOCOMPRESSION:
+		return "none"
+	case COLUMNAR:
+		return "columnar"
+	default:
+		return fmt.Sprintf("EnumCompressionType(%d)", int8(c))
+	}
+}
+
+/*
+EnumWritePolicy decides what happens to a record written at the interval of
+an existing record of a fixed length time bucket. Files written before write
+policies were added read as OVERWRITE
+*/
+type EnumWritePolicy int8
+
+const (
+	OVERWRITE EnumWritePolicy = iota
+	KEEPFIRST
+	REJECT
+	MERGE
+	UNKNOWNWRITEPOLICY
+)
+
+func EnumWritePolicyByName(name string) EnumWritePolicy {
+	name = strings.ToLower(name)
+	switch name {
+	case "", "overwrite":
+		return OVERWRITE
+	case "keep-first":
+		return KEEPFIRST
+	case "reject":
+		return REJECT
+	case "merge":
+		return MERGE
+	default:
+		return UNKNOWNWRITEPOLICY
+	}
+}
+
+func (p EnumWritePolicy) String() string {
+	switch p {
+	case OVERWRITE:
+		return "overwrite"
+	case KEEPFIRST:
+		return "keep-first"
+	case REJECT:
+		return "reject"
+	case MERGE:
+		return "merge"
+	default:
+		return fmt.Sprintf("EnumWritePolicy(%d)", int8(p))
+	}
+}
+
+/*
+EnumTimestampFormat is how the timestamp trailing each variable record is
+stored. TICKS stores 32-bit ticks within the interval of the record, which
+files written before timestamp formats were added use. NANOSECONDS stores the
+int64 number of nanoseconds from the start of the interval
+*/
+type EnumTimestampFormat int8
+
+const (
+	TICKS EnumTimestampFormat = iota
+	NANOSECONDS
+	UNKNOWNTIMESTAMPFORMAT
+)
+
+func EnumTimestampFormatByName(name string) EnumTimestampFormat {
+	name = strings.ToLower(name)
+	switch name {
+	case "", "ticks":
+		return TICKS
+	case "nanoseconds":
+		return NANOSECONDS
+	default:
+		return UNKNOWNTIMESTAMPFORMAT
+	}
+}
+
+func (t EnumTimestampFormat) String() string {
+	switch t {
+	case TICKS:
+		return "ticks"
+	case NANOSECONDS:
+		return "nanoseconds"
+	default:
+		return fmt.Sprintf("EnumTimestampFormat(%d)", int8(t))
+	}
+}
+
+// Size returns the length of the timestamp trailing each variable record
+func (t EnumTimestampFormat) Size() int {
+	if t == NANOSECONDS {
+		return 8
+	}
+	return 4
+}
+
 type EnumElementType byte
 
 /*
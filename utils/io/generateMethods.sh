@@ -74,7 +74,7 @@ func (cs *ColumnSeries) RestrictViaBitmap(bitmap []bool) (err error) {
 		switch col := i_col.(type) {
 EOF
 
-for type in $TYPESET
+for type in $TYPESET string bool
 do
 	cat <<EOF
 		case []$type:
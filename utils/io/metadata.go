@@ -2,6 +2,7 @@ package io
 
 import (
 	"bytes"
+	goio "io"
 	"os"
 	"sync"
 	"unsafe"
@@ -54,6 +55,10 @@ type TimeBucketInfo struct {
 	variableRecordLength int32 // In case of variable recordType, the sum of field lengths in elementTypes
 	elementNames         []string
 	elementTypes         []EnumElementType
+	compression          EnumCompressionType // Storage format of fixed records
+	schemaVersion        int64               // Incremented each time the columns are altered
+	writePolicy          EnumWritePolicy     // Handling of writes to existing fixed records
+	timestampFormat      EnumTimestampFormat // Storage of the timestamps of variable records
 
 	once sync.Once
 }
@@ -141,6 +146,10 @@ func (f *TimeBucketInfo) GetDeepCopy() *TimeBucketInfo {
 		recordType:           f.recordType,
 		recordLength:         f.recordLength,
 		variableRecordLength: f.variableRecordLength,
+		compression:          f.compression,
+		schemaVersion:        f.schemaVersion,
+		writePolicy:          f.writePolicy,
+		timestampFormat:      f.timestampFormat,
 	}
 	fcopy.elementNames = make([]string, len(f.elementNames))
 	fcopy.elementTypes = make([]EnumElementType, len(f.elementTypes))
@@ -206,8 +215,8 @@ func (f *TimeBucketInfo) GetVariableRecordLength() int32 {
 	f.once.Do(f.initFromFile)
 
 	if f.recordType == VARIABLE && f.variableRecordLength == 0 {
-		// Variable records use the raw element sizes plus a 4-byte trailer for interval ticks
-		f.variableRecordLength = int32(f.getFieldRecordLength()) + 4 // Variable records have a 4-byte trailer
+		// Variable records use the raw element sizes plus a trailer for the timestamp
+		f.variableRecordLength = int32(f.getFieldRecordLength() + f.timestampFormat.Size())
 	}
 	return f.variableRecordLength
 }
@@ -219,6 +228,71 @@ func (f *TimeBucketInfo) GetRecordType() EnumRecordType {
 	return f.recordType
 }
 
+// GetCompression returns the storage format of the records in the file
+// described by the given TimeBucketInfo
+func (f *TimeBucketInfo) GetCompression() EnumCompressionType {
+	f.once.Do(f.initFromFile)
+	return f.compression
+}
+
+// SetCompression sets the storage format of the records of a new fixed
+// length file, before its header is written
+func (f *TimeBucketInfo) SetCompression(compression EnumCompressionType) error {
+	if compression != NOCOMPRESSION && f.GetRecordType() != FIXED {
+		return fmt.Errorf("Only fixed record types can be compressed")
+	}
+	f.compression = compression
+	return nil
+}
+
+// GetSchemaVersion returns the number of times the columns of the file
+// described by the given TimeBucketInfo were altered
+func (f *TimeBucketInfo) GetSchemaVersion() int64 {
+	f.once.Do(f.initFromFile)
+	return f.schemaVersion
+}
+
+// SetSchemaVersion sets the schema version of a file before its header is
+// written
+func (f *TimeBucketInfo) SetSchemaVersion(schemaVersion int64) {
+	f.schemaVersion = schemaVersion
+}
+
+// GetWritePolicy returns the handling of writes to the existing records of
+// the file described by the given TimeBucketInfo
+func (f *TimeBucketInfo) GetWritePolicy() EnumWritePolicy {
+	f.once.Do(f.initFromFile)
+	return f.writePolicy
+}
+
+// SetWritePolicy sets the handling of writes to existing records before the
+// header of a fixed length file is written
+func (f *TimeBucketInfo) SetWritePolicy(writePolicy EnumWritePolicy) error {
+	if writePolicy != OVERWRITE && f.GetRecordType() != FIXED {
+		return fmt.Errorf("Only fixed record types have write policies")
+	}
+	f.writePolicy = writePolicy
+	return nil
+}
+
+// GetTimestampFormat returns how the timestamps of the variable records of the
+// file described by the given TimeBucketInfo are stored
+func (f *TimeBucketInfo) GetTimestampFormat() EnumTimestampFormat {
+	f.once.Do(f.initFromFile)
+	return f.timestampFormat
+}
+
+// SetTimestampFormat sets how the timestamps of variable records are stored
+// before the file is created
+func (f *TimeBucketInfo) SetTimestampFormat(timestampFormat EnumTimestampFormat) error {
+	if timestampFormat != TICKS && f.GetRecordType() != VARIABLE {
+		return fmt.Errorf("Only variable record types have timestamp formats")
+	}
+	f.timestampFormat = timestampFormat
+	f.variableRecordLength = 0
+	return nil
+}
+
 // GetElementNames returns the field names contained by the file described by
 // the given TimeBucketInfo
 func (f *TimeBucketInfo) GetElementNames() []string {
@@ -278,14 +352,12 @@ func (f *TimeBucketInfo) readHeader(path string) (err error) {
 		log.Error("Failed to read header part3 from file: %v - Error: %v", path, err)
 		return err
 	}
-	if EnumRecordType(header.RecordType) == VARIABLE {
-		// Read to end of header
-		start += int(header.NElements)
-		n, err = file.Read(buffer[start:Headersize])
-		if err != nil || n != (Headersize-start) {
-			log.Error("Failed to read header part4 from file: %v - Error: %v", path, err)
-			return err
-		}
+	// Read to end of header, which holds the schema version
+	start += int(header.NElements)
+	n, err = file.Read(buffer[start:Headersize])
+	if err != nil || n != (Headersize-start) {
+		log.Error("Failed to read header part4 from file: %v - Error: %v", path, err)
+		return err
 	}
 	f.load(header, path)
 	return nil
@@ -301,6 +373,10 @@ func (f *TimeBucketInfo) load(hp *Header, path string) {
 	f.nElements = int32(hp.NElements)
 	f.recordLength = int32(hp.RecordLength)
 	f.recordType = EnumRecordType(hp.RecordType)
+	f.compression = EnumCompressionType(hp.Compression)
+	f.schemaVersion = hp.SchemaVersion
+	f.writePolicy = EnumWritePolicy(hp.WritePolicy)
+	f.timestampFormat = EnumTimestampFormat(hp.TimestampFormat)
 	f.elementNames = nil
 	f.elementTypes = nil
 	for i := 0; i < int(f.nElements); i++ {
@@ -326,11 +402,15 @@ type Header struct {
 	RecordType   int64
 	NElements    int64
 	RecordLength int64
-	reserved1    int64
+	Compression  int64 // EnumCompressionType, zero in files written before compression
 	// Above is the fixed header portion - size is 312 Bytes = (7*8 + 256)
-	ElementNames [1024][32]byte
-	ElementTypes [1024]byte
-	reserved2    [365]int64
+	ElementNames  [1024][32]byte
+	ElementTypes  [1024]byte
+	SchemaVersion int64 // Zero in files written before columns could be altered
+	WritePolicy   int64 // EnumWritePolicy, zero in files written before write policies
+	// EnumTimestampFormat, zero in files written before timestamp formats
+	TimestampFormat int64
+	reserved2       [362]int64
 }
 
 // WriteHeader writes the header described by a given TimeBucketInfo to the
@@ -343,6 +423,16 @@ func WriteHeader(file *os.File, f *TimeBucketInfo) error {
 	return err
 }
 
+// ReadTimestampFormat reads how the timestamps of variable records are stored
+// from the header of an open file
+func ReadTimestampFormat(r goio.ReaderAt) (EnumTimestampFormat, error) {
+	var buffer [8]byte
+	if _, err := r.ReadAt(buffer[:], int64(unsafe.Offsetof(Header{}.TimestampFormat))); err != nil {
+		return UNKNOWNTIMESTAMPFORMAT, err
+	}
+	return EnumTimestampFormat(ToInt64(buffer[:])), nil
+}
+
 // Load loads the header information from a given TimeBucketInfo
 func (hp *Header) Load(f *TimeBucketInfo) {
 	if f.GetVersion() != FileinfoVersion {
@@ -357,6 +447,10 @@ func (hp *Header) Load(f *TimeBucketInfo) {
 	hp.NElements = int64(f.GetNelements())
 	hp.RecordLength = int64(f.GetRecordLength())
 	hp.RecordType = int64(f.GetRecordType())
+	hp.Compression = int64(f.GetCompression())
+	hp.SchemaVersion = f.GetSchemaVersion()
+	hp.WritePolicy = int64(f.GetWritePolicy())
+	hp.TimestampFormat = int64(f.GetTimestampFormat())
 	for i := 0; i < int(hp.NElements); i++ {
 		copy(hp.ElementNames[i][:], f.GetElementNames()[i])
 		hp.ElementTypes[i] = byte(f.GetElementTypes()[i])
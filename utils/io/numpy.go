@@ -20,6 +20,7 @@ var (
 		UINT64:  "u8",
 		FLOAT32: "f4",
 		FLOAT64: "f8",
+		BOOL:    "b1",
 	}
 )
 
@@ -65,6 +66,14 @@ func (nds *NumpyDataset) Len() int {
 	return nds.Length
 }
 
+// DataShapes returns the name and type of each column
+func (nds *NumpyDataset) DataShapes() ([]DataShape, error) {
+	if nds.dataShapes == nil {
+		return nds.buildDataShapes()
+	}
+	return nds.dataShapes, nil
+}
+
 func (nds *NumpyDataset) buildDataShapes() ([]DataShape, error) {
 	etypes := []EnumElementType{}
 	for _, typeStr := range nds.ColumnTypes {
@@ -0,0 +1,34 @@
+package io
+
+import (
+	"os"
+	"syscall"
+)
+
+// HoleAlignment is the filesystem block size that holes are aligned to, as
+// only whole blocks can be deallocated
+const HoleAlignment = 4096
+
+// UsedBytes returns the disk space allocated to a file, which is less than
+// its size for sparse files
+func UsedBytes(path string) (int64, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	if st, ok := fi.Sys().(*syscall.Stat_t); ok {
+		return int64(st.Blocks) * 512, nil
+	}
+	return fi.Size(), nil
+}
+
+// AlignHole shrinks a byte range to the whole filesystem blocks it covers,
+// returning a zero length when it does not cover any
+func AlignHole(offset, length int64) (alignedOffset, alignedLength int64) {
+	start := (offset + HoleAlignment - 1) / HoleAlignment * HoleAlignment
+	end := (offset + length) / HoleAlignment * HoleAlignment
+	if end <= start {
+		return start, 0
+	}
+	return start, end - start
+}
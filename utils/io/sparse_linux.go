@@ -0,0 +1,19 @@
+// +build linux
+
+package io
+
+import (
+	"os"
+	"syscall"
+)
+
+const (
+	fallocKeepSize  = 0x01 // FALLOC_FL_KEEP_SIZE
+	fallocPunchHole = 0x02 // FALLOC_FL_PUNCH_HOLE
+)
+
+// PunchHole deallocates a byte range of a file, which then reads as zeros
+// without changing the file size
+func PunchHole(fp *os.File, offset, length int64) error {
+	return syscall.Fallocate(int(fp.Fd()), fallocPunchHole|fallocKeepSize, offset, length)
+}
@@ -0,0 +1,13 @@
+// +build !linux
+
+package io
+
+import (
+	"fmt"
+	"os"
+)
+
+// PunchHole is only supported on Linux
+func PunchHole(fp *os.File, offset, length int64) error {
+	return fmt.Errorf("Punching holes is not supported on this platform")
+}
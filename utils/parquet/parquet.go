@@ -0,0 +1,472 @@
+/*
+Package parquet converts time buckets to and from Parquet files, to exchange
+data with data lakes. The files have one column per DataShape, Epoch and the
+Nanoseconds of variable records included, and are partitioned by Symbol and
+year in the Hive layout understood by Spark, DuckDB or pyarrow:
+
+	<dir>/Symbol=AAPL/year=2020/data.parquet
+
+The key, record type and timestamp format of the bucket are kept in the
+key-value metadata of each file, so that importing it recreates the bucket.
+*/
+package parquet
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xitongsys/parquet-go-source/local"
+	format "github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/reader"
+	"github.com/xitongsys/parquet-go/source"
+	"github.com/xitongsys/parquet-go/writer"
+
+	"github.com/alpacahq/marketstore/utils/io"
+)
+
+// FileName is the name of the Parquet file of each partition
+const FileName = "data.parquet"
+
+// Keys of the metadata of the files
+const (
+	keyMetadata             = "marketstore.key"
+	recordTypeMetadata      = "marketstore.record_type"
+	timestampFormatMetadata = "marketstore.timestamp_format"
+)
+
+// Metadata describes the bucket the data of a file was exported from
+type Metadata struct {
+	// Key of the bucket, e.g. AAPL/1Min/OHLCV
+	Key             string
+	RecordType      io.EnumRecordType
+	TimestampFormat io.EnumTimestampFormat
+}
+
+// PartitionPath returns the path of the file of a symbol and year under dir
+func PartitionPath(dir, symbol string, year int) string {
+	return filepath.Join(dir, "Symbol="+symbol, "year="+strconv.Itoa(year), FileName)
+}
+
+// parquetTypes are the Parquet types of the element types, as written in the
+// metadata of the CSV writer of parquet-go
+var parquetTypes = map[io.EnumElementType]string{
+	io.BYTE:    "INT_8",
+	io.INT16:   "INT_16",
+	io.INT32:   "INT32",
+	io.INT64:   "INT64",
+	io.EPOCH:   "INT64",
+	io.UINT8:   "UINT_8",
+	io.UINT16:  "UINT_16",
+	io.UINT32:  "UINT_32",
+	io.UINT64:  "UINT_64",
+	io.FLOAT32: "FLOAT",
+	io.FLOAT64: "DOUBLE",
+	io.BOOL:    "BOOLEAN",
+}
+
+/*
+Exporter writes the series of buckets to their partitions under a directory.
+The series of each bucket are written in time order, a page at a time, and
+the file of a partition is complete once the series move to the next year or
+the Exporter is closed.
+*/
+type Exporter struct {
+	dir   string
+	open  map[io.TimeBucketKey]*fileWriter
+	files []string
+}
+
+func NewExporter(dir string) *Exporter {
+	return &Exporter{dir: dir, open: make(map[io.TimeBucketKey]*fileWriter)}
+}
+
+// Write appends the rows of a series of a bucket to the files of its years
+func (e *Exporter) Write(tbk io.TimeBucketKey, cs *io.ColumnSeries, md Metadata) error {
+	epochs := cs.GetEpoch()
+	if epochs == nil {
+		return fmt.Errorf("Epoch column not present in the series of %s", tbk.String())
+	}
+	symbol := tbk.GetItemInCategory("Symbol")
+	if symbol == "" {
+		return fmt.Errorf("Key %s has no Symbol to partition by", tbk.String())
+	}
+	values, err := rowValues(cs)
+	if err != nil {
+		return err
+	}
+	for start := 0; start < len(epochs); {
+		year := time.Unix(epochs[start], 0).UTC().Year()
+		end := start + 1
+		for end < len(epochs) && time.Unix(epochs[end], 0).UTC().Year() == year {
+			end++
+		}
+		fw := e.open[tbk]
+		if fw == nil || fw.year != year {
+			if fw != nil {
+				delete(e.open, tbk)
+				if err = fw.close(); err != nil {
+					return err
+				}
+			}
+			if fw, err = newFileWriter(PartitionPath(e.dir, symbol, year), cs, md); err != nil {
+				return err
+			}
+			fw.year = year
+			e.open[tbk] = fw
+			e.files = append(e.files, fw.path)
+		} else if strings.Join(fw.names, ",") != strings.Join(cs.GetColumnNames(), ",") {
+			return fmt.Errorf("Columns of %s changed from %v to %v while writing %s",
+				tbk.String(), fw.names, cs.GetColumnNames(), fw.path)
+		}
+		for i := start; i < end; i++ {
+			row := make([]interface{}, len(values))
+			for j, value := range values {
+				row[j] = value(i)
+			}
+			if err = fw.pw.Write(row); err != nil {
+				return err
+			}
+		}
+		start = end
+	}
+	return nil
+}
+
+// Close completes the files still open and returns the paths of all the files
+// written
+func (e *Exporter) Close() ([]string, error) {
+	var err error
+	for tbk, fw := range e.open {
+		delete(e.open, tbk)
+		if cerr := fw.close(); err == nil {
+			err = cerr
+		}
+	}
+	return e.files, err
+}
+
+// Partition is the file of a symbol and year under an export directory
+type Partition struct {
+	Symbol string
+	Year   int
+	Path   string
+}
+
+// Partitions lists the files under dir in Symbol and year order
+func Partitions(dir string) (parts []Partition, err error) {
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(path, ".parquet") {
+			return nil
+		}
+		yearDir := filepath.Base(filepath.Dir(path))
+		symbolDir := filepath.Base(filepath.Dir(filepath.Dir(path)))
+		if !strings.HasPrefix(yearDir, "year=") || !strings.HasPrefix(symbolDir, "Symbol=") {
+			return fmt.Errorf("%s is not under a Symbol=<symbol>/year=<year> partition", path)
+		}
+		year, err := strconv.Atoi(strings.TrimPrefix(yearDir, "year="))
+		if err != nil {
+			return fmt.Errorf("%s is not under a Symbol=<symbol>/year=<year> partition", path)
+		}
+		parts = append(parts, Partition{
+			Symbol: strings.TrimPrefix(symbolDir, "Symbol="),
+			Year:   year,
+			Path:   path,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(parts, func(i, j int) bool {
+		if parts[i].Symbol != parts[j].Symbol {
+			return parts[i].Symbol < parts[j].Symbol
+		}
+		if parts[i].Year != parts[j].Year {
+			return parts[i].Year < parts[j].Year
+		}
+		return parts[i].Path < parts[j].Path
+	})
+	return parts, nil
+}
+
+/*
+Read reads a Parquet file into a series, along with the metadata of the bucket
+it was exported from, which is empty for the files written by other tools. The
+file must have an Epoch column in seconds, or in a timestamp type, and its
+other columns must be of the types of DataShapes, without null values.
+*/
+func Read(path string) (*io.ColumnSeries, *Metadata, error) {
+	f, err := local.NewLocalFileReader(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	pr, err := reader.NewParquetColumnReader(f, 1)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Unable to read %s: %s", path, err.Error())
+	}
+	defer pr.ReadStop()
+
+	md := &Metadata{RecordType: io.FIXED}
+	for _, kv := range pr.Footer.KeyValueMetadata {
+		if kv.Value == nil {
+			continue
+		}
+		switch kv.Key {
+		case keyMetadata:
+			md.Key = *kv.Value
+		case recordTypeMetadata:
+			md.RecordType = io.EnumRecordTypeByName(*kv.Value)
+		case timestampFormatMetadata:
+			md.TimestampFormat = io.EnumTimestampFormatByName(*kv.Value)
+		}
+	}
+
+	rows := pr.GetNumRows()
+	cs := io.NewColumnSeries()
+	elements := pr.SchemaHandler.SchemaElements
+	for i, element := range elements[1:] {
+		if element.GetNumChildren() > 0 || element.Type == nil {
+			return nil, nil, fmt.Errorf("%s: nested column %s is not supported", path, element.Name)
+		}
+		values, _, _, err := pr.ReadColumnByIndex(int64(i), rows)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: unable to read column %s: %s", path, element.Name, err.Error())
+		}
+		col, err := columnFromValues(element, values)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %s", path, err.Error())
+		}
+		cs.AddColumn(element.Name, col)
+	}
+	if cs.GetEpoch() == nil {
+		return nil, nil, fmt.Errorf("%s: Epoch column not present", path)
+	}
+	return cs, md, nil
+}
+
+/*
+Utility functions
+*/
+
+// fileWriter writes the file of a partition
+type fileWriter struct {
+	path  string
+	year  int
+	names []string
+	file  source.ParquetFile
+	pw    *writer.CSVWriter
+}
+
+func newFileWriter(path string, cs *io.ColumnSeries, md Metadata) (*fileWriter, error) {
+	var schema []string
+	for _, shape := range cs.GetDataShapes() {
+		typ, ok := parquetTypes[shape.Type]
+		if !ok {
+			return nil, fmt.Errorf("Column %s of type %s can not be written to Parquet",
+				shape.Name, shape.Type.String())
+		}
+		// The names are parsed from the metadata of the CSV writer
+		if strings.ContainsAny(shape.Name, ",=") {
+			return nil, fmt.Errorf("Column name %s can not be written to Parquet", shape.Name)
+		}
+		schema = append(schema, fmt.Sprintf("name=%s, type=%s", shape.Name, typ))
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	file, err := local.NewLocalFileWriter(path)
+	if err != nil {
+		return nil, err
+	}
+	pw, err := writer.NewCSVWriter(schema, file, 1)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	for key, value := range map[string]string{
+		keyMetadata:             md.Key,
+		recordTypeMetadata:      strings.ToLower(md.RecordType.String()),
+		timestampFormatMetadata: md.TimestampFormat.String(),
+	} {
+		value := value
+		pw.Footer.KeyValueMetadata = append(pw.Footer.KeyValueMetadata,
+			&format.KeyValue{Key: key, Value: &value})
+	}
+	sort.Slice(pw.Footer.KeyValueMetadata, func(i, j int) bool {
+		return pw.Footer.KeyValueMetadata[i].Key < pw.Footer.KeyValueMetadata[j].Key
+	})
+	return &fileWriter{path: path, names: cs.GetColumnNames(), file: file, pw: pw}, nil
+}
+
+func (fw *fileWriter) close() error {
+	if err := fw.pw.WriteStop(); err != nil {
+		fw.file.Close()
+		return fmt.Errorf("Unable to write %s: %s", fw.path, err.Error())
+	}
+	return fw.file.Close()
+}
+
+// rowValues returns the accessors of the values of the columns of a series,
+// in the Go types of their Parquet physical types
+func rowValues(cs *io.ColumnSeries) ([]func(i int) interface{}, error) {
+	var values []func(i int) interface{}
+	for _, name := range cs.GetColumnNames() {
+		var value func(i int) interface{}
+		switch col := cs.GetByName(name).(type) {
+		case []int8:
+			value = func(i int) interface{} { return int32(col[i]) }
+		case []int16:
+			value = func(i int) interface{} { return int32(col[i]) }
+		case []int32:
+			value = func(i int) interface{} { return col[i] }
+		case []int64:
+			value = func(i int) interface{} { return col[i] }
+		case []uint8:
+			value = func(i int) interface{} { return int32(col[i]) }
+		case []uint16:
+			value = func(i int) interface{} { return int32(col[i]) }
+		case []uint32:
+			value = func(i int) interface{} { return int32(col[i]) }
+		case []uint64:
+			value = func(i int) interface{} { return int64(col[i]) }
+		case []float32:
+			value = func(i int) interface{} { return col[i] }
+		case []float64:
+			value = func(i int) interface{} { return col[i] }
+		case []bool:
+			value = func(i int) interface{} { return col[i] }
+		default:
+			return nil, fmt.Errorf("Column %s of type %T can not be written to Parquet", name, col)
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// columnFromValues converts the values read from a column to the slice of
+// the element type of its Parquet type
+func columnFromValues(element *format.SchemaElement, values []interface{}) (interface{}, error) {
+	ints := func() ([]int64, error) {
+		out := make([]int64, len(values))
+		for i, v := range values {
+			switch n := v.(type) {
+			case int32:
+				out[i] = int64(n)
+			case int64:
+				out[i] = n
+			default:
+				return nil, fmt.Errorf("column %s has a null or non integer value", element.Name)
+			}
+		}
+		return out, nil
+	}
+
+	converted := format.ConvertedType(-1)
+	if element.ConvertedType != nil {
+		converted = *element.ConvertedType
+	}
+	switch element.GetType() {
+	case format.Type_INT32, format.Type_INT64:
+		n, err := ints()
+		if err != nil {
+			return nil, err
+		}
+		switch converted {
+		case format.ConvertedType_INT_8:
+			col := make([]int8, len(n))
+			for i := range n {
+				col[i] = int8(n[i])
+			}
+			return col, nil
+		case format.ConvertedType_INT_16:
+			col := make([]int16, len(n))
+			for i := range n {
+				col[i] = int16(n[i])
+			}
+			return col, nil
+		case format.ConvertedType_UINT_8:
+			col := make([]uint8, len(n))
+			for i := range n {
+				col[i] = uint8(n[i])
+			}
+			return col, nil
+		case format.ConvertedType_UINT_16:
+			col := make([]uint16, len(n))
+			for i := range n {
+				col[i] = uint16(n[i])
+			}
+			return col, nil
+		case format.ConvertedType_UINT_32:
+			col := make([]uint32, len(n))
+			for i := range n {
+				col[i] = uint32(n[i])
+			}
+			return col, nil
+		case format.ConvertedType_UINT_64:
+			col := make([]uint64, len(n))
+			for i := range n {
+				col[i] = uint64(n[i])
+			}
+			return col, nil
+		case format.ConvertedType_TIMESTAMP_MILLIS, format.ConvertedType_TIMESTAMP_MICROS:
+			// Timestamps of other tools are truncated to the seconds of Epoch
+			unit := int64(1000)
+			if converted == format.ConvertedType_TIMESTAMP_MICROS {
+				unit = 1000000
+			}
+			for i := range n {
+				n[i] /= unit
+			}
+			return n, nil
+		}
+		if element.GetType() == format.Type_INT32 {
+			col := make([]int32, len(n))
+			for i := range n {
+				col[i] = int32(n[i])
+			}
+			return col, nil
+		}
+		return n, nil
+	case format.Type_FLOAT:
+		col := make([]float32, len(values))
+		for i, v := range values {
+			f, ok := v.(float32)
+			if !ok {
+				return nil, fmt.Errorf("column %s has a null value", element.Name)
+			}
+			col[i] = f
+		}
+		return col, nil
+	case format.Type_DOUBLE:
+		col := make([]float64, len(values))
+		for i, v := range values {
+			f, ok := v.(float64)
+			if !ok {
+				return nil, fmt.Errorf("column %s has a null value", element.Name)
+			}
+			col[i] = f
+		}
+		return col, nil
+	case format.Type_BOOLEAN:
+		col := make([]bool, len(values))
+		for i, v := range values {
+			b, ok := v.(bool)
+			if !ok {
+				return nil, fmt.Errorf("column %s has a null value", element.Name)
+			}
+			col[i] = b
+		}
+		return col, nil
+	}
+	return nil, fmt.Errorf("column %s of Parquet type %s is not supported", element.Name, element.GetType().String())
+}
@@ -0,0 +1,69 @@
+package parquet
+
+import (
+	"path/filepath"
+	"testing"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/alpacahq/marketstore/utils/io"
+)
+
+// Hook up gocheck into the "go test" runner.
+func Test(t *testing.T) { TestingT(t) }
+
+type ParquetTestSuite struct{}
+
+var _ = Suite(&ParquetTestSuite{})
+
+func (s *ParquetTestSuite) TestExportRead(c *C) {
+	dir := c.MkDir()
+	tbk := io.NewTimeBucketKey("AAPL/1Min/TICK")
+	md := Metadata{Key: "AAPL/1Min/TICK", RecordType: io.VARIABLE, TimestampFormat: io.NANOSECONDS}
+
+	// 2019-12-31 23:59, then 2020-01-01 00:00 twice, written in two pages
+	epochs := []int64{1577836740, 1577836800, 1577836800}
+	page := func(from, to int) *io.ColumnSeries {
+		cs := io.NewColumnSeries()
+		cs.AddColumn("Epoch", epochs[from:to])
+		cs.AddColumn("Price", []float32{1.5, 2.5, 3.5}[from:to])
+		cs.AddColumn("Size", []uint64{10, 20, 1 << 63}[from:to])
+		cs.AddColumn("Exchange", []int8{-1, 2, 3}[from:to])
+		cs.AddColumn("Cond", []uint16{1, 2, 65535}[from:to])
+		cs.AddColumn("Spread", []float64{0.1, 0.2, 0.3}[from:to])
+		cs.AddColumn("Nanoseconds", []int32{1, 2, 999999999}[from:to])
+		return cs
+	}
+	exporter := NewExporter(dir)
+	c.Assert(exporter.Write(*tbk, page(0, 2), md), IsNil)
+	c.Assert(exporter.Write(*tbk, page(2, 3), md), IsNil)
+	files, err := exporter.Close()
+	c.Assert(err, IsNil)
+	c.Assert(files, DeepEquals, []string{PartitionPath(dir, "AAPL", 2019), PartitionPath(dir, "AAPL", 2020)})
+	c.Assert(files[1], Equals, filepath.Join(dir, "Symbol=AAPL", "year=2020", "data.parquet"))
+
+	parts, err := Partitions(dir)
+	c.Assert(err, IsNil)
+	c.Assert(parts, DeepEquals, []Partition{
+		{Symbol: "AAPL", Year: 2019, Path: files[0]},
+		{Symbol: "AAPL", Year: 2020, Path: files[1]},
+	})
+
+	cs, readMd, err := Read(files[1])
+	c.Assert(err, IsNil)
+	c.Assert(*readMd, DeepEquals, md)
+	expected := page(1, 3)
+	c.Assert(cs.GetColumnNames(), DeepEquals, expected.GetColumnNames())
+	for _, name := range expected.GetColumnNames() {
+		c.Assert(cs.GetByName(name), DeepEquals, expected.GetByName(name), Commentf(name))
+	}
+
+	// The columns of a file can not change
+	changed := page(2, 3)
+	changed.Remove("Spread")
+	exporter = NewExporter(c.MkDir())
+	c.Assert(exporter.Write(*tbk, page(1, 2), md), IsNil)
+	c.Assert(exporter.Write(*tbk, changed, md), NotNil)
+	_, err = exporter.Close()
+	c.Assert(err, IsNil)
+}
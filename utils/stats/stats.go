@@ -1,3 +1,26 @@
 package stats
 
 var TotalQueries uint64
+
+// Data removed by the retention policies since startup
+var (
+	RetentionFilesRemoved   uint64
+	RetentionRecordsDeleted uint64
+	RetentionBytesFreed     uint64
+)
+
+// Sync writes since startup, and the group commits that made them durable
+var (
+	WALSyncRequests uint64
+	WALGroupCommits uint64
+)
+
+// Transaction groups retained for the followers by a primary, and applied by
+// a follower since startup, along with the catch-ups from a snapshot and the
+// last replication lag measured by a follower
+var (
+	ReplicationTGsShipped uint64
+	ReplicationTGsApplied uint64
+	ReplicationCatchUps   uint64
+	ReplicationLagMillis  uint64
+)